@@ -0,0 +1,132 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * admission.go
+ */
+
+package hatchet
+
+import (
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ADMISSION_SPIKE_PERCENT is the avg latency of slow ops in a window, in
+// percent of the avg of all slow ops, to flag a latency spike
+const ADMISSION_SPIKE_PERCENT = 200
+
+var (
+	flowControlRegex = regexp.MustCompile(`(?i)flow control is engaged`)
+	ticketRegex      = regexp.MustCompile(`(?i)(?:read|write)? ?tickets? (?:are )?(?:exhausted|unavailable)|no (?:read |write )?tickets available`)
+)
+
+// AdmissionWindow counts admission control events and slow ops of a minute
+type AdmissionWindow struct {
+	FlowControl int // flow control engaged logs and throttled slow ops
+	Ops         int // slow ops
+	Tickets     int // ticket exhaustion logs and slow ops queued for tickets
+	TotalMilli  int // total ms of slow ops
+	WaitMilli   int // ms slow ops waited for flow control and tickets
+}
+
+// AdmissionStats counts flow control and ticket exhaustion by minute to
+// correlate them with latency of slow ops
+type AdmissionStats struct {
+	Ops        int // slow ops of all minutes
+	TotalMilli int // total ms of slow ops of all minutes
+	Windows    map[string]*AdmissionWindow
+}
+
+// NewAdmissionStats returns AdmissionStats
+func NewAdmissionStats() *AdmissionStats {
+	return &AdmissionStats{Windows: map[string]*AdmissionWindow{}}
+}
+
+// Add counts flow control and ticket evidence of a log and a slow op of the
+// minute of date
+func (ptr *AdmissionStats) Add(doc *Logv2Info, stat *OpStat, date string) {
+	if len(date) < 16 {
+		return
+	}
+	minute := date[:16]
+	window := ptr.Windows[minute]
+	if window == nil {
+		window = &AdmissionWindow{}
+	}
+	message := doc.Msg + " " + doc.Message
+	if flowControlRegex.MatchString(message) {
+		window.FlowControl++
+	} else if ticketRegex.MatchString(message) {
+		window.Tickets++
+	}
+	if stat != nil {
+		flowMicros, ticketMicros := GetAdmissionWaits(doc)
+		if flowMicros > 0 {
+			window.FlowControl++
+		}
+		if ticketMicros > 0 {
+			window.Tickets++
+		}
+		window.Ops++
+		window.TotalMilli += doc.Attributes.Milli
+		window.WaitMilli += (flowMicros + ticketMicros) / 1000
+		ptr.Ops++
+		ptr.TotalMilli += doc.Attributes.Milli
+	}
+	if window.Ops > 0 || window.FlowControl > 0 || window.Tickets > 0 {
+		ptr.Windows[minute] = window
+	}
+}
+
+// GetAdmissionWaits returns microseconds a slow op waited for flow control
+// and for tickets, from queues since 7.0 or acquiring the Global lock before
+func GetAdmissionWaits(doc *Logv2Info) (int, int) {
+	attr := doc.Attr.Map()
+	flowMicros := 0
+	if flowControl, ok := attr["flowControl"].(bson.D); ok {
+		flowMicros = ToInt(flowControl.Map()["timeAcquiringMicros"])
+	}
+	ticketMicros, ok := GetTicketWait(doc)
+	if !ok {
+		if locks, ok := attr["locks"].(bson.D); ok {
+			if global, ok := locks.Map()["Global"].(bson.D); ok {
+				if micros, ok := global.Map()["timeAcquiringMicros"].(bson.D); ok {
+					for _, mode := range micros {
+						ticketMicros += ToInt(mode.Value)
+					}
+				}
+			}
+		}
+	}
+	return flowMicros, ticketMicros
+}
+
+// GetAuditData returns audit data by type of minutes with flow control or
+// ticket exhaustion, the avg latency of slow ops in percent of the avg of
+// all, flow control and ticket events, ms waited, slow ops, and avg ms
+func (ptr *AdmissionStats) GetAuditData() map[string][]NameValue {
+	data := map[string][]NameValue{}
+	avgMilli := 0.0
+	if ptr.Ops > 0 {
+		avgMilli = float64(ptr.TotalMilli) / float64(ptr.Ops)
+	}
+	for minute, window := range ptr.Windows {
+		if window.FlowControl == 0 && window.Tickets == 0 {
+			continue
+		}
+		percent, avg := 0, 0
+		if window.Ops > 0 {
+			avg = window.TotalMilli / window.Ops
+			if avgMilli > 0 {
+				percent = int(100 * float64(window.TotalMilli) / float64(window.Ops) / avgMilli)
+			}
+		}
+		data["admission"] = append(data["admission"], NameValue{minute, percent})
+		data["admission-flow"] = append(data["admission-flow"], NameValue{minute, window.FlowControl})
+		data["admission-tickets"] = append(data["admission-tickets"], NameValue{minute, window.Tickets})
+		data["admission-wait-ms"] = append(data["admission-wait-ms"], NameValue{minute, window.WaitMilli})
+		data["admission-ops"] = append(data["admission-ops"], NameValue{minute, window.Ops})
+		data["admission-ms"] = append(data["admission-ms"], NameValue{minute, avg})
+	}
+	return data
+}
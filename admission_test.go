@@ -0,0 +1,48 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * admission_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAdmissionStats(t *testing.T) {
+	logs := []string{
+		`{"t":{"$date":"2023-01-01T00:00:10.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders"},"durationMillis":100}}`,
+		`{"t":{"$date":"2023-01-01T00:00:20.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders"},"durationMillis":100}}`,
+		`{"t":{"$date":"2023-01-01T00:01:00.000+00:00"},"s":"W","c":"STORAGE","id":22225,"ctx":"FlowControlRefresher","msg":"Flow control is engaged and the sustainer point is not moving. Please check the health of all secondaries."}`,
+		`{"t":{"$date":"2023-01-01T00:01:10.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn2","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"insert":"orders"},"flowControl":{"acquireCount":1,"timeAcquiringMicros":300000},"durationMillis":700}}`,
+		`{"t":{"$date":"2023-01-01T00:02:10.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn3","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders"},"locks":{"Global":{"acquireCount":{"r":1},"acquireWaitCount":{"r":1},"timeAcquiringMicros":{"r":50000}}},"durationMillis":100}}`,
+	}
+	admission := NewAdmissionStats()
+	for _, str := range logs {
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		stat, err := AnalyzeSlowOp(&doc)
+		if err != nil {
+			stat = nil
+		}
+		admission.Add(&doc, stat, getDateTimeStr(doc.Timestamp))
+	}
+	data := admission.GetAuditData()
+	if len(data["admission"]) != 2 {
+		t.Fatal("expected", 2, "but got", len(data["admission"]))
+	}
+	docs := MergeAuditSeries(AUDIT_SERIES["admission"], data)
+	expected := []interface{}{280, 2, 0, 300, 1, 700}
+	for i, value := range expected {
+		if docs[0].Values[i] != value {
+			t.Fatal("expected", expected, "but got", docs[0].Values)
+		}
+	}
+	if docs[1].Values[2] != 1 || docs[1].Values[3] != 50 {
+		t.Fatal("expected", 1, 50, "but got", docs[1].Values)
+	}
+}
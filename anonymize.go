@@ -0,0 +1,191 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * anonymize.go
+ */
+
+package hatchet
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// anonymizeHostKeys are attributes of host:port values
+var anonymizeHostKeys = map[string]bool{"host": true, "hostAndPort": true, "hostName": true, "me": true,
+	"primary": true, "remote": true, "syncSource": true, "target": true}
+
+// anonymizeCollKeys are fields of collection names, e.g. of $lookup, $merge,
+// and getMore
+var anonymizeCollKeys = map[string]bool{"$out": true, "$unionWith": true, "coll": true, "collection": true,
+	"from": true, "into": true}
+
+// anonymizeKeptDBs are databases of MongoDB kept as is
+var anonymizeKeptDBs = map[string]bool{"": true, "admin": true, "config": true, "local": true}
+
+var (
+	anonymizeIPv4 = regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`)
+	// host:port of free text, of dotted hosts or of 5 digit ports, e.g.
+	// mongo1:27017, not counters of slow ops, e.g. reslen:1234
+	anonymizeHostPort = regexp.MustCompile(`\b(?:[A-Za-z0-9][\w-]*(?:\.[\w-]+)+:\d{1,5}|[A-Za-z][\w-]*:\d{5})\b`)
+	// db.coll of free text
+	anonymizeNamespace = regexp.MustCompile(`\b[A-Za-z_][\w-]*\.[A-Za-z_$][\w$-]*(?:\.[\w$-]+)*`)
+)
+
+// Anonymizer replaces IPs, hostnames, and database and collection names of
+// logs with aliases of a keyed hash, the same name is of the same alias of a
+// key, so that analyses of logs of nodes or of runs of the same key join
+type Anonymizer struct {
+	key []byte
+}
+
+// NewAnonymizer returns Anonymizer of a key, a random key if empty
+func NewAnonymizer(key string) (*Anonymizer, error) {
+	if key != "" {
+		return &Anonymizer{key: []byte(key)}, nil
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return &Anonymizer{key: b}, nil
+}
+
+// getHash returns the first n bytes of the keyed hash of a name of a kind
+func (ptr *Anonymizer) getHash(kind string, name string, n int) []byte {
+	mac := hmac.New(sha256.New, ptr.key)
+	mac.Write([]byte(kind + ":" + name))
+	return mac.Sum(nil)[:n]
+}
+
+// AliasIP returns an alias of an IPv4 address in 10.0.0.0/8, loopback and
+// any addresses are kept
+func (ptr *Anonymizer) AliasIP(ip string) string {
+	if ip == "0.0.0.0" || strings.HasPrefix(ip, "127.") {
+		return ip
+	}
+	h := ptr.getHash("ip", ip, 3)
+	return fmt.Sprintf("10.%d.%d.%d", h[0], h[1], h[2])
+}
+
+// AliasHost returns an alias of a hostname or an IP keeping the port if any
+func (ptr *Anonymizer) AliasHost(hostport string) string {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, port = hostport, ""
+	}
+	if host == "" || host == "localhost" {
+		return hostport
+	} else if anonymizeIPv4.MatchString(host) && net.ParseIP(host) != nil {
+		host = ptr.AliasIP(host)
+	} else if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return hostport
+	} else {
+		host = "host-" + hex.EncodeToString(ptr.getHash("host", strings.ToLower(host), 4))
+	}
+	if port == "" {
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// AliasDB returns an alias of a database name, admin, config, and local are
+// kept
+func (ptr *Anonymizer) AliasDB(name string) string {
+	if anonymizeKeptDBs[name] {
+		return name
+	}
+	return "db_" + hex.EncodeToString(ptr.getHash("db", name, 4))
+}
+
+// AliasCollection returns an alias of a collection name, $cmd and system
+// collections are kept
+func (ptr *Anonymizer) AliasCollection(name string) string {
+	if name == "" || strings.HasPrefix(name, "$cmd") || strings.HasPrefix(name, "system.") || name == "oplog.rs" {
+		return name
+	}
+	return "coll_" + hex.EncodeToString(ptr.getHash("coll", name, 4))
+}
+
+// AliasNamespace returns an alias of a namespace of aliases of its database
+// and collection
+func (ptr *Anonymizer) AliasNamespace(ns string) string {
+	i := strings.Index(ns, ".")
+	if i < 0 {
+		return ptr.AliasDB(ns)
+	}
+	return ptr.AliasDB(ns[:i]) + "." + ptr.AliasCollection(ns[i+1:])
+}
+
+// Anonymize replaces IPs, hostnames, namespaces, and collection names of
+// commands of a log with aliases, returns true if any is replaced
+func (ptr *Anonymizer) Anonymize(doc *Logv2Info) bool {
+	changed := false
+	for i, attr := range doc.Attr {
+		command, ok := attr.Value.(bson.D)
+		if ok && (attr.Key == "command" || attr.Key == "originatingCommand") && len(command) > 0 {
+			if name, ok := command[0].Value.(string); ok { // collection name of a command
+				command[0].Value = ptr.AliasCollection(name)
+				changed = changed || command[0].Value != name
+			}
+			for j := 1; j < len(command); j++ {
+				command[j].Value = ptr.anonymizeValue(command[j].Key, command[j].Value, &changed)
+			}
+			continue
+		}
+		doc.Attr[i].Value = ptr.anonymizeValue(attr.Key, attr.Value, &changed)
+	}
+	return changed
+}
+
+// AnonymizeText replaces host:port, IPs, and namespaces of free text, e.g.
+// messages of legacy lines not converted, with aliases
+func (ptr *Anonymizer) AnonymizeText(str string) string {
+	str = anonymizeHostPort.ReplaceAllStringFunc(str, ptr.AliasHost)
+	str = anonymizeIPv4.ReplaceAllStringFunc(str, ptr.AliasIP)
+	return anonymizeNamespace.ReplaceAllStringFunc(str, ptr.AliasNamespace)
+}
+
+// anonymizeValue returns a value of a field with names replaced by aliases
+func (ptr *Anonymizer) anonymizeValue(key string, value interface{}, changed *bool) interface{} {
+	switch v := value.(type) {
+	case bson.D:
+		d := bson.D{}
+		for _, elem := range v {
+			d = append(d, bson.E{Key: elem.Key, Value: ptr.anonymizeValue(elem.Key, elem.Value, changed)})
+		}
+		return d
+	case bson.A:
+		a := bson.A{}
+		for _, elem := range v {
+			a = append(a, ptr.anonymizeValue(key, elem, changed))
+		}
+		return a
+	case string:
+		alias := v
+		if anonymizeHostKeys[key] {
+			alias = ptr.AliasHost(v)
+		} else if key == "ns" {
+			alias = ptr.AliasNamespace(v)
+		} else if key == "$db" || key == "db" {
+			alias = ptr.AliasDB(v)
+		} else if anonymizeCollKeys[key] {
+			alias = ptr.AliasCollection(v)
+		} else {
+			alias = anonymizeIPv4.ReplaceAllStringFunc(v, ptr.AliasIP)
+		}
+		if alias != v {
+			*changed = true
+		}
+		return alias
+	default:
+		return v
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * anonymize_test.go
+ */
+
+package hatchet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnonymizerAliases(t *testing.T) {
+	anonymizer, _ := NewAnonymizer("secret")
+	other, _ := NewAnonymizer("other")
+	if alias := anonymizer.AliasIP("192.168.1.10"); alias != anonymizer.AliasIP("192.168.1.10") ||
+		!strings.HasPrefix(alias, "10.") || alias == other.AliasIP("192.168.1.10") {
+		t.Fatal("expected", "the same alias of a key", "but got", alias)
+	}
+	for _, value := range []string{"127.0.0.1:27017", "localhost:27017"} {
+		if alias := anonymizer.AliasHost(value); alias != value {
+			t.Fatal("expected", value, "but got", alias)
+		}
+	}
+	for _, value := range []string{"admin.$cmd", "local.oplog.rs", "shop.system.views"} {
+		if alias := anonymizer.AliasNamespace(value); alias != value && !strings.HasSuffix(alias, ".system.views") {
+			t.Fatal("expected", value, "but got", alias)
+		}
+	}
+	host := anonymizer.AliasHost("Node1.Example.COM:27018")
+	if host != anonymizer.AliasHost("node1.example.com:27018") || !strings.HasPrefix(host, "host-") ||
+		!strings.HasSuffix(host, ":27018") {
+		t.Fatal("expected", "host-{hash}:27018", "but got", host)
+	}
+	ns := anonymizer.AliasNamespace("shop.orders")
+	expected := anonymizer.AliasDB("shop") + "." + anonymizer.AliasCollection("orders")
+	if ns != expected || !strings.HasPrefix(ns, "db_") {
+		t.Fatal("expected", expected, "but got", ns)
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	anonymizer, _ := NewAnonymizer("secret")
+	line := `{"t":{"$date":"2023-01-01T00:00:02.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query",` +
+		`"attr":{"type":"command","ns":"shop.orders","command":{"aggregate":"orders","pipeline":[{"$match":{"sku":"A"}},` +
+		`{"$lookup":{"from":"products","localField":"sku","foreignField":"sku","as":"p"}},{"$merge":{"into":{"db":"reports","coll":"daily"}}}],` +
+		`"$db":"shop"},"remote":"10.1.2.3:5000","durationMillis":150}}`
+	doc := Logv2Info{}
+	if err := UnmarshalLogv2([]byte(line), nil, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if !anonymizer.Anonymize(&doc) {
+		t.Fatal("expected", true, "but got", false)
+	}
+	if err := AddLegacyString(&doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"shop", "orders", "products", "reports", "daily", "10.1.2.3"} {
+		if strings.Contains(doc.Message, name) {
+			t.Fatal("expected", name, "replaced but got", doc.Message)
+		}
+	}
+	for _, alias := range []string{anonymizer.AliasNamespace("shop.orders"), `aggregate: "` + anonymizer.AliasCollection("orders"),
+		anonymizer.AliasCollection("products"), anonymizer.AliasDB("reports"), anonymizer.AliasHost("10.1.2.3:5000"), `"sku"`} {
+		if !strings.Contains(doc.Message, alias) {
+			t.Fatal("expected", alias, "but got", doc.Message)
+		}
+	}
+	stat, _ := AnalyzeSlowOp(&doc)
+	if stat.Namespace != anonymizer.AliasNamespace("shop.orders") || stat.Op != "aggregate" {
+		t.Fatal("expected", anonymizer.AliasNamespace("shop.orders"), "but got", stat.Namespace, stat.Op)
+	}
+}
+
+func TestAnonymizeLegacyMessage(t *testing.T) {
+	anonymizer, _ := NewAnonymizer("secret")
+	logv2 := &Logv2{anonymizer: anonymizer}
+	line := &LogLine{Str: `2023-01-01T00:00:05.000+0000 E QUERY    [conn12] Plan executor error on shop.users during find, ` +
+		`from db1.example.com:27017 10.1.2.3 and mongo1:27017, reslen:1234`}
+	if logv2.parseLine(line); line.Err != nil || line.LegacyErr != nil {
+		t.Fatal(line.Err, line.LegacyErr)
+	}
+	expected := "Plan executor error on " + anonymizer.AliasNamespace("shop.users") + " during find, from " +
+		anonymizer.AliasHost("db1.example.com:27017") + " " + anonymizer.AliasIP("10.1.2.3") + " and " +
+		anonymizer.AliasHost("mongo1:27017") + ", reslen:1234"
+	if line.Doc.Message != expected || line.Doc.Msg != expected {
+		t.Fatal("expected", expected, "but got", line.Doc.Message, line.Doc.Msg)
+	}
+}
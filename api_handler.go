@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/julienschmidt/httprouter"
 )
@@ -14,10 +15,57 @@ import (
 // APIHandler responds to API calls
 func APIHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	/** APIs
-	 * /api/hatchet/v1.0/hatchets/{hatchet}/logs/all
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/logs/all[?component=&context=&severity=&source=&duration=&limit=]
 	 * /api/hatchet/v1.0/hatchets/{hatchet}/logs/slowops
-	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/slowops
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/logs/search?q={query}[&limit=]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/logs/raw[?component=&context=&duration=&severity=&ns=&op=&filter=&_index=&source=]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/slowops[?ns={regex}&op={op,...}&minDuration={ms}&sort={[-]field}&offset={n}&limit={n}&slow=true&format=csv]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/writes
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/locks[?topN={n}]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/plans[?duration={start},{end}]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/planning
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/replans
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/targeting[?ratio={n}]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/sorts
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/getmores
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/stages
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/churn
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/reslen[?topN={n}]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/apps[?ns={regex}]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/elections
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/sharding
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/chunks
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/transactions
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/config
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/server
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/sources
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/severity[?severity={W|E|F}&topN={n}]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/stats/templates[?component={component}&rare=true]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/indexes/suggestions[?ns={regex}]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/ns/{db.coll}
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/report/summary[?topN={n}]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/report/html[?topN={n}]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/compare/{other}
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/diff/{other}[?a={start},{end}&b={start},{end}]
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/bookmarks/all
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/migrations/all
+	 * /api/hatchet/v1.0/hatchets/{hatchet}/trace/{id}[?download=true]
 	 */
+	if params.ByName("category") == "trace" && r.URL.Query().Get("download") == "true" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v-trace-%v.json",
+			params.ByName("hatchet"), params.ByName("attr")))
+	} else if params.ByName("category") == "stats" && params.ByName("attr") == "slowops" && r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v_slowops.csv", params.ByName("hatchet")))
+	} else if params.ByName("category") == "report" && params.ByName("attr") == "summary" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	} else if params.ByName("category") == "report" && params.ByName("attr") == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v.html", params.ByName("hatchet")))
+	} else if params.ByName("category") == "logs" && params.ByName("attr") == "raw" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v.log", params.ByName("hatchet")))
+	}
 	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
 	hatchetName := params.ByName("hatchet")
@@ -42,7 +90,404 @@ func APIHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params
 		if err != nil {
 			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
 		}
-		doc := map[string]interface{}{"hatchet": hatchetName, "has_more": false, "offset": 0, "limit": len(ops), "ops": ops}
+		if ops, err = FilterOpsByNamespace(ops, r.URL.Query().Get("ns")); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		if r.URL.Query().Get("slow") == "true" {
+			ops = GetHatchetSlowThresholds(dbase.GetHatchetInfo()).FilterSlowOps(ops)
+		}
+		ops = FilterOpsByOp(ops, r.URL.Query().Get("op"))
+		ops = FilterOpsByMinMilli(ops, ToFloat64(r.URL.Query().Get("minDuration")))
+		if field := r.URL.Query().Get("sort"); field != "" {
+			if err = SortSlowOps(ops, field); err != nil {
+				json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+				return
+			}
+		}
+		total := len(ops)
+		offset := ToInt(r.URL.Query().Get("offset"))
+		limit := ToInt(r.URL.Query().Get("limit"))
+		ops, hasMore := PageSlowOps(ops, offset, limit)
+		if r.URL.Query().Get("format") == "csv" {
+			if err = WriteSlowOpsCSV(w, ops); err != nil {
+				log.Println("export", hatchetName, err)
+			}
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "has_more": hasMore, "offset": offset, "limit": len(ops),
+			"total": total, "ops": ops}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "writes" {
+		writes, err := dbase.GetWriteStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "writes": writes}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "locks" {
+		topN := ToInt(r.URL.Query().Get("topN"))
+		if topN == 0 {
+			topN = TOP_N
+		}
+		ops, err := dbase.GetLockStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "locks": GetLockStatsByNamespace(ops, topN),
+			"waits": GetLockWaitsByOp(ops, topN)}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "planning" {
+		ops, err := dbase.GetPlanningStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		docs := []map[string]interface{}{}
+		for _, op := range ops {
+			docs = append(docs, map[string]interface{}{"op": op.Op, "ns": op.Namespace, "query_pattern": op.QueryPattern,
+				"count": op.Count, "min_micros": op.MinMicros, "avg_micros": op.AvgMicros, "max_micros": op.MaxMicros,
+				"stddev_micros": op.StdDevMicros, "ratio": op.GetRatio(), "thrashing": op.IsThrashing()})
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "planning": docs}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "replans" {
+		ops, err := dbase.GetReplanStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		docs := []map[string]interface{}{}
+		for _, op := range ops {
+			docs = append(docs, map[string]interface{}{"op": op.Op, "ns": op.Namespace, "query_pattern": op.QueryPattern,
+				"count": op.Count, "replans": op.Replans, "avg_ms": op.AvgMilli, "avg_replan_ms": op.AvgReplanMilli,
+				"max_replan_ms": op.MaxReplanMilli, "total_replan_ms": op.TotalReplanMilli, "impact_ms": op.GetImpact(),
+				"evictions": op.Evictions, "reason": op.Reason})
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "replans": docs}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "elections" {
+		events, err := dbase.GetElectionEvents()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		events = GetElectionTimeline(events)
+		elected, steppedDown := GetFailovers(events)
+		doc := map[string]interface{}{"hatchet": hatchetName, "events": events, "elected": elected, "stepped_down": steppedDown}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "chunks" {
+		events, err := dbase.GetChunkEvents()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		collections := []map[string]interface{}{}
+		docs, balancer := GetChunkAuditData(events)
+		for _, doc := range docs {
+			collections = append(collections, map[string]interface{}{"ns": doc.Name, "migrations": doc.Values[0],
+				"failed": doc.Values[1], "splits": doc.Values[2], "total_ms": doc.Values[3], "max_ms": doc.Values[4]})
+		}
+		rounds := 0
+		if len(balancer) > 0 {
+			rounds = ToInt(balancer[0].Values[0])
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "events": events, "collections": collections, "balancer_rounds": rounds}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "transactions" {
+		stats, err := dbase.GetTransactionStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "transactions": stats, "abort_rate": GetAbortRate(stats)}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "config" {
+		changes, err := dbase.GetConfigChanges()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "changes": changes}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "targeting" {
+		shapes, err := dbase.GetTargetingStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		ratio := ToInt(r.URL.Query().Get("ratio"))
+		if ratio <= 0 {
+			ratio = GetLogv2().GetTargetingRatio()
+		}
+		flagged := []TargetingStat{}
+		for _, shape := range shapes {
+			if shape.IsPoorlyTargeted(ratio) {
+				flagged = append(flagged, shape)
+			}
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "ratio": ratio, "namespaces": GetNamespaceTargeting(shapes),
+			"flagged": flagged}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "sorts" {
+		shapes, err := dbase.GetSortStageStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "shapes": shapes}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "getmores" {
+		shapes, err := dbase.GetGetMoreStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "shapes": shapes}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "server" {
+		banner, err := dbase.GetServerInfo()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		server := map[string]string{}
+		for _, doc := range GetServerInfoAuditData(banner) {
+			server[doc.Name] = doc.Values[0].(string)
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "server": server}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "severity" {
+		stats, err := dbase.GetSeverityStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		docs, err := FilterSeverityStats(stats, r.URL.Query().Get("severity"), ToInt(r.URL.Query().Get("topN")))
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		totals := map[string]interface{}{}
+		for _, total := range GetSeverityTotals(stats) {
+			totals[total.Name] = map[string]interface{}{"count": total.Values[0], "messages": total.Values[1]}
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "messages": docs, "totals": totals}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "templates" {
+		docs, err := dbase.GetLogTemplates()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templates := GetLogTemplates(docs, r.URL.Query().Get("component"), r.URL.Query().Get("rare") == "true")
+		doc := map[string]interface{}{"hatchet": hatchetName, "templates": templates}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "apps" {
+		apps, err := dbase.GetAppStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		if apps, err = FilterAppsByNamespace(apps, r.URL.Query().Get("ns")); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "apps": GetAppSummaries(apps), "ops": apps}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "churn" {
+		events, err := dbase.GetConnectionEvents()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "clients": GetClientChurns(events),
+			"lifetime_buckets": LIFETIME_LABELS}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "reslen" {
+		ops, err := dbase.GetSlowOps("total_ms", "DESC", false)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		topN := ToInt(r.URL.Query().Get("topN"))
+		if topN == 0 {
+			topN = TOP_N
+		}
+		namespaces, shapes := GetReslenStats(ops, topN)
+		json.NewEncoder(w).Encode(map[string]interface{}{"hatchet": hatchetName, "namespaces": namespaces,
+			"shapes": shapes})
+		return
+	} else if category == "stats" && attr == "stages" {
+		pipelines, err := dbase.GetPipelineStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		stages := GetStageStats(pipelines)
+		docs := []map[string]interface{}{}
+		for _, stage := range stages {
+			docs = append(docs, map[string]interface{}{"ns": stage.Namespace, "stage": stage.Stage, "count": stage.Count,
+				"pipelines": stage.Pipelines, "occurrences": stage.Occurrences, "avg_ms": stage.GetAvgMilli(),
+				"max_ms": stage.MaxMilli, "total_ms": stage.TotalMilli, "percent": stage.Percent, "dominant": stage.IsDominant()})
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "stages": docs, "pipelines": pipelines,
+			"lookup_heavy": GetLookupHeavyNamespaces(stages)}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "sources" {
+		docs, err := dbase.GetSourceStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "sources": docs}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "sharding" {
+		ops, err := dbase.GetShardingStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		docs := []map[string]interface{}{}
+		for _, op := range ops {
+			docs = append(docs, map[string]interface{}{"op": op.Op, "ns": op.Namespace, "query_pattern": op.QueryPattern,
+				"count": op.Count, "min_shards": op.MinShards, "avg_shards": op.AvgShards, "max_shards": op.MaxShards,
+				"all_shards": op.AllShards, "shards": op.Shards, "targeting": op.GetTargeting()})
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "sharding": docs}
+		if len(ops) > 0 {
+			doc["total_shards"] = ops[0].TotalShards
+		}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "stats" && attr == "plans" {
+		plans, err := dbase.GetShapePlans(r.URL.Query().Get("duration"))
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "plans": GetPlanTimelines(plans)}
 		b, err := json.Marshal(doc)
 		if err != nil {
 			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
@@ -63,6 +508,56 @@ func APIHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params
 			w.Write(b)
 		}
 		return
+	} else if category == "ns" {
+		drilldown, err := GetNamespaceDrilldown(hatchetName, attr)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		b, err := json.Marshal(drilldown)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "report" && (attr == "summary" || attr == "html") {
+		topN := ToInt(r.URL.Query().Get("topN"))
+		if topN == 0 {
+			topN = TOP_N
+		}
+		report, err := GetReport(dbase, hatchetName, topN)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		if attr == "html" {
+			_, err = report.WriteHTML(w)
+		} else {
+			_, err = report.WriteMarkdown(w)
+		}
+		if err != nil {
+			log.Println("report", err)
+		}
+		return
+	} else if category == "indexes" && attr == "suggestions" {
+		ops, err := dbase.GetSlowOps("total_ms", "DESC", false)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		if ops, err = FilterOpsByNamespace(ops, r.URL.Query().Get("ns")); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"hatchet": hatchetName, "suggestions": GetIndexSuggestions(ops)}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
 	} else if category == "logs" && attr == "slowops" {
 		topN := ToInt(r.URL.Query().Get("topN"))
 		if topN == 0 {
@@ -80,11 +575,53 @@ func APIHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params
 			w.Write(b)
 		}
 		return
+	} else if category == "logs" && attr == "raw" {
+		filters, err := GetRawLogFilters(r.URL.Query())
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		count, err := dbase.ExportLogs(w, filters)
+		if err != nil {
+			log.Println("export", hatchetName, err)
+			if count == 0 {
+				json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			}
+			return
+		}
+		if dbase.GetVerbose() {
+			log.Println("exported", count, "lines of", hatchetName)
+		}
+		return
+	} else if category == "logs" && attr == "search" {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": "missing q"})
+			return
+		}
+		limit := r.URL.Query().Get("limit")
+		if limit == "" {
+			limit = fmt.Sprintf("%v", LIMIT)
+		}
+		offset, nlimit := GetOffsetLimit(limit)
+		logs, err := dbase.SearchMessages(query, limit)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		hasMore := len(logs) > nlimit
+		if hasMore {
+			logs = logs[:len(logs)-1]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"hatchet": hatchetName, "q": query, "has_more": hasMore,
+			"offset": offset, "limit": len(logs), "logs": logs})
+		return
 	} else if category == "logs" && attr == "all" {
 		var hasMore bool
 		component := r.URL.Query().Get("component")
 		context := r.URL.Query().Get("context")
 		severity := r.URL.Query().Get("severity")
+		source := r.URL.Query().Get("source")
 		duration := r.URL.Query().Get("duration")
 		limit := r.URL.Query().Get("limit")
 		if limit == "" {
@@ -92,7 +629,8 @@ func APIHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params
 		}
 		offset, nlimit := GetOffsetLimit(limit)
 		logs, err := dbase.GetLogs(fmt.Sprintf("component=%v", component), fmt.Sprintf("limit=%v", limit),
-			fmt.Sprintf("context=%v", context), fmt.Sprintf("severity=%v", severity), fmt.Sprintf("duration=%v", duration))
+			fmt.Sprintf("context=%v", context), fmt.Sprintf("severity=%v", severity), fmt.Sprintf("source=%v", source),
+			fmt.Sprintf("duration=%v", duration))
 		if err != nil {
 			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
 			return
@@ -109,6 +647,65 @@ func APIHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params
 		}
 		w.Write(b)
 		return
+	} else if category == "bookmarks" {
+		bookmarks, err := dbase.GetBookmarks()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"hatchet": hatchetName, "bookmarks": bookmarks})
+		return
+	} else if category == "migrations" {
+		migrations, err := dbase.GetMigrations()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"hatchet": hatchetName, "schema_version": DB_SCHEMA_VERSION,
+			"migrations": migrations})
+		return
+	} else if category == "trace" {
+		id := ToInt(attr)
+		logs, err := dbase.GetConnectionLogs(id)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		trace, err := GetTrace(hatchetName, id, logs)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(trace)
+		return
+	} else if category == "compare" {
+		comparison, err := CompareHatchets(hatchetName, attr)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		b, err := json.Marshal(comparison)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
+	} else if category == "diff" {
+		diff, err := DiffHatchets([2]string{hatchetName, attr}, [2]string{r.URL.Query().Get("a"), r.URL.Query().Get("b")})
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		b, err := json.Marshal(diff)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		} else {
+			w.Write(b)
+		}
+		return
 	}
 	json.NewEncoder(w).Encode(map[string]interface{}{"ok": 1, "message": "Hello Hatchet API!"})
 }
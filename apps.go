@@ -0,0 +1,145 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * apps.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// APP_UNKNOWN is the application of ops of connections without an appName
+const APP_UNKNOWN = "(unknown)"
+
+// AppNames tracks appNames of connections from client metadata
+type AppNames struct {
+	names map[string]string // context to appName
+}
+
+// AppStat stores slow ops of an application by op and namespace
+type AppStat struct {
+	AppName    string `json:"app_name" bson:"app_name"`
+	Count      int    `json:"count" bson:"count"`
+	MaxMilli   int    `json:"max_ms" bson:"max_ms"`
+	Namespace  string `json:"ns" bson:"ns"`
+	Op         string `json:"op" bson:"op"`
+	TotalMilli int    `json:"total_ms" bson:"total_ms"`
+}
+
+// AppSummary stores slow ops of an application
+type AppSummary struct {
+	AppName    string  `json:"app_name" bson:"app_name"`
+	Count      int     `json:"count" bson:"count"`
+	Namespaces int     `json:"namespaces" bson:"namespaces"`
+	Percent    float64 `json:"percent" bson:"percent"` // of total ms of all applications
+	TotalMilli int     `json:"total_ms" bson:"total_ms"`
+}
+
+// NewAppNames returns AppNames
+func NewAppNames() *AppNames {
+	return &AppNames{names: map[string]string{}}
+}
+
+// Add remembers the appName of a connection from its client metadata, and
+// sets the appName of an op from its appName attribute or of its connection
+func (ptr *AppNames) Add(doc *Logv2Info, stat *OpStat) {
+	if name, ok := GetClientAppName(doc); ok {
+		ptr.names[doc.Context] = name
+		return
+	} else if doc.Msg == "Connection ended" {
+		delete(ptr.names, doc.Context)
+		return
+	}
+	if stat == nil || stat.Op == "" {
+		return
+	}
+	if name, ok := doc.Attr.Map()["appName"].(string); ok && name != "" {
+		doc.AppName = name
+	} else {
+		doc.AppName = ptr.names[doc.Context]
+	}
+}
+
+// GetClientAppName returns the application name of client metadata, false
+// otherwise
+func GetClientAppName(doc *Logv2Info) (string, bool) {
+	if doc.Msg != "client metadata" {
+		return "", false
+	}
+	metadata, ok := doc.Attr.Map()["doc"].(bson.D)
+	if !ok {
+		return "", false
+	}
+	application, ok := metadata.Map()["application"].(bson.D)
+	if !ok {
+		return "", false
+	}
+	name, ok := application.Map()["name"].(string)
+	return name, ok && name != ""
+}
+
+// GetAvgMilli returns the average milliseconds of ops
+func (ptr *AppStat) GetAvgMilli() float64 {
+	if ptr.Count == 0 {
+		return 0
+	}
+	return float64(ptr.TotalMilli) / float64(ptr.Count)
+}
+
+// FilterAppsByNamespace returns app stats of namespaces matching a regex
+func FilterAppsByNamespace(apps []AppStat, pattern string) ([]AppStat, error) {
+	if pattern == "" {
+		return apps, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace regex %q: %v", pattern, err)
+	}
+	filtered := []AppStat{}
+	for _, app := range apps {
+		if re.MatchString(app.Namespace) {
+			filtered = append(filtered, app)
+		}
+	}
+	return filtered, nil
+}
+
+// GetAppSummaries returns slow ops by application, the most total
+// milliseconds first
+func GetAppSummaries(apps []AppStat) []AppSummary {
+	summaries := map[string]*AppSummary{}
+	namespaces := map[string]map[string]bool{}
+	total := 0
+	for _, app := range apps {
+		summary, ok := summaries[app.AppName]
+		if !ok {
+			summary = &AppSummary{AppName: app.AppName}
+			summaries[app.AppName] = summary
+			namespaces[app.AppName] = map[string]bool{}
+		}
+		summary.Count += app.Count
+		summary.TotalMilli += app.TotalMilli
+		namespaces[app.AppName][app.Namespace] = true
+		total += app.TotalMilli
+	}
+	docs := []AppSummary{}
+	for name, summary := range summaries {
+		summary.Namespaces = len(namespaces[name])
+		if total > 0 {
+			summary.Percent = 100 * float64(summary.TotalMilli) / float64(total)
+		}
+		docs = append(docs, *summary)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].TotalMilli != docs[j].TotalMilli {
+			return docs[i].TotalMilli > docs[j].TotalMilli
+		}
+		return docs[i].AppName < docs[j].AppName
+	})
+	return docs
+}
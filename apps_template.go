@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * apps_template.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetAppsTemplate returns HTML
+func GetAppsTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+	<form action='/hatchets/{{.Hatchet}}/stats/apps' method='get'>
+		<input type='text' name='ns' value='{{.NS}}' placeholder='namespace regex' size='30'/>
+		<button class='btn' type='submit'><i class='fa fa-search'></i></button>
+	</form>
+{{if .Apps}}
+	<table width='100%'>
+		<caption>Workload by Application</caption>
+		<tr><th>appName</th><th>count</th><th>namespaces</th><th>total ms</th><th>% of time</th></tr>
+	{{range $s := .Summaries}}
+		<tr><td>{{$s.AppName}}</td>
+			<td align='right'>{{numPrinter $s.Count}}</td>
+			<td align='right'>{{numPrinter $s.Namespaces}}</td>
+			<td align='right'>{{numPrinter $s.TotalMilli}}</td>
+			<td align='right'>{{formatFloat $s.Percent}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<table width='100%'>
+		<caption>Slow Ops by Application and Namespace</caption>
+		<tr><th>appName</th><th>op</th><th>namespace</th><th>count</th><th>avg ms</th><th>max ms</th><th>total ms</th></tr>
+	{{range $a := .Apps}}
+		<tr><td>{{$a.AppName}}</td><td>{{$a.Op}}</td>
+			<td><a href='/hatchets/{{$.Hatchet}}/stats/apps?ns=^{{$a.Namespace}}$'>{{$a.Namespace}}</a></td>
+			<td align='right'>{{numPrinter $a.Count}}</td>
+			<td align='right'>{{printf "%.0f" $a.GetAvgMilli}}</td>
+			<td align='right'>{{numPrinter $a.MaxMilli}}</td>
+			<td align='right'>{{numPrinter $a.TotalMilli}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<div>The appName of an op is from its appName attribute or the client metadata of its connection, ops of
+		connections without client metadata in the logs are of the {{.Unknown}} application.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no slow ops found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"formatFloat": func(f float64) string {
+			return fmt.Sprintf("%.1f", f)
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * apps_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAppNames(t *testing.T) {
+	lines := []string{
+		`{"t":{"$date":"2023-01-01T00:00:02.017+00:00"},"s":"I","c":"NETWORK","id":51800,"ctx":"conn102","msg":"client metadata","attr":{"remote":"10.0.4.22:58721","client":"conn102","doc":{"application":{"name":"sessions"},"driver":{"name":"mongo-go-driver","version":"v1.11.1"}}}}`,
+		`{"t":{"$date":"2023-01-01T00:00:03.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn102","msg":"Slow query","attr":{"type":"command","ns":"app.sessions","command":{"find":"sessions","filter":{"a":1}},"durationMillis":200}}`,
+		`{"t":{"$date":"2023-01-01T00:00:04.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn103","msg":"Slow query","attr":{"type":"command","ns":"app.sessions","appName":"reporting","command":{"find":"sessions","filter":{"a":1}},"durationMillis":200}}`,
+		`{"t":{"$date":"2023-01-01T00:00:05.000+00:00"},"s":"I","c":"NETWORK","id":22944,"ctx":"conn102","msg":"Connection ended","attr":{"remote":"10.0.4.22:58721","connectionId":102,"connectionCount":0}}`,
+		`{"t":{"$date":"2023-01-01T00:00:06.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn102","msg":"Slow query","attr":{"type":"command","ns":"app.sessions","command":{"find":"sessions","filter":{"a":1}},"durationMillis":200}}`,
+	}
+	expected := []string{"", "sessions", "reporting", "", ""}
+	apps := NewAppNames()
+	for i, line := range lines {
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(line), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		stat, _ := AnalyzeSlowOp(&doc)
+		apps.Add(&doc, stat)
+		if doc.AppName != expected[i] {
+			t.Fatal("expected", expected[i], "but got", doc.AppName)
+		}
+	}
+}
+
+func TestGetAppSummaries(t *testing.T) {
+	apps := []AppStat{
+		{AppName: "reporting", Count: 2, Namespace: "shop.orders", Op: "aggregate", TotalMilli: 600},
+		{AppName: "orders-svc", Count: 10, Namespace: "shop.orders", Op: "find", TotalMilli: 300},
+		{AppName: "orders-svc", Count: 5, Namespace: "shop.items", Op: "find", TotalMilli: 100},
+	}
+	summaries := GetAppSummaries(apps)
+	if len(summaries) != 2 || summaries[0].AppName != "reporting" || summaries[0].Percent != 60 {
+		t.Fatal("expected", "reporting of 60%", "but got", summaries)
+	}
+	if summaries[1].Count != 15 || summaries[1].Namespaces != 2 {
+		t.Fatal("expected", "15 ops of 2 namespaces", "but got", summaries[1])
+	}
+	filtered, err := FilterAppsByNamespace(apps, `\.items$`)
+	if err != nil || len(filtered) != 1 {
+		t.Fatal("expected", 1, "but got", filtered, err)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * atlas.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/simagix/gox"
+)
+
+// ATLAS_API_URL is the base URL of the Atlas Admin API
+const ATLAS_API_URL = "https://cloud.mongodb.com/api/atlas/v1.0"
+
+// ATLAS_MAX_HOURS is the max hours of logs Atlas keeps
+const ATLAS_MAX_HOURS = 30 * 24
+
+var objectIDHex = regexp.MustCompile(`^[0-9a-f]{24}$`)
+
+// AtlasProcess is a mongod or mongos of an Atlas project
+type AtlasProcess struct {
+	Hostname       string `json:"hostname"`
+	Port           int    `json:"port"`
+	ReplicaSetName string `json:"replicaSetName"`
+	TypeName       string `json:"typeName"`
+	UserAlias      string `json:"userAlias"`
+}
+
+// AtlasLog is a mongod log of a node to download
+type AtlasLog struct {
+	Name string // node name, e.g. cluster0-shard-00-00
+	URL  string
+}
+
+// AtlasClient downloads logs using Atlas Admin API keys
+type AtlasClient struct {
+	baseURL    string
+	publicKey  string
+	privateKey string
+}
+
+// NewAtlasClient returns AtlasClient of API keys, {public key}:{private key}
+// or MONGODB_ATLAS_PUBLIC_API_KEY and MONGODB_ATLAS_PRIVATE_API_KEY if empty
+func NewAtlasClient(apiKey string) (*AtlasClient, error) {
+	client := AtlasClient{baseURL: ATLAS_API_URL}
+	if apiKey == "" {
+		client.publicKey = os.Getenv("MONGODB_ATLAS_PUBLIC_API_KEY")
+		client.privateKey = os.Getenv("MONGODB_ATLAS_PRIVATE_API_KEY")
+	} else if toks := strings.SplitN(apiKey, ":", 2); len(toks) == 2 {
+		client.publicKey, client.privateKey = toks[0], toks[1]
+	}
+	if client.publicKey == "" || client.privateKey == "" {
+		return nil, fmt.Errorf("Atlas API keys are required, use -user {public key}:{private key} or set MONGODB_ATLAS_PUBLIC_API_KEY and MONGODB_ATLAS_PRIVATE_API_KEY")
+	}
+	return &client, nil
+}
+
+// ParseAtlasCluster returns the project and the cluster of {project}/{cluster},
+// a project is an ID or a name
+func ParseAtlasCluster(str string) (string, string, error) {
+	i := strings.LastIndex(str, "/")
+	if i <= 0 || i == len(str)-1 {
+		return "", "", fmt.Errorf("invalid Atlas cluster %q, use {project}/{cluster}", str)
+	}
+	return str[:i], str[i+1:], nil
+}
+
+// GetProjectID returns the ID of a project ID or name
+func (ptr *AtlasClient) GetProjectID(project string) (string, error) {
+	if objectIDHex.MatchString(project) {
+		return project, nil
+	}
+	var group struct {
+		ID string `json:"id"`
+	}
+	if err := ptr.get("/groups/byName/"+url.PathEscape(project), &group); err != nil {
+		return "", err
+	}
+	return group.ID, nil
+}
+
+// GetClusterLogs returns mongod logs of all nodes of a cluster of the last hours
+func (ptr *AtlasClient) GetClusterLogs(projectID string, cluster string, hours int) ([]AtlasLog, error) {
+	if hours <= 0 || hours > ATLAS_MAX_HOURS {
+		return nil, fmt.Errorf("hours must be between 1 and %v", ATLAS_MAX_HOURS)
+	}
+	var processes struct {
+		Results []AtlasProcess `json:"results"`
+	}
+	if err := ptr.get(fmt.Sprintf("/groups/%v/processes?itemsPerPage=500", projectID), &processes); err != nil {
+		return nil, err
+	}
+	end := time.Now()
+	start := end.Add(-time.Duration(hours) * time.Hour)
+	logs := []AtlasLog{}
+	for _, process := range GetClusterProcesses(processes.Results, cluster) {
+		logs = append(logs, AtlasLog{Name: strings.Split(process.UserAlias, ".")[0],
+			URL: fmt.Sprintf("%v/groups/%v/clusters/%v/logs/mongodb.gz?startDate=%d&endDate=%d",
+				ptr.baseURL, projectID, process.Hostname, start.Unix(), end.Unix())})
+	}
+	if len(logs) == 0 {
+		return nil, fmt.Errorf("no mongod found of cluster %v", cluster)
+	}
+	return logs, nil
+}
+
+// Download writes a log to a directory and returns the file name
+func (ptr *AtlasClient) Download(atlasLog AtlasLog, dir string) (string, error) {
+	resp, err := gox.HTTPDigest("GET", atlasLog.URL, ptr.publicKey, ptr.privateKey,
+		map[string]string{"Accept": "application/gzip"})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download %v failed: %v", atlasLog.Name, resp.Status)
+	}
+	filename := filepath.Join(dir, atlasLog.Name+".log"+GZIP_EXT)
+	file, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err = io.Copy(file, resp.Body); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// GetClusterProcesses returns mongod processes of a cluster sorted by names,
+// Atlas names nodes {cluster}-shard-{shard}-{node} and {cluster}-config-{shard}-{node}
+func GetClusterProcesses(processes []AtlasProcess, cluster string) []AtlasProcess {
+	re := regexp.MustCompile(`^` + regexp.QuoteMeta(strings.ToLower(cluster)) + `-(shard|config)-\d+-\d+\.`)
+	nodes := []AtlasProcess{}
+	for _, process := range processes {
+		if process.TypeName == "SHARD_MONGOS" || !re.MatchString(strings.ToLower(process.UserAlias)) {
+			continue
+		}
+		nodes = append(nodes, process)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].UserAlias < nodes[j].UserAlias
+	})
+	return nodes
+}
+
+// get decodes the JSON response of an API
+func (ptr *AtlasClient) get(path string, result interface{}) error {
+	resp, err := gox.HTTPDigest("GET", ptr.baseURL+path, ptr.publicKey, ptr.privateKey,
+		map[string]string{"Accept": "application/json"})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Detail string `json:"detail"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("Atlas API %v failed: %v %v", path, resp.Status, apiErr.Detail)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// DownloadAtlasLogs downloads mongod logs of the last hours of all nodes of
+// an Atlas cluster, {project}/{cluster}, to a directory
+func DownloadAtlasLogs(str string, apiKey string, hours int, dir string) ([]string, error) {
+	project, cluster, err := ParseAtlasCluster(str)
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewAtlasClient(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	var projectID string
+	if projectID, err = client.GetProjectID(project); err != nil {
+		return nil, err
+	}
+	var logs []AtlasLog
+	if logs, err = client.GetClusterLogs(projectID, cluster, hours); err != nil {
+		return nil, err
+	}
+	filenames := []string{}
+	for _, atlasLog := range logs {
+		log.Println("downloading", hours, "hours of logs of", atlasLog.Name)
+		filename, err := client.Download(atlasLog, dir)
+		if err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames, nil
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * atlas_test.go
+ */
+
+package hatchet
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseAtlasCluster(t *testing.T) {
+	project, cluster, err := ParseAtlasCluster("My Project/Cluster0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project != "My Project" || cluster != "Cluster0" {
+		t.Fatal("expected", "My Project Cluster0", "but got", project, cluster)
+	}
+	for _, str := range []string{"Cluster0", "/Cluster0", "project/"} {
+		if _, _, err = ParseAtlasCluster(str); err == nil {
+			t.Fatal("expected", "error of", str, "but got", nil)
+		}
+	}
+}
+
+func TestGetClusterProcesses(t *testing.T) {
+	processes := []AtlasProcess{
+		{Hostname: "atlas-1-shard-00-01.abcde.mongodb.net", TypeName: "REPLICA_SECONDARY", UserAlias: "cluster0-shard-00-01.abcde.mongodb.net"},
+		{Hostname: "atlas-1-shard-00-00.abcde.mongodb.net", TypeName: "REPLICA_PRIMARY", UserAlias: "cluster0-shard-00-00.abcde.mongodb.net"},
+		{Hostname: "atlas-1-mongos-00-00.abcde.mongodb.net", TypeName: "SHARD_MONGOS", UserAlias: "cluster0-shard-00-00.abcde.mongodb.net"},
+		{Hostname: "atlas-2-shard-00-00.fghij.mongodb.net", TypeName: "REPLICA_PRIMARY", UserAlias: "cluster0-dev-shard-00-00.fghij.mongodb.net"},
+	}
+	nodes := GetClusterProcesses(processes, "Cluster0")
+	if len(nodes) != 2 {
+		t.Fatal("expected", 2, "but got", len(nodes))
+	}
+	if nodes[0].TypeName != "REPLICA_PRIMARY" {
+		t.Fatal("expected", "REPLICA_PRIMARY", "but got", nodes[0].TypeName)
+	}
+}
+
+func TestAtlasClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Digest ") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="MMS Public API", nonce="abc", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/groups/byName/My Project":
+			w.Write([]byte(`{"id": "5f0e0a1b2c3d4e5f6a7b8c9d"}`))
+		case r.URL.Path == "/groups/5f0e0a1b2c3d4e5f6a7b8c9d/processes":
+			w.Write([]byte(`{"results": [{"hostname": "atlas-1-shard-00-00.abcde.mongodb.net", "port": 27017,
+				"typeName": "REPLICA_PRIMARY", "userAlias": "cluster0-shard-00-00.abcde.mongodb.net"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/logs/mongodb.gz") && r.URL.Query().Get("startDate") != "":
+			w.Write([]byte("log content\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	if _, err := NewAtlasClient("public"); err == nil {
+		t.Fatal("expected", "error of missing private key", "but got", nil)
+	}
+	client, err := NewAtlasClient("public:private")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.baseURL = server.URL
+	projectID, err := client.GetProjectID("My Project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projectID != "5f0e0a1b2c3d4e5f6a7b8c9d" {
+		t.Fatal("expected", "5f0e0a1b2c3d4e5f6a7b8c9d", "but got", projectID)
+	}
+	logs, err := client.GetClusterLogs(projectID, "Cluster0", 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].Name != "cluster0-shard-00-00" {
+		t.Fatal("expected", "cluster0-shard-00-00", "but got", logs)
+	}
+	filename, err := client.Download(logs[0], t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(filename) != "cluster0-shard-00-00.log.gz" {
+		t.Fatal("expected", "cluster0-shard-00-00.log.gz", "but got", filename)
+	}
+	if data, _ := os.ReadFile(filename); string(data) != "log content\n" {
+		t.Fatal("expected", "log content", "but got", string(data))
+	}
+	if _, err = client.GetClusterLogs(projectID, "Cluster1", 24); err == nil {
+		t.Fatal("expected", "error of no mongod", "but got", nil)
+	}
+}
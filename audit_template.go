@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"html/template"
 	"math/rand"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,7 +19,7 @@ import (
 )
 
 // GetAuditTablesTemplate returns HTML
-func GetAuditTablesTemplate() (*template.Template, error) {
+func GetAuditTablesTemplate(thresholds *SlowThresholds) (*template.Template, error) {
 	html := headers + getContentHTML()
 	html += `{{$name := .Hatchet}}
 	<div style='margin: 5px 5px; width=100%; clear: left;'>
@@ -31,18 +32,30 @@ func GetAuditTablesTemplate() (*template.Template, error) {
 		</tr>
 	  </table>
 	</div>
+{{if hasData .Data "server"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/logs/all?component=CONTROL'); return false;">
+			<i class='fa fa-search'></i></button>Server Info</caption>
+		<tr><th>Name</th><th>Value</th></tr>
+	{{range $n, $val := index .Data "server"}}
+		<tr><td>{{$val.Name}}</td><td class='break'>{{index $val.Values 0}}</td></tr>
+	{{end}}
+	</table>
+{{end}}
+
 {{if hasData .Data "exception"}}
 	<table style='float: left; margin: 10px 10px; clear: left;'>
 		<caption><button class='btn'
 			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/logs/all?severity=W'); return false;">
 			<i class='fa fa-search'></i></button>Exceptions</caption>
-		<tr><th></th><th>Severity</th><th>Total</th></tr>
+		<tr><th></th><th>Severity</th><th>Total</th><th>%</th></tr>
 	{{range $n, $val := index .Data "exception"}}
 		<tr><td align=right>{{add $n 1}}</td>
 		<td>
 			<button class='btn' onClick="javascript:loadData('/hatchets/{{$name}}/logs/all?severity={{slice $val.Name 0 1}}'); return false;"><i class='fa fa-search'></i></button>{{$val.Name}}
 		</td>
-		<td align=right>{{getFormattedNumber $val.Values 0}}</td></tr>
+		<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getPercent (index $.Data "exception") $val 0}}</td></tr>
 	{{end}}
 	</table>
 {{end}}
@@ -72,13 +85,13 @@ func GetAuditTablesTemplate() (*template.Template, error) {
 		<caption><button class='btn'
 			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/logs/all?context=failed'); return false;">
 			<i class='fa fa-search'></i></button>Failed Operations</caption>
-		<tr><th></th><th>Failed Operation</th><th>Total</th></tr>
+		<tr><th></th><th>Failed Operation</th><th>Total</th><th>%</th></tr>
 	{{range $n, $val := index .Data "failed"}}
 		<tr><td align=right>{{add $n 1}}</td>
 			<td>
 				<button class='btn' onClick="javascript:loadData('/hatchets/{{$name}}/logs/all?context={{$val.Name}}'); return false;"><i class='fa fa-search'></i></button>{{$val.Name}}
 			</td>
-			<td align=right>{{getFormattedNumber $val.Values 0}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getPercent (index $.Data "failed") $val 0}}</td>
 		</tr>
 	{{end}}
 	</table>
@@ -89,13 +102,13 @@ func GetAuditTablesTemplate() (*template.Template, error) {
 		<caption><button class='btn'
 			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/connections?type=accepted'); return false;">
 			<i class='fa fa-pie-chart'></i></button>Stats by IPs</caption>
-		<tr><th></th><th>IP</th><th>Accepted Connections</th><th>Response Length</th></tr>
+		<tr><th></th><th>IP</th><th>Accepted Connections</th><th>%</th><th>Response Length</th><th>%</th></tr>
 	{{range $n, $val := index .Data "ip"}}
 		<tr><td align=right>{{add $n 1}}</td>
 		<td>
 			<button class='btn' onClick="javascript:loadData('/hatchets/{{$name}}/charts/reslen-ip?ip={{$val.Name}}'); return false;"><i class='fa fa-pie-chart'></i></button>{{$val.Name}}
 		</td>
-		<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getFormattedSize $val.Values 1}}</td></tr>
+		<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getPercent (index $.Data "ip") $val 0}}</td><td align=right>{{getFormattedSize $val.Values 1}}</td><td align=right>{{getPercent (index $.Data "ip") $val 1}}</td></tr>
 	{{end}}
 	</table>
 {{end}}
@@ -105,13 +118,13 @@ func GetAuditTablesTemplate() (*template.Template, error) {
 		<caption><button class='btn'
 			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/ops?type=stats'); return false;">
 			<i class='fa fa-area-chart'></i></button>Operations Stats</caption>
-		<tr><th></th><th>Operation</th><th>Total</th></tr>
+		<tr><th></th><th>Operation</th><th>Total</th><th>%</th></tr>
 	{{range $n, $val := index .Data "op"}}
 		<tr><td align=right>{{add $n 1}}</td>
 		<td>
 			<button class='btn' onClick="javascript:loadData('/hatchets/{{$name}}/charts/ops?type=stats&op={{$val.Name}}'); return false;"><i class='fa fa-area-chart'></i></button>{{$val.Name}}
 		</td>
-		<td align=right>{{getFormattedNumber $val.Values 0}}</td></tr>
+		<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getPercent (index $.Data "op") $val 0}}</td></tr>
 	{{end}}
 	</table>
 {{end}}
@@ -121,13 +134,403 @@ func GetAuditTablesTemplate() (*template.Template, error) {
 		<caption><button class='btn'
 			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/reslen-ns?ns='); return false;">
 			<i class='fa fa-pie-chart'></i></button>Stats by Namespaces</caption>
-		<tr><th></th><th>Namespace</th><th>Accessed</th><th>Response Length</th></tr>
+		<tr><th></th><th>Namespace</th><th>Accessed</th><th>%</th><th>Response Length</th><th>%</th></tr>
 	{{range $n, $val := index .Data "ns"}}
 		<tr><td align=right>{{add $n 1}}</td>
 		<td>
-			<button class='btn' onClick="javascript:loadData('/hatchets/{{$name}}/logs/all?context={{$val.Name}}'); return false;"><i class='fa fa-search'></i></button>{{$val.Name}}
+			<button class='btn' onClick="javascript:loadData('/hatchets/{{$name}}/logs/all?context={{$val.Name}}'); return false;"><i class='fa fa-search'></i></button><a href='/hatchets/{{$name}}/ns/{{$val.Name}}'>{{$val.Name}}</a>
 		</td>
-		<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getFormattedSize $val.Values 1}}</td></tr>
+		<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getPercent (index $.Data "ns") $val 0}}</td><td align=right>{{getFormattedSize $val.Values 1}}</td><td align=right>{{getPercent (index $.Data "ns") $val 1}}</td></tr>
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "collscans"}}
+	{{$collscan := index .Data "collscan"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/stats/slowops?COLLSCAN=true&orderBy=total_ms'); return false;">
+			<i class='fa fa-search'></i></button>Collection Scans</caption>
+		<tr><td colspan='8'>{{numPrinter (getAuditValue $collscan "count")}} slow ops with COLLSCAN took
+			{{getDurationFromMillis (getAuditValue $collscan "totalMilli")}} in total, worst offenders:</td></tr>
+		<tr><th></th><th>Namespace</th><th>Query Pattern</th><th>Ops</th><th>Count</th><th>Total</th><th>%</th><th>Max ms</th></tr>
+	{{range $n, $val := index .Data "collscans"}}
+		<tr><td align=right>{{add $n 1}}</td><td><a href='/hatchets/{{$.Hatchet}}/ns/{{$val.Name}}'>{{$val.Name}}</a></td>
+			<td class='break'>{{index $val.Values 0}}</td><td>{{index $val.Values 1}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 2}}</td>
+			<td align=right>{{getDurationFromMillis (index $val.Values 3)}}</td>
+			<td align=right>{{getRatioPercent (index $val.Values 3) (getAuditValue $collscan "totalMilli")}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 4}}</td>
+		</tr>
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "latency"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/stats/slowops?orderBy=p99_ms'); return false;">
+			<i class='fa fa-search'></i></button>Latency Percentiles</caption>
+		<tr><th></th><th>Op Namespace</th><th>Count</th><th>p50 ms</th><th>p95 ms</th><th>p99 ms</th><th>Max ms</th></tr>
+	{{range $n, $val := index .Data "latency"}}
+		{{if lt $n 10}}
+		<tr><td align=right>{{add $n 1}}</td><td class='break'>{{$val.Name}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getFormattedNumber $val.Values 1}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 2}}</td><td align=right>{{getFormattedNumber $val.Values 3}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 4}}</td>
+		</tr>
+		{{end}}
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "cursor-not-found"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/logs/search?q=CursorNotFound'); return false;">
+			<i class='fa fa-search'></i></button>Cursors Not Found</caption>
+		<tr><th></th><th>Namespace</th><th>CursorNotFound</th><th>Slow getMore</th></tr>
+	{{range $n, $val := index .Data "cursor-not-found"}}
+		<tr><td align=right>{{add $n 1}}</td>
+			<td>{{$val.Name}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getFormattedNumber $val.Values 1}}</td>
+		</tr>
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "cursor-timeout"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/logs/all?component=QUERY'); return false;">
+			<i class='fa fa-search'></i></button>Cursor Lifecycle</caption>
+		<tr><th></th><th>Namespace</th><th>Timed Out</th><th>Leaked noCursorTimeout</th><th>Killed</th><th>Warnings</th></tr>
+	{{range $n, $val := index .Data "cursor-timeout"}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td>
+		{{if isExcessiveTimeout $val.Values}}
+			<td align=right><mark>{{getFormattedNumber $val.Values 0}}</mark></td>
+		{{else}}
+			<td align=right>{{getFormattedNumber $val.Values 0}}</td>
+		{{end}}
+		{{if gt (index $val.Values 1) 0}}
+			<td align=right><mark>{{getFormattedNumber $val.Values 1}}</mark></td>
+		{{else}}
+			<td align=right>0</td>
+		{{end}}
+			<td align=right>{{getFormattedNumber $val.Values 2}}</td><td align=right>{{getFormattedNumber $val.Values 3}}</td>
+		</tr>
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "hotdoc"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><span style="font-size: 16px; padding: 5px 5px;"><i class="fa fa-fire"></i></span>Hot Documents</caption>
+		<tr><th></th><th>Document</th><th>Writes</th><th>Write Conflicts</th></tr>
+	{{range $n, $val := index .Data "hotdoc"}}
+		<tr><td align=right>{{add $n 1}}</td>
+			<td class='break'>{{$val.Name}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getFormattedNumber $val.Values 1}}</td>
+		</tr>
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "auth-ip"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/logs/all?component=ACCESS'); return false;">
+			<i class='fa fa-search'></i></button>Security</caption>
+		<tr><td colspan='4'>{{numPrinter (getTotal (index .Data "auth-ip") 0)}} failed authentications</td></tr>
+	{{if hasData .Data "auth-spike"}}
+		<tr><th></th><th colspan='2'>Spike (per minute)</th><th>Failures</th></tr>
+		{{range $n, $val := getRestarts (index .Data "auth-spike")}}
+		<tr><td align=right>{{add $n 1}}</td><td colspan='2'>{{$val.Name}}</td>
+			<td align=right><mark>{{getFormattedNumber $val.Values 0}}</mark></td></tr>
+		{{end}}
+	{{end}}
+		<tr><th></th><th colspan='2'>Source IP</th><th>Failures</th></tr>
+	{{range $n, $val := index .Data "auth-ip"}}
+		{{if lt $n 10}}
+		<tr><td align=right>{{add $n 1}}</td><td colspan='2'>{{$val.Name}} ({{index $val.Values 1}} users)</td>
+			<td align=right>{{getFormattedNumber $val.Values 0}}</td></tr>
+		{{end}}
+	{{end}}
+		<tr><th></th><th>User</th><th>SCRAM Failures</th><th>Failures</th></tr>
+	{{range $n, $val := index .Data "auth-user"}}
+		{{if lt $n 10}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td>
+		{{if isRepeatedAuth $val.Values}}
+			<td align=right><mark>{{getFormattedNumber $val.Values 1}}</mark></td>
+		{{else}}
+			<td align=right>{{getFormattedNumber $val.Values 1}}</td>
+		{{end}}
+			<td align=right>{{getFormattedNumber $val.Values 0}}</td></tr>
+		{{end}}
+	{{end}}
+		<tr><th></th><th colspan='2'>Error</th><th>Failures</th></tr>
+	{{range $n, $val := index .Data "auth-error"}}
+		<tr><td align=right>{{add $n 1}}</td><td colspan='2' class='break'>{{$val.Name}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 0}}</td></tr>
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "admission"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/tickets?type=wait'); return false;">
+			<i class='fa fa-area-chart'></i></button>Admission Control</caption>
+		<tr><td colspan='7'>{{len (index .Data "admission")}} minute(s) of flow control or ticket exhaustion, the highest latency first</td></tr>
+		<tr><th></th><th>Minute</th><th>Flow Control</th><th>Tickets</th><th>Waited (ms)</th><th>Slow Ops</th><th>Avg ms (% of avg)</th></tr>
+	{{range $n, $val := index .Data "admission"}}
+		{{if lt $n 10}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 1}}</td><td align=right>{{getFormattedNumber $val.Values 2}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 3}}</td><td align=right>{{getFormattedNumber $val.Values 4}}</td>
+		{{if isLatencySpike $val.Values}}
+			<td align=right><mark>{{getFormattedNumber $val.Values 5}} ({{index $val.Values 0}}%)</mark></td>
+		{{else}}
+			<td align=right>{{getFormattedNumber $val.Values 5}} ({{index $val.Values 0}}%)</td>
+		{{end}}
+		</tr>
+		{{end}}
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "conn-limit"}}
+	{{$max := index .Data "conn-limit-max"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/connections?type=time'); return false;">
+			<i class='fa fa-area-chart'></i></button>Connection Limits</caption>
+		<tr><td colspan='6'>Peak of {{numPrinter (getAuditValue $max "peak")}} open connections{{with getAuditValue $max "limit"}} of the limit of {{numPrinter .}}{{end}}{{if eq (getAuditValue $max "configured") 0}}{{if gt (getAuditValue $max "limit") 0}} when connections were refused{{end}}{{end}}, {{len (index .Data "conn-limit")}} minute(s) of warnings, the most refused first</td></tr>
+		<tr><th></th><th>Minute</th><th>Refused</th><th>Pool Exhausted</th><th>Open Files Errors</th><th>Open Connections</th></tr>
+	{{range $n, $val := index .Data "conn-limit"}}
+		{{if lt $n 10}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td>
+		{{if gt (index $val.Values 0) 0}}
+			<td align=right><mark>{{getFormattedNumber $val.Values 0}}</mark></td>
+		{{else}}
+			<td align=right>0</td>
+		{{end}}
+			<td align=right>{{getFormattedNumber $val.Values 1}}</td><td align=right>{{getFormattedNumber $val.Values 2}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 3}}</td>
+		</tr>
+		{{end}}
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "ttl"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/ttl?type=deletes'); return false;">
+			<i class='fa fa-area-chart'></i></button>TTL Deletes</caption>
+		<tr><th></th><th>Namespace</th><th>Docs Deleted</th><th>Passes</th><th>Docs/Pass</th><th>Total ms</th><th>Slow Op Latency</th></tr>
+	{{range $n, $val := index .Data "ttl"}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getFormattedNumber $val.Values 1}}</td>
+			<td align=right>{{getAverage (index $val.Values 0) (index $val.Values 1)}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 2}}</td>
+		{{if isTTLSpike $val.Values}}
+			<td align=right><mark>{{index $val.Values 3}}% of avg</mark></td>
+		{{else}}
+			<td align=right>{{index $val.Values 3}}% of avg</td>
+		{{end}}
+		</tr>
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "oplog"}}
+	{{$oplog := index .Data "oplog"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+	{{if hasData .Data "oplog-churn"}}
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/oplog?type=churn'); return false;">
+			<i class='fa fa-area-chart'></i></button>Oplog Churn</caption>
+	{{else}}
+		<caption><span style="font-size: 16px; padding: 5px 5px;"><i class="fa fa-refresh"></i></span>Oplog Churn</caption>
+	{{end}}
+		<tr><th>Metric</th><th>Value</th></tr>
+		<tr><td>Logged ops on local.oplog.rs</td><td align=right>{{numPrinter (getAuditValue $oplog "ops")}}</td></tr>
+		<tr><td>Oplog truncations</td><td align=right>{{numPrinter (getAuditValue $oplog "truncations")}}</td></tr>
+	{{with getAuditValue $oplog "size"}}
+		<tr><td>Oplog size</td><td align=right>{{getStorageSize .}}</td></tr>
+	{{end}}
+	{{with getAuditValue $oplog "interval"}}
+		<tr><td>Avg truncation interval</td><td align=right>{{getDurationFromSeconds .}}</td></tr>
+	{{end}}
+	{{with getAuditValue $oplog "window"}}
+		<tr><td>Estimated oplog window</td><td align=right>{{getDurationFromSeconds .}}</td></tr>
+	{{end}}
+	{{with getAuditValue $oplog "churn"}}
+		<tr><td>Churn per hour</td><td align=right>{{getStorageSize .}}</td></tr>
+	{{end}}
+	{{with getAuditValue $oplog "shrinking"}}
+		<tr><td>Truncation interval decrease</td><td align=right><mark>{{.}}%</mark></td></tr>
+	{{end}}
+	{{if hasData .Data "oplog-churn"}}
+		{{$min := getOplogMinWindow (index .Data "oplog-churn")}}
+		<tr><td>Min estimated oplog window, {{$min.Name}}h</td>
+		{{if isOplogWindowLow $min.Value}}
+			<td align=right><mark>{{getDurationFromSeconds $min.Value}}</mark></td></tr>
+		{{else}}
+			<td align=right>{{getDurationFromSeconds $min.Value}}</td></tr>
+		{{end}}
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "restart"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/connections?type=time'); return false;">
+			<i class='fa fa-area-chart'></i></button>Server Restarts</caption>
+		<tr><th></th><th>Restarted at</th><th>PID</th></tr>
+	{{range $n, $val := getRestarts (index .Data "restart")}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td><td align=right>{{index $val.Values 0}}</td></tr>
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "election"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/logs/all?component=ELECTION'); return false;">
+			<i class='fa fa-search'></i></button>Replica Set Timeline</caption>
+		<tr><th></th><th>Date</th><th>Event</th><th>State</th><th>Term</th><th>Reason</th></tr>
+	{{range $n, $val := index .Data "election"}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td><td>{{index $val.Values 0}}</td>
+		{{if isPrimaryChange (index $val.Values 1)}}
+			<td><mark>{{index $val.Values 1}}</mark></td>
+		{{else}}
+			<td>{{index $val.Values 1}}</td>
+		{{end}}
+			<td align=right>{{if gt (index $val.Values 2) 0}}{{index $val.Values 2}}{{end}}</td>
+			<td class='break'>{{index $val.Values 3}}</td>
+		</tr>
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "targeting"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/targeting'; return false;">
+			<i class='fa fa-crosshairs'></i></button>Query Targeting</caption>
+		<tr><th></th><th>Namespace</th><th>Op</th><th>Docs/Returned</th><th>Keys/Returned</th><th>Count</th><th>Query Pattern</th></tr>
+	{{range $n, $val := index .Data "targeting"}}
+		{{if lt $n 10}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td><td>{{index $val.Values 0}}</td>
+			<td align=right><mark>{{index $val.Values 2}}</mark></td><td align=right>{{index $val.Values 3}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 4}}</td><td class='break'>{{index $val.Values 1}}</td>
+		</tr>
+		{{end}}
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "getmore"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption>Initial Execution vs getMore Latency</caption>
+		<tr><th></th><th>Namespace</th><th>Op</th><th>Initial</th><th>Initial Avg ms</th><th>getMore</th><th>getMore Avg ms</th><th>Query Pattern</th></tr>
+	{{range $n, $val := index .Data "getmore"}}
+		{{if lt $n 10}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td><td>{{index $val.Values 0}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 2}}</td><td align=right>{{getFormattedNumber $val.Values 3}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 4}}</td>
+		{{if gt (index $val.Values 5) (index $val.Values 3)}}
+			<td align=right><mark>{{getFormattedNumber $val.Values 5}}</mark></td>
+		{{else}}
+			<td align=right>{{getFormattedNumber $val.Values 5}}</td>
+		{{end}}
+			<td class='break'>{{index $val.Values 1}}</td>
+		</tr>
+		{{end}}
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "sort"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption>Blocking Sorts and Spills to Disk</caption>
+		<tr><th></th><th>Namespace</th><th>Op</th><th>Sorts</th><th>Spills</th><th>Avg ms</th><th>Query Pattern</th><th>Sort</th></tr>
+	{{range $n, $val := index .Data "sort"}}
+		{{if lt $n 10}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td><td>{{index $val.Values 0}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 3}}</td>
+		{{if gt (index $val.Values 4) 0}}
+			<td align=right><mark>{{getFormattedNumber $val.Values 4}}</mark></td>
+		{{else}}
+			<td align=right>0</td>
+		{{end}}
+			<td align=right>{{getFormattedNumber $val.Values 5}}</td>
+			<td class='break'>{{index $val.Values 1}}</td><td class='break'>{{index $val.Values 2}}</td>
+		</tr>
+		{{end}}
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "config"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption>Configuration Changes</caption>
+		<tr><th></th><th>Date</th><th>Change</th><th>Name</th><th>Old Value</th><th>New Value</th></tr>
+	{{range $n, $val := index .Data "config"}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td><td>{{index $val.Values 0}}</td>
+			<td class='break'>{{index $val.Values 1}}</td><td class='break'>{{index $val.Values 2}}</td>
+			<td class='break'><mark>{{index $val.Values 3}}</mark></td>
+		</tr>
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "chunk-migration"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/chunks?type=migrations'); return false;">
+			<i class='fa fa-area-chart'></i></button>Chunk Migrations</caption>
+	{{with getAuditValue (index .Data "balancer") "rounds"}}
+		<tr><td colspan='7'>{{numPrinter .}} balancer round(s)</td></tr>
+	{{end}}
+		<tr><th></th><th>Namespace</th><th>Migrated</th><th>Failed</th><th>Splits</th><th>Avg ms</th><th>Max ms</th></tr>
+	{{range $n, $val := index .Data "chunk-migration"}}
+		{{if lt $n 10}}
+		<tr><td align=right>{{add $n 1}}</td><td>{{$val.Name}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 0}}</td>
+		{{if gt (index $val.Values 1) 0}}
+			<td align=right><mark>{{getFormattedNumber $val.Values 1}}</mark></td>
+		{{else}}
+			<td align=right>0</td>
+		{{end}}
+			<td align=right>{{getFormattedNumber $val.Values 2}}</td>
+			<td align=right>{{getAverage (index $val.Values 3) (index $val.Values 0)}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 4}}</td>
+		</tr>
+		{{end}}
+	{{end}}
+	</table>
+{{end}}
+
+{{if hasData .Data "transaction"}}
+	<table style='float: left; margin: 10px 10px; clear: left;'>
+		<caption><button class='btn'
+			onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/transactions?type=rate'); return false;">
+			<i class='fa fa-area-chart'></i></button>Transactions</caption>
+		<tr><th></th><th>Termination</th><th>Count</th><th>Avg ms</th><th>Max ms</th><th>Avg Ops</th></tr>
+	{{range $n, $val := index .Data "transaction"}}
+		<tr><td align=right>{{add $n 1}}</td>
+		{{if isTxnAbort $val.Name}}
+			<td><mark>{{$val.Name}}</mark></td>
+		{{else}}
+			<td>{{$val.Name}}</td>
+		{{end}}
+			<td align=right>{{getFormattedNumber $val.Values 0}}</td><td align=right>{{getFormattedNumber $val.Values 1}}</td>
+			<td align=right>{{getFormattedNumber $val.Values 2}}</td><td align=right>{{getFormattedNumber $val.Values 3}}</td>
+		</tr>
 	{{end}}
 	</table>
 {{end}}
@@ -156,6 +559,14 @@ func GetAuditTablesTemplate() (*template.Template, error) {
 		"hasData": func(data map[string][]NameValues, key string) bool {
 			return len(data[key]) > 0
 		},
+		"getAuditValue": func(docs []NameValues, name string) int {
+			for _, doc := range docs {
+				if doc.Name == name && len(doc.Values) > 0 {
+					return ToInt(doc.Values[0])
+				}
+			}
+			return 0
+		},
 		"numPrinter": func(n interface{}) string {
 			printer := message.NewPrinter(language.English)
 			return printer.Sprintf("%v", ToInt(n))
@@ -176,6 +587,58 @@ func GetAuditTablesTemplate() (*template.Template, error) {
 		"checkDriver": func(version string, values []interface{}) error {
 			return CheckDriverCompatibility(version, values[0].(string), values[1].(string))
 		},
+		"getRestarts": func(docs []NameValues) []NameValues {
+			restarts := append([]NameValues{}, docs...)
+			sort.Slice(restarts, func(i int, j int) bool {
+				return restarts[i].Name < restarts[j].Name
+			})
+			return restarts
+		},
+		"getTotal": func(docs []NameValues, i int) int {
+			total := 0
+			for _, doc := range docs {
+				if i < len(doc.Values) {
+					total += ToInt(doc.Values[i])
+				}
+			}
+			return total
+		},
+		"isExcessiveTimeout": func(values []interface{}) bool {
+			return len(values) > 0 && ToInt(values[0]) >= CURSOR_TIMEOUT_EXCESSIVE
+		},
+		"isLatencySpike": func(values []interface{}) bool {
+			return len(values) > 0 && ToInt(values[0]) >= ADMISSION_SPIKE_PERCENT
+		},
+		"isPrimaryChange": func(change interface{}) bool {
+			return strings.Contains(fmt.Sprintf("%v", change), "PRIMARY")
+		},
+		"isRepeatedAuth": func(values []interface{}) bool {
+			return len(values) > 1 && ToInt(values[1]) >= AUTH_REPEATED
+		},
+		"isTTLSpike": func(values []interface{}) bool {
+			return len(values) > 3 && ToInt(values[3]) >= ADMISSION_SPIKE_PERCENT
+		},
+		"isTxnAbort": func(name string) bool {
+			return strings.HasPrefix(name, TXN_ABORTED)
+		},
+		"getPercent": func(docs []NameValues, doc NameValues, i int) string {
+			total := 0
+			for _, d := range docs {
+				if i < len(d.Values) {
+					total += ToInt(d.Values[i])
+				}
+			}
+			if total == 0 || i >= len(doc.Values) {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f", 100*ToFloat64(doc.Values[i])/float64(total))
+		},
+		"getAverage": func(total interface{}, count interface{}) string {
+			if ToInt(count) == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f", float64(ToInt(total))/float64(ToInt(count)))
+		},
 		"getFormattedNumber": func(numbers []interface{}, i int) string {
 			printer := message.NewPrinter(language.English)
 			return printer.Sprintf("%v", numbers[i])
@@ -185,12 +648,28 @@ func GetAuditTablesTemplate() (*template.Template, error) {
 			randomNum := rand.Intn(2)
 			return (randomNum%2 == 0)
 		},
+		"getOplogMinWindow": func(docs []NameValues) NameValue {
+			hour, window := GetOplogMinWindow(docs)
+			return NameValue{hour, window}
+		},
+		"isOplogWindowLow": func(seconds int) bool {
+			return seconds > 0 && seconds < GetLogv2().GetOplogWindowThreshold()*3600
+		},
 		"getDurationFromSeconds": func(s int) string {
 			return gox.GetDurationFromSeconds(float64(s))
 		},
 		"getFormattedDuration": func(numbers []interface{}, i int) string {
 			return gox.GetDurationFromSeconds(float64(numbers[i].(int)))
 		},
+		"getDurationFromMillis": func(ms interface{}) string {
+			return gox.GetDurationFromSeconds(ToFloat64(ms) / 1000)
+		},
+		"getRatioPercent": func(value interface{}, total int) string {
+			if total == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f", 100*ToFloat64(value)/float64(total))
+		},
 		"getStorageSize": func(s int) string {
 			return gox.GetStorageSize(float64(s))
 		},
@@ -327,8 +806,8 @@ func GetAuditTablesTemplate() (*template.Template, error) {
 						} else if doc.Name == "avgMilli" && doc.Values[0].(int) > 0 {
 							milli := doc.Values[0].(int)
 							html += printer.Sprintf("Moreover, the average operation time was <span style='color: orange;'>%d</span> milliseconds", milli)
-							if milli > 100 {
-								html += `, where operation time <mark>greater than 100 milliseconds is, IMO, "slow"</mark>. `
+							if slowMilli := thresholds.Default; milli > slowMilli {
+								html += printer.Sprintf(`, where operation time <mark>greater than %d milliseconds is, IMO, "slow"</mark>. `, slowMilli)
 							} else {
 								html += ". "
 							}
@@ -343,6 +822,214 @@ func GetAuditTablesTemplate() (*template.Template, error) {
 							}
 						}
 					}
+				} else if key == "shapes" && len(docs) > 0 {
+					values := map[string]int{}
+					for _, doc := range docs {
+						values[doc.Name] = ToInt(doc.Values[0])
+					}
+					html += printer.Sprintf("<mark>The number of distinct query shapes reached the limit of %d and %d ops of new shapes were counted as %v</mark>, query normalization may need tuning. ",
+						values["max"], values["overflow"], SHAPE_OTHER)
+				} else if key == "restart" && len(docs) > 0 {
+					restarts := []string{}
+					for _, date := range GetRestarts(data, "", "") {
+						restarts = append(restarts, strings.Replace(date[:19], "T", " ", 1))
+					}
+					str := printer.Sprintf("The server restarted <span style='color: orange;'>%d</span> time(s) within the logs, at %v", len(docs), strings.Join(restarts, ", "))
+					if len(docs) >= RESTART_WARN {
+						html += "<mark>" + str + "</mark>, frequent restarts are a red flag. "
+					} else {
+						html += str + ". "
+					}
+					html += "Connection counts are reset across restarts. "
+				} else if key == "cursor-not-found" && len(docs) > 0 {
+					count := 0
+					for _, doc := range docs {
+						count += doc.Values[0].(int)
+					}
+					html += printer.Sprintf("Applications hit <mark><i>CursorNotFound</i> errors %d times</mark> on %d namespaces, ", count, len(docs))
+					html += "which usually means cursors were held idle longer than the server's cursor timeout while iterating. "
+				} else if key == "cursor-timeout" && len(docs) > 0 {
+					timedOut, leaked, excessive := 0, 0, 0
+					for _, doc := range docs {
+						timedOut += ToInt(doc.Values[0])
+						leaked += ToInt(doc.Values[1])
+						if ToInt(doc.Values[0]) >= CURSOR_TIMEOUT_EXCESSIVE {
+							excessive++
+						}
+					}
+					html += printer.Sprintf("<span style='color: orange;'>%d</span> cursor(s) timed out. ", timedOut)
+					if excessive > 0 {
+						html += printer.Sprintf("<mark>%d namespace(s) had at least %d cursor timeouts</mark>, applications may abandon cursors without exhausting or closing them. ",
+							excessive, CURSOR_TIMEOUT_EXCESSIVE)
+					}
+					if leaked > 0 {
+						html += printer.Sprintf("<mark>%d noCursorTimeout cursor(s) were never exhausted or killed</mark>, these cursors hold resources until the server restarts. ", leaked)
+					}
+				} else if key == "auth-ip" && len(docs) > 0 {
+					failures := 0
+					for _, doc := range docs {
+						failures += ToInt(doc.Values[0])
+					}
+					html += printer.Sprintf("There were <span style='color: orange;'>%d</span> failed authentications from %d source IPs, ", failures, len(docs))
+					html += printer.Sprintf("the most from %v (%d). ", template.HTMLEscapeString(docs[0].Name), docs[0].Values[0])
+					if spikes := data["auth-spike"]; len(spikes) > 0 {
+						html += printer.Sprintf("<mark>Failed authentications spiked in %d minute(s)</mark>, which may be a misconfigured client or a brute force attempt. ", len(spikes))
+					}
+					repeated := 0
+					for _, doc := range data["auth-user"] {
+						if len(doc.Values) > 1 && ToInt(doc.Values[1]) >= AUTH_REPEATED {
+							repeated++
+						}
+					}
+					if repeated > 0 {
+						html += printer.Sprintf("<mark>%d user(s) failed SCRAM authentication repeatedly</mark>, check their passwords in application configurations. ", repeated)
+					}
+				} else if key == "admission" && len(docs) > 0 {
+					flowControl, tickets, spikes := 0, 0, 0
+					for _, doc := range docs {
+						if ToInt(doc.Values[1]) > 0 {
+							flowControl++
+						}
+						if ToInt(doc.Values[2]) > 0 {
+							tickets++
+						}
+						if ToInt(doc.Values[0]) >= ADMISSION_SPIKE_PERCENT {
+							spikes++
+						}
+					}
+					html += printer.Sprintf("Flow control was engaged in <span style='color: orange;'>%d</span> minute(s) and ops queued for read/write tickets in <span style='color: orange;'>%d</span> minute(s). ", flowControl, tickets)
+					if spikes > 0 {
+						html += printer.Sprintf("<mark>Slow ops were at least %dx slower than average in %d of these minutes</mark>, stalls may be from admission control, i.e. lagging secondaries or exhausted tickets, rather than the queries themselves. ",
+							ADMISSION_SPIKE_PERCENT/100, spikes)
+					}
+				} else if key == "conn-limit" && len(docs) > 0 {
+					refused, pool, files := 0, 0, 0
+					for _, doc := range docs {
+						refused += ToInt(doc.Values[0])
+						pool += ToInt(doc.Values[1])
+						files += ToInt(doc.Values[2])
+					}
+					values := map[string]int{}
+					for _, doc := range data["conn-limit-max"] {
+						values[doc.Name] = ToInt(doc.Values[0])
+					}
+					if refused > 0 {
+						html += printer.Sprintf("<mark>The server refused %d connection(s) because of too many open connections</mark>, with a peak of %d open connections, applications may leak connections or size their pools too large. ",
+							refused, values["peak"])
+					} else if values["limit"] > 0 && 100*values["peak"] >= CONN_LIMIT_WARN_PERCENT*values["limit"] {
+						html += printer.Sprintf("Open connections peaked at <span style='color: orange;'>%d</span>, %d%% of the connection limit of %d. ",
+							values["peak"], 100*values["peak"]/values["limit"], values["limit"])
+					}
+					if pool > 0 {
+						html += printer.Sprintf("Connection pools were exhausted <span style='color: orange;'>%d</span> time(s). ", pool)
+					}
+					if files > 0 {
+						html += printer.Sprintf("<mark>%d too many open files error(s) were logged</mark>, check the open files limit of the server. ", files)
+					}
+				} else if key == "chunk-migration" && len(docs) > 0 {
+					migrations, failed := 0, 0
+					for _, doc := range docs {
+						migrations += ToInt(doc.Values[0])
+						failed += ToInt(doc.Values[1])
+					}
+					html += printer.Sprintf("<span style='color: orange;'>%d</span> chunk(s) of %d collection(s) were migrated, the most of %v (%d). ",
+						migrations, len(docs), template.HTMLEscapeString(docs[0].Name), docs[0].Values[0])
+					if failed > 0 {
+						html += printer.Sprintf("<mark>%d chunk migration(s) failed</mark>, check moveChunk errors of the SHARDING component. ", failed)
+					}
+				} else if key == "transaction" && len(docs) > 0 {
+					total, aborted := 0, 0
+					cause, most := "", 0
+					for _, doc := range docs {
+						count := ToInt(doc.Values[0])
+						total += count
+						if strings.HasPrefix(doc.Name, TXN_ABORTED) {
+							aborted += count
+							if count > most {
+								cause, most = doc.Name, count
+							}
+						}
+					}
+					html += printer.Sprintf("<span style='color: orange;'>%d</span> multi-document transaction(s) were logged, %d aborted. ", total, aborted)
+					if aborted > 0 {
+						html += printer.Sprintf("<mark>%.1f%% of transactions aborted</mark>, the most %v (%d), retry transient errors and keep transactions short. ",
+							100*float64(aborted)/float64(total), template.HTMLEscapeString(cause), most)
+					}
+				} else if key == "ttl" && len(docs) > 0 {
+					deleted, passes, spikes := 0, 0, 0
+					for _, doc := range docs {
+						deleted += ToInt(doc.Values[0])
+						passes += ToInt(doc.Values[1])
+						if ToInt(doc.Values[3]) >= ADMISSION_SPIKE_PERCENT {
+							spikes++
+						}
+					}
+					html += printer.Sprintf("TTL indexes deleted <span style='color: orange;'>%d</span> documents of %d namespace(s) in %d passes, the most from %v. ",
+						deleted, len(docs), passes, template.HTMLEscapeString(docs[0].Name))
+					if spikes > 0 {
+						html += printer.Sprintf("<mark>Slow ops were at least %dx slower than average in minutes of TTL deletes of %d namespace(s)</mark>, consider spreading expiration times or deleting in batches off-peak. ",
+							ADMISSION_SPIKE_PERCENT/100, spikes)
+					}
+				} else if key == "targeting" && len(docs) > 0 {
+					html += printer.Sprintf("<mark>%d query shape(s) examined more than %d keys or documents per document returned</mark>, the worst of %v, see query targeting for missing or unselective indexes. ",
+						len(docs), GetLogv2().GetTargetingRatio(), template.HTMLEscapeString(docs[0].Name))
+				} else if key == "getmore" && len(docs) > 0 {
+					slower := 0
+					for _, doc := range docs {
+						if ToInt(doc.Values[5]) > ToInt(doc.Values[3]) {
+							slower++
+						}
+					}
+					html += printer.Sprintf("Slow getMores iterated cursors of <span style='color: orange;'>%d</span> query shape(s), the most time spent on %v. ",
+						len(docs), template.HTMLEscapeString(docs[0].Name))
+					if slower > 0 {
+						html += printer.Sprintf("<mark>Batches of %d shape(s) were slower on average than their initial executions</mark>, consider smaller batch sizes or indexes supporting the sort to avoid slow iteration. ", slower)
+					}
+				} else if key == "sort" && len(docs) > 0 {
+					sorts, spills := 0, 0
+					for _, doc := range docs {
+						sorts += ToInt(doc.Values[3])
+						spills += ToInt(doc.Values[4])
+					}
+					html += printer.Sprintf("There were <span style='color: orange;'>%d</span> in-memory blocking sorts and <span style='color: orange;'>%d</span> spills to disk of %d query shape(s), ",
+						sorts, spills, len(docs))
+					html += printer.Sprintf("<mark>consider indexes supporting the sort of %v</mark>. ", template.HTMLEscapeString(docs[0].Name))
+				} else if key == "config" && len(docs) > 0 {
+					html += printer.Sprintf("<mark>%d runtime configuration change(s)</mark> were logged, see configuration changes to correlate behavior changes with them. ", len(docs))
+				} else if key == "election" && len(docs) > 0 {
+					elected, steppedDown := 0, 0
+					for _, doc := range docs {
+						change := fmt.Sprintf("%v", doc.Values[1])
+						if strings.HasSuffix(change, "PRIMARY") {
+							elected++
+						} else if strings.HasPrefix(change, "PRIMARY") {
+							steppedDown++
+						}
+					}
+					if elected+steppedDown > 0 {
+						html += printer.Sprintf("<mark>The member became primary %d time(s) and stepped down %d time(s)</mark>, see the replica set timeline for when and why. ", elected, steppedDown)
+					}
+				} else if key == "hotdoc" && len(docs) > 0 {
+					html += printer.Sprintf("There were <span style='color: orange;'>%d</span> documents updated repeatedly by their <i>_id</i>, ", len(docs))
+					html += printer.Sprintf("and the hottest one, <mark>%v</mark>, was written <span style='color: orange;'>%d</span> times. ", template.HTMLEscapeString(docs[0].Name), docs[0].Values[0])
+				} else if key == "oplog" && len(docs) > 0 {
+					values := map[string]int{}
+					for _, doc := range docs {
+						values[doc.Name] = ToInt(doc.Values[0])
+					}
+					if values["window"] > 0 {
+						html += printer.Sprintf("Judging from oplog truncations, the oplog window was about <span style='color: orange;'>%s</span>. ",
+							gox.GetDurationFromSeconds(float64(values["window"])))
+					}
+					if values["shrinking"] >= OPLOG_SHRINK_WARN {
+						html += printer.Sprintf("<mark>The time between oplog truncations decreased by %d%% toward the end of the log, the effective oplog window is shrinking</mark> and secondaries may fall off the oplog. ",
+							values["shrinking"])
+					}
+					threshold := GetLogv2().GetOplogWindowThreshold()
+					if hour, window := GetOplogMinWindow(data["oplog-churn"]); window > 0 && window < threshold*3600 {
+						html += printer.Sprintf("<mark>The estimated oplog window dropped to %s at %sh, below %d hours</mark>, a secondary offline or lagging longer than the window needs an initial sync. ",
+							gox.GetDurationFromSeconds(float64(window)), strings.Replace(hour, "T", " ", 1), threshold)
+					}
 				} else if key == "collscan" && len(docs) > 0 {
 					html += "Let's move to the performance evaluation. "
 					for _, doc := range docs {
@@ -0,0 +1,386 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * auth.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// authentication modes of the web server
+const (
+	AUTH_BASIC = "basic"
+	AUTH_OIDC  = "oidc"
+)
+
+// cookies of OIDC logins and sessions
+const (
+	AUTH_SESSION_COOKIE = "hatchet_session"
+	AUTH_STATE_COOKIE   = "hatchet_oidc_state"
+)
+
+// AUTH_UNSUPPORTED_HASHES are prefixes of password hashes other than bcrypt,
+// e.g. of htpasswd -m, -s, and crypt, rejected instead of compared as plain
+var AUTH_UNSUPPORTED_HASHES = []string{"$apr1$", "$1$", "$5$", "$6$", "{SHA}"}
+
+// AUTH_SESSION_TTL is how long an OIDC session lasts before signing in again
+const AUTH_SESSION_TTL = 12 * time.Hour
+
+// AUTH_MAX_PENDING caps OIDC logins not called back, the oldest is evicted
+// so that anonymous requests don't grow them without limit
+const AUTH_MAX_PENDING = 1000
+
+// AuthOptions are settings of authentication of the web server
+type AuthOptions struct {
+	Allowed      string // comma separated emails and @domains of OIDC users, all if empty
+	ClientID     string
+	ClientSecret string
+	Issuer       string
+	Mode         string // basic or oidc
+	RedirectURL  string // callback URL registered with the OIDC provider
+	Users        string // comma separated user:password, bcrypt hashes allowed, or @{htpasswd file}
+}
+
+// Auth authenticates requests to the web UI and APIs by basic auth or by
+// OIDC sessions and bearer ID tokens
+type Auth struct {
+	allowed     []string
+	mode        string
+	mutex       sync.Mutex
+	oidc        *OIDCProvider
+	pending     map[string]authLogin // OIDC logins by state
+	redirectURL *url.URL
+	sessions    map[string]authSession
+	users       map[string]string
+}
+
+type authLogin struct {
+	expires time.Time
+	nonce   string
+	target  string // URL requested before signing in
+}
+
+type authSession struct {
+	expires time.Time
+	user    string
+}
+
+// NewAuth returns Auth of a mode, discovering endpoints of the OIDC issuer
+func NewAuth(opts AuthOptions) (*Auth, error) {
+	var err error
+	auth := &Auth{mode: opts.Mode, pending: map[string]authLogin{}, sessions: map[string]authSession{}}
+	if opts.Mode == AUTH_BASIC {
+		if auth.users, err = ParseAuthUsers(opts.Users); err != nil {
+			return nil, err
+		} else if len(auth.users) == 0 {
+			return nil, errors.New("basic auth requires -auth-users, user:password or @{htpasswd file}")
+		}
+		return auth, nil
+	} else if opts.Mode != AUTH_OIDC {
+		return nil, fmt.Errorf("unsupported auth %v, use basic or oidc", opts.Mode)
+	}
+	if opts.Issuer == "" || opts.ClientID == "" || opts.RedirectURL == "" {
+		return nil, errors.New("oidc auth requires -oidc-issuer, -oidc-client-id, and -oidc-redirect-url")
+	}
+	if auth.redirectURL, err = url.Parse(opts.RedirectURL); err != nil {
+		return nil, err
+	}
+	for _, allowed := range strings.Split(opts.Allowed, ",") {
+		if allowed = strings.ToLower(strings.TrimSpace(allowed)); allowed != "" {
+			auth.allowed = append(auth.allowed, allowed)
+		}
+	}
+	if auth.oidc, err = NewOIDCProvider(opts.Issuer, opts.ClientID, opts.ClientSecret); err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+// ParseAuthUsers returns passwords by user of comma separated user:password,
+// or of lines of an htpasswd file of bcrypt hashes if prefixed with @.  Only
+// bcrypt hashes are supported, other hashes and plain passwords of an
+// htpasswd file are rejected
+func ParseAuthUsers(users string) (map[string]string, error) {
+	passwords := map[string]string{}
+	lines := strings.Split(users, ",")
+	htpasswd := strings.HasPrefix(users, "@")
+	if htpasswd {
+		file, err := os.Open(users[1:])
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		lines = []string{}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		if err = scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		toks := strings.SplitN(line, ":", 2)
+		if len(toks) != 2 || toks[0] == "" || toks[1] == "" {
+			return nil, fmt.Errorf("invalid user %q, expected user:password", toks[0])
+		}
+		if strings.HasPrefix(toks[1], "$2") {
+			if _, err := bcrypt.Cost([]byte(toks[1])); err != nil {
+				return nil, fmt.Errorf("invalid bcrypt hash of user %q: %v", toks[0], err)
+			}
+		} else if htpasswd || isUnsupportedHash(toks[1]) {
+			return nil, fmt.Errorf("unsupported password of user %q, only bcrypt hashes, e.g. of htpasswd -B, are supported", toks[0])
+		}
+		passwords[toks[0]] = toks[1]
+	}
+	return passwords, nil
+}
+
+// Handler returns a handler authenticating requests before next
+func (ptr *Auth) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/favicon.ico" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if ptr.mode == AUTH_BASIC {
+			user, password, ok := r.BasicAuth()
+			if ok && ptr.checkPassword(user, password) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="hatchet", charset="UTF-8"`)
+			ptr.unauthorized(w, r)
+			return
+		}
+		if r.URL.Path == ptr.redirectURL.Path {
+			ptr.callback(w, r)
+			return
+		} else if r.URL.Path == "/auth/logout" {
+			ptr.logout(w, r)
+			return
+		}
+		if user, err := ptr.getUser(r); err == nil {
+			next.ServeHTTP(w, r)
+			return
+		} else if r.Header.Get("Authorization") != "" || isAPIRequest(r) {
+			if GetLogv2().verbose {
+				log.Println("auth", r.URL.Path, user, err)
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="hatchet"`)
+			ptr.unauthorized(w, r)
+			return
+		}
+		ptr.login(w, r)
+	})
+}
+
+// isUnsupportedHash returns true if a password is a hash other than bcrypt
+func isUnsupportedHash(password string) bool {
+	for _, prefix := range AUTH_UNSUPPORTED_HASHES {
+		if strings.HasPrefix(password, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPassword compares a password to the plain or bcrypt hashed password
+// of a user
+func (ptr *Auth) checkPassword(user string, password string) bool {
+	expected, ok := ptr.users[user]
+	if !ok {
+		return false
+	} else if strings.HasPrefix(expected, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(expected), []byte(password)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(password)) == 1
+}
+
+// getUser returns the user of a session cookie or a bearer ID token
+func (ptr *Auth) getUser(r *http.Request) (string, error) {
+	if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); token != r.Header.Get("Authorization") {
+		claims, err := ptr.oidc.Verify(token, "")
+		if err != nil {
+			return "", err
+		}
+		return ptr.getAllowedUser(claims)
+	}
+	cookie, err := r.Cookie(AUTH_SESSION_COOKIE)
+	if err != nil {
+		return "", errors.New("not signed in")
+	}
+	ptr.mutex.Lock()
+	defer ptr.mutex.Unlock()
+	session, ok := ptr.sessions[cookie.Value]
+	if !ok || time.Now().After(session.expires) {
+		delete(ptr.sessions, cookie.Value)
+		return "", errors.New("session expired")
+	}
+	return session.user, nil
+}
+
+// getAllowedUser returns the email, or the subject if no email, of claims
+// of an ID token if allowed
+func (ptr *Auth) getAllowedUser(claims map[string]interface{}) (string, error) {
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		email = ""
+	}
+	email = strings.ToLower(email)
+	if len(ptr.allowed) == 0 {
+		if email != "" {
+			return email, nil
+		}
+		return subject, nil
+	}
+	for _, allowed := range ptr.allowed {
+		if (email != "" && (allowed == email || (strings.HasPrefix(allowed, "@") && strings.HasSuffix(email, allowed)))) ||
+			allowed == strings.ToLower(subject) {
+			if email != "" {
+				return email, nil
+			}
+			return subject, nil
+		}
+	}
+	return "", fmt.Errorf("user %v not allowed", email+subject)
+}
+
+// login redirects to the authorization endpoint of the OIDC provider
+func (ptr *Auth) login(w http.ResponseWriter, r *http.Request) {
+	state, nonce := getRandomHex(16), getRandomHex(16)
+	ptr.mutex.Lock()
+	oldest := ""
+	for key, pending := range ptr.pending {
+		if time.Now().After(pending.expires) {
+			delete(ptr.pending, key)
+		} else if oldest == "" || pending.expires.Before(ptr.pending[oldest].expires) {
+			oldest = key
+		}
+	}
+	if len(ptr.pending) >= AUTH_MAX_PENDING {
+		delete(ptr.pending, oldest)
+	}
+	ptr.pending[state] = authLogin{expires: time.Now().Add(10 * time.Minute), nonce: nonce, target: r.URL.RequestURI()}
+	ptr.mutex.Unlock()
+	http.SetCookie(w, &http.Cookie{Name: AUTH_STATE_COOKIE, Value: state, Path: "/", MaxAge: 600, HttpOnly: true,
+		Secure: ptr.redirectURL.Scheme == "https", SameSite: http.SameSiteLaxMode})
+	http.Redirect(w, r, ptr.oidc.GetAuthURL(ptr.redirectURL.String(), state, nonce), http.StatusFound)
+}
+
+// callback exchanges the code of the OIDC provider for an ID token and
+// starts a session
+func (ptr *Auth) callback(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+	cookie, err := r.Cookie(AUTH_STATE_COOKIE)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		http.Error(w, "invalid state of the login", http.StatusBadRequest)
+		return
+	}
+	ptr.mutex.Lock()
+	pending, ok := ptr.pending[state]
+	delete(ptr.pending, state)
+	ptr.mutex.Unlock()
+	if !ok || time.Now().After(pending.expires) {
+		http.Error(w, "login expired, try again", http.StatusBadRequest)
+		return
+	} else if msg := r.URL.Query().Get("error"); msg != "" {
+		http.Error(w, "login failed, "+msg+" "+r.URL.Query().Get("error_description"), http.StatusUnauthorized)
+		return
+	}
+	token, err := ptr.oidc.Exchange(r.URL.Query().Get("code"), ptr.redirectURL.String())
+	if err != nil {
+		log.Println("auth", err)
+		http.Error(w, "login failed, "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	claims, err := ptr.oidc.Verify(token, pending.nonce)
+	if err != nil {
+		log.Println("auth", err)
+		http.Error(w, "login failed, "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	user, err := ptr.getAllowedUser(claims)
+	if err != nil {
+		log.Println("auth", err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	id := getRandomHex(32)
+	ptr.mutex.Lock()
+	for key, session := range ptr.sessions {
+		if time.Now().After(session.expires) {
+			delete(ptr.sessions, key)
+		}
+	}
+	ptr.sessions[id] = authSession{expires: time.Now().Add(AUTH_SESSION_TTL), user: user}
+	ptr.mutex.Unlock()
+	log.Println("auth", user, "signed in")
+	http.SetCookie(w, &http.Cookie{Name: AUTH_STATE_COOKIE, Value: "", Path: "/", MaxAge: -1})
+	http.SetCookie(w, &http.Cookie{Name: AUTH_SESSION_COOKIE, Value: id, Path: "/", MaxAge: int(AUTH_SESSION_TTL.Seconds()),
+		HttpOnly: true, Secure: ptr.redirectURL.Scheme == "https", SameSite: http.SameSiteLaxMode})
+	target := pending.target
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		target = "/"
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// logout ends the session
+func (ptr *Auth) logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(AUTH_SESSION_COOKIE); err == nil {
+		ptr.mutex.Lock()
+		delete(ptr.sessions, cookie.Value)
+		ptr.mutex.Unlock()
+	}
+	http.SetCookie(w, &http.Cookie{Name: AUTH_SESSION_COOKIE, Value: "", Path: "/", MaxAge: -1})
+	fmt.Fprintln(w, "signed out")
+}
+
+// unauthorized responds with 401, in JSON of APIs
+func (ptr *Auth) unauthorized(w http.ResponseWriter, r *http.Request) {
+	if isAPIRequest(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": "unauthorized"})
+		return
+	}
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// isAPIRequest returns true of APIs and metrics, which are not redirected to
+// sign in
+func isAPIRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/metrics" || r.Method != http.MethodGet
+}
+
+// getRandomHex returns hex of n random bytes
+func getRandomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
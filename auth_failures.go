@@ -0,0 +1,96 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * auth_failures.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// AUTH_REPEATED is the SCRAM failures of a user to flag repeated failures
+	AUTH_REPEATED = 3
+	// AUTH_SPIKE is the failed authentications in a minute to flag a spike
+	AUTH_SPIKE = 5
+)
+
+// AuthFailures counts failed authentications by source IP, user, error, and
+// minute
+type AuthFailures struct {
+	Errors  map[string]int // mechanism and error
+	IPs     map[string]int
+	IPUsers map[string]map[string]bool // distinct users tried from an IP
+	Minutes map[string]int
+	SCRAM   map[string]int // SCRAM failures of users
+	Users   map[string]int // user@db
+}
+
+// NewAuthFailures returns AuthFailures
+func NewAuthFailures() *AuthFailures {
+	return &AuthFailures{Errors: map[string]int{}, IPs: map[string]int{}, IPUsers: map[string]map[string]bool{},
+		Minutes: map[string]int{}, SCRAM: map[string]int{}, Users: map[string]int{}}
+}
+
+// Add counts a failed authentication of ACCESS
+func (ptr *AuthFailures) Add(doc *Logv2Info, date string) {
+	if doc.Component != "ACCESS" || doc.Msg != "Authentication failed" {
+		return
+	}
+	attr := doc.Attr.Map()
+	mechanism, _ := attr["mechanism"].(string)
+	user, _ := attr["principalName"].(string)
+	if db, ok := attr["authenticationDatabase"].(string); ok && db != "" {
+		user += "@" + db
+	}
+	ip := "-"
+	if remote, ok := attr["remote"].(string); ok && remote != "" {
+		ip = strings.Split(remote, ":")[0]
+	}
+	errmsg := fmt.Sprintf("%v", attr["error"])
+	if attr["error"] == nil {
+		errmsg = "unknown error"
+	}
+	ptr.Errors[strings.TrimSpace(mechanism+" "+errmsg)]++
+	ptr.IPs[ip]++
+	if ptr.IPUsers[ip] == nil {
+		ptr.IPUsers[ip] = map[string]bool{}
+	}
+	ptr.IPUsers[ip][user] = true
+	if len(date) >= 16 {
+		ptr.Minutes[date[:16]]++
+	}
+	if strings.HasPrefix(mechanism, "SCRAM") {
+		ptr.SCRAM[user]++
+	}
+	ptr.Users[user]++
+}
+
+// GetAuditData returns audit data by type, failures and distinct users of
+// source IPs, failures and SCRAM failures of users, failures by error, and
+// minutes of at least AUTH_SPIKE failures
+func (ptr *AuthFailures) GetAuditData() map[string][]NameValue {
+	data := map[string][]NameValue{}
+	if len(ptr.IPs) == 0 {
+		return data
+	}
+	for ip, count := range ptr.IPs {
+		data["auth-ip"] = append(data["auth-ip"], NameValue{ip, count})
+		data["auth-ip-users"] = append(data["auth-ip-users"], NameValue{ip, len(ptr.IPUsers[ip])})
+	}
+	for user, count := range ptr.Users {
+		data["auth-user"] = append(data["auth-user"], NameValue{user, count})
+		data["auth-user-scram"] = append(data["auth-user-scram"], NameValue{user, ptr.SCRAM[user]})
+	}
+	for errmsg, count := range ptr.Errors {
+		data["auth-error"] = append(data["auth-error"], NameValue{errmsg, count})
+	}
+	for minute, count := range ptr.Minutes {
+		if count >= AUTH_SPIKE {
+			data["auth-spike"] = append(data["auth-spike"], NameValue{minute, count})
+		}
+	}
+	return data
+}
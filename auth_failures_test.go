@@ -0,0 +1,47 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * auth_failures_test.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAuthFailures(t *testing.T) {
+	auths := NewAuthFailures()
+	for i := 0; i < 6; i++ {
+		str := fmt.Sprintf(`{"t":{"$date":"2023-01-01T00:01:0%d.000+00:00"},"s":"I","c":"ACCESS","id":20249,"ctx":"conn%d","msg":"Authentication failed","attr":{"mechanism":"SCRAM-SHA-256","principalName":"app","authenticationDatabase":"admin","remote":"10.9.9.9:5000%d","error":"AuthenticationFailed: SCRAM authentication failed, storedKey mismatch"}}`, i, i, i)
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		auths.Add(&doc, getDateTimeStr(doc.Timestamp))
+	}
+	var doc Logv2Info
+	str := `2020-03-01T10:00:01.000+0000 I  ACCESS   [conn9] SASL SCRAM-SHA-1 authentication failed for bob on admin from client 10.0.0.7:53960 ; AuthenticationFailed: SCRAM authentication failed, storedKey mismatch`
+	if err := ParseLegacyLog(str, nil, &doc); err != nil {
+		t.Fatal(err)
+	}
+	auths.Add(&doc, getDateTimeStr(doc.Timestamp))
+
+	data := auths.GetAuditData()
+	docs := MergeAuditSeries([]string{"auth-ip", "auth-ip-users"}, data)
+	if len(docs) != 2 || docs[0].Name != "10.9.9.9" || docs[0].Values[0] != 6 || docs[0].Values[1] != 1 {
+		t.Fatal("expected", "6 failures of 1 user from 10.9.9.9", "but got", docs)
+	}
+	docs = MergeAuditSeries([]string{"auth-user", "auth-user-scram"}, data)
+	if len(docs) != 2 || docs[1].Name != "bob@admin" || docs[1].Values[1] != 1 {
+		t.Fatal("expected", "a SCRAM failure of bob@admin", "but got", docs)
+	}
+	if spikes := data["auth-spike"]; len(spikes) != 1 || spikes[0].Name != "2023-01-01T00:01" {
+		t.Fatal("expected", "a spike at 2023-01-01T00:01", "but got", spikes)
+	}
+	if len(data["auth-error"]) != 2 {
+		t.Fatal("expected", 2, "but got", data["auth-error"])
+	}
+}
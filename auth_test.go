@@ -0,0 +1,288 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * auth_test.go
+ */
+
+package hatchet
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var authOKHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+})
+
+func TestAuthBasic(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := NewAuth(AuthOptions{Mode: AUTH_BASIC, Users: "alice:wonderland,bob:" + string(hash)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := auth.Handler(authOKHandler)
+	tests := []struct {
+		path     string
+		user     string
+		password string
+		code     int
+	}{
+		{"/", "alice", "wonderland", http.StatusOK},
+		{"/", "bob", "secret", http.StatusOK},
+		{"/", "bob", "wonderland", http.StatusUnauthorized},
+		{"/", "", "", http.StatusUnauthorized},
+		{"/api/openapi.json", "carol", "secret", http.StatusUnauthorized},
+		{"/favicon.ico", "", "", http.StatusOK},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if test.user != "" {
+			req.SetBasicAuth(test.user, test.password)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != test.code {
+			t.Fatal("expected", test.code, "but got", w.Code, test)
+		}
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Fatal("expected", "WWW-Authenticate", "but got", w.Header())
+		}
+	}
+
+	if _, err = NewAuth(AuthOptions{Mode: AUTH_BASIC}); err == nil {
+		t.Fatal("expected", "error of no users", "but got", err)
+	}
+	if _, err = ParseAuthUsers("alice"); err == nil {
+		t.Fatal("expected", "error of no password", "but got", err)
+	}
+}
+
+func TestParseAuthUsersHtpasswd(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "hatchet.htpasswd")
+	if err = os.WriteFile(filename, []byte("# users\nbob:"+string(hash)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	users, err := ParseAuthUsers("@" + filename)
+	if err != nil || users["bob"] != string(hash) {
+		t.Fatal("expected", string(hash), "but got", users, err)
+	}
+
+	// hashes other than bcrypt and plain passwords of htpasswd files
+	for _, line := range []string{"bob:$apr1$r31.....$HqJZimcKQFAMYayBlzkrA/", "bob:{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=",
+		"bob:rqXexS6ZhobKA", "bob:secret", "bob:$2y$05$invalid"} {
+		if err = os.WriteFile(filename, []byte(line+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = ParseAuthUsers("@" + filename); err == nil {
+			t.Fatal("expected", "error of", line, "but got", err)
+		}
+	}
+	if _, err = ParseAuthUsers("bob:$apr1$r31.....$HqJZimcKQFAMYayBlzkrA/"); err == nil {
+		t.Fatal("expected", "error of an apr1 hash", "but got", err)
+	}
+}
+
+// oidcTestProvider is an OIDC provider issuing ID tokens signed by an RSA key
+type oidcTestProvider struct {
+	key    *rsa.PrivateKey
+	nonce  string
+	server *httptest.Server
+}
+
+func newOIDCTestProvider(t *testing.T) *oidcTestProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := &oidcTestProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": provider.server.URL,
+			"authorization_endpoint": provider.server.URL + "/authorize",
+			"jwks_uri":               provider.server.URL + "/jwks", "token_endpoint": provider.server.URL + "/token"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{"kty": "RSA", "kid": "k1", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, _ := r.BasicAuth(); id != "hatchet" || secret != "s3cret" || r.FormValue("code") != "code1" {
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": provider.sign(t, map[string]interface{}{
+			"email": "ken@example.com", "nonce": provider.nonce})})
+	})
+	provider.server = httptest.NewServer(mux)
+	return provider
+}
+
+// sign returns an ID token of claims merged with valid iss, aud, and exp
+func (ptr *oidcTestProvider) sign(t *testing.T, claims map[string]interface{}) string {
+	doc := map[string]interface{}{"iss": ptr.server.URL, "aud": "hatchet", "sub": "1234",
+		"exp": time.Now().Add(time.Hour).Unix()}
+	for k, v := range claims {
+		doc[k] = v
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1", "typ": "JWT"})
+	payload, _ := json.Marshal(doc)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, ptr.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestAuthOIDC(t *testing.T) {
+	provider := newOIDCTestProvider(t)
+	defer provider.server.Close()
+	auth, err := NewAuth(AuthOptions{Allowed: "@example.com", ClientID: "hatchet", ClientSecret: "s3cret",
+		Issuer: provider.server.URL, Mode: AUTH_OIDC, RedirectURL: "http://localhost:3721/auth/callback"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := auth.Handler(authOKHandler)
+
+	// browsers are redirected to sign in, APIs are not
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/hatchet/v1.0/schema", nil))
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "unauthorized") {
+		t.Fatal("expected", http.StatusUnauthorized, "but got", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hatchets/h1/stats/slowops", nil))
+	location, _ := url.Parse(w.Header().Get("Location"))
+	if w.Code != http.StatusFound || location.Path != "/authorize" {
+		t.Fatal("expected", "redirect to /authorize", "but got", w.Code, location)
+	}
+	state := location.Query().Get("state")
+	provider.nonce = location.Query().Get("nonce")
+	cookies := w.Result().Cookies()
+	if state == "" || provider.nonce == "" || len(cookies) != 1 || cookies[0].Value != state {
+		t.Fatal("expected", "state cookie", "but got", cookies)
+	}
+
+	// callback of a forged state is rejected
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/auth/callback?code=code1&state=forged", nil)
+	req.AddCookie(cookies[0])
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatal("expected", http.StatusBadRequest, "but got", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/auth/callback?code=code1&state="+state, nil)
+	req.AddCookie(cookies[0])
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/hatchets/h1/stats/slowops" {
+		t.Fatal("expected", "redirect to /hatchets/h1/stats/slowops", "but got", w.Code, w.Header(), w.Body.String())
+	}
+	var session *http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == AUTH_SESSION_COOKIE {
+			session = cookie
+		}
+	}
+	if session == nil || !session.HttpOnly {
+		t.Fatal("expected", "HttpOnly session cookie", "but got", w.Result().Cookies())
+	}
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/hatchet/v1.0/schema", nil)
+	req.AddCookie(session)
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatal("expected", http.StatusOK, "but got", w.Code)
+	}
+
+	// bearer ID tokens of API clients
+	tests := []struct {
+		claims map[string]interface{}
+		code   int
+	}{
+		{map[string]interface{}{"email": "ken@example.com"}, http.StatusOK},
+		{map[string]interface{}{"email": "ken@example.org"}, http.StatusUnauthorized},
+		{map[string]interface{}{"email": "ken@example.com", "email_verified": false}, http.StatusUnauthorized},
+		{map[string]interface{}{"email": "ken@example.com", "aud": "other"}, http.StatusUnauthorized},
+		{map[string]interface{}{"email": "ken@example.com", "exp": time.Now().Add(-time.Hour).Unix()}, http.StatusUnauthorized},
+		{map[string]interface{}{"email": "ken@example.com", "iss": "https://evil.example.com"}, http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Authorization", "Bearer "+provider.sign(t, test.claims))
+		handler.ServeHTTP(w, req)
+		if w.Code != test.code {
+			t.Fatal("expected", test.code, "but got", w.Code, test.claims)
+		}
+	}
+	token := provider.sign(t, nil)
+	tampered := strings.Split(token, ".")
+	payload, _ := json.Marshal(map[string]interface{}{"iss": provider.server.URL, "aud": "hatchet", "sub": "admin",
+		"exp": time.Now().Add(time.Hour).Unix()})
+	tampered[1] = base64.RawURLEncoding.EncodeToString(payload)
+	if _, err = auth.oidc.Verify(strings.Join(tampered, "."), ""); err == nil {
+		t.Fatal("expected", "invalid token signature", "but got", err)
+	}
+
+	// logout ends the session
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/auth/logout", nil)
+	req.AddCookie(session)
+	handler.ServeHTTP(w, req)
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/hatchet/v1.0/schema", nil)
+	req.AddCookie(session)
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatal("expected", http.StatusUnauthorized, "but got", w.Code)
+	}
+}
+
+func TestAuthOIDCPendingLogins(t *testing.T) {
+	provider := newOIDCTestProvider(t)
+	defer provider.server.Close()
+	auth, err := NewAuth(AuthOptions{ClientID: "hatchet", Issuer: provider.server.URL, Mode: AUTH_OIDC,
+		RedirectURL: "http://localhost:3721/auth/callback"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := auth.Handler(authOKHandler)
+	state := ""
+	for i := 0; i < AUTH_MAX_PENDING+10; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hatchets", nil))
+		location, _ := url.Parse(w.Header().Get("Location"))
+		state = location.Query().Get("state")
+	}
+	auth.mutex.Lock()
+	defer auth.mutex.Unlock()
+	if _, ok := auth.pending[state]; !ok || len(auth.pending) != AUTH_MAX_PENDING {
+		t.Fatal("expected", AUTH_MAX_PENDING, "pending logins of the latest but got", len(auth.pending), ok)
+	}
+}
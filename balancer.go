@@ -0,0 +1,124 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * balancer.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// metadata events of chunk migrations, splits, and balancer rounds logged to
+// config.changelog and config.actionlog
+const (
+	CHUNK_BALANCER_ROUND = "balancer.round"
+	CHUNK_MIGRATION_FROM = "moveChunk.from" // a donor shard completed a migration
+	CHUNK_MIGRATION_TO   = "moveChunk.to"   // a recipient shard completed a migration
+	CHUNK_MIGRATION_FAIL = "moveChunk.error"
+	CHUNK_MULTI_SPLIT    = "multi-split"
+	CHUNK_SPLIT          = "split"
+)
+
+// CHUNK_SERIES are series of GetChunkMigrationStats
+var CHUNK_SERIES = []string{"chunk migrations", "failed migrations", "max migration (s)", "balancer rounds"}
+
+// ChunkEvent is a chunk migration, a split, or a balancer round
+type ChunkEvent struct {
+	Date      string `json:"date" bson:"date"`
+	Milli     int    `json:"ms" bson:"chunk_ms"`
+	Namespace string `json:"ns" bson:"ns"`
+	Type      string `json:"type" bson:"chunk_event"`
+}
+
+// IsChunkMigration returns true if a chunk was migrated
+func (ptr *ChunkEvent) IsChunkMigration() bool {
+	return ptr.Type == CHUNK_MIGRATION_FROM || ptr.Type == CHUNK_MIGRATION_TO
+}
+
+// GetChunkEvent returns the namespace, type, and milliseconds of a chunk
+// migration, a split, or a balancer round from a logged metadata event, the
+// milliseconds of a migration are the sum of its steps, false otherwise
+func GetChunkEvent(doc *Logv2Info) (string, string, int, bool) {
+	if doc.Component != "SHARDING" || !strings.Contains(strings.ToLower(doc.Msg), "metadata event") {
+		return "", "", 0, false
+	}
+	event, ok := doc.Attr.Map()["event"].(bson.D)
+	if !ok {
+		return "", "", 0, false
+	}
+	attr := event.Map()
+	what, _ := attr["what"].(string)
+	if !strings.HasPrefix(what, "moveChunk.") && what != CHUNK_SPLIT && what != CHUNK_MULTI_SPLIT &&
+		what != CHUNK_BALANCER_ROUND {
+		return "", "", 0, false
+	}
+	ns, _ := attr["ns"].(string)
+	milli := 0
+	if details, ok := attr["details"].(bson.D); ok {
+		for _, elem := range details {
+			if strings.HasPrefix(elem.Key, "step ") {
+				milli += ToInt(elem.Value)
+			} else if elem.Key == "executionTimeMillis" || elem.Key == "totalTimeMillis" {
+				milli = ToInt(elem.Value)
+				break
+			}
+		}
+	}
+	return ns, what, milli, true
+}
+
+// GetChunkAuditData returns collections of most chunks migrated with failed
+// migrations, splits, and total and max ms of migrations, and counts and
+// total ms of balancer rounds
+func GetChunkAuditData(events []ChunkEvent) ([]NameValues, []NameValues) {
+	type collection struct {
+		failed, maxMilli, migrations, splits, totalMilli int
+	}
+	collections := map[string]*collection{}
+	rounds, roundMilli := 0, 0
+	for _, event := range events {
+		if event.Type == CHUNK_BALANCER_ROUND {
+			rounds++
+			roundMilli += event.Milli
+			continue
+		}
+		doc := collections[event.Namespace]
+		if doc == nil {
+			doc = &collection{}
+			collections[event.Namespace] = doc
+		}
+		if event.IsChunkMigration() {
+			doc.migrations++
+			doc.totalMilli += event.Milli
+			if event.Milli > doc.maxMilli {
+				doc.maxMilli = event.Milli
+			}
+		} else if event.Type == CHUNK_MIGRATION_FAIL {
+			doc.failed++
+		} else if event.Type == CHUNK_SPLIT || event.Type == CHUNK_MULTI_SPLIT {
+			doc.splits++
+		}
+	}
+	docs := []NameValues{}
+	for ns, doc := range collections {
+		if doc.migrations == 0 && doc.failed == 0 && doc.splits == 0 {
+			continue
+		}
+		docs = append(docs, NameValues{ns, []interface{}{doc.migrations, doc.failed, doc.splits, doc.totalMilli, doc.maxMilli}})
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].Values[0].(int) != docs[j].Values[0].(int) {
+			return docs[i].Values[0].(int) > docs[j].Values[0].(int)
+		}
+		return docs[i].Name < docs[j].Name
+	})
+	balancer := []NameValues{}
+	if rounds > 0 {
+		balancer = append(balancer, NameValues{"rounds", []interface{}{rounds}}, NameValues{"ms", []interface{}{roundMilli}})
+	}
+	return docs, balancer
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * balancer_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetChunkEvent(t *testing.T) {
+	tests := []struct {
+		str   string
+		ns    string
+		what  string
+		milli int
+		ok    bool
+	}{
+		{`{"t":{"$date":"2023-01-01T00:01:00.000+00:00"},"s":"I","c":"SHARDING","id":22080,"ctx":"MoveChunk","msg":"About to log metadata event","attr":{"namespace":"changelog","event":{"_id":"shard01:27018-2023-01-01T00:01:00.000+00:00-1","server":"shard01:27018","shard":"shard01","clientAddr":"","time":{"$date":"2023-01-01T00:01:00.000Z"},"what":"moveChunk.from","ns":"shop.orders","details":{"step 1 of 6":0,"step 2 of 6":5,"step 3 of 6":120,"step 4 of 6":3400,"step 5 of 6":30,"step 6 of 6":45,"min":{"_id":{"$minKey":1}},"max":{"_id":100},"to":"shard02","from":"shard01","note":"success"}}}}`,
+			"shop.orders", CHUNK_MIGRATION_FROM, 3600, true},
+		{`{"t":{"$date":"2023-01-01T00:02:00.000+00:00"},"s":"I","c":"SHARDING","id":22080,"ctx":"Balancer","msg":"About to log metadata event","attr":{"namespace":"actionlog","event":{"_id":"cfg:27019-2023-01-01T00:02:00.000+00:00-2","server":"cfg:27019","what":"balancer.round","ns":"","details":{"executionTimeMillis":250,"errorOccured":false,"candidateChunks":1,"chunksMoved":1}}}}`,
+			"", CHUNK_BALANCER_ROUND, 250, true},
+		{`{"t":{"$date":"2023-01-01T00:03:00.000+00:00"},"s":"I","c":"SHARDING","id":22080,"ctx":"conn9","msg":"About to log metadata event","attr":{"namespace":"changelog","event":{"what":"dropCollection","ns":"shop.tmp","details":{}}}}`,
+			"", "", 0, false},
+		{`{"t":{"$date":"2023-01-01T00:04:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","durationMillis":200}}`,
+			"", "", 0, false},
+	}
+	for _, test := range tests {
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(test.str), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		ns, what, milli, ok := GetChunkEvent(&doc)
+		if ns != test.ns || what != test.what || milli != test.milli || ok != test.ok {
+			t.Fatal("expected", test.ns, test.what, test.milli, test.ok, "but got", ns, what, milli, ok)
+		}
+	}
+}
+
+func TestGetChunkAuditData(t *testing.T) {
+	events := []ChunkEvent{
+		{Namespace: "shop.orders", Type: CHUNK_MIGRATION_FROM, Milli: 3000},
+		{Namespace: "shop.orders", Type: CHUNK_MIGRATION_FROM, Milli: 1000},
+		{Namespace: "shop.orders", Type: CHUNK_MIGRATION_FAIL},
+		{Namespace: "shop.items", Type: CHUNK_SPLIT},
+		{Namespace: "shop.items", Type: CHUNK_MIGRATION_TO, Milli: 500},
+		{Type: CHUNK_BALANCER_ROUND, Milli: 200},
+		{Type: CHUNK_BALANCER_ROUND, Milli: 100},
+	}
+	docs, balancer := GetChunkAuditData(events)
+	if len(docs) != 2 || docs[0].Name != "shop.orders" {
+		t.Fatal("expected", "shop.orders and shop.items", "but got", docs)
+	}
+	expected := []interface{}{2, 1, 0, 4000, 3000}
+	for i, value := range expected {
+		if docs[0].Values[i] != value {
+			t.Fatal("expected", expected, "but got", docs[0].Values)
+		}
+	}
+	if len(balancer) != 2 || balancer[0].Values[0] != 2 || balancer[1].Values[0] != 300 {
+		t.Fatal("expected", "2 rounds of 300 ms", "but got", balancer)
+	}
+}
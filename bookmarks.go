@@ -0,0 +1,28 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * bookmarks.go
+ */
+
+package hatchet
+
+import (
+	"crypto/sha1"
+	"fmt"
+)
+
+// Bookmark is an op shape tagged with a note
+type Bookmark struct {
+	Date         string `json:"date" bson:"date"`
+	Hash         string `json:"hash" bson:"_id"`
+	Index        string `json:"index" bson:"index"`
+	Namespace    string `json:"ns" bson:"ns"`
+	Note         string `json:"note" bson:"note"`
+	Op           string `json:"op" bson:"op"`
+	QueryPattern string `json:"query_pattern" bson:"query_pattern"`
+}
+
+// GetOpHash returns a stable hash of an op shape
+func GetOpHash(op string, ns string, filter string, index string) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%v\x00%v\x00%v\x00%v", op, ns, filter, index)))
+	return fmt.Sprintf("%x", sum[:8])
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * bookmarks_handler.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// BookmarksHandler responds to bookmarks view
+func BookmarksHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	/** APIs
+	 * /hatchets/{hatchet}/bookmarks/all
+	 */
+	hatchetName := params.ByName("hatchet")
+	dbase, err := GetDatabase(hatchetName)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	defer dbase.Close()
+	if dbase.GetVerbose() {
+		log.Println("BookmarksHandler", r.URL.Path, hatchetName)
+	}
+	summary := GetHatchetSummary(dbase.GetHatchetInfo())
+	bookmarks, err := dbase.GetBookmarks()
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	ops, err := dbase.GetSlowOps("avg_ms", "DESC", false)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	stats := map[string]OpStat{}
+	for _, op := range ops {
+		stats[GetOpHash(op.Op, op.Namespace, op.QueryPattern, op.Index)] = op
+	}
+	templ, err := GetBookmarksTemplate()
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	doc := map[string]interface{}{"Hatchet": hatchetName, "Bookmarks": bookmarks, "Stats": stats, "Summary": summary}
+	if err = templ.Execute(w, doc); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+}
+
+// BookmarkAPIHandler saves or removes a bookmark of an op shape
+func BookmarkAPIHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	/** APIs
+	 * POST /api/hatchet/v1.0/hatchets/{hatchet}/bookmarks/{hash} with op, ns, filter, index, and note
+	 * DELETE /api/hatchet/v1.0/hatchets/{hatchet}/bookmarks/{hash}
+	 */
+	w.Header().Set("Content-Type", "application/json")
+	hatchetName := params.ByName("hatchet")
+	hash := params.ByName("attr")
+	dbase, err := GetDatabase(hatchetName)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	defer dbase.Close()
+	if dbase.GetVerbose() {
+		log.Println("BookmarkAPIHandler", r.Method, r.URL.Path, hatchetName, hash)
+	}
+	if r.Method == http.MethodDelete {
+		if err = dbase.DeleteBookmark(hash); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 1, "hash": hash})
+		return
+	}
+	if err = r.ParseForm(); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	doc := Bookmark{Op: r.FormValue("op"), Namespace: r.FormValue("ns"), QueryPattern: r.FormValue("filter"),
+		Index: r.FormValue("index"), Note: r.FormValue("note"), Date: getDateTimeStr(time.Now())}
+	doc.Hash = GetOpHash(doc.Op, doc.Namespace, doc.QueryPattern, doc.Index)
+	if doc.Hash != hash {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": "op hash mismatched " + hash})
+		return
+	}
+	if err = dbase.SaveBookmark(doc); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": 1, "hash": hash})
+}
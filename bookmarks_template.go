@@ -0,0 +1,94 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * bookmarks_template.go
+ */
+
+package hatchet
+
+import (
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetBookmarksTemplate returns HTML
+func GetBookmarksTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += getBookmarkScript() + `
+<div style='margin: 10px 10px; clear: left;'>
+{{$stats := .Stats}}
+{{if .Bookmarks}}
+	<table width='100%'>
+		<caption>Bookmarked Ops</caption>
+		<tr><th>#</th><th></th><th>note</th><th>op</th><th>namespace</th><th>count</th><th>avg ms</th><th>max ms</th>
+			<th>index</th><th>query pattern</th><th>bookmarked</th></tr>
+	{{range $n, $b := .Bookmarks}}
+		<tr><td align='right'>{{add $n 1}}</td>
+			<td><button class='btn' onClick='bookmark(this); return false;' data-hash='{{$b.Hash}}' data-op='{{$b.Op}}'
+				data-ns='{{$b.Namespace}}' data-filter='{{$b.QueryPattern}}' data-index='{{$b.Index}}' data-note='{{$b.Note}}'>
+				<i class='fa fa-bookmark'></i></button></td>
+			<td class='break'>{{$b.Note}}</td><td>{{$b.Op}}</td><td class='break'>{{$b.Namespace}}</td>
+		{{with index $stats $b.Hash}}
+			<td align='right'>{{numPrinter .Count}}</td><td align='right'>{{numPrinter .AvgMilli}}</td>
+			<td align='right'>{{numPrinter .MaxMilli}}</td>
+		{{else}}
+			<td></td><td></td><td></td>
+		{{end}}
+			<td>{{$b.Index}}</td><td class='break'>{{$b.QueryPattern}}</td><td>{{getDateTime $b.Date}}</td>
+		</tr>
+	{{end}}
+	</table>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no bookmarks found, bookmark ops from the Stats page</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"add": func(a int, b int) int {
+			return a + b
+		},
+		"getDateTime": func(str string) string {
+			if len(str) > 19 {
+				return str[:10] + " " + str[11:19]
+			}
+			return str
+		},
+		"numPrinter": func(n interface{}) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", ToInt(n))
+		}}).Parse(html)
+}
+
+// getBookmarkScript returns JavaScript to save or remove a bookmark of a
+// button with data-hash, data-op, data-ns, data-filter, data-index, and data-note
+func getBookmarkScript() string {
+	return `
+<script>
+	function bookmark(btn) {
+		var d = btn.dataset;
+		var bookmarked = btn.firstElementChild.classList.contains('fa-bookmark');
+		var msg = bookmarked ? 'Edit the note, or clear it to remove the bookmark' : 'Bookmark with an optional note';
+		var note = prompt(msg, d.note);
+		if (note == null) {
+			return;
+		}
+		var url = '/api/hatchet/v1.0/hatchets/{{.Hatchet}}/bookmarks/' + d.hash;
+		var opts = {method: 'DELETE'};
+		if (!bookmarked || note != '') {
+			opts = {method: 'POST', body: new URLSearchParams({op: d.op, ns: d.ns, filter: d.filter, index: d.index, note: note})};
+		}
+		fetch(url, opts)
+			.then(response => response.json())
+			.then(data => {
+				if (data.ok != 1) {
+					alert(data.error);
+					return;
+				}
+				d.note = note;
+				btn.firstElementChild.className = opts.method == 'POST' ? 'fa fa-bookmark' : 'fa fa-bookmark-o';
+			})
+			.catch(error => alert(error));
+	}
+</script>`
+}
@@ -0,0 +1,23 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * bookmarks_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestGetOpHash(t *testing.T) {
+	hash := GetOpHash("find", "shop.orders", "{ _id:1 }", "IDHACK")
+	if len(hash) != 16 {
+		t.Fatal("expected", 16, "but got", len(hash))
+	}
+	if hash != GetOpHash("find", "shop.orders", "{ _id:1 }", "IDHACK") {
+		t.Fatal("expected", hash, "but got", GetOpHash("find", "shop.orders", "{ _id:1 }", "IDHACK"))
+	}
+	if hash == GetOpHash("find", "shop.orders", "{ _id:1 }", "COLLSCAN") {
+		t.Fatal("expected different hashes")
+	}
+}
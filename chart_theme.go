@@ -0,0 +1,61 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * chart_theme.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CHART_THEMES are palettes of series colors of charts, google is the
+// default palette of Google Charts
+var CHART_THEMES = map[string][]string{
+	"colorblind": {"#0072B2", "#E69F00", "#009E73", "#CC79A7", "#56B4E9", "#D55E00", "#F0E442", "#000000"},
+	"google":     {},
+	"grayscale":  {"#212121", "#616161", "#9E9E9E", "#424242", "#757575", "#BDBDBD"},
+	"hatchet":    {"#2C5234", "#7BAF9B", "#DB4437", "#5E8961", "#F4B400", "#9FCCB3", "#4285F4", "#C1D8C5"},
+}
+
+var chartColorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ParseChartTheme returns colors of a theme name or of comma separated hex
+// colors, e.g. #0B3D91,#FC3D21, empty for the default palette
+func ParseChartTheme(theme string) ([]string, error) {
+	theme = strings.TrimSpace(theme)
+	if theme == "" {
+		return []string{}, nil
+	} else if colors, ok := CHART_THEMES[strings.ToLower(theme)]; ok {
+		return colors, nil
+	} else if !strings.HasPrefix(theme, "#") {
+		names := []string{}
+		for name := range CHART_THEMES {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown chart theme %v, use %v, or comma separated colors, e.g. #0B3D91,#FC3D21",
+			theme, strings.Join(names, ", "))
+	}
+	colors := []string{}
+	for _, color := range strings.Split(theme, ",") {
+		color = strings.TrimSpace(color)
+		if !chartColorRegex.MatchString(color) {
+			return nil, fmt.Errorf("invalid chart color %q, use #rgb or #rrggbb", color)
+		}
+		colors = append(colors, color)
+	}
+	return colors, nil
+}
+
+// GetChartColors returns series colors of charts, empty for the default
+// palette
+func GetChartColors() []string {
+	if colors := GetLogv2().chartColors; colors != nil {
+		return colors
+	}
+	return []string{}
+}
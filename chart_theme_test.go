@@ -0,0 +1,28 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * chart_theme_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestParseChartTheme(t *testing.T) {
+	colors, err := ParseChartTheme("")
+	if err != nil || len(colors) != 0 {
+		t.Fatal("expected", "default palette", "but got", colors, err)
+	}
+	if colors, err = ParseChartTheme("Colorblind"); err != nil || len(colors) != len(CHART_THEMES["colorblind"]) {
+		t.Fatal("expected", CHART_THEMES["colorblind"], "but got", colors, err)
+	}
+	if colors, err = ParseChartTheme("#0B3D91, #fc3d21,#FFF"); err != nil || len(colors) != 3 || colors[1] != "#fc3d21" {
+		t.Fatal("expected", "3 colors", "but got", colors, err)
+	}
+	for _, theme := range []string{"pink", "#0B3D91,red", "#12345"} {
+		if _, err = ParseChartTheme(theme); err == nil {
+			t.Fatal("expected", "error of", theme, "but got", err)
+		}
+	}
+}
@@ -16,17 +16,32 @@ import (
 )
 
 const (
-	BAR_CHART    = "bar_chart"
-	BUBBLE_CHART = "bubble_chart"
-	PIE_CHART    = "pie_chart"
+	BAR_CHART     = "bar_chart"
+	BUBBLE_CHART  = "bubble_chart"
+	HEATMAP_CHART = "heatmap_chart"
+	LINE_CHART    = "line_chart"
+	PIE_CHART     = "pie_chart"
 
 	T_OPS            = "ops"
 	T_RESLEN_UP      = "reslen-ip"
 	T_OPS_COUNTS     = "ops-counts"
 	T_CONNS_ACCEPTED = "connections-accepted"
+	T_CONNS_IP       = "connections-ip"
 	T_CONNS_TIME     = "connections-time"
 	T_CONNS_TOTAL    = "connections-total"
+	T_DISK_READS     = "disk-reads"
 	T_RESLEN_NS      = "reslen-ns"
+	T_RESLEN_TIME    = "reslen-time"
+	T_TICKETS        = "tickets"
+	T_REPL_LAG       = "repl-lag"
+	T_WIREDTIGER     = "wiredtiger"
+	T_HEATMAP        = "heatmap"
+	T_LOCK_WAITS     = "lock-waits"
+	T_TTL            = "ttl"
+	T_CHUNKS         = "chunks"
+	T_TXN            = "transactions"
+	T_SEVERITY       = "severity"
+	T_OPLOG          = "oplog"
 )
 
 type Chart struct {
@@ -52,6 +67,32 @@ var charts = map[string]Chart{
 		"Display total response length by client IPs", "/reslen-ip?ip="},
 	T_RESLEN_NS: {7, "Response Length by Namespaces ",
 		"Display total response length by namespaces", "/reslen-ns?ns="},
+	T_TICKETS: {8, "Ticket Wait Time",
+		"Display time waited for read/write tickets over a period of time", "/tickets?type=wait"},
+	T_REPL_LAG: {9, "Replication Lag",
+		"Display replication lag of applied oplog entries and write throughput over a period of time", "/repl-lag?type=lag"},
+	T_WIREDTIGER: {10, "WiredTiger Checkpoints & Eviction",
+		"Display checkpoint durations, eviction warnings, and slow ops over a period of time", "/wiredtiger?type=checkpoint"},
+	T_HEATMAP: {11, "Operations Heatmap",
+		"Display counts or total duration of slow ops by namespace and hour or by day and hour of day", "/heatmap?type=ns&metric=count"},
+	T_LOCK_WAITS: {12, "Lock Wait Time",
+		"Display time slow ops waited acquiring locks and counts of slow ops waited over a period of time", "/lock-waits?type=wait"},
+	T_TTL: {13, "TTL Deletes",
+		"Display documents deleted and duration of TTL passes with avg slow op time over a period of time", "/ttl?type=deletes"},
+	T_CHUNKS: {14, "Chunk Migrations",
+		"Display chunk migrations, failed migrations, max migration time, and balancer rounds over a period of time", "/chunks?type=migrations"},
+	T_TXN: {15, "Transaction Commits & Aborts",
+		"Display committed and aborted multi-document transactions and the abort rate over a period of time", "/transactions?type=rate"},
+	T_SEVERITY: {16, "Warnings & Errors",
+		"Display counts of warnings, errors, and fatal messages over a period of time", "/severity?type=counts"},
+	T_OPLOG: {17, "Oplog Churn & Window",
+		"Display estimated oplog churn and oplog window by hour from truncations and writes", "/oplog?type=churn"},
+	T_CONNS_IP: {18, "Connections by IPs",
+		"Display open connections of top client IPs and others over a period of time", "/connections?type=ip"},
+	T_RESLEN_TIME: {19, "Response Length over Time",
+		"Display total, average, and max response bytes of ops over a period of time", "/reslen-time?type=bytes"},
+	T_DISK_READS: {20, "Disk Reads",
+		"Display MB read from disk, avg read time, and read time in percent of slow op time over a period of time", "/disk-reads?type=bytes"},
 }
 
 // ChartsHandler responds to charts API calls
@@ -148,6 +189,32 @@ func ChartsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Par
 				return
 			}
 			return
+		} else if chartType == "ip" {
+			chartType = T_CONNS_IP
+			topN := ToInt(r.URL.Query().Get("topN"))
+			if topN <= 0 {
+				topN = CONNS_TOP_N
+			}
+			docs, err := dbase.GetConnectionsByIP(duration)
+			if err != nil {
+				json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+				return
+			}
+			labels, series := GetConnectionsByIPSeries(docs, topN)
+			templ, err := GetChartTemplate(LINE_CHART)
+			if err != nil {
+				json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+				return
+			}
+			doc := map[string]interface{}{"Hatchet": hatchetName, "Series": series, "Labels": labels,
+				"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+				"VAxisLabel": "open connections", "Restarts": getChartRestarts(dbase, duration),
+				"Stacked": true, "Toggles": true}
+			if err = templ.Execute(w, doc); err != nil {
+				json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+				return
+			}
+			return
 		} else { // type is time or total
 			docs, err := dbase.GetConnectionStats(chartType, duration)
 			if err != nil {
@@ -162,6 +229,10 @@ func ChartsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Par
 			}
 			doc := map[string]interface{}{"Hatchet": hatchetName, "Remote": docs, "Chart": charts[chartType],
 				"Type": chartType, "Summary": summary, "Start": start, "End": end}
+			if chartType == T_CONNS_TIME {
+				doc["Limit"] = getChartConnLimit(dbase)
+				doc["Restarts"] = getChartRestarts(dbase, duration)
+			}
 			if err = templ.Execute(w, doc); err != nil {
 				json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
 				return
@@ -222,6 +293,261 @@ func ChartsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Par
 			return
 		}
 		return
+	} else if attr == T_TICKETS {
+		chartType := attr
+		docs, err := dbase.GetTicketWaits(duration)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChartTemplate(LINE_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Series": docs, "Labels": TICKET_WAIT_SERIES,
+			"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+			"VAxisLabel": "milliseconds", "Restarts": getChartRestarts(dbase, duration)}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == T_REPL_LAG {
+		chartType := attr
+		docs, err := dbase.GetReplicationLags(duration)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChartTemplate(LINE_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Series": docs, "Labels": REPL_LAG_SERIES,
+			"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+			"VAxisLabel": "seconds / counts", "Restarts": getChartRestarts(dbase, duration)}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == T_WIREDTIGER {
+		chartType := attr
+		docs, err := dbase.GetWiredTigerStats(duration)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChartTemplate(LINE_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Series": docs, "Labels": WIREDTIGER_SERIES,
+			"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+			"VAxisLabel": "seconds / counts", "Restarts": getChartRestarts(dbase, duration)}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == T_LOCK_WAITS {
+		chartType := attr
+		docs, err := dbase.GetLockWaits(duration)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChartTemplate(LINE_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Series": docs, "Labels": LOCK_WAIT_SERIES,
+			"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+			"VAxisLabel": "seconds / counts", "Restarts": getChartRestarts(dbase, duration)}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == T_TTL {
+		chartType := attr
+		docs, err := dbase.GetTTLDeletes(duration)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChartTemplate(LINE_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Series": docs, "Labels": TTL_SERIES,
+			"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+			"VAxisLabel": "counts / milliseconds", "Restarts": getChartRestarts(dbase, duration)}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == T_CHUNKS {
+		chartType := attr
+		docs, err := dbase.GetChunkMigrationStats(duration)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChartTemplate(LINE_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Series": docs, "Labels": CHUNK_SERIES,
+			"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+			"VAxisLabel": "seconds / counts", "Restarts": getChartRestarts(dbase, duration)}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == T_TXN {
+		chartType := attr
+		docs, err := dbase.GetTransactionRates(duration)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChartTemplate(LINE_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Series": docs, "Labels": TXN_SERIES,
+			"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+			"VAxisLabel": "counts / percent", "Restarts": getChartRestarts(dbase, duration)}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == T_SEVERITY {
+		chartType := attr
+		docs, err := dbase.GetSeverityCounts(duration)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChartTemplate(LINE_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Series": docs, "Labels": SEVERITY_SERIES,
+			"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+			"VAxisLabel": "counts", "Restarts": getChartRestarts(dbase, duration)}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == T_RESLEN_TIME {
+		chartType := attr
+		docs, err := dbase.GetReslenOverTime(duration)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChartTemplate(LINE_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Series": docs, "Labels": RESLEN_SERIES,
+			"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+			"VAxisLabel": "bytes returned", "Restarts": getChartRestarts(dbase, duration), "Toggles": true}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == T_DISK_READS {
+		chartType := attr
+		docs, err := dbase.GetStorageReads(duration)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChartTemplate(LINE_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Series": docs, "Labels": STORAGE_READ_SERIES,
+			"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+			"VAxisLabel": "disk reads", "Restarts": getChartRestarts(dbase, duration), "Toggles": true}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == T_OPLOG {
+		chartType := attr
+		data, err := dbase.GetAuditData()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		docs := []TimeSeries{}
+		for _, doc := range GetOplogChurnSeries(data["oplog-churn"]) {
+			if duration == "" || (doc.Date[:16] >= start && doc.Date[:16] <= end) {
+				docs = append(docs, doc)
+			}
+		}
+		templ, err := GetChartTemplate(LINE_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Series": docs, "Labels": []string{"churn (MB/hour)", "window (hours)"},
+			"Chart": charts[chartType], "Type": chartType, "Summary": summary, "Start": start, "End": end,
+			"VAxisLabel": "MB / hours", "Restarts": getChartRestarts(dbase, duration)}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == T_HEATMAP {
+		layout := r.URL.Query().Get("type")
+		if layout != HEATMAP_BY_DAY {
+			layout = HEATMAP_BY_NS
+		}
+		metric := r.URL.Query().Get("metric")
+		if metric != HEATMAP_MILLIS {
+			metric = HEATMAP_COUNT
+		}
+		cells, err := dbase.GetOpsHeatmap(layout, duration)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChartTemplate(HEATMAP_CHART)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		chart := charts[attr]
+		chart.URL = fmt.Sprintf("/heatmap?type=%v&metric=%v", layout, metric)
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Heatmap": GetHeatmap(cells, layout, metric),
+			"Chart": chart, "Type": attr, "Layout": layout, "Metric": metric, "Summary": summary,
+			"Start": start, "End": end, "Duration": duration}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
 	}
 }
 
@@ -238,3 +564,31 @@ func getStartEndDates(duration string) (string, string) {
 	}
 	return start, end
 }
+
+// getChartConnLimit returns the connection limit to draw with connections,
+// 0 if the limit is unknown
+func getChartConnLimit(dbase Database) int {
+	data, err := dbase.GetAuditData()
+	if err != nil {
+		return 0
+	}
+	for _, doc := range data["conn-limit-max"] {
+		if doc.Name == "limit" {
+			return ToInt(doc.Values[0])
+		}
+	}
+	return 0
+}
+
+// getChartRestarts returns restart dates within a duration to annotate timelines
+func getChartRestarts(dbase Database, duration string) []string {
+	data, err := dbase.GetAuditData()
+	if err != nil {
+		return []string{}
+	}
+	var start, end string
+	if toks := strings.Split(duration, ","); len(toks) == 2 {
+		start, end = toks[0], toks[1]
+	}
+	return GetRestarts(data, start, end)
+}
@@ -22,18 +22,34 @@ func GetChartTemplate(chartType string) (*template.Template, error) {
 		html += getPieChart()
 	} else if chartType == BAR_CHART {
 		html += getConnectionsChart()
+	} else if chartType == LINE_CHART {
+		html += getTimeSeriesChart()
+	} else if chartType == HEATMAP_CHART {
+		html += getHeatmapChart()
 	}
 	html += `
 	<div style="float: left; width: 100%; clear: left;">
 		<input type='datetime-local' id='start' value='{{.Start}}'></input>
 		<input type='datetime-local' id='end' value='{{.End}}'></input>
-		<button onClick="refreshChart(); return false;" class="button">Refresh</button>
+		<button onClick="refreshChart(); return false;" class="button">Refresh</button>`
+	if chartType != HEATMAP_CHART {
+		html += `
+		<button onClick="downloadChart('png'); return false;" class="button" style="float: right;"
+			title="download the chart as PNG"><i class="fa fa-download"></i> PNG</button>
+		<button onClick="downloadChart('svg'); return false;" class="button" style="float: right;"
+			title="download the chart as SVG"><i class="fa fa-download"></i> SVG</button>`
+	}
+	html += `
   	</div>
   	<div id='hatchetChart' style="width: 100%; clear: left;"></div>
   
 		</body></html>`
+	if chartType != HEATMAP_CHART {
+		html += getDownloadChartScript()
+	}
 
 	return template.New("hatchet").Funcs(template.FuncMap{
+		"chartColors": GetChartColors,
 		"descr": func(v OpCount) template.HTML {
 			if v.Filter == "" {
 				return template.HTML(v.Namespace)
@@ -41,6 +57,15 @@ func GetChartTemplate(chartType string) (*template.Template, error) {
 			str := fmt.Sprintf("%v, QP: %v", v.Namespace, v.Filter)
 			return template.HTML(str)
 		},
+		"heatColor": func(value int, max int) template.CSS {
+			return template.CSS(GetHeatColor(value, max))
+		},
+		"heatLabel": func(column string) string {
+			if len(column) == 13 { // yyyy-mm-ddThh
+				return column[5:10] + " " + column[11:] + "h"
+			}
+			return column + "h"
+		},
 		"toSeconds": func(n float64) float64 {
 			return n / 1000
 		},
@@ -82,6 +107,7 @@ func getOpStatsChart() string {
 		// Set chart options
 		var options = {
 			'backgroundColor': { 'fill': 'transparent' },
+			{{if chartColors}}'colors': {{chartColors}},{{end}}
 			'title': '{{.Chart.Title}}',
 			// 'hAxis': { textPosition: 'none' },
 			'hAxis': { slantedText: true, slantedTextAngle: 30 },
@@ -126,6 +152,7 @@ func getPieChart() string {
 		// Set chart options
 		var options = {
 			'backgroundColor': { 'fill': 'transparent' },
+			{{if chartColors}}'colors': {{chartColors}},{{end}}
 			'title': '{{.Chart.Title}}',
 			'width': '100%',
 			'height': 480,
@@ -145,7 +172,13 @@ func getPieChart() string {
 
 func getConnectionsChart() string {
 	return `
-{{ if .Remote }}
+{{ if .Remote }}` + getRestartsScript() + `
+{{if eq .Type "connections-time"}}
+<div style="float: left; width: 100%; clear: left;" align='right'>
+	<button onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/connections?type=ip&duration={{.Start}},{{.End}}'); return false;"
+		class="button" title="connections of top client IPs and others">by IP</button>
+</div>
+{{end}}
 <script>
 	setChartType();
 	google.charts.load('current', {'packages':['corechart']});
@@ -155,22 +188,26 @@ func getConnectionsChart() string {
 	{{$ctype := .Type}}
 		var data = google.visualization.arrayToDataTable([
 	{{if eq $ctype "connections-time"}}
-			['Date/Time', 'Connections'],
+			['Date/Time', 'Connections'{{if .Limit}}, 'Limit'{{end}}],
 	{{else}}
 			['IP', 'Accepted', 'Ended'],
 	{{end}}
 
 	{{range $i, $v := .Remote}}
 		{{if eq $ctype "connections-time"}}
-			[new Date("{{$v.IP}}"), {{$v.Accepted}}],
+			[new Date("{{$v.IP}}"), {{$v.Accepted}}{{if $.Limit}}, {{$.Limit}}{{end}}],
 		{{else}}
 			['{{$v.IP}}', {{$v.Accepted}}, {{$v.Ended}}],
 		{{end}}
 	{{end}}
 		]);
+	{{if eq $ctype "connections-time"}}
+		addRestarts(data);
+	{{end}}
 		// Set chart options
 		var options = {
 			'backgroundColor': { 'fill': 'transparent' },
+			{{if chartColors}}'colors': {{chartColors}},{{end}}
 			'title': '{{.Chart.Title}}',
 			'hAxis': { slantedText: true, slantedTextAngle: 30 },
 			'vAxis': {title: 'Count', minValue: 0},
@@ -192,3 +229,231 @@ func getConnectionsChart() string {
 <div align='center' class='btn'><span style='color: red'>no data found</span></div>
 {{end}}`
 }
+
+func getTimeSeriesChart() string {
+	return `
+{{ if .Series }}` + getRestartsScript() + `
+<script>
+	setChartType();
+	google.charts.load('current', {'packages':['corechart']});
+	google.charts.setOnLoadCallback(drawChart);
+
+	function drawChart() {
+		var data = google.visualization.arrayToDataTable([
+			['Date/Time'{{range $i, $label := .Labels}}, '{{$label}}'{{end}}],
+	{{range $i, $v := .Series}}
+			[new Date("{{$v.Date}}"){{range $j, $n := $v.Values}}, {{$n}}{{end}}],
+	{{end}}
+		]);
+		addRestarts(data);
+		// Set chart options
+		var options = {
+			'backgroundColor': { 'fill': 'transparent' },
+			{{if chartColors}}'colors': {{chartColors}},{{end}}
+			'title': '{{.Chart.Title}}',
+			'hAxis': { slantedText: true, slantedTextAngle: 30 },
+			'vAxis': {title: '{{.VAxisLabel}}', minValue: 0},
+			'width': '100%',
+			'height': 480,
+			'titleTextStyle': {'fontSize': 20},
+			'explorer': { actions: ['dragToZoom', 'rightClickToReset'] },
+	{{if .Stacked}}
+			'isStacked': true,
+	{{end}}
+			'legend': { 'position': 'right' } };
+		// Instantiate and draw our chart, passing in some options.
+	{{if .Stacked}}
+		var chart = new google.visualization.AreaChart(document.getElementById('hatchetChart'));
+	{{else}}
+		var chart = new google.visualization.LineChart(document.getElementById('hatchetChart'));
+	{{end}}
+	{{if .Toggles}}
+		drawToggledSeries(chart, data, options);
+	{{else}}
+		chart.draw(data, options);
+	{{end}}
+	}
+</script>
+{{if .Toggles}}` + getSeriesTogglesScript() + `
+<div style="float: left; width: 100%; clear: left;">
+	{{range $i, $label := .Labels}}
+	<label style="margin-right: 10px;"><input type='checkbox' class='seriesToggle' value='{{$label}}' checked
+		onchange='toggleSeries(this.value, this.checked)'/>{{$label}}</label>
+	{{end}}
+</div>
+{{end}}
+{{else}}
+<div align='center' class='btn'><span style='color: red'>no data found</span></div>
+{{end}}`
+}
+
+func getHeatmapChart() string {
+	return `
+<script>
+	setChartType();
+	function gotoHeatmap(layout, metric) {
+		var sd = document.getElementById('start').value;
+		var ed = document.getElementById('end').value;
+		loadData('/hatchets/{{.Hatchet}}/charts/heatmap?type=' + layout + '&metric=' + metric + '&duration=' + sd + ',' + ed);
+	}
+</script>
+<div style="float: left; width: 100%; clear: left;" align='center'>
+	<h3>{{.Chart.Title}}</h3>
+	<button onClick="gotoHeatmap('ns', '{{.Metric}}'); return false;" class="button"
+		{{if eq .Layout "ns"}}disabled{{end}}>namespace × hour</button>
+	<button onClick="gotoHeatmap('day', '{{.Metric}}'); return false;" class="button"
+		{{if eq .Layout "day"}}disabled{{end}}>day × hour of day</button>
+	<button onClick="gotoHeatmap('{{.Layout}}', 'count'); return false;" class="button"
+		{{if eq .Metric "count"}}disabled{{end}}>counts</button>
+	<button onClick="gotoHeatmap('{{.Layout}}', 'ms'); return false;" class="button"
+		{{if eq .Metric "ms"}}disabled{{end}}>total ms</button>
+</div>
+{{ if .Heatmap.Rows }}
+{{$max := .Heatmap.Max}}
+<div style="float: left; width: 100%; clear: left; overflow-x: auto;">
+	<table>
+		<tr><th>{{if eq .Layout "day"}}day{{else}}namespace{{end}}</th>
+	{{range $c := .Heatmap.Columns}}
+			<th>{{heatLabel $c}}</th>
+	{{end}}
+			<th>total</th></tr>
+	{{range $r := .Heatmap.Rows}}
+		<tr><td class='break'>{{$r.Name}}</td>
+		{{range $i, $v := $r.Values}}
+			<td align='right' style='background-color: {{heatColor $v $max}};'
+				title='{{$r.Name}} {{heatLabel (index $.Heatmap.Columns $i)}}: {{$v}}'>{{if gt $v 0}}{{$v}}{{end}}</td>
+		{{end}}
+			<td align='right'>{{$r.Total}}</td></tr>
+	{{end}}
+	</table>
+</div>
+{{else}}
+<div align='center' class='btn'><span style='color: red'>no data found</span></div>
+{{end}}`
+}
+
+// getDownloadChartScript returns functions to download the chart drawn as an
+// SVG file, or as a PNG image of twice the resolution on a white background
+func getDownloadChartScript() string {
+	return `
+<script>
+	function downloadChart(format) {
+		var svg = document.querySelector('#hatchetChart svg');
+		if (svg == null) {
+			return;
+		}
+		var rect = svg.getBoundingClientRect();
+		var clone = svg.cloneNode(true);
+		clone.setAttribute('width', rect.width);
+		clone.setAttribute('height', rect.height);
+		// references of clip paths and gradients are absolute URLs of the page
+		var text = new XMLSerializer().serializeToString(clone).replace(/url\([^)#]*#/g, 'url(#');
+		var filename = ('{{.Hatchet}}-{{.Chart.Title}}').replace(/[^\w.-]+/g, '_');
+		var svgURL = URL.createObjectURL(new Blob([text], {type: 'image/svg+xml;charset=utf-8'}));
+		if (format == 'svg') {
+			saveChart(svgURL, filename + '.svg');
+			return;
+		}
+		var img = new Image();
+		img.onload = function() {
+			var scale = 2;
+			var canvas = document.createElement('canvas');
+			canvas.width = rect.width * scale;
+			canvas.height = rect.height * scale;
+			var ctx = canvas.getContext('2d');
+			ctx.fillStyle = 'white';
+			ctx.fillRect(0, 0, canvas.width, canvas.height);
+			ctx.scale(scale, scale);
+			ctx.drawImage(img, 0, 0, rect.width, rect.height);
+			URL.revokeObjectURL(svgURL);
+			canvas.toBlob(function(blob) {
+				saveChart(URL.createObjectURL(blob), filename + '.png');
+			}, 'image/png');
+		};
+		img.src = svgURL;
+	}
+
+	function saveChart(href, filename) {
+		var a = document.createElement('a');
+		a.href = href;
+		a.download = filename;
+		document.body.appendChild(a);
+		a.click();
+		a.remove();
+		setTimeout(function() { URL.revokeObjectURL(href); }, 1000);
+	}
+</script>`
+}
+
+// getRestartsScript returns a function to annotate restarts on a timeline, a
+// zero value row is added at each restart to reset counts across the boundary
+// getSeriesTogglesScript returns script to draw series of a chart shown,
+// toggled by checkboxes or clicking the legend, colors are kept of series
+func getSeriesTogglesScript() string {
+	return `
+<script>
+	var hiddenSeries = {};
+	var toggledChart = null;
+
+	function drawToggledSeries(chart, data, options) {
+		toggledChart = {chart: chart, data: data, options: options};
+		google.visualization.events.addListener(chart, 'select', function() {
+			var sel = chart.getSelection();
+			if (sel.length > 0 && sel[0].row == null && sel[0].column != null) { // legend clicked
+				var label = data.getColumnLabel(sel[0].column);
+				toggleSeries(label, hiddenSeries[label] == true);
+			}
+		});
+		redrawSeries();
+	}
+
+	function toggleSeries(label, checked) {
+		hiddenSeries[label] = !checked;
+		document.querySelectorAll('.seriesToggle').forEach(function(input) {
+			if (input.value == label) {
+				input.checked = checked;
+			}
+		});
+		redrawSeries();
+	}
+
+	function redrawSeries() {
+		if (toggledChart == null) {
+			return;
+		}
+		var data = toggledChart.data;
+		var columns = [];
+		for (var j = 0; j < data.getNumberOfColumns(); j++) {
+			if (j > 0 && data.getColumnRole(j) == '' && hiddenSeries[data.getColumnLabel(j)]) {
+				columns.push({label: data.getColumnLabel(j), type: 'number', calc: function() { return null; }});
+			} else {
+				columns.push(j);
+			}
+		}
+		var view = new google.visualization.DataView(data);
+		view.setColumns(columns);
+		toggledChart.chart.draw(view, toggledChart.options);
+	}
+</script>`
+}
+
+func getRestartsScript() string {
+	return `
+<script>
+	function addRestarts(data) {
+		var restarts = [{{range $i, $r := .Restarts}}new Date("{{substr $r 19}}"), {{end}}];
+		if (restarts.length == 0) {
+			return;
+		}
+		data.insertColumn(2, {type: 'string', role: 'annotation'});
+		for (var i = 0; i < restarts.length; i++) {
+			var row = [restarts[i]];
+			for (var j = 1; j < data.getNumberOfColumns(); j++) {
+				row.push(j == 2 ? 'restart' : 0);
+			}
+			data.addRow(row);
+		}
+		data.sort([{column: 0}]);
+	}
+</script>`
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * churn.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// CHURN_MIN_CONNS is the min connections of a client to flag it not pooling
+	CHURN_MIN_CONNS = 10
+	// CHURN_PER_MINUTE is the connections opened per minute of a client not pooling
+	CHURN_PER_MINUTE = 60
+	// CHURN_SHORT_LIVED_MILLIS is the lifetime of a short-lived connection
+	CHURN_SHORT_LIVED_MILLIS = 1000
+	// CHURN_SHORT_LIVED_RATIO is the ratio of short-lived connections of a client not pooling
+	CHURN_SHORT_LIVED_RATIO = 0.5
+)
+
+// LIFETIME_BUCKETS are upper bounds in milliseconds of connection lifetimes,
+// the last bucket is unbounded
+var LIFETIME_BUCKETS = []int{1000, 10 * 1000, 60 * 1000, 10 * 60 * 1000, 60 * 60 * 1000}
+
+// LIFETIME_LABELS are labels of connection lifetime buckets
+var LIFETIME_LABELS = []string{"< 1s", "1s - 10s", "10s - 1m", "1m - 10m", "10m - 1h", ">= 1h"}
+
+// ConnEvent is a connection accepted or ended
+type ConnEvent struct {
+	Accepted bool   `json:"accepted" bson:"accepted"`
+	Date     string `json:"date" bson:"date"`
+	IP       string `json:"ip" bson:"ip"`
+	Port     string `json:"port" bson:"port"`
+}
+
+// ClientChurn stores connection churn of a remote IP
+type ClientChurn struct {
+	Accepted        int     `json:"accepted" bson:"accepted"`
+	AvgLifeMilli    float64 `json:"avg_lifetime_ms" bson:"avg_lifetime_ms"`
+	Ended           int     `json:"ended" bson:"ended"`
+	IP              string  `json:"ip" bson:"ip"`
+	Lifetimes       []int   `json:"lifetimes" bson:"lifetimes"` // connections by LIFETIME_BUCKETS
+	MaxLifeMilli    int     `json:"max_lifetime_ms" bson:"max_lifetime_ms"`
+	MedianLifeMilli int     `json:"median_lifetime_ms" bson:"median_lifetime_ms"`
+	MinLifeMilli    int     `json:"min_lifetime_ms" bson:"min_lifetime_ms"`
+	NoPooling       bool    `json:"no_pooling" bson:"no_pooling"`
+	PerMinute       float64 `json:"per_minute" bson:"per_minute"` // connections opened per minute
+	ShortLived      int     `json:"short_lived" bson:"short_lived"`
+	ShortLivedRatio float64 `json:"short_lived_ratio" bson:"short_lived_ratio"` // of connections with lifetimes
+}
+
+// GetClientChurns returns connection churn by remote IP from connection
+// events in time order, clients opening the most connections first.  The
+// lifetime of a connection is from when its ip:port is accepted to when it
+// ended, connections accepted before the log began or still open when it
+// ended have no lifetime
+func GetClientChurns(events []ConnEvent) []ClientChurn {
+	clients := map[string]*ClientChurn{}
+	lifetimes := map[string][]int{}
+	first := map[string]time.Time{}
+	last := map[string]time.Time{}
+	opened := map[string]time.Time{}
+	for _, event := range events {
+		t, err := time.Parse("2006-01-02T15:04:05.000-0700", event.Date)
+		if err != nil {
+			continue
+		}
+		client, ok := clients[event.IP]
+		if !ok {
+			client = &ClientChurn{IP: event.IP, Lifetimes: make([]int, len(LIFETIME_LABELS))}
+			clients[event.IP] = client
+			first[event.IP] = t
+		}
+		last[event.IP] = t
+		remote := event.IP + ":" + event.Port
+		if event.Accepted {
+			client.Accepted++
+			opened[remote] = t
+			continue
+		}
+		client.Ended++
+		if begin, ok := opened[remote]; ok {
+			lifetimes[event.IP] = append(lifetimes[event.IP], int(t.Sub(begin).Milliseconds()))
+			delete(opened, remote)
+		}
+	}
+	docs := []ClientChurn{}
+	for ip, client := range clients {
+		minutes := last[ip].Sub(first[ip]).Minutes()
+		if minutes < 1 {
+			minutes = 1
+		}
+		client.PerMinute = float64(client.Accepted) / minutes
+		client.setLifetimes(lifetimes[ip])
+		client.NoPooling = client.Accepted >= CHURN_MIN_CONNS &&
+			(client.ShortLivedRatio >= CHURN_SHORT_LIVED_RATIO || client.PerMinute >= CHURN_PER_MINUTE)
+		docs = append(docs, *client)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].Accepted != docs[j].Accepted {
+			return docs[i].Accepted > docs[j].Accepted
+		}
+		return docs[i].IP < docs[j].IP
+	})
+	return docs
+}
+
+// setLifetimes sets the lifetime distribution of connections of a client
+func (ptr *ClientChurn) setLifetimes(lifetimes []int) {
+	if len(lifetimes) == 0 {
+		return
+	}
+	sort.Ints(lifetimes)
+	total := 0
+	for _, milli := range lifetimes {
+		total += milli
+		if milli < CHURN_SHORT_LIVED_MILLIS {
+			ptr.ShortLived++
+		}
+		i := 0
+		for i < len(LIFETIME_BUCKETS) && milli >= LIFETIME_BUCKETS[i] {
+			i++
+		}
+		ptr.Lifetimes[i]++
+	}
+	ptr.AvgLifeMilli = float64(total) / float64(len(lifetimes))
+	ptr.MaxLifeMilli = lifetimes[len(lifetimes)-1]
+	ptr.MedianLifeMilli = lifetimes[len(lifetimes)/2]
+	ptr.MinLifeMilli = lifetimes[0]
+	ptr.ShortLivedRatio = float64(ptr.ShortLived) / float64(len(lifetimes))
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * churn_template.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetChurnTemplate returns HTML
+func GetChurnTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Clients}}
+	<table width='100%'>
+		<caption>Connection Churn by Client</caption>
+		<tr><th rowspan='2'>ip</th><th rowspan='2'>accepted</th><th rowspan='2'>ended</th><th rowspan='2'>per minute</th>
+			<th rowspan='2'>short-lived</th><th colspan='4'>lifetime</th><th colspan='{{len .Labels}}'>lifetime distribution</th>
+			<th rowspan='2'>pooling</th></tr>
+		<tr><th>min</th><th>median</th><th>avg</th><th>max</th>
+			{{range $label := .Labels}}<th>{{$label}}</th>{{end}}</tr>
+	{{range $c := .Clients}}
+		<tr><td>{{$c.IP}}</td>
+			<td align='right'>{{numPrinter $c.Accepted}}</td>
+			<td align='right'>{{numPrinter $c.Ended}}</td>
+			<td align='right'>{{formatFloat $c.PerMinute}}</td>
+			<td align='right'>{{formatPercent $c.ShortLivedRatio}}</td>
+			<td align='right'>{{getDurationFromMillis $c.MinLifeMilli}}</td>
+			<td align='right'>{{getDurationFromMillis $c.MedianLifeMilli}}</td>
+			<td align='right'>{{getDurationFromMillis (toInt $c.AvgLifeMilli)}}</td>
+			<td align='right'>{{getDurationFromMillis $c.MaxLifeMilli}}</td>
+			{{range $n := $c.Lifetimes}}<td align='right'>{{numPrinter $n}}</td>{{end}}
+			{{if $c.NoPooling}}
+			<td><span style='color: red;'>not pooled</span></td>
+			{{else}}
+			<td></td>
+			{{end}}
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<div>A connection is short-lived if it ends within {{.ShortLivedMillis}} ms of being accepted; lifetimes are of
+		connections both accepted and ended in the logs.  A client of at least {{.MinConns}} connections is flagged
+		not pooled if at least {{formatPercent .ShortLivedRatio}} of its connections are short-lived or it opens
+		{{.PerMinute}} or more connections per minute.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no connections found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"formatFloat": func(f float64) string {
+			return fmt.Sprintf("%.1f", f)
+		},
+		"formatPercent": func(f float64) string {
+			return fmt.Sprintf("%.0f%%", 100*f)
+		},
+		"getDurationFromMillis": func(ms int) string {
+			if ms < 1000 {
+				return fmt.Sprintf("%d ms", ms)
+			}
+			return fmt.Sprintf("%.1f s", float64(ms)/1000)
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		},
+		"toInt": func(f float64) int {
+			return int(f)
+		}}).Parse(html)
+}
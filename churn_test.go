@@ -0,0 +1,43 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * churn_test.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetClientChurns(t *testing.T) {
+	events := []ConnEvent{
+		{Accepted: false, Date: "2023-01-01T00:00:00.100-0000", IP: "10.0.0.2", Port: "4000"}, // accepted before the log
+		{Accepted: true, Date: "2023-01-01T00:00:01.000-0000", IP: "10.0.0.2", Port: "4001"},
+	}
+	for i := 0; i < 20; i++ { // a new connection every second, closed in 200 ms
+		date := fmt.Sprintf("2023-01-01T00:00:%02d.000-0000", i)
+		events = append(events, ConnEvent{Accepted: true, Date: date, IP: "10.0.0.1", Port: fmt.Sprint(5000 + i)})
+		date = fmt.Sprintf("2023-01-01T00:00:%02d.200-0000", i)
+		events = append(events, ConnEvent{Accepted: false, Date: date, IP: "10.0.0.1", Port: fmt.Sprint(5000 + i)})
+	}
+	events = append(events, ConnEvent{Accepted: false, Date: "2023-01-01T00:10:01.000-0000", IP: "10.0.0.2", Port: "4001"})
+	clients := GetClientChurns(events)
+	if len(clients) != 2 || clients[0].IP != "10.0.0.1" {
+		t.Fatal("expected", "10.0.0.1 first", "but got", clients)
+	}
+	client := clients[0]
+	if client.Accepted != 20 || client.ShortLivedRatio != 1 || client.MedianLifeMilli != 200 || !client.NoPooling {
+		t.Fatal("expected", "20 short-lived connections not pooled", "but got", client)
+	}
+	if client.PerMinute != 20 || client.Lifetimes[0] != 20 {
+		t.Fatal("expected", 20, "but got", client.PerMinute, client.Lifetimes)
+	}
+	client = clients[1]
+	if client.Accepted != 1 || client.Ended != 2 || client.MaxLifeMilli != 600000 || client.NoPooling {
+		t.Fatal("expected", "one 10 minutes connection", "but got", client)
+	}
+	if client.Lifetimes[4] != 1 {
+		t.Fatal("expected", "a lifetime of 10m - 1h", "but got", client.Lifetimes)
+	}
+}
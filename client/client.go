@@ -0,0 +1,302 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * client.go
+ */
+
+// Package client queries hatchet datasets of the hatchet API, described by
+// the OpenAPI specification at /api/openapi.json, without the dependencies
+// of the hatchet package
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// API_PREFIX is the path prefix of hatchet APIs
+const API_PREFIX = "/api/hatchet/v1.0"
+
+// OpStat is a slow op shape
+type OpStat struct {
+	AvgMilli     float64 `json:"avg_ms"`
+	Count        int     `json:"count"`
+	FirstSeen    string  `json:"first_seen"`
+	Index        string  `json:"index"`
+	LastSeen     string  `json:"last_seen"`
+	MaxMilli     int     `json:"max_ms"`
+	Namespace    string  `json:"ns"`
+	Op           string  `json:"op"`
+	P50Milli     int     `json:"p50_ms"`
+	P95Milli     int     `json:"p95_ms"`
+	P99Milli     int     `json:"p99_ms"`
+	QueryPattern string  `json:"query_pattern"`
+	SortPattern  string  `json:"sort_pattern"`
+	Reslen       int     `json:"total_reslen"`
+	TotalMilli   int     `json:"total_ms"`
+}
+
+// LegacyLog is a log in the legacy format
+type LegacyLog struct {
+	ID        int    `json:"id,omitempty"`
+	Timestamp string `json:"date"`
+	Severity  string `json:"severity"`
+	Component string `json:"component"`
+	Context   string `json:"context"`
+	Message   string `json:"message"`
+	Source    string `json:"source,omitempty"`
+}
+
+// IndexSuggestion is an index suggested of a slow op shape
+type IndexSuggestion struct {
+	Count        int    `json:"count"`
+	Index        string `json:"index"`
+	Namespace    string `json:"ns"`
+	Op           string `json:"op"`
+	Plan         string `json:"plan"`
+	QueryPattern string `json:"query_pattern"`
+	Reason       string `json:"reason"`
+	SortPattern  string `json:"sort_pattern"`
+	TotalMilli   int    `json:"total_ms"`
+}
+
+// NameValues is a row of audit data
+type NameValues struct {
+	Name   string
+	Values []interface{}
+}
+
+// SlowOpsPage is a page of slow op shapes
+type SlowOpsPage struct {
+	Hatchet string   `json:"hatchet"`
+	HasMore bool     `json:"has_more"`
+	Limit   int      `json:"limit"`
+	Offset  int      `json:"offset"`
+	Ops     []OpStat `json:"ops"`
+	Total   int      `json:"total"`
+}
+
+// LogsPage is a page of logs
+type LogsPage struct {
+	Hatchet string      `json:"hatchet"`
+	HasMore bool        `json:"has_more"`
+	Limit   int         `json:"limit"`
+	Logs    []LegacyLog `json:"logs"`
+	Offset  int         `json:"offset"`
+}
+
+// SlowOpsOptions are parameters of slow op shapes, zero values are not set
+type SlowOpsOptions struct {
+	Limit       int
+	MinDuration float64 // minimum average milliseconds
+	Namespace   string  // regular expression
+	Offset      int
+	Ops         []string
+	Slow        bool   // above slow thresholds only
+	Sort        string // field, descending if prefixed with -
+}
+
+// LogsOptions are parameters of logs, zero values are not set
+type LogsOptions struct {
+	Component string
+	Context   string
+	Duration  string // {start},{end}
+	Limit     string // {offset},{limit} or {limit}
+	Severity  string
+	Source    string
+}
+
+// Client calls APIs of a hatchet web server
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Token      string // ID token sent as a bearer token to servers of -auth oidc
+}
+
+// New returns a client of a hatchet web server, e.g. http://localhost:3721
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTPClient: http.DefaultClient}
+}
+
+// GetSlowOps returns a page of slow op shapes of a hatchet
+func (ptr *Client) GetSlowOps(ctx context.Context, hatchetName string, opts SlowOpsOptions) (*SlowOpsPage, error) {
+	query := url.Values{}
+	setQuery(query, "limit", opts.Limit)
+	setQuery(query, "minDuration", opts.MinDuration)
+	setQuery(query, "ns", opts.Namespace)
+	setQuery(query, "offset", opts.Offset)
+	setQuery(query, "op", strings.Join(opts.Ops, ","))
+	setQuery(query, "slow", opts.Slow)
+	setQuery(query, "sort", opts.Sort)
+	var page SlowOpsPage
+	err := ptr.Get(ctx, getHatchetPath(hatchetName, "stats", "slowops"), query, &page)
+	return &page, err
+}
+
+// GetLogs returns a page of logs of a hatchet
+func (ptr *Client) GetLogs(ctx context.Context, hatchetName string, opts LogsOptions) (*LogsPage, error) {
+	query := url.Values{}
+	setQuery(query, "component", opts.Component)
+	setQuery(query, "context", opts.Context)
+	setQuery(query, "duration", opts.Duration)
+	setQuery(query, "limit", opts.Limit)
+	setQuery(query, "severity", opts.Severity)
+	setQuery(query, "source", opts.Source)
+	var page LogsPage
+	err := ptr.Get(ctx, getHatchetPath(hatchetName, "logs", "all"), query, &page)
+	return &page, err
+}
+
+// SearchLogs returns a page of logs of messages matching a full text query,
+// limit is of {offset},{limit} or {limit}
+func (ptr *Client) SearchLogs(ctx context.Context, hatchetName string, q string, limit string) (*LogsPage, error) {
+	query := url.Values{}
+	setQuery(query, "q", q)
+	setQuery(query, "limit", limit)
+	var page LogsPage
+	err := ptr.Get(ctx, getHatchetPath(hatchetName, "logs", "search"), query, &page)
+	return &page, err
+}
+
+// GetSlowestLogs returns the slowest topN ops logged of a hatchet
+func (ptr *Client) GetSlowestLogs(ctx context.Context, hatchetName string, topN int) ([]LegacyLog, error) {
+	query := url.Values{}
+	setQuery(query, "topN", topN)
+	var page LogsPage
+	err := ptr.Get(ctx, getHatchetPath(hatchetName, "logs", "slowops"), query, &page)
+	return page.Logs, err
+}
+
+// GetIndexSuggestions returns index suggestions of namespaces matching a
+// regular expression, all if empty
+func (ptr *Client) GetIndexSuggestions(ctx context.Context, hatchetName string, ns string) ([]IndexSuggestion, error) {
+	query := url.Values{}
+	setQuery(query, "ns", ns)
+	var doc struct {
+		Suggestions []IndexSuggestion `json:"suggestions"`
+	}
+	err := ptr.Get(ctx, getHatchetPath(hatchetName, "indexes", "suggestions"), query, &doc)
+	return doc.Suggestions, err
+}
+
+// GetAudit returns audit data by category of a hatchet
+func (ptr *Client) GetAudit(ctx context.Context, hatchetName string) (map[string][]NameValues, error) {
+	var doc struct {
+		Audit map[string][]NameValues `json:"audit"`
+	}
+	err := ptr.Get(ctx, getHatchetPath(hatchetName, "stats", "audit"), nil, &doc)
+	return doc.Audit, err
+}
+
+// GetSummary returns the Markdown summary of a hatchet
+func (ptr *Client) GetSummary(ctx context.Context, hatchetName string, topN int) (string, error) {
+	query := url.Values{}
+	setQuery(query, "topN", topN)
+	body, err := ptr.do(ctx, getHatchetPath(hatchetName, "report", "summary"), query)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(string(body), "{") {
+		return "", getAPIError(body)
+	}
+	return string(body), nil
+}
+
+// GetOpenAPISpec returns the OpenAPI specification of the server
+func (ptr *Client) GetOpenAPISpec(ctx context.Context) (map[string]interface{}, error) {
+	doc := map[string]interface{}{}
+	err := ptr.Get(ctx, "/api/openapi.json", nil, &doc)
+	return doc, err
+}
+
+// Get decodes the JSON response of a path of any API into v, errors of
+// responses of ok 0 are returned
+func (ptr *Client) Get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	body, err := ptr.do(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	if err = getAPIError(body); err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// do returns the body of a GET request
+func (ptr *Client) do(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	uri := ptr.BaseURL + path
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	if ptr.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+ptr.Token)
+	}
+	httpClient := ptr.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if err = getAPIError(body); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%v %v", resp.Status, uri)
+	}
+	return body, nil
+}
+
+// getAPIError returns the error of a response of ok 0
+func getAPIError(body []byte) error {
+	var doc struct {
+		Error string `json:"error"`
+		OK    *int   `json:"ok"`
+	}
+	if json.Unmarshal(body, &doc) == nil && doc.OK != nil && *doc.OK == 0 {
+		return errors.New(doc.Error)
+	}
+	return nil
+}
+
+// getHatchetPath returns the path of an API of a hatchet
+func getHatchetPath(hatchetName string, category string, attr string) string {
+	return fmt.Sprintf("%v/hatchets/%v/%v/%v", API_PREFIX, url.PathEscape(hatchetName), category, url.PathEscape(attr))
+}
+
+// setQuery sets a parameter of a value other than the zero value
+func setQuery(query url.Values, name string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			query.Set(name, v)
+		}
+	case int:
+		if v != 0 {
+			query.Set(name, fmt.Sprint(v))
+		}
+	case float64:
+		if v != 0 {
+			query.Set(name, fmt.Sprint(v))
+		}
+	case bool:
+		if v {
+			query.Set(name, "true")
+		}
+	}
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * client_test.go
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetSlowOps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/hatchet/v1.0/hatchets/mongod_1a2b3c/stats/slowops" &&
+			r.URL.RawQuery == "limit=2&op=find%2Cupdate&sort=-total_ms" {
+			w.Write([]byte(`{"hatchet":"mongod_1a2b3c","has_more":true,"limit":2,"offset":0,"total":5,
+				"ops":[{"op":"find","ns":"shop.orders","avg_ms":120.5,"count":3}]}`))
+			return
+		}
+		w.Write([]byte(`{"ok":0,"error":"invalid sort field \"bogus\""}`))
+	}))
+	defer server.Close()
+
+	api := New(server.URL + "/")
+	page, err := api.GetSlowOps(context.Background(), "mongod_1a2b3c",
+		SlowOpsOptions{Limit: 2, Ops: []string{"find", "update"}, Sort: "-total_ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !page.HasMore || page.Total != 5 || len(page.Ops) != 1 || page.Ops[0].AvgMilli != 120.5 {
+		t.Fatal("expected", "1 of 5 shapes", "but got", page)
+	}
+	if _, err = api.GetSlowOps(context.Background(), "mongod_1a2b3c", SlowOpsOptions{Sort: "bogus"}); err == nil ||
+		err.Error() != `invalid sort field "bogus"` {
+		t.Fatal("expected", "invalid sort field", "but got", err)
+	}
+}
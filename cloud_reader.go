@@ -0,0 +1,268 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * cloud_reader.go
+ */
+
+package hatchet
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	AZURE_API_VERSION = "2020-10-02"
+	GCS_READ_SCOPE    = "https://www.googleapis.com/auth/devstorage.read_only"
+	GOOGLE_TOKEN_URI  = "https://oauth2.googleapis.com/token"
+)
+
+// CLOUD_URL_SCHEMES are schemes of objects streamed from cloud storage
+var CLOUD_URL_SCHEMES = []string{"s3://", "gs://", "az://"}
+
+// IsCloudURL returns true if logname is an s3://, gs://, or az:// URL
+func IsCloudURL(logname string) bool {
+	for _, scheme := range CLOUD_URL_SCHEMES {
+		if strings.HasPrefix(logname, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCloudContent returns a reader streaming an object of
+// s3://bucket/key, gs://bucket/object, or az://account/container/blob
+func GetCloudContent(logname string, s3client *S3Client) (io.ReadCloser, error) {
+	u, err := url.Parse(logname)
+	if err != nil {
+		return nil, err
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return nil, fmt.Errorf("invalid object URL %v", logname)
+	}
+	switch u.Scheme {
+	case "s3":
+		if s3client == nil {
+			return nil, errors.New("no AWS S3 client")
+		}
+		return s3client.GetObjectReader(u.Host, key)
+	case "gs":
+		return getGCSObject(u.Host, key)
+	case "az":
+		return getAzureBlob(u.Host, key)
+	}
+	return nil, fmt.Errorf("unsupported scheme %v", u.Scheme)
+}
+
+// getGCSObject streams an object of Google Cloud Storage, the endpoint is
+// from STORAGE_EMULATOR_HOST if set
+func getGCSObject(bucket, object string) (io.ReadCloser, error) {
+	endpoint := "https://storage.googleapis.com"
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		endpoint = strings.TrimSuffix(host, "/")
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%v/storage/v1/b/%v/o/%v?alt=media",
+		endpoint, url.PathEscape(bucket), url.PathEscape(object)), nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := getGCSToken()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return getObjectBody(req)
+}
+
+// getGCSToken returns an access token from GOOGLE_OAUTH_ACCESS_TOKEN or the
+// application default credentials, empty for anonymous access
+func getGCSToken() (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	filename := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if filename == "" {
+		home, _ := os.UserHomeDir()
+		filename = filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+		if _, err := os.Stat(filename); err != nil {
+			return "", nil
+		}
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	var creds struct {
+		ClientEmail  string `json:"client_email"`
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		PrivateKey   string `json:"private_key"`
+		RefreshToken string `json:"refresh_token"`
+		TokenURI     string `json:"token_uri"`
+		Type         string `json:"type"`
+	}
+	if err = json.Unmarshal(data, &creds); err != nil {
+		return "", fmt.Errorf("%v: %v", filename, err)
+	}
+	if creds.TokenURI == "" {
+		creds.TokenURI = GOOGLE_TOKEN_URI
+	}
+	form := url.Values{}
+	switch creds.Type {
+	case "service_account":
+		assertion, err := signGoogleJWT(creds.ClientEmail, creds.PrivateKey, creds.TokenURI)
+		if err != nil {
+			return "", err
+		}
+		form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+		form.Set("assertion", assertion)
+	case "authorized_user":
+		form.Set("grant_type", "refresh_token")
+		form.Set("client_id", creds.ClientID)
+		form.Set("client_secret", creds.ClientSecret)
+		form.Set("refresh_token", creds.RefreshToken)
+	default:
+		return "", fmt.Errorf("unsupported credentials type %v of %v", creds.Type, filename)
+	}
+	resp, err := http.PostForm(creds.TokenURI, form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token exchange failed: %v", resp.Status)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// signGoogleJWT returns a RS256 signed JWT to exchange for an access token
+func signGoogleJWT(email string, privateKey string, aud string) (string, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return "", errors.New("invalid private key of service account")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return "", err
+		}
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("private key of service account is not RSA")
+	}
+	now := time.Now().Unix()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss": email, "scope": GCS_READ_SCOPE, "aud": aud, "iat": now, "exp": now + 3600})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hashed := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// getAzureBlob streams a blob of Azure Storage, credentials are from
+// AZURE_STORAGE_CONNECTION_STRING, AZURE_STORAGE_SAS_TOKEN, or
+// AZURE_STORAGE_KEY, anonymous access if none is set
+func getAzureBlob(account, blob string) (io.ReadCloser, error) {
+	endpoint := fmt.Sprintf("https://%v.blob.core.windows.net", account)
+	key := os.Getenv("AZURE_STORAGE_KEY")
+	sas := os.Getenv("AZURE_STORAGE_SAS_TOKEN")
+	if str := os.Getenv("AZURE_STORAGE_CONNECTION_STRING"); str != "" {
+		for _, pair := range strings.Split(str, ";") {
+			toks := strings.SplitN(pair, "=", 2)
+			if len(toks) != 2 {
+				continue
+			}
+			switch toks[0] {
+			case "AccountName":
+				if toks[1] != account {
+					return nil, fmt.Errorf("account %v doesn't match %v of connection string", account, toks[1])
+				}
+			case "AccountKey":
+				key = toks[1]
+			case "BlobEndpoint":
+				endpoint = strings.TrimSuffix(toks[1], "/")
+			case "SharedAccessSignature":
+				sas = toks[1]
+			}
+		}
+	}
+	uri := endpoint + "/" + (&url.URL{Path: blob}).EscapedPath()
+	if sas != "" {
+		uri += "?" + strings.TrimPrefix(sas, "?")
+	}
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", AZURE_API_VERSION)
+	if sas == "" && key != "" {
+		if err = signAzureRequest(req, account, key); err != nil {
+			return nil, err
+		}
+	}
+	return getObjectBody(req)
+}
+
+// signAzureRequest signs a GET request with the Shared Key of an account
+func signAzureRequest(req *http.Request, account string, key string) error {
+	secret, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return fmt.Errorf("invalid storage account key: %v", err)
+	}
+	// verb, 11 standard headers, canonicalized x-ms- headers, and resource
+	str := req.Method + strings.Repeat("\n", 12) +
+		"x-ms-date:" + req.Header.Get("x-ms-date") + "\n" +
+		"x-ms-version:" + req.Header.Get("x-ms-version") + "\n" +
+		"/" + account + req.URL.EscapedPath()
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(str))
+	req.Header.Set("Authorization", fmt.Sprintf("SharedKey %v:%v",
+		account, base64.StdEncoding.EncodeToString(mac.Sum(nil))))
+	return nil
+}
+
+// getObjectBody returns the body of a successful response
+func getObjectBody(req *http.Request) (io.ReadCloser, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%v://%v%v failed: %v", req.URL.Scheme, req.URL.Host, req.URL.Path, resp.Status)
+	}
+	return resp.Body, nil
+}
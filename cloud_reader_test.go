@@ -0,0 +1,116 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * cloud_reader_test.go
+ */
+
+package hatchet
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const cloudLogLine = `{"t":{"$date":"2023-01-01T00:00:00.000+00:00"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted"}`
+
+func TestIsCloudURL(t *testing.T) {
+	for logname, expected := range map[string]bool{"s3://bucket/mongod.log": true, "gs://bucket/mongod.log": true,
+		"az://account/logs/mongod.log": true, "https://host/mongod.log": false, "mongod.log": false} {
+		if IsCloudURL(logname) != expected {
+			t.Fatal("expected", expected, "of", logname, "but got", !expected)
+		}
+	}
+	if _, err := GetCloudContent("gs://bucket", nil); err == nil {
+		t.Fatal("expected", "invalid object URL", "but got", nil)
+	}
+	if _, err := GetCloudContent("s3://bucket/mongod.log", nil); err == nil {
+		t.Fatal("expected", "no AWS S3 client", "but got", nil)
+	}
+}
+
+func TestGetCloudContentGCS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.FormValue("assertion") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "secret"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		} else if r.URL.EscapedPath() != "/storage/v1/b/logs/o/node1%2Fmongod.log" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, cloudLogLine+"\n")
+	}))
+	defer server.Close()
+	filename := filepath.Join(t.TempDir(), "sa.json")
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	data, _ := json.Marshal(map[string]string{"type": "service_account", "client_email": "hatchet@example.com",
+		"private_key": string(pemKey), "token_uri": server.URL + "/token"})
+	if err = os.WriteFile(filename, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("STORAGE_EMULATOR_HOST", server.URL)
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", filename)
+	body, err := GetCloudContent("gs://logs/node1/mongod.log", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	if data, _ = io.ReadAll(body); string(data) != cloudLogLine+"\n" {
+		t.Fatal("expected", cloudLogLine, "but got", string(data))
+	}
+	if _, err = GetCloudContent("gs://logs/missing.log", nil); err == nil {
+		t.Fatal("expected", "404 Not Found", "but got", nil)
+	}
+}
+
+func TestGetCloudContentAzure(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString([]byte("account-key"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		signAzureRequest(r, "devstoreaccount1", secret)
+		if auth == "" || auth != r.Header.Get("Authorization") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		io.WriteString(w, cloudLogLine+"\n")
+	}))
+	defer server.Close()
+	t.Setenv("AZURE_STORAGE_CONNECTION_STRING", "DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;"+
+		"AccountKey="+secret+";BlobEndpoint="+server.URL+"/devstoreaccount1;")
+	body, err := GetCloudContent("az://devstoreaccount1/logs/mongod.log", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	reader, err := NewLogReader(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line, _ := reader.ReadString('\n'); line != cloudLogLine+"\n" {
+		t.Fatal("expected", cloudLogLine, "but got", line)
+	}
+	if _, err = GetCloudContent("az://another/logs/mongod.log", nil); err == nil {
+		t.Fatal("expected", "account mismatch", "but got", nil)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * collscans.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+	"strings"
+)
+
+// CollscanStat is slow ops of a namespace and query pattern with a COLLSCAN
+// plan summary
+type CollscanStat struct {
+	Count        int      `json:"count"`
+	MaxMilli     int      `json:"max_ms"`
+	Namespace    string   `json:"ns"`
+	Ops          []string `json:"ops"`
+	QueryPattern string   `json:"query_pattern"`
+	TotalMilli   int      `json:"total_ms"`
+}
+
+// GetCollscanStats returns COLLSCAN slow ops by namespace and query pattern,
+// the most total milliseconds first
+func GetCollscanStats(ops []OpStat) []CollscanStat {
+	stats := []CollscanStat{}
+	positions := map[string]int{}
+	for _, op := range ops {
+		if op.Index != COLLSCAN {
+			continue
+		}
+		key := op.Namespace + "\t" + op.QueryPattern
+		i, ok := positions[key]
+		if !ok {
+			i = len(stats)
+			positions[key] = i
+			stats = append(stats, CollscanStat{Namespace: op.Namespace, Ops: []string{}, QueryPattern: op.QueryPattern})
+		}
+		stat := &stats[i]
+		stat.Count += op.Count
+		stat.TotalMilli += op.TotalMilli
+		if op.MaxMilli > stat.MaxMilli {
+			stat.MaxMilli = op.MaxMilli
+		}
+		found := false
+		for _, name := range stat.Ops {
+			found = found || name == op.Op
+		}
+		if !found {
+			stat.Ops = append(stat.Ops, op.Op)
+			sort.Strings(stat.Ops)
+		}
+	}
+	sort.SliceStable(stats, func(i int, j int) bool {
+		return stats[i].TotalMilli > stats[j].TotalMilli
+	})
+	return stats
+}
+
+// GetCollscanAuditData returns audit data of the top N COLLSCAN shapes, the
+// name is the namespace and values are query pattern, ops, count, total ms,
+// and max ms
+func GetCollscanAuditData(ops []OpStat, topN int) []NameValues {
+	docs := []NameValues{}
+	for i, stat := range GetCollscanStats(ops) {
+		if topN > 0 && i >= topN {
+			break
+		}
+		docs = append(docs, NameValues{stat.Namespace, []interface{}{stat.QueryPattern, strings.Join(stat.Ops, ", "),
+			stat.Count, stat.TotalMilli, stat.MaxMilli}})
+	}
+	return docs
+}
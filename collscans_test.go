@@ -0,0 +1,32 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * collscans_test.go
+ */
+
+package hatchet
+
+import "testing"
+
+func TestGetCollscanStats(t *testing.T) {
+	ops := []OpStat{
+		{Op: "find", Namespace: "shop.orders", Index: COLLSCAN, QueryPattern: `{ status:1 }`, Count: 2, TotalMilli: 100, MaxMilli: 60},
+		{Op: "count", Namespace: "shop.orders", Index: COLLSCAN, QueryPattern: `{ status:1 }`, Count: 1, TotalMilli: 80, MaxMilli: 80},
+		{Op: "find", Namespace: "shop.orders", Index: "{ status:1 }", QueryPattern: `{ status:1 }`, Count: 9, TotalMilli: 900},
+		{Op: "find", Namespace: "shop.items", Index: COLLSCAN, QueryPattern: `{ sku:1 }`, Count: 5, TotalMilli: 500, MaxMilli: 200},
+	}
+	stats := GetCollscanStats(ops)
+	if len(stats) != 2 {
+		t.Fatal("expected", 2, "but got", len(stats))
+	}
+	if stats[0].Namespace != "shop.items" {
+		t.Fatal("expected", "shop.items", "but got", stats[0].Namespace)
+	}
+	stat := stats[1]
+	if stat.Count != 3 || stat.TotalMilli != 180 || stat.MaxMilli != 80 || len(stat.Ops) != 2 || stat.Ops[0] != "count" {
+		t.Fatal("expected", "3 180 80 [count find]", "but got", stat.Count, stat.TotalMilli, stat.MaxMilli, stat.Ops)
+	}
+	docs := GetCollscanAuditData(ops, 1)
+	if len(docs) != 1 || docs[0].Values[1] != "find" {
+		t.Fatal("expected", "1 doc of find", "but got", docs)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * compare.go
+ */
+
+package hatchet
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	DIVERGENCE_RATIO = 2.0
+	DIVERGENCE_DELTA = 1.0
+)
+
+// ComparisonRow is a metric of two hatchets side by side
+type ComparisonRow struct {
+	Category string     `json:"category"`
+	Metric   string     `json:"metric"`
+	Values   [2]float64 `json:"values"`
+	Diverged bool       `json:"diverged"`
+}
+
+// Comparison compares a good and a bad hatchet
+type Comparison struct {
+	Hatchets [2]string       `json:"hatchets"`
+	Summary  [2]string       `json:"summary"`
+	Rows     []ComparisonRow `json:"rows"`
+}
+
+// CompareHatchets returns op mix, slow ops rates, errors, and connections of
+// two hatchets, rates are per hour of logs to allow different log spans
+func CompareHatchets(good string, bad string) (*Comparison, error) {
+	comparison := &Comparison{Hatchets: [2]string{good, bad}}
+	metrics := [2]map[string]map[string]float64{}
+	for i, hatchetName := range comparison.Hatchets {
+		dbase, err := GetDatabase(hatchetName)
+		if err != nil {
+			return nil, err
+		}
+		info := dbase.GetHatchetInfo()
+		comparison.Summary[i] = GetHatchetSummary(info)
+		data, err := dbase.GetAuditData()
+		dbase.Close()
+		if err != nil {
+			return nil, err
+		}
+		metrics[i] = getComparisonMetrics(info, data)
+	}
+	for _, category := range []string{"op mix %", "slow ops", "errors", "connections"} {
+		names := map[string]bool{}
+		for i := range metrics {
+			for name := range metrics[i][category] {
+				names[name] = true
+			}
+		}
+		keys := []string{}
+		for name := range names {
+			keys = append(keys, name)
+		}
+		sort.Strings(keys)
+		for _, name := range keys {
+			row := ComparisonRow{Category: category, Metric: name,
+				Values: [2]float64{metrics[0][category][name], metrics[1][category][name]}}
+			row.Diverged = IsDivergent(row.Values[0], row.Values[1])
+			comparison.Rows = append(comparison.Rows, row)
+		}
+	}
+	return comparison, nil
+}
+
+// IsDivergent returns true if one value is at least DIVERGENCE_RATIO times of
+// the other and they differ by at least DIVERGENCE_DELTA
+func IsDivergent(a float64, b float64) bool {
+	lo, hi := math.Min(a, b), math.Max(a, b)
+	return hi-lo >= DIVERGENCE_DELTA && hi >= lo*DIVERGENCE_RATIO
+}
+
+func getComparisonMetrics(info HatchetInfo, data map[string][]NameValues) map[string]map[string]float64 {
+	metrics := map[string]map[string]float64{"op mix %": {}, "slow ops": {}, "errors": {}, "connections": {}}
+	hours := getLogHours(info.Start, info.End)
+	total := 0
+	for _, doc := range data["op"] {
+		total += ToInt(doc.Values[0])
+	}
+	for _, doc := range data["op"] {
+		metrics["op mix %"][doc.Name] = math.Round(1000*ToFloat64(doc.Values[0])/float64(total)) / 10
+	}
+	metrics["slow ops"]["per hour"] = math.Round(10*float64(total)/hours) / 10
+	for _, doc := range data["stats"] {
+		if doc.Name == "avgMilli" || doc.Name == "maxMilli" {
+			metrics["slow ops"][doc.Name] = ToFloat64(doc.Values[0])
+		} else if doc.Name == "maxConns" {
+			metrics["connections"][doc.Name] = ToFloat64(doc.Values[0])
+		}
+	}
+	for _, doc := range data["collscan"] {
+		if doc.Name == "count" {
+			metrics["slow ops"]["COLLSCAN per hour"] = math.Round(10*ToFloat64(doc.Values[0])/hours) / 10
+		}
+	}
+	for _, doc := range data["exception"] {
+		metrics["errors"][doc.Name+" per hour"] = math.Round(10*ToFloat64(doc.Values[0])/hours) / 10
+	}
+	failed := 0
+	for _, doc := range data["failed"] {
+		failed += ToInt(doc.Values[0])
+	}
+	metrics["errors"]["failed per hour"] = math.Round(10*float64(failed)/hours) / 10
+	accepted := 0
+	for _, doc := range data["ip"] {
+		accepted += ToInt(doc.Values[0])
+	}
+	metrics["connections"]["accepted per hour"] = math.Round(10*float64(accepted)/hours) / 10
+	metrics["connections"]["client IPs"] = float64(len(data["ip"]))
+	return metrics
+}
+
+// getLogHours returns hours between two timestamps, at least a minute
+func getLogHours(start string, end string) float64 {
+	layout := "2006-01-02T15:04:05"
+	hours := 1.0 / 60
+	if len(start) < len(layout) || len(end) < len(layout) {
+		return hours
+	}
+	stime, err := time.Parse(layout, start[:len(layout)])
+	if err != nil {
+		return hours
+	}
+	etime, err := time.Parse(layout, end[:len(layout)])
+	if err != nil {
+		return hours
+	}
+	return math.Max(hours, etime.Sub(stime).Hours())
+}
+
+// String returns comparison in a table, diverged metrics are marked with *
+func (ptr *Comparison) String() string {
+	var buffer bytes.Buffer
+	line := "+-------------+--------------------------+--------------+--------------+---+\n"
+	buffer.WriteString(fmt.Sprintf("A: %v\nB: %v\n", ptr.Summary[0], ptr.Summary[1]))
+	buffer.WriteString(line)
+	buffer.WriteString(fmt.Sprintf("| %-11s | %-24s | %12s | %12s |   |\n", "Category", "Metric", "A", "B"))
+	buffer.WriteString(line)
+	for _, row := range ptr.Rows {
+		mark := " "
+		if row.Diverged {
+			mark = "*"
+		}
+		metric := row.Metric
+		if len(metric) > 24 {
+			metric = metric[:24]
+		}
+		buffer.WriteString(fmt.Sprintf("| %-11s | %-24s | %12v | %12v | %v |\n",
+			row.Category, metric, row.Values[0], row.Values[1], mark))
+	}
+	buffer.WriteString(line)
+	return buffer.String()
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * compare_handler.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// CompareHandler responds to API calls
+func CompareHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	/** APIs
+	 * /hatchets/{hatchet}/compare/{other}
+	 */
+	hatchetName := params.ByName("hatchet")
+	other := params.ByName("attr")
+	if GetLogv2().verbose {
+		log.Println("CompareHandler", r.URL.Path, hatchetName, other)
+	}
+	comparison, err := CompareHatchets(hatchetName, other)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	templ, err := GetCompareTemplate()
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	doc := map[string]interface{}{"Hatchet": hatchetName, "Comparison": comparison}
+	if err = templ.Execute(w, doc); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+}
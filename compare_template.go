@@ -0,0 +1,45 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * compare_template.go
+ */
+
+package hatchet
+
+import (
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetCompareTemplate returns HTML
+func GetCompareTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+	<p>A: {{index .Comparison.Summary 0}}<br/>B: {{index .Comparison.Summary 1}}</p>
+	<table>
+		<caption>Comparison, divergent metrics are highlighted</caption>
+		<tr><th>Category</th><th>Metric</th>
+			<th><a href='/hatchets/{{index .Comparison.Hatchets 0}}/stats/audit'>A</a></th>
+			<th><a href='/hatchets/{{index .Comparison.Hatchets 1}}/stats/audit'>B</a></th></tr>
+	{{range $n, $row := .Comparison.Rows}}
+		<tr><td>{{$row.Category}}</td><td>{{$row.Metric}}</td>
+		{{if $row.Diverged}}
+			<td align=right><mark>{{numPrinter (index $row.Values 0)}}</mark></td>
+			<td align=right><mark>{{numPrinter (index $row.Values 1)}}</mark></td>
+		{{else}}
+			<td align=right>{{numPrinter (index $row.Values 0)}}</td>
+			<td align=right>{{numPrinter (index $row.Values 1)}}</td>
+		{{end}}
+		</tr>
+	{{end}}
+	</table>
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"numPrinter": func(n float64) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
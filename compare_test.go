@@ -0,0 +1,44 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * compare_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestIsDivergent(t *testing.T) {
+	tests := []struct {
+		a, b     float64
+		diverged bool
+	}{{0, 0, false}, {10, 12, false}, {10, 25, true}, {0, 5, true}, {0.2, 0.6, false}, {40, 10, true}}
+	for _, test := range tests {
+		if IsDivergent(test.a, test.b) != test.diverged {
+			t.Fatal("expected", test.diverged, "but got", !test.diverged, test.a, test.b)
+		}
+	}
+}
+
+func TestGetComparisonMetrics(t *testing.T) {
+	info := HatchetInfo{Start: "2023-01-01T00:00:00.000Z", End: "2023-01-01T02:00:00.000Z"}
+	data := map[string][]NameValues{
+		"op":        {{"find", []interface{}{30}}, {"update", []interface{}{10}}},
+		"exception": {{"Error", []interface{}{8}}},
+		"ip":        {{"10.0.0.1", []interface{}{100}}, {"10.0.0.2", []interface{}{20}}},
+	}
+	metrics := getComparisonMetrics(info, data)
+	if metrics["op mix %"]["find"] != 75 {
+		t.Fatal("expected", 75, "but got", metrics["op mix %"]["find"])
+	}
+	if metrics["slow ops"]["per hour"] != 20 {
+		t.Fatal("expected", 20, "but got", metrics["slow ops"]["per hour"])
+	}
+	if metrics["errors"]["Error per hour"] != 4 {
+		t.Fatal("expected", 4, "but got", metrics["errors"]["Error per hour"])
+	}
+	if metrics["connections"]["accepted per hour"] != 60 {
+		t.Fatal("expected", 60, "but got", metrics["connections"]["accepted per hour"])
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * config_changes.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// types of runtime configuration changes
+const (
+	CONFIG_COMMIT_QUORUM = "commitQuorum"
+	CONFIG_FCV           = "featureCompatibilityVersion"
+	CONFIG_PARAMETER     = "setParameter"
+)
+
+// legacy format of a setParameter, e.g. successfully set parameter logLevel to 1 (was 0)
+var setParameterRegex = regexp.MustCompile(`(?i)successfully set parameter (\S+) to (.*) \(was (.*)\)`)
+
+// ConfigChange is a change of a server parameter, the featureCompatibilityVersion,
+// or the commit quorum of an index build
+type ConfigChange struct {
+	Date     string `json:"date" bson:"date"`
+	Name     string `json:"name" bson:"config_name"`
+	NewValue string `json:"new_value" bson:"config_new"`
+	OldValue string `json:"old_value" bson:"config_old"`
+	Type     string `json:"type" bson:"config_change"`
+}
+
+// GetConfigChange returns a setParameter, a featureCompatibilityVersion
+// change, or a commit quorum change of an index build, false otherwise
+func GetConfigChange(doc *Logv2Info) (ConfigChange, bool) {
+	change := ConfigChange{}
+	msg := strings.ToLower(doc.Msg)
+	attr := doc.Attr.Map()
+	if strings.Contains(msg, "set parameter") {
+		if name, ok := attr["parameterName"].(string); ok {
+			change.Type, change.Name = CONFIG_PARAMETER, name
+			change.NewValue, change.OldValue = getConfigValue(attr["newValue"]), getConfigValue(attr["oldValue"])
+			return change, true
+		} else if matches := setParameterRegex.FindStringSubmatch(doc.Msg); matches != nil {
+			change.Type, change.Name, change.NewValue, change.OldValue = CONFIG_PARAMETER, matches[1], matches[2], matches[3]
+			return change, true
+		}
+		return change, false
+	}
+	if strings.Contains(msg, "featurecompatibilityversion") && !strings.Contains(msg, "starting") {
+		if attr["newVersion"] == nil {
+			return change, false
+		}
+		change.Type, change.Name = CONFIG_FCV, CONFIG_FCV
+		change.NewValue, change.OldValue = getConfigValue(attr["newVersion"]), getConfigValue(attr["previousVersion"])
+		return change, true
+	}
+	if strings.Contains(msg, "commit quorum") {
+		for _, key := range []string{"newCommitQuorum", "commitQuorum"} {
+			if attr[key] != nil {
+				change.Type, change.NewValue = CONFIG_COMMIT_QUORUM, getConfigValue(attr[key])
+				break
+			}
+		}
+		if change.Type == "" {
+			return change, false
+		}
+		change.OldValue = getConfigValue(attr["currentCommitQuorum"])
+		change.Name = getConfigIndexBuild(attr)
+		return change, true
+	}
+	if command, ok := attr["command"].(bson.D); ok && len(command) > 0 && command[0].Key == "setIndexCommitQuorum" {
+		params := command.Map()
+		change.Type, change.NewValue = CONFIG_COMMIT_QUORUM, getConfigValue(params["commitQuorum"])
+		change.Name = getConfigIndexBuild(params)
+		if doc.Attributes.NS != "" {
+			change.Name = strings.TrimSpace(doc.Attributes.NS + " " + change.Name)
+		}
+		return change, true
+	}
+	return change, false
+}
+
+// GetConfigAuditData returns configuration changes by date with the type,
+// name, and old and new values
+func GetConfigAuditData(changes []ConfigChange) []NameValues {
+	docs := []NameValues{}
+	for _, change := range changes {
+		docs = append(docs, NameValues{change.Date, []interface{}{change.Type, change.Name, change.OldValue, change.NewValue}})
+	}
+	return docs
+}
+
+// getConfigIndexBuild returns index names of an index build, or its UUID
+func getConfigIndexBuild(attr map[string]interface{}) string {
+	if names, ok := attr["indexNames"].(bson.A); ok {
+		arr := []string{}
+		for _, name := range names {
+			arr = append(arr, fmt.Sprintf("%v", name))
+		}
+		return strings.Join(arr, ",")
+	}
+	if uuid := attr["buildUUID"]; uuid != nil {
+		return getConfigValue(uuid)
+	}
+	return ""
+}
+
+// getConfigValue returns a parameter value as a string, empty if not logged
+func getConfigValue(value interface{}) string {
+	if value == nil {
+		return ""
+	} else if str, ok := value.(string); ok {
+		return str
+	}
+	return strings.TrimSpace(fmt.Sprintf("%v", toLegacyString(value)))
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * config_changes_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetConfigChange(t *testing.T) {
+	tests := []struct {
+		str    string
+		change ConfigChange
+		ok     bool
+	}{
+		{`{"t":{"$date":"2023-01-01T00:01:00.000+00:00"},"s":"I","c":"COMMAND","id":23435,"ctx":"conn1","msg":"Successfully set parameter to new value","attr":{"parameterName":"logLevel","newValue":1,"oldValue":0}}`,
+			ConfigChange{Type: CONFIG_PARAMETER, Name: "logLevel", OldValue: "0", NewValue: "1"}, true},
+		{`{"t":{"$date":"2023-01-01T00:02:00.000+00:00"},"s":"I","c":"COMMAND","id":6744302,"ctx":"conn1","msg":"setFeatureCompatibilityVersion succeeded","attr":{"upgradeOrDowngrade":"upgrade","previousVersion":"5.0","newVersion":"6.0"}}`,
+			ConfigChange{Type: CONFIG_FCV, Name: CONFIG_FCV, OldValue: "5.0", NewValue: "6.0"}, true},
+		{`{"t":{"$date":"2023-01-01T00:02:00.000+00:00"},"s":"I","c":"COMMAND","id":6744300,"ctx":"conn1","msg":"setFeatureCompatibilityVersion starting","attr":{"upgradeOrDowngrade":"upgrade","previousVersion":"5.0","newVersion":"6.0"}}`,
+			ConfigChange{}, false},
+		{`{"t":{"$date":"2023-01-01T00:03:00.000+00:00"},"s":"I","c":"INDEX","id":4715300,"ctx":"conn2","msg":"Updating commit quorum of index build","attr":{"buildUUID":"b1","currentCommitQuorum":"majority","newCommitQuorum":2}}`,
+			ConfigChange{Type: CONFIG_COMMIT_QUORUM, Name: "b1", OldValue: "majority", NewValue: "2"}, true},
+		{`{"t":{"$date":"2023-01-01T00:04:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn3","msg":"Slow query","attr":{"type":"command","ns":"shop.$cmd","command":{"setIndexCommitQuorum":"orders","indexNames":["status_1"],"commitQuorum":"votingMembers","$db":"shop"},"durationMillis":120}}`,
+			ConfigChange{Type: CONFIG_COMMIT_QUORUM, Name: "status_1", NewValue: "votingMembers"}, true},
+		{`{"t":{"$date":"2023-01-01T00:05:00.000+00:00"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted","attr":{"remote":"127.0.0.1:5555","connectionId":5,"connectionCount":1}}`,
+			ConfigChange{}, false},
+	}
+	for _, test := range tests {
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(test.str), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		change, ok := GetConfigChange(&doc)
+		if change != test.change || ok != test.ok {
+			t.Fatal("expected", test.change, test.ok, "but got", change, ok)
+		}
+	}
+
+	doc := Logv2Info{Severity: "I", Component: "COMMAND", Msg: "successfully set parameter logLevel to 2 (was 0)"}
+	change, ok := GetConfigChange(&doc)
+	if !ok || change.Name != "logLevel" || change.NewValue != "2" || change.OldValue != "0" {
+		t.Fatal("expected", "logLevel from 0 to 2", "but got", change, ok)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * conn_limits.go
+ */
+
+package hatchet
+
+import (
+	"regexp"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CONN_LIMIT_WARN_PERCENT is the open connections, in percent of the
+// connection limit, to warn the server approached its limit
+const CONN_LIMIT_WARN_PERCENT = 80
+
+var (
+	connRefusedRegex = regexp.MustCompile(`(?i)connection refused because (?:there are )?too many open connections(?::\s*(\d+))?`)
+	connPoolRegex    = regexp.MustCompile(`(?i)couldn't get a connection within the time limit|connection pool (?:is )?exhausted`)
+	openFilesRegex   = regexp.MustCompile(`(?i)too many open files`)
+)
+
+// ConnLimitWindow counts refused connections, pool exhaustion, and open
+// files errors, and keeps the peak open connections of a minute
+type ConnLimitWindow struct {
+	Files   int // too many open files errors
+	Peak    int // open connections logged
+	Pool    int // connection pool exhaustion
+	Refused int // connections refused of too many open connections
+}
+
+// ConnLimitStats counts connection limit and pool exhaustion warnings by
+// minute to tell whether a server approached or hit its connection limit
+type ConnLimitStats struct {
+	Configured int // net.maxIncomingConnections of startup options
+	Peak       int // peak open connections logged
+	RefusedAt  int // highest open connections when connections were refused
+	Windows    map[string]*ConnLimitWindow
+}
+
+// NewConnLimitStats returns ConnLimitStats
+func NewConnLimitStats() *ConnLimitStats {
+	return &ConnLimitStats{Windows: map[string]*ConnLimitWindow{}}
+}
+
+// Add counts a connection limit warning or keeps open connections of the
+// minute of date
+func (ptr *ConnLimitStats) Add(doc *Logv2Info, date string) {
+	if len(date) < 16 {
+		return
+	}
+	minute := date[:16]
+	attr := doc.Attr.Map()
+	if doc.Msg == "Options set by command line" {
+		if options, ok := attr["options"].(bson.D); ok {
+			if net, ok := options.Map()["net"].(bson.D); ok {
+				ptr.Configured = ToInt(net.Map()["maxIncomingConnections"])
+			}
+		}
+		return
+	}
+	window := ptr.Windows[minute]
+	if window == nil {
+		window = &ConnLimitWindow{}
+	}
+	message := doc.Msg + " " + doc.Message
+	if doc.Msg == "Connection accepted" {
+		count := ToInt(attr["connectionCount"])
+		if count > window.Peak {
+			window.Peak = count
+		}
+		if count > ptr.Peak {
+			ptr.Peak = count
+		}
+	} else if matches := connRefusedRegex.FindStringSubmatch(message); matches != nil {
+		count := ToInt(attr["connectionCount"])
+		if count == 0 && matches[1] != "" {
+			count, _ = strconv.Atoi(matches[1])
+		}
+		if count > ptr.RefusedAt {
+			ptr.RefusedAt = count
+		}
+		if count > window.Peak {
+			window.Peak = count
+		}
+		if count > ptr.Peak {
+			ptr.Peak = count
+		}
+		window.Refused++
+	} else if connPoolRegex.MatchString(message) {
+		window.Pool++
+	} else if openFilesRegex.MatchString(message) {
+		window.Files++
+	} else {
+		return
+	}
+	ptr.Windows[minute] = window
+}
+
+// GetLimit returns the configured connection limit, or the open connections
+// when connections were refused, 0 if unknown
+func (ptr *ConnLimitStats) GetLimit() int {
+	if ptr.Configured > 0 {
+		return ptr.Configured
+	}
+	return ptr.RefusedAt
+}
+
+// GetAuditData returns audit data by type of minutes with refused connections,
+// pool exhaustion, or open files errors, or of open connections of at least
+// CONN_LIMIT_WARN_PERCENT of the limit, and the limit and peak connections
+func (ptr *ConnLimitStats) GetAuditData() map[string][]NameValue {
+	data := map[string][]NameValue{}
+	limit := ptr.GetLimit()
+	for minute, window := range ptr.Windows {
+		approached := limit > 0 && 100*window.Peak >= CONN_LIMIT_WARN_PERCENT*limit
+		if window.Refused == 0 && window.Pool == 0 && window.Files == 0 && !approached {
+			continue
+		}
+		data["conn-limit"] = append(data["conn-limit"], NameValue{minute, window.Refused})
+		data["conn-limit-pool"] = append(data["conn-limit-pool"], NameValue{minute, window.Pool})
+		data["conn-limit-files"] = append(data["conn-limit-files"], NameValue{minute, window.Files})
+		data["conn-limit-peak"] = append(data["conn-limit-peak"], NameValue{minute, window.Peak})
+	}
+	if len(data) > 0 {
+		data["conn-limit-max"] = []NameValue{{"limit", limit}, {"peak", ptr.Peak}, {"configured", ptr.Configured}}
+	}
+	return data
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * conn_limits_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestConnLimitStats(t *testing.T) {
+	stats := NewConnLimitStats()
+	for i := 1; i <= 9; i++ {
+		doc := Logv2Info{Component: "NETWORK", Msg: "Connection accepted",
+			Attr: bson.D{{Key: "connectionCount", Value: int32(i)}}}
+		stats.Add(&doc, "2023-01-01T00:01:00.000Z")
+	}
+	stats.Add(&Logv2Info{Component: "NETWORK", Msg: "Connection accepted",
+		Attr: bson.D{{Key: "connectionCount", Value: int32(2)}}}, "2023-01-01T00:02:00.000Z")
+	stats.Add(&Logv2Info{Component: "NETWORK", Msg: "Connection refused because there are too many open connections",
+		Attr: bson.D{{Key: "connectionCount", Value: int32(10)}}}, "2023-01-01T00:03:00.000Z")
+	stats.Add(&Logv2Info{Component: "NETWORK", Msg: "connection refused because too many open connections: 10",
+		Message: "connection refused because too many open connections: 10"}, "2023-01-01T00:03:30.000Z")
+	stats.Add(&Logv2Info{Component: "NETWORK", Msg: "Error accepting new connection on local endpoint",
+		Message: `Error accepting new connection on local endpoint error: "Too many open files"`}, "2023-01-01T00:04:00.000Z")
+	if stats.GetLimit() != 10 || stats.Peak != 10 {
+		t.Fatal("expected", 10, "but got", stats.GetLimit(), stats.Peak)
+	}
+	data := stats.GetAuditData()
+	docs := MergeAuditSeries(AUDIT_SERIES["conn-limit"], data)
+	if len(docs) != 3 {
+		t.Fatal("expected", 3, "but got", docs)
+	}
+	if docs[0].Name != "2023-01-01T00:03" || ToInt(docs[0].Values[0]) != 2 {
+		t.Fatal("expected", "2 refused in 2023-01-01T00:03", "but got", docs[0])
+	}
+	for _, doc := range docs {
+		if doc.Name == "2023-01-01T00:02" {
+			t.Fatal("expected", "no warning below the limit", "but got", doc)
+		} else if doc.Name == "2023-01-01T00:01" && ToInt(doc.Values[3]) != 9 {
+			t.Fatal("expected", 9, "but got", doc.Values[3])
+		} else if doc.Name == "2023-01-01T00:04" && ToInt(doc.Values[2]) != 1 {
+			t.Fatal("expected", 1, "but got", doc.Values[2])
+		}
+	}
+
+	stats = NewConnLimitStats()
+	stats.Add(&Logv2Info{Component: "CONTROL", Msg: "Options set by command line",
+		Attr: bson.D{{Key: "options", Value: bson.D{{Key: "net", Value: bson.D{{Key: "maxIncomingConnections", Value: int32(100)}}}}}}},
+		"2023-01-01T00:00:00.000Z")
+	stats.Add(&Logv2Info{Component: "NETWORK", Msg: "Connection accepted",
+		Attr: bson.D{{Key: "connectionCount", Value: int32(50)}}}, "2023-01-01T00:01:00.000Z")
+	if data = stats.GetAuditData(); len(data) != 0 {
+		t.Fatal("expected", "no warnings", "but got", data)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * conns_by_ip.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+)
+
+// CONNS_OTHERS is the series of connections of IPs not of the top N
+const CONNS_OTHERS = "others"
+
+// CONNS_TOP_N is the number of IPs of their own series by default
+const CONNS_TOP_N = 10
+
+// IPConns is counts of connections accepted and ended of an IP in a period
+type IPConns struct {
+	Accepted int    `bson:"accepted"`
+	Date     string `bson:"date"`
+	Ended    int    `bson:"ended"`
+	IP       string `bson:"ip"`
+}
+
+// GetConnectionsByIPSeries returns labels, IPs of the most connections at
+// peak and others if more than topN IPs, and series of open connections
+// estimated of connections accepted minus ended, never below 0, of periods
+// of docs in order of dates
+func GetConnectionsByIPSeries(docs []IPConns, topN int) ([]string, []TimeSeries) {
+	dates := []string{}
+	opens := map[string]map[string]int{} // date, ip, open connections
+	open := map[string]int{}
+	peaks := map[string]int{}
+	for _, doc := range docs {
+		if _, ok := opens[doc.Date]; !ok {
+			dates = append(dates, doc.Date)
+			opens[doc.Date] = map[string]int{}
+		}
+		open[doc.IP] += doc.Accepted - doc.Ended
+		if open[doc.IP] < 0 { // connections accepted before the period
+			open[doc.IP] = 0
+		}
+		opens[doc.Date][doc.IP] = open[doc.IP]
+		if peak, ok := peaks[doc.IP]; !ok || open[doc.IP] > peak {
+			peaks[doc.IP] = open[doc.IP]
+		}
+	}
+	ips := []string{}
+	for ip := range peaks {
+		ips = append(ips, ip)
+	}
+	sort.Slice(ips, func(i int, j int) bool {
+		if peaks[ips[i]] != peaks[ips[j]] {
+			return peaks[ips[i]] > peaks[ips[j]]
+		}
+		return ips[i] < ips[j]
+	})
+	labels := ips
+	if len(ips) > topN {
+		labels = append(append([]string{}, ips[:topN]...), CONNS_OTHERS)
+	}
+	series := []TimeSeries{}
+	last := map[string]int{} // open connections of IPs not of a period
+	for _, date := range dates {
+		for ip, n := range opens[date] {
+			last[ip] = n
+		}
+		doc := TimeSeries{Date: date, Values: make([]float64, len(labels))}
+		for i, ip := range ips {
+			if i < len(labels) && labels[i] == ip {
+				doc.Values[i] = float64(last[ip])
+			} else {
+				doc.Values[len(labels)-1] += float64(last[ip])
+			}
+		}
+		series = append(series, doc)
+	}
+	return labels, series
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * conns_by_ip_test.go
+ */
+
+package hatchet
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetConnectionsByIPSeries(t *testing.T) {
+	docs := []IPConns{
+		{Date: "2023-03-01T10:00", IP: "10.0.0.1", Accepted: 5},
+		{Date: "2023-03-01T10:00", IP: "10.0.0.2", Accepted: 2},
+		{Date: "2023-03-01T10:00", IP: "10.0.0.3", Ended: 4}, // accepted before the period
+		{Date: "2023-03-01T10:01", IP: "10.0.0.2", Accepted: 8, Ended: 1},
+		{Date: "2023-03-01T10:01", IP: "10.0.0.4", Accepted: 1},
+		{Date: "2023-03-01T10:02", IP: "10.0.0.1", Ended: 5},
+		{Date: "2023-03-01T10:02", IP: "10.0.0.3", Accepted: 3},
+	}
+	labels, series := GetConnectionsByIPSeries(docs, 2)
+	expected := []string{"10.0.0.2", "10.0.0.1", CONNS_OTHERS}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Fatal("expected", expected, "but got", labels)
+	}
+	values := [][]float64{{2, 5, 0}, {9, 5, 1}, {9, 0, 4}}
+	if len(series) != len(values) {
+		t.Fatal("expected", len(values), "but got", len(series))
+	}
+	for i, doc := range series {
+		if !reflect.DeepEqual(doc.Values, values[i]) {
+			t.Fatal("expected", values[i], "of", doc.Date, "but got", doc.Values)
+		}
+	}
+
+	labels, _ = GetConnectionsByIPSeries(docs, CONNS_TOP_N)
+	if len(labels) != 4 || labels[3] != "10.0.0.4" {
+		t.Fatal("expected", "4 IPs without others", "but got", labels)
+	}
+}
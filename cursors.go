@@ -0,0 +1,170 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * cursors.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const CURSOR_NOT_FOUND = 43
+
+// cursor lifecycle
+const (
+	CURSOR_MAX_OPEN          = 100000 // open cursors tracked to attribute timeouts to namespaces
+	CURSOR_TIMEOUT_EXCESSIVE = 10     // cursor timeouts of a namespace flagged as excessive
+	CURSOR_UNKNOWN_NS        = "unknown"
+)
+
+// openCursor is a cursor left open by a logged find or aggregate
+type openCursor struct {
+	namespace string
+	noTimeout bool
+}
+
+// CursorStats counts cursor errors and getMore ops by namespace and tracks
+// cursors opened, exhausted, killed, and timed out
+type CursorStats struct {
+	Expired  int // cursors timed out counted by the cursor monitor
+	GetMores map[string]int
+	Killed   map[string]int
+	NotFound map[string]int
+	Open     map[string]openCursor // by cursor id
+	TimedOut map[string]int
+	Warnings map[string]int
+}
+
+// NewCursorStats returns CursorStats
+func NewCursorStats() *CursorStats {
+	return &CursorStats{GetMores: map[string]int{}, Killed: map[string]int{}, NotFound: map[string]int{},
+		Open: map[string]openCursor{}, TimedOut: map[string]int{}, Warnings: map[string]int{}}
+}
+
+// Add counts a parsed log
+func (ptr *CursorStats) Add(doc *Logv2Info, stat *OpStat) {
+	if ns, ok := GetCursorNotFoundNS(doc); ok {
+		ptr.NotFound[ns]++
+		return
+	}
+	if stat != nil && stat.Op == cmdGetMore && stat.Namespace != "" {
+		ptr.GetMores[stat.Namespace]++
+	}
+	ptr.addLifecycle(doc)
+}
+
+// addLifecycle tracks cursors left open by finds and aggregates until
+// exhausted by a getMore, killed, or timed out, and counts cursor warnings
+func (ptr *CursorStats) addLifecycle(doc *Logv2Info) {
+	attr := doc.Attr.Map()
+	lower := strings.ToLower(doc.Msg)
+	if n, ok := attr["numTimedOut"]; ok {
+		ptr.Expired += ToInt(n)
+		return
+	}
+	if strings.Contains(lower, "cursor") && strings.Contains(lower, "timed out") {
+		ns := CURSOR_UNKNOWN_NS
+		id := fmt.Sprintf("%v", attr["cursorId"])
+		if cursor, ok := ptr.Open[id]; ok {
+			ns = cursor.namespace
+			delete(ptr.Open, id)
+		}
+		ptr.TimedOut[ns]++
+		return
+	}
+	if doc.Severity == "W" && strings.Contains(lower, "cursor") {
+		ns, _ := attr["ns"].(string)
+		if ns == "" {
+			ns = CURSOR_UNKNOWN_NS
+		}
+		ptr.Warnings[ns]++
+		return
+	}
+	command, ok := attr["command"].(bson.D)
+	if !ok || len(command) == 0 {
+		return
+	}
+	ns, _ := attr["ns"].(string)
+	switch command[0].Key {
+	case cmdGetMore:
+		if exhausted, _ := attr["cursorExhausted"].(bool); exhausted {
+			delete(ptr.Open, fmt.Sprintf("%v", command[0].Value))
+		}
+	case "killCursors":
+		if coll, ok := command[0].Value.(string); ok {
+			ns = strings.TrimSuffix(ns, "$cmd") + coll
+		}
+		cursors, _ := command.Map()["cursors"].(bson.A)
+		for _, id := range cursors {
+			delete(ptr.Open, fmt.Sprintf("%v", id))
+			ptr.Killed[ns]++
+		}
+	case cmdFind, cmdAggregate:
+		id, ok := attr["cursorid"]
+		if !ok || ToInt(id) == 0 || ns == "" || len(ptr.Open) >= CURSOR_MAX_OPEN {
+			return
+		}
+		noTimeout, _ := command.Map()["noCursorTimeout"].(bool)
+		ptr.Open[fmt.Sprintf("%v", id)] = openCursor{namespace: ns, noTimeout: noTimeout}
+	}
+}
+
+// GetAuditData returns audit data by type of namespaces of cursor timeouts,
+// noCursorTimeout cursors never exhausted or killed, cursors killed, and
+// cursor warnings, timeouts of the cursor monitor not logged by cursor are of
+// CURSOR_UNKNOWN_NS
+func (ptr *CursorStats) GetAuditData() map[string][]NameValue {
+	data := map[string][]NameValue{}
+	timedOut := map[string]int{}
+	logged := 0
+	for ns, count := range ptr.TimedOut {
+		timedOut[ns] = count
+		logged += count
+	}
+	if ptr.Expired > logged {
+		timedOut[CURSOR_UNKNOWN_NS] += ptr.Expired - logged
+	}
+	leaked := map[string]int{}
+	for _, cursor := range ptr.Open {
+		if cursor.noTimeout {
+			leaked[cursor.namespace]++
+		}
+	}
+	namespaces := map[string]bool{}
+	for _, counts := range []map[string]int{timedOut, leaked, ptr.Warnings} {
+		for ns := range counts {
+			namespaces[ns] = true
+		}
+	}
+	for ns := range namespaces {
+		data["cursor-timeout"] = append(data["cursor-timeout"], NameValue{ns, timedOut[ns]})
+		data["cursor-leaked"] = append(data["cursor-leaked"], NameValue{ns, leaked[ns]})
+		data["cursor-killed"] = append(data["cursor-killed"], NameValue{ns, ptr.Killed[ns]})
+		data["cursor-warning"] = append(data["cursor-warning"], NameValue{ns, ptr.Warnings[ns]})
+	}
+	return data
+}
+
+// GetCursorNotFoundNS returns namespace of a CursorNotFound (code 43) error
+func GetCursorNotFoundNS(doc *Logv2Info) (string, bool) {
+	if doc.Component != "COMMAND" && doc.Component != "QUERY" {
+		return "", false
+	}
+	attrMap := doc.Attr.Map()
+	if ToInt(attrMap["errCode"]) != CURSOR_NOT_FOUND && attrMap["errName"] != "CursorNotFound" {
+		return "", false
+	}
+	ns, _ := attrMap["ns"].(string)
+	if strings.HasSuffix(ns, ".$cmd") {
+		if command, ok := attrMap["command"].(bson.D); ok {
+			if coll, ok := command.Map()["collection"].(string); ok {
+				ns = strings.TrimSuffix(ns, "$cmd") + coll
+			}
+		}
+	}
+	return ns, ns != ""
+}
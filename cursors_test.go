@@ -0,0 +1,125 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * cursors_test.go
+ */
+
+package hatchet
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetCursorNotFoundNS(t *testing.T) {
+	str := `{"t":{"$date":"2023-03-01T10:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn9","msg":"Slow query","attr":{"type":"command","ns":"shop.$cmd","command":{"getMore":{"$numberLong":"8532410283464551238"},"collection":"orders","$db":"shop"},"ok":0,"errMsg":"cursor id 8532410283464551238 not found","errName":"CursorNotFound","errCode":43,"reslen":130,"durationMillis":0}}`
+	doc := Logv2Info{}
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	ns, ok := GetCursorNotFoundNS(&doc)
+	if !ok || ns != "shop.orders" {
+		t.Fatal("expected", "shop.orders", "but got", ns)
+	}
+
+	stats := NewCursorStats()
+	stats.Add(&doc, &OpStat{})
+	stats.Add(&Logv2Info{Component: "COMMAND"}, &OpStat{Op: cmdGetMore, Namespace: "shop.orders"})
+	if stats.NotFound["shop.orders"] != 1 || stats.GetMores["shop.orders"] != 1 {
+		t.Fatal("expected", 1, "but got", stats.NotFound, stats.GetMores)
+	}
+}
+
+func TestCursorLifecycle(t *testing.T) {
+	logs := []string{
+		`{"t":{"$date":"2023-03-01T10:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders","filter":{"status":"new"},"noCursorTimeout":true,"$db":"shop"},"cursorid":{"$numberLong":"8532410283464551238"},"nreturned":101,"durationMillis":150}}`,
+		`{"t":{"$date":"2023-03-01T10:00:01.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders","filter":{"status":"old"},"noCursorTimeout":true,"$db":"shop"},"cursorid":{"$numberLong":"1111"},"nreturned":101,"durationMillis":150}}`,
+		`{"t":{"$date":"2023-03-01T10:00:02.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"getMore":{"$numberLong":"1111"},"collection":"orders","$db":"shop"},"originatingCommand":{"find":"orders","filter":{"status":"old"}},"cursorExhausted":true,"durationMillis":120}}`,
+		`{"t":{"$date":"2023-03-01T10:00:03.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn2","msg":"Slow query","attr":{"type":"command","ns":"shop.items","command":{"aggregate":"items","pipeline":[{"$match":{"a":1}}],"cursor":{},"$db":"shop"},"cursorid":{"$numberLong":"2222"},"durationMillis":300}}`,
+		`{"t":{"$date":"2023-03-01T10:00:04.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn2","msg":"Slow query","attr":{"type":"command","ns":"shop.items","command":{"aggregate":"items","pipeline":[{"$match":{"a":1}}],"cursor":{},"$db":"shop"},"cursorid":{"$numberLong":"3333"},"durationMillis":300}}`,
+		`{"t":{"$date":"2023-03-01T10:10:00.000+00:00"},"s":"I","c":"QUERY","id":20529,"ctx":"clientcursormon","msg":"Cursor timed out","attr":{"cursorId":{"$numberLong":"2222"},"idleSince":{"$date":"2023-03-01T10:00:03.000Z"}}}`,
+		`{"t":{"$date":"2023-03-01T10:10:00.000+00:00"},"s":"I","c":"QUERY","id":20528,"ctx":"clientcursormon","msg":"Cursors timed out","attr":{"numTimedOut":3}}`,
+		`{"t":{"$date":"2023-03-01T10:11:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn2","msg":"Slow query","attr":{"type":"command","ns":"shop.$cmd","command":{"killCursors":"items","cursors":[{"$numberLong":"3333"}],"$db":"shop"},"durationMillis":100}}`,
+	}
+	stats := NewCursorStats()
+	for _, str := range logs {
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		stat, err := AnalyzeSlowOp(&doc)
+		if err != nil {
+			stat = nil
+		}
+		stats.Add(&doc, stat)
+	}
+	if len(stats.Open) != 1 {
+		t.Fatal("expected", 1, "but got", stats.Open)
+	}
+	docs := MergeAuditSeries(AUDIT_SERIES["cursor-timeout"], stats.GetAuditData())
+	expected := map[string][]interface{}{
+		"unknown":     {2, 0, 0, 0},
+		"shop.items":  {1, 0, 1, 0},
+		"shop.orders": {0, 1, 0, 0},
+	}
+	if len(docs) != len(expected) {
+		t.Fatal("expected", expected, "but got", docs)
+	}
+	for _, doc := range docs {
+		for i, value := range expected[doc.Name] {
+			if doc.Values[i] != value {
+				t.Fatal("expected", expected[doc.Name], "of", doc.Name, "but got", doc.Values)
+			}
+		}
+	}
+}
+
+func TestCursorNotFoundLink(t *testing.T) {
+	RegisterSQLite3Extended()
+	saved := instance
+	defer func() { instance = saved }()
+	dir := t.TempDir()
+	lines := []string{
+		`{"t":{"$date":"2023-03-01T10:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn9","msg":"Slow query","attr":{"type":"command","ns":"shop.$cmd","command":{"getMore":{"$numberLong":"8532410283464551238"},"collection":"orders","$db":"shop"},"ok":0,"errMsg":"cursor id 8532410283464551238 not found","errName":"CursorNotFound","errCode":43,"reslen":130,"durationMillis":0}}`,
+		`{"t":{"$date":"2023-03-01T10:00:01.000+00:00"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted","attr":{"remote":"10.0.0.1:5000","connectionId":1,"connectionCount":1}}`,
+		`{"t":{"$date":"2023-03-01T10:00:02.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn10","msg":"Slow query","attr":{"type":"command","ns":"shop.$cmd","command":{"getMore":{"$numberLong":"1234567890"},"collection":"carts","$db":"shop"},"ok":0,"errMsg":"cursor id 1234567890 not found","errName":"CursorNotFound","errCode":43,"reslen":130,"durationMillis":0}}`,
+	}
+	filename := filepath.Join(dir, "mongod.log")
+	if err := os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logv2 := &Logv2{testing: true, url: filepath.Join(dir, "hatchet.db")}
+	instance = logv2
+	if err := logv2.Analyze(filename); err != nil {
+		t.Fatal(err)
+	}
+	router := httprouter.New()
+	router.GET("/hatchets/:hatchet/logs/:attr", LogsHandler)
+	router.GET("/hatchets/:hatchet/stats/:attr", StatsHandler)
+	get := func(url string) string {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		return w.Body.String()
+	}
+
+	body := get("/hatchets/" + logv2.hatchetName + "/stats/audit")
+	matches := regexp.MustCompile(`loadData\('([^']*CursorNotFound[^']*)'\)`).FindStringSubmatch(body)
+	if matches == nil {
+		t.Fatal("expected", "a link of CursorNotFound", "but got", body)
+	}
+	body = get(matches[1])
+	for _, expected := range []string{"cursor id 8532410283464551238 not found", "cursor id 1234567890 not found"} {
+		if !strings.Contains(body, expected) {
+			t.Fatal("expected", expected, "of", matches[1], "but got", body)
+		}
+	}
+	if strings.Contains(body, "Connection accepted") {
+		t.Fatal("expected", "CursorNotFound lines only", "but got", body)
+	}
+}
@@ -5,13 +5,32 @@
 
 package hatchet
 
-import "log"
+import (
+	"io"
+	"log"
+	"sort"
+)
 
 const (
 	SQLite3 = iota
 	Mongo
 )
 
+// BACKENDS maps names of -backend to database types
+var BACKENDS = map[string]int{
+	"mongodb": Mongo,
+	"sqlite3": SQLite3,
+}
+
+// UNSUPPORTED_DATABASES are -backend names and url schemes of databases of no
+// implementation, rejected rather than taken as file names of SQLite3
+var UNSUPPORTED_DATABASES = map[string]string{
+	"clickhouse": "ClickHouse",
+	"duckdb":     "DuckDB",
+	"postgres":   "PostgreSQL",
+	"postgresql": "PostgreSQL",
+}
+
 type NameValue struct {
 	Name  string `bson:"name"`
 	Value int    `bson:"value"`
@@ -22,29 +41,97 @@ type NameValues struct {
 	Values []interface{}
 }
 
+// AUDIT_SERIES lists audit categories assembled from companion types, values
+// follow the order of the types
+var AUDIT_SERIES = map[string][]string{
+	"admission":        {"admission", "admission-flow", "admission-tickets", "admission-wait-ms", "admission-ops", "admission-ms"},
+	"auth-error":       {"auth-error"},
+	"auth-ip":          {"auth-ip", "auth-ip-users"},
+	"auth-spike":       {"auth-spike"},
+	"auth-user":        {"auth-user", "auth-user-scram"},
+	"conn-limit":       {"conn-limit", "conn-limit-pool", "conn-limit-files", "conn-limit-peak"},
+	"conn-limit-max":   {"conn-limit-max"},
+	"cursor-not-found": {"cursor-not-found", "cursor-getmore"},
+	"cursor-timeout":   {"cursor-timeout", "cursor-leaked", "cursor-killed", "cursor-warning"},
+	"hotdoc":           {"hotdoc", "hotdoc-wc"},
+	"latency":          {"latency", "latency-p50", "latency-p95", "latency-p99", "latency-max"},
+	"oplog-churn":      OPLOG_CHURN_SERIES,
+	"ttl":              {"ttl", "ttl-passes", "ttl-ms", "ttl-latency"},
+}
+
 type Database interface {
 	Begin() error
 	Close() error
 	Commit() error
 	CreateMetaData() error
+	DeleteBookmark(hash string) error
+	DeleteView(name string) error
+	ExportLogs(w io.Writer, filters []RawLogFilter) (int, error)
 	Drop() error
 	GetAcceptedConnsCounts(duration string) ([]NameValue, error)
+	GetAppStats() ([]AppStat, error)
 	GetAuditData() (map[string][]NameValues, error)
 	GetAverageOpTime(op string, duration string) ([]OpCount, error)
+	GetBookmarks() ([]Bookmark, error)
+	GetChunkEvents() ([]ChunkEvent, error)
+	GetChunkMigrationStats(duration string) ([]TimeSeries, error)
+	GetConfigChanges() ([]ConfigChange, error)
+	GetConnectionEvents() ([]ConnEvent, error)
+	GetConnectionLogs(id int) ([]TraceLog, error)
+	GetElectionEvents() ([]ElectionEvent, error)
 	GetConnectionStats(chartType string, duration string) ([]RemoteClient, error)
+	GetConnectionsByIP(duration string) ([]IPConns, error)
 	GetHatchetInfo() HatchetInfo
 	GetHatchetNames() ([]string, error)
+	GetIngest(logname string) (Ingest, error)
+	GetLockStats() ([]LockStat, error)
+	GetLockWaits(duration string) ([]TimeSeries, error)
 	GetLogs(opts ...string) ([]LegacyLog, error)
+	GetLogTemplates() ([]NameValues, error)
+	GetMigrations() ([]MigrationRecord, error)
 	GetOpsCounts(duration string) ([]NameValue, error)
+	GetOpsHeatmap(layout string, duration string) ([]HeatmapCell, error)
+	GetPipelineStats() ([]PipelineStat, error)
+	GetPlanningStats() ([]PlanningStat, error)
+	GetReplanStats() ([]ReplanStat, error)
+	GetShardingStats() ([]ShardingStat, error)
+	GetGetMoreStats() ([]GetMoreStat, error)
+	GetSortStageStats() ([]BlockingSortStat, error)
+	GetStorageReads(duration string) ([]TimeSeries, error)
+	GetSourceStats() ([]SourceStat, error)
+	GetTargetingStats() ([]TargetingStat, error)
+	GetReplicationLags(duration string) ([]TimeSeries, error)
 	GetReslenByNamespace(ip string, duration string) ([]NameValue, error)
 	GetReslenByIP(ip string, duration string) ([]NameValue, error)
+	GetReslenOverTime(duration string) ([]TimeSeries, error)
+	GetShapePlans(duration string) ([]ShapePlan, error)
 	GetSlowOps(orderBy string, order string, collscan bool) ([]OpStat, error)
+	GetSeverityCounts(duration string) ([]TimeSeries, error)
+	GetServerInfo() ([]NameValue, error)
+	GetSeverityStats() ([]SeverityStat, error)
 	GetSlowestLogs(topN int) ([]LegacyLog, error)
+	GetSlowOpsByShape(duration string) ([]OpStat, error)
+	GetTicketWaits(duration string) ([]TimeSeries, error)
+	GetTransactionRates(duration string) ([]TimeSeries, error)
+	GetTransactionStats() ([]TxnStat, error)
+	GetTTLDeletes(duration string) ([]TimeSeries, error)
+	GetViews() ([]SavedView, error)
+	GetWiredTigerStats(duration string) ([]TimeSeries, error)
 	GetVerbose() bool
+	GetWriteStats() ([]WriteStat, error)
+	InsertAuditData(category string, data []NameValue) error
 	InsertClientConn(index int, doc *Logv2Info) error
 	InsertDriver(index int, doc *Logv2Info) error
 	InsertLog(index int, end string, doc *Logv2Info, stat *OpStat) error
+	Migrate() ([]Migration, error)
+	ResetMetaData() error
+	Resume() error
+	SaveBookmark(doc Bookmark) error
+	SaveIngest(doc Ingest) error
+	SaveView(doc SavedView) error
 	SearchLogs(opts ...string) ([]LegacyLog, error)
+	SearchMessages(query string, limit string) ([]LegacyLog, error)
+	SetBatchSize(size int)
 	SetVerbose(v bool)
 	UpdateHatchetInfo(info HatchetInfo) error
 }
@@ -56,6 +143,9 @@ func GetDatabase(hatchetName string) (Database, error) {
 	if logv2.verbose {
 		log.Println("url", logv2.url, "hatchet name", hatchetName)
 	}
+	if err = logv2.CheckDBType(); err != nil {
+		return nil, err
+	}
 	if GetLogv2().GetDBType() == Mongo {
 		if dbase, err = NewMongoDB(logv2.url, hatchetName); err != nil {
 			return nil, err
@@ -65,6 +155,41 @@ func GetDatabase(hatchetName string) (Database, error) {
 			return nil, err
 		}
 	}
+	dbase.SetBatchSize(logv2.batchSize)
 	dbase.SetVerbose(logv2.verbose)
 	return dbase, err
 }
+
+// MergeAuditSeries merges audit rows of companion types by name and sorts by
+// the value of the first type
+func MergeAuditSeries(types []string, rows map[string][]NameValue) []NameValues {
+	docs := []NameValues{}
+	if len(types) == 0 {
+		return docs
+	}
+	values := map[string][]interface{}{}
+	for i, category := range types {
+		for _, row := range rows[category] {
+			if values[row.Name] == nil {
+				if i > 0 {
+					continue
+				}
+				values[row.Name] = make([]interface{}, len(types))
+				for j := range types {
+					values[row.Name][j] = 0
+				}
+			}
+			values[row.Name][i] = row.Value
+		}
+	}
+	for _, row := range rows[types[0]] {
+		if values[row.Name] != nil {
+			docs = append(docs, NameValues{row.Name, values[row.Name]})
+			delete(values, row.Name)
+		}
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Values[0].(int) > docs[j].Values[0].(int)
+	})
+	return docs
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * db_archive.go
+ */
+
+package hatchet
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	GZIP_EXT = ".gz"
+	ZSTD_EXT = ".zst"
+)
+
+var archiveFormats = map[string]string{"gzip": GZIP_EXT, "zstd": ZSTD_EXT}
+
+// GetArchiveExt returns file extension of a compression format, gzip or zstd
+func GetArchiveExt(format string) (string, error) {
+	ext, ok := archiveFormats[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported compression format %v, use gzip or zstd", format)
+	}
+	return ext, nil
+}
+
+// IsCompressedDB returns true if a file is a compressed database artifact
+func IsCompressedDB(filename string) bool {
+	return strings.HasSuffix(filename, GZIP_EXT) || strings.HasSuffix(filename, ZSTD_EXT)
+}
+
+// CompressDB compresses a database file to an artifact, the format is
+// decided by the artifact extension, .gz or .zst
+func CompressDB(dbfile string, artifact string) error {
+	if !IsCompressedDB(artifact) {
+		return fmt.Errorf("unsupported compressed database %v, use %v or %v", artifact, GZIP_EXT, ZSTD_EXT)
+	}
+	if err := compressFile(dbfile, artifact+".tmp", strings.HasSuffix(artifact, ZSTD_EXT)); err != nil {
+		os.Remove(artifact + ".tmp")
+		return fmt.Errorf("failed to compress %v: %v", dbfile, err)
+	}
+	return os.Rename(artifact+".tmp", artifact)
+}
+
+func compressFile(src string, dest string, useZstd bool) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	var writer io.WriteCloser
+	if useZstd {
+		if writer, err = zstd.NewWriter(out); err != nil {
+			return err
+		}
+	} else {
+		writer = gzip.NewWriter(out)
+	}
+	if _, err = io.Copy(writer, in); err != nil {
+		writer.Close()
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// DecompressDB decompresses a database artifact to a temp working copy and
+// returns its file name, the caller is responsible for removing it
+func DecompressDB(artifact string) (string, error) {
+	in, err := os.Open(artifact)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	out, err := os.CreateTemp("", "hatchet-*.db")
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+	var reader io.Reader
+	if strings.HasSuffix(artifact, ZSTD_EXT) {
+		var decoder *zstd.Decoder
+		if decoder, err = zstd.NewReader(in); err == nil {
+			defer decoder.Close()
+			reader = decoder
+		}
+	} else {
+		var gzipReader *gzip.Reader
+		if gzipReader, err = gzip.NewReader(in); err == nil {
+			defer gzipReader.Close()
+			reader = gzipReader
+		}
+	}
+	if err == nil {
+		_, err = io.Copy(out, reader)
+	}
+	if err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return "", fmt.Errorf("failed to decompress %v, not a valid compressed database: %v", artifact, err)
+	}
+	return out.Name(), nil
+}
+
+// RemoveOnExit removes a temp file when the process is interrupted or terminated
+func RemoveOnExit(filename string) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-c
+		log.Println("removing", filename)
+		os.Remove(filename)
+		os.Exit(1)
+	}()
+}
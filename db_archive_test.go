@@ -0,0 +1,60 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * db_archive_test.go
+ */
+
+package hatchet
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompressDB(t *testing.T) {
+	dir := t.TempDir()
+	dbfile := filepath.Join(dir, "hatchet.db")
+	data := bytes.Repeat([]byte("SQLite format 3\x00"), 1000)
+	if err := os.WriteFile(dbfile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{"gzip", "zstd"} {
+		ext, err := GetArchiveExt(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		artifact := dbfile + ext
+		if err = CompressDB(dbfile, artifact); err != nil {
+			t.Fatal(err)
+		}
+		if !IsCompressedDB(artifact) {
+			t.Fatal("expected", true, "but got", false)
+		}
+		filename, err := DecompressDB(artifact)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf, err := os.ReadFile(filename)
+		os.Remove(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, buf) {
+			t.Fatal("expected", len(data), "but got", len(buf))
+		}
+	}
+	if _, err := GetArchiveExt("bzip2"); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
+
+func TestDecompressDBInvalid(t *testing.T) {
+	artifact := filepath.Join(t.TempDir(), "hatchet.db.gz")
+	if err := os.WriteFile(artifact, []byte("not compressed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecompressDB(artifact); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
@@ -0,0 +1,274 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * diff.go
+ */
+
+package hatchet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// regressions of a query shape of B from A
+const (
+	REGRESSION_COUNT   = "count"
+	REGRESSION_LATENCY = "latency"
+	REGRESSION_NEW     = "new"
+)
+
+// DiffSnapshot is slow ops by shape, accepted connections, and warnings,
+// errors, and fatal messages of a hatchet in a duration
+type DiffSnapshot struct {
+	Accepted   int
+	Duration   string
+	Hatchet    string
+	Hours      float64
+	Severities map[string]int
+	Shapes     []OpStat
+	Summary    string
+}
+
+// ShapeDiff is a query shape of A and B side by side, counts are per hour
+type ShapeDiff struct {
+	AvgMilli     [2]float64 `json:"avg_ms"`
+	Count        [2]int     `json:"count"`
+	CountPerHour [2]float64 `json:"count_per_hour"`
+	Namespace    string     `json:"ns"`
+	Op           string     `json:"op"`
+	QueryPattern string     `json:"query_pattern"`
+	Regressions  []string   `json:"regressions"`
+	SortPattern  string     `json:"sort_pattern"`
+}
+
+// Diff compares slow ops by shape, connections, and error rates of two
+// hatchets or two durations of a hatchet
+type Diff struct {
+	Durations [2]string       `json:"durations"`
+	Hatchets  [2]string       `json:"hatchets"`
+	Rows      []ComparisonRow `json:"rows"`
+	Shapes    []ShapeDiff     `json:"shapes"`
+	Summary   [2]string       `json:"summary"`
+}
+
+// RunDiff prints differences of two hatchets or two durations of a hatchet
+func RunDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	durationA := fs.String("a", "", "duration of A, {start},{end}, of all logs if not set")
+	durationB := fs.String("b", "", "duration of B, {start},{end}, of all logs if not set")
+	asJSON := fs.Bool("json", false, "print differences in JSON")
+	topN := fs.Int("topN", TOP_N, "query shapes printed, regressions first")
+	url := fs.String("url", SQLITE3_FILE, "database file name or connection string")
+	fs.Parse(args)
+
+	names := fs.Args()
+	if len(names) == 1 && *durationA != "" && *durationB != "" {
+		names = append(names, names[0])
+	}
+	if len(names) != 2 {
+		return errors.New("usage: hatchet diff [-url file] [-a {start},{end}] [-b {start},{end}] [-json] hatchetA [hatchetB]")
+	}
+	instance = &Logv2{url: *url}
+	if GetLogv2().GetDBType() == SQLite3 {
+		RegisterSQLite3Extended()
+	}
+	diff, err := DiffHatchets([2]string{names[0], names[1]}, [2]string{*durationA, *durationB})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if *topN > 0 && len(diff.Shapes) > *topN {
+		diff.Shapes = diff.Shapes[:*topN]
+	}
+	fmt.Print(diff)
+	return nil
+}
+
+// DiffHatchets returns differences of B from A of two hatchets, or of the
+// same hatchet in two durations
+func DiffHatchets(hatchets [2]string, durations [2]string) (*Diff, error) {
+	snapshots := [2]DiffSnapshot{}
+	for i, hatchetName := range hatchets {
+		snapshot, err := getDiffSnapshot(hatchetName, durations[i])
+		if err != nil {
+			return nil, err
+		}
+		snapshots[i] = snapshot
+	}
+	return DiffSnapshots(snapshots), nil
+}
+
+// getDiffSnapshot returns a snapshot of a hatchet in a duration
+func getDiffSnapshot(hatchetName string, duration string) (DiffSnapshot, error) {
+	snapshot := DiffSnapshot{Duration: duration, Hatchet: hatchetName, Severities: map[string]int{}}
+	if duration != "" && len(strings.Split(duration, ",")) != 2 {
+		return snapshot, fmt.Errorf("invalid duration %v, expected {start},{end}", duration)
+	}
+	dbase, err := GetDatabase(hatchetName)
+	if err != nil {
+		return snapshot, err
+	}
+	defer dbase.Close()
+	info := dbase.GetHatchetInfo()
+	snapshot.Summary = GetHatchetSummary(info)
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		snapshot.Hours = getLogHours(toks[0], toks[1])
+	} else {
+		snapshot.Hours = getLogHours(info.Start, info.End)
+	}
+	if snapshot.Shapes, err = dbase.GetSlowOpsByShape(duration); err != nil {
+		return snapshot, err
+	}
+	conns, err := dbase.GetAcceptedConnsCounts(duration)
+	if err != nil {
+		return snapshot, err
+	}
+	for _, doc := range conns {
+		snapshot.Accepted += doc.Value
+	}
+	counts, err := dbase.GetSeverityCounts(duration)
+	if err != nil {
+		return snapshot, err
+	}
+	for _, doc := range counts {
+		for i, severity := range SEVERITY_LEVELS {
+			snapshot.Severities[severity] += int(doc.Values[i])
+		}
+	}
+	return snapshot, nil
+}
+
+// DiffSnapshots returns slow ops, connections, and error rates per hour and
+// query shapes of A and B, shapes of B regressed from A are the first
+func DiffSnapshots(snapshots [2]DiffSnapshot) *Diff {
+	diff := &Diff{}
+	metrics := [2]map[string]map[string]float64{}
+	shapes := map[string]*ShapeDiff{}
+	keys := []string{}
+	for i, snapshot := range snapshots {
+		diff.Durations[i], diff.Hatchets[i], diff.Summary[i] = snapshot.Duration, snapshot.Hatchet, snapshot.Summary
+		hours := math.Max(snapshot.Hours, 1.0/60)
+		count, totalMilli := 0, 0
+		for _, stat := range snapshot.Shapes {
+			count += stat.Count
+			totalMilli += stat.TotalMilli
+			key := strings.Join([]string{stat.Op, stat.Namespace, stat.QueryPattern, stat.SortPattern}, "\t")
+			shape := shapes[key]
+			if shape == nil {
+				shape = &ShapeDiff{Namespace: stat.Namespace, Op: stat.Op, QueryPattern: stat.QueryPattern,
+					SortPattern: stat.SortPattern, Regressions: []string{}}
+				shapes[key] = shape
+				keys = append(keys, key)
+			}
+			shape.AvgMilli[i] = math.Round(10*stat.AvgMilli) / 10
+			shape.Count[i] = stat.Count
+			shape.CountPerHour[i] = math.Round(10*float64(stat.Count)/hours) / 10
+		}
+		metrics[i] = map[string]map[string]float64{"slow ops": {}, "connections": {}, "errors": {}}
+		metrics[i]["slow ops"]["per hour"] = math.Round(10*float64(count)/hours) / 10
+		if count > 0 {
+			metrics[i]["slow ops"]["avgMilli"] = math.Round(10*float64(totalMilli)/float64(count)) / 10
+		}
+		metrics[i]["connections"]["accepted per hour"] = math.Round(10*float64(snapshot.Accepted)/hours) / 10
+		for _, severity := range SEVERITY_LEVELS {
+			metrics[i]["errors"][severity+" per hour"] = math.Round(10*float64(snapshot.Severities[severity])/hours) / 10
+		}
+	}
+	for _, category := range []string{"slow ops", "connections", "errors"} {
+		names := []string{}
+		for name := range metrics[0][category] {
+			names = append(names, name)
+		}
+		for name := range metrics[1][category] {
+			if _, ok := metrics[0][category][name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			row := ComparisonRow{Category: category, Metric: name,
+				Values: [2]float64{metrics[0][category][name], metrics[1][category][name]}}
+			row.Diverged = IsDivergent(row.Values[0], row.Values[1])
+			diff.Rows = append(diff.Rows, row)
+		}
+	}
+	for _, key := range keys {
+		shape := shapes[key]
+		if shape.Count[0] == 0 {
+			shape.Regressions = append(shape.Regressions, REGRESSION_NEW)
+		} else if shape.Count[1] > 0 {
+			if shape.CountPerHour[1] > shape.CountPerHour[0] && IsDivergent(shape.CountPerHour[0], shape.CountPerHour[1]) {
+				shape.Regressions = append(shape.Regressions, REGRESSION_COUNT)
+			}
+			if shape.AvgMilli[1] > shape.AvgMilli[0] && IsDivergent(shape.AvgMilli[0], shape.AvgMilli[1]) {
+				shape.Regressions = append(shape.Regressions, REGRESSION_LATENCY)
+			}
+		}
+		diff.Shapes = append(diff.Shapes, *shape)
+	}
+	sort.SliceStable(diff.Shapes, func(i, j int) bool {
+		a, b := diff.Shapes[i], diff.Shapes[j]
+		if (len(a.Regressions) > 0) != (len(b.Regressions) > 0) {
+			return len(a.Regressions) > 0
+		}
+		return a.AvgMilli[1]*float64(a.Count[1]) > b.AvgMilli[1]*float64(b.Count[1])
+	})
+	return diff
+}
+
+// String returns differences in tables, diverged metrics are marked with *
+func (ptr *Diff) String() string {
+	var buffer bytes.Buffer
+	labels := [2]string{}
+	for i := range labels {
+		labels[i] = ptr.Summary[i]
+		if ptr.Durations[i] != "" {
+			labels[i] += " [" + ptr.Durations[i] + "]"
+		}
+	}
+	buffer.WriteString(fmt.Sprintf("A: %v\nB: %v\n", labels[0], labels[1]))
+	line := "+-------------+--------------------------+--------------+--------------+---+\n"
+	buffer.WriteString(line)
+	buffer.WriteString(fmt.Sprintf("| %-11s | %-24s | %12s | %12s |   |\n", "Category", "Metric", "A", "B"))
+	buffer.WriteString(line)
+	for _, row := range ptr.Rows {
+		mark := " "
+		if row.Diverged {
+			mark = "*"
+		}
+		buffer.WriteString(fmt.Sprintf("| %-11s | %-24s | %12v | %12v | %v |\n",
+			row.Category, row.Metric, row.Values[0], row.Values[1], mark))
+	}
+	buffer.WriteString(line)
+	if len(ptr.Shapes) == 0 {
+		return buffer.String()
+	}
+	line = "+--------------------+--------------------------------+-------------+-------------+-------------+-------------+-----------------+\n"
+	buffer.WriteString(line)
+	buffer.WriteString(fmt.Sprintf("| %-18s | %-30s | %11s | %11s | %11s | %11s | %-15s |\n",
+		"Namespace", "Query Pattern", "A count/h", "B count/h", "A avg ms", "B avg ms", "Regression"))
+	buffer.WriteString(line)
+	for _, shape := range ptr.Shapes {
+		ns := fitWidth(shape.Namespace, 18)
+		pattern := fitWidth(shape.Op+" "+shape.QueryPattern, 30)
+		buffer.WriteString(fmt.Sprintf("| %-18s | %-30s | %11v | %11v | %11v | %11v | %-15s |\n", ns, pattern,
+			shape.CountPerHour[0], shape.CountPerHour[1], shape.AvgMilli[0], shape.AvgMilli[1],
+			strings.Join(shape.Regressions, ",")))
+	}
+	buffer.WriteString(line)
+	return buffer.String()
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * diff_handler.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// DiffHandler responds to API calls
+func DiffHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	/** APIs
+	 * /hatchets/{hatchet}/diff/{other}[?a={start},{end}&b={start},{end}]
+	 */
+	hatchetName := params.ByName("hatchet")
+	other := params.ByName("attr")
+	if GetLogv2().verbose {
+		log.Println("DiffHandler", r.URL.Path, hatchetName, other)
+	}
+	durations := [2]string{r.URL.Query().Get("a"), r.URL.Query().Get("b")}
+	diff, err := DiffHatchets([2]string{hatchetName, other}, durations)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	templ, err := GetDiffTemplate()
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	doc := map[string]interface{}{"Hatchet": hatchetName, "Diff": diff}
+	if err = templ.Execute(w, doc); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * diff_template.go
+ */
+
+package hatchet
+
+import (
+	"html/template"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetDiffTemplate returns HTML
+func GetDiffTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+	<form action='/hatchets/{{index .Diff.Hatchets 0}}/diff/{{index .Diff.Hatchets 1}}' method='get'>
+		A <input type='text' name='a' value='{{index .Diff.Durations 0}}' placeholder='{start},{end}' size='50'/>
+		B <input type='text' name='b' value='{{index .Diff.Durations 1}}' placeholder='{start},{end}' size='50'/>
+		<button class='btn' type='submit'><i class='fa fa-search'></i></button>
+	</form>
+	<p>A: {{index .Diff.Summary 0}}{{if index .Diff.Durations 0}} [{{index .Diff.Durations 0}}]{{end}}<br/>
+		B: {{index .Diff.Summary 1}}{{if index .Diff.Durations 1}} [{{index .Diff.Durations 1}}]{{end}}</p>
+	<table>
+		<caption>Differences, divergent metrics are highlighted</caption>
+		<tr><th>Category</th><th>Metric</th>
+			<th><a href='/hatchets/{{index .Diff.Hatchets 0}}/stats/audit'>A</a></th>
+			<th><a href='/hatchets/{{index .Diff.Hatchets 1}}/stats/audit'>B</a></th></tr>
+	{{range $row := .Diff.Rows}}
+		<tr><td>{{$row.Category}}</td><td>{{$row.Metric}}</td>
+		{{if $row.Diverged}}
+			<td align=right><mark>{{numPrinter (index $row.Values 0)}}</mark></td>
+			<td align=right><mark>{{numPrinter (index $row.Values 1)}}</mark></td>
+		{{else}}
+			<td align=right>{{numPrinter (index $row.Values 0)}}</td>
+			<td align=right>{{numPrinter (index $row.Values 1)}}</td>
+		{{end}}
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+{{if .Diff.Shapes}}
+	<table width='100%'>
+		<caption>Query Shapes, regressions of B are highlighted</caption>
+		<tr><th>op</th><th>namespace</th><th>query pattern</th><th>sort</th>
+			<th>A count/h</th><th>B count/h</th><th>A avg ms</th><th>B avg ms</th><th>regression</th></tr>
+	{{range $s := .Diff.Shapes}}
+		<tr><td>{{$s.Op}}</td><td>{{$s.Namespace}}</td><td>{{$s.QueryPattern}}</td><td>{{$s.SortPattern}}</td>
+		{{if $s.Regressions}}
+			<td align=right><mark>{{numPrinter (index $s.CountPerHour 0)}}</mark></td>
+			<td align=right><mark>{{numPrinter (index $s.CountPerHour 1)}}</mark></td>
+			<td align=right><mark>{{numPrinter (index $s.AvgMilli 0)}}</mark></td>
+			<td align=right><mark>{{numPrinter (index $s.AvgMilli 1)}}</mark></td>
+			<td><span style='color: red;'>{{join $s.Regressions}}</span></td>
+		{{else}}
+			<td align=right>{{numPrinter (index $s.CountPerHour 0)}}</td>
+			<td align=right>{{numPrinter (index $s.CountPerHour 1)}}</td>
+			<td align=right>{{numPrinter (index $s.AvgMilli 0)}}</td>
+			<td align=right>{{numPrinter (index $s.AvgMilli 1)}}</td>
+			<td></td>
+		{{end}}
+		</tr>
+	{{end}}
+	</table>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"join": func(values []string) string {
+			return strings.Join(values, ", ")
+		},
+		"numPrinter": func(n float64) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
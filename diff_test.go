@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * diff_test.go
+ */
+
+package hatchet
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	snapshots := [2]DiffSnapshot{
+		{Hatchet: "good", Hours: 2, Accepted: 20, Severities: map[string]int{"W": 4},
+			Shapes: []OpStat{
+				{Op: "find", Namespace: "shop.orders", QueryPattern: `{"status":1}`, Count: 10, AvgMilli: 100, TotalMilli: 1000},
+				{Op: "update", Namespace: "shop.items", QueryPattern: `{"_id":1}`, Count: 20, AvgMilli: 200, TotalMilli: 4000},
+				{Op: "find", Namespace: "shop.users", QueryPattern: `{"email":1}`, Count: 4, AvgMilli: 150, TotalMilli: 600},
+			}},
+		{Hatchet: "bad", Hours: 1, Accepted: 100, Severities: map[string]int{"W": 2, "E": 5},
+			Shapes: []OpStat{
+				{Op: "find", Namespace: "shop.orders", QueryPattern: `{"status":1}`, Count: 5, AvgMilli: 900, TotalMilli: 4500},
+				{Op: "update", Namespace: "shop.items", QueryPattern: `{"_id":1}`, Count: 50, AvgMilli: 210, TotalMilli: 10500},
+				{Op: "aggregate", Namespace: "shop.carts", QueryPattern: `{"user":1}`, Count: 1, AvgMilli: 50, TotalMilli: 50},
+			}},
+	}
+	diff := DiffSnapshots(snapshots)
+	if len(diff.Shapes) != 4 {
+		t.Fatal("expected", 4, "but got", diff.Shapes)
+	}
+	expected := map[string]string{"shop.items": REGRESSION_COUNT, "shop.orders": REGRESSION_LATENCY,
+		"shop.carts": REGRESSION_NEW, "shop.users": ""}
+	for i, shape := range diff.Shapes {
+		regression := ""
+		if len(shape.Regressions) > 0 {
+			regression = shape.Regressions[0]
+		}
+		if regression != expected[shape.Namespace] {
+			t.Fatal("expected", expected[shape.Namespace], "of", shape.Namespace, "but got", shape.Regressions)
+		}
+		if i < 3 && regression == "" {
+			t.Fatal("expected", "regressions first", "but got", diff.Shapes)
+		}
+	}
+	if diff.Shapes[0].Namespace != "shop.items" || diff.Shapes[0].CountPerHour != [2]float64{10, 50} {
+		t.Fatal("expected", "shop.items of 10 and 50 per hour", "but got", diff.Shapes[0])
+	}
+	diverged := map[string]bool{}
+	for _, row := range diff.Rows {
+		diverged[row.Metric] = row.Diverged
+	}
+	if !diverged["accepted per hour"] || !diverged["E per hour"] || diverged["W per hour"] {
+		t.Fatal("expected", "accepted and E diverged", "but got", diff.Rows)
+	}
+}
+
+func TestDiffStringMultibyte(t *testing.T) {
+	diff := Diff{Shapes: []ShapeDiff{{Op: "find", Namespace: "shop.商品目録コレクション名前一覧",
+		QueryPattern: `{"名前":1,"価格":1,"在庫":1,"分類":1}`}}}
+	lines := strings.Split(strings.TrimSpace(diff.String()), "\n")
+	row := lines[len(lines)-2]
+	expected := `| shop.商品目録コレクション名前一 | find {"名前":1,"価格":1,"在庫":1,"分類 |`
+	if !utf8.ValidString(row) || !strings.HasPrefix(row, expected) ||
+		utf8.RuneCountInString(row) != utf8.RuneCountInString(lines[len(lines)-1]) {
+		t.Fatal("expected", expected, "but got", row)
+	}
+}
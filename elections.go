@@ -0,0 +1,147 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * elections.go
+ */
+
+package hatchet
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// election event types
+const (
+	ELECTION_DRY_RUN   = "dry-run"
+	ELECTION_FAILED    = "failed"
+	ELECTION_STARTED   = "election"
+	ELECTION_STATE     = "state"
+	ELECTION_STEPDOWN  = "stepdown"
+	ELECTION_SUCCEEDED = "elected"
+	ELECTION_TERM      = "term"
+)
+
+// ELECTION_MSG_FILTER matches msg of REPL and ELECTION logs to classify
+const ELECTION_MSG_FILTER = "elect|transition|step|term|takeover|running for primary"
+
+var (
+	electionAttrRegex   = regexp.MustCompile(`(\w+): ?(?:"([^"]*)"|(\S+))`)
+	electionCauseRegex  = regexp.MustCompile(`(?i)(?:since|due to|because) (.*)$`)
+	electionLegacyState = regexp.MustCompile(`(?i)transition to (\w+) from (\w+)`)
+	electionLegacyTerm  = regexp.MustCompile(`(?i)term:? (\d+)`)
+)
+
+// ElectionEvent is an election or a member state change of a replica set
+type ElectionEvent struct {
+	Date     string `json:"date" bson:"date"`
+	Message  string `json:"message" bson:"message"`
+	NewState string `json:"new_state,omitempty" bson:"new_state,omitempty"`
+	OldState string `json:"old_state,omitempty" bson:"old_state,omitempty"`
+	Reason   string `json:"reason,omitempty" bson:"reason,omitempty"` // why an election, a stepdown, or a state change
+	Term     int    `json:"term,omitempty" bson:"term,omitempty"`
+	Type     string `json:"type" bson:"type"`
+}
+
+// GetElectionEvent returns an event of a REPL or ELECTION log from its msg and
+// the message with attributes, false if not an election or a state change
+func GetElectionEvent(date string, component string, msg string, message string) (ElectionEvent, bool) {
+	event := ElectionEvent{Date: date, Message: message}
+	if component != "REPL" && component != "ELECTION" {
+		return event, false
+	}
+	lower := strings.ToLower(msg)
+	if strings.Contains(lower, "dry run election") || strings.Contains(lower, "dry election run") {
+		event.Type = ELECTION_DRY_RUN
+	} else if strings.Contains(lower, "election succeeded") {
+		event.Type = ELECTION_SUCCEEDED
+	} else if strings.Contains(lower, "not running for primary") || strings.Contains(lower, "election failed") ||
+		strings.Contains(lower, "lost election") || strings.Contains(lower, "not starting an election") {
+		event.Type = ELECTION_FAILED
+	} else if strings.Contains(lower, "starting an election") || strings.Contains(lower, "takeover") {
+		event.Type = ELECTION_STARTED
+	} else if strings.Contains(lower, "state transition") || electionLegacyState.MatchString(msg) {
+		event.Type = ELECTION_STATE
+	} else if strings.Contains(lower, "stepdown") || strings.Contains(lower, "stepping down") {
+		event.Type = ELECTION_STEPDOWN
+	} else if strings.Contains(lower, "updating term") {
+		event.Type = ELECTION_TERM
+	} else {
+		return event, false
+	}
+	attrs := map[string]string{}
+	for _, match := range electionAttrRegex.FindAllStringSubmatch(strings.TrimPrefix(message, msg), -1) {
+		attrs[match[1]] = match[2] + match[3]
+	}
+	event.NewState, event.OldState = attrs["newState"], attrs["oldState"]
+	if m := electionLegacyState.FindStringSubmatch(msg); m != nil && event.NewState == "" {
+		event.NewState, event.OldState = m[1], m[2]
+	}
+	for _, key := range []string{"newTerm", "term", "currentTerm"} {
+		if n, err := strconv.Atoi(attrs[key]); err == nil {
+			event.Term = n
+			break
+		}
+	}
+	if m := electionLegacyTerm.FindStringSubmatch(msg); m != nil && event.Term == 0 {
+		event.Term, _ = strconv.Atoi(m[1])
+	}
+	if event.Reason = attrs["reason"]; event.Reason == "" {
+		if m := electionCauseRegex.FindStringSubmatch(msg); m != nil {
+			event.Reason = m[1]
+		} else if event.Type == ELECTION_FAILED {
+			event.Reason = msg
+		}
+	}
+	return event, true
+}
+
+// GetElectionTimeline sets the reason of a state change to or from PRIMARY
+// without one to the reason of the election or stepdown before it
+func GetElectionTimeline(events []ElectionEvent) []ElectionEvent {
+	var election, stepdown string
+	for i, event := range events {
+		if event.Type == ELECTION_STARTED && event.Reason != "" {
+			election = event.Reason
+		} else if event.Type == ELECTION_STEPDOWN && event.Reason != "" {
+			stepdown = event.Reason
+		} else if event.Type == ELECTION_STATE && event.Reason == "" {
+			if event.NewState == "PRIMARY" && election != "" {
+				events[i].Reason, election = election, ""
+			} else if event.OldState == "PRIMARY" && stepdown != "" {
+				events[i].Reason, stepdown = stepdown, ""
+			}
+		}
+	}
+	return events
+}
+
+// GetFailovers returns the number of state changes to PRIMARY and from
+// PRIMARY
+func GetFailovers(events []ElectionEvent) (int, int) {
+	var elected, steppedDown int
+	for _, event := range events {
+		if event.Type != ELECTION_STATE {
+			continue
+		} else if event.NewState == "PRIMARY" {
+			elected++
+		} else if event.OldState == "PRIMARY" {
+			steppedDown++
+		}
+	}
+	return elected, steppedDown
+}
+
+// GetElectionAuditData returns audit data of elections and state changes,
+// values are the type, the state change, the term, and the reason
+func GetElectionAuditData(events []ElectionEvent) []NameValues {
+	docs := []NameValues{}
+	for _, event := range GetElectionTimeline(events) {
+		change := ""
+		if event.NewState != "" {
+			change = event.OldState + " → " + event.NewState
+		}
+		docs = append(docs, NameValues{event.Date, []interface{}{event.Type, change, event.Term, event.Reason}})
+	}
+	return docs
+}
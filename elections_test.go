@@ -0,0 +1,58 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * elections_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestGetElectionEvent(t *testing.T) {
+	tests := []struct {
+		component string
+		msg       string
+		message   string
+		event     ElectionEvent
+	}{
+		{"REPL", "Replica set state transition", `Replica set state transition newState: "PRIMARY" oldState: "SECONDARY"`,
+			ElectionEvent{Type: ELECTION_STATE, NewState: "PRIMARY", OldState: "SECONDARY"}},
+		{"ELECTION", "Conducting a dry run election to see if we could be elected", "Conducting a dry run election to see if we could be elected currentTerm:3",
+			ElectionEvent{Type: ELECTION_DRY_RUN, Term: 3}},
+		{"REPL", "Starting stepdown", `Starting stepdown reason: "replSetStepDown command"`,
+			ElectionEvent{Type: ELECTION_STEPDOWN, Reason: "replSetStepDown command"}},
+		{"REPL", "transition to PRIMARY from SECONDARY", "transition to PRIMARY from SECONDARY",
+			ElectionEvent{Type: ELECTION_STATE, NewState: "PRIMARY", OldState: "SECONDARY"}},
+		{"ELECTION", "election succeeded, assuming primary role in term 7", "election succeeded, assuming primary role in term 7",
+			ElectionEvent{Type: ELECTION_SUCCEEDED, Term: 7}},
+		{"REPL", "Starting an election, since we've seen no PRIMARY in the past 10000ms", "Starting an election, since we've seen no PRIMARY in the past 10000ms",
+			ElectionEvent{Type: ELECTION_STARTED, Reason: "we've seen no PRIMARY in the past 10000ms"}},
+	}
+	for _, test := range tests {
+		event, ok := GetElectionEvent("2023-01-01T00:00:00.000-0000", test.component, test.msg, test.message)
+		if !ok || event.Type != test.event.Type || event.NewState != test.event.NewState || event.OldState != test.event.OldState ||
+			event.Term != test.event.Term || event.Reason != test.event.Reason {
+			t.Fatal("expected", test.event, "but got", event, ok)
+		}
+	}
+	if event, ok := GetElectionEvent("", "REPL", "Applied op", "Applied op"); ok {
+		t.Fatal("expected", false, "but got", event)
+	}
+}
+
+func TestGetElectionTimeline(t *testing.T) {
+	events := GetElectionTimeline([]ElectionEvent{
+		{Type: ELECTION_STARTED, Reason: "no primary"},
+		{Type: ELECTION_STATE, NewState: "PRIMARY", OldState: "SECONDARY"},
+		{Type: ELECTION_STEPDOWN, Reason: "replSetStepDown command"},
+		{Type: ELECTION_STATE, NewState: "SECONDARY", OldState: "PRIMARY"},
+		{Type: ELECTION_STATE, NewState: "PRIMARY", OldState: "SECONDARY"},
+	})
+	if events[1].Reason != "no primary" || events[3].Reason != "replSetStepDown command" || events[4].Reason != "" {
+		t.Fatal("expected", "reasons of state changes", "but got", events)
+	}
+	if elected, steppedDown := GetFailovers(events); elected != 2 || steppedDown != 1 {
+		t.Fatal("expected", 2, 1, "but got", elected, steppedDown)
+	}
+}
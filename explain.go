@@ -0,0 +1,92 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * explain.go
+ */
+
+package hatchet
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+var (
+	explainKeyRegex  = regexp.MustCompile(`([{,]\s*)([^\s"{}\[\],:/]+):`)
+	explainListRegex = regexp.MustCompile(`\[\.\.\.\]`)
+)
+
+// GetExplainScript returns a mongosh script running explain("executionStats")
+// of the top N shapes, COLLSCAN shapes first and then by avg ms
+func GetExplainScript(hatchetName string, ops []OpStat, topN int) string {
+	shapes := []OpStat{}
+	for _, op := range ops {
+		if getExplainCommand(op) != "" {
+			shapes = append(shapes, op)
+		}
+	}
+	sort.SliceStable(shapes, func(i int, j int) bool {
+		if (shapes[i].Index == COLLSCAN) != (shapes[j].Index == COLLSCAN) {
+			return shapes[i].Index == COLLSCAN
+		}
+		return shapes[i].AvgMilli > shapes[j].AvgMilli
+	})
+	if topN > 0 && len(shapes) > topN {
+		shapes = shapes[:topN]
+	}
+	printer := message.NewPrinter(language.English)
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("// explain script of %v generated by hatchet\n", hatchetName))
+	buffer.WriteString("// query values are placeholders (1), replace them with representative values before running\n")
+	for i, op := range shapes {
+		buffer.WriteString(printer.Sprintf("\n// %d. %v %v, count: %d, avg ms: %d, index: %v\n",
+			i+1, op.Op, op.Namespace, op.Count, int(op.AvgMilli), op.Index))
+		buffer.WriteString(fmt.Sprintf("printjson(%v);\n", getExplainCommand(op)))
+	}
+	return buffer.String()
+}
+
+// getExplainCommand returns an explain command of a shape, empty if the shape
+// cannot be reconstructed
+func getExplainCommand(op OpStat) string {
+	n := strings.Index(op.Namespace, ".")
+	if n <= 0 || strings.HasSuffix(op.Namespace, ".$cmd") || strings.Contains(op.QueryPattern, ":...") {
+		return ""
+	}
+	coll := fmt.Sprintf(`db.getSiblingDB(%q).getCollection(%q)`, op.Namespace[:n], op.Namespace[n+1:])
+	filter := GetExplainFilter(op.QueryPattern)
+	switch strings.ToLower(op.Op) {
+	case cmdFind:
+		if op.SortPattern != "" {
+			return fmt.Sprintf(`%v.find(%v).sort(%v).explain("executionStats")`, coll, filter, GetExplainFilter(op.SortPattern))
+		}
+		return fmt.Sprintf(`%v.find(%v).explain("executionStats")`, coll, filter)
+	case cmdCount:
+		return fmt.Sprintf(`%v.explain("executionStats").count(%v)`, coll, filter)
+	case cmdAggregate:
+		return fmt.Sprintf(`%v.explain("executionStats").aggregate([{ "$match": %v }])`, coll, filter)
+	case cmdUpdate:
+		return fmt.Sprintf(`%v.explain("executionStats").update(%v, { "$set": { "_placeholder": 1 } })`, coll, filter)
+	case cmdDelete, cmdRemove:
+		return fmt.Sprintf(`%v.explain("executionStats").remove(%v)`, coll, filter)
+	case cmdFindAndModify:
+		return fmt.Sprintf(`%v.explain("executionStats").findAndModify({ "query": %v, "update": { "$set": { "_placeholder": 1 } } })`,
+			coll, filter)
+	}
+	return ""
+}
+
+// GetExplainFilter returns a shell filter of a query pattern, keys are quoted
+// and lists are replaced by a placeholder list
+func GetExplainFilter(pattern string) string {
+	if pattern == "" {
+		return "{}"
+	}
+	filter := explainListRegex.ReplaceAllString(pattern, "[1]")
+	return explainKeyRegex.ReplaceAllString(filter, `$1"$2":`)
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * explain_test.go
+ */
+
+package hatchet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetExplainFilter(t *testing.T) {
+	expected := `{ "category":1, "price":{ "$gt":1 }, "a.b":{ "$in":[1] } }`
+	filter := GetExplainFilter("{ category:1, price:{ $gt:1 }, a.b:{ $in:[...] } }")
+	if filter != expected {
+		t.Fatal("expected", expected, "but got", filter)
+	}
+}
+
+func TestGetExplainScript(t *testing.T) {
+	ops := []OpStat{
+		{Op: "find", Namespace: "shop.products", Index: "{ category:1 }", QueryPattern: "{ category:1 }", AvgMilli: 900, Count: 3},
+		{Op: "update", Namespace: "shop.orders", Index: COLLSCAN, QueryPattern: "{ status:1 }", AvgMilli: 200, Count: 12},
+		{Op: "getMore", Namespace: "shop.$cmd", Index: "ErrMsg: cursor not found"},
+		{Op: "aggregate", Namespace: "shop.orders", Index: COLLSCAN, QueryPattern: `{ $facet:... }`},
+	}
+	script := GetExplainScript("test", ops, 2)
+	if n := strings.Count(script, "printjson("); n != 2 {
+		t.Fatal("expected", 2, "but got", n)
+	}
+	first := strings.Index(script, `getCollection("orders").explain("executionStats").update({ "status":1 }`)
+	second := strings.Index(script, `getCollection("products").find({ "category":1 }).explain("executionStats")`)
+	if first < 0 || second < first {
+		t.Fatal("expected", "COLLSCAN update first", "but got", script)
+	}
+	if !strings.Contains(script, "// 1. update shop.orders, count: 12, avg ms: 200, index: COLLSCAN") {
+		t.Fatal("expected", "count and avg ms comment", "but got", script)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * export.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RunExport exports tables of a SQLite3 database to files of a format
+func RunExport(args []string) error {
+	var err error
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "parquet", "file format, parquet, csv, or tsv")
+	output := fs.String("o", ".", "output directory")
+	url := fs.String("url", SQLITE3_FILE, "SQLite3 database file")
+	fs.Parse(args)
+
+	if *format != "parquet" && *format != "csv" && *format != "tsv" {
+		return fmt.Errorf("unsupported format %v, use parquet, csv, or tsv", *format)
+	} else if strings.HasPrefix(*url, "mongodb") {
+		return errors.New("export only applies to a SQLite3 database file")
+	} else if fs.NArg() == 0 {
+		return errors.New("usage: hatchet export [-format parquet|csv|tsv] [-o dir] [-url file] table...")
+	}
+	if _, err = os.Stat(*url); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite3", *url)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	for _, table := range fs.Args() {
+		filename := filepath.Join(*output, table+"."+*format)
+		file, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		writer := bufio.NewWriter(file)
+		n, err := ExportTable(db, table, *format, writer)
+		if err == nil {
+			err = writer.Flush()
+		}
+		file.Close()
+		if err != nil {
+			os.Remove(filename)
+			return err
+		}
+		log.Printf("exported %v rows of %v to %v\n", n, table, filename)
+	}
+	return nil
+}
+
+// ExportTable writes rows of a table in parquet, csv, or tsv and returns the
+// number of rows
+func ExportTable(db *sql.DB, table string, format string, w io.Writer) (int, error) {
+	var err error
+	var count int
+	if err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count); err != nil {
+		return 0, err
+	} else if count == 0 {
+		return 0, fmt.Errorf("table %v not found", table)
+	}
+	rows, err := db.Query(fmt.Sprintf(`SELECT * FROM "%v"`, table))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	columns := []ParquetColumn{}
+	for _, ctype := range types {
+		column := ParquetColumn{Name: ctype.Name(), Type: PARQUET_BYTE_ARRAY}
+		decl := strings.ToUpper(ctype.DatabaseTypeName())
+		if strings.Contains(decl, "INT") {
+			column.Type = PARQUET_INT64
+		} else if decl == "NUMERIC" || decl == "REAL" || decl == "DOUBLE" || decl == "FLOAT" {
+			column.Type = PARQUET_DOUBLE
+		}
+		columns = append(columns, column)
+	}
+	var csvWriter *csv.Writer
+	var parquet *ParquetWriter
+	names := []string{}
+	for _, column := range columns {
+		names = append(names, column.Name)
+	}
+	if format == "parquet" {
+		if parquet, err = NewParquetWriter(w, columns); err != nil {
+			return 0, err
+		}
+	} else if format == "csv" {
+		csvWriter = csv.NewWriter(w)
+		if err = csvWriter.Write(names); err != nil {
+			return 0, err
+		}
+	} else if _, err = fmt.Fprintln(w, strings.Join(names, "\t")); err != nil {
+		return 0, err
+	}
+	n := 0
+	for rows.Next() {
+		row := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return n, err
+		}
+		if parquet != nil {
+			err = parquet.Write(row)
+		} else if csvWriter != nil {
+			err = csvWriter.Write(toStrings(row))
+		} else {
+			err = writeTSVRow(w, row)
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err = rows.Err(); err != nil {
+		return n, err
+	}
+	if parquet != nil {
+		return n, parquet.Close()
+	} else if csvWriter != nil {
+		csvWriter.Flush()
+		return n, csvWriter.Error()
+	}
+	return n, nil
+}
+
+// WriteSlowOpsCSV writes the summary of slow op shapes as CSV
+func WriteSlowOpsCSV(w io.Writer, ops []OpStat) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"op", "ns", "filter", "sort", "count", "avg_ms", "max_ms", "total_ms", "index",
+		"reslen", "first_seen", "last_seen"})
+	for _, op := range ops {
+		writer.Write([]string{op.Op, op.Namespace, op.QueryPattern, op.SortPattern, fmt.Sprintf("%v", op.Count),
+			fmt.Sprintf("%.1f", op.AvgMilli), fmt.Sprintf("%v", op.MaxMilli), fmt.Sprintf("%v", op.TotalMilli),
+			op.Index, fmt.Sprintf("%v", op.Reslen), op.FirstSeen, op.LastSeen})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// toStrings returns values as strings, empty for nil
+func toStrings(row []interface{}) []string {
+	values := make([]string, len(row))
+	for i, value := range row {
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+		if value != nil {
+			values[i] = fmt.Sprintf("%v", value)
+		}
+	}
+	return values
+}
+
+// writeTSVRow writes values separated by tabs, tabs and newlines of values
+// are replaced by spaces
+func writeTSVRow(w io.Writer, row []interface{}) error {
+	values := toStrings(row)
+	for i, value := range values {
+		values[i] = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(value)
+	}
+	_, err := fmt.Fprintln(w, strings.Join(values, "\t"))
+	return err
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * follow.go
+ */
+
+package hatchet
+
+import (
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// FOLLOW_POLL_INTERVAL is the wait between reads at the end of a followed log
+const FOLLOW_POLL_INTERVAL = 500 * time.Millisecond
+
+// FOLLOW_REFRESH_INTERVAL is the min interval between refreshes of stats of a
+// followed log
+const FOLLOW_REFRESH_INTERVAL = 5 * time.Second
+
+// FollowReader reads a growing log like tail -F, it waits for new lines at
+// the end and reopens the log after it is rotated by rename or truncation
+type FollowReader struct {
+	OnIdle func() // called at the end of the log before waiting
+
+	file     *os.File
+	filename string
+	interval time.Duration
+	offset   int64
+}
+
+// NewFollowReader returns FollowReader of an opened log
+func NewFollowReader(file *os.File, filename string) *FollowReader {
+	return &FollowReader{file: file, filename: filename, interval: FOLLOW_POLL_INTERVAL}
+}
+
+// Read reads available bytes and blocks at the end of the log until more
+// are written
+func (ptr *FollowReader) Read(p []byte) (int, error) {
+	for {
+		n, err := ptr.file.Read(p)
+		ptr.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		} else if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if ptr.reopen() {
+			continue
+		}
+		if ptr.OnIdle != nil {
+			ptr.OnIdle()
+		}
+		time.Sleep(ptr.interval)
+	}
+}
+
+// Close closes the log being read
+func (ptr *FollowReader) Close() error {
+	return ptr.file.Close()
+}
+
+// reopen reads the log from the beginning if it was truncated or replaced,
+// the replaced log has been read to the end
+func (ptr *FollowReader) reopen() bool {
+	info, err := os.Stat(ptr.filename)
+	if err != nil { // renamed and not yet recreated
+		return false
+	}
+	current, err := ptr.file.Stat()
+	if err != nil {
+		return false
+	}
+	if !os.SameFile(info, current) {
+		file, err := os.Open(ptr.filename)
+		if err != nil {
+			return false
+		}
+		log.Println("log rotated, reading new", ptr.filename)
+		ptr.file.Close()
+		ptr.file = file
+		ptr.offset = 0
+		return true
+	} else if info.Size() < ptr.offset {
+		log.Println("log truncated, reading", ptr.filename, "from the beginning")
+		if _, err = ptr.file.Seek(0, io.SeekStart); err != nil {
+			return false
+		}
+		ptr.offset = 0
+		return true
+	}
+	return false
+}
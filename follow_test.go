@@ -0,0 +1,67 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * follow_test.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowReader(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "mongod.log")
+	if err := os.WriteFile(filename, []byte("line 1\nline 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	follower := NewFollowReader(file, filename)
+	defer follower.Close()
+	follower.interval = time.Millisecond
+	writes := []func() error{
+		func() error { // append
+			f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = f.WriteString("line 3\n")
+			return err
+		},
+		func() error { // rotate by rename
+			if err := os.Rename(filename, filename+".1"); err != nil {
+				return err
+			}
+			return os.WriteFile(filename, []byte("line 4\nline 5\n"), 0644)
+		},
+		func() error { // truncate
+			return os.WriteFile(filename, []byte("line 6\n"), 0644)
+		},
+	}
+	follower.OnIdle = func() {
+		if len(writes) == 0 {
+			return
+		}
+		if err := writes[0](); err != nil {
+			t.Error(err)
+		}
+		writes = writes[1:]
+	}
+	reader := bufio.NewReader(follower)
+	for i, expected := range []string{"line 1", "line 2", "line 3", "line 4", "line 5", "line 6"} {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != expected+"\n" {
+			t.Fatal("expected", expected, "but got", line, "at", i)
+		}
+	}
+}
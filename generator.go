@@ -0,0 +1,350 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * generator.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const GENERATE_START = "2023-01-01T00:00:00Z"
+const LOGV2_DATE_FORMAT = "2006-01-02T15:04:05.000-07:00"
+
+// LogGenerator synthesizes a logv2 log of slow queries, connections,
+// authentications, and errors, output is deterministic for a given seed
+type LogGenerator struct {
+	Seed        int64
+	Start       time.Time
+	Duration    time.Duration
+	SlowOps     int
+	Connections int
+	Auths       int
+	Errors      int
+	Host        string
+
+	events []genEvent
+	rand   *rand.Rand
+}
+
+// genEvent is a log line to be written, conns is +1 for an accepted and -1
+// for an ended connection so that the open count is filled in when written
+type genEvent struct {
+	t     time.Time
+	seq   int
+	conns int
+	doc   bson.D
+}
+
+// genApp is a client application and its connection pattern
+type genApp struct {
+	name    string
+	driver  string
+	version string
+	ip      string
+	user    string
+	pattern string // pool, churn, or storm
+}
+
+// genShape is a query shape with its namespace, plan, and base milliseconds
+type genShape struct {
+	ns      string
+	app     string
+	plan    string
+	milli   int
+	command func(r *rand.Rand) (string, bson.D)
+}
+
+var genApps = []genApp{
+	{"catalog", "mongo-java-driver|sync", "4.8.1", "10.0.1.", "catalog_svc", "pool"},
+	{"orders-svc", "nodejs", "4.13.0", "10.0.2.", "orders_svc", "churn"},
+	{"reporting", "PyMongo", "4.3.3", "10.0.3.", "report_ro", "storm"},
+	{"sessions", "mongo-go-driver", "v1.11.1", "10.0.4.", "session_svc", "churn"},
+}
+
+var genShapes = []genShape{
+	{"shop.products", "catalog", "COLLSCAN", 450, func(r *rand.Rand) (string, bson.D) {
+		return "command", bson.D{{Key: "find", Value: "products"},
+			{Key: "filter", Value: bson.D{{Key: "category", Value: fmt.Sprintf("c%d", r.Intn(50))}, {Key: "price", Value: bson.D{{Key: "$gt", Value: r.Intn(100)}}}}},
+			{Key: "sort", Value: bson.D{{Key: "price", Value: -1}, {Key: "name", Value: 1}}}, {Key: "$db", Value: "shop"}}
+	}},
+	{"shop.products", "catalog", "IXSCAN { sku: 1 }", 120, func(r *rand.Rand) (string, bson.D) {
+		return "command", bson.D{{Key: "find", Value: "products"},
+			{Key: "filter", Value: bson.D{{Key: "sku", Value: bson.D{{Key: "$in", Value: bson.A{fmt.Sprintf("SKU-%05d", r.Intn(99999)), fmt.Sprintf("SKU-%05d", r.Intn(99999))}}}}}},
+			{Key: "$db", Value: "shop"}}
+	}},
+	{"shop.orders", "orders-svc", "IXSCAN { customerId: 1, createdAt: -1 }", 180, func(r *rand.Rand) (string, bson.D) {
+		return "command", bson.D{{Key: "find", Value: "orders"},
+			{Key: "filter", Value: bson.D{{Key: "customerId", Value: r.Intn(100000)}, {Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{"new", "paid"}}}}}},
+			{Key: "sort", Value: bson.D{{Key: "createdAt", Value: -1}}}, {Key: "limit", Value: 20}, {Key: "$db", Value: "shop"}}
+	}},
+	{"shop.orders", "orders-svc", "IDHACK", 110, func(r *rand.Rand) (string, bson.D) {
+		return "update", bson.D{{Key: "q", Value: bson.D{{Key: "_id", Value: r.Intn(1000)}}},
+			{Key: "u", Value: bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "paid"}}}}}, {Key: "multi", Value: false}, {Key: "upsert", Value: false}}
+	}},
+	{"shop.orders", "reporting", "COLLSCAN", 2500, func(r *rand.Rand) (string, bson.D) {
+		return "command", bson.D{{Key: "aggregate", Value: "orders"},
+			{Key: "pipeline", Value: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "status", Value: "paid"}, {Key: "createdAt", Value: bson.D{{Key: "$gte", Value: fmt.Sprintf("2023-0%d-01", 1+r.Intn(9))}}}}}},
+				bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$customerId"}, {Key: "total", Value: bson.D{{Key: "$sum", Value: "$amount"}}}}}},
+				bson.D{{Key: "$sort", Value: bson.D{{Key: "total", Value: -1}}}}}},
+			{Key: "cursor", Value: bson.D{}}, {Key: "$db", Value: "shop"}}
+	}},
+	{"analytics.events", "reporting", "IXSCAN { type: 1, ts: 1 }", 900, func(r *rand.Rand) (string, bson.D) {
+		return "command", bson.D{{Key: "count", Value: "events"},
+			{Key: "query", Value: bson.D{{Key: "type", Value: fmt.Sprintf("t%d", r.Intn(20))}, {Key: "ts", Value: bson.D{{Key: "$gte", Value: r.Intn(1000000)}}}}},
+			{Key: "$db", Value: "analytics"}}
+	}},
+	{"app.sessions", "sessions", "IXSCAN { expiresAt: 1 }", 150, func(r *rand.Rand) (string, bson.D) {
+		return "remove", bson.D{{Key: "q", Value: bson.D{{Key: "expiresAt", Value: bson.D{{Key: "$lt", Value: r.Intn(1000000)}}}}}, {Key: "limit", Value: 0}}
+	}},
+	{"app.sessions", "sessions", "IXSCAN { userId: 1 }", 105, func(r *rand.Rand) (string, bson.D) {
+		return "command", bson.D{{Key: "find", Value: "sessions"},
+			{Key: "filter", Value: bson.D{{Key: "userId", Value: r.Intn(100000)}}}, {Key: "$db", Value: "app"}}
+	}},
+}
+
+var genErrors = []struct {
+	severity  string
+	component string
+	id        int
+	msg       string
+	attr      func(r *rand.Rand) bson.D
+}{
+	{"W", "COMMAND", 20525, "Failed to gather storage statistics for slow operation", func(r *rand.Rand) bson.D {
+		return bson.D{{Key: "opId", Value: r.Intn(1000000)}, {Key: "error", Value: "lock acquire timeout"}}
+	}},
+	{"E", "STORAGE", 22435, "WiredTiger error", func(r *rand.Rand) bson.D {
+		return bson.D{{Key: "error", Value: 16}, {Key: "message", Value: "__posix_open_file: Device or resource busy"}}
+	}},
+	{"W", "NETWORK", 4615610, "Failed to check socket connectivity", func(r *rand.Rand) bson.D {
+		return bson.D{{Key: "error", Value: bson.D{{Key: "code", Value: 6}, {Key: "codeName", Value: "HostUnreachable"}, {Key: "errmsg", Value: "Connection reset by peer"}}}}
+	}},
+	{"E", "REPL", 21799, "Sync source candidate chosen failed", func(r *rand.Rand) bson.D {
+		return bson.D{{Key: "syncSource", Value: fmt.Sprintf("host%d:27017", 2+r.Intn(2))}, {Key: "error", Value: "NetworkTimeout"}}
+	}},
+}
+
+// NewLogGenerator returns LogGenerator with default counts
+func NewLogGenerator(seed int64) *LogGenerator {
+	start, _ := time.Parse(time.RFC3339, GENERATE_START)
+	return &LogGenerator{Seed: seed, Start: start, Duration: time.Hour, SlowOps: 1000,
+		Connections: 200, Auths: 100, Errors: 20, Host: "host1"}
+}
+
+// RunGenerate parses arguments of the generate subcommand and writes a log
+func RunGenerate(args []string) error {
+	var err error
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	seed := fs.Int64("seed", 0, "random seed, 0 for a random one")
+	start := fs.String("start", GENERATE_START, "timestamp of the first line")
+	duration := fs.Duration("duration", time.Hour, "time span of logs")
+	slowOps := fs.Int("slow-ops", 1000, "number of slow queries")
+	conns := fs.Int("conns", 200, "number of connections")
+	auths := fs.Int("auths", 100, "number of authentications")
+	errs := fs.Int("errors", 20, "number of warnings and errors")
+	output := fs.String("o", "-", "output file, - for stdout")
+	fs.Parse(args)
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	gen := NewLogGenerator(*seed)
+	if gen.Start, err = time.Parse(time.RFC3339, *start); err != nil {
+		return err
+	}
+	gen.Duration = *duration
+	gen.SlowOps = *slowOps
+	gen.Connections = *conns
+	gen.Auths = *auths
+	gen.Errors = *errs
+
+	writer := os.Stdout
+	if *output != "-" {
+		if writer, err = os.Create(*output); err != nil {
+			return err
+		}
+		defer writer.Close()
+	}
+	if err = gen.Generate(writer); err != nil {
+		return err
+	}
+	log.Printf("generated logs with seed %v\n", *seed)
+	return nil
+}
+
+// Generate writes logs in time order
+func (ptr *LogGenerator) Generate(w io.Writer) error {
+	if ptr.Duration <= 0 {
+		return fmt.Errorf("invalid duration %v", ptr.Duration)
+	}
+	ptr.rand = rand.New(rand.NewSource(ptr.Seed))
+	ptr.events = nil
+	ptr.addStartup()
+	ptr.addConnections()
+	ptr.addAuths()
+	ptr.addSlowOps()
+	ptr.addErrors()
+	sort.SliceStable(ptr.events, func(i, j int) bool {
+		if ptr.events[i].t.Equal(ptr.events[j].t) {
+			return ptr.events[i].seq < ptr.events[j].seq
+		}
+		return ptr.events[i].t.Before(ptr.events[j].t)
+	})
+
+	writer := bufio.NewWriter(w)
+	conns := 0
+	for _, event := range ptr.events {
+		doc := event.doc
+		if event.conns != 0 {
+			conns += event.conns
+			attr := doc[len(doc)-1].Value.(bson.D)
+			doc[len(doc)-1].Value = append(attr, bson.E{Key: "connectionCount", Value: conns})
+		}
+		buf, err := bson.MarshalExtJSON(doc, false, false)
+		if err != nil {
+			return err
+		}
+		writer.Write(buf)
+		writer.WriteByte('\n')
+	}
+	return writer.Flush()
+}
+
+// add appends a log line
+func (ptr *LogGenerator) add(t time.Time, conns int, severity string, component string, id int,
+	ctx string, msg string, attr bson.D) {
+	doc := bson.D{{Key: "t", Value: bson.D{{Key: "$date", Value: t.UTC().Format(LOGV2_DATE_FORMAT)}}},
+		{Key: "s", Value: severity}, {Key: "c", Value: component}, {Key: "id", Value: id}, {Key: "ctx", Value: ctx}, {Key: "msg", Value: msg}, {Key: "attr", Value: attr}}
+	ptr.events = append(ptr.events, genEvent{t: t, seq: len(ptr.events), conns: conns, doc: doc})
+}
+
+// randomTime returns a time within the span
+func (ptr *LogGenerator) randomTime() time.Time {
+	return ptr.Start.Add(time.Duration(ptr.rand.Int63n(int64(ptr.Duration))))
+}
+
+func (ptr *LogGenerator) addStartup() {
+	ptr.add(ptr.Start, 0, "I", "CONTROL", 4615611, "initandlisten", "MongoDB starting",
+		bson.D{{Key: "pid", Value: 1000 + ptr.rand.Intn(60000)}, {Key: "port", Value: 27017}, {Key: "dbPath", Value: "/data/db"},
+			{Key: "architecture", Value: "64-bit"}, {Key: "host", Value: ptr.Host}})
+	ptr.add(ptr.Start, 0, "I", "CONTROL", 23403, "initandlisten", "Build Info",
+		bson.D{{Key: "buildInfo", Value: bson.D{{Key: "version", Value: "6.0.5"}, {Key: "gitVersion", Value: "c9a99c120371d4d4c52cbb15dac34a36ce8d3b1d"},
+			{Key: "modules", Value: bson.A{"enterprise"}},
+			{Key: "environment", Value: bson.D{{Key: "distmod", Value: "rhel80"}, {Key: "distarch", Value: "x86_64"}}}}}})
+}
+
+// addConnections adds connections of pooled clients lasting most of the span,
+// clients churning short-lived connections, and a burst of connections
+func (ptr *LogGenerator) addConnections() {
+	storm := ptr.randomTime()
+	for i := 1; i <= ptr.Connections; i++ {
+		app := genApps[ptr.rand.Intn(len(genApps))]
+		var opened time.Time
+		var lasting time.Duration
+		switch app.pattern {
+		case "pool":
+			opened = ptr.Start.Add(time.Duration(ptr.rand.Int63n(int64(ptr.Duration/20) + 1)))
+			lasting = ptr.Duration
+		case "storm":
+			opened = storm.Add(time.Duration(ptr.rand.Int63n(int64(time.Minute))))
+			lasting = time.Duration(1+ptr.rand.Intn(30)) * time.Second
+		default:
+			opened = ptr.randomTime()
+			lasting = time.Duration(100+ptr.rand.Intn(5000)) * time.Millisecond
+		}
+		ctx := fmt.Sprintf("conn%d", i)
+		remote := fmt.Sprintf("%v%d:%d", app.ip, 10+ptr.rand.Intn(20), 30000+ptr.rand.Intn(30000))
+		uuid := fmt.Sprintf("%08x-0000-4000-8000-%012x", ptr.rand.Uint32(), i)
+		ptr.add(opened, 1, "I", "NETWORK", 22943, "listener", "Connection accepted",
+			bson.D{{Key: "remote", Value: remote}, {Key: "uuid", Value: uuid}, {Key: "connectionId", Value: i}})
+		ptr.add(opened.Add(time.Millisecond), 0, "I", "NETWORK", 51800, ctx, "client metadata",
+			bson.D{{Key: "remote", Value: remote}, {Key: "client", Value: ctx},
+				{Key: "doc", Value: bson.D{{Key: "application", Value: bson.D{{Key: "name", Value: app.name}}},
+					{Key: "driver", Value: bson.D{{Key: "name", Value: app.driver}, {Key: "version", Value: app.version}}},
+					{Key: "os", Value: bson.D{{Key: "type", Value: "Linux"}, {Key: "name", Value: "linux"}, {Key: "architecture", Value: "x86_64"}}}}}})
+		if ended := opened.Add(lasting); ended.Before(ptr.Start.Add(ptr.Duration)) {
+			ptr.add(ended, -1, "I", "NETWORK", 22944, ctx, "Connection ended",
+				bson.D{{Key: "remote", Value: remote}, {Key: "uuid", Value: uuid}, {Key: "connectionId", Value: i}})
+		}
+	}
+}
+
+// addAuths adds authentications, one in ten fails
+func (ptr *LogGenerator) addAuths() {
+	for i := 0; i < ptr.Auths; i++ {
+		app := genApps[ptr.rand.Intn(len(genApps))]
+		t := ptr.randomTime()
+		ctx := fmt.Sprintf("conn%d", 1+ptr.rand.Intn(ptr.Connections+1))
+		remote := fmt.Sprintf("%v%d:%d", app.ip, 10+ptr.rand.Intn(20), 30000+ptr.rand.Intn(30000))
+		if ptr.rand.Intn(10) == 0 {
+			ptr.add(t, 0, "I", "ACCESS", 20249, ctx, "Authentication failed",
+				bson.D{{Key: "mechanism", Value: "SCRAM-SHA-256"}, {Key: "speculative", Value: false}, {Key: "principalName", Value: app.user},
+					{Key: "authenticationDatabase", Value: "admin"}, {Key: "remote", Value: remote},
+					{Key: "error", Value: "AuthenticationFailed: SCRAM authentication failed, storedKey mismatch"}})
+			continue
+		}
+		ptr.add(t, 0, "I", "ACCESS", 20250, ctx, "Authentication succeeded",
+			bson.D{{Key: "mechanism", Value: "SCRAM-SHA-256"}, {Key: "speculative", Value: true}, {Key: "principalName", Value: app.user},
+				{Key: "authenticationDatabase", Value: "admin"}, {Key: "remote", Value: remote}, {Key: "extraInfo", Value: bson.D{}}})
+	}
+}
+
+// addSlowOps adds slow queries of shapes, a few shapes are more frequent
+func (ptr *LogGenerator) addSlowOps() {
+	for i := 0; i < ptr.SlowOps; i++ {
+		n := ptr.rand.Intn(len(genShapes))
+		if ptr.rand.Intn(2) == 0 {
+			n = ptr.rand.Intn(3)
+		}
+		shape := genShapes[n]
+		t := ptr.randomTime()
+		milli := shape.milli/2 + ptr.rand.Intn(shape.milli)
+		if milli < 100 {
+			milli = 100
+		}
+		opType, command := shape.command(ptr.rand)
+		component := "COMMAND"
+		if opType != "command" {
+			component = "WRITE"
+		}
+		docs := 1 + ptr.rand.Intn(1000)
+		keys := docs
+		if shape.plan == "COLLSCAN" {
+			keys = 0
+			docs *= 100
+		}
+		attr := bson.D{{Key: "type", Value: opType}, {Key: "ns", Value: shape.ns}, {Key: "appName", Value: shape.app}, {Key: "command", Value: command},
+			{Key: "planSummary", Value: shape.plan}, {Key: "keysExamined", Value: keys}, {Key: "docsExamined", Value: docs}}
+		if opType == "update" {
+			attr = append(attr, bson.E{Key: "nMatched", Value: 1}, bson.E{Key: "nModified", Value: ptr.rand.Intn(2)})
+		} else if opType == "remove" {
+			attr = append(attr, bson.E{Key: "ndeleted", Value: ptr.rand.Intn(500)})
+		} else {
+			attr = append(attr, bson.E{Key: "nreturned", Value: ptr.rand.Intn(100)},
+				bson.E{Key: "reslen", Value: 200 + ptr.rand.Intn(100000)})
+		}
+		attr = append(attr, bson.E{Key: "protocol", Value: "op_msg"}, bson.E{Key: "durationMillis", Value: milli})
+		ctx := fmt.Sprintf("conn%d", 1+ptr.rand.Intn(ptr.Connections+1))
+		ptr.add(t, 0, "I", component, 51803, ctx, "Slow query", attr)
+	}
+}
+
+func (ptr *LogGenerator) addErrors() {
+	for i := 0; i < ptr.Errors; i++ {
+		e := genErrors[ptr.rand.Intn(len(genErrors))]
+		ptr.add(ptr.randomTime(), 0, e.severity, e.component, e.id, "conn"+fmt.Sprint(1+ptr.rand.Intn(ptr.Connections+1)),
+			e.msg, e.attr(ptr.rand))
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * generator_test.go
+ */
+
+package hatchet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogGenerator(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	gen := NewLogGenerator(42)
+	gen.SlowOps = 50
+	gen.Connections = 20
+	gen.Auths = 10
+	gen.Errors = 5
+	if err := gen.Generate(&buf1); err != nil {
+		t.Fatal(err)
+	}
+	if err := gen.Generate(&buf2); err != nil {
+		t.Fatal(err)
+	}
+	if buf1.String() != buf2.String() {
+		t.Fatal("expected same logs of the same seed")
+	}
+
+	slowOps := 0
+	var last time.Time
+	for _, line := range strings.Split(strings.TrimSpace(buf1.String()), "\n") {
+		doc := Logv2Info{}
+		if err := UnmarshalLogv2([]byte(line), time.UTC, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.Timestamp.Before(last) {
+			t.Fatal("expected time order but got", doc.Timestamp, "before", last)
+		}
+		last = doc.Timestamp
+		if stat, err := AnalyzeSlowOp(&doc); err == nil && stat.Op != "" {
+			slowOps++
+		}
+	}
+	if slowOps != gen.SlowOps {
+		t.Fatal("expected", gen.SlowOps, "but got", slowOps)
+	}
+
+	gen.Seed = 43
+	var buf3 bytes.Buffer
+	if err := gen.Generate(&buf3); err != nil {
+		t.Fatal(err)
+	}
+	if buf1.String() == buf3.String() {
+		t.Fatal("expected different logs of a different seed")
+	}
+}
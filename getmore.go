@@ -0,0 +1,82 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * getmore.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// GetMoreStat stores latencies of initial executions, finds or aggregates,
+// and of getMore batches of a shape
+type GetMoreStat struct {
+	Cursors        int     `json:"cursors" bson:"cursors"` // distinct cursors of getMores
+	GetMoreAvg     float64 `json:"getmore_avg_ms" bson:"getmore_avg_ms"`
+	GetMoreCount   int     `json:"getmore_count" bson:"getmore_count"`
+	GetMoreMax     int     `json:"getmore_max_ms" bson:"getmore_max_ms"`
+	GetMoreTotalMs int     `json:"getmore_total_ms" bson:"getmore_total_ms"`
+	InitialAvg     float64 `json:"initial_avg_ms" bson:"initial_avg_ms"`
+	InitialCount   int     `json:"initial_count" bson:"initial_count"`
+	InitialMax     int     `json:"initial_max_ms" bson:"initial_max_ms"`
+	Namespace      string  `json:"ns" bson:"ns"`
+	OriginatingOp  string  `json:"originating_op" bson:"originating_op"`
+	QueryPattern   string  `json:"query_pattern" bson:"query_pattern"`
+}
+
+// GetCursorOp returns the op of the originating command and the cursor id of
+// a getMore, or the op and the cursor id of a find or an aggregate returning
+// a cursor, false otherwise
+func GetCursorOp(doc *Logv2Info, stat *OpStat) (string, interface{}, bool) {
+	if stat == nil || stat.Op == "" {
+		return "", nil, false
+	}
+	attr := doc.Attr.Map()
+	if stat.Op == cmdGetMore {
+		command, ok := attr["command"].(bson.D)
+		if !ok || len(command) == 0 || command[0].Key != cmdGetMore {
+			return "", nil, false
+		}
+		return getOp(doc.Attributes.OriginatingCommand), getCursorID(command[0].Value), true
+	} else if stat.Op == cmdFind || stat.Op == cmdAggregate {
+		return stat.Op, getCursorID(attr["cursorid"]), true
+	}
+	return "", nil, false
+}
+
+// SortGetMoreStats sorts shapes by total getMore milliseconds
+func SortGetMoreStats(stats []GetMoreStat) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].GetMoreTotalMs > stats[j].GetMoreTotalMs
+	})
+}
+
+// GetGetMoreAuditData returns shapes iterated by getMores by namespace with
+// the originating op, query pattern, initial count and average milliseconds,
+// and getMore count and average milliseconds
+func GetGetMoreAuditData(stats []GetMoreStat) []NameValues {
+	docs := []NameValues{}
+	for _, stat := range stats {
+		docs = append(docs, NameValues{stat.Namespace, []interface{}{stat.OriginatingOp, stat.QueryPattern,
+			stat.InitialCount, int(stat.InitialAvg + 0.5), stat.GetMoreCount, int(stat.GetMoreAvg + 0.5)}})
+	}
+	return docs
+}
+
+// getCursorID returns a non-zero cursor id, nil otherwise
+func getCursorID(value interface{}) interface{} {
+	switch id := value.(type) {
+	case int64:
+		if id != 0 {
+			return id
+		}
+	case int32:
+		if id != 0 {
+			return int64(id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * getmore_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestGetCursorOp(t *testing.T) {
+	str := `{"t":{"$date":"2023-01-01T00:10:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"getMore":{"$numberLong":"1000"},"collection":"orders","$db":"shop"},"originatingCommand":{"find":"orders","filter":{"status":"A"},"$db":"shop"},"planSummary":"COLLSCAN","cursorid":{"$numberLong":"1000"},"nreturned":101,"durationMillis":400}}`
+	doc := Logv2Info{}
+	if err := UnmarshalLogv2([]byte(str), GetLogv2().location, &doc); err != nil {
+		t.Fatal(err)
+	}
+	stat, err := AnalyzeSlowOp(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, id, ok := GetCursorOp(&doc, stat)
+	if !ok || op != cmdFind || id != int64(1000) {
+		t.Fatal("expected", "find of cursor 1000", "but got", op, id, ok)
+	}
+	str = `{"t":{"$date":"2023-01-01T00:10:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders","filter":{"status":"A"},"$db":"shop"},"planSummary":"COLLSCAN","nreturned":1,"durationMillis":400}}`
+	doc = Logv2Info{}
+	if err = UnmarshalLogv2([]byte(str), GetLogv2().location, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if stat, err = AnalyzeSlowOp(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if op, id, ok = GetCursorOp(&doc, stat); !ok || op != cmdFind || id != nil {
+		t.Fatal("expected", "find without a cursor", "but got", op, id, ok)
+	}
+}
+
+func TestSortGetMoreStats(t *testing.T) {
+	stats := []GetMoreStat{
+		{Namespace: "shop.items", OriginatingOp: cmdAggregate, InitialCount: 1, InitialAvg: 900, GetMoreCount: 1, GetMoreAvg: 200, GetMoreTotalMs: 200},
+		{Namespace: "shop.orders", OriginatingOp: cmdFind, InitialCount: 6, InitialAvg: 120, GetMoreCount: 18, GetMoreAvg: 400.6, GetMoreTotalMs: 7210},
+	}
+	SortGetMoreStats(stats)
+	docs := GetGetMoreAuditData(stats)
+	if docs[0].Name != "shop.orders" || docs[0].Values[4] != 18 || docs[0].Values[5] != 401 {
+		t.Fatal("expected", "18 getMores of shop.orders at 401 ms", "but got", docs[0])
+	}
+}
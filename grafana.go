@@ -0,0 +1,163 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * grafana.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// datasources of generated Grafana dashboards
+const (
+	GRAFANA_API        = "api"        // hatchet API by the Infinity plugin
+	GRAFANA_PROMETHEUS = "prometheus" // /metrics scraped by Prometheus
+)
+
+// GRAFANA_INFINITY is the plugin id of the Infinity datasource, querying JSON
+// of URLs
+const GRAFANA_INFINITY = "yesoreyeram-infinity-datasource"
+
+// grafanaQuery is a Prometheus expression or a hatchet API path of a panel
+type grafanaQuery struct {
+	Legend string
+	Query  string
+}
+
+// grafanaPanel is a time series panel of Prometheus expressions, or a table
+// of rows of a hatchet API response
+type grafanaPanel struct {
+	Queries []grafanaQuery
+	Root    string // field of rows of an API response
+	Title   string
+	Unit    string
+}
+
+// grafanaPrometheusPanels are panels of metrics of /metrics
+var grafanaPrometheusPanels = []grafanaPanel{
+	{Title: "Ops per Second", Unit: "ops", Queries: []grafanaQuery{
+		{"{{op}}", `sum by (op) (rate(hatchet_ops_total{hatchet=~"$hatchet"}[$__rate_interval]))`}}},
+	{Title: "Slow Ops per Second", Unit: "ops", Queries: []grafanaQuery{
+		{"{{op}}", `sum by (op) (rate(hatchet_slow_ops_total{hatchet=~"$hatchet"}[$__rate_interval]))`}}},
+	{Title: "Average Op Latency", Unit: "ms", Queries: []grafanaQuery{
+		{"{{op}}", `sum by (op) (rate(hatchet_ops_milliseconds_total{hatchet=~"$hatchet"}[$__rate_interval])) / sum by (op) (rate(hatchet_ops_total{hatchet=~"$hatchet"}[$__rate_interval]))`}}},
+	{Title: "Connections", Unit: "short", Queries: []grafanaQuery{
+		{"open {{hatchet}}", `hatchet_connections{hatchet=~"$hatchet"}`},
+		{"accepted/s {{hatchet}}", `rate(hatchet_connections_accepted_total{hatchet=~"$hatchet"}[$__rate_interval])`}}},
+	{Title: "Messages per Second by Severity", Unit: "short", Queries: []grafanaQuery{
+		{"{{severity}}", `sum by (severity) (rate(hatchet_messages_total{hatchet=~"$hatchet",severity!="I"}[$__rate_interval]))`}}},
+	{Title: "Ingestion", Unit: "short", Queries: []grafanaQuery{
+		{"lines/s {{hatchet}}", `rate(hatchet_lines_total{hatchet=~"$hatchet"}[$__rate_interval])`},
+		{"parse errors/s {{hatchet}}", `rate(hatchet_parse_errors_total{hatchet=~"$hatchet"}[$__rate_interval])`},
+		{"lag s {{hatchet}}", `time() - hatchet_last_log_timestamp_seconds{hatchet=~"$hatchet"}`}}},
+}
+
+// grafanaAPIPanels are tables of hatchet API responses
+var grafanaAPIPanels = []grafanaPanel{
+	{Title: "Slow Op Shapes", Root: "ops", Queries: []grafanaQuery{{"", "/stats/slowops?orderBy=total_ms"}}},
+	{Title: "Poorly Targeted Shapes", Root: "flagged", Queries: []grafanaQuery{{"", "/stats/targeting"}}},
+	{Title: "Blocking Sorts and Spills to Disk", Root: "shapes", Queries: []grafanaQuery{{"", "/stats/sorts"}}},
+	{Title: "Initial Execution vs getMore Latency", Root: "shapes", Queries: []grafanaQuery{{"", "/stats/getmores"}}},
+	{Title: "Index Suggestions", Root: "suggestions", Queries: []grafanaQuery{{"", "/indexes/suggestions"}}},
+	{Title: "Configuration Changes", Root: "changes", Queries: []grafanaQuery{{"", "/stats/config"}}},
+}
+
+// RunGrafana prints a Grafana dashboard of metrics scraped from /metrics or
+// of tables of the hatchet API
+func RunGrafana(args []string) error {
+	fs := flag.NewFlagSet("grafana", flag.ExitOnError)
+	datasource := fs.String("datasource", GRAFANA_PROMETHEUS, "datasource of panels, prometheus or api")
+	hatchetName := fs.String("hatchet", "", "default hatchet of the dashboard")
+	output := fs.String("o", "", "output file, stdout if not set")
+	title := fs.String("title", "Hatchet", "dashboard title")
+	url := fs.String("url", "http://localhost:3721", "base URL of the hatchet web server of api panels")
+	fs.Parse(args)
+
+	if *datasource != GRAFANA_PROMETHEUS && *datasource != GRAFANA_API {
+		return errors.New("usage: hatchet grafana [-datasource prometheus|api] [-hatchet name] [-url base] [-title title] [-o file]")
+	}
+	dashboard := GetGrafanaDashboard(*datasource, *title, *hatchetName, *url)
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(dashboard); err != nil {
+		return err
+	}
+	if *output != "" {
+		fmt.Fprintln(os.Stderr, "dashboard written to", *output)
+	}
+	return nil
+}
+
+// GetGrafanaDashboard returns a dashboard to import to Grafana, of time
+// series of Prometheus metrics or of tables of the hatchet API at baseURL
+func GetGrafanaDashboard(datasource string, title string, hatchetName string, baseURL string) map[string]interface{} {
+	dsType, panels := "prometheus", grafanaPrometheusPanels
+	if datasource == GRAFANA_API {
+		dsType, panels = GRAFANA_INFINITY, grafanaAPIPanels
+	}
+	ds := map[string]interface{}{"type": dsType, "uid": "${datasource}"}
+	hatchetVar := map[string]interface{}{"name": "hatchet", "label": "hatchet", "type": "textbox",
+		"query": hatchetName, "current": map[string]interface{}{"text": hatchetName, "value": hatchetName}}
+	if datasource == GRAFANA_PROMETHEUS {
+		hatchetVar = map[string]interface{}{"name": "hatchet", "label": "hatchet", "type": "query", "datasource": ds,
+			"query": "label_values(hatchet_lines_total, hatchet)", "refresh": 2, "multi": true, "includeAll": true,
+			"allValue": ".*"}
+	}
+	items := []map[string]interface{}{}
+	for i, panel := range panels {
+		item := map[string]interface{}{"id": i + 1, "title": panel.Title, "datasource": ds,
+			"gridPos": map[string]int{"h": 8, "w": 12, "x": 12 * (i % 2), "y": 8 * (i / 2)}}
+		targets := []map[string]interface{}{}
+		for n, query := range panel.Queries {
+			refID := string(rune('A' + n))
+			if datasource == GRAFANA_PROMETHEUS {
+				targets = append(targets, map[string]interface{}{"refId": refID, "datasource": ds,
+					"expr": query.Query, "legendFormat": query.Legend})
+			} else {
+				targets = append(targets, map[string]interface{}{"refId": refID, "datasource": ds,
+					"type": "json", "source": "url", "format": "table", "parser": "backend",
+					"url":           strings.TrimSuffix(baseURL, "/") + "/api/hatchet/v1.0/hatchets/$hatchet" + query.Query,
+					"root_selector": panel.Root})
+			}
+		}
+		item["targets"] = targets
+		if datasource == GRAFANA_PROMETHEUS {
+			item["type"] = "timeseries"
+			item["fieldConfig"] = map[string]interface{}{"defaults": map[string]interface{}{"unit": panel.Unit}, "overrides": []interface{}{}}
+		} else {
+			item["type"] = "table"
+			item["options"] = map[string]interface{}{"showHeader": true}
+		}
+		items = append(items, item)
+	}
+	return map[string]interface{}{
+		"title":         title,
+		"uid":           nil,
+		"editable":      true,
+		"schemaVersion": 38,
+		"tags":          []string{"hatchet", "mongodb"},
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"refresh":       "1m",
+		"panels":        items,
+		"templating": map[string]interface{}{"list": []interface{}{
+			map[string]interface{}{"name": "datasource", "label": "datasource", "type": "datasource", "query": dsType},
+			hatchetVar,
+		}},
+	}
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * grafana_test.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGetGrafanaDashboard(t *testing.T) {
+	dashboard := GetGrafanaDashboard(GRAFANA_PROMETHEUS, "Hatchet", "", "")
+	data, err := json.Marshal(dashboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	panels := dashboard["panels"].([]map[string]interface{})
+	if len(panels) != len(grafanaPrometheusPanels) || panels[0]["type"] != "timeseries" {
+		t.Fatal("expected", len(grafanaPrometheusPanels), "timeseries panels", "but got", panels)
+	}
+	if !strings.Contains(string(data), "label_values(hatchet_lines_total, hatchet)") {
+		t.Fatal("expected", "hatchet variable of label values", "but got", string(data))
+	}
+
+	dashboard = GetGrafanaDashboard(GRAFANA_API, "Hatchet", "mongod_1a2b3c", "http://localhost:3721/")
+	panels = dashboard["panels"].([]map[string]interface{})
+	target := panels[0]["targets"].([]map[string]interface{})[0]
+	expected := "http://localhost:3721/api/hatchet/v1.0/hatchets/$hatchet/stats/slowops?orderBy=total_ms"
+	if panels[0]["type"] != "table" || target["url"] != expected || target["root_selector"] != "ops" {
+		t.Fatal("expected", expected, "but got", target)
+	}
+}
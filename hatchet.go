@@ -6,7 +6,6 @@
 package hatchet
 
 import (
-	"database/sql"
 	"encoding/base64"
 	"encoding/json"
 	"flag"
@@ -17,33 +16,115 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
-	"github.com/mattn/go-sqlite3"
 )
 
 const SQLITE3_FILE = "./data/hatchet.db"
 
 func Run(fullVersion string) {
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		if err := RunGenerate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	} else if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := RunExport(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	} else if len(os.Args) > 1 && os.Args[1] == "diff" {
+		if err := RunDiff(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	} else if len(os.Args) > 1 && os.Args[1] == "grafana" {
+		if err := RunGrafana(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	} else if len(os.Args) > 1 && os.Args[1] == "report" {
+		if err := RunReport(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	} else if len(os.Args) > 1 && os.Args[1] == "prune" {
+		if err := RunPrune(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	backend := flag.String("backend", "", "database type, sqlite3 or mongodb, detected from -url if not set")
+	batch := flag.Int("batch", 0, fmt.Sprintf("lines inserted per transaction, defaults to %v of SQLite3 and %v of MongoDB",
+		SQLITE_BATCH_SIZE, BATCH_SIZE))
+	anonymize := flag.Bool("anonymize", false, "replace IPs, hostnames, and database and collection names with consistent aliases before storing")
+	anonymizeKey := flag.String("anonymize-key", os.Getenv("HATCHET_ANONYMIZE_KEY"), "key of aliases of -anonymize, the same key of the same aliases, defaults to $HATCHET_ANONYMIZE_KEY or a random key")
+	atlas := flag.String("atlas", "", "download and analyze mongod logs of all nodes of an Atlas cluster, {project}/{cluster}")
+	assumeTZ := flag.String("assume-tz", "UTC", "time zone of timestamps without UTC offset, e.g. America/New_York or Local")
+	authMode := flag.String("auth", "", "authenticate users of the web server, basic or oidc")
+	authUsers := flag.String("auth-users", os.Getenv("HATCHET_AUTH_USERS"), "users of basic auth, user:password[,...], bcrypt hashed passwords allowed, or @{htpasswd file} of bcrypt hashes only")
 	bios := flag.Bool("bios", false, "populate bios documents")
+	chartTheme := flag.String("chart-theme", "", "colors of charts, colorblind, google, grayscale, or hatchet, or comma separated colors, e.g. #0B3D91,#FC3D21")
+	compare := flag.Bool("compare", false, "compare logs of a good and a bad node")
 	dbfile := flag.String("dbfile", SQLITE3_FILE, "deprecated, use -url")
 	digest := flag.Bool("digest", false, "HTTP digest")
 	endpoint := flag.String("endpoint-url", "", "AWS endpoint")
+	follow := flag.Bool("follow", false, "tail a live log, ingesting new lines as they are written, and start the web server")
+	flag.BoolVar(follow, "f", false, "same as -follow")
+	hours := flag.Int("hours", 24, "hours of Atlas logs to download")
+	hotDocs := flag.Int("hot-doc-threshold", 10, "min writes by _id to report a hot document, 0 to disable")
+	incremental := flag.Bool("incremental", false, "ingest only lines appended to a log file since it was last ingested")
+	jsonl := flag.String("jsonl", "", "write parsed log documents to a file as JSON lines")
 	legacy := flag.Bool("legacy", false, "view logs in legacy format")
+	legacyOut := flag.String("legacy-out", "", "write logs in legacy format to a file, or to a directory if -legacy-split, gzipped if ending with .gz")
+	legacySplit := flag.String("legacy-split", "", "split logs in legacy format of -legacy-out into files by component or severity")
+	maxDBSize := flag.String("max-db-size", "", "stop ingesting when the database file reaches the size, e.g. 10GB")
+	mem := flag.Bool("mem", false, "keep data in memory without a database file, add -web to view results until exit")
+	maxShapes := flag.Int("max-shapes", MAX_SHAPES, "max distinct query shapes, others are counted as "+SHAPE_OTHER+", 0 for unlimited")
+	merge := flag.Bool("merge", false, "analyze logs of nodes into one hatchet, tagging lines with their sources")
 	infile := flag.String("obfuscate", "", "obfuscate logs")
+	oidcAllowed := flag.String("oidc-allowed", "", "emails or @domains of OIDC users allowed, e.g. @example.com, all if not set")
+	oidcClientID := flag.String("oidc-client-id", "", "OIDC client id")
+	oidcClientSecret := flag.String("oidc-client-secret", os.Getenv("HATCHET_OIDC_CLIENT_SECRET"), "OIDC client secret, defaults to $HATCHET_OIDC_CLIENT_SECRET")
+	oidcIssuer := flag.String("oidc-issuer", "", "OIDC issuer URL, e.g. https://accounts.google.com")
+	oidcRedirectURL := flag.String("oidc-redirect-url", "", "OIDC redirect URL registered of the client, e.g. https://hatchet.example.com/auth/callback")
+	oplogWindow := flag.Int("oplog-window", OPLOG_WINDOW_WARN, "warns when the estimated oplog window drops below the hours")
+	targetingRatio := flag.Int("targeting-ratio", TARGETING_RATIO, "flags shapes examining more keys or documents per document returned")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "export slow ops as spans to an OTLP/HTTP endpoint, e.g. http://localhost:4318")
+	otlpService := flag.String("otlp-service", "", `service names of spans by namespace regex, e.g. shop\..*=shop-svc, defaults to namespaces`)
 	port := flag.Int("port", 3721, "web server port number")
+	raw := flag.Bool("raw", false, "store original log lines to export them as is")
+	redact := flag.Bool("redact", false, "replace literal values of filters and commands with ### before storing")
+	quarantine := flag.String("quarantine", "", "write skipped malformed lines and their errors to a file")
 	profile := flag.String("aws-profile", "default", "AWS profile name")
 	s3 := flag.Bool("s3", false, "files from AWS S3")
 	sim := flag.String("sim", "", "simulate read/write load tests")
+	slowMilli := flag.Int("slow-ms", SLOW_MS, "slow op threshold in milliseconds of namespaces not in -slow-ns")
+	slowNS := flag.String("slow-ns", "", `slow op thresholds by namespace regex, e.g. analytics\..*=5000,app.sessions=20`)
+	compressDB := flag.String("compress-db", "", "compress the database file after processing logs, gzip or zstd")
 	connstr := flag.String("url", SQLITE3_FILE, "database file name or connection string")
-	user := flag.String("user", "", "HTTP Auth (username:password)")
+	tlsSelfSigned := flag.Bool("tls", false, "serve the web server over HTTPS, of a self-signed certificate unless -tls-cert and -tls-key")
+	tlsCert := flag.String("tls-cert", "", "PEM certificate file of HTTPS, chain included")
+	flag.StringVar(tlsCert, "tlsCert", "", "same as -tls-cert")
+	tlsKey := flag.String("tls-key", "", "PEM private key file of HTTPS")
+	flag.StringVar(tlsKey, "tlsKey", "", "same as -tls-key")
+	tui := flag.Bool("tui", false, "browse results in a terminal UI")
+	user := flag.String("user", "", "HTTP Auth (username:password) or Atlas API keys (public:private)")
 	ver := flag.Bool("version", false, "print version number")
 	verbose := flag.Bool("verbose", false, "turn on verbose")
 	web := flag.Bool("web", false, "starts a web server")
+	workers := flag.Int("workers", runtime.NumCPU(), "goroutines parsing lines, 1 to parse lines as read")
 	flag.Parse()
 	flagset := make(map[string]bool)
 	flag.Visit(func(f *flag.Flag) { flagset[f.Name] = true })
+	if *legacySplit != "" && *legacyOut == "" {
+		log.Fatalln("-legacy-split requires -legacy-out")
+	} else if *legacyOut != "" {
+		*legacy = true
+	}
 
 	if *ver {
 		fmt.Println(fullVersion)
@@ -80,18 +161,83 @@ func Run(fullVersion string) {
 		connstr = dbfile
 	}
 
-	if *connstr == "in-memory" {
-		if len(flag.Args()) == 0 {
-			log.Fatalln("cannot use -in-memory without a log file")
+	lognames := flag.Args()
+	if *mem && flagset["url"] && *connstr != "in-memory" {
+		log.Fatalln("-mem cannot be used with -url")
+	}
+	if *mem || *connstr == "in-memory" {
+		if len(lognames) == 0 && *atlas == "" {
+			log.Fatalln("cannot use -mem without a log file")
 		}
 		log.Println("in-memory mode is enabled, no data will be persisted")
 		*connstr = "file::memory:?cache=shared"
-		*web = true
+		if !*mem { // -url in-memory always starts the web server
+			*web = true
+		}
+	}
+
+	var err error
+	var archive, archiveExt, atlasDir string
+	var tempDB string // working copy of a compressed database
+	// fatal and fatalln remove the working copy, deferred calls are skipped
+	// by log.Fatal
+	fatal := func(v ...interface{}) {
+		if tempDB != "" {
+			os.Remove(tempDB)
+		}
+		log.Fatal(v...)
+	}
+	fatalln := func(v ...interface{}) {
+		if tempDB != "" {
+			os.Remove(tempDB)
+		}
+		log.Fatalln(v...)
+	}
+	if *compressDB != "" {
+		if archiveExt, err = GetArchiveExt(*compressDB); err != nil {
+			log.Fatal(err)
+		}
+		if strings.HasPrefix(*connstr, "mongodb") || strings.HasPrefix(*connstr, "file::memory:") {
+			log.Fatalln("-compress-db only applies to a SQLite3 database file")
+		}
+	}
+	if IsCompressedDB(*connstr) {
+		archive = *connstr
+		if *connstr, err = DecompressDB(archive); err != nil {
+			fatal(err)
+		}
+		log.Println("decompressed", archive, "to", *connstr)
+		tempDB = *connstr
+		RemoveOnExit(tempDB)
+		defer os.Remove(tempDB)
 	}
 
 	logv2 := Logv2{version: fullVersion, url: *connstr, verbose: *verbose,
-		legacy: *legacy, user: *user, isDigest: *digest}
+		legacy: *legacy, user: *user, isDigest: *digest, hotDocThreshold: *hotDocs,
+		maxShapes: *maxShapes, oplogWindow: *oplogWindow, otlpEndpoint: *otlpEndpoint, storeRaw: *raw, follow: *follow,
+		workers: *workers, batchSize: *batch, backend: *backend, incremental: *incremental, targetingRatio: *targetingRatio,
+		redact: *redact, metrics: NewMetrics()}
 	instance = &logv2
+	if err = logv2.CheckDBType(); err != nil {
+		fatal(err)
+	}
+	if logv2.location, err = time.LoadLocation(*assumeTZ); err != nil {
+		fatal(err)
+	}
+	if logv2.slowThresholds, err = ParseSlowThresholds(*slowNS, *slowMilli); err != nil {
+		fatal(err)
+	}
+	if logv2.otlpServices, err = ParseServiceNames(*otlpService); err != nil {
+		fatal(err)
+	}
+	if logv2.chartColors, err = ParseChartTheme(*chartTheme); err != nil {
+		fatal(err)
+	}
+	if *maxDBSize != "" {
+		if logv2.maxDBSize, err = ParseSize(*maxDBSize); err != nil {
+			fatal(err)
+		}
+	}
 	str := *connstr
 	if strings.HasPrefix(*connstr, "mongodb") {
 		pattern := regexp.MustCompile(`mongodb(\+srv)?:\/\/(.+):(.+)@(.+)`)
@@ -102,29 +248,167 @@ func Run(fullVersion string) {
 	}
 	log.Println("using database", str)
 	if GetLogv2().GetDBType() == SQLite3 {
-		regex := func(re, s string) (bool, error) {
-			return regexp.MatchString(re, s)
-		}
-		sql.Register("sqlite3_extended",
-			&sqlite3.SQLiteDriver{
-				ConnectHook: func(conn *sqlite3.SQLiteConn) error {
-					return conn.RegisterFunc("regexp", regex, true)
-				},
-			})
+		RegisterSQLite3Extended()
 	}
-	if *s3 {
+	hasS3URL := false
+	for _, logname := range lognames {
+		hasS3URL = hasS3URL || strings.HasPrefix(logname, "s3://")
+	}
+	if *s3 || hasS3URL {
 		var err error
 		if logv2.s3client, err = NewS3Client(*profile, *endpoint); err != nil {
-			log.Fatal(err)
+			fatal(err)
 		}
 	}
-	for _, logname := range flag.Args() {
-		if err := logv2.Analyze(logname); err != nil {
-			log.Fatal(err)
+	if *atlas != "" {
+		if len(lognames) > 0 || *s3 || *follow {
+			fatalln("-atlas cannot be used with log files, -s3, or -follow")
+		}
+		if atlasDir, err = os.MkdirTemp("", "hatchet-atlas-*"); err != nil {
+			fatal(err)
+		}
+		if lognames, err = DownloadAtlasLogs(*atlas, *user, *hours, atlasDir); err != nil {
+			os.RemoveAll(atlasDir)
+			fatal(err)
+		}
+	}
+	if *compare && len(lognames) != 2 {
+		fatalln("-compare requires logs of a good and a bad node")
+	}
+	if *follow {
+		if len(lognames) != 1 || *s3 || strings.Contains(lognames[0], "://") {
+			fatalln("-follow requires a local log file")
+		} else if *compare || *compressDB != "" || archive != "" || *tui {
+			fatalln("-follow cannot be used with -compare, -compress-db, compressed databases, or -tui")
+		}
+	}
+	if *merge && (*compare || *follow || *legacy) {
+		fatalln("-merge cannot be used with -compare, -follow, or -legacy")
+	}
+	if *redact && *raw {
+		fatalln("-redact cannot be used with -raw")
+	}
+	if *anonymize {
+		if *raw {
+			fatalln("-anonymize cannot be used with -raw")
+		}
+		if logv2.anonymizer, err = NewAnonymizer(*anonymizeKey); err != nil {
+			fatal(err)
+		}
+		if *anonymizeKey == "" {
+			log.Println("aliases of -anonymize are of a random key, set -anonymize-key for the same aliases of other runs")
+		}
+	}
+	if *incremental && (*compare || *follow || *merge || *mem || strings.HasPrefix(*connstr, "file::memory:")) {
+		fatalln("-incremental cannot be used with -compare, -follow, -merge, or in-memory mode")
+	}
+	if *quarantine != "" && len(lognames) > 0 {
+		if logv2.quarantine, err = NewQuarantine(*quarantine); err != nil {
+			fatal(err)
+		}
+	}
+	if *legacyOut != "" && len(lognames) > 0 {
+		if logv2.legacyOut, err = NewLegacyWriter(*legacyOut, *legacySplit); err != nil {
+			fatal(err)
+		}
+	}
+	if *jsonl != "" && len(lognames) > 0 && !*legacy {
+		if logv2.jsonl, err = NewJSONLWriter(*jsonl); err != nil {
+			fatal(err)
+		}
+	}
+	if err = MigrateHatchets(); err != nil {
+		fatal(err)
+	}
+	hatchetNames := []string{}
+	if *follow && !*legacy { // ingests in the background while serving the web UI
+		logv2.live = NewLiveHub(logv2.metrics)
+		go logv2.live.Run(LIVE_INTERVAL, nil)
+		go func() {
+			if err := logv2.Analyze(lognames[0]); err != nil {
+				fatal(err)
+			}
+		}()
+		*web = true
+	} else if *merge && len(lognames) > 0 {
+		if err := logv2.Merge(lognames); err != nil {
+			fatal(err)
+		}
+		hatchetNames = append(hatchetNames, logv2.hatchetName)
+	} else {
+		for _, logname := range lognames {
+			if err := logv2.Analyze(logname); err != nil {
+				fatal(err)
+			}
+			hatchetNames = append(hatchetNames, logv2.hatchetName)
+		}
+	}
+	if atlasDir != "" {
+		os.RemoveAll(atlasDir)
+	}
+	if logv2.quarantine != nil && !*follow {
+		if err = logv2.quarantine.Close(); err != nil {
+			fatal(err)
 		}
+		log.Printf("%v skipped lines written to %v\n", logv2.quarantine.Count, *quarantine)
+	}
+	if logv2.jsonl != nil && !*follow {
+		if err = logv2.jsonl.Close(); err != nil {
+			fatal(err)
+		}
+		log.Printf("%v documents written to %v\n", logv2.jsonl.Count, *jsonl)
+	}
+	if logv2.legacyOut != nil {
+		if err = logv2.legacyOut.Close(); err != nil {
+			fatal(err)
+		}
+		log.Printf("%v lines written to %v\n", logv2.legacyOut.Count, strings.Join(logv2.legacyOut.Filenames(), ", "))
+	}
+	if archive != "" && len(lognames) > 0 && !*legacy {
+		if err = CompressDB(*connstr, archive); err != nil {
+			fatal(err)
+		}
+		log.Println("updated", archive)
+	} else if archiveExt != "" && len(lognames) > 0 && !*legacy {
+		artifact := *connstr + archiveExt
+		if err = CompressDB(*connstr, artifact); err != nil {
+			fatal(err)
+		}
+		os.Remove(*connstr)
+		log.Println("compressed database to", artifact)
+		if *web {
+			if logv2.url, err = DecompressDB(artifact); err != nil {
+				fatal(err)
+			}
+			tempDB = logv2.url
+			RemoveOnExit(tempDB)
+			defer os.Remove(tempDB)
+		}
+	}
+	if *compare && !*legacy {
+		comparison, err := CompareHatchets(hatchetNames[0], hatchetNames[1])
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Print(comparison)
+		if *web {
+			log.Printf("comparison is available at /hatchets/%v/compare/%v\n", hatchetNames[0], hatchetNames[1])
+		}
+	}
+	if *tui && !*legacy {
+		hatchetName := ""
+		if len(hatchetNames) > 0 {
+			hatchetName = hatchetNames[len(hatchetNames)-1]
+		} else if hatchetName, err = SelectHatchet(); err != nil {
+			fatal(err)
+		}
+		if err = RunTUI(hatchetName); err != nil {
+			fatal(err)
+		}
+		return
 	}
 	if *legacy || !*web {
-		if len(flag.Args()) == 0 {
+		if len(lognames) == 0 {
 			flag.PrintDefaults()
 		}
 		return
@@ -133,21 +417,60 @@ func Run(fullVersion string) {
 	router := httprouter.New()
 	router.GET("/", Handler)
 	router.GET("/favicon.ico", FaviconHandler)
+	router.GET("/metrics", MetricsHandler)
+	router.GET("/api/openapi.json", OpenAPIHandler)
 
 	router.GET("/api/hatchet/v1.0/mongodb/:mongo/drivers/:driver", DriverHandler)
 	router.GET("/api/hatchet/v1.0/hatchets/:hatchet/:category/:attr", APIHandler)
+	router.GET("/api/hatchet/v1.0/schema", SchemaHandler)
+	router.GET("/api/hatchet/v1.0/prune", PruneHandler)
+	router.DELETE("/api/hatchet/v1.0/prune", PruneHandler)
+	router.POST("/api/hatchet/v1.0/hatchets/:hatchet/bookmarks/:attr", BookmarkAPIHandler)
+	router.DELETE("/api/hatchet/v1.0/hatchets/:hatchet/bookmarks/:attr", BookmarkAPIHandler)
+	router.GET("/api/hatchet/v1.0/views", ViewsAPIHandler)
+	router.POST("/api/hatchet/v1.0/views/:name", ViewsAPIHandler)
+	router.DELETE("/api/hatchet/v1.0/views/:name", ViewsAPIHandler)
 
+	router.GET("/hatchets/:hatchet/bookmarks/:attr", BookmarksHandler)
 	router.GET("/hatchets/:hatchet/charts/:attr", ChartsHandler)
+	router.GET("/hatchets/:hatchet/compare/:attr", CompareHandler)
+	router.GET("/hatchets/:hatchet/diff/:attr", DiffHandler)
+	router.GET("/hatchets/:hatchet/live/:attr", LiveHandler)
 	router.GET("/hatchets/:hatchet/logs/:attr", LogsHandler)
+	router.GET("/hatchets/:hatchet/ns/:attr", NamespaceHandler)
 	router.GET("/hatchets/:hatchet/stats/:attr", StatsHandler)
 
+	var handler http.Handler = router
+	if *authMode != "" {
+		auth, err := NewAuth(AuthOptions{Allowed: *oidcAllowed, ClientID: *oidcClientID, ClientSecret: *oidcClientSecret,
+			Issuer: *oidcIssuer, Mode: *authMode, RedirectURL: *oidcRedirectURL, Users: *authUsers})
+		if err != nil {
+			fatal(err)
+		}
+		handler = auth.Handler(router)
+		log.Println("web server requires", *authMode, "authentication")
+	}
+
 	addr := fmt.Sprintf(":%d", *port)
+	server := &http.Server{Addr: addr, Handler: handler}
+	if *tlsSelfSigned || *tlsCert != "" || *tlsKey != "" {
+		if server.TLSConfig, err = GetTLSConfig(*tlsCert, *tlsKey); err != nil {
+			fatal(err)
+		}
+		if *tlsCert == "" {
+			log.Println("self-signed certificate, SHA-256 fingerprint", GetCertFingerprint(server.TLSConfig.Certificates[0]))
+		}
+	}
 	if listener, err := net.Listen("tcp", addr); err != nil {
-		log.Fatal(err)
+		fatal(err)
 	} else {
 		listener.Close()
+		if server.TLSConfig != nil {
+			log.Println("starting web server at", addr, "over HTTPS")
+			fatal(server.ListenAndServeTLS("", ""))
+		}
 		log.Println("starting web server at", addr)
-		log.Fatal(http.ListenAndServe(addr, router))
+		fatal(server.ListenAndServe())
 	}
 }
 
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * heatmap.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"sort"
+)
+
+// heatmap layouts and metrics
+const (
+	HEATMAP_BY_DAY = "day" // day by hour of day
+	HEATMAP_BY_NS  = "ns"  // namespace by hour
+	HEATMAP_COUNT  = "count"
+	HEATMAP_MILLIS = "ms"
+
+	// HEATMAP_MAX_ROWS is the max namespaces of a heatmap, the busiest first
+	HEATMAP_MAX_ROWS = 25
+)
+
+// HeatmapCell is slow ops of a row, a namespace or a day, in a column, an
+// hour or an hour of day
+type HeatmapCell struct {
+	Column     string `bson:"column"`
+	Count      int    `bson:"count"`
+	Row        string `bson:"row"`
+	TotalMilli int    `bson:"total_ms"`
+}
+
+// HeatmapRow is values of a row by column
+type HeatmapRow struct {
+	Name   string
+	Total  int
+	Values []int
+}
+
+// Heatmap is rows of values by columns and the max value of cells
+type Heatmap struct {
+	Columns []string
+	Max     int
+	Rows    []HeatmapRow
+}
+
+// GetHeatmap returns a heatmap of counts or total milliseconds of cells, all
+// 24 hours are columns of a day layout and rows are days in order, columns of
+// a namespace layout are hours logged and rows are the busiest namespaces
+func GetHeatmap(cells []HeatmapCell, layout string, metric string) Heatmap {
+	heatmap := Heatmap{Columns: []string{}, Rows: []HeatmapRow{}}
+	positions := map[string]int{}
+	if layout == HEATMAP_BY_DAY {
+		for hour := 0; hour < 24; hour++ {
+			heatmap.Columns = append(heatmap.Columns, fmt.Sprintf("%02d", hour))
+		}
+	} else {
+		seen := map[string]bool{}
+		for _, cell := range cells {
+			if !seen[cell.Column] {
+				seen[cell.Column] = true
+				heatmap.Columns = append(heatmap.Columns, cell.Column)
+			}
+		}
+		sort.Strings(heatmap.Columns)
+	}
+	for i, column := range heatmap.Columns {
+		positions[column] = i
+	}
+	rows := map[string]*HeatmapRow{}
+	for _, cell := range cells {
+		i, ok := positions[cell.Column]
+		if !ok {
+			continue
+		}
+		row := rows[cell.Row]
+		if row == nil {
+			row = &HeatmapRow{Name: cell.Row, Values: make([]int, len(heatmap.Columns))}
+			rows[cell.Row] = row
+		}
+		value := cell.Count
+		if metric == HEATMAP_MILLIS {
+			value = cell.TotalMilli
+		}
+		row.Values[i] += value
+		row.Total += value
+	}
+	for _, row := range rows {
+		heatmap.Rows = append(heatmap.Rows, *row)
+	}
+	sort.Slice(heatmap.Rows, func(i, j int) bool {
+		if layout != HEATMAP_BY_DAY && heatmap.Rows[i].Total != heatmap.Rows[j].Total {
+			return heatmap.Rows[i].Total > heatmap.Rows[j].Total
+		}
+		return heatmap.Rows[i].Name < heatmap.Rows[j].Name
+	})
+	if layout != HEATMAP_BY_DAY && len(heatmap.Rows) > HEATMAP_MAX_ROWS {
+		heatmap.Rows = heatmap.Rows[:HEATMAP_MAX_ROWS]
+	}
+	for _, row := range heatmap.Rows {
+		for _, value := range row.Values {
+			if value > heatmap.Max {
+				heatmap.Max = value
+			}
+		}
+	}
+	return heatmap
+}
+
+// GetHeatColor returns the background color of a value, from transparent to
+// the hottest of the max
+func GetHeatColor(value int, max int) string {
+	if value == 0 || max == 0 {
+		return "transparent"
+	}
+	return fmt.Sprintf("rgba(230, 81, 0, %.2f)", 0.1+0.9*float64(value)/float64(max))
+}
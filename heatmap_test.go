@@ -0,0 +1,45 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * heatmap_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestGetHeatmap(t *testing.T) {
+	cells := []HeatmapCell{
+		{Row: "shop.orders", Column: "2023-01-01T02", Count: 10, TotalMilli: 5000},
+		{Row: "shop.orders", Column: "2023-01-01T03", Count: 2, TotalMilli: 400},
+		{Row: "shop.users", Column: "2023-01-01T03", Count: 5, TotalMilli: 6000},
+	}
+	heatmap := GetHeatmap(cells, HEATMAP_BY_NS, HEATMAP_COUNT)
+	if len(heatmap.Columns) != 2 || len(heatmap.Rows) != 2 || heatmap.Max != 10 {
+		t.Fatal("expected", 2, 2, 10, "but got", len(heatmap.Columns), len(heatmap.Rows), heatmap.Max)
+	}
+	if heatmap.Rows[0].Name != "shop.orders" || heatmap.Rows[0].Total != 12 || heatmap.Rows[1].Values[0] != 0 {
+		t.Fatal("expected", "shop.orders", 12, 0, "but got", heatmap.Rows[0].Name, heatmap.Rows[0].Total, heatmap.Rows[1].Values[0])
+	}
+	heatmap = GetHeatmap(cells, HEATMAP_BY_NS, HEATMAP_MILLIS)
+	if heatmap.Rows[0].Name != "shop.users" || heatmap.Max != 6000 {
+		t.Fatal("expected", "shop.users", 6000, "but got", heatmap.Rows[0].Name, heatmap.Max)
+	}
+
+	cells = []HeatmapCell{
+		{Row: "2023-01-02", Column: "02", Count: 7},
+		{Row: "2023-01-01", Column: "02", Count: 3},
+		{Row: "2023-01-01", Column: "23", Count: 1},
+	}
+	heatmap = GetHeatmap(cells, HEATMAP_BY_DAY, HEATMAP_COUNT)
+	if len(heatmap.Columns) != 24 || heatmap.Rows[0].Name != "2023-01-01" || heatmap.Rows[0].Values[23] != 1 {
+		t.Fatal("expected", 24, "2023-01-01", 1, "but got", len(heatmap.Columns), heatmap.Rows[0].Name, heatmap.Rows[0].Values[23])
+	}
+	if color := GetHeatColor(0, heatmap.Max); color != "transparent" {
+		t.Fatal("expected", "transparent", "but got", color)
+	}
+	if color := GetHeatColor(7, heatmap.Max); color != "rgba(230, 81, 0, 1.00)" {
+		t.Fatal("expected", "rgba(230, 81, 0, 1.00)", "but got", color)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * hot_docs.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const HOT_DOC_CAPACITY = 1000
+
+// HotDoc stores writes counted against a single document
+type HotDoc struct {
+	Count          int    `json:"count" bson:"count"`
+	Key            string `json:"key" bson:"key"` // namespace and _id
+	WriteConflicts int    `json:"write_conflicts" bson:"write_conflicts"`
+}
+
+// HotDocCounter keeps approximate top-K write counts using the space-saving
+// algorithm so that memory stays bounded regardless of the number of documents
+type HotDocCounter struct {
+	capacity int
+	docs     map[string]*HotDoc
+}
+
+// NewHotDocCounter returns HotDocCounter
+func NewHotDocCounter(capacity int) *HotDocCounter {
+	return &HotDocCounter{capacity: capacity, docs: map[string]*HotDoc{}}
+}
+
+// Add counts a write to a document
+func (ptr *HotDocCounter) Add(key string, writeConflicts int) {
+	if doc, ok := ptr.docs[key]; ok {
+		doc.Count++
+		doc.WriteConflicts += writeConflicts
+		return
+	}
+	if len(ptr.docs) < ptr.capacity {
+		ptr.docs[key] = &HotDoc{Count: 1, Key: key, WriteConflicts: writeConflicts}
+		return
+	}
+	var min *HotDoc
+	for _, doc := range ptr.docs {
+		if min == nil || doc.Count < min.Count {
+			min = doc
+		}
+	}
+	delete(ptr.docs, min.Key)
+	ptr.docs[key] = &HotDoc{Count: min.Count + 1, Key: key, WriteConflicts: writeConflicts}
+}
+
+// GetHotDocs returns documents written at least threshold times, hottest first
+func (ptr *HotDocCounter) GetHotDocs(threshold int, limit int) []HotDoc {
+	docs := []HotDoc{}
+	for _, doc := range ptr.docs {
+		if doc.Count >= threshold {
+			docs = append(docs, *doc)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].Count == docs[j].Count {
+			return docs[i].Key < docs[j].Key
+		}
+		return docs[i].Count > docs[j].Count
+	})
+	if limit > 0 && len(docs) > limit {
+		docs = docs[:limit]
+	}
+	return docs
+}
+
+// GetHotDocKey returns namespace and _id of a write op targeting a single
+// document by an _id equality
+func GetHotDocKey(doc *Logv2Info) (string, bool) {
+	attrMap := doc.Attr.Map()
+	ns, _ := attrMap["ns"].(string)
+	command, ok := attrMap["command"].(bson.D)
+	if !ok || ns == "" || strings.HasSuffix(ns, ".$cmd") {
+		return "", false
+	}
+	cmdMap := command.Map()
+	var query interface{}
+	switch attrMap["type"] {
+	case cmdUpdate, cmdRemove, cmdDelete:
+		query = cmdMap["q"]
+	default:
+		if cmdMap["findAndModify"] == nil && cmdMap[cmdFindAndModify] == nil {
+			return "", false
+		}
+		query = cmdMap["query"]
+	}
+	filter, ok := query.(bson.D)
+	if !ok {
+		return "", false
+	}
+	value, ok := filter.Map()["_id"]
+	if !ok {
+		return "", false
+	}
+	if d, ok := value.(bson.D); ok {
+		if len(d) != 1 || d[0].Key != "$eq" {
+			return "", false
+		}
+		value = d[0].Value
+	}
+	var id string
+	switch v := value.(type) {
+	case primitive.ObjectID:
+		id = fmt.Sprintf("ObjectId('%v')", v.Hex())
+	case string:
+		if v == "###" || v == "" { // redacted
+			return "", false
+		}
+		id = fmt.Sprintf(`"%v"`, v)
+	case int32, int64, float64:
+		id = fmt.Sprintf("%v", v)
+	default:
+		return "", false
+	}
+	return fmt.Sprintf("%v { _id: %v }", ns, id), true
+}
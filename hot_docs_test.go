@@ -0,0 +1,61 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * hot_docs_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestHotDocCounter(t *testing.T) {
+	counter := NewHotDocCounter(2)
+	for i := 0; i < 5; i++ {
+		counter.Add("db.orders { _id: 1 }", 1)
+	}
+	counter.Add("db.orders { _id: 2 }", 0)
+	counter.Add("db.orders { _id: 3 }", 0) // evicts _id: 2
+	docs := counter.GetHotDocs(1, 0)
+	if len(docs) != 2 {
+		t.Fatal("expected", 2, "but got", len(docs))
+	}
+	if docs[0].Key != "db.orders { _id: 1 }" || docs[0].Count != 5 || docs[0].WriteConflicts != 5 {
+		t.Fatal("expected", "db.orders { _id: 1 }", "but got", docs[0])
+	}
+	if docs[1].Key != "db.orders { _id: 3 }" || docs[1].Count != 2 {
+		t.Fatal("expected", "db.orders { _id: 3 }", "but got", docs[1])
+	}
+	if docs = counter.GetHotDocs(3, 0); len(docs) != 1 {
+		t.Fatal("expected", 1, "but got", len(docs))
+	}
+}
+
+func TestGetHotDocKey(t *testing.T) {
+	str := `{"t":{"$date":"2021-07-25T09:56:00.691+00:00"},"s":"I","c":"WRITE","id":51803,"ctx":"conn12","msg":"Slow query","attr":{"type":"update","ns":"demo.orders","command":{"q":{"_id":{"$oid":"60fd0b8a5d3b4e2f8c6f1a2b"}},"u":{"$inc":{"qty":1}},"multi":false,"upsert":false},"planSummary":"IDHACK","writeConflicts":3,"durationMillis":105}}`
+	doc := Logv2Info{}
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	AnalyzeSlowOp(&doc)
+	key, ok := GetHotDocKey(&doc)
+	expected := "demo.orders { _id: ObjectId('60fd0b8a5d3b4e2f8c6f1a2b') }"
+	if !ok || key != expected {
+		t.Fatal("expected", expected, "but got", key)
+	}
+	if doc.Attributes.WriteConflicts != 3 {
+		t.Fatal("expected", 3, "but got", doc.Attributes.WriteConflicts)
+	}
+
+	str = `{"t":{"$date":"2021-07-25T09:56:00.691+00:00"},"s":"I","c":"WRITE","id":51803,"ctx":"conn12","msg":"Slow query","attr":{"type":"update","ns":"demo.orders","command":{"q":{"_id":{"$in":[1,2]}},"u":{"$inc":{"qty":1}}},"durationMillis":105}}`
+	doc = Logv2Info{}
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	AnalyzeSlowOp(&doc)
+	if key, ok = GetHotDocKey(&doc); ok {
+		t.Fatal("expected no key but got", key)
+	}
+}
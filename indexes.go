@@ -0,0 +1,266 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * indexes.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var indexFieldRegex = regexp.MustCompile(`"?([^\s"{},:]+)"?:`)
+
+// IndexSuggestion is a suggested index of a slow query shape, fields are in
+// the equality, sort, and range (ESR) order
+type IndexSuggestion struct {
+	Count        int    `json:"count"`
+	Index        string `json:"index"` // suggested index
+	Namespace    string `json:"ns"`
+	Op           string `json:"op"`
+	Plan         string `json:"plan"` // index used
+	QueryPattern string `json:"query_pattern"`
+	Reason       string `json:"reason"`
+	SortPattern  string `json:"sort_pattern"`
+	TotalMilli   int    `json:"total_ms"`
+}
+
+// indexField is a field of a query pattern, an equality or a range
+type indexField struct {
+	name     string
+	equality bool
+}
+
+// GetCreateIndex returns a mongosh createIndex command of the suggestion
+func (ptr *IndexSuggestion) GetCreateIndex() string {
+	n := strings.Index(ptr.Namespace, ".")
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(`db.getSiblingDB(%q).getCollection(%q).createIndex(%v)`,
+		ptr.Namespace[:n], ptr.Namespace[n+1:], GetExplainFilter(ptr.Index))
+}
+
+// GetIndexSuggestions returns suggested indexes of slow query shapes with a
+// COLLSCAN or an index missing fields of the filter or sort, the most total
+// milliseconds first
+func GetIndexSuggestions(ops []OpStat) []IndexSuggestion {
+	suggestions := []IndexSuggestion{}
+	positions := map[string]int{}
+	for _, op := range ops {
+		if op.QueryPattern == "" || strings.HasSuffix(op.Namespace, ".$cmd") {
+			continue
+		}
+		for _, index := range GetSuggestedIndexes(op.QueryPattern, op.SortPattern) {
+			reason := getIndexReason(op.Index, index)
+			if reason == "" {
+				continue
+			}
+			key := strings.Join([]string{op.Op, op.Namespace, op.QueryPattern, op.SortPattern, index}, "\t")
+			if i, ok := positions[key]; ok {
+				suggestions[i].Count += op.Count
+				suggestions[i].TotalMilli += op.TotalMilli
+				continue
+			}
+			positions[key] = len(suggestions)
+			suggestions = append(suggestions, IndexSuggestion{Count: op.Count, Index: index, Namespace: op.Namespace,
+				Op: op.Op, Plan: op.Index, QueryPattern: op.QueryPattern, Reason: reason, SortPattern: op.SortPattern,
+				TotalMilli: op.TotalMilli})
+		}
+	}
+	sort.SliceStable(suggestions, func(i int, j int) bool {
+		return suggestions[i].TotalMilli > suggestions[j].TotalMilli
+	})
+	return suggestions
+}
+
+// GetSuggestedIndexes returns ESR ordered indexes of a query pattern and a
+// sort pattern, one for each branch of $or
+func GetSuggestedIndexes(queryPattern string, sortPattern string) []string {
+	indexes := []string{}
+	query := &patternNode{isObj: true}
+	if queryPattern != "" && queryPattern != "{}" {
+		parser := &patternParser{str: queryPattern}
+		node, err := parser.parseValue()
+		if err != nil || !node.isObj {
+			return indexes
+		}
+		query = node
+	}
+	sorts := getSortFields(sortPattern)
+	existing := map[string]bool{}
+	for _, fields := range getFilterFields(query) {
+		keys := []string{}
+		used := map[string]bool{}
+		for _, field := range fields {
+			if field.equality && !used[field.name] {
+				keys = append(keys, " "+field.name+":1")
+				used[field.name] = true
+			}
+		}
+		for _, field := range sorts {
+			if !used[field.name] {
+				keys = append(keys, " "+field.name+":"+field.direction)
+				used[field.name] = true
+			}
+		}
+		for _, field := range fields {
+			if !used[field.name] {
+				keys = append(keys, " "+field.name+":1")
+				used[field.name] = true
+			}
+		}
+		if len(keys) == 0 || (len(keys) == 1 && keys[0] == " _id:1") {
+			continue
+		}
+		index := "{" + strings.Join(keys, ",") + " }"
+		if !existing[index] {
+			indexes = append(indexes, index)
+			existing[index] = true
+		}
+	}
+	return indexes
+}
+
+// getFilterFields returns fields of a filter, one list for each branch of $or
+func getFilterFields(node *patternNode) [][]indexField {
+	alternatives := [][]indexField{{}}
+	for i, key := range node.keys {
+		value := node.values[i]
+		if key == "$and" {
+			for _, branch := range value.values {
+				if branch.isObj {
+					alternatives = crossFields(alternatives, getFilterFields(branch))
+				}
+			}
+			continue
+		} else if key == "$or" {
+			branches := [][]indexField{}
+			for _, branch := range value.values {
+				if branch.isObj {
+					branches = append(branches, getFilterFields(branch)...)
+				}
+			}
+			if len(branches) > 0 {
+				alternatives = crossFields(alternatives, branches)
+			}
+			continue
+		} else if strings.HasPrefix(key, "$") {
+			continue
+		}
+		field := indexField{name: strings.Trim(key, `"`), equality: isEqualityPattern(value)}
+		for j := range alternatives {
+			alternatives[j] = append(alternatives[j], field)
+		}
+	}
+	for i, fields := range alternatives {
+		alternatives[i] = mergeFields(fields)
+	}
+	return alternatives
+}
+
+// crossFields returns every combination of fields of two lists of branches
+func crossFields(a [][]indexField, b [][]indexField) [][]indexField {
+	crossed := [][]indexField{}
+	for _, x := range a {
+		for _, y := range b {
+			fields := append(append([]indexField{}, x...), y...)
+			crossed = append(crossed, fields)
+		}
+	}
+	return crossed
+}
+
+// mergeFields returns fields sorted by name, a field is an equality if any of
+// its conditions is an equality
+func mergeFields(fields []indexField) []indexField {
+	merged := []indexField{}
+	positions := map[string]int{}
+	for _, field := range fields {
+		if i, ok := positions[field.name]; ok {
+			merged[i].equality = merged[i].equality || field.equality
+			continue
+		}
+		positions[field.name] = len(merged)
+		merged = append(merged, field)
+	}
+	sort.SliceStable(merged, func(i int, j int) bool {
+		return merged[i].name < merged[j].name
+	})
+	return merged
+}
+
+// isEqualityPattern returns true if the value of a field is a value, an
+// embedded document, $eq, $in, or $elemMatch
+func isEqualityPattern(value *patternNode) bool {
+	if value.isArr {
+		return true
+	} else if !value.isObj {
+		return !strings.HasPrefix(value.text, "/")
+	} else if isFieldDoc(value) {
+		return true
+	}
+	for _, key := range value.keys {
+		if key != "$eq" && key != "$in" && key != "$elemMatch" {
+			return false
+		}
+	}
+	return len(value.keys) > 0
+}
+
+type sortField struct {
+	name      string
+	direction string
+}
+
+// getSortFields returns fields of a sort pattern in order, $meta sorts are
+// skipped
+func getSortFields(sortPattern string) []sortField {
+	fields := []sortField{}
+	if sortPattern == "" {
+		return fields
+	}
+	parser := &patternParser{str: sortPattern}
+	node, err := parser.parseValue()
+	if err != nil || !node.isObj {
+		return fields
+	}
+	for i, key := range node.keys {
+		if node.values[i].isObj {
+			continue
+		}
+		direction := "1"
+		if node.values[i].text == "-1" {
+			direction = "-1"
+		}
+		fields = append(fields, sortField{name: strings.Trim(key, `"`), direction: direction})
+	}
+	return fields
+}
+
+// getIndexReason returns why an index is suggested for a plan, empty if the
+// plan uses an index of all fields of the suggestion
+func getIndexReason(plan string, index string) string {
+	if plan == COLLSCAN {
+		return COLLSCAN
+	} else if !strings.HasPrefix(plan, "{") {
+		return ""
+	}
+	used := map[string]bool{}
+	for _, match := range indexFieldRegex.FindAllStringSubmatch(plan, -1) {
+		used[match[1]] = true
+	}
+	missing := []string{}
+	for _, match := range indexFieldRegex.FindAllStringSubmatch(index, -1) {
+		if !used[match[1]] {
+			missing = append(missing, match[1])
+		}
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+	return "index misses " + strings.Join(missing, ", ")
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * indexes_template.go
+ */
+
+package hatchet
+
+import (
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetIndexesTemplate returns HTML
+func GetIndexesTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Suggestions}}
+	<table width='100%'>
+		<caption>Index Suggestions by Shape</caption>
+		<tr><th>#</th><th>op</th><th>namespace</th><th>count</th><th>total ms</th><th>index used</th>
+			<th>query pattern</th><th>sort</th><th>suggested index</th></tr>
+	{{range $i, $s := .Suggestions}}
+		<tr><td align='right'>{{add $i 1}}</td><td>{{$s.Op}}</td><td>{{$s.Namespace}}</td>
+			<td align='right'>{{numPrinter $s.Count}}</td>
+			<td align='right'>{{numPrinter $s.TotalMilli}}</td>
+			{{if eq $s.Plan "COLLSCAN"}}
+			<td style='color: red;'>{{$s.Plan}}</td>
+			{{else}}
+			<td class='break' title='{{$s.Reason}}'>{{$s.Plan}}</td>
+			{{end}}
+			<td class='break'>{{$s.QueryPattern}}</td>
+			<td class='break'>{{$s.SortPattern}}</td>
+			<td class='break' title='{{$s.GetCreateIndex}}'>{{$s.Index}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<div>Fields of suggested indexes are in the equality, sort, and range (ESR) order.  Verify a suggestion with
+		explain() and existing indexes before creating it, an index of the same prefix may already exist.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: green'>no index suggestions</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"add": func(a int, b int) int {
+			return a + b
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * indexes_test.go
+ */
+
+package hatchet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetSuggestedIndexes(t *testing.T) {
+	tests := []struct {
+		query    string
+		sort     string
+		expected string
+	}{
+		{`{ qty:{ $gt:1 }, status:1 }`, `{ createdAt:-1 }`, `{ status:1, createdAt:-1, qty:1 }`},
+		{`{ customerId:1, status:{ $in:[...] } }`, "", `{ customerId:1, status:1 }`},
+		{`{ name:/^.../i, "address.city":1 }`, "", `{ address.city:1, name:1 }`},
+		{`{ $and:[{ a:{ $lt:1 } },{ a:{ $gte:1 } },{ b:1 }] }`, "", `{ b:1, a:1 }`},
+		{`{ $or:[{ a:1 },{ b:{ $gt:1 } }], c:1 }`, `{ d:1 }`, `{ a:1, c:1, d:1 }|{ c:1, d:1, b:1 }`},
+		{`{}`, `{ score:{ $meta:1 }, ts:1 }`, `{ ts:1 }`},
+		{`{ _id:1 }`, "", ""},
+	}
+	for _, test := range tests {
+		indexes := strings.Join(GetSuggestedIndexes(test.query, test.sort), "|")
+		if indexes != test.expected {
+			t.Fatal("expected", test.expected, "but got", indexes)
+		}
+	}
+}
+
+func TestGetIndexSuggestions(t *testing.T) {
+	ops := []OpStat{
+		{Op: "find", Namespace: "shop.orders", Index: COLLSCAN, QueryPattern: `{ status:1 }`, Count: 2, TotalMilli: 100},
+		{Op: "find", Namespace: "shop.orders", Index: "{ status:1 }", QueryPattern: `{ qty:{ $gt:1 }, status:1 }`,
+			SortPattern: `{ ts:1 }`, Count: 3, TotalMilli: 300},
+		{Op: "find", Namespace: "shop.orders", Index: "{ status:1, qty:1 }", QueryPattern: `{ qty:{ $gt:1 }, status:1 }`,
+			Count: 1, TotalMilli: 50},
+		{Op: "update", Namespace: "shop.orders", Index: "IDHACK", QueryPattern: `{ _id:1 }`, Count: 1, TotalMilli: 500},
+	}
+	suggestions := GetIndexSuggestions(ops)
+	if len(suggestions) != 2 {
+		t.Fatal("expected", 2, "but got", len(suggestions))
+	}
+	if suggestions[0].Index != `{ status:1, ts:1, qty:1 }` || suggestions[0].Reason != "index misses ts, qty" {
+		t.Fatal("expected", `{ status:1, ts:1, qty:1 }`, "but got", suggestions[0].Index, suggestions[0].Reason)
+	}
+	if suggestions[1].Reason != COLLSCAN {
+		t.Fatal("expected", COLLSCAN, "but got", suggestions[1].Reason)
+	}
+	expected := `db.getSiblingDB("shop").getCollection("orders").createIndex({ "status":1 })`
+	if cmd := suggestions[1].GetCreateIndex(); cmd != expected {
+		t.Fatal("expected", expected, "but got", cmd)
+	}
+}
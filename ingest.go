@@ -0,0 +1,75 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * ingest.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const (
+	INGESTS_TABLE = "hatchet_ingests"
+	MAX_HEAD_SIZE = 256 // bytes of the first line kept to tell a rotated log
+)
+
+// Ingest is how far a log file was ingested into a hatchet, lines appended
+// later are ingested from the offset with -incremental
+type Ingest struct {
+	End     string `bson:"end"`     // timestamp of the last line
+	Head    string `bson:"head"`    // beginning of the first line
+	Lines   int    `bson:"lines"`   // lines ingested
+	Logname string `bson:"logname"` // absolute path of the log
+	Name    string `bson:"_id"`     // hatchet name
+	Offset  int64  `bson:"offset"`  // bytes ingested
+	Start   string `bson:"start"`   // timestamp of the first line
+	Updated string `bson:"updated"`
+}
+
+// getLogHead returns the beginning of the first line of a log
+func getLogHead(line string) string {
+	if len(line) > MAX_HEAD_SIZE {
+		return line[:MAX_HEAD_SIZE]
+	}
+	return line
+}
+
+// findIngest returns the last ingest of a plain log file if the log has the
+// same first line and is not shorter, nil otherwise
+func (ptr *Logv2) findIngest(logname string) (*Ingest, error) {
+	info, err := os.Stat(logname)
+	if err != nil || !info.Mode().IsRegular() || IsCompressedLog(logname) || ptr.s3client != nil {
+		return nil, nil
+	}
+	if logname, err = filepath.Abs(logname); err != nil {
+		return nil, err
+	}
+	dbase, err := GetDatabase("")
+	if err != nil {
+		return nil, err
+	}
+	defer dbase.Close()
+	doc, err := dbase.GetIngest(logname)
+	if err != nil || doc.Name == "" {
+		return nil, err
+	}
+	if info.Size() < doc.Offset {
+		log.Println(logname, "is shorter than ingested into", doc.Name+", ingesting as a new hatchet")
+		return nil, nil
+	}
+	file, err := os.Open(logname)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	line, _, _ := bufio.NewReaderSize(file, MAX_HEAD_SIZE+16).ReadLine()
+	if getLogHead(string(line)) != doc.Head {
+		log.Println(logname, "was rotated since ingested into", doc.Name+", ingesting as a new hatchet")
+		return nil, nil
+	}
+	return &doc, nil
+}
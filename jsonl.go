@@ -0,0 +1,105 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * jsonl.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// JSONLDoc is a parsed log document with the fields hatchet extracts
+type JSONLDoc struct {
+	Attr        json.RawMessage `json:"attr,omitempty"` // relaxed extended JSON
+	Component   string          `json:"c"`
+	Context     string          `json:"ctx"`
+	Filter      string          `json:"filter,omitempty"`
+	ID          int             `json:"id"`
+	Index       string          `json:"index,omitempty"`
+	Line        int             `json:"line"`
+	Milli       int             `json:"durationMillis,omitempty"`
+	Msg         string          `json:"msg"`
+	NS          string          `json:"ns,omitempty"`
+	Op          string          `json:"op,omitempty"`
+	PlanSummary string          `json:"planSummary,omitempty"`
+	Remote      string          `json:"remote,omitempty"`
+	Reslen      int             `json:"reslen,omitempty"`
+	Severity    string          `json:"s"`
+	Sort        string          `json:"sort,omitempty"`
+	Source      string          `json:"source,omitempty"`
+	Timestamp   string          `json:"t"`
+	Type        string          `json:"type,omitempty"`
+}
+
+// JSONLWriter writes parsed log documents to a file, a JSON document a line
+type JSONLWriter struct {
+	Count  int
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// NewJSONLWriter returns JSONLWriter writing to a file
+func NewJSONLWriter(filename string) (*JSONLWriter, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLWriter{file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+// Add writes a parsed document of a line
+func (ptr *JSONLWriter) Add(index int, end string, doc *Logv2Info, stat *OpStat) error {
+	b, err := json.Marshal(GetJSONLDoc(index, end, doc, stat))
+	if err != nil {
+		return err
+	}
+	ptr.Count++
+	b = append(b, '\n')
+	_, err = ptr.writer.Write(b)
+	return err
+}
+
+// Flush writes buffered documents to the file
+func (ptr *JSONLWriter) Flush() error {
+	return ptr.writer.Flush()
+}
+
+// Close flushes and closes the file
+func (ptr *JSONLWriter) Close() error {
+	if err := ptr.writer.Flush(); err != nil {
+		ptr.file.Close()
+		return err
+	}
+	return ptr.file.Close()
+}
+
+// GetJSONLDoc returns the normalized document of a line
+func GetJSONLDoc(index int, end string, doc *Logv2Info, stat *OpStat) JSONLDoc {
+	jdoc := JSONLDoc{Component: doc.Component, Context: doc.Context, ID: doc.ID, Line: index,
+		Milli: doc.Attributes.Milli, Msg: doc.Msg, NS: doc.Attributes.NS, PlanSummary: doc.Attributes.PlanSummary,
+		Reslen: doc.Attributes.Reslen, Severity: doc.Severity, Source: doc.Source, Timestamp: end,
+		Type: doc.Attributes.Type}
+	if len(doc.Attr) > 0 {
+		if b, err := bson.MarshalExtJSON(doc.Attr, false, false); err == nil {
+			jdoc.Attr = b
+		}
+	}
+	if stat != nil {
+		jdoc.Filter = stat.QueryPattern
+		jdoc.Index = stat.Index
+		jdoc.Op = stat.Op
+		jdoc.Sort = stat.SortPattern
+	}
+	if remote, ok := doc.Attr.Map()["remote"].(string); ok {
+		jdoc.Remote = remote
+	} else if doc.Client != nil && doc.Client.IP != "" {
+		jdoc.Remote = fmt.Sprintf("%v:%v", doc.Client.IP, doc.Client.Port)
+	}
+	return jdoc
+}
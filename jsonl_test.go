@@ -0,0 +1,43 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * jsonl_test.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGetJSONLDoc(t *testing.T) {
+	line := `{"t":{"$date":"2023-01-01T00:00:01.330+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn108","msg":"Slow query",` +
+		`"attr":{"type":"command","ns":"shop.products","command":{"find":"products","filter":{"sku":"A"},"$db":"shop"},` +
+		`"planSummary":"IXSCAN { sku: 1 }","reslen":100,"remote":"10.0.0.1:5000","durationMillis":150}}`
+	doc := Logv2Info{}
+	if err := UnmarshalLogv2([]byte(line), nil, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddLegacyString(&doc); err != nil {
+		t.Fatal(err)
+	}
+	stat, _ := AnalyzeSlowOp(&doc)
+	b, err := json.Marshal(GetJSONLDoc(3, "2023-01-01T00:00:01.330-0000", &doc, stat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jdoc map[string]interface{}
+	if err = json.Unmarshal(b, &jdoc); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"line": 3.0, "ns": "shop.products", "op": "find", "durationMillis": 150.0,
+		"index": "{ sku:1 }", "remote": "10.0.0.1:5000", "t": "2023-01-01T00:00:01.330-0000", "reslen": 100.0}
+	for key, value := range expected {
+		if jdoc[key] != value {
+			t.Fatal("expected", value, "of", key, "but got", jdoc[key])
+		}
+	}
+	if attr, ok := jdoc["attr"].(map[string]interface{}); !ok || attr["planSummary"] != "IXSCAN { sku: 1 }" {
+		t.Fatal("expected", "attr with planSummary", "but got", jdoc["attr"])
+	}
+}
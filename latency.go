@@ -0,0 +1,107 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * latency.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// LATENCY_PERCENTILES are percentiles of slow op durations of op shapes and
+// of namespaces and ops
+var LATENCY_PERCENTILES = []int{50, 95, 99}
+
+// GetSQLRankedOps returns a subquery of slow ops of a table ranked by milli
+// within groups of columns, rn is the rank and cnt the size of a group
+func GetSQLRankedOps(table string, columns string) string {
+	return fmt.Sprintf(`(SELECT *, ROW_NUMBER() OVER (PARTITION BY %v ORDER BY milli) rn,
+		COUNT(*) OVER (PARTITION BY %v) cnt FROM %v WHERE op != "")`, columns, columns, table)
+}
+
+// GetSQLPercentiles returns aggregates of the nearest-rank percentiles of
+// LATENCY_PERCENTILES of ranked ops, e.g. p50_ms
+func GetSQLPercentiles() string {
+	exprs := []string{}
+	for _, p := range LATENCY_PERCENTILES {
+		exprs = append(exprs, fmt.Sprintf("MIN(CASE WHEN rn*100 >= cnt*%d THEN milli END) p%d_ms", p, p))
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// GetSQLLatencyAudit returns a statement inserting counts, percentiles, and
+// max ms of slow ops by op and namespace of a table into its audit table
+func GetSQLLatencyAudit(table string) string {
+	selects := []string{"SELECT 'latency', name, count FROM p"}
+	for _, p := range LATENCY_PERCENTILES {
+		selects = append(selects, fmt.Sprintf("SELECT 'latency-p%d', name, p%d_ms FROM p", p, p))
+	}
+	selects = append(selects, "SELECT 'latency-max', name, max_ms FROM p")
+	return fmt.Sprintf(`WITH p AS (SELECT op || ' ' || ns name, COUNT(*) count, %v, MAX(milli) max_ms
+		FROM %v GROUP BY op, ns)
+		INSERT INTO %v_audit %v;`, GetSQLPercentiles(), GetSQLRankedOps(table, "op, ns"), table,
+		strings.Join(selects, " UNION ALL "))
+}
+
+// GetMongoLatencyStages returns stages grouping slow ops by fields of keys,
+// e.g. {"op": "$op"}, into count, total_ms, max_ms, accumulators of $sum,
+// $min, or $max of extra, and p{N}_ms of LATENCY_PERCENTILES.  Percentiles
+// are of $percentile of MongoDB 7.0 or later, or else of counts of distinct
+// durations of a group, not of an array of all its durations that may exceed
+// the document size limit
+func GetMongoLatencyStages(keys bson.M, extra bson.M, percentile bool) []bson.M {
+	set := bson.M{}
+	if percentile {
+		ps := bson.A{}
+		for i, p := range LATENCY_PERCENTILES {
+			ps = append(ps, float64(p)/100)
+			set[fmt.Sprintf("p%d_ms", p)] = bson.M{"$round": bson.A{bson.M{"$arrayElemAt": bson.A{"$percentiles", i}}, 0}}
+		}
+		group := bson.M{"_id": keys, "count": bson.M{"$sum": 1}, "total_ms": bson.M{"$sum": "$milli"},
+			"max_ms":      bson.M{"$max": "$milli"},
+			"percentiles": bson.M{"$percentile": bson.M{"input": "$milli", "p": ps, "method": "approximate"}}}
+		for field, acc := range extra {
+			group[field] = acc
+		}
+		return []bson.M{{"$group": group}, {"$set": set}, {"$unset": "percentiles"}}
+	}
+	ids := bson.M{"milli": "$milli"}
+	regroupIDs := bson.M{}
+	for field, value := range keys {
+		ids[field] = value
+		regroupIDs[field] = "$_id." + field
+	}
+	group := bson.M{"_id": ids, "count": bson.M{"$sum": 1}}
+	regroup := bson.M{"_id": regroupIDs, "count": bson.M{"$sum": "$count"},
+		"total_ms": bson.M{"$sum": bson.M{"$multiply": bson.A{"$_id.milli", "$count"}}},
+		"max_ms":   bson.M{"$max": "$_id.milli"},
+		"millis":   bson.M{"$push": bson.M{"m": "$_id.milli", "c": "$count"}}}
+	for field, acc := range extra {
+		group[field] = acc
+		for op := range acc.(bson.M) {
+			regroup[field] = bson.M{op: "$" + field}
+		}
+	}
+	for _, p := range LATENCY_PERCENTILES {
+		set[fmt.Sprintf("p%d_ms", p)] = GetMongoPercentile("$millis", "$count", p)
+	}
+	return []bson.M{{"$group": group}, {"$sort": bson.M{"_id.milli": 1}}, {"$group": regroup}, {"$set": set},
+		{"$unset": "millis"}}
+}
+
+// GetMongoPercentile returns an expression of the nearest-rank percentile p
+// of an array of {m: duration, c: count} sorted by durations of total counts
+func GetMongoPercentile(counts string, total string, p int) bson.M {
+	rank := bson.M{"$trunc": bson.M{"$divide": bson.A{
+		bson.M{"$add": bson.A{bson.M{"$multiply": bson.A{total, p}}, 99}}, 100}}}
+	n := bson.M{"$add": bson.A{"$$value.n", "$$this.c"}}
+	reduce := bson.M{"$reduce": bson.M{"input": counts, "initialValue": bson.M{"n": 0, "v": nil},
+		"in": bson.M{"n": n, "v": bson.M{"$cond": bson.A{
+			bson.M{"$and": bson.A{bson.M{"$eq": bson.A{"$$value.v", nil}}, bson.M{"$gte": bson.A{n, rank}}}},
+			"$$this.m", "$$value.v"}}}}}
+	return bson.M{"$let": bson.M{"vars": bson.M{"r": reduce}, "in": "$$r.v"}}
+}
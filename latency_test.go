@@ -0,0 +1,86 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * latency_test.go
+ */
+
+package hatchet
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSQLite3LatencyPercentiles(t *testing.T) {
+	RegisterSQLite3Extended()
+	dbfile := filepath.Join(t.TempDir(), "latency.db")
+	db, err := sql.Open("sqlite3_extended", dbfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbase := &SQLite3DB{db: db, dbfile: dbfile, hatchetName: "latency_test"}
+	defer dbase.Close()
+	if _, err = db.Exec(GetHatchetInitStmt(dbase.hatchetName)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 100; i++ {
+		if _, err = db.Exec(`INSERT INTO latency_test (date, severity, component, context, msg, message, op, ns, filter, sort, _index, milli, reslen)
+			VALUES ('2023-01-01T00:00:00.000-0000', 'I', 'COMMAND', 'conn1', 'Slow query', '', 'find', 'shop.orders', '{a:1}', '', 'COLLSCAN', ?, 0)`, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = dbase.CreateMetaData(); err != nil {
+		t.Fatal(err)
+	}
+	ops, err := dbase.GetSlowOps("avg_ms", "DESC", false)
+	if err != nil || len(ops) != 1 {
+		t.Fatal("expected", 1, "but got", len(ops), err)
+	}
+	if ops[0].P50Milli != 50 || ops[0].P95Milli != 95 || ops[0].P99Milli != 99 {
+		t.Fatal("expected", 50, 95, 99, "but got", ops[0].P50Milli, ops[0].P95Milli, ops[0].P99Milli)
+	}
+	data, err := dbase.GetAuditData()
+	if err != nil || len(data["latency"]) != 1 {
+		t.Fatal("expected", 1, "but got", len(data["latency"]), err)
+	}
+	doc := data["latency"][0]
+	expected := []interface{}{100, 50, 95, 99, 100}
+	for i, value := range expected {
+		if doc.Name != "find shop.orders" || doc.Values[i] != value {
+			t.Fatal("expected", "find shop.orders", expected, "but got", doc.Name, doc.Values)
+		}
+	}
+}
+
+func TestGetMongoLatencyStages(t *testing.T) {
+	keys := bson.M{"op": "$op", "ns": "$ns"}
+	extra := bson.M{"reslen": bson.M{"$sum": "$reslen"}}
+	stages := GetMongoLatencyStages(keys, extra, true)
+	group := stages[0]["$group"].(bson.M)
+	p := group["percentiles"].(bson.M)["$percentile"].(bson.M)["p"]
+	if fmt.Sprint(p) != "[0.5 0.95 0.99]" || group["reslen"] == nil {
+		t.Fatal("expected", "[0.5 0.95 0.99]", "but got", p)
+	}
+
+	// grouped by distinct durations, no array of all durations
+	stages = GetMongoLatencyStages(keys, extra, false)
+	if ids := stages[0]["$group"].(bson.M)["_id"].(bson.M); ids["milli"] != "$milli" || ids["op"] != "$op" {
+		t.Fatal("expected", "$milli", "but got", ids)
+	}
+	regroup := stages[2]["$group"].(bson.M)
+	if fmt.Sprint(regroup["reslen"]) != "map[$sum:$reslen]" || fmt.Sprint(regroup["_id"]) != "map[ns:$_id.ns op:$_id.op]" {
+		t.Fatal("expected", "map[$sum:$reslen]", "but got", regroup)
+	}
+	for _, stage := range stages {
+		if strings.Contains(fmt.Sprint(stage), `$push:$milli`) {
+			t.Fatal("expected", "no $push of $milli", "but got", stage)
+		}
+	}
+	if set := stages[3]["$set"].(bson.M); len(set) != len(LATENCY_PERCENTILES) || set["p95_ms"] == nil {
+		t.Fatal("expected", LATENCY_PERCENTILES, "but got", set)
+	}
+}
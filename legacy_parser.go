@@ -0,0 +1,492 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * legacy_parser.go
+ */
+
+package hatchet
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// legacyLine matches a line of the text format before 4.4, e.g.
+// 2020-01-01T00:00:00.000+0000 I  COMMAND  [conn1] command test.foo ...
+var legacyLine = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?)(Z|[+-]\d{2}:?\d{2})?\s+([FEWID]\d?)\s+(\S+)\s+\[([^\]]*)\]\s?(.*)$`)
+
+var (
+	legacyAccepted = regexp.MustCompile(`^connection accepted from (\S+) #(\d+) \((\d+) connections? now open\)`)
+	legacyEnded    = regexp.MustCompile(`^end connection (\S+) \((\d+) connections? now open\)`)
+	legacyMetadata = regexp.MustCompile(`^received client metadata from (\S+) (\S+): (\{.*\})$`)
+	legacyAuth     = regexp.MustCompile(`^Successfully authenticated as principal (\S+) on (\S+)(?: from client (\S+))?`)
+	legacyAuthFail = regexp.MustCompile(`^SASL (\S+) authentication failed for (\S+) on (\S+) from client (\S+) ; (.*)$`)
+	legacyStarting = regexp.MustCompile(`^MongoDB starting : pid=(\d+) port=(\d+) dbpath=(\S+) (\S+) host=(\S+)`)
+	legacyVersion  = regexp.MustCompile(`^db version v(\S+)`)
+	legacyOptions  = regexp.MustCompile(`^options: (\{.*\})$`)
+	legacySlowOp   = regexp.MustCompile(`^(\w+) (\S+) (.*) (\d+)ms$`)
+	legacyConnID   = regexp.MustCompile(`^conn(\d+)$`)
+	legacyAttrKey  = regexp.MustCompile(`^([A-Za-z_$][\w$.]*):`)
+)
+
+// IsLegacyLogLine returns true if a line is in the text format before 4.4
+func IsLegacyLogLine(line string) bool {
+	return len(line) > 0 && line[0] != '{' && legacyLine.MatchString(line)
+}
+
+// ParseLegacyLog converts a line of the text format before 4.4 to logv2, the
+// known messages of connections, authentications, startups, and slow ops are
+// converted to their logv2 messages and attributes, timestamps without an UTC
+// offset are read in the assumed location, UTC if nil
+func ParseLegacyLog(line string, loc *time.Location, doc *Logv2Info) error {
+	matches := legacyLine.FindStringSubmatch(line)
+	if matches == nil {
+		return errors.New("not a legacy log line")
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	var err error
+	*doc = Logv2Info{Severity: matches[3], Component: matches[4], Context: matches[5], Msg: matches[6], Message: matches[6]}
+	if offset := strings.ReplaceAll(matches[2], ":", ""); offset == "" {
+		doc.Timestamp, err = time.ParseInLocation("2006-01-02T15:04:05.999999999", matches[1], loc)
+	} else if offset == "Z" {
+		doc.Timestamp, err = time.Parse("2006-01-02T15:04:05.999999999", matches[1])
+	} else {
+		doc.Timestamp, err = time.Parse("2006-01-02T15:04:05.999999999-0700", matches[1]+offset)
+	}
+	if err != nil {
+		return err
+	}
+	msg := matches[6]
+	if m := legacyAccepted.FindStringSubmatch(msg); m != nil {
+		doc.ID, doc.Msg = 22943, "Connection accepted"
+		doc.Attr = bson.D{{Key: "remote", Value: m[1]}, {Key: "connectionId", Value: toLegacyInt(m[2])},
+			{Key: "connectionCount", Value: toLegacyInt(m[3])}}
+	} else if m = legacyEnded.FindStringSubmatch(msg); m != nil {
+		doc.ID, doc.Msg = 22944, "Connection ended"
+		doc.Attr = bson.D{{Key: "remote", Value: m[1]}}
+		if id := legacyConnID.FindStringSubmatch(doc.Context); id != nil {
+			doc.Attr = append(doc.Attr, bson.E{Key: "connectionId", Value: toLegacyInt(id[1])})
+		}
+		doc.Attr = append(doc.Attr, bson.E{Key: "connectionCount", Value: toLegacyInt(m[2])})
+	} else if m = legacyMetadata.FindStringSubmatch(msg); m != nil {
+		var value interface{}
+		if value, err = ParseShellValue(m[3]); err != nil {
+			return err
+		}
+		doc.ID, doc.Msg = 51800, "client metadata"
+		doc.Attr = bson.D{{Key: "remote", Value: m[1]}, {Key: "client", Value: m[2]}, {Key: "doc", Value: value}}
+	} else if m = legacyAuth.FindStringSubmatch(msg); m != nil {
+		doc.ID, doc.Msg = 20250, "Authentication succeeded"
+		doc.Attr = bson.D{{Key: "principalName", Value: m[1]}, {Key: "authenticationDatabase", Value: m[2]}}
+		if m[3] != "" {
+			doc.Attr = append(doc.Attr, bson.E{Key: "remote", Value: m[3]})
+		}
+	} else if m = legacyAuthFail.FindStringSubmatch(msg); m != nil {
+		doc.ID, doc.Msg = 20249, "Authentication failed"
+		doc.Attr = bson.D{{Key: "mechanism", Value: m[1]}, {Key: "principalName", Value: m[2]},
+			{Key: "authenticationDatabase", Value: m[3]}, {Key: "remote", Value: m[4]}, {Key: "error", Value: m[5]}}
+	} else if m = legacyStarting.FindStringSubmatch(msg); m != nil && doc.Component == "CONTROL" {
+		doc.ID, doc.Msg = 4615611, "MongoDB starting"
+		doc.Attr = bson.D{{Key: "pid", Value: toLegacyInt(m[1])}, {Key: "port", Value: toLegacyInt(m[2])},
+			{Key: "dbPath", Value: m[3]}, {Key: "architecture", Value: m[4]}, {Key: "host", Value: m[5]}}
+	} else if m = legacyVersion.FindStringSubmatch(msg); m != nil && doc.Component == "CONTROL" {
+		doc.ID, doc.Msg = 23403, "Build Info"
+		doc.Attr = bson.D{{Key: "buildInfo", Value: bson.D{{Key: "version", Value: m[1]}}}}
+	} else if m = legacyOptions.FindStringSubmatch(msg); m != nil && doc.Component == "CONTROL" {
+		if value, perr := ParseShellValue(m[1]); perr == nil { // kept as a message if not parsed
+			doc.ID, doc.Msg = 21951, "Options set by command line"
+			doc.Attr = bson.D{{Key: "options", Value: value}}
+		}
+	} else if m = legacySlowOp.FindStringSubmatch(msg); m != nil &&
+		(doc.Component == "COMMAND" || doc.Component == "WRITE" || doc.Component == "QUERY") {
+		doc.ID, doc.Msg = 51803, "Slow query"
+		doc.Attr = getLegacySlowOpAttr(m[1], m[2], m[3], toLegacyInt(m[4]))
+	}
+	return nil
+}
+
+// getLegacySlowOpAttr returns logv2 attributes of a slow op, e.g. command
+// test.foo command: find { find: "foo" } planSummary: COLLSCAN nreturned:1
+func getLegacySlowOpAttr(opType string, ns string, str string, milli interface{}) bson.D {
+	attr := bson.D{{Key: "type", Value: opType}, {Key: "ns", Value: ns}}
+	p := &shellParser{str: str}
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.str) {
+			break
+		}
+		m := legacyAttrKey.FindStringSubmatch(p.str[p.pos:])
+		if m == nil { // text without a key, e.g. exception messages
+			word := p.readUntilSpace()
+			if n := len(attr) - 1; n >= 0 {
+				if s, ok := attr[n].Value.(string); ok {
+					attr[n].Value = s + " " + word
+				}
+			}
+			continue
+		}
+		key := m[1]
+		p.pos += len(m[0])
+		p.skipSpaces()
+		var value interface{}
+		var err error
+		if key == "planSummary" {
+			value = p.readPlanSummary()
+		} else if key == "command" || key == "query" || key == "originatingCommand" {
+			if p.peek() != '{' { // command name, e.g. command: find { find: "foo" }
+				p.readUntilSpace()
+				p.skipSpaces()
+			}
+			if value, err = p.parseValue(); err != nil {
+				value = p.readUntilSpace()
+			}
+		} else if c := p.peek(); c == '{' || c == '"' || c == '[' {
+			if value, err = p.parseValue(); err != nil {
+				value = p.readUntilSpace()
+			}
+		} else {
+			word := p.readUntilSpace()
+			if value = toLegacyInt(word); value == nil {
+				value = word
+			}
+		}
+		attr = append(attr, bson.E{Key: key, Value: value})
+	}
+	if opType == "query" && ns != "" { // legacy opcode, see as find
+		if query, ok := getLegacyValue(attr, "query").(bson.D); ok {
+			command := bson.D{{Key: "find", Value: ns[strings.Index(ns, ".")+1:]}}
+			if q, ok := query.Map()["$query"]; ok {
+				command = append(command, bson.E{Key: "filter", Value: q})
+				if sort, ok := query.Map()["$orderby"]; ok {
+					command = append(command, bson.E{Key: "sort", Value: sort})
+				}
+			} else {
+				command = append(command, bson.E{Key: "filter", Value: query})
+			}
+			attr[0].Value = "command"
+			attr = append(attr, bson.E{Key: "command", Value: command})
+		}
+	}
+	return append(attr, bson.E{Key: "durationMillis", Value: milli})
+}
+
+func getLegacyValue(attr bson.D, key string) interface{} {
+	for _, e := range attr {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
+
+// toLegacyInt returns int32 or int64 of a number as logv2 does, nil if not
+// an integer
+func toLegacyInt(s string) interface{} {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil
+	} else if int64(int32(n)) == n {
+		return int32(n)
+	}
+	return n
+}
+
+// ParseShellValue parses a document printed by mongod before 4.4, e.g.
+// { _id: ObjectId('5f0e0a1b2c3d4e5f6a7b8c9d'), a: "x", b: [ 1, 2 ] }
+func ParseShellValue(str string) (interface{}, error) {
+	p := &shellParser{str: str}
+	p.skipSpaces()
+	return p.parseValue()
+}
+
+// shellParser parses shell-like values, keys are unquoted and values can be
+// constructors such as ObjectId, new Date, and Timestamp
+type shellParser struct {
+	str string
+	pos int
+}
+
+func (ptr *shellParser) peek() byte {
+	if ptr.pos >= len(ptr.str) {
+		return 0
+	}
+	return ptr.str[ptr.pos]
+}
+
+func (ptr *shellParser) skipSpaces() {
+	for ptr.pos < len(ptr.str) && (ptr.str[ptr.pos] == ' ' || ptr.str[ptr.pos] == '\t') {
+		ptr.pos++
+	}
+}
+
+func (ptr *shellParser) readUntilSpace() string {
+	start := ptr.pos
+	for ptr.pos < len(ptr.str) && ptr.str[ptr.pos] != ' ' {
+		ptr.pos++
+	}
+	return ptr.str[start:ptr.pos]
+}
+
+// readPlanSummary reads a plan summary up to the next key, e.g.
+// IXSCAN { a: 1 }, IXSCAN { b: 1 }
+func (ptr *shellParser) readPlanSummary() string {
+	start, depth := ptr.pos, 0
+	for ; ptr.pos < len(ptr.str); ptr.pos++ {
+		switch c := ptr.str[ptr.pos]; c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ' ':
+			if depth == 0 && legacyAttrKey.MatchString(ptr.str[ptr.pos+1:]) {
+				return ptr.str[start:ptr.pos]
+			}
+		}
+	}
+	return ptr.str[start:ptr.pos]
+}
+
+func (ptr *shellParser) parseValue() (interface{}, error) {
+	switch c := ptr.peek(); {
+	case c == '{':
+		return ptr.parseDocument()
+	case c == '[':
+		return ptr.parseArray()
+	case c == '"' || c == '\'':
+		return ptr.parseString()
+	case c == '/':
+		return ptr.parseRegex()
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return ptr.parseNumber()
+	case c == '_' || c == '$' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z'):
+		return ptr.parseIdentifier()
+	case c == 0:
+		return nil, errors.New("unexpected end of document")
+	default:
+		return nil, fmt.Errorf("unexpected %q at %v", c, ptr.pos)
+	}
+}
+
+func (ptr *shellParser) parseDocument() (bson.D, error) {
+	doc := bson.D{}
+	ptr.pos++ // {
+	for {
+		ptr.skipSpaces()
+		if ptr.peek() == '}' {
+			ptr.pos++
+			return doc, nil
+		} else if strings.HasPrefix(ptr.str[ptr.pos:], "...") { // truncated
+			ptr.pos += 3
+			continue
+		}
+		var key string
+		if c := ptr.peek(); c == '"' || c == '\'' {
+			s, err := ptr.parseString()
+			if err != nil {
+				return doc, err
+			}
+			key = s
+		} else {
+			i := strings.IndexByte(ptr.str[ptr.pos:], ':')
+			if i < 0 {
+				return doc, fmt.Errorf("key not found at %v", ptr.pos)
+			}
+			key = strings.TrimSpace(ptr.str[ptr.pos : ptr.pos+i])
+			ptr.pos += i
+		}
+		ptr.skipSpaces()
+		if ptr.peek() != ':' {
+			return doc, fmt.Errorf("':' expected at %v", ptr.pos)
+		}
+		ptr.pos++
+		ptr.skipSpaces()
+		value, err := ptr.parseValue()
+		if err != nil {
+			return doc, err
+		}
+		doc = append(doc, bson.E{Key: key, Value: value})
+		ptr.skipSpaces()
+		if ptr.peek() == ',' {
+			ptr.pos++
+		} else if ptr.peek() != '}' && !strings.HasPrefix(ptr.str[ptr.pos:], "...") {
+			return doc, fmt.Errorf("',' or '}' expected at %v", ptr.pos)
+		}
+	}
+}
+
+func (ptr *shellParser) parseArray() (bson.A, error) {
+	arr := bson.A{}
+	ptr.pos++ // [
+	for {
+		ptr.skipSpaces()
+		if ptr.peek() == ']' {
+			ptr.pos++
+			return arr, nil
+		} else if strings.HasPrefix(ptr.str[ptr.pos:], "...") {
+			ptr.pos += 3
+			continue
+		}
+		value, err := ptr.parseValue()
+		if err != nil {
+			return arr, err
+		}
+		arr = append(arr, value)
+		ptr.skipSpaces()
+		if ptr.peek() == ',' {
+			ptr.pos++
+		} else if ptr.peek() != ']' && !strings.HasPrefix(ptr.str[ptr.pos:], "...") {
+			return arr, fmt.Errorf("',' or ']' expected at %v", ptr.pos)
+		}
+	}
+}
+
+func (ptr *shellParser) parseString() (string, error) {
+	quote := ptr.str[ptr.pos]
+	var sb strings.Builder
+	for ptr.pos++; ptr.pos < len(ptr.str); ptr.pos++ {
+		c := ptr.str[ptr.pos]
+		if c == '\\' && ptr.pos+1 < len(ptr.str) {
+			ptr.pos++
+			sb.WriteByte(ptr.str[ptr.pos])
+		} else if c == quote {
+			ptr.pos++
+			return sb.String(), nil
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String(), errors.New("unterminated string")
+}
+
+func (ptr *shellParser) parseRegex() (primitive.Regex, error) {
+	start := ptr.pos + 1
+	for ptr.pos++; ptr.pos < len(ptr.str); ptr.pos++ {
+		if c := ptr.str[ptr.pos]; c == '\\' {
+			ptr.pos++
+		} else if c == '/' {
+			pattern := ptr.str[start:ptr.pos]
+			ptr.pos++
+			options := ptr.pos
+			for ptr.pos < len(ptr.str) && ptr.str[ptr.pos] >= 'a' && ptr.str[ptr.pos] <= 'z' {
+				ptr.pos++
+			}
+			return primitive.Regex{Pattern: pattern, Options: ptr.str[options:ptr.pos]}, nil
+		}
+	}
+	return primitive.Regex{}, errors.New("unterminated regex")
+}
+
+func (ptr *shellParser) parseNumber() (interface{}, error) {
+	start := ptr.pos
+	for ptr.pos < len(ptr.str) && strings.IndexByte("+-.0123456789eE", ptr.str[ptr.pos]) >= 0 {
+		ptr.pos++
+	}
+	s := ptr.str[start:ptr.pos]
+	if n := toLegacyInt(s); n != nil {
+		return n, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseIdentifier parses literals and constructors, unknown constructors are
+// kept as strings
+func (ptr *shellParser) parseIdentifier() (interface{}, error) {
+	start := ptr.pos
+	for ptr.pos < len(ptr.str) {
+		c := ptr.str[ptr.pos]
+		if c != '_' && c != '$' && c != '.' && !(c >= '0' && c <= '9') && !(c >= 'A' && c <= 'Z') && !(c >= 'a' && c <= 'z') {
+			break
+		}
+		ptr.pos++
+	}
+	name := ptr.str[start:ptr.pos]
+	if name == "new" {
+		ptr.skipSpaces()
+		return ptr.parseIdentifier()
+	}
+	var args []string
+	if ptr.peek() == '(' {
+		end := strings.IndexByte(ptr.str[ptr.pos:], ')')
+		if end < 0 {
+			return nil, fmt.Errorf("')' expected of %v", name)
+		}
+		for _, arg := range strings.Split(ptr.str[ptr.pos+1:ptr.pos+end], ",") {
+			args = append(args, strings.Trim(strings.TrimSpace(arg), `"'`))
+		}
+		ptr.pos += end + 1
+	} else if name == "Timestamp" && ptr.peek() == ' ' { // Timestamp 1580000000|1
+		ptr.skipSpaces()
+		args = strings.Split(ptr.readUntilDelimiter(), "|")
+	}
+	switch name {
+	case "true", "false":
+		return name == "true", nil
+	case "null", "undefined":
+		return nil, nil
+	case "MinKey":
+		return primitive.MinKey{}, nil
+	case "MaxKey":
+		return primitive.MaxKey{}, nil
+	case "NaN":
+		return strconv.ParseFloat("NaN", 64)
+	case "inf", "Infinity":
+		return strconv.ParseFloat("Inf", 64)
+	}
+	if len(args) > 0 {
+		switch name {
+		case "ObjectId":
+			if oid, err := primitive.ObjectIDFromHex(args[0]); err == nil {
+				return oid, nil
+			}
+		case "Date", "ISODate":
+			if ms, err := strconv.ParseInt(args[0], 10, 64); err == nil {
+				return primitive.DateTime(ms), nil
+			} else if tm, err := time.Parse(time.RFC3339Nano, args[0]); err == nil {
+				return primitive.NewDateTimeFromTime(tm), nil
+			}
+		case "Timestamp":
+			if len(args) == 2 {
+				t, _ := strconv.ParseUint(args[0], 10, 32)
+				i, _ := strconv.ParseUint(args[1], 10, 32)
+				return primitive.Timestamp{T: uint32(t), I: uint32(i)}, nil
+			}
+		case "NumberLong", "NumberInt":
+			if n, err := strconv.ParseInt(args[0], 10, 64); err == nil {
+				return n, nil
+			}
+		case "NumberDecimal":
+			if d, err := primitive.ParseDecimal128(args[0]); err == nil {
+				return d, nil
+			}
+		case "BinData", "UUID":
+			data := args[len(args)-1]
+			subtype := byte(4)
+			if name == "BinData" && len(args) == 2 {
+				n, _ := strconv.Atoi(args[0])
+				subtype = byte(n)
+			}
+			if b, err := hex.DecodeString(strings.ReplaceAll(data, "-", "")); err == nil {
+				return primitive.Binary{Subtype: subtype, Data: b}, nil
+			}
+		}
+		return fmt.Sprintf("%v(%v)", name, strings.Join(args, ", ")), nil
+	}
+	return name, nil
+}
+
+func (ptr *shellParser) readUntilDelimiter() string {
+	start := ptr.pos
+	for ptr.pos < len(ptr.str) && strings.IndexByte(" ,}]", ptr.str[ptr.pos]) < 0 {
+		ptr.pos++
+	}
+	return ptr.str[start:ptr.pos]
+}
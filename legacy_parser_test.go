@@ -0,0 +1,97 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * legacy_parser_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseLegacyLog(t *testing.T) {
+	if IsLegacyLogLine(`{"t":{"$date":"2023-01-01T00:00:00.000+00:00"}}`) {
+		t.Fatal("expected", false, "of logv2", "but got", true)
+	}
+	var doc Logv2Info
+	str := `2020-03-01T10:00:01.000+0000 I  NETWORK  [listener] connection accepted from 10.0.0.5:50123 #12 (3 connections now open)`
+	if !IsLegacyLogLine(str) {
+		t.Fatal("expected", true, "but got", false)
+	}
+	if err := ParseLegacyLog(str, nil, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Msg != "Connection accepted" || doc.Attr.Map()["connectionId"] != int32(12) {
+		t.Fatal("expected", "Connection accepted #12", "but got", doc.Msg, doc.Attr)
+	}
+	if err := AddLegacyString(&doc); err != nil || doc.Client == nil || doc.Client.IP != "10.0.0.5" {
+		t.Fatal("expected", "10.0.0.5", "but got", doc.Client, err)
+	}
+
+	loc, _ := time.LoadLocation("America/New_York")
+	str = `2020-03-01T10:00:02.000 I  COMMAND  [conn12] command shop.orders appName: "orders-svc" command: find { find: "orders", filter: { status: "A", qty: { $gt: 5 } }, sort: { ts: -1 }, $db: "shop" } planSummary: IXSCAN { status: 1 }, IXSCAN { qty: 1 } keysExamined:10 docsExamined:10 numYields:0 nreturned:5 reslen:4512 locks:{ Global: { acquireCount: { r: 1 } } } protocol:op_msg 1532ms`
+	if err := ParseLegacyLog(str, loc, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "2020-03-01T15:00:02.000-0000"; getDateTimeStr(doc.Timestamp) != expected {
+		t.Fatal("expected", expected, "but got", getDateTimeStr(doc.Timestamp))
+	}
+	attr := doc.Attr.Map()
+	if attr["planSummary"] != "IXSCAN { status: 1 }, IXSCAN { qty: 1 }" || attr["reslen"] != int32(4512) ||
+		attr["durationMillis"] != int32(1532) || attr["appName"] != "orders-svc" {
+		t.Fatal("expected", "slow op attributes", "but got", doc.Attr)
+	}
+	stat, err := AnalyzeSlowOp(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Op != "find" || stat.Namespace != "shop.orders" || stat.QueryPattern != `{ qty:{ $gt:1 }, status:1 }` {
+		t.Fatal("expected", "find shop.orders { qty:{ $gt:1 }, status:1 }", "but got", stat.Op, stat.Namespace, stat.QueryPattern)
+	}
+
+	str = `2020-03-01T10:00:07.000Z W  STORAGE  [WTCheckpointThread] some warning text`
+	if err = ParseLegacyLog(str, nil, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Msg != "some warning text" || doc.Severity != "W" || doc.Context != "WTCheckpointThread" {
+		t.Fatal("expected", "some warning text", "but got", doc.Msg)
+	}
+}
+
+func TestParseShellValue(t *testing.T) {
+	str := `{ _id: ObjectId('5e5b7d8f1c9d440000a1b2c3'), ts: new Date(1583056803000), "a.b": [ 1, 2.5, "x" ], re: /^ab/i, ` +
+		`t: Timestamp(1583056801, 1), id: UUID("2f4a1c2e-1b2c-4d5e-8f90-a1b2c3d4e5f6"), n: null, ok: true, big: 8589934592, ... }`
+	value, err := ParseShellValue(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := value.(bson.D).Map()
+	if oid, _ := primitive.ObjectIDFromHex("5e5b7d8f1c9d440000a1b2c3"); doc["_id"] != oid {
+		t.Fatal("expected", oid, "but got", doc["_id"])
+	}
+	if doc["ts"] != primitive.DateTime(1583056803000) {
+		t.Fatal("expected", 1583056803000, "but got", doc["ts"])
+	}
+	if arr := doc["a.b"].(bson.A); len(arr) != 3 || arr[1] != 2.5 {
+		t.Fatal("expected", "[1, 2.5, x]", "but got", arr)
+	}
+	if doc["re"] != (primitive.Regex{Pattern: "^ab", Options: "i"}) {
+		t.Fatal("expected", "/^ab/i", "but got", doc["re"])
+	}
+	if doc["t"] != (primitive.Timestamp{T: 1583056801, I: 1}) {
+		t.Fatal("expected", "Timestamp(1583056801, 1)", "but got", doc["t"])
+	}
+	if bin := doc["id"].(primitive.Binary); bin.Subtype != 4 || len(bin.Data) != 16 {
+		t.Fatal("expected", "UUID", "but got", bin)
+	}
+	if doc["n"] != nil || doc["ok"] != true || doc["big"] != int64(8589934592) {
+		t.Fatal("expected", "null, true, and int64", "but got", doc["n"], doc["ok"], doc["big"])
+	}
+	if _, err = ParseShellValue(`{ a: "unterminated }`); err == nil {
+		t.Fatal("expected", "error", "but got", nil)
+	}
+}
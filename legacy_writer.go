@@ -0,0 +1,155 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * legacy_writer.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	LEGACY_SPLIT_COMPONENT = "component"
+	LEGACY_SPLIT_SEVERITY  = "severity"
+)
+
+// legacyFile is an output file of legacy lines, gzipped if gz is not nil
+type legacyFile struct {
+	file   *os.File
+	gz     *gzip.Writer
+	writer *bufio.Writer
+}
+
+// LegacyWriter writes logs converted to the legacy format to a file, or to
+// files of a directory, {component}.log or {severity}.log, if split. Files
+// are gzipped if the name ends with .gz, and the directory is the name
+// without .gz if split
+type LegacyWriter struct {
+	Count    int
+	filename string
+	files    map[string]*legacyFile
+	gzip     bool
+	split    string
+}
+
+// NewLegacyWriter returns LegacyWriter writing to a file, or to a directory
+// if split by component or severity
+func NewLegacyWriter(filename string, split string) (*LegacyWriter, error) {
+	if split != "" && split != LEGACY_SPLIT_COMPONENT && split != LEGACY_SPLIT_SEVERITY {
+		return nil, fmt.Errorf("unknown split %v, use %v or %v", split, LEGACY_SPLIT_COMPONENT, LEGACY_SPLIT_SEVERITY)
+	}
+	ptr := &LegacyWriter{filename: filename, files: map[string]*legacyFile{},
+		gzip: strings.HasSuffix(filename, GZIP_EXT), split: split}
+	if split == "" {
+		return ptr, ptr.open("", filename)
+	}
+	ptr.filename = strings.TrimSuffix(filename, GZIP_EXT)
+	return ptr, os.MkdirAll(ptr.filename, 0755)
+}
+
+// open creates the output file of a key
+func (ptr *LegacyWriter) open(key string, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	out := &legacyFile{file: file}
+	if ptr.gzip {
+		out.gz = gzip.NewWriter(file)
+		out.writer = bufio.NewWriter(out.gz)
+	} else {
+		out.writer = bufio.NewWriter(file)
+	}
+	ptr.files[key] = out
+	return nil
+}
+
+// getKey returns the component or severity of a log, - if not logged
+func (ptr *LegacyWriter) getKey(doc *Logv2Info) string {
+	key := ""
+	if ptr.split == LEGACY_SPLIT_COMPONENT {
+		key = doc.Component
+	} else if ptr.split == LEGACY_SPLIT_SEVERITY {
+		key = doc.Severity
+	}
+	if key = replaceSpecialChars(strings.TrimSpace(key)); key == "" && ptr.split != "" {
+		key = "-"
+	}
+	return key
+}
+
+// Add writes a log in the legacy format
+func (ptr *LegacyWriter) Add(doc *Logv2Info) error {
+	key := ptr.getKey(doc)
+	out, ok := ptr.files[key]
+	if !ok {
+		filename := filepath.Join(ptr.filename, key+".log")
+		if ptr.gzip {
+			filename += GZIP_EXT
+		}
+		if err := ptr.open(key, filename); err != nil {
+			return err
+		}
+		out = ptr.files[key]
+	}
+	ptr.Count++
+	_, err := fmt.Fprintln(out.writer, GetLegacyLogString(doc))
+	return err
+}
+
+// Filenames returns names of output files in order
+func (ptr *LegacyWriter) Filenames() []string {
+	names := []string{}
+	for _, out := range ptr.files {
+		names = append(names, out.file.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Flush writes buffered lines to files
+func (ptr *LegacyWriter) Flush() error {
+	for _, out := range ptr.files {
+		if err := out.writer.Flush(); err != nil {
+			return err
+		}
+		if out.gz != nil {
+			if err := out.gz.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close flushes and closes files
+func (ptr *LegacyWriter) Close() error {
+	var err error
+	for _, out := range ptr.files {
+		if werr := out.writer.Flush(); werr != nil && err == nil {
+			err = werr
+		}
+		if out.gz != nil {
+			if werr := out.gz.Close(); werr != nil && err == nil {
+				err = werr
+			}
+		}
+		if werr := out.file.Close(); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// GetLegacyLogString returns a log line in the legacy format
+func GetLegacyLogString(doc *Logv2Info) string {
+	return fmt.Sprintf("%v %-2s %-8s [%v] %v", getDateTimeStr(doc.Timestamp),
+		doc.Severity, doc.Component, doc.Context, doc.Message)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * legacy_writer_test.go
+ */
+
+package hatchet
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLegacyWriter(t *testing.T) {
+	lines := []string{
+		`{"t":{"$date":"2023-01-01T00:00:01.000+00:00"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted","attr":{"remote":"10.0.0.1:5000","connectionId":1,"connectionCount":1}}`,
+		`{"t":{"$date":"2023-01-01T00:00:02.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders","filter":{"sku":"A"},"$db":"shop"},"durationMillis":150}}`,
+		`{"t":{"$date":"2023-01-01T00:00:03.000+00:00"},"s":"W","c":"COMMAND","id":20525,"ctx":"conn1","msg":"Failed to gather storage statistics for slow operation","attr":{"opId":1}}`,
+	}
+	docs := []Logv2Info{}
+	for _, line := range lines {
+		doc := Logv2Info{}
+		if err := UnmarshalLogv2([]byte(line), nil, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if err := AddLegacyString(&doc); err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, doc)
+	}
+
+	dir := t.TempDir()
+	writer, err := NewLegacyWriter(filepath.Join(dir, "split.gz"), LEGACY_SPLIT_COMPONENT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range docs {
+		if err = writer.Add(&docs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{filepath.Join(dir, "split", "COMMAND.log.gz"), filepath.Join(dir, "split", "NETWORK.log.gz")}
+	if names := writer.Filenames(); writer.Count != 3 || !reflect.DeepEqual(names, expected) {
+		t.Fatal("expected", expected, "but got", names, writer.Count)
+	}
+	file, err := os.Open(expected[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Split(strings.TrimSpace(string(b)), "\n"); len(got) != 2 || got[0] != GetLegacyLogString(&docs[1]) {
+		t.Fatal("expected", GetLegacyLogString(&docs[1]), "but got", got)
+	}
+
+	filename := filepath.Join(dir, "mongod.log")
+	if writer, err = NewLegacyWriter(filename, ""); err != nil {
+		t.Fatal(err)
+	}
+	writer.Add(&docs[0])
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b, err = os.ReadFile(filename); err != nil || string(b) != GetLegacyLogString(&docs[0])+"\n" {
+		t.Fatal("expected", GetLegacyLogString(&docs[0]), "but got", string(b), err)
+	}
+
+	if _, err = NewLegacyWriter(dir, "ns"); err == nil {
+		t.Fatal("expected", "unknown split error", "but got", err)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * live.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// LIVE_INTERVAL is the interval of pushing metrics of followed logs
+const LIVE_INTERVAL = 2 * time.Second
+
+// live update types
+const (
+	LIVE_METRICS = "metrics"
+	LIVE_REFRESH = "refresh" // stats of the hatchet were saved
+)
+
+// LiveUpdate is a message pushed to browsers of a followed log
+type LiveUpdate struct {
+	Hatchet string          `json:"hatchet"`
+	Metrics *HatchetMetrics `json:"metrics,omitempty"`
+	Time    int64           `json:"time"` // milliseconds since epoch
+	Type    string          `json:"type"`
+}
+
+// LiveHub pushes metrics of followed logs to WebSocket clients as lines are
+// ingested, and notifies them when stats are refreshed
+type LiveHub struct {
+	clients map[*liveClient]bool
+	metrics *Metrics
+	mutex   sync.Mutex
+}
+
+type liveClient struct {
+	hatchetName string
+	lines       int // lines of the last metrics sent
+	send        chan []byte
+}
+
+// NewLiveHub returns LiveHub of metrics
+func NewLiveHub(metrics *Metrics) *LiveHub {
+	return &LiveHub{clients: map[*liveClient]bool{}, metrics: metrics}
+}
+
+// Run pushes metrics changed to clients at an interval until stop is closed
+func (ptr *LiveHub) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ptr.publish()
+		}
+	}
+}
+
+// Refreshed notifies clients of a hatchet that its stats were saved
+func (ptr *LiveHub) Refreshed(hatchetName string) {
+	data, _ := json.Marshal(LiveUpdate{Hatchet: hatchetName, Time: time.Now().UnixMilli(), Type: LIVE_REFRESH})
+	ptr.mutex.Lock()
+	defer ptr.mutex.Unlock()
+	for client := range ptr.clients {
+		if client.hatchetName == hatchetName {
+			client.enqueue(data)
+		}
+	}
+}
+
+// Serve pushes updates of a hatchet to a WebSocket until it is closed
+func (ptr *LiveHub) Serve(ws *WebSocket, hatchetName string) {
+	client := &liveClient{hatchetName: hatchetName, lines: -1, send: make(chan []byte, 16)}
+	ptr.mutex.Lock()
+	ptr.clients[client] = true
+	ptr.mutex.Unlock()
+	defer func() {
+		ptr.mutex.Lock()
+		delete(ptr.clients, client)
+		ptr.mutex.Unlock()
+		ws.Close()
+	}()
+	ptr.publish()
+	for {
+		select {
+		case <-ws.Done():
+			return
+		case data := <-client.send:
+			if err := ws.WriteText(data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// publish enqueues metrics of hatchets to clients whose last metrics differ
+func (ptr *LiveHub) publish() {
+	ptr.mutex.Lock()
+	defer ptr.mutex.Unlock()
+	snapshots := map[string]HatchetMetrics{}
+	updates := map[string][]byte{}
+	for client := range ptr.clients {
+		metrics, ok := snapshots[client.hatchetName]
+		if !ok {
+			metrics = ptr.metrics.Get(client.hatchetName)
+			snapshots[client.hatchetName] = metrics
+			updates[client.hatchetName], _ = json.Marshal(LiveUpdate{Hatchet: client.hatchetName, Metrics: &metrics,
+				Time: time.Now().UnixMilli(), Type: LIVE_METRICS})
+		}
+		if metrics.Lines != client.lines && client.enqueue(updates[client.hatchetName]) {
+			client.lines = metrics.Lines
+		}
+	}
+}
+
+// enqueue queues a message without blocking, false if the client lags
+func (ptr *liveClient) enqueue(data []byte) bool {
+	select {
+	case ptr.send <- data:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * live_handler.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// LiveHandler responds to live updates of a followed log
+func LiveHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	/** APIs
+	 * /hatchets/{hatchet}/live/dashboard
+	 * /hatchets/{hatchet}/live/ws ; WebSocket of LiveUpdate messages
+	 */
+	hatchetName := params.ByName("hatchet")
+	attr := params.ByName("attr")
+	live := GetLogv2().live
+	if GetLogv2().verbose {
+		log.Println("LiveHandler", r.URL.Path, hatchetName)
+	}
+	if attr == "ws" {
+		if live == nil {
+			http.Error(w, "live updates are of logs followed with -follow", http.StatusNotFound)
+			return
+		}
+		ws, err := UpgradeWebSocket(w, r)
+		if err != nil {
+			log.Println("live", err)
+			return
+		}
+		live.Serve(ws, hatchetName)
+		return
+	} else if attr != "dashboard" {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": "unsupported " + attr})
+		return
+	}
+	dbase, err := GetDatabase(hatchetName)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	defer dbase.Close()
+	templ, err := GetLiveTemplate()
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	doc := map[string]interface{}{"Hatchet": hatchetName, "Live": live != nil,
+		"Summary": GetHatchetSummary(dbase.GetHatchetInfo())}
+	if err = templ.Execute(w, doc); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+}
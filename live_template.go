@@ -0,0 +1,172 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * live_template.go
+ */
+
+package hatchet
+
+import (
+	"html/template"
+)
+
+// GetLiveTemplate returns HTML of counters and rates of a followed log
+// updated by messages of a WebSocket
+func GetLiveTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Live}}
+	<div id='liveStatus' style='float: right;'>connecting...</div>
+	<table>
+		<caption>Live Counters</caption>
+		<tr><th>lines</th><th>parse errors</th><th>ops</th><th>slow ops</th><th>avg ms</th>
+			<th>connections</th><th>accepted</th><th>last log</th></tr>
+		<tr><td align='right' id='liveLines'></td><td align='right' id='liveErrors'></td>
+			<td align='right' id='liveOps'></td><td align='right' id='liveSlowOps'></td>
+			<td align='right' id='liveAvg'></td><td align='right' id='liveConns'></td>
+			<td align='right' id='liveAccepted'></td><td id='liveTimestamp'></td></tr>
+	</table>
+	<div id='liveChart' style='width: 100%; height: 320px;'></div>
+	<table id='liveByOp' style='float: left; margin-right: 20px;'>
+		<caption>Ops</caption>
+		<tr><th>op</th><th>count</th><th>slow</th><th>avg ms</th></tr>
+	</table>
+	<table id='liveShapes'>
+		<caption>Top Slow Op Shapes by Total Time</caption>
+		<tr><th>op</th><th>namespace</th><th>count</th><th>avg ms</th><th>total ms</th><th>query pattern</th></tr>
+	</table>
+<script>
+	google.charts.load('current', {'packages':['corechart']});
+	var liveRates = [];
+	var liveLast = null;
+
+	function liveSum(values) {
+		return Object.values(values || {}).reduce(function(a, b) { return a + b; }, 0);
+	}
+
+	function liveStatus(text) {
+		document.getElementById('liveStatus').textContent = text;
+	}
+
+	function liveRow(table, cells) {
+		var tr = document.createElement('tr');
+		cells.forEach(function(cell, i) {
+			var td = document.createElement('td');
+			td.textContent = cell;
+			if (typeof cell == 'number') {
+				td.align = 'right';
+			} else if (i == cells.length - 1) {
+				td.className = 'break';
+			}
+			tr.appendChild(td);
+		});
+		table.appendChild(tr);
+	}
+
+	function liveClear(table) {
+		while (table.rows.length > 1) {
+			table.deleteRow(1);
+		}
+	}
+
+	function liveUpdate(update) {
+		var m = update.metrics;
+		var ops = liveSum(m.ops), slow = liveSum(m.slow_ops), milli = liveSum(m.milli);
+		document.getElementById('liveLines').textContent = m.lines.toLocaleString();
+		document.getElementById('liveErrors').textContent = m.parse_errors.toLocaleString();
+		document.getElementById('liveOps').textContent = ops.toLocaleString();
+		document.getElementById('liveSlowOps').textContent = slow.toLocaleString();
+		document.getElementById('liveAvg').textContent = ops > 0 ? (milli / ops).toFixed(1) : '';
+		document.getElementById('liveConns').textContent = m.conns.toLocaleString();
+		document.getElementById('liveAccepted').textContent = m.accepted.toLocaleString();
+		document.getElementById('liveTimestamp').textContent = m.timestamp > 0 ? new Date(m.timestamp * 1000).toISOString() : '';
+		var table = document.getElementById('liveByOp');
+		liveClear(table);
+		Object.keys(m.ops || {}).sort().forEach(function(op) {
+			liveRow(table, [op, m.ops[op], m.slow_ops[op] || 0, Number((m.milli[op] / m.ops[op]).toFixed(1))]);
+		});
+		if (liveLast != null && update.time > liveLast.time) {
+			var seconds = (update.time - liveLast.time) / 1000;
+			liveRates.push([new Date(update.time), (m.lines - liveLast.lines) / seconds,
+				(ops - liveLast.ops) / seconds, (slow - liveLast.slow) / seconds]);
+			if (liveRates.length > 300) {
+				liveRates.shift();
+			}
+			google.charts.setOnLoadCallback(liveDraw);
+		}
+		liveLast = {time: update.time, lines: m.lines, ops: ops, slow: slow};
+	}
+
+	function liveDraw() {
+		var data = new google.visualization.DataTable();
+		data.addColumn('datetime', 'Date/Time');
+		data.addColumn('number', 'lines/s');
+		data.addColumn('number', 'ops/s');
+		data.addColumn('number', 'slow ops/s');
+		data.addRows(liveRates);
+		var options = {
+			'backgroundColor': { 'fill': 'transparent' },
+			{{if chartColors}}'colors': {{chartColors}},{{end}}
+			'title': 'Rates of Lines Ingested',
+			'vAxis': {minValue: 0},
+			'height': 320,
+			'legend': { 'position': 'right' } };
+		new google.visualization.LineChart(document.getElementById('liveChart')).draw(data, options);
+	}
+
+	function liveShapes() {
+		fetch('/api/hatchet/v1.0/hatchets/{{.Hatchet}}/stats/slowops?sort=-total_ms&limit=10')
+			.then(response => response.json())
+			.then(doc => {
+				var table = document.getElementById('liveShapes');
+				if (table == null) {
+					return;
+				}
+				liveClear(table);
+				(doc.ops || []).forEach(function(op) {
+					liveRow(table, [op.op, op.ns, op.count, op.avg_ms, op.total_ms, op.query_pattern]);
+				});
+			});
+	}
+
+	function liveConnect() {
+		var scheme = location.protocol == 'https:' ? 'wss://' : 'ws://';
+		var ws = new WebSocket(scheme + location.host + '/hatchets/{{.Hatchet}}/live/ws');
+		ws.onopen = function() {
+			liveStatus('connected');
+		};
+		ws.onmessage = function(event) {
+			if (document.getElementById('liveLines') == null) { // left the page
+				ws.onclose = null;
+				ws.close();
+				return;
+			}
+			var update = JSON.parse(event.data);
+			if (update.type == 'metrics') {
+				liveUpdate(update);
+				liveStatus('updated at ' + new Date(update.time).toLocaleTimeString());
+			} else if (update.type == 'refresh') {
+				liveShapes();
+				liveStatus('stats saved at ' + new Date(update.time).toLocaleTimeString());
+			}
+		};
+		ws.onclose = function() {
+			if (document.getElementById('liveLines') != null) {
+				liveStatus('disconnected, reconnecting...');
+				setTimeout(liveConnect, 5000);
+			}
+		};
+	}
+
+	liveShapes();
+	liveConnect();
+</script>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>live updates are of logs followed with -follow</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"chartColors": GetChartColors,
+	}).Parse(html)
+}
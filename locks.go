@@ -0,0 +1,230 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * locks.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// LOCK_COUNTERS are columns of lock acquisitions by level and mode, intent
+// modes r and w are counted as R and W, values of GetLockCounts follow the order
+var LOCK_COUNTERS = []string{"lock_global_r", "lock_global_w", "lock_database_r", "lock_database_w",
+	"lock_collection_r", "lock_collection_w"}
+
+// lockLevels maps lock levels of locks.<level>.acquireCount.<mode> to the
+// position of its R counter in LOCK_COUNTERS
+var lockLevels = map[string]int{"Global": 0, "Database": 2, "Collection": 4}
+
+// LOCK_WAIT_SERIES are series of GetLockWaits
+var LOCK_WAIT_SERIES = []string{"lock wait (s)", "ops waited", "slow ops"}
+
+// LockStat stores lock acquisitions of an op shape
+type LockStat struct {
+	CollectionR  int    `json:"lock_collection_r" bson:"lock_collection_r"`
+	CollectionW  int    `json:"lock_collection_w" bson:"lock_collection_w"`
+	Count        int    `json:"count" bson:"count"`
+	DatabaseR    int    `json:"lock_database_r" bson:"lock_database_r"`
+	DatabaseW    int    `json:"lock_database_w" bson:"lock_database_w"`
+	GlobalR      int    `json:"lock_global_r" bson:"lock_global_r"`
+	GlobalW      int    `json:"lock_global_w" bson:"lock_global_w"`
+	Namespace    string `json:"ns" bson:"ns"`
+	Op           string `json:"op" bson:"op"`
+	QueryPattern string `json:"query_pattern" bson:"query_pattern"`
+	TotalMilli   int    `json:"total_ms" bson:"total_ms"`
+	WaitCount    int    `json:"lock_wait_count" bson:"lock_wait_count"`
+	WaitMicros   int    `json:"lock_wait_micros" bson:"lock_wait_micros"`
+}
+
+// LockStats are op shapes of a namespace with most lock acquisitions
+type LockStats struct {
+	Namespace string     `json:"ns"`
+	Acquires  int        `json:"acquires"`
+	Ops       []LockStat `json:"ops"`
+}
+
+// LockWait stores lock waits of slow ops of a namespace and an op
+type LockWait struct {
+	Count      int    `json:"count"`
+	Namespace  string `json:"ns"`
+	Op         string `json:"op"`
+	TotalMilli int    `json:"total_ms"`
+	WaitCount  int    `json:"lock_wait_count"`
+	WaitMicros int    `json:"lock_wait_micros"`
+}
+
+// GetWaitMilli returns milliseconds waited acquiring locks
+func (ptr *LockWait) GetWaitMilli() float64 {
+	return float64(ptr.WaitMicros) / 1000
+}
+
+// GetAvgWaitMilli returns milliseconds waited acquiring locks per slow op
+func (ptr *LockWait) GetAvgWaitMilli() float64 {
+	if ptr.Count == 0 {
+		return 0
+	}
+	return ptr.GetWaitMilli() / float64(ptr.Count)
+}
+
+// GetWaitPercent returns time waited acquiring locks in percent of the
+// duration of slow ops
+func (ptr *LockWait) GetWaitPercent() float64 {
+	if ptr.TotalMilli == 0 {
+		return 0
+	}
+	return 100 * ptr.GetWaitMilli() / float64(ptr.TotalMilli)
+}
+
+// GetAcquires returns total lock acquisitions
+func (ptr *LockStat) GetAcquires() int {
+	return ptr.GlobalR + ptr.GlobalW + ptr.DatabaseR + ptr.DatabaseW + ptr.CollectionR + ptr.CollectionW
+}
+
+// GetMilliPerAcquire returns milliseconds per lock acquisition, high values
+// indicate locks held long and low values indicate lock churn
+func (ptr *LockStat) GetMilliPerAcquire() float64 {
+	if acquires := ptr.GetAcquires(); acquires > 0 {
+		return float64(ptr.TotalMilli) / float64(acquires)
+	}
+	return 0
+}
+
+// GetLockCounts returns lock acquisitions of a log in the order of
+// LOCK_COUNTERS, nil if a level is not logged
+func GetLockCounts(doc *Logv2Info) []interface{} {
+	counts := make([]interface{}, len(LOCK_COUNTERS))
+	locks, ok := doc.Attr.Map()["locks"].(bson.D)
+	if !ok {
+		return counts
+	}
+	for _, level := range locks {
+		i, ok := lockLevels[level.Key]
+		if !ok {
+			continue
+		}
+		lock, ok := level.Value.(bson.D)
+		if !ok {
+			continue
+		}
+		for _, elem := range lock {
+			if elem.Key != "acquireCount" {
+				continue
+			}
+			modes, ok := elem.Value.(bson.D)
+			if !ok {
+				break
+			}
+			read, write := 0, 0
+			for _, mode := range modes {
+				if mode.Key == "r" || mode.Key == "R" {
+					read += ToInt(mode.Value)
+				} else if mode.Key == "w" || mode.Key == "W" {
+					write += ToInt(mode.Value)
+				}
+			}
+			counts[i] = read
+			counts[i+1] = write
+		}
+	}
+	return counts
+}
+
+// GetLockWait returns waits and microseconds waited acquiring locks of a log
+// summed across lock levels and modes, false if neither is logged
+func GetLockWait(doc *Logv2Info) (int, int, bool) {
+	count, micros, logged := 0, 0, false
+	locks, ok := doc.Attr.Map()["locks"].(bson.D)
+	if !ok {
+		return count, micros, logged
+	}
+	for _, level := range locks {
+		lock, ok := level.Value.(bson.D)
+		if !ok {
+			continue
+		}
+		for _, elem := range lock {
+			if elem.Key != "acquireWaitCount" && elem.Key != "timeAcquiringMicros" {
+				continue
+			}
+			modes, ok := elem.Value.(bson.D)
+			if !ok {
+				continue
+			}
+			logged = true
+			for _, mode := range modes {
+				if elem.Key == "acquireWaitCount" {
+					count += ToInt(mode.Value)
+				} else {
+					micros += ToInt(mode.Value)
+				}
+			}
+		}
+	}
+	return count, micros, logged
+}
+
+// GetLockWaitsByOp sums lock waits of op shapes by namespace and op keeping
+// topN of most time waited, ops never waited are excluded
+func GetLockWaitsByOp(ops []LockStat, topN int) []LockWait {
+	docs := []LockWait{}
+	index := map[string]int{}
+	for _, op := range ops {
+		key := op.Namespace + " " + op.Op
+		i, ok := index[key]
+		if !ok {
+			i = len(docs)
+			index[key] = i
+			docs = append(docs, LockWait{Namespace: op.Namespace, Op: op.Op})
+		}
+		docs[i].Count += op.Count
+		docs[i].TotalMilli += op.TotalMilli
+		docs[i].WaitCount += op.WaitCount
+		docs[i].WaitMicros += op.WaitMicros
+	}
+	waits := []LockWait{}
+	for _, doc := range docs {
+		if doc.WaitCount > 0 || doc.WaitMicros > 0 {
+			waits = append(waits, doc)
+		}
+	}
+	sort.SliceStable(waits, func(i, j int) bool {
+		return waits[i].WaitMicros > waits[j].WaitMicros
+	})
+	if topN > 0 && len(waits) > topN {
+		waits = waits[:topN]
+	}
+	return waits
+}
+
+// GetLockStatsByNamespace groups op shapes by namespace keeping topN shapes
+// of most lock acquisitions, namespaces of most acquisitions first
+func GetLockStatsByNamespace(ops []LockStat, topN int) []LockStats {
+	docs := []LockStats{}
+	index := map[string]int{}
+	for _, op := range ops {
+		i, ok := index[op.Namespace]
+		if !ok {
+			i = len(docs)
+			index[op.Namespace] = i
+			docs = append(docs, LockStats{Namespace: op.Namespace})
+		}
+		docs[i].Acquires += op.GetAcquires()
+		docs[i].Ops = append(docs[i].Ops, op)
+	}
+	for i := range docs {
+		sort.SliceStable(docs[i].Ops, func(a, b int) bool {
+			return docs[i].Ops[a].GetAcquires() > docs[i].Ops[b].GetAcquires()
+		})
+		if topN > 0 && len(docs[i].Ops) > topN {
+			docs[i].Ops = docs[i].Ops[:topN]
+		}
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Acquires > docs[j].Acquires
+	})
+	return docs
+}
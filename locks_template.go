@@ -0,0 +1,78 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * locks_template.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetLocksTemplate returns HTML
+func GetLocksTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Waits}}
+	<table width='100%'>
+		<caption>Lock Waits by Namespace and Op</caption>
+		<tr><th>namespace</th><th>op</th><th>count</th><th>waits</th><th>wait ms</th><th>avg wait ms</th>
+			<th>total ms</th><th>% waited</th></tr>
+	{{range $w := .Waits}}
+		<tr><td>{{$w.Namespace}}</td><td>{{$w.Op}}</td><td align='right'>{{numPrinter $w.Count}}</td>
+			<td align='right'>{{numPrinter $w.WaitCount}}</td>
+			<td align='right'>{{printf "%.1f" $w.GetWaitMilli}}</td>
+			<td align='right'>{{printf "%.1f" $w.GetAvgWaitMilli}}</td>
+			<td align='right'>{{numPrinter $w.TotalMilli}}</td>
+			<td align='right'>{{printf "%.1f" $w.GetWaitPercent}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<div>Namespaces and ops of most time waited acquiring locks, <a class='btn' href='/hatchets/{{.Hatchet}}/charts/lock-waits?type=wait'>Lock Wait Time</a> charts waits over time.</div>
+	<p/>
+{{end}}
+{{if .Locks}}
+	{{range $l := .Locks}}
+	<table width='100%'>
+		<caption>{{$l.Namespace}}, {{numPrinter $l.Acquires}} Lock Acquisitions</caption>
+		<tr><th rowspan='2'>op</th><th rowspan='2'>count</th><th rowspan='2'>acquisitions</th><th rowspan='2'>avg/op</th>
+			<th rowspan='2'>ms/acquisition</th><th colspan='2'>Global</th><th colspan='2'>Database</th><th colspan='2'>Collection</th>
+			<th rowspan='2'>query pattern</th></tr>
+		<tr><th>R</th><th>W</th><th>R</th><th>W</th><th>R</th><th>W</th></tr>
+	{{range $op := $l.Ops}}
+		<tr><td>{{$op.Op}}</td><td align='right'>{{numPrinter $op.Count}}</td>
+			<td align='right'>{{numPrinter $op.GetAcquires}}</td>
+			<td align='right'>{{getAverage $op.GetAcquires $op.Count}}</td>
+			<td align='right'>{{printf "%.2f" $op.GetMilliPerAcquire}}</td>
+			<td align='right'>{{numPrinter $op.GlobalR}}</td><td align='right'>{{numPrinter $op.GlobalW}}</td>
+			<td align='right'>{{numPrinter $op.DatabaseR}}</td><td align='right'>{{numPrinter $op.DatabaseW}}</td>
+			<td align='right'>{{numPrinter $op.CollectionR}}</td><td align='right'>{{numPrinter $op.CollectionW}}</td>
+			<td class='break'>{{$op.QueryPattern}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	{{end}}
+	<div>Ops of many acquisitions per op and low ms per acquisition churn locks, ops of high ms per acquisition hold locks long.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no lock acquisitions found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"getAverage": func(total int, count int) string {
+			if count == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f", float64(total)/float64(count))
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * locks_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetLockCounts(t *testing.T) {
+	str := `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"WRITE","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"update","ns":"shop.orders","command":{"q":{"status":"new"},"u":{"$set":{"status":"done"}},"multi":true},"locks":{"ParallelBatchWriterMode":{"acquireCount":{"r":9}},"Global":{"acquireCount":{"r":2,"w":{"$numberLong":"40"}}},"Database":{"acquireCount":{"w":40,"W":1}},"Collection":{"acquireCount":{"w":40},"acquireWaitCount":{"w":3},"timeAcquiringMicros":{"w":5000}},"Mutex":{"acquireCount":{"r":80}}},"durationMillis":1200}}`
+	var doc Logv2Info
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	counts := GetLockCounts(&doc)
+	expected := []interface{}{2, 40, 0, 41, 0, 40}
+	for i, count := range counts {
+		if count != expected[i] {
+			t.Fatal("expected", expected[i], "of", LOCK_COUNTERS[i], "but got", count)
+		}
+	}
+
+	str = `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","locks":{"Global":{"acquireCount":1},"Collection":"none"},"durationMillis":200}}`
+	doc = Logv2Info{}
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for i, count := range GetLockCounts(&doc) {
+		if count != nil {
+			t.Fatal("expected", nil, "of", LOCK_COUNTERS[i], "but got", count)
+		}
+	}
+}
+
+func TestGetLockStatsByNamespace(t *testing.T) {
+	ops := []LockStat{
+		{Op: "find", Namespace: "db.a", Count: 10, TotalMilli: 1000, GlobalR: 10},
+		{Op: "update", Namespace: "db.b", Count: 10, TotalMilli: 100, GlobalW: 400, DatabaseW: 400},
+		{Op: "remove", Namespace: "db.a", Count: 1, TotalMilli: 10, GlobalW: 50},
+	}
+	docs := GetLockStatsByNamespace(ops, 1)
+	if len(docs) != 2 || docs[0].Namespace != "db.b" || docs[1].Acquires != 60 {
+		t.Fatal("expected", "db.b and db.a of 60", "but got", docs)
+	}
+	if len(docs[1].Ops) != 1 || docs[1].Ops[0].Op != "remove" {
+		t.Fatal("expected", "remove", "but got", docs[1].Ops)
+	}
+	if milli := docs[0].Ops[0].GetMilliPerAcquire(); milli != 0.125 {
+		t.Fatal("expected", 0.125, "but got", milli)
+	}
+}
+
+func TestGetLockWait(t *testing.T) {
+	str := `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"WRITE","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"update","ns":"shop.orders","locks":{"Global":{"acquireCount":{"w":2},"acquireWaitCount":{"w":1},"timeAcquiringMicros":{"w":{"$numberLong":"1500"}}},"Collection":{"acquireCount":{"w":40},"acquireWaitCount":{"w":3,"W":1},"timeAcquiringMicros":{"w":5000,"W":250}}},"durationMillis":1200}}`
+	var doc Logv2Info
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	count, micros, ok := GetLockWait(&doc)
+	if !ok || count != 5 || micros != 6750 {
+		t.Fatal("expected", 5, 6750, "but got", count, micros, ok)
+	}
+
+	str = `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","locks":{"Global":{"acquireCount":{"r":1}}},"durationMillis":200}}`
+	doc = Logv2Info{}
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok = GetLockWait(&doc); ok {
+		t.Fatal("expected", false, "but got", ok)
+	}
+}
+
+func TestGetLockWaitsByOp(t *testing.T) {
+	ops := []LockStat{
+		{Op: "find", Namespace: "db.a", Count: 10, TotalMilli: 1000},
+		{Op: "update", Namespace: "db.b", Count: 10, TotalMilli: 100, WaitCount: 4, WaitMicros: 20000},
+		{Op: "update", Namespace: "db.b", Count: 10, TotalMilli: 100, WaitCount: 1, WaitMicros: 30000},
+		{Op: "remove", Namespace: "db.a", Count: 1, TotalMilli: 10, WaitCount: 1, WaitMicros: 1000},
+	}
+	docs := GetLockWaitsByOp(ops, 0)
+	if len(docs) != 2 || docs[0].Namespace != "db.b" || docs[0].WaitCount != 5 || docs[0].Count != 20 {
+		t.Fatal("expected", "db.b update of 5 waits and remove of db.a", "but got", docs)
+	}
+	if percent := docs[0].GetWaitPercent(); percent != 25 {
+		t.Fatal("expected", 25, "but got", percent)
+	}
+	if milli := docs[0].GetAvgWaitMilli(); milli != 2.5 {
+		t.Fatal("expected", 2.5, "but got", milli)
+	}
+	if docs = GetLockWaitsByOp(ops, 1); len(docs) != 1 {
+		t.Fatal("expected", 1, "but got", len(docs))
+	}
+}
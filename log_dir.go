@@ -0,0 +1,178 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * log_dir.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LOG_DIR_SCAN_LINES is the max number of lines read to find the first timestamp
+const LOG_DIR_SCAN_LINES = 1000
+
+// rotatedLogName matches a log and its rotated files, e.g. mongod.log,
+// mongod.log.1, mongod.log.2023-01-01T00-00-00, and mongod.log-20230101
+var rotatedLogName = regexp.MustCompile(`^(.+\.log)([.-].+)?$`)
+
+// LogDir is a log and its rotated files in a directory
+type LogDir struct {
+	Name  string   // log name, e.g. mongod.log
+	Files []string // sorted by the first timestamp
+
+	opened []*os.File
+}
+
+// NewLogDir discovers a log and its rotated files in a directory and sorts
+// them in chronological order
+func NewLogDir(dir string) (*LogDir, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string][]string{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		name := entry.Name()
+		for _, ext := range LOG_COMPRESSED_EXTS {
+			name = strings.TrimSuffix(name, ext)
+		}
+		if matches := rotatedLogName.FindStringSubmatch(name); matches != nil {
+			names[matches[1]] = append(names[matches[1]], filepath.Join(dir, entry.Name()))
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no logs found in %v", dir)
+	} else if len(names) > 1 {
+		found := []string{}
+		for name := range names {
+			found = append(found, name)
+		}
+		sort.Strings(found)
+		return nil, fmt.Errorf("logs of %v found in %v, specify log files instead", strings.Join(found, ", "), dir)
+	}
+	logDir := LogDir{}
+	firsts := map[string]time.Time{}
+	for name, files := range names {
+		logDir.Name = name
+		for _, filename := range files {
+			first, err := getFirstTimestamp(filename)
+			if err != nil {
+				return nil, err
+			} else if first.IsZero() {
+				log.Println("no timestamp found in", filename, "skipped")
+				continue
+			}
+			firsts[filename] = first
+			logDir.Files = append(logDir.Files, filename)
+		}
+	}
+	if len(logDir.Files) == 0 {
+		return nil, fmt.Errorf("no log lines found in logs of %v in %v", logDir.Name, dir)
+	}
+	sort.SliceStable(logDir.Files, func(i, j int) bool {
+		return firsts[logDir.Files[i]].Before(firsts[logDir.Files[j]])
+	})
+	return &logDir, nil
+}
+
+// Open returns a reader of all files in order, files opened previously are
+// closed
+func (ptr *LogDir) Open() (*bufio.Reader, error) {
+	ptr.Close()
+	readers := []io.Reader{}
+	for _, filename := range ptr.Files {
+		file, err := os.Open(filename)
+		if err != nil {
+			ptr.Close()
+			return nil, err
+		}
+		ptr.opened = append(ptr.opened, file)
+		reader, err := NewLogReader(file)
+		if err != nil {
+			ptr.Close()
+			return nil, fmt.Errorf("%v: %v", filename, err)
+		}
+		readers = append(readers, reader)
+	}
+	return bufio.NewReader(&logDirReader{readers: readers}), nil
+}
+
+// Close closes opened files
+func (ptr *LogDir) Close() error {
+	var err error
+	for _, file := range ptr.opened {
+		if e := file.Close(); e != nil {
+			err = e
+		}
+	}
+	ptr.opened = nil
+	return err
+}
+
+// getFirstTimestamp returns the timestamp of the first logv2 line of a log,
+// zero if not found
+func getFirstTimestamp(filename string) (time.Time, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer file.Close()
+	reader, err := NewLogReader(file)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v: %v", filename, err)
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for i := 0; i < LOG_DIR_SCAN_LINES && scanner.Scan(); i++ {
+		doc := Logv2Info{}
+		if IsLegacyLogLine(scanner.Text()) {
+			err = ParseLegacyLog(scanner.Text(), nil, &doc)
+		} else {
+			err = UnmarshalLogv2(scanner.Bytes(), nil, &doc)
+		}
+		if err == nil && !doc.Timestamp.IsZero() {
+			return doc.Timestamp, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// logDirReader concatenates logs, a newline is added if a log doesn't end
+// with one
+type logDirReader struct {
+	readers []io.Reader
+	last    byte
+}
+
+func (ptr *logDirReader) Read(p []byte) (int, error) {
+	for len(ptr.readers) > 0 {
+		n, err := ptr.readers[0].Read(p)
+		if n > 0 {
+			ptr.last = p[n-1]
+			return n, nil
+		} else if err != nil && err != io.EOF {
+			return 0, err
+		} else if err == io.EOF {
+			ptr.readers = ptr.readers[1:]
+			if ptr.last != '\n' && ptr.last != 0 && len(ptr.readers) > 0 && len(p) > 0 {
+				ptr.last = '\n'
+				p[0] = '\n'
+				return 1, nil
+			}
+		}
+	}
+	return 0, io.EOF
+}
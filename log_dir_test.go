@@ -0,0 +1,67 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * log_dir_test.go
+ */
+
+package hatchet
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewLogDir(t *testing.T) {
+	line := `{"t":{"$date":"%v"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted"}`
+	dir := t.TempDir()
+	logs := map[string]string{
+		"mongod.log":          strings.Replace(line, "%v", "2023-01-03T00:00:00.000+00:00", 1) + "\n",
+		"mongod.log-20230102": strings.Replace(line, "%v", "2023-01-02T00:00:00.000+00:00", 1), // no trailing newline
+		"notes.txt":           "not a log\n",
+	}
+	for name, content := range logs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file, err := os.Create(filepath.Join(dir, "mongod.log.1.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzipWriter := gzip.NewWriter(file)
+	gzipWriter.Write([]byte(strings.Replace(line, "%v", "2023-01-01T00:00:00.000+00:00", 1) + "\n"))
+	gzipWriter.Close()
+	file.Close()
+
+	logDir, err := NewLogDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logDir.Close()
+	if logDir.Name != "mongod.log" {
+		t.Fatal("expected", "mongod.log", "but got", logDir.Name)
+	}
+	for i, expected := range []string{"mongod.log.1.gz", "mongod.log-20230102", "mongod.log"} {
+		if filepath.Base(logDir.Files[i]) != expected {
+			t.Fatal("expected", expected, "but got", logDir.Files[i])
+		}
+	}
+	reader, err := logDir.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, _ := io.ReadAll(reader)
+	if lines := strings.Split(strings.TrimSpace(string(buf)), "\n"); len(lines) != 3 {
+		t.Fatal("expected", 3, "but got", len(lines))
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "mongos.log"), []byte(logs["mongod.log"]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewLogDir(dir); err == nil {
+		t.Fatal("expected", "error of logs of mongod.log and mongos.log", "but got", nil)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * log_reader.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const BZIP2_EXT = ".bz2"
+
+// LOG_COMPRESSED_EXTS are file extensions of compressed logs
+var LOG_COMPRESSED_EXTS = []string{GZIP_EXT, ZSTD_EXT, BZIP2_EXT}
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte("BZh")
+)
+
+// IsCompressedLog returns true if a log name has a compressed file extension
+func IsCompressedLog(logname string) bool {
+	for _, ext := range LOG_COMPRESSED_EXTS {
+		if strings.HasSuffix(logname, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewLogReader returns a reader of a plain, gzip, zstd, or bzip2 log, the
+// compression is detected by the magic number of the stream
+func NewLogReader(in io.Reader) (*bufio.Reader, error) {
+	reader := bufio.NewReader(in)
+	magic, _ := reader.Peek(len(zstdMagic))
+	if bytes.HasPrefix(magic, gzipMagic) {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return bufio.NewReader(gzipReader), nil
+	} else if bytes.HasPrefix(magic, zstdMagic) {
+		decoder, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return bufio.NewReader(&zstdLogReader{decoder: decoder}), nil
+	} else if bytes.HasPrefix(magic, bzip2Magic) {
+		return bufio.NewReader(bzip2.NewReader(reader)), nil
+	}
+	return reader, nil
+}
+
+// zstdLogReader releases goroutines of the decoder at the end of the stream
+type zstdLogReader struct {
+	decoder *zstd.Decoder
+	err     error
+}
+
+func (ptr *zstdLogReader) Read(p []byte) (int, error) {
+	if ptr.err != nil {
+		return 0, ptr.err
+	}
+	n, err := ptr.decoder.Read(p)
+	if err != nil {
+		ptr.err = err
+		ptr.decoder.Close()
+	}
+	return n, err
+}
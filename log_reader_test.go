@@ -0,0 +1,67 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * log_reader_test.go
+ */
+
+package hatchet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNewLogReader(t *testing.T) {
+	content := `{"t":{"$date":"2023-01-01T00:00:00.000+00:00"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted"}` + "\n"
+	var gzipBuf, zstdBuf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipBuf)
+	gzipWriter.Write([]byte(content))
+	gzipWriter.Close()
+	zstdWriter, err := zstd.NewWriter(&zstdBuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zstdWriter.Write([]byte(content))
+	zstdWriter.Close()
+	streams := map[string][]byte{"plain": []byte(content), "gzip": gzipBuf.Bytes(), "zstd": zstdBuf.Bytes()}
+	if _, err = exec.LookPath("bzip2"); err == nil {
+		cmd := exec.Command("bzip2", "-c")
+		cmd.Stdin = strings.NewReader(content)
+		if streams["bzip2"], err = cmd.Output(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range streams {
+		reader, err := NewLogReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		buf, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if string(buf) != content {
+			t.Fatal("expected", content, "but got", string(buf), "of", name)
+		}
+	}
+}
+
+func TestIsCompressedLog(t *testing.T) {
+	for _, logname := range []string{"mongod.log.gz", "mongod.log.zst", "mongod.log.bz2"} {
+		if !IsCompressedLog(logname) {
+			t.Fatal("expected", true, "but got", false, "of", logname)
+		}
+		name := getHatchetName(logname)
+		if name[:len("mongod_")] != "mongod_" || len(name) != len("mongod")+TAIL_SIZE {
+			t.Fatal("expected", "mongod_*", "but got", name)
+		}
+	}
+	if IsCompressedLog("mongod.log") {
+		t.Fatal("expected", false, "but got", true)
+	}
+}
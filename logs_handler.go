@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/julienschmidt/httprouter"
@@ -15,8 +16,9 @@ import (
 // LogsHandler responds to charts API calls
 func LogsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	/** APIs
-	 * /hatchets/{hatchet}/logs/all
+	 * /hatchets/{hatchet}/logs/all[?component=&context=&severity=&source=&duration=&limit=]
 	 * /hatchets/{hatchet}/logs/slowops
+	 * /hatchets/{hatchet}/logs/search[?q=&limit=]
 	 */
 	hatchetName := params.ByName("hatchet")
 	attr := params.ByName("attr")
@@ -38,6 +40,7 @@ func LogsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Param
 		component := r.URL.Query().Get("component")
 		context := r.URL.Query().Get("context")
 		severity := r.URL.Query().Get("severity")
+		source := r.URL.Query().Get("source")
 		limit := r.URL.Query().Get("limit")
 		if limit == "" {
 			limit = fmt.Sprintf("%v", LIMIT)
@@ -45,7 +48,7 @@ func LogsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Param
 		offset, nlimit := GetOffsetLimit(limit)
 		logs, err := dbase.GetLogs(fmt.Sprintf("component=%v", component), fmt.Sprintf("limit=%v", limit),
 			fmt.Sprintf("context=%v", context), fmt.Sprintf("severity=%v", severity),
-			fmt.Sprintf("duration=%v", duration))
+			fmt.Sprintf("source=%v", source), fmt.Sprintf("duration=%v", duration))
 		if err != nil {
 			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
 			return
@@ -65,11 +68,15 @@ func LogsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Param
 			logs = logs[:len(logs)-1]
 		}
 		limit = fmt.Sprintf("%v,%v", offset+nlimit, nlimit)
-		url := fmt.Sprintf("%v?component=%v&context=%v&severity=%v&duration=%v&limit=%v", r.URL.Path,
-			component, context, severity, duration, limit)
+		url := fmt.Sprintf("%v?component=%v&context=%v&severity=%v&source=%v&duration=%v&limit=%v", r.URL.Path,
+			component, context, severity, source, duration, limit)
+		merged := source != ""
+		for _, doc := range logs {
+			merged = merged || doc.Source != ""
+		}
 		doc := map[string]interface{}{"Hatchet": hatchetName, "Logs": logs, "Seq": seq,
-			"Summary": summary, "Context": context, "Component": component, "Severity": severity,
-			"HasMore": hasMore, "URL": url}
+			"Summary": summary, "Context": context, "Component": component, "Severity": severity, "Duration": duration,
+			"Source": source, "Merged": merged, "HasMore": hasMore, "URL": url}
 		if err = templ.Execute(w, doc); err != nil {
 			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
 			return
@@ -96,5 +103,41 @@ func LogsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Param
 			return
 		}
 		return
+	} else if attr == "search" {
+		var hasMore bool
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		limit := r.URL.Query().Get("limit")
+		if limit == "" {
+			limit = fmt.Sprintf("%v", LIMIT)
+		}
+		offset, nlimit := GetOffsetLimit(limit)
+		logs := []LegacyLog{}
+		if query != "" {
+			if logs, err = dbase.SearchMessages(query, limit); err != nil {
+				json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+				return
+			}
+		}
+		templ, err := GetLogTableTemplate(attr)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		hasMore = len(logs) > nlimit
+		if hasMore {
+			logs = logs[:len(logs)-1]
+		}
+		url := fmt.Sprintf("%v?q=%v&limit=%v,%v", r.URL.Path, url.QueryEscape(query), offset+nlimit, nlimit)
+		merged := false
+		for _, doc := range logs {
+			merged = merged || doc.Source != ""
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Logs": logs, "Seq": offset + 1, "Summary": summary,
+			"Query": query, "Merged": merged, "HasMore": hasMore, "URL": url}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
 	}
 }
@@ -19,6 +19,8 @@ func GetLogTableTemplate(attr string) (*template.Template, error) {
 	html := getContentHTML()
 	if attr == "slowops" {
 		html += getSlowOpsLogsTable()
+	} else if attr == "search" {
+		html += getSearchLogsTable()
 	} else {
 		html += getLegacyLogsTable()
 	}
@@ -54,6 +56,9 @@ func GetLogTableTemplate(attr string) (*template.Template, error) {
 		"highlightLog": func(log string, params ...string) template.HTML {
 			return template.HTML(highlightLog(log, params...))
 		},
+		"highlightSearch": func(log string, query string) template.HTML {
+			return template.HTML(highlightLog(log, GetSearchTerms(query)...))
+		},
 		"formatDateTime": func(str string) string {
 			return strings.Replace(str, "T", " ", 1)
 		}}).Parse(html)
@@ -77,6 +82,31 @@ func highlightLog(log string, params ...string) string {
 	return log
 }
 
+// GetSearchTerms returns regex quoted words and phrases of a full text query
+// to highlight, operators and excluded terms are skipped
+func GetSearchTerms(query string) []string {
+	terms := []string{}
+	re := regexp.MustCompile(`-?\(*"[^"]+"\)*|\S+`)
+	negate := false
+	for _, tok := range re.FindAllString(query, -1) {
+		tok = strings.Trim(tok, "()")
+		if tok == "AND" || tok == "OR" || tok == "" {
+			continue
+		} else if tok == "NOT" {
+			negate = true
+			continue
+		}
+		if !negate && !strings.HasPrefix(tok, "-") {
+			tok = strings.TrimSuffix(strings.Trim(tok, `"`), "*")
+			if tok != "" {
+				terms = append(terms, regexp.QuoteMeta(tok))
+			}
+		}
+		negate = false
+	}
+	return terms
+}
+
 func getSlowOpsLogsTable() string {
 	template := ` 
 <p/>
@@ -98,7 +128,8 @@ func getSlowOpsLogsTable() string {
 			<td>{{ $value.Severity }}</td>
 			<td>{{ $value.Component }}</td>
 			<td><a href='/hatchets/{{$hatchet}}/logs/all?context={{$value.Context}}'>{{ $value.Context }}</a></td>
-			<td>{{ highlightLog $value.Message }}</td>
+			<td>{{ highlightLog $value.Message }}
+				<a href='/api/hatchet/v1.0/hatchets/{{$hatchet}}/trace/{{$value.ID}}?download=true' title='export trace'><i class='fa fa-download'></i></a></td>
 		</tr>
 {{end}}
 	</table>
@@ -131,6 +162,17 @@ func getLegacyLogsTable() string {
 	<input id='context' type='text' value='{{.Context}}' size='30'/>
 	<button id="find" onClick="findLogs()" class="button" style="float: right;">Find</button>
   </div>
+  <div style="float: left; margin-right: 20px;">
+	<button id="export" onClick="javascript:location.href='/api/hatchet/v1.0/hatchets/{{.Hatchet}}/logs/raw?component={{.Component}}&severity={{.Severity}}&context={{.Context}}&source={{.Source}}&duration={{.Duration}}'; return false;"
+		class="btn" title="export log lines of the filter"><i class="fa fa-download"></i></button>
+  </div>
+{{if .Source}}
+  <div style="float: left; margin-right: 20px;">
+	<label><i class="fa fa-server"></i></label> {{.Source}}
+	<a href='/hatchets/{{.Hatchet}}/logs/all?component={{.Component}}&severity={{.Severity}}&context={{.Context}}&duration={{.Duration}}'
+		title='all sources'><i class="fa fa-times"></i></a>
+  </div>
+{{end}}
 
 <p/>
 <div>
@@ -146,8 +188,10 @@ func getLegacyLogsTable() string {
 			<th>S</th>
 			<th>component</th>
 			<th>context</th>
+			{{if .Merged}}<th>source</th>{{end}}
 			<th>message</th>
 		</tr>
+	{{$merged := .Merged}}
 	{{$search := .Context}}
 	{{$seq := .Seq}}
 	{{$hatchet := .Hatchet}}
@@ -158,6 +202,7 @@ func getLegacyLogsTable() string {
 			<td>{{ $value.Severity }}</td>
 			<td>{{ $value.Component }}</td>
 			<td><a href='/hatchets/{{$hatchet}}/logs/all?context={{$value.Context}}'>{{ $value.Context }}</a></td>
+			{{if $merged}}<td><a href='/hatchets/{{$hatchet}}/logs/all?source={{$value.Source}}'>{{ $value.Source }}</a></td>{{end}}
 			<td>{{ highlightLog $value.Message $search }}</td>
 		</tr>
 	{{end}}
@@ -184,7 +229,75 @@ func getLegacyLogsTable() string {
 		sel = document.getElementById('severity')
 		var severity = sel.options[sel.selectedIndex].value;
 		var context = document.getElementById('context').value
-		loadData('/hatchets/{{.Hatchet}}/logs/all?component='+component+'&severity='+severity+'&context='+context);
+		loadData('/hatchets/{{.Hatchet}}/logs/all?component='+component+'&severity='+severity+'&context='+context+'&source={{.Source}}');
+	}
+</script>
+`
+	return template
+}
+
+func getSearchLogsTable() string {
+	template := `
+  <div style="float: left; margin-right: 20px; clear: left;">
+	<label><i class="fa fa-binoculars"></i></label>
+	<input id='query' type='text' value='{{.Query}}' size='60'
+		placeholder='words, "a phrase", prefix*, AND, OR, NOT, (...)'/>
+	<button id="find" onClick="searchLogs()" class="button" style="float: right;">Search</button>
+  </div>
+
+<p/>
+<div>
+{{ if .Logs }}
+	{{if .HasMore}}
+		<button onClick="javascript:loadData('{{.URL}}'); return false;"
+			class="btn" style="float: right; clear: right"><i class="fa fa-arrow-right"></i></button>
+	{{end}}
+	<table width='100%'>
+		<tr>
+			<th>#</th>
+			<th>date</th>
+			<th>S</th>
+			<th>component</th>
+			<th>context</th>
+			{{if .Merged}}<th>source</th>{{end}}
+			<th>message</th>
+		</tr>
+	{{$merged := .Merged}}
+	{{$query := .Query}}
+	{{$seq := .Seq}}
+	{{$hatchet := .Hatchet}}
+	{{range $n, $value := .Logs}}
+		<tr>
+			<td align='right'>{{ add $n $seq }}</td>
+			<td>{{ formatDateTime $value.Timestamp }}</td>
+			<td>{{ $value.Severity }}</td>
+			<td>{{ $value.Component }}</td>
+			<td><a href='/hatchets/{{$hatchet}}/logs/all?context={{$value.Context}}'>{{ $value.Context }}</a></td>
+			{{if $merged}}<td><a href='/hatchets/{{$hatchet}}/logs/all?source={{$value.Source}}'>{{ $value.Source }}</a></td>{{end}}
+			<td>{{ highlightSearch $value.Message $query }}</td>
+		</tr>
+	{{end}}
+	</table>
+	{{if .HasMore}}
+		<button onClick="javascript:loadData('{{.URL}}'); return false;"
+			class="btn" style="float: right; clear: right;"><i class="fa fa-arrow-right"></i></button>
+	{{end}}
+<div align='center'><hr/><p/>@simagix</div>
+{{ else if .Query }}
+	<div align='center' class='btn' style='clear: left;'><span style='color: red'>no messages matched</span></div>
+{{end}}
+</div>
+<script>
+	document.getElementById("query").addEventListener("keypress", function(event) {
+		if (event.key === "Enter") {
+			event.preventDefault();
+			document.getElementById("find").click();
+		}
+	});
+
+	function searchLogs() {
+		var query = document.getElementById('query').value;
+		loadData('/hatchets/{{.Hatchet}}/logs/search?q=' + encodeURIComponent(query));
 	}
 </script>
 `
@@ -9,9 +9,13 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/simagix/gox"
@@ -23,10 +27,14 @@ const (
 	DOLLAR_CMD = "$cmd"
 	LIMIT      = 100
 	TOP_N      = 23
+
+	MAX_DB_SIZE_CHECK = 10000 // lines between database size checks
 )
 
 var instance *Logv2
 
+var offsetlessDate = regexp.MustCompile(`^\s*{\s*"t"\s*:\s*{\s*"\$date"\s*:\s*"(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?)"`)
+
 // GetLogv2 returns Logv2 instance
 func GetLogv2() *Logv2 {
 	if instance == nil {
@@ -37,18 +45,57 @@ func GetLogv2() *Logv2 {
 
 // Logv2 keeps Logv2 object
 type Logv2 struct {
-	buildInfo   map[string]interface{}
-	logname     string
-	legacy      bool
-	hatchetName string
-	isDigest    bool
-	s3client    *S3Client
-	testing     bool //test mode
-	totalLines  int
-	url         string // connection string
-	user        string
-	verbose     bool
-	version     string
+	backend         string // database type, detected from url if empty
+	batchSize       int    // lines inserted per transaction, 0 for the default of the database
+	buildInfo       map[string]interface{}
+	chartColors     []string // series colors of charts, the default palette if empty
+	admission       *AdmissionStats
+	anonymizer      *Anonymizer // aliases of IPs, hosts, and namespaces, nil if not enabled
+	apps            *AppNames
+	auths           *AuthFailures
+	connLimits      *ConnLimitStats
+	cursors         *CursorStats
+	logname         string
+	maxDBSize       int64 // stops ingesting when the database file reaches the size
+	maxShapes       int   // caps distinct shapes, 0 for unlimited
+	legacy          bool
+	legacyOut       *LegacyWriter // legacy lines, stdout if nil
+	live            *LiveHub      // pushes updates of a followed log, nil if not following
+	merge           *mergeState   // logs merged into one hatchet, nil if not merging
+	metrics         *Metrics      // scraped from /metrics, nil if not enabled
+	hatchetName     string
+	hotDocs         *HotDocCounter
+	hotDocThreshold int
+	incremental     bool // ingests lines appended to a log ingested before
+	isDigest        bool
+	jsonl           *JSONLWriter   // parsed documents, nil if not enabled
+	location        *time.Location // assumed time zone of offset-less timestamps
+	oplog           *OplogStats
+	oplogWindow     int           // hours, warns when the estimated oplog window drops below
+	otlp            *OTLPExporter // slow ops as spans, nil if not enabled
+	otlpEndpoint    string
+	otlpServices    *ServiceNames
+	quarantine      *Quarantine // skipped lines, nil if not enabled
+	redact          bool        // replaces literals of commands before storing
+	follow          bool        // tails a live log
+	restarts        *RestartStats
+	server          *ServerInfo
+	storeRaw        bool // stores original lines
+	targetingRatio  int  // keys or documents examined per document returned to flag a shape
+	templates       *TemplateMiner
+	shapes          *ShapeGuard
+	s3client        *S3Client
+	source          string // tags lines of merged logs
+	slowOnce        sync.Once
+	slowThresholds  *SlowThresholds
+	testing         bool //test mode
+	totalLines      int
+	ttl             *TTLStats
+	url             string // connection string
+	user            string
+	verbose         bool
+	version         string
+	workers         int // goroutines parsing lines
 }
 
 // Logv2Info stores logv2 struct
@@ -61,9 +108,12 @@ type Logv2Info struct {
 	Severity  string    `json:"s" bson:"s"`
 	Timestamp time.Time `json:"t" bson:"t"`
 
+	AppName    string // appName of an op
 	Attributes Attributes
 	Message    string // remaining legacy message
 	Client     *RemoteClient
+	Raw        string // original line, stored if enabled
+	Source     string // source of merged logs
 }
 
 type Attributes struct {
@@ -75,6 +125,7 @@ type Attributes struct {
 	PlanSummary        string                 `json:"planSummary" bson:"planSummary"`
 	Reslen             int                    `json:"reslen" bson:"reslen"`
 	Type               string                 `json:"type" bson:"type"`
+	WriteConflicts     int                    `json:"writeConflicts" bson:"writeConflicts"`
 }
 
 type RemoteClient struct {
@@ -92,21 +143,29 @@ type RemoteClient struct {
 type OpStat struct {
 	AvgMilli     float64 `json:"avg_ms" bson:"avg_ms"`               // avg millisecond
 	Count        int     `json:"count" bson:"count"`                 // number of ops
+	FirstSeen    string  `json:"first_seen" bson:"first_seen"`       // first op timestamp
 	Index        string  `json:"index" bson:"index"`                 // index used
+	LastSeen     string  `json:"last_seen" bson:"last_seen"`         // last op timestamp
 	MaxMilli     int     `json:"max_ms" bson:"max_ms"`               // max millisecond
 	Namespace    string  `json:"ns" bson:"ns"`                       // database.collectin
 	Op           string  `json:"op" bson:"op"`                       // count, delete, find, remove, and update
+	P50Milli     int     `json:"p50_ms" bson:"p50_ms"`               // median millisecond
+	P95Milli     int     `json:"p95_ms" bson:"p95_ms"`               // 95th percentile millisecond
+	P99Milli     int     `json:"p99_ms" bson:"p99_ms"`               // 99th percentile millisecond
 	QueryPattern string  `json:"query_pattern" bson:"query_pattern"` // query pattern
+	SortPattern  string  `json:"sort_pattern" bson:"sort_pattern"`   // sort pattern
 	Reslen       int     `json:"total_reslen" bson:"total_reslen"`   // total reslen
 	TotalMilli   int     `json:"total_ms" bson:"total_ms"`           // total milliseconds
 }
 
 type LegacyLog struct {
+	ID        int    `json:"id,omitempty" bson:"_id"`
 	Timestamp string `json:"date" bson:"date"`
 	Severity  string `json:"severity" bson:"severity"`
 	Component string `json:"component" bson:"component"`
 	Context   string `json:"context" bson:"context"`
 	Message   string `json:"message" bson:"message"` // remaining legacy message
+	Source    string `json:"source,omitempty" bson:"source,omitempty"`
 }
 
 type HatchetInfo struct {
@@ -118,32 +177,138 @@ type HatchetInfo struct {
 	Start   string `bson:"start"`
 	Version string `bson:"version"`
 
+	SlowMilli int    `bson:"slow_ms"` // slow thresholds ingested with
+	SlowNS    string `bson:"slow_ns"`
+
 	Drivers  []map[string]string
 	Provider string `bson:"region"`
 	Region   string `bson:"provider"`
 }
 
+// UnmarshalLogv2 parses a logv2 line, timestamps without an UTC offset are
+// read in the assumed location, UTC if nil
+func UnmarshalLogv2(buf []byte, loc *time.Location, doc *Logv2Info) error {
+	err := bson.UnmarshalExtJSON(buf, false, doc)
+	if err == nil {
+		return err
+	}
+	matches := offsetlessDate.FindSubmatchIndex(buf)
+	if matches == nil {
+		return err
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	var tm time.Time
+	if tm, err = time.ParseInLocation("2006-01-02T15:04:05", string(buf[matches[2]:matches[3]]), loc); err != nil {
+		return err
+	}
+	data := append([]byte{}, buf[:matches[2]]...)
+	data = append(data, tm.UTC().Format(time.RFC3339Nano)...)
+	data = append(data, buf[matches[3]:]...)
+	*doc = Logv2Info{}
+	return bson.UnmarshalExtJSON(data, false, doc)
+}
+
+// GetSlowThresholds returns slow thresholds by namespace, SLOW_MS if not set
+func (ptr *Logv2) GetSlowThresholds() *SlowThresholds {
+	ptr.slowOnce.Do(func() { // read by concurrent handlers
+		if ptr.slowThresholds == nil {
+			ptr.slowThresholds = &SlowThresholds{Default: SLOW_MS}
+		}
+	})
+	return ptr.slowThresholds
+}
+
+// GetOplogWindowThreshold returns hours of the oplog window to warn when the
+// estimated window drops below, OPLOG_WINDOW_WARN if not set
+func (ptr *Logv2) GetOplogWindowThreshold() int {
+	if ptr.oplogWindow <= 0 {
+		return OPLOG_WINDOW_WARN
+	}
+	return ptr.oplogWindow
+}
+
+// GetTargetingRatio returns keys or documents examined per document returned
+// to flag poor query targeting, TARGETING_RATIO if not set
+func (ptr *Logv2) GetTargetingRatio() int {
+	if ptr.targetingRatio <= 0 {
+		return TARGETING_RATIO
+	}
+	return ptr.targetingRatio
+}
+
+// GetDBType returns the type of database of -backend or by the scheme of
+// the url
 func (ptr *Logv2) GetDBType() int {
-	if strings.HasPrefix(ptr.url, "mongodb://") || strings.HasPrefix(ptr.url, "mongodb+srv://") {
+	if dbType, ok := BACKENDS[ptr.backend]; ok {
+		return dbType
+	} else if strings.HasPrefix(ptr.url, "mongodb://") || strings.HasPrefix(ptr.url, "mongodb+srv://") {
 		return Mongo
 	}
 	return SQLite3
 }
 
+// CheckDBType returns an error if -backend or the scheme of the url is of a
+// database not implemented, e.g. postgres://
+func (ptr *Logv2) CheckDBType() error {
+	for key, name := range UNSUPPORTED_DATABASES {
+		if ptr.backend == key || strings.HasPrefix(ptr.url, key+"://") {
+			return fmt.Errorf("%v is not supported, no driver is built in, use a SQLite3 file or a mongodb:// URL", name)
+		}
+	}
+	if _, ok := BACKENDS[ptr.backend]; ptr.backend != "" && !ok {
+		return fmt.Errorf("unknown -backend %v, use sqlite3 or mongodb", ptr.backend)
+	}
+	return nil
+}
+
 // Analyze analyzes logs from a file
 func (ptr *Logv2) Analyze(logname string) error {
 	var err error
-	var buf []byte
 	var file *os.File
+	var follower *FollowReader
 	var reader *bufio.Reader
+	var logDir *LogDir
 	ptr.logname = logname
-	ptr.hatchetName = getHatchetName(ptr.logname)
+	if info, err := os.Stat(logname); err == nil && info.IsDir() && ptr.s3client == nil {
+		if logDir, err = NewLogDir(logname); err != nil {
+			return err
+		}
+		defer logDir.Close()
+		ptr.hatchetName = getHatchetName(logDir.Name)
+	} else {
+		ptr.hatchetName = getHatchetName(ptr.logname)
+	}
+	resuming := ptr.merge != nil && ptr.merge.hatchetName != ""
+	if resuming {
+		ptr.hatchetName = ptr.merge.hatchetName
+	}
+	var ingest *Ingest // a log ingested before, nil if ingested from the beginning
+	if ptr.incremental && !ptr.legacy {
+		if ingest, err = ptr.findIngest(logname); err != nil {
+			return err
+		}
+		if ingest != nil {
+			ptr.hatchetName = ingest.Name
+			log.Printf("resuming %v ingested to line %v (offset %v bytes)\n", logname, ingest.Lines, ingest.Offset)
+		}
+	}
 	if !ptr.legacy {
 		log.Println("processing", logname)
 		log.Println("hatchet name is", ptr.hatchetName)
 	}
 
-	if ptr.s3client != nil {
+	if IsCloudURL(logname) {
+		var body io.ReadCloser
+		if body, err = GetCloudContent(logname, ptr.s3client); err != nil {
+			return err
+		}
+		defer body.Close()
+		if reader, err = NewLogReader(body); err != nil {
+			return err
+		}
+	} else if ptr.s3client != nil {
 		var buf []byte
 		if buf, err = ptr.s3client.GetObject(logname); err != nil {
 			return err
@@ -151,6 +316,15 @@ func (ptr *Logv2) Analyze(logname string) error {
 		if reader, err = GetBufioReader(buf); err != nil {
 			return err
 		}
+	} else if strings.HasPrefix(logname, "ssh://") {
+		var body io.ReadCloser
+		if body, err = GetSSHContent(logname); err != nil {
+			return err
+		}
+		defer body.Close()
+		if reader, err = NewLogReader(body); err != nil {
+			return err
+		}
 	} else if strings.HasPrefix(logname, "http://") || strings.HasPrefix(logname, "https://") {
 		var username, password string
 		if ptr.user != "" {
@@ -169,96 +343,220 @@ func (ptr *Logv2) Analyze(logname string) error {
 				return err
 			}
 		}
+	} else if ptr.follow {
+		if IsCompressedLog(logname) || logDir != nil {
+			return fmt.Errorf("cannot follow compressed log or directory %v", logname)
+		}
+		if file, err = os.Open(logname); err != nil {
+			return err
+		}
+		follower = NewFollowReader(file, logname)
+		defer follower.Close()
+		reader = bufio.NewReader(follower)
+	} else if logDir != nil {
+		for _, filename := range logDir.Files {
+			log.Println("merging", filename)
+		}
+		if reader, err = logDir.Open(); err != nil {
+			return err
+		}
+		if !ptr.legacy {
+			log.Println("fast counting", logname, "...")
+			ptr.totalLines, _ = gox.CountLines(reader)
+			log.Println("counted", ptr.totalLines, "lines")
+			if reader, err = logDir.Open(); err != nil {
+				return err
+			}
+		}
 	} else {
 		if file, err = os.Open(logname); err != nil {
 			return err
 		}
 		defer file.Close()
-		if reader, err = gox.NewReader(file); err != nil {
+		if ingest != nil { // lines appended only
+			if _, err = file.Seek(ingest.Offset, 0); err != nil {
+				return err
+			}
+			reader = bufio.NewReader(file)
+		} else if reader, err = NewLogReader(file); err != nil {
 			return err
 		}
-		if !ptr.legacy {
+		if !ptr.legacy && ingest == nil {
 			log.Println("fast counting", logname, "...")
 			ptr.totalLines, _ = gox.CountLines(reader)
 			log.Println("counted", ptr.totalLines, "lines")
 			if _, err = file.Seek(0, 0); err != nil {
 				return err
 			}
-			if reader, err = gox.NewReader(file); err != nil {
+			if reader, err = NewLogReader(file); err != nil {
 				return err
 			}
 		}
 	}
 
-	var isPrefix bool
+	var head string
+	var legacyText bool
 	var stat *OpStat
+	var offset int64
 	index := 0
+	base := 0 // ids of lines continue from logs merged
+	if ptr.merge != nil {
+		base = ptr.merge.lines
+	}
 	var start, end string
 	var dbase Database
+	if ingest != nil {
+		base, head, start, end = ingest.Lines, ingest.Head, ingest.Start, ingest.End
+	}
 
 	if !ptr.legacy {
 		if dbase, err = GetDatabase(ptr.hatchetName); err != nil {
 			return err
 		}
 		defer dbase.Close()
-		if err = dbase.Begin(); err != nil {
+		if resuming || ingest != nil {
+			err = dbase.Resume()
+		} else {
+			err = dbase.Begin()
+		}
+		if err != nil {
 			return err
 		}
 	}
+	if !ptr.legacy && !resuming {
+		ptr.admission = NewAdmissionStats()
+		ptr.apps = NewAppNames()
+		ptr.auths = NewAuthFailures()
+		ptr.connLimits = NewConnLimitStats()
+		ptr.cursors = NewCursorStats()
+		ptr.oplog = NewOplogStats()
+		ptr.restarts = NewRestartStats()
+		ptr.server = NewServerInfo()
+		ptr.shapes = NewShapeGuard(ptr.maxShapes)
+		ptr.templates = NewTemplateMiner()
+		ptr.ttl = NewTTLStats()
+		if ptr.hotDocThreshold > 0 {
+			ptr.hotDocs = NewHotDocCounter(HOT_DOC_CAPACITY)
+		}
+		if ptr.otlpEndpoint != "" {
+			ptr.otlp = NewOTLPExporter(ptr.otlpEndpoint, ptr.otlpServices, ptr.hatchetName)
+		}
+		if ingest != nil {
+			if err = ptr.replayStats(logname, ingest.Offset); err != nil {
+				return err
+			}
+		}
+		if follower != nil {
+			var refreshed time.Time
+			lastIndex := 0
+			follower.OnIdle = func() { // lines are read in the same goroutine
+				if index == lastIndex || time.Since(refreshed) < FOLLOW_REFRESH_INTERVAL {
+					return
+				}
+				if err := ptr.refresh(dbase, start, end); err != nil {
+					log.Println("follow", err)
+				}
+				lastIndex, refreshed = index, time.Now()
+			}
+		}
+	}
 
+	workers := ptr.workers
+	if follower != nil { // idle callbacks commit in the goroutine reading lines
+		workers = 1
+	}
+	lines := NewLinePipeline(reader, workers, ptr.parseLine)
+	defer lines.Close()
 	for {
 		if !ptr.testing && !ptr.legacy && index%50 == 0 && ptr.totalLines > 0 {
 			fmt.Fprintf(os.Stderr, "\r%3d%% \r", (100*index)/ptr.totalLines)
 		}
-		if buf, isPrefix, err = reader.ReadLine(); err != nil { // 0x0A separator = newline
+		if ptr.maxDBSize > 0 && index > 0 && index%MAX_DB_SIZE_CHECK == 0 && ptr.GetDBType() == SQLite3 {
+			if size := GetDBFileSize(ptr.url); size >= ptr.maxDBSize {
+				log.Printf("database size %v reached the limit %v, stopped at line %v (offset %v bytes, timestamp %v)\n",
+					gox.GetStorageSize(size), gox.GetStorageSize(ptr.maxDBSize), index, offset, end)
+				break
+			}
+		}
+		line, ok := lines.Next()
+		if !ok {
 			break
 		}
-		index++
-		if len(buf) == 0 {
+		index, offset = line.Index, line.Offset
+		if index == 1 && ingest == nil {
+			head = getLogHead(line.Str)
+		}
+		if line.Str == "" {
 			continue
 		}
-		str := string(buf)
-		for isPrefix {
-			var bbuf []byte
-			if bbuf, isPrefix, err = reader.ReadLine(); err != nil {
-				break
+		str := line.Str
+		if line.Legacy {
+			if !legacyText && !ptr.legacy {
+				log.Println("line", index, "is in the legacy text format, converting to logv2")
 			}
-			str += string(bbuf)
+			legacyText = true
 		}
-
-		doc := Logv2Info{}
-		if err = bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
-			log.Println("line", index, err)
+		if line.Err != nil {
+			if ptr.quarantine == nil {
+				log.Println("line", index, line.Err)
+			}
+			ptr.quarantineLine(index, str, line.Err)
+			if ptr.metrics != nil {
+				ptr.metrics.AddParseError(ptr.hatchetName)
+			}
 			continue
-		}
-
-		if err = AddLegacyString(&doc); err != nil {
+		} else if line.LegacyErr != nil {
+			ptr.quarantineLine(index, str, line.LegacyErr)
+			if ptr.metrics != nil {
+				ptr.metrics.AddParseError(ptr.hatchetName)
+			}
 			continue
 		}
+		doc := line.Doc
+		stat = line.Stat
 		if ptr.buildInfo == nil && doc.Msg == "Build Info" {
 			ptr.buildInfo = doc.Attr.Map()["buildInfo"].(bson.D).Map()
 		}
 		if ptr.legacy {
-			dt := getDateTimeStr(doc.Timestamp)
-			logstr := fmt.Sprintf("%v %-2s %-8s [%v] %v", dt,
-				doc.Severity, doc.Component, doc.Context, doc.Message)
-			if !ptr.testing {
-				fmt.Println(logstr)
+			if ptr.legacyOut != nil {
+				if err = ptr.legacyOut.Add(&doc); err != nil {
+					return err
+				}
+			} else if !ptr.testing {
+				fmt.Println(GetLegacyLogString(&doc))
 			}
 			continue
 		}
-		stat, _ = AnalyzeSlowOp(&doc)
 		end = getDateTimeStr(doc.Timestamp)
 		if start == "" {
 			start = end
 		}
-		dbase.InsertLog(index, end, &doc, stat)
+		if !ptr.addStats(&doc, stat, end) && ptr.shapes.Overflow == 1 {
+			log.Printf("reached %v distinct shapes at line %v, new shapes are counted as %v, query normalization may need tuning\n",
+				ptr.maxShapes, index, SHAPE_OTHER)
+		}
+		if ptr.metrics != nil {
+			ptr.metrics.Add(ptr.hatchetName, &doc, stat)
+		}
+		if err = dbase.InsertLog(base+index, end, &doc, stat); err != nil {
+			return err
+		}
+		if ptr.jsonl != nil {
+			if err = ptr.jsonl.Add(base+index, end, &doc, stat); err != nil {
+				return err
+			}
+		}
+		if ptr.otlp != nil {
+			if err = ptr.otlp.Add(base+index, &doc, stat); err != nil {
+				log.Println("otlp", err)
+			}
+		}
 		if doc.Client != nil {
 			if (doc.Client.Accepted + doc.Client.Ended) > 0 { // record connections
-				dbase.InsertClientConn(index, &doc)
+				dbase.InsertClientConn(base+index, &doc)
 			} else if doc.Client.Driver != "" {
 				if isAppDriver(doc.Client) {
-					dbase.InsertDriver(index, &doc)
+					dbase.InsertDriver(base+index, &doc)
 				}
 			}
 		}
@@ -266,11 +564,196 @@ func (ptr *Logv2) Analyze(logname string) error {
 	if ptr.legacy {
 		return nil
 	}
+	if ptr.otlp != nil {
+		if err = ptr.otlp.Flush(); err != nil {
+			log.Println("otlp", err)
+		}
+		log.Printf("exported %v slow ops as spans to %v, %v failed\n", ptr.otlp.Exported, ptr.otlp.GetEndpoint(), ptr.otlp.Failed)
+	}
+	if err = dbase.Commit(); err != nil {
+		return err
+	}
+	if !ptr.testing {
+		fmt.Fprintf(os.Stderr, "\r                         \r")
+	}
+	if ptr.merge != nil { // stats are saved after all logs are merged
+		ptr.merge.add(ptr.hatchetName, index, start, end)
+		return nil
+	}
+	if ingest != nil {
+		if err = dbase.ResetMetaData(); err != nil {
+			return err
+		}
+	}
+	if err = ptr.saveStats(dbase, start, end); err != nil {
+		return err
+	}
+	if file != nil && follower == nil && !IsCompressedLog(logname) {
+		doc := Ingest{End: end, Head: head, Lines: base + index, Start: start,
+			Updated: time.Now().UTC().Format(time.RFC3339Nano)}
+		if doc.Logname, err = filepath.Abs(logname); err != nil {
+			return err
+		}
+		if doc.Offset = offset; ingest != nil {
+			doc.Offset += ingest.Offset
+		}
+		if err = dbase.SaveIngest(doc); err != nil {
+			return err
+		}
+	}
+	return ptr.PrintSummary()
+}
+
+// addStats adds a log to stats saved as audit data, returns false if its
+// shape is counted as SHAPE_OTHER
+func (ptr *Logv2) addStats(doc *Logv2Info, stat *OpStat, end string) bool {
+	added := ptr.shapes.Add(stat)
+	ptr.apps.Add(doc, stat)
+	ptr.cursors.Add(doc, stat)
+	ptr.oplog.Add(doc, stat)
+	if ptr.hotDocs != nil {
+		if key, ok := GetHotDocKey(doc); ok {
+			ptr.hotDocs.Add(key, doc.Attributes.WriteConflicts)
+		}
+	}
+	ptr.restarts.Add(doc, end)
+	ptr.auths.Add(doc, end)
+	ptr.admission.Add(doc, stat, end)
+	ptr.connLimits.Add(doc, end)
+	ptr.ttl.Add(doc, stat, end)
+	ptr.templates.Add(doc, stat)
+	ptr.server.Add(doc)
+	return added
+}
+
+// replayStats adds logs of a file ingested before, up to the offset, to stats
+// without inserting them, so that audit data recreated after lines appended
+// are ingested are of the whole log, as ResetMetaData removes them
+func (ptr *Logv2) replayStats(logname string, offset int64) error {
+	file, err := os.Open(logname)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	lines := NewLinePipeline(bufio.NewReader(io.LimitReader(file, offset)), ptr.workers, ptr.parseLine)
+	defer lines.Close()
+	for {
+		line, ok := lines.Next()
+		if !ok {
+			break
+		}
+		if line.Str == "" || line.Err != nil || line.LegacyErr != nil {
+			continue
+		}
+		doc := line.Doc
+		ptr.addStats(&doc, line.Stat, getDateTimeStr(doc.Timestamp))
+	}
+	return nil
+}
+
+// parseLine parses a line into a logv2 document and analyzes it if a slow
+// op, lines are parsed concurrently if more than one worker
+func (ptr *Logv2) parseLine(line *LogLine) {
+	doc := &line.Doc
+	if line.Legacy = IsLegacyLogLine(line.Str); line.Legacy { // text format before 4.4
+		line.Err = ParseLegacyLog(line.Str, ptr.location, doc)
+	} else {
+		line.Err = UnmarshalLogv2([]byte(line.Str), ptr.location, doc)
+	}
+	if line.Err != nil {
+		return
+	}
+	// original message of the legacy text format, msg of lines not converted
+	// is the same free text
+	message := doc.Message
+	text := line.Legacy && doc.Msg == doc.Message
+	if ptr.redact {
+		if RedactLiterals(doc) {
+			message = "" // of literals
+		} else {
+			message = RedactMessage(message)
+		}
+		if text {
+			doc.Msg = RedactMessage(doc.Msg)
+		}
+	}
+	if ptr.anonymizer != nil {
+		if ptr.anonymizer.Anonymize(doc) {
+			message = "" // of names
+		} else {
+			message = ptr.anonymizer.AnonymizeText(message)
+		}
+		if text {
+			doc.Msg = ptr.anonymizer.AnonymizeText(doc.Msg)
+		}
+	}
+	if line.LegacyErr = AddLegacyString(doc); line.LegacyErr != nil {
+		return
+	}
+	if message != "" {
+		doc.Message = message
+	}
+	if ptr.storeRaw {
+		doc.Raw = line.Str
+	}
+	doc.Source = ptr.source
+	if !ptr.legacy {
+		line.Stat, _ = AnalyzeSlowOp(doc)
+		if _, ok := GetReplanReason(doc); ok && doc.Attributes.NS == "" {
+			doc.Attributes.NS = GetReplanNamespace(doc)
+		} else if ns, _, _, ok := GetTTLDelete(doc); ok && doc.Attributes.NS == "" {
+			doc.Attributes.NS = ns
+		} else if ns, _, _, ok := GetChunkEvent(doc); ok && doc.Attributes.NS == "" {
+			doc.Attributes.NS = ns
+		}
+	}
+}
+
+// refresh commits logs of a followed log so far and updates its stats
+func (ptr *Logv2) refresh(dbase Database, start string, end string) error {
+	var err error
+	if ptr.otlp != nil {
+		if err = ptr.otlp.Flush(); err != nil {
+			log.Println("otlp", err)
+		}
+	}
+	if ptr.quarantine != nil {
+		if err = ptr.quarantine.Flush(); err != nil {
+			log.Println("quarantine", err)
+		}
+	}
+	if ptr.jsonl != nil {
+		if err = ptr.jsonl.Flush(); err != nil {
+			log.Println("jsonl", err)
+		}
+	}
 	if err = dbase.Commit(); err != nil {
 		return err
 	}
-	info := HatchetInfo{Start: start, End: end}
-	if ptr.buildInfo != nil {
+	if err = dbase.ResetMetaData(); err != nil {
+		return err
+	}
+	if err = ptr.saveStats(dbase, start, end); err != nil {
+		return err
+	}
+	if err = dbase.Resume(); err != nil {
+		return err
+	}
+	if ptr.live != nil {
+		ptr.live.Refreshed(ptr.hatchetName)
+	}
+	return nil
+}
+
+// saveStats saves hatchet info, op shapes, and audit data of logs committed
+func (ptr *Logv2) saveStats(dbase Database, start string, end string) error {
+	var err error
+	thresholds := ptr.GetSlowThresholds()
+	info := HatchetInfo{Start: start, End: end, SlowMilli: thresholds.Default, SlowNS: thresholds.Spec}
+	if ptr.buildInfo == nil { // e.g. lines appended to a log ingested before
+		saved := dbase.GetHatchetInfo()
+		info.Arch, info.Module, info.OS, info.Version = saved.Arch, saved.Module, saved.OS, saved.Version
+	} else {
 		if ptr.buildInfo["environment"] != nil {
 			env := ptr.buildInfo["environment"].(bson.D).Map()
 			info.Arch, _ = env["distarch"].(string)
@@ -289,10 +772,71 @@ func (ptr *Logv2) Analyze(logname string) error {
 	if err = dbase.CreateMetaData(); err != nil {
 		return err
 	}
-	if !ptr.testing && !ptr.legacy {
-		fmt.Fprintf(os.Stderr, "\r                         \r")
+	if err = ptr.insertCursorStats(dbase); err != nil {
+		return err
+	}
+	if ptr.hotDocs != nil {
+		if err = ptr.insertHotDocs(dbase); err != nil {
+			return err
+		}
+	}
+	if data := ptr.oplog.GetAuditData(); len(data) > 0 {
+		if err = dbase.InsertAuditData("oplog", data); err != nil {
+			return err
+		}
+	}
+	churn := ptr.oplog.GetChurnAuditData()
+	for _, category := range OPLOG_CHURN_SERIES {
+		if len(churn[category]) == 0 {
+			continue
+		}
+		if err = dbase.InsertAuditData(category, churn[category]); err != nil {
+			return err
+		}
+	}
+	if ptr.shapes.Overflow > 0 {
+		log.Printf("%v ops of shapes beyond the %v limit were counted as %v\n", ptr.shapes.Overflow, ptr.maxShapes, SHAPE_OTHER)
+		data := []NameValue{{"max", ptr.maxShapes}, {"overflow", ptr.shapes.Overflow}}
+		if err = dbase.InsertAuditData("shapes", data); err != nil {
+			return err
+		}
+	}
+	if err = ptr.insertAuthFailures(dbase); err != nil {
+		return err
+	}
+	if err = ptr.insertAdmissionStats(dbase); err != nil {
+		return err
+	}
+	if err = ptr.insertConnLimits(dbase); err != nil {
+		return err
+	}
+	if err = ptr.insertTTLStats(dbase); err != nil {
+		return err
+	}
+	if err = ptr.insertLogTemplates(dbase); err != nil {
+		return err
+	}
+	if data := ptr.server.GetAuditData(); len(data) > 0 {
+		if err = dbase.InsertAuditData("server", data); err != nil {
+			return err
+		}
+	}
+	if len(ptr.restarts.Restarts) > 0 {
+		if err = dbase.InsertAuditData("restart", ptr.restarts.Restarts); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// quarantineLine writes a skipped line to the quarantine file if enabled
+func (ptr *Logv2) quarantineLine(index int, line string, err error) {
+	if ptr.quarantine == nil {
+		return
+	}
+	if werr := ptr.quarantine.Add(ptr.logname, index, line, err); werr != nil {
+		log.Println("quarantine", werr)
 	}
-	return ptr.PrintSummary()
 }
 
 func (ptr *Logv2) PrintSummary() error {
@@ -376,6 +920,131 @@ func (ptr *Logv2) PrintSummary() error {
 	return err
 }
 
+// insertHotDocs saves hot documents and their write conflicts to audit data
+func (ptr *Logv2) insertHotDocs(dbase Database) error {
+	var err error
+	counts := []NameValue{}
+	conflicts := []NameValue{}
+	for _, doc := range ptr.hotDocs.GetHotDocs(ptr.hotDocThreshold, LIMIT) {
+		counts = append(counts, NameValue{doc.Key, doc.Count})
+		conflicts = append(conflicts, NameValue{doc.Key, doc.WriteConflicts})
+	}
+	if len(counts) == 0 {
+		return err
+	}
+	if err = dbase.InsertAuditData("hotdoc", counts); err != nil {
+		return err
+	}
+	return dbase.InsertAuditData("hotdoc-wc", conflicts)
+}
+
+// insertAuthFailures saves failed authentications by source IP, user, error,
+// and spike minute
+func (ptr *Logv2) insertAuthFailures(dbase Database) error {
+	data := ptr.auths.GetAuditData()
+	for _, category := range []string{"auth-ip", "auth-ip-users", "auth-user", "auth-user-scram", "auth-error", "auth-spike"} {
+		if len(data[category]) == 0 {
+			continue
+		}
+		if err := dbase.InsertAuditData(category, data[category]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertAdmissionStats saves minutes of flow control or ticket exhaustion
+// with latency of slow ops
+func (ptr *Logv2) insertAdmissionStats(dbase Database) error {
+	data := ptr.admission.GetAuditData()
+	for _, category := range AUDIT_SERIES["admission"] {
+		if len(data[category]) == 0 {
+			continue
+		}
+		if err := dbase.InsertAuditData(category, data[category]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertConnLimits saves minutes of refused connections, pool exhaustion,
+// or open connections near the limit, and the limit and peak connections
+func (ptr *Logv2) insertConnLimits(dbase Database) error {
+	data := ptr.connLimits.GetAuditData()
+	for _, category := range append(AUDIT_SERIES["conn-limit"], AUDIT_SERIES["conn-limit-max"]...) {
+		if len(data[category]) == 0 {
+			continue
+		}
+		if err := dbase.InsertAuditData(category, data[category]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertTTLStats saves TTL deletes by namespace with latency of slow ops in
+// minutes of passes
+func (ptr *Logv2) insertTTLStats(dbase Database) error {
+	data := ptr.ttl.GetAuditData()
+	for _, category := range AUDIT_SERIES["ttl"] {
+		if len(data[category]) == 0 {
+			continue
+		}
+		if err := dbase.InsertAuditData(category, data[category]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertLogTemplates saves templates mined from messages other than slow ops
+func (ptr *Logv2) insertLogTemplates(dbase Database) error {
+	if ptr.templates.Overflow > 0 {
+		log.Printf("%v messages beyond the %v templates limit were not mined\n", ptr.templates.Overflow, TEMPLATE_MAX_CLUSTERS)
+	}
+	data := ptr.templates.GetAuditData()
+	for _, category := range TEMPLATE_SERIES {
+		if len(data[category]) == 0 {
+			continue
+		}
+		if err := dbase.InsertAuditData(category, data[category]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertCursorStats saves CursorNotFound errors and getMore counts, and cursor
+// timeouts, leaked noCursorTimeout cursors, kills, and warnings by namespace
+func (ptr *Logv2) insertCursorStats(dbase Database) error {
+	var err error
+	notFound := []NameValue{}
+	getMores := []NameValue{}
+	for ns, count := range ptr.cursors.NotFound {
+		notFound = append(notFound, NameValue{ns, count})
+		getMores = append(getMores, NameValue{ns, ptr.cursors.GetMores[ns]})
+	}
+	if len(notFound) > 0 {
+		if err = dbase.InsertAuditData("cursor-not-found", notFound); err != nil {
+			return err
+		}
+		if err = dbase.InsertAuditData("cursor-getmore", getMores); err != nil {
+			return err
+		}
+	}
+	data := ptr.cursors.GetAuditData()
+	for _, category := range AUDIT_SERIES["cursor-timeout"] {
+		if len(data[category]) == 0 {
+			continue
+		}
+		if err = dbase.InsertAuditData(category, data[category]); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
 func isAppDriver(client *RemoteClient) bool {
 	driver := client.Driver
 	version := client.Version
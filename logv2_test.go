@@ -6,23 +6,18 @@
 package hatchet
 
 import (
-	"database/sql"
-	"regexp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
-
-	"github.com/mattn/go-sqlite3"
+	"time"
 )
 
 func TestAnalyze(t *testing.T) {
-	regex := func(re, s string) (bool, error) {
-		return regexp.MatchString(re, s)
-	}
-	sql.Register("sqlite3_extended",
-		&sqlite3.SQLiteDriver{
-			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
-				return conn.RegisterFunc("regexp", regex, true)
-			},
-		})
+	RegisterSQLite3Extended()
 	filename := "testdata/mongod_ops.log.gz"
 	logv2 := &Logv2{testing: true, url: SQLITE3_FILE}
 	err := logv2.Analyze(filename)
@@ -31,6 +26,64 @@ func TestAnalyze(t *testing.T) {
 	}
 }
 
+func TestAnalyzeIncremental(t *testing.T) {
+	RegisterSQLite3Extended()
+	saved := instance
+	defer func() { instance = saved }()
+	first := []string{
+		`{"t":{"$date":"2023-01-01T00:00:00.000+00:00"},"s":"I","c":"CONTROL","id":4615611,"ctx":"initandlisten","msg":"MongoDB starting","attr":{"pid":100,"port":27017,"dbPath":"/data/db","architecture":"64-bit","host":"db1"}}`,
+		`{"t":{"$date":"2023-01-01T00:00:10.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders","filter":{"status":"A"}},"planSummary":"COLLSCAN","durationMillis":100}}`,
+		`{"t":{"$date":"2023-01-01T00:00:20.000+00:00"},"s":"I","c":"ACCESS","id":20249,"ctx":"conn2","msg":"Authentication failed","attr":{"mechanism":"SCRAM-SHA-256","principalName":"app","authenticationDatabase":"admin","remote":"10.9.9.9:50001","error":"AuthenticationFailed: SCRAM authentication failed, storedKey mismatch"}}`,
+	}
+	second := []string{
+		`{"t":{"$date":"2023-01-01T00:01:00.000+00:00"},"s":"I","c":"CONTROL","id":4615611,"ctx":"initandlisten","msg":"MongoDB starting","attr":{"pid":200,"port":27017,"dbPath":"/data/db","architecture":"64-bit","host":"db1"}}`,
+		`{"t":{"$date":"2023-01-01T00:01:10.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.users","command":{"find":"users","filter":{"email":"a"}},"planSummary":"COLLSCAN","durationMillis":300}}`,
+		`{"t":{"$date":"2023-01-01T00:01:20.000+00:00"},"s":"I","c":"ACCESS","id":20249,"ctx":"conn2","msg":"Authentication failed","attr":{"mechanism":"SCRAM-SHA-256","principalName":"app","authenticationDatabase":"admin","remote":"10.9.9.9:50002","error":"AuthenticationFailed: SCRAM authentication failed, storedKey mismatch"}}`,
+	}
+	analyze := func(dir string, lines []string) map[string][]string {
+		filename := filepath.Join(dir, "mongod.log")
+		file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintln(file, strings.Join(lines, "\n"))
+		file.Close()
+		logv2 := &Logv2{testing: true, url: filepath.Join(dir, "hatchet.db"), incremental: true, maxShapes: 1}
+		instance = logv2
+		if err = logv2.Analyze(filename); err != nil {
+			t.Fatal(err)
+		}
+		dbase, err := GetDatabase(logv2.hatchetName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer dbase.Close()
+		data, err := dbase.GetAuditData()
+		if err != nil {
+			t.Fatal(err)
+		}
+		audit := map[string][]string{} // of rows in order
+		for category, docs := range data {
+			for _, doc := range docs {
+				audit[category] = append(audit[category], fmt.Sprint(doc.Name, doc.Values))
+			}
+			sort.Strings(audit[category])
+		}
+		return audit
+	}
+
+	dir := t.TempDir()
+	analyze(dir, first)
+	audit := analyze(dir, second) // lines appended
+	expected := analyze(t.TempDir(), append(append([]string{}, first...), second...))
+	if !reflect.DeepEqual(audit, expected) {
+		t.Fatal("expected", expected, "but got", audit)
+	}
+	if len(audit["restart"]) != 1 || len(audit["shapes"]) != 2 || !strings.HasPrefix(audit["auth-ip"][0], "10.9.9.9[2 ") {
+		t.Fatal("expected", "a restart, shapes overflow, and 2 auth failures", "but got", audit)
+	}
+}
+
 func TestAnalyzeLegacy(t *testing.T) {
 	filename := "testdata/mongod_ops.log.gz"
 	logv2 := &Logv2{testing: true, legacy: true}
@@ -39,3 +92,65 @@ func TestAnalyzeLegacy(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestUnmarshalLogv2WithOffset(t *testing.T) {
+	str := `{"t":{"$date":"2021-07-25T09:38:57.078+05:00"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted"}`
+	loc, _ := time.LoadLocation("America/New_York")
+	doc := Logv2Info{}
+	if err := UnmarshalLogv2([]byte(str), loc, &doc); err != nil {
+		t.Fatal(err)
+	}
+	expected := "2021-07-25T04:38:57.078-0000"
+	if dt := getDateTimeStr(doc.Timestamp); dt != expected {
+		t.Fatal("expected", expected, "but got", dt)
+	}
+}
+
+func TestUnmarshalLogv2AssumeTZ(t *testing.T) {
+	str := `{"t":{"$date":"2021-07-25T09:38:57.078"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted"}`
+	loc, _ := time.LoadLocation("America/New_York")
+	doc := Logv2Info{}
+	if err := UnmarshalLogv2([]byte(str), loc, &doc); err != nil {
+		t.Fatal(err)
+	}
+	expected := "2021-07-25T13:38:57.078-0000"
+	if dt := getDateTimeStr(doc.Timestamp); dt != expected {
+		t.Fatal("expected", expected, "but got", dt)
+	}
+	if doc.Msg != "Connection accepted" {
+		t.Fatal("expected", "Connection accepted", "but got", doc.Msg)
+	}
+
+	doc = Logv2Info{}
+	if err := UnmarshalLogv2([]byte(str), nil, &doc); err != nil {
+		t.Fatal(err)
+	}
+	expected = "2021-07-25T09:38:57.078-0000"
+	if dt := getDateTimeStr(doc.Timestamp); dt != expected {
+		t.Fatal("expected", expected, "but got", dt)
+	}
+}
+
+func TestGetDBType(t *testing.T) {
+	urls := map[string]int{"data/hatchet.db": SQLite3, "mongodb://localhost/logdb": Mongo,
+		"mongodb+srv://cluster0.example.net/logdb": Mongo}
+	for url, expected := range urls {
+		if dbType := (&Logv2{url: url}).GetDBType(); dbType != expected {
+			t.Fatal("expected", expected, "but got", dbType, "for", url)
+		}
+	}
+	if dbType := (&Logv2{url: "data/hatchet.db", backend: "mongodb"}).GetDBType(); dbType != Mongo {
+		t.Fatal("expected", Mongo, "but got", dbType)
+	}
+	for _, logv2 := range []*Logv2{{url: "postgres://localhost/hatchet"}, {url: "postgresql://localhost/hatchet"},
+		{url: "clickhouse://localhost:9000/hatchet"},
+		{url: "data/hatchet.db", backend: "postgres"}, {url: "data/hatchet.db", backend: "duckdb"},
+		{url: "data/hatchet.db", backend: "oracle"}} {
+		if err := logv2.CheckDBType(); err == nil {
+			t.Fatal("expected", "error", "but got", nil, "for", logv2.url, logv2.backend)
+		}
+	}
+	if err := (&Logv2{url: "mongodb://localhost/logdb"}).CheckDBType(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * metrics.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HatchetMetrics counts ingested lines, parse errors, ops, slow ops,
+// connections, and messages by severity of a hatchet
+type HatchetMetrics struct {
+	Accepted    int            `json:"accepted"`
+	Conns       int            `json:"conns"` // open connections of the last connection log
+	Lines       int            `json:"lines"` // lines ingested, including parse errors
+	Milli       map[string]int `json:"milli"` // milliseconds of ops by op
+	Ops         map[string]int `json:"ops"`
+	ParseErrors int            `json:"parse_errors"`
+	Severities  map[string]int `json:"severities"`
+	SlowOps     map[string]int `json:"slow_ops"`  // ops at or above the slow threshold of the namespace by op
+	Timestamp   int64          `json:"timestamp"` // seconds since epoch of the last log
+}
+
+// Metrics keeps metrics of hatchets ingested by this process to be scraped
+// by Prometheus from /metrics
+type Metrics struct {
+	hatchets map[string]*HatchetMetrics
+	mutex    sync.Mutex
+}
+
+// NewMetrics returns Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{hatchets: map[string]*HatchetMetrics{}}
+}
+
+// Add counts an ingested log of a hatchet
+func (ptr *Metrics) Add(hatchetName string, doc *Logv2Info, stat *OpStat) {
+	ptr.mutex.Lock()
+	defer ptr.mutex.Unlock()
+	metrics := ptr.get(hatchetName)
+	metrics.Lines++
+	metrics.Severities[doc.Severity]++
+	metrics.Timestamp = doc.Timestamp.Unix()
+	if doc.Client != nil && doc.Client.Accepted+doc.Client.Ended > 0 {
+		metrics.Accepted += doc.Client.Accepted
+		metrics.Conns = doc.Client.Conns
+	}
+	if stat == nil || stat.Op == "" {
+		return
+	}
+	metrics.Ops[stat.Op]++
+	metrics.Milli[stat.Op] += doc.Attributes.Milli
+	if doc.Attributes.Milli >= GetLogv2().GetSlowThresholds().Get(stat.Namespace) {
+		metrics.SlowOps[stat.Op]++
+	}
+}
+
+// AddParseError counts a line of a hatchet failed to parse
+func (ptr *Metrics) AddParseError(hatchetName string) {
+	ptr.mutex.Lock()
+	defer ptr.mutex.Unlock()
+	metrics := ptr.get(hatchetName)
+	metrics.Lines++
+	metrics.ParseErrors++
+}
+
+// WriteTo writes metrics in the Prometheus text exposition format
+func (ptr *Metrics) WriteTo(w io.Writer) (int64, error) {
+	ptr.mutex.Lock()
+	defer ptr.mutex.Unlock()
+	names := []string{}
+	for name := range ptr.hatchets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf strings.Builder
+	writeMetric := func(name string, kind string, help string, value func(m *HatchetMetrics) int64) {
+		fmt.Fprintf(&buf, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, kind)
+		for _, hatchetName := range names {
+			fmt.Fprintf(&buf, "%v{hatchet=\"%v\"} %v\n", name, escapeLabel(hatchetName), value(ptr.hatchets[hatchetName]))
+		}
+	}
+	writeMetrics := func(name string, kind string, help string, label string, values func(m *HatchetMetrics) map[string]int) {
+		fmt.Fprintf(&buf, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, kind)
+		for _, hatchetName := range names {
+			counts := values(ptr.hatchets[hatchetName])
+			keys := []string{}
+			for key := range counts {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fmt.Fprintf(&buf, "%v{hatchet=\"%v\",%v=\"%v\"} %v\n", name, escapeLabel(hatchetName), label,
+					escapeLabel(key), counts[key])
+			}
+		}
+	}
+	writeMetric("hatchet_lines_total", "counter", "Log lines ingested.",
+		func(m *HatchetMetrics) int64 { return int64(m.Lines) })
+	writeMetric("hatchet_parse_errors_total", "counter", "Log lines failed to parse.",
+		func(m *HatchetMetrics) int64 { return int64(m.ParseErrors) })
+	writeMetrics("hatchet_messages_total", "counter", "Log messages by severity.", "severity",
+		func(m *HatchetMetrics) map[string]int { return m.Severities })
+	writeMetrics("hatchet_ops_total", "counter", "Logged ops by op.", "op",
+		func(m *HatchetMetrics) map[string]int { return m.Ops })
+	writeMetrics("hatchet_ops_milliseconds_total", "counter", "Milliseconds of logged ops by op.", "op",
+		func(m *HatchetMetrics) map[string]int { return m.Milli })
+	writeMetrics("hatchet_slow_ops_total", "counter", "Logged ops at or above the slow threshold by op.", "op",
+		func(m *HatchetMetrics) map[string]int { return m.SlowOps })
+	writeMetric("hatchet_connections_accepted_total", "counter", "Connections accepted.",
+		func(m *HatchetMetrics) int64 { return int64(m.Accepted) })
+	writeMetric("hatchet_connections", "gauge", "Open connections of the last connection log.",
+		func(m *HatchetMetrics) int64 { return int64(m.Conns) })
+	writeMetric("hatchet_last_log_timestamp_seconds", "gauge", "Timestamp of the last log ingested.",
+		func(m *HatchetMetrics) int64 { return m.Timestamp })
+	n, err := io.WriteString(w, buf.String())
+	return int64(n), err
+}
+
+// Get returns a copy of metrics of a hatchet, zeros if not ingested
+func (ptr *Metrics) Get(hatchetName string) HatchetMetrics {
+	ptr.mutex.Lock()
+	defer ptr.mutex.Unlock()
+	metrics := HatchetMetrics{}
+	if m := ptr.hatchets[hatchetName]; m != nil {
+		metrics = *m
+	}
+	for _, m := range []*map[string]int{&metrics.Milli, &metrics.Ops, &metrics.Severities, &metrics.SlowOps} {
+		values := map[string]int{}
+		for k, v := range *m {
+			values[k] = v
+		}
+		*m = values
+	}
+	return metrics
+}
+
+// get returns metrics of a hatchet, created if not found
+func (ptr *Metrics) get(hatchetName string) *HatchetMetrics {
+	metrics := ptr.hatchets[hatchetName]
+	if metrics == nil {
+		metrics = &HatchetMetrics{Milli: map[string]int{}, Ops: map[string]int{}, Severities: map[string]int{},
+			SlowOps: map[string]int{}}
+		ptr.hatchets[hatchetName] = metrics
+	}
+	return metrics
+}
+
+// escapeLabel escapes a label value of the text exposition format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * metrics_handler.go
+ */
+
+package hatchet
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// MetricsHandler responds to Prometheus scrapes
+func MetricsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	/** APIs
+	 * /metrics
+	 */
+	if GetLogv2().verbose {
+		log.Println("MetricsHandler", r.URL.Path)
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics := GetLogv2().metrics
+	if metrics == nil {
+		metrics = NewMetrics()
+	}
+	if _, err := metrics.WriteTo(w); err != nil {
+		log.Println("metrics", err)
+	}
+}
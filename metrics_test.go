@@ -0,0 +1,39 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * metrics_test.go
+ */
+
+package hatchet
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+	doc := Logv2Info{Severity: "I", Timestamp: time.Unix(1672531200, 0)}
+	doc.Attributes.Milli = SLOW_MS
+	metrics.Add("mongod", &doc, &OpStat{Op: "find", Namespace: "shop.orders"})
+	doc.Attributes.Milli = 10
+	metrics.Add("mongod", &doc, &OpStat{Op: "find", Namespace: "shop.orders"})
+	doc = Logv2Info{Severity: "W", Timestamp: time.Unix(1672531260, 0), Client: &RemoteClient{Accepted: 1, Conns: 12}}
+	metrics.Add("mongod", &doc, &OpStat{})
+	metrics.AddParseError("mongod")
+	var buf strings.Builder
+	if _, err := metrics.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{`hatchet_lines_total{hatchet="mongod"} 4`, `hatchet_parse_errors_total{hatchet="mongod"} 1`,
+		`hatchet_ops_total{hatchet="mongod",op="find"} 2`, `hatchet_slow_ops_total{hatchet="mongod",op="find"} 1`,
+		`hatchet_messages_total{hatchet="mongod",severity="W"} 1`, `hatchet_connections{hatchet="mongod"} 12`,
+		`hatchet_last_log_timestamp_seconds{hatchet="mongod"} 1672531260`, "# TYPE hatchet_connections gauge"} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Fatal("expected", line, "but got", buf.String())
+		}
+	}
+	if expected := `a\"b\\c`; escapeLabel(`a"b\c`) != expected {
+		t.Fatal("expected", expected, "but got", escapeLabel(`a"b\c`))
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * migrations.go
+ */
+
+package hatchet
+
+import (
+	"log"
+	"strings"
+)
+
+const (
+	HATCHET_TABLE    = "hatchet"
+	MIGRATIONS_TABLE = "hatchet_migrations"
+)
+
+// MIGRATION_CREATED is the description of the schema version recorded when a
+// hatchet is created
+const MIGRATION_CREATED = "created"
+
+// MigrationColumn is a column added to a table of a hatchet, Table is the
+// suffix of the table name, e.g. _ops, empty for the logs table, or hatchet
+// for the metadata table shared by hatchets
+type MigrationColumn struct {
+	Table string
+	Name  string
+	Type  string
+}
+
+// Migration brings tables of a hatchet to a schema version by adding missing
+// columns and backfilling them, %[1]v of Backfill is the hatchet name
+type Migration struct {
+	Version     int
+	Description string
+	Columns     []MigrationColumn
+	Backfill    string
+}
+
+// MigrationRecord is a migration applied to a hatchet
+type MigrationRecord struct {
+	Version     int    `json:"version" bson:"version"`
+	Description string `json:"description" bson:"description"`
+	Applied     string `json:"applied" bson:"applied"`
+}
+
+// MIGRATIONS are forward migrations in order, hatchets created before
+// migrations were recorded are of version 1
+var MIGRATIONS = []Migration{
+	{Version: 2, Description: "add first and last seen of op shapes",
+		Columns: []MigrationColumn{{"_ops", "first_seen", "text"}, {"_ops", "last_seen", "text"}},
+		Backfill: `UPDATE %[1]v_ops SET
+			first_seen = (SELECT MIN(date) FROM %[1]v l WHERE l.op = %[1]v_ops.op AND l.ns = %[1]v_ops.ns
+				AND l.filter = %[1]v_ops.filter AND l._index = %[1]v_ops._index),
+			last_seen = (SELECT MAX(date) FROM %[1]v l WHERE l.op = %[1]v_ops.op AND l.ns = %[1]v_ops.ns
+				AND l.filter = %[1]v_ops.filter AND l._index = %[1]v_ops._index)
+			WHERE first_seen IS NULL;`},
+	{Version: 3, Description: "add ticket wait",
+		Columns: []MigrationColumn{{"", "ticket_wait", "integer"}}},
+	{Version: 4, Description: "add write counters",
+		Columns: []MigrationColumn{{"", "n_matched", "integer"}, {"", "n_modified", "integer"},
+			{"", "n_inserted", "integer"}, {"", "n_upserted", "integer"}, {"", "n_deleted", "integer"}}},
+	{Version: 5, Description: "add sort shapes",
+		Columns:  []MigrationColumn{{"", "sort", "text"}, {"_ops", "sort", "text"}},
+		Backfill: `UPDATE %[1]v_ops SET sort = '' WHERE sort IS NULL;`},
+	{Version: 6, Description: "add lock acquire counts",
+		Columns: []MigrationColumn{{"", "lock_global_r", "integer"}, {"", "lock_global_w", "integer"},
+			{"", "lock_database_r", "integer"}, {"", "lock_database_w", "integer"},
+			{"", "lock_collection_r", "integer"}, {"", "lock_collection_w", "integer"}}},
+	{Version: 7, Description: "add planning time",
+		Columns: []MigrationColumn{{"", "planning_micros", "integer"}}},
+	{Version: 8, Description: "add raw log lines",
+		Columns: []MigrationColumn{{"", "raw", "text"}}},
+	{Version: 9, Description: "add shard targeting",
+		Columns: []MigrationColumn{{"", "n_shards", "integer"}, {"", "shards", "text"}}},
+	{Version: 10, Description: "add log sources",
+		Columns: []MigrationColumn{{"", "source", "text"}}},
+	{Version: 11, Description: "add replan reasons",
+		Columns: []MigrationColumn{{"", "replan_reason", "text"}}},
+	{Version: 12, Description: "add aggregation stages",
+		Columns: []MigrationColumn{{"", "stages", "text"}}},
+	{Version: 13, Description: "add application names",
+		Columns: []MigrationColumn{{"", "app_name", "text"}}},
+	{Version: 14, Description: "add replication lag",
+		Columns: []MigrationColumn{{"", "repl_lag_ms", "integer"}}},
+	{Version: 15, Description: "add WiredTiger events",
+		Columns: []MigrationColumn{{"", "wt_event", "text"}, {"", "wt_checkpoint_ms", "integer"}}},
+	{Version: 16, Description: "add latency percentiles",
+		Columns: []MigrationColumn{{"_ops", "p50_ms", "integer"}, {"_ops", "p95_ms", "integer"}, {"_ops", "p99_ms", "integer"}},
+		Backfill: `UPDATE %[1]v_ops SET p50_ms = p.p50_ms, p95_ms = p.p95_ms, p99_ms = p.p99_ms
+			FROM (SELECT op, ns, filter, IFNULL(sort, '') sort, _index, ` + GetSQLPercentiles() + `
+				FROM ` + GetSQLRankedOps("%[1]v", "op, ns, filter, sort, _index") + ` GROUP BY op, ns, filter, sort, _index) p
+			WHERE p.op = %[1]v_ops.op AND p.ns = %[1]v_ops.ns AND p.filter = %[1]v_ops.filter
+				AND p.sort = IFNULL(%[1]v_ops.sort, '') AND p._index = %[1]v_ops._index;
+			` + GetSQLLatencyAudit("%[1]v")},
+	{Version: 17, Description: "add lock waits",
+		Columns: []MigrationColumn{{"", "lock_wait_count", "integer"}, {"", "lock_wait_micros", "integer"}}},
+	{Version: 18, Description: "add TTL deletes",
+		Columns: []MigrationColumn{{"", "ttl_deleted", "integer"}, {"", "ttl_ms", "integer"}}},
+	{Version: 19, Description: "add chunk migrations",
+		Columns: []MigrationColumn{{"", "chunk_event", "text"}, {"", "chunk_ms", "integer"}}},
+	{Version: 20, Description: "add transactions",
+		Columns: []MigrationColumn{{"", "txn_result", "text"}, {"", "txn_cause", "text"}, {"", "txn_ms", "integer"},
+			{"", "txn_ops", "integer"}}},
+	{Version: 21, Description: "add log id",
+		Columns: []MigrationColumn{{"", "log_id", "integer"}}},
+	{Version: 22, Description: "add runtime configuration changes",
+		Columns: []MigrationColumn{{"", "config_change", "text"}, {"", "config_name", "text"}, {"", "config_old", "text"},
+			{"", "config_new", "text"}}},
+	{Version: 23, Description: "add keys and documents examined and returned",
+		Columns: []MigrationColumn{{"", "keys_examined", "integer"}, {"", "docs_examined", "integer"}, {"", "nreturned", "integer"}}},
+	{Version: 24, Description: "add blocking sorts and spills to disk",
+		Columns: []MigrationColumn{{"", "has_sort_stage", "integer"}, {"", "used_disk", "integer"}}},
+	{Version: 25, Description: "add originating ops and cursor ids of getMores",
+		Columns: []MigrationColumn{{"", "originating_op", "text"}, {"", "cursor_id", "integer"}}},
+	{Version: 26, Description: "add storage reads",
+		Columns: []MigrationColumn{{"", "bytes_read", "integer"}, {"", "read_micros", "integer"}}},
+	{Version: 27, Description: "add slow thresholds",
+		Columns: []MigrationColumn{{HATCHET_TABLE, "slow_ms", "integer"}, {HATCHET_TABLE, "slow_ns", "text"}}},
+}
+
+// DB_SCHEMA_VERSION is the schema version of hatchets created
+var DB_SCHEMA_VERSION = MIGRATIONS[len(MIGRATIONS)-1].Version
+
+// GetPendingMigrations returns migrations after a version
+func GetPendingMigrations(version int) []Migration {
+	migrations := []Migration{}
+	for _, migration := range MIGRATIONS {
+		if migration.Version > version {
+			migrations = append(migrations, migration)
+		}
+	}
+	return migrations
+}
+
+// MigrateHatchets applies pending migrations to all hatchets
+func MigrateHatchets() error {
+	dbase, err := GetDatabase("")
+	if err != nil {
+		return err
+	}
+	names, err := dbase.GetHatchetNames()
+	dbase.Close()
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") { // new database
+			return nil
+		}
+		return err
+	}
+	for _, name := range names {
+		if dbase, err = GetDatabase(name); err != nil {
+			return err
+		}
+		applied, err := dbase.Migrate()
+		dbase.Close()
+		if err != nil {
+			return err
+		}
+		for _, migration := range applied {
+			log.Printf("migrated hatchet %v to schema version %v, %v\n", name, migration.Version, migration.Description)
+		}
+	}
+	return nil
+}
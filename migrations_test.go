@@ -0,0 +1,41 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * migrations_test.go
+ */
+
+package hatchet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	columns := map[string]bool{}
+	for _, word := range strings.FieldsFunc(GetHatchetInitStmt("test"), func(r rune) bool {
+		return r != '_' && (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}) {
+		columns[word] = true
+	}
+	version := 1
+	for _, migration := range MIGRATIONS {
+		if migration.Version != version+1 {
+			t.Fatal("expected", version+1, "but got", migration.Version)
+		}
+		version = migration.Version
+		for _, column := range migration.Columns {
+			if !columns[column.Name] {
+				t.Fatal("expected", column.Name, "in tables created but got", "none")
+			}
+		}
+	}
+	if DB_SCHEMA_VERSION != version {
+		t.Fatal("expected", version, "but got", DB_SCHEMA_VERSION)
+	}
+	if pending := GetPendingMigrations(3); len(pending) != DB_SCHEMA_VERSION-3 || pending[0].Version != 4 {
+		t.Fatal("expected", DB_SCHEMA_VERSION-3, "but got", len(pending))
+	}
+	if pending := GetPendingMigrations(DB_SCHEMA_VERSION); len(pending) != 0 {
+		t.Fatal("expected", 0, "but got", len(pending))
+	}
+}
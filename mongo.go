@@ -7,8 +7,10 @@ package hatchet
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/url"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -17,10 +19,11 @@ import (
 
 const (
 	MAX_DOC_SIZE = 16 * (1024 * 1024)
-	BATCH_SIZE   = 1000
+	BATCH_SIZE   = 1000 // default documents of an insertMany
 )
 
 type MongoDB struct {
+	batchSize   int
 	db          *mongo.Database
 	hatchetName string
 	url         string
@@ -33,7 +36,7 @@ type MongoDB struct {
 
 func NewMongoDB(connstr string, hatchetName string) (*MongoDB, error) {
 	var err error
-	mongodb := &MongoDB{url: connstr, hatchetName: hatchetName}
+	mongodb := &MongoDB{url: connstr, hatchetName: hatchetName, batchSize: BATCH_SIZE}
 	clientOptions := options.Client().ApplyURI(connstr)
 	client, err := mongo.Connect(context.Background(), clientOptions)
 	if err != nil {
@@ -56,6 +59,14 @@ func (ptr *MongoDB) SetVerbose(b bool) {
 	ptr.verbose = b
 }
 
+// SetBatchSize sets documents of an insertMany, BATCH_SIZE if not positive
+func (ptr *MongoDB) SetBatchSize(size int) {
+	if size <= 0 {
+		size = BATCH_SIZE
+	}
+	ptr.batchSize = size
+}
+
 func (ptr *MongoDB) Begin() error {
 	var err error
 	log.Println("creating hatchet", ptr.hatchetName)
@@ -65,6 +76,8 @@ func (ptr *MongoDB) Begin() error {
 		{{Key: "context", Value: 1}, {Key: "date", Value: 1}},
 		{{Key: "severity", Value: 1}},
 		{{Key: "op", Value: 1}, {Key: "ns", Value: 1}, {Key: "filter", Value: 1}},
+		{{Key: "t", Value: 1}},
+		{{Key: "message", Value: "text"}},
 	} {
 		index := mongo.IndexModel{
 			Keys:    keys,
@@ -85,26 +98,49 @@ func (ptr *MongoDB) Begin() error {
 	if err != nil {
 		return err
 	}
-
-	return err
+	return ptr.recordSchemaVersion()
 }
 
+// Commit inserts buffered documents
 func (ptr *MongoDB) Commit() error {
+	ctx := context.Background()
 	if len(ptr.logs) > 0 {
-		ptr.db.Collection(ptr.hatchetName).InsertMany(context.Background(), ptr.logs)
+		if _, err := ptr.db.Collection(ptr.hatchetName).InsertMany(ctx, ptr.logs); err != nil {
+			return err
+		}
 		ptr.logs = []interface{}{}
 	}
 	if len(ptr.clients) > 0 {
-		ptr.db.Collection(ptr.hatchetName+"_clients").InsertMany(context.Background(), ptr.clients)
+		if _, err := ptr.db.Collection(ptr.hatchetName+"_clients").InsertMany(ctx, ptr.clients); err != nil {
+			return err
+		}
 		ptr.clients = []interface{}{}
 	}
 	if len(ptr.drivers) > 0 {
-		ptr.db.Collection(ptr.hatchetName+"_drivers").InsertMany(context.Background(), ptr.drivers)
+		if _, err := ptr.db.Collection(ptr.hatchetName+"_drivers").InsertMany(ctx, ptr.drivers); err != nil {
+			return err
+		}
 		ptr.drivers = []interface{}{}
 	}
 	return nil
 }
 
+// Resume continues inserting logs of a hatchet already created, logs are
+// buffered and inserted by Commit
+func (ptr *MongoDB) Resume() error {
+	return nil
+}
+
+// ResetMetaData removes op shapes and audit data before they are recreated
+func (ptr *MongoDB) ResetMetaData() error {
+	ctx := context.Background()
+	if _, err := ptr.db.Collection(ptr.hatchetName+"_ops").DeleteMany(ctx, bson.M{}); err != nil {
+		return err
+	}
+	_, err := ptr.db.Collection(ptr.hatchetName+"_audit").DeleteMany(ctx, bson.M{})
+	return err
+}
+
 func (ptr *MongoDB) Close() error {
 	var err error
 	defer ptr.db.Client().Disconnect(context.Background())
@@ -115,22 +151,21 @@ func (ptr *MongoDB) Close() error {
 func (ptr *MongoDB) Drop() error {
 	var err error
 	ptr.db.Collection(ptr.hatchetName + "_audit").Drop(context.Background())
+	ptr.db.Collection(ptr.hatchetName + "_bookmarks").Drop(context.Background())
 	ptr.db.Collection(ptr.hatchetName + "_clients").Drop(context.Background())
 	ptr.db.Collection(ptr.hatchetName + "_drivers").Drop(context.Background())
 	ptr.db.Collection(ptr.hatchetName + "_ops").Drop(context.Background())
 	ptr.db.Collection(ptr.hatchetName).Drop(context.Background())
 	ptr.db.Collection("hatchet").DeleteOne(context.Background(), bson.M{"name": ptr.hatchetName})
+	ptr.db.Collection(MIGRATIONS_TABLE).DeleteMany(context.Background(), bson.M{"name": ptr.hatchetName})
+	ptr.db.Collection(INGESTS_TABLE).DeleteOne(context.Background(), bson.M{"_id": ptr.hatchetName})
 	return err
 }
 
 func (ptr *MongoDB) InsertLog(index int, end string, doc *Logv2Info, stat *OpStat) error {
 	var err error
-	data := bson.M{
-		"_id": index, "date": end, "severity": doc.Severity, "component": doc.Component, "context": doc.Context,
-		"msg": doc.Msg, "plan": doc.Attributes.PlanSummary, "type": doc.Attr.Map()["type"], "ns": doc.Attributes.NS, "message": doc.Message,
-		"op": stat.Op, "filter": stat.QueryPattern, "_index": stat.Index, "milli": doc.Attributes.Milli, "reslen": doc.Attributes.Reslen}
-	ptr.logs = append(ptr.logs, data)
-	if len(ptr.logs) > BATCH_SIZE {
+	ptr.logs = append(ptr.logs, GetLogDocument(index, end, doc, stat))
+	if len(ptr.logs) >= ptr.batchSize {
 		collName := ptr.hatchetName
 		_, err = ptr.db.Collection(collName).InsertMany(context.Background(), ptr.logs)
 		ptr.logs = []interface{}{}
@@ -138,6 +173,140 @@ func (ptr *MongoDB) InsertLog(index int, end string, doc *Logv2Info, stat *OpSta
 	return err
 }
 
+// GetLogDocument returns the document of a log line stored in MongoDB, the
+// columns of SQLite3 plus the timestamp as a date, the message id, and the
+// attributes with keys escaped
+func GetLogDocument(index int, end string, doc *Logv2Info, stat *OpStat) bson.M {
+	data := bson.M{
+		"_id": index, "date": end, "severity": doc.Severity, "component": doc.Component, "context": doc.Context,
+		"msg": doc.Msg, "plan": doc.Attributes.PlanSummary, "type": doc.Attr.Map()["type"], "ns": doc.Attributes.NS, "message": doc.Message,
+		"op": stat.Op, "filter": stat.QueryPattern, "sort": stat.SortPattern, "_index": stat.Index, "milli": doc.Attributes.Milli, "reslen": doc.Attributes.Reslen,
+		"t": doc.Timestamp, "id": doc.ID}
+	if len(doc.Attr) > 0 {
+		attr := escapeMongoKeys(doc.Attr)
+		if b, err := bson.Marshal(attr); err == nil && len(b) < MAX_DOC_SIZE/2 { // room for raw
+			data["attr"] = attr
+		}
+	}
+	if micros, ok := GetTicketWait(doc); ok {
+		data["ticket_wait"] = micros
+	}
+	if micros, ok := GetPlanningTime(doc); ok {
+		data["planning_micros"] = micros
+	}
+	if doc.Raw != "" {
+		data["raw"] = doc.Raw
+	}
+	if doc.Source != "" {
+		data["source"] = doc.Source
+	}
+	if n, names, ok := GetShardTargets(doc); ok {
+		data["n_shards"] = n
+		data["shards"] = names
+	}
+	if reason, ok := GetReplanReason(doc); ok {
+		data["replan_reason"] = reason
+	}
+	if stages, ok := GetPipelineStages(doc); ok {
+		data["stages"] = stages
+	}
+	if doc.AppName != "" {
+		data["app_name"] = doc.AppName
+	}
+	if milli, ok := GetReplicationLag(doc); ok {
+		data["repl_lag_ms"] = milli
+	}
+	if event, milli, ok := GetWiredTigerEvent(doc); ok {
+		data["wt_event"] = event
+		if event == WT_CHECKPOINT {
+			data["wt_checkpoint_ms"] = milli
+		}
+	}
+	if count, micros, ok := GetLockWait(doc); ok {
+		data["lock_wait_count"] = count
+		data["lock_wait_micros"] = micros
+	}
+	if _, deleted, milli, ok := GetTTLDelete(doc); ok {
+		data["ttl_deleted"] = deleted
+		data["ttl_ms"] = milli
+	}
+	if _, event, milli, ok := GetChunkEvent(doc); ok {
+		data["chunk_event"] = event
+		data["chunk_ms"] = milli
+	}
+	if change, ok := GetConfigChange(doc); ok {
+		data["config_change"] = change.Type
+		data["config_name"] = change.Name
+		data["config_old"] = change.OldValue
+		data["config_new"] = change.NewValue
+	}
+	if keys, docs, returned, ok := GetExamined(doc); ok && stat.Op != "" {
+		for key, value := range map[string]interface{}{"keys_examined": keys, "docs_examined": docs, "nreturned": returned} {
+			if value != nil {
+				data[key] = value
+			}
+		}
+	}
+	if sorted, spilled, ok := GetSortStage(doc); ok && stat.Op != "" {
+		data["has_sort_stage"] = getSortStageFlag(sorted)
+		data["used_disk"] = getSortStageFlag(spilled)
+	}
+	if op, id, ok := GetCursorOp(doc, stat); ok {
+		data["originating_op"] = op
+		if id != nil {
+			data["cursor_id"] = id
+		}
+	}
+	if bytes, micros, ok := GetStorageRead(doc); ok {
+		data["bytes_read"] = bytes
+		data["read_micros"] = micros
+	}
+	if txn, ok := GetTransaction(doc); ok {
+		data["txn_result"] = txn.Result
+		data["txn_ms"] = txn.Milli
+		data["txn_ops"] = txn.Ops
+		if txn.Cause != "" {
+			data["txn_cause"] = txn.Cause
+		}
+	}
+	for i, counter := range GetWriteCounters(doc) {
+		if counter != nil {
+			data[WRITE_COUNTERS[i]] = counter
+		}
+	}
+	for i, count := range GetLockCounts(doc) {
+		if count != nil {
+			data[LOCK_COUNTERS[i]] = count
+		}
+	}
+	return data
+}
+
+// escapeMongoKeys returns a copy of a document with the leading $ and dots of
+// keys replaced by full-width ＄ and ．, e.g. { filter: { qty: { ＄gt: 5 } } },
+// so operators of logged commands are stored as fields
+func escapeMongoKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.D:
+		doc := make(bson.D, 0, len(v))
+		for _, elem := range v {
+			key := strings.ReplaceAll(elem.Key, ".", "．")
+			if strings.HasPrefix(key, "$") {
+				key = "＄" + key[1:]
+			}
+			doc = append(doc, bson.E{Key: key, Value: escapeMongoKeys(elem.Value)})
+		}
+		return doc
+	case bson.A:
+		arr := make(bson.A, 0, len(v))
+		for _, elem := range v {
+			arr = append(arr, escapeMongoKeys(elem))
+		}
+		return arr
+	}
+	return value
+}
+
 func (ptr *MongoDB) InsertClientConn(index int, doc *Logv2Info) error {
 	var err error
 	client := doc.Client
@@ -145,7 +314,7 @@ func (ptr *MongoDB) InsertClientConn(index int, doc *Logv2Info) error {
 		"_id": index, "ip": client.IP, "port": client.Port, "conns": client.Conns, "accepted": client.Accepted,
 		"ended": client.Ended, "context": doc.Context}
 	ptr.clients = append(ptr.clients, data)
-	if len(ptr.clients) > BATCH_SIZE {
+	if len(ptr.clients) >= ptr.batchSize {
 		collName := ptr.hatchetName + "_clients"
 		_, err = ptr.db.Collection(collName).InsertMany(context.Background(), ptr.clients)
 		ptr.clients = []interface{}{}
@@ -159,7 +328,7 @@ func (ptr *MongoDB) InsertDriver(index int, doc *Logv2Info) error {
 	data := bson.M{
 		"_id": index, "ip": client.IP, "driver": client.Driver, "version": client.Version}
 	ptr.drivers = append(ptr.drivers, data)
-	if len(ptr.drivers) > BATCH_SIZE {
+	if len(ptr.drivers) >= ptr.batchSize {
 		collName := ptr.hatchetName + "_drivers"
 		_, err = ptr.db.Collection(collName).InsertMany(context.Background(), ptr.drivers)
 		ptr.drivers = []interface{}{}
@@ -167,54 +336,118 @@ func (ptr *MongoDB) InsertDriver(index int, doc *Logv2Info) error {
 	return err
 }
 
+// InsertAuditData inserts rows of a category into the audit collection
+func (ptr *MongoDB) InsertAuditData(category string, data []NameValue) error {
+	var err error
+	docs := []interface{}{}
+	for _, doc := range data {
+		docs = append(docs, bson.M{"type": category, "name": doc.Name, "value": doc.Value})
+	}
+	if len(docs) == 0 {
+		return err
+	}
+	_, err = ptr.db.Collection(ptr.hatchetName+"_audit").InsertMany(context.Background(), docs)
+	return err
+}
+
 func (ptr *MongoDB) UpdateHatchetInfo(info HatchetInfo) error {
 	var err error
 	filter := bson.M{"name": ptr.hatchetName}
-	update := bson.M{"$set": bson.M{"version": info.Version, "module": info.Module, "arch": info.Arch, "os": info.OS, "start": info.Start, "end": info.End}}
+	update := bson.M{"$set": bson.M{"version": info.Version, "module": info.Module, "arch": info.Arch, "os": info.OS, "start": info.Start, "end": info.End,
+		"slow_ms": info.SlowMilli, "slow_ns": info.SlowNS}}
 	upsertOptions := options.Update().SetUpsert(true)
 	_, err = ptr.db.Collection("hatchet").UpdateOne(context.Background(), filter, update, upsertOptions)
 	return err
 }
 
+// isPercentileSupported returns true if the server, MongoDB 7.0 or later,
+// supports $percentile
+func (ptr *MongoDB) isPercentileSupported() bool {
+	var info bson.M
+	if err := ptr.db.RunCommand(context.Background(), bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return false
+	}
+	versions, ok := info["versionArray"].(bson.A)
+	return ok && len(versions) > 0 && ToInt(versions[0]) >= 7
+}
+
 func (ptr *MongoDB) CreateMetaData() error {
 	var err error
 	log.Printf("insert ops into %v_ops\n", ptr.hatchetName)
-	pipeline := []bson.M{
-		{"$match": bson.M{
-			"op": bson.M{
-				"$nin": []interface{}{nil, ""},
-			},
-		}},
-		{"$group": bson.M{
-			"_id": bson.M{
-				"op":     "$op",
-				"ns":     "$ns",
-				"filter": "$filter",
-				"_index": "$_index",
-			},
-			"count":    bson.M{"$sum": 1},
-			"avg_ms":   bson.M{"$avg": "$milli"},
-			"max_ms":   bson.M{"$max": "$milli"},
-			"total_ms": bson.M{"$sum": "$milli"},
-			"reslen":   bson.M{"$sum": "$reslen"},
-		}},
+	percentile := ptr.isPercentileSupported()
+	match := bson.M{"$match": bson.M{
+		"op": bson.M{
+			"$nin": []interface{}{nil, ""},
+		},
+	}}
+	pipeline := []bson.M{match}
+	pipeline = append(pipeline, GetMongoLatencyStages(bson.M{
+		"op":     "$op",
+		"ns":     "$ns",
+		"filter": "$filter",
+		"sort":   "$sort",
+		"_index": "$_index",
+	}, bson.M{
+		"reslen":     bson.M{"$sum": "$reslen"},
+		"first_seen": bson.M{"$min": "$date"},
+		"last_seen":  bson.M{"$max": "$date"},
+	}, percentile)...)
+	pipeline = append(pipeline, []bson.M{
 		{"$project": bson.M{
-			"_id":      0,
-			"op":       "$_id.op",
-			"count":    1,
-			"avg_ms":   bson.M{"$round": []interface{}{"$avg_ms", 0}},
-			"max_ms":   1,
-			"total_ms": 1,
-			"ns":       "$_id.ns",
-			"_index":   "$_id._index",
-			"reslen":   1,
-			"filter":   "$_id.filter",
+			"_id":        0,
+			"op":         "$_id.op",
+			"count":      1,
+			"avg_ms":     bson.M{"$round": []interface{}{bson.M{"$divide": bson.A{"$total_ms", "$count"}}, 0}},
+			"max_ms":     1,
+			"total_ms":   1,
+			"ns":         "$_id.ns",
+			"_index":     "$_id._index",
+			"reslen":     1,
+			"filter":     "$_id.filter",
+			"sort":       "$_id.sort",
+			"first_seen": 1,
+			"last_seen":  1,
+			"p50_ms":     1,
+			"p95_ms":     1,
+			"p99_ms":     1,
 		}},
 		{"$merge": bson.M{
 			"into": ptr.hatchetName + "_ops",
 		}},
+	}...)
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	if _, err = ptr.db.Collection(ptr.hatchetName).Aggregate(context.Background(), pipeline, opts); err != nil {
+		return err
 	}
-	if _, err = ptr.db.Collection(ptr.hatchetName).Aggregate(context.Background(), pipeline); err != nil {
+
+	log.Printf("insert [latency] into %v_audit\n", ptr.hatchetName)
+	values := bson.A{bson.M{"type": "latency", "value": "$count"}}
+	for _, p := range LATENCY_PERCENTILES {
+		values = append(values, bson.M{"type": fmt.Sprintf("latency-p%d", p), "value": fmt.Sprintf("$p%d_ms", p)})
+	}
+	values = append(values, bson.M{"type": "latency-max", "value": "$max_ms"})
+	pipeline = []bson.M{match}
+	pipeline = append(pipeline, GetMongoLatencyStages(bson.M{
+		"op": "$op",
+		"ns": "$ns",
+	}, bson.M{}, percentile)...)
+	pipeline = append(pipeline, []bson.M{
+		{"$project": bson.M{
+			"_id":    0,
+			"name":   bson.M{"$concat": bson.A{"$_id.op", " ", "$_id.ns"}},
+			"values": values,
+		}},
+		{"$unwind": "$values"},
+		{"$project": bson.M{
+			"type":  "$values.type",
+			"name":  1,
+			"value": "$values.value",
+		}},
+		{"$merge": bson.M{
+			"into": ptr.hatchetName + "_audit",
+		}},
+	}...)
+	if _, err = ptr.db.Collection(ptr.hatchetName).Aggregate(context.Background(), pipeline, opts); err != nil {
 		return err
 	}
 
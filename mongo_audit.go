@@ -78,6 +78,7 @@ func (ptr *MongoDB) GetAuditData() (map[string][]NameValues, error) {
 		}
 		count := ToInt(m["count"])
 		if count > 0 {
+			data[category] = append(data[category], NameValues{"count", []interface{}{count}})
 			val := ToInt(m["max_ms"])
 			data[category] = append(data[category], NameValues{"maxMilli", []interface{}{val}})
 			data[category] = append(data[category], NameValues{"avgMilli", []interface{}{val / count}})
@@ -87,8 +88,73 @@ func (ptr *MongoDB) GetAuditData() (map[string][]NameValues, error) {
 	}
 	defer cur.Close(ctx)
 
-	// get audit data of exception, failed, op, and duration
-	filter := bson.M{"type": bson.M{"$in": []interface{}{"exception", "failed", "op", "duration"}}}
+	// get the worst COLLSCAN shapes
+	category = "collscans"
+	if ops, err := ptr.GetSlowOps("total_ms", "DESC", true); err == nil {
+		if docs := GetCollscanAuditData(ops, TOP_N); len(docs) > 0 {
+			data[category] = docs
+		}
+	}
+
+	// get elections and state changes of the replica set
+	category = "election"
+	if events, err := ptr.GetElectionEvents(); err == nil {
+		if docs := GetElectionAuditData(events); len(docs) > 0 {
+			data[category] = docs
+		}
+	}
+
+	// get chunk migrations by collection and balancer rounds of a sharded cluster
+	if events, err := ptr.GetChunkEvents(); err == nil {
+		docs, balancer := GetChunkAuditData(events)
+		if len(docs) > 0 {
+			data["chunk-migration"] = docs
+		}
+		if len(balancer) > 0 {
+			data["balancer"] = balancer
+		}
+	}
+
+	// get multi-document transactions by termination and cause of aborts
+	if stats, err := ptr.GetTransactionStats(); err == nil {
+		if docs := GetTransactionAuditData(stats); len(docs) > 0 {
+			data["transaction"] = docs
+		}
+	}
+
+	// get runtime configuration changes
+	if changes, err := ptr.GetConfigChanges(); err == nil {
+		if docs := GetConfigAuditData(changes); len(docs) > 0 {
+			data["config"] = docs
+		}
+	}
+
+	// get shapes of poor query targeting
+	if stats, err := ptr.GetTargetingStats(); err == nil {
+		if docs := GetTargetingAuditData(stats, GetLogv2().GetTargetingRatio()); len(docs) > 0 {
+			data["targeting"] = docs
+		}
+	}
+
+	// get shapes of blocking sorts and spills to disk
+	if stats, err := ptr.GetSortStageStats(); err == nil && len(stats) > 0 {
+		data["sort"] = GetBlockingSortAuditData(stats)
+	}
+
+	// get initial and getMore latencies of shapes iterated by getMores
+	if stats, err := ptr.GetGetMoreStats(); err == nil && len(stats) > 0 {
+		data["getmore"] = GetGetMoreAuditData(stats)
+	}
+
+	// get the startup banner of the last boot
+	if banner, err := ptr.GetServerInfo(); err == nil {
+		if docs := GetServerInfoAuditData(banner); len(docs) > 0 {
+			data["server"] = docs
+		}
+	}
+
+	// get audit data of exception, failed, op, duration, oplog, restart, and shapes
+	filter := bson.M{"type": bson.M{"$in": []interface{}{"exception", "failed", "op", "duration", "oplog", "restart", "shapes"}}}
 	opts := options.Find().SetSort(bson.D{{Key: "type", Value: 1}, {Key: "value", Value: -1}})
 	if cur, err = ptr.db.Collection(ptr.hatchetName+"_audit").Find(ctx, filter, opts); err != nil {
 		return data, err
@@ -169,6 +235,31 @@ func (ptr *MongoDB) GetAuditData() (map[string][]NameValues, error) {
 		}
 	}
 
+	// get audit series assembled from companion types
+	for category, types := range AUDIT_SERIES {
+		filter := bson.M{"type": bson.M{"$in": types}}
+		opts := options.Find().SetSort(bson.D{{Key: "value", Value: -1}})
+		if cur, err = ptr.db.Collection(ptr.hatchetName+"_audit").Find(ctx, filter, opts); err != nil {
+			return data, err
+		}
+		defer cur.Close(ctx)
+		series := map[string][]NameValue{}
+		for cur.Next(ctx) {
+			var auditData struct {
+				Type  string `bson:"type"`
+				Name  string `bson:"name"`
+				Value int    `bson:"value"`
+			}
+			if err := cur.Decode(&auditData); err != nil {
+				return data, err
+			}
+			series[auditData.Type] = append(series[auditData.Type], NameValue{auditData.Name, auditData.Value})
+		}
+		if docs := MergeAuditSeries(types, series); len(docs) > 0 {
+			data[category] = docs
+		}
+	}
+
 	// get drivers data
 	category = "driver"
 	pipeline = []bson.M{
@@ -203,3 +294,52 @@ func (ptr *MongoDB) GetAuditData() (map[string][]NameValues, error) {
 	}
 	return data, err
 }
+
+// GetLogTemplates returns counts, and first and last seen of log templates
+// merged by TEMPLATE_SERIES
+func (ptr *MongoDB) GetLogTemplates() ([]NameValues, error) {
+	ctx := context.Background()
+	filter := bson.M{"type": bson.M{"$in": TEMPLATE_SERIES}}
+	cur, err := ptr.db.Collection(ptr.hatchetName+"_audit").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	series := map[string][]NameValue{}
+	for cur.Next(ctx) {
+		var auditData struct {
+			Type  string `bson:"type"`
+			Name  string `bson:"name"`
+			Value int    `bson:"value"`
+		}
+		if err = cur.Decode(&auditData); err != nil {
+			return nil, err
+		}
+		series[auditData.Type] = append(series[auditData.Type], NameValue{auditData.Name, auditData.Value})
+	}
+	return MergeAuditSeries(TEMPLATE_SERIES, series), nil
+}
+
+// GetServerInfo returns the startup banner of the last boot as "name=value"
+// in the logged order
+func (ptr *MongoDB) GetServerInfo() ([]NameValue, error) {
+	ctx := context.Background()
+	opts := options.Find().SetSort(bson.D{{Key: "value", Value: 1}})
+	cur, err := ptr.db.Collection(ptr.hatchetName+"_audit").Find(ctx, bson.M{"type": "server"}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	docs := []NameValue{}
+	for cur.Next(ctx) {
+		var auditData struct {
+			Name  string `bson:"name"`
+			Value int    `bson:"value"`
+		}
+		if err = cur.Decode(&auditData); err != nil {
+			return nil, err
+		}
+		docs = append(docs, NameValue{auditData.Name, auditData.Value})
+	}
+	return docs, nil
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * mongo_bookmarks.go
+ */
+
+package hatchet
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetBookmarks returns bookmarked ops
+func (ptr *MongoDB) GetBookmarks() ([]Bookmark, error) {
+	docs := []Bookmark{}
+	ctx := context.Background()
+	opts := options.Find().SetSort(bson.M{"date": 1})
+	cursor, err := ptr.db.Collection(ptr.hatchetName+"_bookmarks").Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	err = cursor.All(ctx, &docs)
+	return docs, err
+}
+
+// SaveBookmark inserts or updates a bookmark
+func (ptr *MongoDB) SaveBookmark(doc Bookmark) error {
+	ctx := context.Background()
+	opts := options.Update().SetUpsert(true)
+	_, err := ptr.db.Collection(ptr.hatchetName+"_bookmarks").UpdateOne(ctx, bson.M{"_id": doc.Hash},
+		bson.M{"$set": bson.M{"note": doc.Note}, "$setOnInsert": bson.M{"op": doc.Op, "ns": doc.Namespace,
+			"query_pattern": doc.QueryPattern, "index": doc.Index, "date": doc.Date}}, opts)
+	return err
+}
+
+// DeleteBookmark removes a bookmark
+func (ptr *MongoDB) DeleteBookmark(hash string) error {
+	_, err := ptr.db.Collection(ptr.hatchetName+"_bookmarks").DeleteOne(context.Background(), bson.M{"_id": hash})
+	return err
+}
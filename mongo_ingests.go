@@ -0,0 +1,33 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * mongo_ingests.go
+ */
+
+package hatchet
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetIngest returns the last ingest of a log, Name is empty if not found
+func (ptr *MongoDB) GetIngest(logname string) (Ingest, error) {
+	doc := Ingest{}
+	opts := options.FindOne().SetSort(bson.M{"updated": -1})
+	err := ptr.db.Collection(INGESTS_TABLE).FindOne(context.Background(), bson.M{"logname": logname}, opts).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return doc, nil
+	}
+	return doc, err
+}
+
+// SaveIngest inserts or replaces the ingest of a hatchet
+func (ptr *MongoDB) SaveIngest(doc Ingest) error {
+	doc.Name = ptr.hatchetName
+	opts := options.Replace().SetUpsert(true)
+	_, err := ptr.db.Collection(INGESTS_TABLE).ReplaceOne(context.Background(), bson.M{"_id": doc.Name}, doc, opts)
+	return err
+}
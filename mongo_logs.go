@@ -7,11 +7,14 @@ package hatchet
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log"
 	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -33,13 +36,19 @@ func (ptr *MongoDB) GetSlowOps(orderBy string, order string, collscan bool) ([]O
 					"op":     "$op",
 					"ns":     "$ns",
 					"filter": "$filter",
+					"sort":   "$sort",
 					"_index": "$_index",
 				},
-				"count":    bson.M{"$sum": "$count"},
-				"avg_ms":   bson.M{"$avg": "$avg_ms"},
-				"max_ms":   bson.M{"$max": "$max_ms"},
-				"total_ms": bson.M{"$sum": "$total_ms"},
-				"reslen":   bson.M{"$sum": "$reslen"},
+				"count":      bson.M{"$sum": "$count"},
+				"avg_ms":     bson.M{"$avg": "$avg_ms"},
+				"max_ms":     bson.M{"$max": "$max_ms"},
+				"total_ms":   bson.M{"$sum": "$total_ms"},
+				"reslen":     bson.M{"$sum": "$reslen"},
+				"first_seen": bson.M{"$min": "$first_seen"},
+				"last_seen":  bson.M{"$max": "$last_seen"},
+				"p50_ms":     bson.M{"$max": "$p50_ms"},
+				"p95_ms":     bson.M{"$max": "$p95_ms"},
+				"p99_ms":     bson.M{"$max": "$p99_ms"},
 			},
 		},
 		{
@@ -54,6 +63,12 @@ func (ptr *MongoDB) GetSlowOps(orderBy string, order string, collscan bool) ([]O
 				"index":         "$_id._index",
 				"reslen":        1,
 				"query_pattern": "$_id.filter",
+				"sort_pattern":  "$_id.sort",
+				"first_seen":    1,
+				"last_seen":     1,
+				"p50_ms":        1,
+				"p95_ms":        1,
+				"p99_ms":        1,
 			},
 		},
 		{
@@ -194,6 +209,84 @@ func (ptr *MongoDB) SearchLogs(opts ...string) ([]LegacyLog, error) {
 	return docs, nil
 }
 
+// SearchMessages returns logs of messages matching a $text search in order of
+// dates, words are of any, "phrases" are required, and NOT or -word excludes
+func (ptr *MongoDB) SearchMessages(query string, limit string) ([]LegacyLog, error) {
+	docs := []LegacyLog{}
+	ctx := context.Background()
+	collection := ptr.db.Collection(ptr.hatchetName)
+	index := mongo.IndexModel{Keys: bson.D{{Key: "message", Value: "text"}}}
+	if _, err := collection.Indexes().CreateOne(ctx, index); err != nil {
+		return docs, err
+	}
+	offset, nlimit := GetOffsetLimit(limit)
+	if nlimit <= 0 {
+		nlimit = LIMIT
+	}
+	words := []string{}
+	negate := false
+	for _, word := range strings.Fields(query) {
+		if word == "AND" || word == "OR" {
+			continue
+		} else if word == "NOT" {
+			negate = true
+			continue
+		}
+		if negate {
+			word = "-" + word
+			negate = false
+		}
+		words = append(words, word)
+	}
+	filter := bson.M{"$text": bson.M{"$search": strings.Join(words, " ")}}
+	fopts := options.Find().SetSort(bson.M{"_id": 1}).SetSkip(int64(offset)).SetLimit(int64(nlimit + 1))
+	cursor, err := collection.Find(ctx, filter, fopts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc LegacyLog
+		if err = cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// ExportLogs writes original lines, or lines in the legacy format if not
+// stored, of logs matching filters in order and returns the number of lines
+func (ptr *MongoDB) ExportLogs(w io.Writer, filters []RawLogFilter) (int, error) {
+	ctx := context.Background()
+	cond := bson.M{}
+	for _, filter := range filters {
+		if filter.Column == "date" {
+			cond["date"] = bson.M{"$gte": filter.Values[0], "$lte": filter.Values[1]}
+		} else {
+			cond[filter.Column] = bson.M{"$in": filter.Values}
+		}
+	}
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Find(ctx, cond, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+	count := 0
+	for cursor.Next(ctx) {
+		var doc LegacyLog
+		if err = cursor.Decode(&doc); err != nil {
+			return count, err
+		}
+		raw, _ := cursor.Current.Lookup("raw").StringValueOK()
+		if _, err = fmt.Fprintln(w, GetRawLogLine(raw, doc)); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, cursor.Err()
+}
+
 func (ptr *MongoDB) GetSlowestLogs(topN int) ([]LegacyLog, error) {
 	collection := ptr.db.Collection(ptr.hatchetName)
 	pipeline := []bson.M{
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * mongo_migrations.go
+ */
+
+package hatchet
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// recordSchemaVersion records the schema version of a hatchet just created
+func (ptr *MongoDB) recordSchemaVersion() error {
+	ctx := context.Background()
+	coll := ptr.db.Collection(MIGRATIONS_TABLE)
+	if _, err := coll.DeleteMany(ctx, bson.M{"name": ptr.hatchetName}); err != nil {
+		return err
+	}
+	_, err := coll.InsertOne(ctx, bson.M{"name": ptr.hatchetName, "version": DB_SCHEMA_VERSION,
+		"description": MIGRATION_CREATED, "applied": time.Now().UTC().Format(time.RFC3339)})
+	return err
+}
+
+// GetMigrations returns migrations recorded of a hatchet
+func (ptr *MongoDB) GetMigrations() ([]MigrationRecord, error) {
+	docs := []MigrationRecord{}
+	ctx := context.Background()
+	opts := options.Find().SetSort(bson.M{"version": 1})
+	cursor, err := ptr.db.Collection(MIGRATIONS_TABLE).Find(ctx, bson.M{"name": ptr.hatchetName}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	err = cursor.All(ctx, &docs)
+	return docs, err
+}
+
+// Migrate records migrations of a hatchet created by an older version, fields
+// missing from documents are queried as nulls and need no changes
+func (ptr *MongoDB) Migrate() ([]Migration, error) {
+	records, err := ptr.GetMigrations()
+	if err != nil {
+		return nil, err
+	}
+	version := 1
+	for _, record := range records {
+		if record.Version > version {
+			version = record.Version
+		}
+	}
+	migrations := GetPendingMigrations(version)
+	for _, migration := range migrations {
+		if _, err = ptr.db.Collection(MIGRATIONS_TABLE).InsertOne(context.Background(), bson.M{
+			"name": ptr.hatchetName, "version": migration.Version, "description": migration.Description,
+			"applied": time.Now().UTC().Format(time.RFC3339)}); err != nil {
+			return nil, err
+		}
+	}
+	return migrations, nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/simagix/gox"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -83,6 +84,1101 @@ func (ptr *MongoDB) GetAverageOpTime(op string, duration string) ([]OpCount, err
 	return docs, nil
 }
 
+// GetWriteStats returns documents written by op shapes, ordered by documents modified
+func (ptr *MongoDB) GetWriteStats() ([]WriteStat, error) {
+	var docs []WriteStat
+	ctx := context.Background()
+	exists := bson.A{}
+	for _, name := range WRITE_COUNTERS {
+		exists = append(exists, bson.M{name: bson.M{"$exists": true}})
+	}
+	group := bson.M{
+		"_id":   bson.M{"op": "$op", "ns": "$ns", "query_pattern": "$filter"},
+		"count": bson.M{"$sum": 1},
+	}
+	project := bson.M{"_id": 0, "op": "$_id.op", "ns": "$_id.ns", "query_pattern": "$_id.query_pattern", "count": 1}
+	for _, name := range WRITE_COUNTERS {
+		group[name] = bson.M{"$sum": "$" + name}
+		project[name] = 1
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"op": bson.M{"$ne": ""}, "$or": exists}},
+		{"$group": group},
+		{"$project": project},
+		{"$sort": bson.D{{Key: "n_modified", Value: -1}, {Key: "count", Value: -1}}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetShapePlans returns counts of plans by op shape and time bucket
+func (ptr *MongoDB) GetShapePlans(duration string) ([]ShapePlan, error) {
+	var docs []ShapePlan
+	var substr bson.M
+	ctx := context.Background()
+	cond := bson.M{"op": bson.M{"$ne": ""}, "_index": bson.M{"$ne": "", "$not": primitive.Regex{Pattern: "^ErrMsg:"}}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		substr = GetMongoDateSubString(toks[0], toks[1])
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetMongoDateSubString(info.Start, info.End)
+	}
+	group := bson.M{
+		"_id": bson.M{"op": "$op", "ns": "$ns", "query_pattern": "$filter", "sort_pattern": "$sort",
+			"index": "$_index", "date": substr},
+		"count":      bson.M{"$sum": 1},
+		"first_seen": bson.M{"$min": "$date"},
+	}
+	project := bson.M{"_id": 0, "op": "$_id.op", "ns": "$_id.ns", "query_pattern": "$_id.query_pattern",
+		"sort_pattern": bson.M{"$ifNull": bson.A{"$_id.sort_pattern", ""}}, "index": "$_id.index", "date": "$_id.date",
+		"count": 1, "first_seen": 1}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": group},
+		{"$project": project},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetLockStats returns lock acquisitions of op shapes
+func (ptr *MongoDB) GetLockStats() ([]LockStat, error) {
+	var docs []LockStat
+	ctx := context.Background()
+	group := bson.M{
+		"_id":      bson.M{"op": "$op", "ns": "$ns", "query_pattern": "$filter"},
+		"count":    bson.M{"$sum": 1},
+		"total_ms": bson.M{"$sum": "$milli"},
+	}
+	project := bson.M{"_id": 0, "op": "$_id.op", "ns": "$_id.ns", "query_pattern": "$_id.query_pattern",
+		"count": 1, "total_ms": 1}
+	for _, name := range append(append([]string{}, LOCK_COUNTERS...), "lock_wait_count", "lock_wait_micros") {
+		group[name] = bson.M{"$sum": "$" + name}
+		project[name] = 1
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"op": bson.M{"$ne": ""}, "$or": bson.A{
+			bson.M{"lock_global_r": bson.M{"$exists": true}},
+			bson.M{"lock_database_r": bson.M{"$exists": true}},
+			bson.M{"lock_collection_r": bson.M{"$exists": true}},
+			bson.M{"lock_wait_micros": bson.M{"$exists": true}}}}},
+		{"$group": group},
+		{"$project": project},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetAppStats returns slow ops by application, op, and namespace, ops of
+// connections without an appName are of APP_UNKNOWN
+func (ptr *MongoDB) GetAppStats() ([]AppStat, error) {
+	docs := []AppStat{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"op": bson.M{"$ne": ""}}},
+		{"$group": bson.M{
+			"_id":      bson.M{"app_name": bson.M{"$ifNull": []interface{}{"$app_name", APP_UNKNOWN}}, "op": "$op", "ns": "$ns"},
+			"count":    bson.M{"$sum": 1},
+			"total_ms": bson.M{"$sum": "$milli"},
+			"max_ms":   bson.M{"$max": "$milli"},
+		}},
+		{"$sort": bson.M{"total_ms": -1}},
+		{"$project": bson.M{"_id": 0, "app_name": "$_id.app_name", "op": "$_id.op", "ns": "$_id.ns",
+			"count": 1, "total_ms": 1, "max_ms": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetElectionEvents returns elections and state changes of a replica set in
+// time order
+func (ptr *MongoDB) GetElectionEvents() ([]ElectionEvent, error) {
+	docs := []ElectionEvent{}
+	ctx := context.Background()
+	filter := bson.M{"component": bson.M{"$in": []string{"REPL", "ELECTION"}},
+		"msg": primitive.Regex{Pattern: ELECTION_MSG_FILTER, Options: "i"}}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"date": 1, "component": 1, "msg": 1, "message": 1})
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Find(ctx, filter, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc struct {
+			Component string `bson:"component"`
+			Date      string `bson:"date"`
+			Message   string `bson:"message"`
+			Msg       string `bson:"msg"`
+		}
+		if err = cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		if event, ok := GetElectionEvent(doc.Date, doc.Component, doc.Msg, doc.Message); ok {
+			docs = append(docs, event)
+		}
+	}
+	return docs, cursor.Err()
+}
+
+// GetConfigChanges returns setParameter, featureCompatibilityVersion, and
+// commit quorum changes in time order
+func (ptr *MongoDB) GetConfigChanges() ([]ConfigChange, error) {
+	docs := []ConfigChange{}
+	ctx := context.Background()
+	filter := bson.M{"config_change": bson.M{"$ne": nil}}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"date": 1, "config_change": 1, "config_name": 1, "config_old": 1, "config_new": 1})
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Find(ctx, filter, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetChunkEvents returns chunk migrations, splits, and balancer rounds in
+// time order
+func (ptr *MongoDB) GetChunkEvents() ([]ChunkEvent, error) {
+	docs := []ChunkEvent{}
+	ctx := context.Background()
+	filter := bson.M{"chunk_event": bson.M{"$ne": nil}}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"date": 1, "ns": 1, "chunk_event": 1, "chunk_ms": 1})
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Find(ctx, filter, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetTransactionStats returns multi-document transactions by termination and
+// cause of aborts
+func (ptr *MongoDB) GetTransactionStats() ([]TxnStat, error) {
+	docs := []TxnStat{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"txn_result": bson.M{"$ne": nil}}},
+		{"$group": bson.M{
+			"_id":      bson.M{"result": "$txn_result", "cause": bson.M{"$ifNull": bson.A{"$txn_cause", ""}}},
+			"count":    bson.M{"$sum": 1},
+			"total_ms": bson.M{"$sum": "$txn_ms"},
+			"max_ms":   bson.M{"$max": "$txn_ms"},
+			"ops":      bson.M{"$sum": "$txn_ops"},
+		}},
+		{"$project": bson.M{"_id": 0, "result": "$_id.result", "cause": "$_id.cause", "count": 1, "total_ms": 1,
+			"max_ms": bson.M{"$ifNull": bson.A{"$max_ms", 0}}, "ops": 1}},
+		{"$sort": bson.M{"count": -1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetSlowOpsByShape returns slow ops by op, namespace, query shape, and sort
+// shape in a duration, of all logs if empty, the most total ms first
+func (ptr *MongoDB) GetSlowOpsByShape(duration string) ([]OpStat, error) {
+	docs := []OpStat{}
+	ctx := context.Background()
+	cond := bson.M{"op": bson.M{"$ne": ""}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": bson.M{
+			"_id": bson.M{"op": "$op", "ns": "$ns", "filter": "$filter",
+				"sort": bson.M{"$ifNull": bson.A{"$sort", ""}}},
+			"count":        bson.M{"$sum": 1},
+			"avg_ms":       bson.M{"$avg": "$milli"},
+			"max_ms":       bson.M{"$max": "$milli"},
+			"total_ms":     bson.M{"$sum": "$milli"},
+			"total_reslen": bson.M{"$sum": "$reslen"},
+			"first_seen":   bson.M{"$min": "$date"},
+			"last_seen":    bson.M{"$max": "$date"},
+		}},
+		{"$sort": bson.M{"total_ms": -1}},
+		{"$project": bson.M{"_id": 0, "op": "$_id.op", "ns": "$_id.ns", "query_pattern": "$_id.filter",
+			"sort_pattern": "$_id.sort", "count": 1, "avg_ms": 1, "max_ms": 1, "total_ms": 1, "total_reslen": 1,
+			"first_seen": 1, "last_seen": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetSeverityStats returns warnings, errors, and fatal messages by severity,
+// component, and log id, or by message if logged in legacy format, with the
+// first and last occurrences
+func (ptr *MongoDB) GetSeverityStats() ([]SeverityStat, error) {
+	docs := []SeverityStat{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	logID := bson.M{"$ifNull": bson.A{"$id", 0}}
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"severity": bson.M{"$in": SEVERITY_LEVELS}}},
+		{"$group": bson.M{
+			"_id": bson.M{"severity": "$severity", "component": "$component",
+				"key": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{logID, 0}}, logID, "$msg"}}},
+			"id":    bson.M{"$max": logID},
+			"msg":   bson.M{"$max": "$msg"},
+			"count": bson.M{"$sum": 1},
+			"first": bson.M{"$min": "$date"},
+			"last":  bson.M{"$max": "$date"},
+		}},
+		{"$project": bson.M{"_id": 0, "severity": "$_id.severity", "component": "$_id.component", "id": 1, "msg": 1,
+			"count": 1, "first": 1, "last": 1}},
+		{"$sort": bson.M{"count": -1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetPipelineStats returns slow aggregate pipelines by namespace and stages
+func (ptr *MongoDB) GetPipelineStats() ([]PipelineStat, error) {
+	docs := []PipelineStat{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"op": bson.M{"$ne": ""}, "stages": bson.M{"$ne": nil}}},
+		{"$group": bson.M{
+			"_id":      bson.M{"ns": "$ns", "stages": "$stages"},
+			"count":    bson.M{"$sum": 1},
+			"total_ms": bson.M{"$sum": "$milli"},
+			"max_ms":   bson.M{"$max": "$milli"},
+		}},
+		{"$sort": bson.M{"total_ms": -1}},
+		{"$project": bson.M{"_id": 0, "ns": "$_id.ns", "stages": "$_id.stages", "count": 1, "total_ms": 1, "max_ms": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetPlanningStats returns planning times of op shapes of more than one
+// execution logging planningTimeMicros
+func (ptr *MongoDB) GetPlanningStats() ([]PlanningStat, error) {
+	docs := []PlanningStat{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"op": bson.M{"$ne": ""}, "planning_micros": bson.M{"$ne": nil}}},
+		{"$group": bson.M{
+			"_id":           bson.M{"op": "$op", "ns": "$ns", "query_pattern": "$filter"},
+			"count":         bson.M{"$sum": 1},
+			"min_micros":    bson.M{"$min": "$planning_micros"},
+			"max_micros":    bson.M{"$max": "$planning_micros"},
+			"avg_micros":    bson.M{"$avg": "$planning_micros"},
+			"stddev_micros": bson.M{"$stdDevPop": "$planning_micros"},
+		}},
+		{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+		{"$project": bson.M{"_id": 0, "op": "$_id.op", "ns": "$_id.ns", "query_pattern": "$_id.query_pattern",
+			"count": 1, "min_micros": 1, "max_micros": 1, "avg_micros": 1, "stddev_micros": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	SortPlanningStats(docs)
+	return docs, nil
+}
+
+// GetGetMoreStats returns latencies of initial executions and getMores of
+// shapes iterated by getMores, the most getMore milliseconds first
+func (ptr *MongoDB) GetGetMoreStats() ([]GetMoreStat, error) {
+	docs := []GetMoreStat{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	isGetMore := bson.M{"$eq": bson.A{"$op", cmdGetMore}}
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"op": bson.M{"$in": bson.A{cmdFind, cmdAggregate, cmdGetMore}}}},
+		{"$group": bson.M{
+			"_id": bson.M{"ns": "$ns", "query_pattern": "$filter",
+				"originating_op": bson.M{"$cond": bson.A{isGetMore, bson.M{"$ifNull": bson.A{"$originating_op", ""}}, "$op"}}},
+			"initial_count":    bson.M{"$sum": bson.M{"$cond": bson.A{isGetMore, 0, 1}}},
+			"initial_avg_ms":   bson.M{"$avg": bson.M{"$cond": bson.A{isGetMore, nil, "$milli"}}},
+			"initial_max_ms":   bson.M{"$max": bson.M{"$cond": bson.A{isGetMore, nil, "$milli"}}},
+			"getmore_count":    bson.M{"$sum": bson.M{"$cond": bson.A{isGetMore, 1, 0}}},
+			"getmore_avg_ms":   bson.M{"$avg": bson.M{"$cond": bson.A{isGetMore, "$milli", nil}}},
+			"getmore_max_ms":   bson.M{"$max": bson.M{"$cond": bson.A{isGetMore, "$milli", nil}}},
+			"getmore_total_ms": bson.M{"$sum": bson.M{"$cond": bson.A{isGetMore, "$milli", 0}}},
+			"cursor_ids":       bson.M{"$addToSet": bson.M{"$cond": bson.A{isGetMore, "$cursor_id", nil}}},
+		}},
+		{"$match": bson.M{"getmore_count": bson.M{"$gt": 0}}},
+		{"$project": bson.M{"_id": 0, "ns": "$_id.ns", "query_pattern": "$_id.query_pattern", "originating_op": "$_id.originating_op",
+			"initial_count": 1, "initial_avg_ms": bson.M{"$ifNull": bson.A{"$initial_avg_ms", 0}},
+			"initial_max_ms": bson.M{"$ifNull": bson.A{"$initial_max_ms", 0}}, "getmore_count": 1, "getmore_avg_ms": 1,
+			"getmore_max_ms": 1, "getmore_total_ms": 1,
+			"cursors": bson.M{"$size": bson.M{"$setDifference": bson.A{"$cursor_ids", bson.A{nil}}}}}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	SortGetMoreStats(docs)
+	return docs, nil
+}
+
+// GetSortStageStats returns op shapes of blocking sorts or spills to disk,
+// the most spills first
+func (ptr *MongoDB) GetSortStageStats() ([]BlockingSortStat, error) {
+	docs := []BlockingSortStat{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"op": bson.M{"$ne": ""}, "$or": []bson.M{{"has_sort_stage": 1}, {"used_disk": 1}}}},
+		{"$group": bson.M{
+			"_id":    bson.M{"op": "$op", "ns": "$ns", "query_pattern": "$filter", "sort": "$sort"},
+			"count":  bson.M{"$sum": 1},
+			"sorts":  bson.M{"$sum": "$has_sort_stage"},
+			"spills": bson.M{"$sum": "$used_disk"},
+			"avg_ms": bson.M{"$avg": "$milli"},
+		}},
+		{"$project": bson.M{"_id": 0, "op": "$_id.op", "ns": "$_id.ns", "query_pattern": "$_id.query_pattern",
+			"sort": "$_id.sort", "count": 1, "sorts": 1, "spills": 1, "avg_ms": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	SortBlockingSortStats(docs)
+	return docs, nil
+}
+
+// GetTargetingStats returns keys and documents examined and documents
+// returned by op shapes, the worst targeting first
+func (ptr *MongoDB) GetTargetingStats() ([]TargetingStat, error) {
+	docs := []TargetingStat{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"op": bson.M{"$ne": ""}, "nreturned": bson.M{"$ne": nil}}},
+		{"$group": bson.M{
+			"_id":           bson.M{"op": "$op", "ns": "$ns", "query_pattern": "$filter"},
+			"count":         bson.M{"$sum": 1},
+			"docs_examined": bson.M{"$sum": "$docs_examined"},
+			"keys_examined": bson.M{"$sum": "$keys_examined"},
+			"nreturned":     bson.M{"$sum": "$nreturned"},
+		}},
+		{"$project": bson.M{"_id": 0, "op": "$_id.op", "ns": "$_id.ns", "query_pattern": "$_id.query_pattern",
+			"count": 1, "docs_examined": 1, "keys_examined": 1, "nreturned": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	SortTargetingStats(docs)
+	return docs, nil
+}
+
+// GetReplanStats returns op shapes of replanned executions and plan cache
+// evictions of namespaces
+func (ptr *MongoDB) GetReplanStats() ([]ReplanStat, error) {
+	docs := []ReplanStat{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	coll := ptr.db.Collection(ptr.hatchetName)
+	replanned := bson.M{"$gt": bson.A{"$replan_reason", nil}}
+	cursor, err := coll.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"op": bson.M{"$ne": ""}}},
+		{"$group": bson.M{
+			"_id":             bson.M{"op": "$op", "ns": "$ns", "query_pattern": "$filter"},
+			"count":           bson.M{"$sum": 1},
+			"replans":         bson.M{"$sum": bson.M{"$cond": bson.A{replanned, 1, 0}}},
+			"avg_ms":          bson.M{"$avg": bson.M{"$cond": bson.A{replanned, "$$REMOVE", "$milli"}}},
+			"avg_replan_ms":   bson.M{"$avg": bson.M{"$cond": bson.A{replanned, "$milli", "$$REMOVE"}}},
+			"max_replan_ms":   bson.M{"$max": bson.M{"$cond": bson.A{replanned, "$milli", "$$REMOVE"}}},
+			"total_replan_ms": bson.M{"$sum": bson.M{"$cond": bson.A{replanned, "$milli", 0}}},
+			"reason":          bson.M{"$max": "$replan_reason"},
+		}},
+		{"$match": bson.M{"replans": bson.M{"$gt": 0}}},
+		{"$project": bson.M{"_id": 0, "op": "$_id.op", "ns": "$_id.ns", "query_pattern": "$_id.query_pattern",
+			"count": 1, "replans": 1, "avg_ms": bson.M{"$ifNull": bson.A{"$avg_ms", 0}}, "avg_replan_ms": 1,
+			"max_replan_ms": 1, "total_replan_ms": 1, "reason": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	var results []struct {
+		Count int    `bson:"count"`
+		NS    string `bson:"_id"`
+	}
+	if cursor, err = coll.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"op": "", "replan_reason": bson.M{"$ne": nil}}},
+		{"$group": bson.M{"_id": bson.M{"$ifNull": bson.A{"$ns", ""}}, "count": bson.M{"$sum": 1}}},
+	}, opts); err != nil {
+		return docs, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return docs, err
+	}
+	evictions := map[string]int{}
+	for _, result := range results {
+		evictions[result.NS] = result.Count
+	}
+	return MergeReplanEvictions(docs, evictions), nil
+}
+
+// GetShardingStats returns shard targeting of op shapes routed by a mongos
+func (ptr *MongoDB) GetShardingStats() ([]ShardingStat, error) {
+	docs := []ShardingStat{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	coll := ptr.db.Collection(ptr.hatchetName)
+	var total struct {
+		MaxShards int `bson:"max_shards"`
+	}
+	cursor, err := coll.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"n_shards": bson.M{"$ne": nil}}},
+		{"$group": bson.M{"_id": nil, "max_shards": bson.M{"$max": "$n_shards"}}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	if cursor.Next(ctx) {
+		cursor.Decode(&total)
+	}
+	cursor.Close(ctx)
+	var results []struct {
+		ShardingStat `bson:",inline"`
+		Names        []string `bson:"names"`
+	}
+	if cursor, err = coll.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"op": bson.M{"$ne": ""}, "n_shards": bson.M{"$ne": nil}}},
+		{"$group": bson.M{
+			"_id":        bson.M{"op": "$op", "ns": "$ns", "query_pattern": "$filter"},
+			"count":      bson.M{"$sum": 1},
+			"min_shards": bson.M{"$min": "$n_shards"},
+			"max_shards": bson.M{"$max": "$n_shards"},
+			"avg_shards": bson.M{"$avg": "$n_shards"},
+			"all_shards": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$and": bson.A{
+				bson.M{"$gt": bson.A{"$n_shards", 1}}, bson.M{"$gte": bson.A{"$n_shards", total.MaxShards}}}}, 1, 0}}},
+			"names": bson.M{"$addToSet": "$shards"},
+		}},
+		{"$project": bson.M{"_id": 0, "op": "$_id.op", "ns": "$_id.ns", "query_pattern": "$_id.query_pattern",
+			"count": 1, "min_shards": 1, "max_shards": 1, "avg_shards": 1, "all_shards": 1, "names": 1}},
+	}, opts); err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &results); err != nil {
+		return docs, err
+	}
+	for _, result := range results {
+		doc := result.ShardingStat
+		doc.SetShards(result.Names)
+		docs = append(docs, doc)
+	}
+	SortShardingStats(docs)
+	return docs, nil
+}
+
+// GetSourceStats returns counts of lines by sources of merged logs
+func (ptr *MongoDB) GetSourceStats() ([]SourceStat, error) {
+	docs := []SourceStat{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"source": bson.M{"$ne": nil}}},
+		{"$group": bson.M{
+			"_id":      "$source",
+			"lines":    bson.M{"$sum": 1},
+			"slow_ops": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$ne": bson.A{"$op", ""}}, 1, 0}}},
+			"warnings": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$severity", "W"}}, 1, 0}}},
+			"errors":   bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$in": bson.A{"$severity", bson.A{"F", "E"}}}, 1, 0}}},
+			"start":    bson.M{"$min": "$date"},
+			"end":      bson.M{"$max": "$date"},
+		}},
+		{"$project": bson.M{"_id": 0, "source": "$_id", "lines": 1, "slow_ops": 1, "warnings": 1, "errors": 1,
+			"start": 1, "end": 1}},
+		{"$sort": bson.M{"source": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetReplicationLags returns avg and max replication lag in seconds, counts of
+// applied ops, and documents written over time
+func (ptr *MongoDB) GetReplicationLags(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	var substr bson.M
+	ctx := context.Background()
+	cond := bson.M{"$or": []bson.M{{"repl_lag_ms": bson.M{"$ne": nil}}, {"n_inserted": bson.M{"$ne": nil}},
+		{"n_upserted": bson.M{"$ne": nil}}, {"n_modified": bson.M{"$ne": nil}}, {"n_deleted": bson.M{"$ne": nil}}}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		substr = GetMongoDateSubString(toks[0], toks[1])
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetMongoDateSubString(info.Start, info.End)
+	}
+	written := bson.A{}
+	for _, field := range []string{"$n_inserted", "$n_upserted", "$n_modified", "$n_deleted"} {
+		written = append(written, bson.M{"$ifNull": bson.A{field, 0}})
+	}
+	group := bson.M{
+		"_id":     substr,
+		"avg":     bson.M{"$avg": "$repl_lag_ms"},
+		"max":     bson.M{"$max": "$repl_lag_ms"},
+		"applied": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$repl_lag_ms", nil}}, 1, 0}}},
+		"written": bson.M{"$sum": bson.M{"$add": written}},
+	}
+	project := bson.M{
+		"_id":  0,
+		"date": "$_id",
+		"values": bson.A{
+			bson.M{"$divide": bson.A{bson.M{"$ifNull": bson.A{"$avg", 0}}, 1000}},
+			bson.M{"$divide": bson.A{bson.M{"$ifNull": bson.A{"$max", 0}}, 1000}},
+			"$applied", "$written"},
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": group},
+		{"$project": project},
+		{"$sort": bson.M{"date": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc TimeSeries
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		if len(doc.Date) < 19 {
+			full := "2023-09-23T23:59:59"
+			doc.Date += full[len(doc.Date):]
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// GetOpsHeatmap returns counts and total milliseconds of slow ops by
+// namespace and hour, or by day and hour of day
+func (ptr *MongoDB) GetOpsHeatmap(layout string, duration string) ([]HeatmapCell, error) {
+	docs := []HeatmapCell{}
+	ctx := context.Background()
+	cond := bson.M{"op": bson.M{"$nin": []interface{}{"", nil}}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	}
+	var row, column interface{} = "$ns", bson.M{"$substr": bson.A{"$date", 0, 13}}
+	if layout == HEATMAP_BY_DAY {
+		row, column = bson.M{"$substr": bson.A{"$date", 0, 10}}, bson.M{"$substr": bson.A{"$date", 11, 2}}
+	}
+	group := bson.M{
+		"_id":      bson.M{"row": row, "column": column},
+		"count":    bson.M{"$sum": 1},
+		"total_ms": bson.M{"$sum": "$milli"},
+	}
+	project := bson.M{"_id": 0, "row": "$_id.row", "column": "$_id.column", "count": 1, "total_ms": 1}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": group},
+		{"$project": project},
+		{"$sort": bson.D{{Key: "row", Value: 1}, {Key: "column", Value: 1}}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc HeatmapCell
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, cursor.Err()
+}
+
+// GetWiredTigerStats returns max checkpoint seconds, counts of eviction
+// warnings and cache pressure messages, and counts of slow ops over time
+func (ptr *MongoDB) GetWiredTigerStats(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	var substr bson.M
+	ctx := context.Background()
+	cond := bson.M{"$or": []bson.M{{"wt_event": bson.M{"$ne": nil}}, {"op": bson.M{"$nin": []interface{}{"", nil}}}}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		substr = GetMongoDateSubString(toks[0], toks[1])
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetMongoDateSubString(info.Start, info.End)
+	}
+	group := bson.M{
+		"_id":        substr,
+		"checkpoint": bson.M{"$max": "$wt_checkpoint_ms"},
+		"evictions":  bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$wt_event", WT_EVICTION}}, 1, 0}}},
+		"cache":      bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$wt_event", WT_CACHE}}, 1, 0}}},
+		"slowops":    bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$op", ""}}, 1, 0}}},
+	}
+	project := bson.M{
+		"_id":  0,
+		"date": "$_id",
+		"values": bson.A{
+			bson.M{"$divide": bson.A{bson.M{"$ifNull": bson.A{"$checkpoint", 0}}, 1000}},
+			"$evictions", "$cache", "$slowops"},
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": group},
+		{"$project": project},
+		{"$sort": bson.M{"date": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc TimeSeries
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		if len(doc.Date) < 19 {
+			full := "2023-09-23T23:59:59"
+			doc.Date += full[len(doc.Date):]
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// GetLockWaits returns seconds waited acquiring locks, counts of slow ops
+// waited, and counts of slow ops over time
+func (ptr *MongoDB) GetLockWaits(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	var substr bson.M
+	ctx := context.Background()
+	cond := bson.M{"op": bson.M{"$nin": []interface{}{"", nil}}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		substr = GetMongoDateSubString(toks[0], toks[1])
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetMongoDateSubString(info.Start, info.End)
+	}
+	group := bson.M{
+		"_id":    substr,
+		"micros": bson.M{"$sum": "$lock_wait_micros"},
+		"waited": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$or": bson.A{
+			bson.M{"$gt": bson.A{"$lock_wait_micros", 0}}, bson.M{"$gt": bson.A{"$lock_wait_count", 0}}}}, 1, 0}}},
+		"slowops": bson.M{"$sum": 1},
+	}
+	project := bson.M{
+		"_id":    0,
+		"date":   "$_id",
+		"values": bson.A{bson.M{"$divide": bson.A{"$micros", 1000000}}, "$waited", "$slowops"},
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": group},
+		{"$project": project},
+		{"$sort": bson.M{"date": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc TimeSeries
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		if len(doc.Date) < 19 {
+			full := "2023-09-23T23:59:59"
+			doc.Date += full[len(doc.Date):]
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// GetChunkMigrationStats returns counts of chunk migrations and failed
+// migrations, max seconds of migrations, and counts of balancer rounds over time
+func (ptr *MongoDB) GetChunkMigrationStats(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	var substr bson.M
+	ctx := context.Background()
+	cond := bson.M{"chunk_event": bson.M{"$ne": nil}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		substr = GetMongoDateSubString(toks[0], toks[1])
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetMongoDateSubString(info.Start, info.End)
+	}
+	migrated := bson.M{"$in": bson.A{"$chunk_event", bson.A{CHUNK_MIGRATION_FROM, CHUNK_MIGRATION_TO}}}
+	group := bson.M{
+		"_id":        substr,
+		"migrations": bson.M{"$sum": bson.M{"$cond": bson.A{migrated, 1, 0}}},
+		"failed":     bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$chunk_event", CHUNK_MIGRATION_FAIL}}, 1, 0}}},
+		"max_ms":     bson.M{"$max": bson.M{"$cond": bson.A{migrated, "$chunk_ms", nil}}},
+		"rounds":     bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$chunk_event", CHUNK_BALANCER_ROUND}}, 1, 0}}},
+	}
+	project := bson.M{
+		"_id":  0,
+		"date": "$_id",
+		"values": bson.A{"$migrations", "$failed",
+			bson.M{"$divide": bson.A{bson.M{"$ifNull": bson.A{"$max_ms", 0}}, 1000}}, "$rounds"},
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": group},
+		{"$project": project},
+		{"$sort": bson.M{"date": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc TimeSeries
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		if len(doc.Date) < 19 {
+			full := "2023-09-23T23:59:59"
+			doc.Date += full[len(doc.Date):]
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// GetSeverityCounts returns counts of warnings, errors, and fatal messages over
+// time
+func (ptr *MongoDB) GetSeverityCounts(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	var substr bson.M
+	ctx := context.Background()
+	cond := bson.M{"severity": bson.M{"$in": SEVERITY_LEVELS}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		substr = GetMongoDateSubString(toks[0], toks[1])
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetMongoDateSubString(info.Start, info.End)
+	}
+	group := bson.M{"_id": substr}
+	values := bson.A{}
+	for i, severity := range SEVERITY_LEVELS {
+		field := fmt.Sprintf("s%v", i)
+		group[field] = bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$severity", severity}}, 1, 0}}}
+		values = append(values, "$"+field)
+	}
+	project := bson.M{"_id": 0, "date": "$_id", "values": values}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": group},
+		{"$project": project},
+		{"$sort": bson.M{"date": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc TimeSeries
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		if len(doc.Date) < 19 {
+			full := "2023-09-23T23:59:59"
+			doc.Date += full[len(doc.Date):]
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// GetTransactionRates returns counts of committed and aborted transactions and
+// aborted in percent of transactions over time
+func (ptr *MongoDB) GetTransactionRates(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	var substr bson.M
+	ctx := context.Background()
+	cond := bson.M{"txn_result": bson.M{"$ne": nil}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		substr = GetMongoDateSubString(toks[0], toks[1])
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetMongoDateSubString(info.Start, info.End)
+	}
+	group := bson.M{
+		"_id":       substr,
+		"committed": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$txn_result", TXN_COMMITTED}}, 1, 0}}},
+		"aborted":   bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$txn_result", TXN_ABORTED}}, 1, 0}}},
+	}
+	project := bson.M{
+		"_id":  0,
+		"date": "$_id",
+		"values": bson.A{"$committed", "$aborted", bson.M{"$divide": bson.A{
+			bson.M{"$multiply": bson.A{100, "$aborted"}}, bson.M{"$add": bson.A{"$committed", "$aborted"}}}}},
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": group},
+		{"$project": project},
+		{"$sort": bson.M{"date": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc TimeSeries
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		if len(doc.Date) < 19 {
+			full := "2023-09-23T23:59:59"
+			doc.Date += full[len(doc.Date):]
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// GetTTLDeletes returns documents deleted and milliseconds of passes of TTL
+// indexes and avg milliseconds of slow ops over time
+func (ptr *MongoDB) GetTTLDeletes(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	var substr bson.M
+	ctx := context.Background()
+	cond := bson.M{"$or": []bson.M{{"ttl_deleted": bson.M{"$ne": nil}}, {"op": bson.M{"$nin": []interface{}{"", nil}}}}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		substr = GetMongoDateSubString(toks[0], toks[1])
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetMongoDateSubString(info.Start, info.End)
+	}
+	group := bson.M{
+		"_id":     substr,
+		"deleted": bson.M{"$sum": "$ttl_deleted"},
+		"ttl_ms":  bson.M{"$sum": "$ttl_ms"},
+		"avg_ms":  bson.M{"$avg": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$op", ""}}, "$milli", nil}}},
+	}
+	project := bson.M{
+		"_id":    0,
+		"date":   "$_id",
+		"values": bson.A{"$deleted", "$ttl_ms", bson.M{"$ifNull": bson.A{"$avg_ms", 0}}},
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": group},
+		{"$project": project},
+		{"$sort": bson.M{"date": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc TimeSeries
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		if len(doc.Date) < 19 {
+			full := "2023-09-23T23:59:59"
+			doc.Date += full[len(doc.Date):]
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// GetTicketWaits returns avg and max ticket wait in ms and counts of queued ops
+func (ptr *MongoDB) GetTicketWaits(duration string) ([]TimeSeries, error) {
+	var docs []TimeSeries
+	var substr bson.M
+	ctx := context.Background()
+	cond := bson.M{"ticket_wait": bson.M{"$ne": nil}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		substr = GetMongoDateSubString(toks[0], toks[1])
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetMongoDateSubString(info.Start, info.End)
+	}
+	group := bson.M{
+		"_id":    substr,
+		"avg":    bson.M{"$avg": "$ticket_wait"},
+		"max":    bson.M{"$max": "$ticket_wait"},
+		"queued": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$ticket_wait", 0}}, 1, 0}}},
+	}
+	project := bson.M{
+		"_id":  0,
+		"date": "$_id",
+		"values": bson.A{
+			bson.M{"$divide": bson.A{"$avg", 1000}},
+			bson.M{"$divide": bson.A{"$max", 1000}},
+			"$queued"},
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": group},
+		{"$project": project},
+		{"$sort": bson.M{"date": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc TimeSeries
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		if len(doc.Date) < 19 {
+			full := "2023-09-23T23:59:59"
+			doc.Date += full[len(doc.Date):]
+		}
+		docs = append(docs, doc)
+	}
+	if err := cursor.Err(); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
 func (ptr *MongoDB) GetHatchetInfo() HatchetInfo {
 	ctx := context.Background()
 	var info HatchetInfo
@@ -176,6 +1272,114 @@ func (ptr *MongoDB) GetHatchetNames() ([]string, error) {
 	return names, err
 }
 
+// GetReslenOverTime returns total response MB, avg KB per op, and max KB of
+// ops over time
+func (ptr *MongoDB) GetReslenOverTime(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	var substr bson.M
+	ctx := context.Background()
+	cond := bson.M{"reslen": bson.M{"$gt": 0}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		substr = GetMongoDateSubString(toks[0], toks[1])
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetMongoDateSubString(info.Start, info.End)
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": bson.M{"_id": substr, "total": bson.M{"$sum": "$reslen"}, "avg": bson.M{"$avg": "$reslen"},
+			"max": bson.M{"$max": "$reslen"}}},
+		{"$project": bson.M{"_id": 0, "date": "$_id", "values": bson.A{
+			bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$total", 1048576}}, 2}},
+			bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$avg", 1024}}, 1}},
+			bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$max", 1024}}, 1}}}}},
+		{"$sort": bson.M{"date": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc TimeSeries
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		if len(doc.Date) < 19 {
+			full := "2023-09-23T23:59:59"
+			doc.Date += full[len(doc.Date):]
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// GetStorageReads returns MB read from disk, avg milliseconds reading of slow
+// ops read, and time reading in percent of the duration of slow ops, at most
+// 100 of durations logged in milliseconds, over time
+func (ptr *MongoDB) GetStorageReads(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	var substr bson.M
+	ctx := context.Background()
+	cond := bson.M{"op": bson.M{"$nin": []interface{}{"", nil}}}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		substr = GetMongoDateSubString(toks[0], toks[1])
+		cond["$and"] = []bson.M{
+			{"date": bson.M{"$gte": toks[0]}},
+			{"date": bson.M{"$lt": toks[1]}},
+		}
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetMongoDateSubString(info.Start, info.End)
+	}
+	group := bson.M{
+		"_id":    substr,
+		"bytes":  bson.M{"$sum": "$bytes_read"},
+		"micros": bson.M{"$sum": "$read_micros"},
+		"avg":    bson.M{"$avg": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$bytes_read", 0}}, "$read_micros", nil}}},
+		"milli":  bson.M{"$sum": "$milli"},
+	}
+	project := bson.M{
+		"_id":  0,
+		"date": "$_id",
+		"values": bson.A{
+			bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$bytes", 1048576}}, 2}},
+			bson.M{"$round": bson.A{bson.M{"$divide": bson.A{bson.M{"$ifNull": bson.A{"$avg", 0}}, 1000}}, 1}},
+			bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$milli", 0}},
+				bson.M{"$round": bson.A{bson.M{"$min": bson.A{bson.M{"$divide": bson.A{"$micros",
+					bson.M{"$multiply": bson.A{"$milli", 10}}}}, 100}}, 1}}, 0}}},
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName).Aggregate(ctx, []bson.M{
+		{"$match": cond},
+		{"$group": group},
+		{"$project": project},
+		{"$sort": bson.M{"date": 1}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc TimeSeries
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		if len(doc.Date) < 19 {
+			full := "2023-09-23T23:59:59"
+			doc.Date += full[len(doc.Date):]
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
 // GetAcceptedConnsCounts returns opened connection counts
 func (ptr *MongoDB) GetAcceptedConnsCounts(duration string) ([]NameValue, error) {
 	var err error
@@ -248,6 +1452,77 @@ func (ptr *MongoDB) GetAcceptedConnsCounts(duration string) ([]NameValue, error)
 	return docs, nil
 }
 
+// GetConnectionEvents returns connections accepted and ended in time order
+func (ptr *MongoDB) GetConnectionEvents() ([]ConnEvent, error) {
+	docs := []ConnEvent{}
+	ctx := context.Background()
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := ptr.db.Collection(ptr.hatchetName+"_clients").Aggregate(ctx, []bson.M{
+		{"$sort": bson.M{"_id": 1}},
+		{"$lookup": bson.M{"from": ptr.hatchetName, "localField": "_id", "foreignField": "_id", "as": "logs"}},
+		{"$unwind": "$logs"},
+		{"$project": bson.M{"_id": 0, "date": "$logs.date", "ip": 1, "port": 1,
+			"accepted": bson.M{"$gt": []interface{}{"$accepted", 0}}}},
+	}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	if err = cursor.All(ctx, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// GetConnectionsByIP returns connections accepted and ended of IPs by
+// periods in order of dates
+func (ptr *MongoDB) GetConnectionsByIP(duration string) ([]IPConns, error) {
+	ctx := context.Background()
+	docs := []IPConns{}
+	var sd, ed string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		sd, ed = toks[0], toks[1]
+	} else {
+		info := ptr.GetHatchetInfo()
+		sd, ed = info.Start, info.End
+	}
+	pipeline := []bson.M{
+		{"$lookup": bson.M{
+			"from": ptr.hatchetName,
+			"let":  bson.M{"id": "$_id"},
+			"pipeline": []bson.M{
+				{"$match": bson.M{
+					"$expr": bson.M{
+						"$and": []bson.M{
+							{"$eq": []interface{}{"$_id", "$$id"}},
+							{"$gte": []interface{}{"$date", sd}},
+							{"$lte": []interface{}{"$date", ed}},
+						}},
+				}},
+				{"$project": bson.M{"_id": 0, "date": 1}},
+			},
+			"as": "clients",
+		}},
+		{"$unwind": "$clients"},
+		{"$project": bson.M{"_id": 0, "date": "$clients.date", "ip": 1, "accepted": 1, "ended": 1}},
+		{"$group": bson.M{
+			"_id":      bson.M{"date": GetMongoDateSubString(sd, ed), "ip": "$ip"},
+			"accepted": bson.M{"$sum": "$accepted"},
+			"ended":    bson.M{"$sum": "$ended"},
+		}},
+		{"$project": bson.M{"_id": 0, "date": "$_id.date", "ip": "$_id.ip", "accepted": 1, "ended": 1}},
+		{"$sort": bson.D{{Key: "date", Value: 1}, {Key: "ip", Value: 1}}},
+	}
+	cursor, err := ptr.db.Collection(ptr.hatchetName+"_clients").Aggregate(ctx, pipeline)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	err = cursor.All(ctx, &docs)
+	return docs, err
+}
+
 // GetConnectionStats returns stats data of accepted and ended
 func (ptr *MongoDB) GetConnectionStats(chartType string, duration string) ([]RemoteClient, error) {
 	var err error
@@ -0,0 +1,42 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * mongo_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetLogDocument(t *testing.T) {
+	str := `{"t":{"$date":"2023-01-01T00:00:01.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query",` +
+		`"attr":{"type":"command","ns":"shop.orders","command":{"find":"orders","filter":{"qty":{"$gt":5},"a.b":1},"$db":"shop"},` +
+		`"planSummary":"COLLSCAN","durationMillis":150}}`
+	var doc Logv2Info
+	if err := UnmarshalLogv2([]byte(str), nil, &doc); err != nil {
+		t.Fatal(err)
+	}
+	stat, _ := AnalyzeSlowOp(&doc)
+	data := GetLogDocument(1, getDateTimeStr(doc.Timestamp), &doc, stat)
+	if data["t"] != doc.Timestamp || data["id"] != 51803 || data["op"] != "find" || data["milli"] != 150 {
+		t.Fatal("expected", "timestamp, id 51803, find, and 150", "but got", data["t"], data["id"], data["op"], data["milli"])
+	}
+	attr, ok := data["attr"].(bson.D)
+	if !ok {
+		t.Fatal("expected", "attr", "but got", data["attr"])
+	}
+	command := attr.Map()["command"].(bson.D).Map()
+	filter := command["filter"].(bson.D).Map()
+	if _, ok = command["＄db"]; !ok {
+		t.Fatal("expected", "＄db", "but got", command)
+	}
+	if filter["qty"].(bson.D)[0].Key != "＄gt" || filter["a．b"] == nil {
+		t.Fatal("expected", "＄gt and a．b", "but got", filter)
+	}
+	if _, err := bson.Marshal(data); err != nil {
+		t.Fatal(err)
+	}
+}
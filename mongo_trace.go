@@ -0,0 +1,82 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * mongo_trace.go
+ */
+
+package hatchet
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetConnectionLogs returns logs of the connection of a log, from the
+// connection accepted to the connection ended around the log
+func (ptr *MongoDB) GetConnectionLogs(id int) ([]TraceLog, error) {
+	ctx := context.Background()
+	collection := ptr.db.Collection(ptr.hatchetName)
+	var doc TraceLog
+	if err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+		return nil, err
+	}
+	filter := bson.M{"_id": id}
+	if connID := GetConnectionID(doc.Context); connID > 0 {
+		findID := func(filter bson.M, order int) (int, bool) {
+			var doc TraceLog
+			opts := options.FindOne().SetSort(bson.M{"_id": order}).SetProjection(bson.M{"_id": 1})
+			if err := collection.FindOne(ctx, filter, opts).Decode(&doc); err != nil {
+				return 0, false
+			}
+			return doc.ID, true
+		}
+		start := 0
+		if endedID, ok := findID(bson.M{"_id": bson.M{"$lt": id}, "context": doc.Context, "msg": "Connection ended"}, -1); ok {
+			start = endedID + 1 // connection ids are reused after restarts
+		}
+		filters := bson.A{}
+		pattern := regexp.QuoteMeta(fmt.Sprintf("#%v (", connID))
+		if acceptedID, ok := findID(bson.M{"_id": bson.M{"$lt": id}, "msg": "Connection accepted",
+			"message": bson.M{"$regex": pattern}}, -1); ok && acceptedID >= start {
+			start = acceptedID
+			filters = append(filters, bson.M{"_id": acceptedID})
+		}
+		ids := bson.M{"$gte": start}
+		if endedID, ok := findID(bson.M{"_id": bson.M{"$gt": id}, "context": doc.Context, "msg": "Connection ended"}, 1); ok {
+			ids["$lte"] = endedID
+		}
+		filter = bson.M{"$or": append(filters, bson.M{"context": doc.Context, "_id": ids})}
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	return getTraceLogs(ctx, cursor)
+}
+
+func getTraceLogs(ctx context.Context, cursor *mongo.Cursor) ([]TraceLog, error) {
+	docs := []TraceLog{}
+	for cursor.Next(ctx) {
+		var doc TraceLog
+		if err := cursor.Decode(&doc); err != nil {
+			return docs, err
+		}
+		var values bson.M
+		if err := cursor.Decode(&values); err != nil {
+			return docs, err
+		}
+		doc.Metrics = map[string]int{}
+		for _, metric := range TRACE_METRICS {
+			if value, ok := values[metric]; ok && value != nil && (doc.Op != "" || ToInt(value) != 0) {
+				doc.Metrics[metric] = ToInt(value)
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return docs, cursor.Err()
+}
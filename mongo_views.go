@@ -0,0 +1,40 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * mongo_views.go
+ */
+
+package hatchet
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetViews returns saved views sorted by names
+func (ptr *MongoDB) GetViews() ([]SavedView, error) {
+	docs := []SavedView{}
+	ctx := context.Background()
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cursor, err := ptr.db.Collection(VIEWS_TABLE).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return docs, err
+	}
+	defer cursor.Close(ctx)
+	err = cursor.All(ctx, &docs)
+	return docs, err
+}
+
+// SaveView inserts or replaces a view
+func (ptr *MongoDB) SaveView(doc SavedView) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := ptr.db.Collection(VIEWS_TABLE).ReplaceOne(context.Background(), bson.M{"_id": doc.Name}, doc, opts)
+	return err
+}
+
+// DeleteView removes a view
+func (ptr *MongoDB) DeleteView(name string) error {
+	_, err := ptr.db.Collection(VIEWS_TABLE).DeleteOne(context.Background(), bson.M{"_id": name})
+	return err
+}
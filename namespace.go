@@ -0,0 +1,127 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * namespace.go
+ */
+
+package hatchet
+
+import (
+	"math"
+	"sort"
+)
+
+// NamespaceTrend is slow ops and their average milliseconds of a namespace
+// in a time bucket
+type NamespaceTrend struct {
+	AvgMilli float64 `json:"avg_ms"`
+	Count    int     `json:"count"`
+	Date     string  `json:"date"`
+}
+
+// NamespaceDrilldown is everything known of a namespace, i.e. slow op shapes,
+// the op mix, the latency trend, index usage, and query targeting
+type NamespaceDrilldown struct {
+	Hatchet     string            `json:"hatchet"`
+	Indexes     []NameValue       `json:"indexes"` // slow ops by plan, COLLSCAN or the index used
+	Namespace   string            `json:"ns"`
+	Ops         []NameValue       `json:"ops"` // slow ops by op
+	Shapes      []OpStat          `json:"shapes"`
+	Suggestions []IndexSuggestion `json:"suggestions"`
+	Targeting   []TargetingStat   `json:"targeting"`
+	Trend       []NamespaceTrend  `json:"trend"`
+}
+
+// GetNamespaceDrilldown returns everything known of a namespace of a hatchet
+func GetNamespaceDrilldown(hatchetName string, ns string) (*NamespaceDrilldown, error) {
+	drilldown := &NamespaceDrilldown{Hatchet: hatchetName, Namespace: ns, Shapes: []OpStat{},
+		Targeting: []TargetingStat{}}
+	dbase, err := GetDatabase(hatchetName)
+	if err != nil {
+		return drilldown, err
+	}
+	defer dbase.Close()
+	ops, err := dbase.GetSlowOps("total_ms", "DESC", false)
+	if err != nil {
+		return drilldown, err
+	}
+	for _, op := range ops {
+		if op.Namespace == ns {
+			drilldown.Shapes = append(drilldown.Shapes, op)
+		}
+	}
+	drilldown.Ops, drilldown.Indexes = getNamespaceOpMix(drilldown.Shapes)
+	drilldown.Suggestions = GetIndexSuggestions(drilldown.Shapes)
+	counts, err := dbase.GetAverageOpTime("", "")
+	if err != nil {
+		return drilldown, err
+	}
+	drilldown.Trend = getNamespaceTrend(counts, ns)
+	stats, err := dbase.GetTargetingStats()
+	if err != nil {
+		return drilldown, err
+	}
+	for _, stat := range stats {
+		if stat.Namespace == ns {
+			drilldown.Targeting = append(drilldown.Targeting, stat)
+		}
+	}
+	return drilldown, nil
+}
+
+// getNamespaceOpMix returns slow ops by op and by plan of shapes, the most
+// first
+func getNamespaceOpMix(shapes []OpStat) ([]NameValue, []NameValue) {
+	byOp, byPlan := map[string]int{}, map[string]int{}
+	for _, shape := range shapes {
+		byOp[shape.Op] += shape.Count
+		plan := shape.Index
+		if plan == "" {
+			plan = "-"
+		}
+		byPlan[plan] += shape.Count
+	}
+	return getSortedNameValues(byOp), getSortedNameValues(byPlan)
+}
+
+// getNamespaceTrend returns slow ops and their average milliseconds of a
+// namespace by time bucket
+func getNamespaceTrend(counts []OpCount, ns string) []NamespaceTrend {
+	trends := map[string]*NamespaceTrend{}
+	dates := []string{}
+	for _, doc := range counts {
+		if doc.Namespace != ns {
+			continue
+		}
+		trend := trends[doc.Date]
+		if trend == nil {
+			trend = &NamespaceTrend{Date: doc.Date}
+			trends[doc.Date] = trend
+			dates = append(dates, doc.Date)
+		}
+		trend.AvgMilli += doc.Milli * float64(doc.Count) // total until averaged below
+		trend.Count += doc.Count
+	}
+	sort.Strings(dates)
+	docs := []NamespaceTrend{}
+	for _, date := range dates {
+		trend := trends[date]
+		trend.AvgMilli = math.Round(10*trend.AvgMilli/float64(trend.Count)) / 10
+		docs = append(docs, *trend)
+	}
+	return docs
+}
+
+// getSortedNameValues returns counts by name, the most first
+func getSortedNameValues(counts map[string]int) []NameValue {
+	docs := []NameValue{}
+	for name, count := range counts {
+		docs = append(docs, NameValue{name, count})
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].Value != docs[j].Value {
+			return docs[i].Value > docs[j].Value
+		}
+		return docs[i].Name < docs[j].Name
+	})
+	return docs
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * namespace_handler.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// NamespaceHandler responds to API calls
+func NamespaceHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	/** APIs
+	 * /hatchets/{hatchet}/ns/{db.coll}
+	 */
+	hatchetName := params.ByName("hatchet")
+	ns := params.ByName("attr")
+	if GetLogv2().verbose {
+		log.Println("NamespaceHandler", r.URL.Path, hatchetName, ns)
+	}
+	drilldown, err := GetNamespaceDrilldown(hatchetName, ns)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	templ, err := GetNamespaceTemplate()
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	doc := map[string]interface{}{"Hatchet": hatchetName, "Drilldown": drilldown,
+		"Ratio": GetLogv2().GetTargetingRatio()}
+	if err = templ.Execute(w, doc); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+}
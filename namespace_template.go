@@ -0,0 +1,120 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * namespace_template.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetNamespaceTemplate returns HTML
+func GetNamespaceTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+	<h3>{{.Drilldown.Namespace}}
+		<button class='btn' onClick="javascript:location.href='/api/hatchet/v1.0/hatchets/{{.Hatchet}}/ns/{{.Drilldown.Namespace}}'; return false;"
+			title='JSON'><i class='fa fa-download'></i></button></h3>
+{{if .Drilldown.Shapes}}
+	<table style='float: left; margin: 10px 10px;'>
+		<caption>Op Mix</caption>
+		<tr><th>op</th><th>count</th></tr>
+	{{range $doc := .Drilldown.Ops}}
+		<tr><td>{{$doc.Name}}</td><td align='right'>{{numPrinter $doc.Value}}</td></tr>
+	{{end}}
+	</table>
+	<table style='float: left; margin: 10px 10px;'>
+		<caption>Index Usage</caption>
+		<tr><th>plan</th><th>count</th></tr>
+	{{range $doc := .Drilldown.Indexes}}
+		{{if eq $doc.Name "COLLSCAN"}}
+		<tr><td style='color: red;'>{{$doc.Name}}</td><td align='right'>{{numPrinter $doc.Value}}</td></tr>
+		{{else}}
+		<tr><td class='break'>{{$doc.Name}}</td><td align='right'>{{numPrinter $doc.Value}}</td></tr>
+		{{end}}
+	{{end}}
+	</table>
+	<table style='float: left; margin: 10px 10px;'>
+		<caption>Latency Trend</caption>
+		<tr><th>date</th><th>count</th><th>avg ms</th></tr>
+	{{range $doc := .Drilldown.Trend}}
+		<tr><td>{{$doc.Date}}</td><td align='right'>{{numPrinter $doc.Count}}</td>
+			<td align='right'>{{formatMilli $doc.AvgMilli}}</td></tr>
+	{{end}}
+	</table>
+	<table width='100%' style='clear: left;'>
+		<caption>Slow Op Shapes</caption>
+		<tr><th>op</th><th>count</th><th>avg ms</th><th>p95 ms</th><th>max ms</th><th>total ms</th><th>index</th>
+			<th>query pattern</th><th>sort</th></tr>
+	{{range $op := .Drilldown.Shapes}}
+		<tr><td>{{$op.Op}}</td><td align='right'>{{numPrinter $op.Count}}</td>
+			<td align='right'>{{formatMilli $op.AvgMilli}}</td>
+			<td align='right'>{{numPrinter $op.P95Milli}}</td>
+			<td align='right'>{{numPrinter $op.MaxMilli}}</td>
+			<td align='right'>{{numPrinter $op.TotalMilli}}</td>
+			{{if eq $op.Index "COLLSCAN"}}
+			<td style='color: red;'>{{$op.Index}}</td>
+			{{else}}
+			<td class='break'>{{$op.Index}}</td>
+			{{end}}
+			<td class='break'>{{$op.QueryPattern}}</td>
+			<td class='break'>{{$op.SortPattern}}</td>
+		</tr>
+	{{end}}
+	</table>
+	{{if .Drilldown.Targeting}}
+	<p/>
+	<table width='100%'>
+		<caption>Query Targeting</caption>
+		<tr><th>op</th><th>count</th><th>keys/returned</th><th>docs/returned</th><th>query pattern</th></tr>
+	{{range $op := .Drilldown.Targeting}}
+		<tr><td>{{$op.Op}}</td><td align='right'>{{numPrinter $op.Count}}</td>
+		{{if $op.IsPoorlyTargeted $.Ratio}}
+			<td align='right' style='color: red;'>{{formatRatio $op.GetKeysRatio}}</td>
+			<td align='right' style='color: red;'>{{formatRatio $op.GetDocsRatio}}</td>
+		{{else}}
+			<td align='right'>{{formatRatio $op.GetKeysRatio}}</td>
+			<td align='right'>{{formatRatio $op.GetDocsRatio}}</td>
+		{{end}}
+			<td class='break'>{{$op.QueryPattern}}</td>
+		</tr>
+	{{end}}
+	</table>
+	{{end}}
+	{{if .Drilldown.Suggestions}}
+	<p/>
+	<table width='100%'>
+		<caption>Index Suggestions</caption>
+		<tr><th>op</th><th>count</th><th>index used</th><th>query pattern</th><th>sort</th><th>suggested index</th></tr>
+	{{range $s := .Drilldown.Suggestions}}
+		<tr><td>{{$s.Op}}</td><td align='right'>{{numPrinter $s.Count}}</td>
+			<td class='break'>{{$s.Plan}}</td><td class='break'>{{$s.QueryPattern}}</td>
+			<td class='break'>{{$s.SortPattern}}</td>
+			<td class='break' title='{{$s.GetCreateIndex}}'>{{$s.Index}}</td>
+		</tr>
+	{{end}}
+	</table>
+	{{end}}
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no slow ops found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"formatMilli": func(ms float64) string {
+			return fmt.Sprintf("%.0f", ms)
+		},
+		"formatRatio": func(ratio float64) string {
+			return fmt.Sprintf("%.1f", ratio)
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * namespace_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestGetNamespaceOpMix(t *testing.T) {
+	shapes := []OpStat{
+		{Op: "find", Namespace: "shop.orders", Index: "COLLSCAN", Count: 10},
+		{Op: "update", Namespace: "shop.orders", Index: "{ _id:1 }", Count: 20},
+		{Op: "find", Namespace: "shop.orders", Index: "{ _id:1 }", Count: 15},
+		{Op: "insert", Namespace: "shop.orders", Count: 1},
+	}
+	ops, indexes := getNamespaceOpMix(shapes)
+	if len(ops) != 3 || ops[0].Name != "find" || ops[0].Value != 25 {
+		t.Fatal("expected", "find of 25 first", "but got", ops)
+	}
+	if len(indexes) != 3 || indexes[0].Name != "{ _id:1 }" || indexes[0].Value != 35 || indexes[2].Name != "-" {
+		t.Fatal("expected", "{ _id:1 } of 35 first", "but got", indexes)
+	}
+}
+
+func TestGetNamespaceTrend(t *testing.T) {
+	counts := []OpCount{
+		{Date: "2023-01-01T00:01:59", Count: 1, Milli: 100, Op: "find", Namespace: "shop.orders"},
+		{Date: "2023-01-01T00:00:59", Count: 2, Milli: 100, Op: "find", Namespace: "shop.orders"},
+		{Date: "2023-01-01T00:00:59", Count: 1, Milli: 400, Op: "update", Namespace: "shop.orders"},
+		{Date: "2023-01-01T00:00:59", Count: 5, Milli: 900, Op: "find", Namespace: "shop.items"},
+	}
+	trend := getNamespaceTrend(counts, "shop.orders")
+	if len(trend) != 2 || trend[0].Date != "2023-01-01T00:00:59" || trend[0].Count != 3 || trend[0].AvgMilli != 200 {
+		t.Fatal("expected", "3 ops of 200 ms first", "but got", trend)
+	}
+}
@@ -0,0 +1,314 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * oidc.go
+ */
+
+package hatchet
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OIDCProvider signs in users of an OpenID Connect issuer by the
+// authorization code flow and verifies ID tokens by keys of the issuer
+type OIDCProvider struct {
+	AuthURL  string
+	Issuer   string
+	JWKSURL  string
+	TokenURL string
+
+	clientID     string
+	clientSecret string
+	fetched      time.Time // when keys were last fetched
+	httpClient   *http.Client
+	keys         map[string]crypto.PublicKey
+	mutex        sync.Mutex
+}
+
+// NewOIDCProvider returns OIDCProvider of endpoints discovered of an issuer
+func NewOIDCProvider(issuer string, clientID string, clientSecret string) (*OIDCProvider, error) {
+	provider := &OIDCProvider{clientID: clientID, clientSecret: clientSecret,
+		httpClient: &http.Client{Timeout: 30 * time.Second}, keys: map[string]crypto.PublicKey{}}
+	var doc struct {
+		AuthURL  string `json:"authorization_endpoint"`
+		Issuer   string `json:"issuer"`
+		JWKSURL  string `json:"jwks_uri"`
+		TokenURL string `json:"token_endpoint"`
+	}
+	if err := provider.getJSON(strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("discovery of %v failed, %v", issuer, err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("issuer %v discovered, expected %v", doc.Issuer, issuer)
+	} else if doc.AuthURL == "" || doc.TokenURL == "" || doc.JWKSURL == "" {
+		return nil, fmt.Errorf("discovery of %v missing endpoints", issuer)
+	}
+	provider.AuthURL, provider.Issuer, provider.JWKSURL, provider.TokenURL = doc.AuthURL, doc.Issuer, doc.JWKSURL, doc.TokenURL
+	return provider, nil
+}
+
+// GetAuthURL returns the URL of the authorization endpoint to sign in
+func (ptr *OIDCProvider) GetAuthURL(redirectURL string, state string, nonce string) string {
+	query := url.Values{}
+	query.Set("client_id", ptr.clientID)
+	query.Set("nonce", nonce)
+	query.Set("redirect_uri", redirectURL)
+	query.Set("response_type", "code")
+	query.Set("scope", "openid email profile")
+	query.Set("state", state)
+	sep := "?"
+	if strings.Contains(ptr.AuthURL, "?") {
+		sep = "&"
+	}
+	return ptr.AuthURL + sep + query.Encode()
+}
+
+// Exchange returns the ID token of an authorization code
+func (ptr *OIDCProvider) Exchange(code string, redirectURL string) (string, error) {
+	if code == "" {
+		return "", errors.New("missing authorization code")
+	}
+	form := url.Values{}
+	form.Set("code", code)
+	form.Set("grant_type", "authorization_code")
+	form.Set("redirect_uri", redirectURL)
+	req, err := http.NewRequest(http.MethodPost, ptr.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(ptr.clientID), url.QueryEscape(ptr.clientSecret))
+	resp, err := ptr.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var doc struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+		IDToken     string `json:"id_token"`
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if err = json.Unmarshal(body, &doc); err != nil {
+		return "", fmt.Errorf("token endpoint %v, %v", resp.Status, err)
+	} else if doc.Error != "" {
+		return "", fmt.Errorf("token endpoint %v %v", doc.Error, doc.Description)
+	} else if doc.IDToken == "" {
+		return "", errors.New("token endpoint returned no id_token")
+	}
+	return doc.IDToken, nil
+}
+
+// Verify returns claims of an ID token signed by the issuer to the client,
+// nonce is checked if not empty
+func (ptr *OIDCProvider) Verify(token string, nonce string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	claims := map[string]interface{}{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	key, err := ptr.getKey(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err = verifyJWTSignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+	now := float64(time.Now().Unix())
+	if iss, _ := claims["iss"].(string); iss != ptr.Issuer {
+		return nil, fmt.Errorf("token issuer %v, expected %v", iss, ptr.Issuer)
+	} else if !hasAudience(claims["aud"], ptr.clientID) {
+		return nil, errors.New("token not issued to the client")
+	} else if exp, ok := claims["exp"].(float64); !ok || now > exp+60 {
+		return nil, errors.New("token expired")
+	} else if nbf, ok := claims["nbf"].(float64); ok && now < nbf-60 {
+		return nil, errors.New("token not valid yet")
+	} else if n, _ := claims["nonce"].(string); nonce != "" && n != nonce {
+		return nil, errors.New("token nonce mismatched")
+	}
+	return claims, nil
+}
+
+// getKey returns the public key of a key id, keys are fetched again of an
+// unknown key id as the issuer rotates keys, at most once a minute
+func (ptr *OIDCProvider) getKey(kid string) (crypto.PublicKey, error) {
+	ptr.mutex.Lock()
+	defer ptr.mutex.Unlock()
+	if key, ok := ptr.keys[kid]; ok {
+		return key, nil
+	} else if time.Since(ptr.fetched) < time.Minute {
+		return nil, fmt.Errorf("unknown signing key %v", kid)
+	}
+	ptr.fetched = time.Now()
+	var doc struct {
+		Keys []struct {
+			Crv string `json:"crv"`
+			E   string `json:"e"`
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			Use string `json:"use"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := ptr.getJSON(ptr.JWKSURL, &doc); err != nil {
+		return nil, err
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if jwk.Kty == "RSA" {
+			n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+			if err != nil {
+				continue
+			}
+			e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+			if err != nil || len(e) > 4 {
+				continue
+			}
+			keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		} else if jwk.Kty == "EC" {
+			curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+			curve, ok := curves[jwk.Crv]
+			if !ok {
+				continue
+			}
+			x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+			if err != nil {
+				continue
+			}
+			y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+			if err != nil {
+				continue
+			}
+			keys[jwk.Kid] = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	ptr.keys = keys
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %v", kid)
+}
+
+// getJSON decodes the JSON response of a GET request into v
+func (ptr *OIDCProvider) getJSON(uri string, v interface{}) error {
+	resp, err := ptr.httpClient.Get(uri)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%v %v", resp.Status, uri)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// decodeJWTPart decodes a base64url encoded JSON part of a JWT
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errors.New("malformed token")
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return errors.New("malformed token")
+	}
+	return nil
+}
+
+// verifyJWTSignature verifies a signature of RS256/384/512 or ES256/384/512
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed []byte, signature []byte) error {
+	var hash crypto.Hash
+	var digest []byte
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported token algorithm %v", alg)
+	}
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+		sum := sha256.Sum256(signed)
+		digest = sum[:]
+	case "384":
+		hash = crypto.SHA384
+		sum := sha512.Sum384(signed)
+		digest = sum[:]
+	case "512":
+		hash = crypto.SHA512
+		sum := sha512.Sum512(signed)
+		digest = sum[:]
+	default:
+		return fmt.Errorf("unsupported token algorithm %v", alg)
+	}
+	if strings.HasPrefix(alg, "RS") {
+		if rsaKey, ok := key.(*rsa.PublicKey); ok && rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature) == nil {
+			return nil
+		}
+	} else if strings.HasPrefix(alg, "ES") {
+		if ecKey, ok := key.(*ecdsa.PublicKey); ok {
+			size := (ecKey.Curve.Params().BitSize + 7) / 8
+			if len(signature) == 2*size {
+				r := new(big.Int).SetBytes(signature[:size])
+				s := new(big.Int).SetBytes(signature[size:])
+				if ecdsa.Verify(ecKey, digest, r, s) {
+					return nil
+				}
+			}
+		}
+	} else {
+		return fmt.Errorf("unsupported token algorithm %v", alg)
+	}
+	return errors.New("invalid token signature")
+}
+
+// hasAudience returns true if the aud claim, a string or an array, has the
+// client id
+func hasAudience(aud interface{}, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []interface{}:
+		for _, a := range v {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
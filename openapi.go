@@ -0,0 +1,306 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * openapi.go
+ */
+
+package hatchet
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// APIParam is a query string parameter of an API
+type APIParam struct {
+	Description string
+	Name        string
+	Type        string // string, integer, number, or boolean
+}
+
+// APIEndpoint describes an API, responses are JSON objects of fields of
+// values of their types unless of a content type
+type APIEndpoint struct {
+	Content  string // media type other than JSON, e.g. text/csv
+	Method   string
+	Params   []APIParam
+	Path     string
+	Response map[string]interface{}
+	Summary  string
+	Tag      string
+}
+
+var apiDurationParam = APIParam{"time range, {start},{end}", "duration", "string"}
+var apiTopNParam = APIParam{"number of rows", "topN", "integer"}
+
+// API_ENDPOINTS lists APIs described by the OpenAPI specification
+var API_ENDPOINTS = []APIEndpoint{
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/slowops", Tag: "stats", Summary: "Slow op shapes",
+		Params: []APIParam{{"ops of orderBy descending, e.g. avg_ms or total_ms", "orderBy", "string"},
+			{"namespace regular expression", "ns", "string"}, {"comma separated ops", "op", "string"},
+			{"minimum average milliseconds", "minDuration", "number"},
+			{"field to sort by, descending if prefixed with -", "sort", "string"},
+			{"shapes skipped", "offset", "integer"}, {"maximum shapes, all if 0", "limit", "integer"},
+			{"shapes above slow thresholds only", "slow", "boolean"}, {"csv to download as CSV", "format", "string"}},
+		Response: map[string]interface{}{"hatchet": "", "has_more": false, "offset": 0, "limit": 0, "total": 0, "ops": []OpStat{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/audit", Tag: "stats", Summary: "Audit data by category",
+		Response: map[string]interface{}{"hatchet": "", "audit": map[string][]NameValues{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/writes", Tag: "stats", Summary: "Write stats",
+		Response: map[string]interface{}{"hatchet": "", "writes": []WriteStat{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/locks", Tag: "stats", Summary: "Lock stats and waits",
+		Params:   []APIParam{apiTopNParam},
+		Response: map[string]interface{}{"hatchet": "", "locks": []LockStats{}, "waits": []LockWait{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/plans", Tag: "stats", Summary: "Plan timelines of shapes",
+		Params:   []APIParam{apiDurationParam},
+		Response: map[string]interface{}{"hatchet": "", "plans": []PlanTimeline{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/planning", Tag: "stats", Summary: "Query planning time",
+		Response: map[string]interface{}{"hatchet": "", "planning": []map[string]interface{}{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/replans", Tag: "stats", Summary: "Replanned shapes",
+		Response: map[string]interface{}{"hatchet": "", "replans": []map[string]interface{}{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/targeting", Tag: "stats", Summary: "Query targeting",
+		Params:   []APIParam{{"minimum examined to returned ratio flagged", "ratio", "integer"}},
+		Response: map[string]interface{}{"hatchet": "", "ratio": 0, "namespaces": []TargetingStat{}, "flagged": []TargetingStat{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/sorts", Tag: "stats", Summary: "Blocking sorts and spills to disk",
+		Response: map[string]interface{}{"hatchet": "", "shapes": []BlockingSortStat{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/getmores", Tag: "stats", Summary: "Initial execution and getMore latency",
+		Response: map[string]interface{}{"hatchet": "", "shapes": []GetMoreStat{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/stages", Tag: "stats", Summary: "Aggregation pipeline stages",
+		Response: map[string]interface{}{"hatchet": "", "stages": []map[string]interface{}{}, "pipelines": []PipelineStat{},
+			"lookup_heavy": []string{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/churn", Tag: "stats", Summary: "Connection churn by client",
+		Response: map[string]interface{}{"hatchet": "", "clients": []ClientChurn{}, "lifetime_buckets": []string{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/reslen", Tag: "stats", Summary: "Namespaces and shapes by response bytes",
+		Params:   []APIParam{apiTopNParam},
+		Response: map[string]interface{}{"hatchet": "", "namespaces": []ReslenStat{}, "shapes": []ReslenStat{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/apps", Tag: "stats", Summary: "Ops by application",
+		Params:   []APIParam{{"namespace regular expression", "ns", "string"}},
+		Response: map[string]interface{}{"hatchet": "", "apps": []AppSummary{}, "ops": []AppStat{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/elections", Tag: "stats", Summary: "Replica set elections",
+		Response: map[string]interface{}{"hatchet": "", "events": []ElectionEvent{}, "elected": 0, "stepped_down": 0}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/sharding", Tag: "stats", Summary: "Ops of mongos by shards",
+		Response: map[string]interface{}{"hatchet": "", "sharding": []map[string]interface{}{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/chunks", Tag: "stats", Summary: "Chunk migrations",
+		Response: map[string]interface{}{"hatchet": "", "events": []ChunkEvent{}, "collections": []map[string]interface{}{},
+			"balancer_rounds": 0}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/transactions", Tag: "stats", Summary: "Transactions",
+		Response: map[string]interface{}{"hatchet": "", "transactions": []TxnStat{}, "abort_rate": 0.0}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/config", Tag: "stats", Summary: "Configuration changes",
+		Response: map[string]interface{}{"hatchet": "", "changes": []ConfigChange{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/server", Tag: "stats", Summary: "Server info",
+		Response: map[string]interface{}{"hatchet": "", "server": map[string]string{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/sources", Tag: "stats", Summary: "Logs by source of merged logs",
+		Response: map[string]interface{}{"hatchet": "", "sources": []SourceStat{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/severity", Tag: "stats", Summary: "Warnings and errors by message",
+		Params:   []APIParam{{"W, E, or F", "severity", "string"}, apiTopNParam},
+		Response: map[string]interface{}{"hatchet": "", "messages": []SeverityStat{}, "totals": map[string]interface{}{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/stats/templates", Tag: "stats", Summary: "Log message templates",
+		Params:   []APIParam{{"log component", "component", "string"}, {"rare templates only", "rare", "boolean"}},
+		Response: map[string]interface{}{"hatchet": "", "templates": []NameValues{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/indexes/suggestions", Tag: "indexes", Summary: "Index suggestions",
+		Params:   []APIParam{{"namespace regular expression", "ns", "string"}},
+		Response: map[string]interface{}{"hatchet": "", "suggestions": []IndexSuggestion{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/ns/{ns}", Tag: "stats", Summary: "Namespace drilldown",
+		Response: map[string]interface{}{"": NamespaceDrilldown{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/logs/all", Tag: "logs", Summary: "Logs",
+		Params: []APIParam{{"log component", "component", "string"}, {"thread or connection name", "context", "string"},
+			{"F, E, W, I, or D1 to D5", "severity", "string"}, {"source of merged logs", "source", "string"}, apiDurationParam,
+			{"{offset},{limit} or {limit}", "limit", "string"}},
+		Response: map[string]interface{}{"hatchet": "", "has_more": false, "offset": 0, "limit": 0, "logs": []LegacyLog{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/logs/slowops", Tag: "logs", Summary: "Slowest ops logged",
+		Params:   []APIParam{apiTopNParam},
+		Response: map[string]interface{}{"hatchet": "", "has_more": false, "offset": 0, "limit": 0, "logs": []LegacyLog{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/logs/search", Tag: "logs", Summary: "Full text search of messages",
+		Params: []APIParam{{"words, \"phrases\", prefix*, AND, OR, NOT, and parentheses", "q", "string"},
+			{"{offset},{limit} or {limit}", "limit", "string"}},
+		Response: map[string]interface{}{"hatchet": "", "q": "", "has_more": false, "offset": 0, "limit": 0, "logs": []LegacyLog{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/logs/raw", Tag: "logs", Summary: "Raw log lines", Content: "text/plain",
+		Params: []APIParam{{"log component", "component", "string"}, {"thread or connection name", "context", "string"},
+			apiDurationParam, {"F, E, W, I, or D1 to D5", "severity", "string"}, {"namespace", "ns", "string"},
+			{"op", "op", "string"}, {"query pattern", "filter", "string"}, {"index used", "_index", "string"},
+			{"source of merged logs", "source", "string"}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/report/summary", Tag: "reports", Summary: "Markdown summary",
+		Content: "text/markdown", Params: []APIParam{apiTopNParam}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/report/html", Tag: "reports", Summary: "Self-contained HTML report",
+		Content: "text/html", Params: []APIParam{apiTopNParam}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/compare/{other}", Tag: "reports", Summary: "Comparison of two hatchets",
+		Response: map[string]interface{}{"": Comparison{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/diff/{other}", Tag: "reports", Summary: "Differences of two hatchets or durations",
+		Params:   []APIParam{{"duration of A, {start},{end}", "a", "string"}, {"duration of B, {start},{end}", "b", "string"}},
+		Response: map[string]interface{}{"": Diff{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/trace/{id}", Tag: "logs", Summary: "Trace of a slow op",
+		Params:   []APIParam{{"download as an attachment", "download", "boolean"}},
+		Response: map[string]interface{}{"": Trace{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/bookmarks/all", Tag: "bookmarks", Summary: "Bookmarks of shapes",
+		Response: map[string]interface{}{"hatchet": "", "bookmarks": []Bookmark{}}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/bookmarks/{hash}", Tag: "bookmarks", Summary: "Bookmark a shape",
+		Method: http.MethodPost, Params: []APIParam{{"op", "op", "string"}, {"namespace", "ns", "string"},
+			{"query pattern", "filter", "string"}, {"index used", "index", "string"}, {"note", "note", "string"}},
+		Response: map[string]interface{}{"ok": 0, "hash": ""}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/bookmarks/{hash}", Tag: "bookmarks", Summary: "Remove a bookmark",
+		Method: http.MethodDelete, Response: map[string]interface{}{"ok": 0, "hash": ""}},
+	{Path: "/api/hatchet/v1.0/views", Tag: "views", Summary: "Saved views of filters",
+		Response: map[string]interface{}{"ok": 0, "views": []SavedView{}}},
+	{Path: "/api/hatchet/v1.0/views/{name}", Tag: "views", Summary: "Save a view", Method: http.MethodPost,
+		Params: []APIParam{{"page, {category}/{attr}, e.g. stats/slowops", "path", "string"},
+			{"query string of filters, e.g. ns=shop.orders&duration={start},{end}", "query", "string"}},
+		Response: map[string]interface{}{"ok": 0, "name": "", "view": SavedView{}}},
+	{Path: "/api/hatchet/v1.0/views/{name}", Tag: "views", Summary: "Remove a view", Method: http.MethodDelete,
+		Response: map[string]interface{}{"ok": 0, "name": ""}},
+	{Path: "/api/hatchet/v1.0/hatchets/{hatchet}/migrations/all", Tag: "admin", Summary: "Applied schema migrations",
+		Response: map[string]interface{}{"hatchet": "", "schema_version": 0, "migrations": []MigrationRecord{}}},
+	{Path: "/api/hatchet/v1.0/mongodb/{mongo}/drivers/{driver}", Tag: "admin", Summary: "Driver compatibility",
+		Params:   []APIParam{{"driver version", "compatibleWith", "string"}},
+		Response: map[string]interface{}{"ok": 0, "MongoDB": "", "driver": map[string]interface{}{}}},
+	{Path: "/api/hatchet/v1.0/schema", Tag: "admin", Summary: "Fields of logs and op shapes",
+		Response: map[string]interface{}{"ok": 0, "schema": Schema{}}},
+	{Path: "/api/hatchet/v1.0/prune", Tag: "admin", Summary: "Hatchets to prune",
+		Params:   []APIParam{{"age, e.g. 30d, 2w, or 12h", "olderThan", "string"}},
+		Response: map[string]interface{}{"ok": 0, "dry_run": false, "hatchets": []HatchetAge{}}},
+	{Path: "/api/hatchet/v1.0/prune", Tag: "admin", Summary: "Prune hatchets", Method: http.MethodDelete,
+		Params:   []APIParam{{"age, e.g. 30d, 2w, or 12h", "olderThan", "string"}},
+		Response: map[string]interface{}{"ok": 0, "dry_run": false, "hatchets": []HatchetAge{}}},
+	{Path: "/metrics", Tag: "admin", Summary: "Prometheus metrics", Content: "text/plain"},
+	{Path: "/api/openapi.json", Tag: "admin", Summary: "OpenAPI specification", Response: map[string]interface{}{"": map[string]interface{}{}}},
+}
+
+var apiPathParam = regexp.MustCompile(`{(\w+)}`)
+
+// GetOpenAPISpec returns the OpenAPI 3.0 specification of API_ENDPOINTS,
+// schemas of components are of types of responses
+func GetOpenAPISpec(version string) map[string]interface{} {
+	schemas := map[string]interface{}{
+		"Error": map[string]interface{}{"type": "object", "properties": map[string]interface{}{
+			"ok": map[string]interface{}{"type": "integer"}, "error": map[string]interface{}{"type": "string"}}},
+	}
+	paths := map[string]interface{}{}
+	for _, endpoint := range API_ENDPOINTS {
+		params := []interface{}{}
+		for _, match := range apiPathParam.FindAllStringSubmatch(endpoint.Path, -1) {
+			params = append(params, map[string]interface{}{"name": match[1], "in": "path", "required": true,
+				"schema": map[string]interface{}{"type": "string"}})
+		}
+		for _, param := range endpoint.Params {
+			params = append(params, map[string]interface{}{"name": param.Name, "in": "query", "description": param.Description,
+				"schema": map[string]interface{}{"type": param.Type}})
+		}
+		var schema map[string]interface{}
+		if value, ok := endpoint.Response[""]; ok {
+			schema = getOpenAPISchema(reflect.TypeOf(value), schemas)
+		} else {
+			schema = map[string]interface{}{"type": "object", "properties": getOpenAPIProperties(endpoint.Response, schemas)}
+		}
+		content := map[string]interface{}{"application/json": map[string]interface{}{"schema": map[string]interface{}{
+			"oneOf": []interface{}{schema, map[string]interface{}{"$ref": "#/components/schemas/Error"}}}}}
+		if endpoint.Content != "" {
+			content = map[string]interface{}{endpoint.Content: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}}
+		}
+		method := strings.ToLower(endpoint.Method)
+		if method == "" {
+			method = "get"
+		}
+		if paths[endpoint.Path] == nil {
+			paths[endpoint.Path] = map[string]interface{}{}
+		}
+		paths[endpoint.Path].(map[string]interface{})[method] = map[string]interface{}{
+			"summary":     endpoint.Summary,
+			"operationId": getOpenAPIOperationID(method, endpoint.Path),
+			"tags":        []string{endpoint.Tag},
+			"parameters":  params,
+			"responses": map[string]interface{}{"200": map[string]interface{}{
+				"description": "OK, errors are of ok 0 and error", "content": content}},
+		}
+	}
+	return map[string]interface{}{
+		"openapi":    "3.0.3",
+		"info":       map[string]interface{}{"title": "Hatchet API", "version": version},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+}
+
+// getOpenAPIOperationID returns an operation id of a method and path, e.g.
+// getStatsSlowops of GET /api/hatchet/v1.0/hatchets/{hatchet}/stats/slowops
+func getOpenAPIOperationID(method string, path string) string {
+	id := method
+	for _, tok := range strings.Split(path, "/") {
+		if tok == "" || tok == "api" || tok == "hatchet" || tok == "v1.0" || tok == "hatchets" || strings.HasPrefix(tok, "{") {
+			continue
+		}
+		tok = strings.TrimSuffix(tok, ".json")
+		id += strings.ToUpper(tok[:1]) + tok[1:]
+	}
+	return id
+}
+
+// getOpenAPIProperties returns schemas of fields of values
+func getOpenAPIProperties(fields map[string]interface{}, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for name, value := range fields {
+		properties[name] = getOpenAPISchema(reflect.TypeOf(value), schemas)
+	}
+	return properties
+}
+
+// getOpenAPISchema returns the schema of a type, named structs are added to
+// schemas and referenced
+func getOpenAPISchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": getOpenAPISchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": getOpenAPISchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return getOpenAPIStructSchema(t, schemas)
+		}
+		if schemas[t.Name()] == nil {
+			schemas[t.Name()] = map[string]interface{}{} // placeholder of recursive types
+			schemas[t.Name()] = getOpenAPIStructSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+// getOpenAPIStructSchema returns the object schema of fields of a struct
+// named as encoding/json does
+func getOpenAPIStructSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.PkgPath != "" || name == "-" {
+			continue
+		} else if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := getOpenAPIStructSchema(field.Type, schemas)["properties"].(map[string]interface{})
+			for key, value := range embedded {
+				properties[key] = value
+			}
+			continue
+		} else if name == "" {
+			name = field.Name
+		}
+		properties[name] = getOpenAPISchema(field.Type, schemas)
+	}
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
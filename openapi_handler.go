@@ -0,0 +1,29 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * openapi_handler.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// OpenAPIHandler responds with the OpenAPI specification of APIs
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	/** APIs
+	 * /api/openapi.json
+	 */
+	if GetLogv2().verbose {
+		log.Println("OpenAPIHandler", r.URL.Path)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(GetOpenAPISpec(GetLogv2().version))
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * openapi_test.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/simagix/hatchet/client"
+)
+
+func TestGetOpenAPISpec(t *testing.T) {
+	spec := GetOpenAPISpec("test")
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	for _, match := range regexp.MustCompile(`#/components/schemas/(\w+)`).FindAllStringSubmatch(string(data), -1) {
+		if schemas[match[1]] == nil {
+			t.Fatal("expected", match[1], "in schemas but got", schemas)
+		}
+	}
+	path := spec["paths"].(map[string]interface{})["/api/hatchet/v1.0/hatchets/{hatchet}/stats/slowops"]
+	get := path.(map[string]interface{})["get"].(map[string]interface{})
+	if get["operationId"] != "getStatsSlowops" || len(get["parameters"].([]interface{})) != 10 {
+		t.Fatal("expected", "getStatsSlowops of 10 parameters", "but got", get)
+	}
+	properties := schemas["OpStat"].(map[string]interface{})["properties"].(map[string]interface{})
+	if properties["avg_ms"].(map[string]interface{})["type"] != "number" {
+		t.Fatal("expected", "number", "but got", properties["avg_ms"])
+	}
+}
+
+func TestClientTypes(t *testing.T) {
+	for _, types := range [][2]interface{}{{OpStat{}, client.OpStat{}}, {LegacyLog{}, client.LegacyLog{}},
+		{IndexSuggestion{}, client.IndexSuggestion{}}, {NameValues{}, client.NameValues{}}} {
+		expected := getOpenAPISchema(reflect.TypeOf(types[0]), map[string]interface{}{})
+		schema := getOpenAPISchema(reflect.TypeOf(types[1]), map[string]interface{}{})
+		if ref := expected["$ref"]; ref != schema["$ref"] {
+			t.Fatal("expected", ref, "but got", schema["$ref"])
+		}
+		a := getOpenAPIStructSchema(reflect.TypeOf(types[0]), map[string]interface{}{})
+		b := getOpenAPIStructSchema(reflect.TypeOf(types[1]), map[string]interface{}{})
+		if !reflect.DeepEqual(a, b) {
+			t.Fatal("expected", a, "but got", b)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * oplog.go
+ */
+
+package hatchet
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	OPLOG_NS          = "local.oplog.rs"
+	OPLOG_SHRINK_WARN = 25 // percent
+	OPLOG_WINDOW_WARN = 24 // hours
+)
+
+// OPLOG_CHURN_SERIES are series of estimated oplog churn in bytes per hour
+// and the oplog window in seconds by hour
+var OPLOG_CHURN_SERIES = []string{"oplog-churn", "oplog-window"}
+
+// OplogStats collects oplog activity, truncations, and logged oplog metrics
+type OplogStats struct {
+	Markers     int
+	Ops         int
+	Size        int // bytes
+	Truncations []time.Time
+	Writes      map[string]int // documents written by hour
+}
+
+// NewOplogStats returns OplogStats
+func NewOplogStats() *OplogStats {
+	return &OplogStats{Truncations: []time.Time{}, Writes: map[string]int{}}
+}
+
+// Add counts a parsed log and documents written of a slow write op, the
+// oplog namespace is included regardless of filters applied to the slow
+// ops analysis
+func (ptr *OplogStats) Add(doc *Logv2Info, stat *OpStat) {
+	attrMap := doc.Attr.Map()
+	if ns, _ := attrMap["ns"].(string); ns == OPLOG_NS {
+		ptr.Ops++
+	}
+	if stat != nil && isOplogWrite(stat.Op) {
+		written := 0
+		for _, n := range GetWriteCounters(doc) {
+			written += ToInt(n)
+		}
+		if written == 0 {
+			written = 1
+		}
+		ptr.Writes[getOplogHour(doc.Timestamp)] += written
+	}
+	msg := strings.ToLower(doc.Msg)
+	if !strings.Contains(msg, "oplog") {
+		return
+	}
+	if strings.Contains(msg, "stones") || strings.Contains(msg, "markers") {
+		for _, key := range []string{"numMarkers", "numTruncateMarkers", "numStones"} {
+			if n := ToInt(attrMap[key]); n > 0 {
+				ptr.Markers = n
+			}
+		}
+	} else if strings.Contains(msg, "truncation finished") || strings.Contains(msg, "truncated the oplog") {
+		ptr.Truncations = append(ptr.Truncations, doc.Timestamp)
+	}
+	if mb := ToInt(attrMap["oplogSizeMB"]); mb > 0 {
+		ptr.Size = mb * 1024 * 1024
+	} else if size := ToInt(attrMap["dataSize"]); size > 0 && ptr.Size == 0 {
+		ptr.Size = size
+	}
+}
+
+// GetAuditData returns oplog churn stats, intervals and window are in seconds
+// and shrinking is the percent decrease of truncation intervals over time
+func (ptr *OplogStats) GetAuditData() []NameValue {
+	data := []NameValue{}
+	if ptr.Ops == 0 && len(ptr.Truncations) == 0 {
+		return data
+	}
+	data = append(data, NameValue{"ops", ptr.Ops}, NameValue{"truncations", len(ptr.Truncations)})
+	if ptr.Size > 0 {
+		data = append(data, NameValue{"size", ptr.Size})
+	}
+	intervals := []float64{}
+	for i := 1; i < len(ptr.Truncations); i++ {
+		intervals = append(intervals, ptr.Truncations[i].Sub(ptr.Truncations[i-1]).Seconds())
+	}
+	if len(intervals) == 0 {
+		return data
+	}
+	interval := average(intervals)
+	data = append(data, NameValue{"interval", int(interval)})
+	if ptr.Markers > 0 {
+		window := interval * float64(ptr.Markers)
+		data = append(data, NameValue{"window", int(window)})
+		if ptr.Size > 0 && window > 0 {
+			data = append(data, NameValue{"churn", int(float64(ptr.Size) * 3600 / window)})
+		}
+	}
+	if len(intervals) >= 4 {
+		half := len(intervals) / 2
+		early, late := average(intervals[:half]), average(intervals[len(intervals)-half:])
+		if early > 0 && late < early {
+			data = append(data, NameValue{"shrinking", int(100 * (early - late) / early)})
+		}
+	}
+	return data
+}
+
+// GetChurnAuditData returns audit data by type of the estimated oplog churn
+// in bytes per hour and the oplog window in seconds by hour.  Rates between
+// truncations are of the bytes of a truncate marker, rates of hours not
+// between truncations are scaled from documents written by bytes per
+// document of hours between truncations.
+func (ptr *OplogStats) GetChurnAuditData() map[string][]NameValue {
+	data := map[string][]NameValue{}
+	if ptr.Size == 0 || ptr.Markers == 0 || len(ptr.Truncations) < 2 {
+		return data
+	}
+	markerBytes := float64(ptr.Size) / float64(ptr.Markers)
+	rates := map[string]float64{}
+	for i := 1; i < len(ptr.Truncations); i++ {
+		seconds := ptr.Truncations[i].Sub(ptr.Truncations[i-1]).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+		rate := markerBytes * 3600 / seconds
+		for tm := ptr.Truncations[i-1].Truncate(time.Hour); !tm.After(ptr.Truncations[i]); tm = tm.Add(time.Hour) {
+			if hour := getOplogHour(tm); rate > rates[hour] {
+				rates[hour] = rate
+			}
+		}
+	}
+	bytes, written := 0.0, 0
+	for hour, rate := range rates {
+		if ptr.Writes[hour] > 0 {
+			bytes += rate
+			written += ptr.Writes[hour]
+		}
+	}
+	if written > 0 {
+		for hour, n := range ptr.Writes {
+			if _, ok := rates[hour]; !ok {
+				rates[hour] = bytes * float64(n) / float64(written)
+			}
+		}
+	}
+	hours := []string{}
+	for hour := range rates {
+		hours = append(hours, hour)
+	}
+	sort.Strings(hours)
+	for _, hour := range hours {
+		if rates[hour] <= 0 {
+			continue
+		}
+		data["oplog-churn"] = append(data["oplog-churn"], NameValue{hour, int(rates[hour])})
+		data["oplog-window"] = append(data["oplog-window"], NameValue{hour, int(float64(ptr.Size) * 3600 / rates[hour])})
+	}
+	return data
+}
+
+// GetOplogChurnSeries returns estimated oplog churn in MB per hour and the
+// oplog window in hours by hour of merged OPLOG_CHURN_SERIES
+func GetOplogChurnSeries(docs []NameValues) []TimeSeries {
+	series := []TimeSeries{}
+	for _, doc := range docs {
+		churn, window := float64(ToInt(doc.Values[0])), float64(ToInt(doc.Values[1]))
+		series = append(series, TimeSeries{Date: doc.Name + ":00:00",
+			Values: []float64{math.Round(10*churn/(1024*1024)) / 10, math.Round(10*window/3600) / 10}})
+	}
+	sort.Slice(series, func(i int, j int) bool {
+		return series[i].Date < series[j].Date
+	})
+	return series
+}
+
+// GetOplogMinWindow returns the hour and the shortest estimated oplog window
+// in seconds of merged OPLOG_CHURN_SERIES, 0 if not estimated
+func GetOplogMinWindow(docs []NameValues) (string, int) {
+	hour, window := "", 0
+	for _, doc := range docs {
+		if seconds := ToInt(doc.Values[1]); seconds > 0 && (window == 0 || seconds < window) {
+			hour, window = doc.Name, seconds
+		}
+	}
+	return hour, window
+}
+
+// isOplogWrite returns true if an op writes oplog entries
+func isOplogWrite(op string) bool {
+	return op == cmdInsert || op == cmdUpdate || op == cmdRemove || op == cmdDelete || op == cmdFindAndModify
+}
+
+// getOplogHour returns the hour of a time, e.g. 2023-01-01T10
+func getOplogHour(tm time.Time) string {
+	return tm.UTC().Format("2006-01-02T15")
+}
+
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * oplog_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestOplogStats(t *testing.T) {
+	stats := NewOplogStats()
+	tm := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	stats.Add(&Logv2Info{Msg: "Oplog truncate markers calculated", Timestamp: tm,
+		Attr: bson.D{{Key: "numMarkers", Value: int32(10)}, {Key: "dataSize", Value: int64(1024 * 1024 * 1024)}}}, nil)
+	stats.Add(&Logv2Info{Msg: "Slow query", Attr: bson.D{{Key: "ns", Value: OPLOG_NS}}}, nil)
+	for _, minutes := range []int{60, 60, 60, 30, 30, 30} {
+		tm = tm.Add(time.Duration(minutes) * time.Minute)
+		stats.Add(&Logv2Info{Msg: "WiredTiger record store oplog truncation finished", Timestamp: tm}, nil)
+	}
+	data := map[string]int{}
+	for _, doc := range stats.GetAuditData() {
+		data[doc.Name] = doc.Value
+	}
+	if data["ops"] != 1 || data["truncations"] != 6 {
+		t.Fatal("expected", 1, 6, "but got", data["ops"], data["truncations"])
+	}
+	if data["interval"] != 2520 { // (60+60+30+30+30) minutes / 5
+		t.Fatal("expected", 2520, "but got", data["interval"])
+	}
+	if data["window"] != 25200 {
+		t.Fatal("expected", 25200, "but got", data["window"])
+	}
+	if data["shrinking"] != 50 {
+		t.Fatal("expected", 50, "but got", data["shrinking"])
+	}
+}
+
+func TestOplogChurn(t *testing.T) {
+	stats := NewOplogStats()
+	tm := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	stats.Add(&Logv2Info{Msg: "Oplog truncate markers calculated", Timestamp: tm,
+		Attr: bson.D{{Key: "numMarkers", Value: int32(10)}, {Key: "dataSize", Value: int64(1000 * 3600)}}}, nil)
+	for i := 0; i < 3; i++ { // 1 marker of 360000 bytes every 30 minutes in 10h
+		stats.Add(&Logv2Info{Msg: "WiredTiger record store oplog truncation finished", Timestamp: tm.Add(time.Duration(30*i) * time.Minute)}, nil)
+	}
+	for i, hour := range []int{10, 10, 12, 12, 12, 12} { // 2 writes in 10h and 4 in 12h
+		update := &Logv2Info{Msg: "Slow query", Timestamp: tm.Add(time.Duration(hour-10)*time.Hour + time.Duration(i)*time.Minute),
+			Attr: bson.D{{Key: "ns", Value: "db.coll"}, {Key: "nModified", Value: int32(1)}}}
+		stats.Add(update, &OpStat{Op: cmdUpdate})
+	}
+	stats.Add(&Logv2Info{Msg: "Slow query", Timestamp: tm}, &OpStat{Op: cmdFind})
+	docs := MergeAuditSeries(OPLOG_CHURN_SERIES, stats.GetChurnAuditData())
+	series := GetOplogChurnSeries(docs)
+	if len(series) != 3 || series[0].Date != "2023-03-01T10:00:00" || series[2].Date != "2023-03-01T12:00:00" {
+		t.Fatal("expected", "10h to 12h", "but got", series)
+	}
+	values := map[string]int{}
+	for _, doc := range docs {
+		values[doc.Name] = ToInt(doc.Values[1])
+	}
+	if values["2023-03-01T10"] != 5*3600 || values["2023-03-01T11"] != 5*3600 || values["2023-03-01T12"] != 5*3600/2 {
+		t.Fatal("expected", 5*3600, 5*3600/2, "but got", values)
+	}
+	if hour, window := GetOplogMinWindow(docs); hour != "2023-03-01T12" || window != 5*3600/2 {
+		t.Fatal("expected", "2023-03-01T12", 5*3600/2, "but got", hour, window)
+	}
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * otlp.go
+ */
+
+package hatchet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const OTLP_BATCH_SIZE = 512
+const OTLP_TRACES_PATH = "/v1/traces"
+
+// OTLP span kind of an op served by mongod
+const otlpSpanKindServer = 2
+
+// ServiceNames map namespaces to service names of spans, the first matched
+// pattern wins and the namespace is the service name otherwise
+type ServiceNames struct {
+	patterns []*regexp.Regexp
+	names    []string
+}
+
+// ParseServiceNames parses comma separated {namespace regex}={service}, e.g.
+// shop\..*=shop-svc,app.sessions=sessions, patterns match whole namespaces
+func ParseServiceNames(str string) (*ServiceNames, error) {
+	services := &ServiceNames{}
+	if strings.TrimSpace(str) == "" {
+		return services, nil
+	}
+	for _, tok := range strings.Split(str, ",") {
+		n := strings.LastIndex(tok, "=")
+		name := ""
+		if n > 0 {
+			name = strings.TrimSpace(tok[n+1:])
+		}
+		if name == "" {
+			return nil, fmt.Errorf("invalid service name %q, use {namespace regex}={service}", tok)
+		}
+		re, err := regexp.Compile("^(?:" + strings.TrimSpace(tok[:n]) + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace regex %q: %v", tok[:n], err)
+		}
+		services.patterns = append(services.patterns, re)
+		services.names = append(services.names, name)
+	}
+	return services, nil
+}
+
+// Get returns the service name of a namespace
+func (ptr *ServiceNames) Get(ns string) string {
+	for i, re := range ptr.patterns {
+		if re.MatchString(ns) {
+			return ptr.names[i]
+		}
+	}
+	return ns
+}
+
+// OTLPExporter sends slow ops as spans to an OTLP/HTTP endpoint in batches,
+// spans are backfilled at the times ops were logged
+type OTLPExporter struct {
+	Exported int
+	Failed   int
+
+	client      *http.Client
+	endpoint    string
+	hatchetName string
+	services    *ServiceNames
+	spans       map[string][]otlpSpan // by service name
+	pending     int
+}
+
+type otlpValue struct {
+	IntValue    string `json:"intValue,omitempty"`
+	StringValue string `json:"stringValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	Attributes        []otlpAttribute `json:"attributes"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Kind              int             `json:"kind"`
+	Name              string          `json:"name"`
+	SpanID            string          `json:"spanId"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	Status            otlpStatus      `json:"status"`
+	TraceID           string          `json:"traceId"`
+}
+
+// NewOTLPExporter returns OTLPExporter, the traces path is appended to an
+// endpoint without a path, e.g. http://localhost:4318
+func NewOTLPExporter(endpoint string, services *ServiceNames, hatchetName string) *OTLPExporter {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(endpoint, OTLP_TRACES_PATH) {
+		endpoint += OTLP_TRACES_PATH
+	}
+	if services == nil {
+		services = &ServiceNames{}
+	}
+	return &OTLPExporter{client: &http.Client{Timeout: 30 * time.Second}, endpoint: endpoint,
+		hatchetName: hatchetName, services: services, spans: map[string][]otlpSpan{}}
+}
+
+// GetEndpoint returns the URL spans are posted to
+func (ptr *OTLPExporter) GetEndpoint() string {
+	return ptr.endpoint
+}
+
+// Add adds a slow op as a span and sends spans when a batch is full, index is
+// the line number of the log
+func (ptr *OTLPExporter) Add(index int, doc *Logv2Info, stat *OpStat) error {
+	if stat == nil || stat.Op == "" {
+		return nil
+	}
+	ns := doc.Attributes.NS
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%v/%v", ptr.hatchetName, index)))
+	attrs := doc.Attr.Map()
+	span := otlpSpan{TraceID: fmt.Sprintf("%x", hash[:16]), SpanID: fmt.Sprintf("%x", hash[16:24]),
+		Name: stat.Op + " " + ns, Kind: otlpSpanKindServer,
+		StartTimeUnixNano: fmt.Sprint(doc.Timestamp.Add(-time.Duration(doc.Attributes.Milli) * time.Millisecond).UnixNano()),
+		EndTimeUnixNano:   fmt.Sprint(doc.Timestamp.UnixNano())}
+	span.Attributes = append(span.Attributes, getOTLPString("db.system", "mongodb"),
+		getOTLPString("db.operation", stat.Op), getOTLPString("db.mongodb.namespace", ns),
+		getOTLPInt("db.mongodb.duration_ms", doc.Attributes.Milli), getOTLPString("hatchet.name", ptr.hatchetName),
+		getOTLPString("thread.name", doc.Context))
+	if stat.QueryPattern != "" {
+		span.Attributes = append(span.Attributes, getOTLPString("hatchet.query_pattern", stat.QueryPattern))
+	}
+	if i := strings.Index(ns, "."); i > 0 {
+		span.Attributes = append(span.Attributes, getOTLPString("db.name", ns[:i]),
+			getOTLPString("db.mongodb.collection", ns[i+1:]))
+	}
+	if doc.Attributes.PlanSummary != "" {
+		span.Attributes = append(span.Attributes, getOTLPString("db.mongodb.plan_summary", doc.Attributes.PlanSummary))
+	}
+	if appName, ok := attrs["appName"].(string); ok {
+		span.Attributes = append(span.Attributes, getOTLPString("db.mongodb.app_name", appName))
+	}
+	for key, name := range map[string]string{"docsExamined": "db.mongodb.docs_examined",
+		"keysExamined": "db.mongodb.keys_examined", "nreturned": "db.mongodb.nreturned"} {
+		if value, ok := attrs[key]; ok {
+			span.Attributes = append(span.Attributes, getOTLPInt(name, ToInt(value)))
+		}
+	}
+	if doc.Attributes.Reslen > 0 {
+		span.Attributes = append(span.Attributes, getOTLPInt("db.mongodb.reslen", doc.Attributes.Reslen))
+	}
+	if doc.Attributes.ErrMsg != "" {
+		span.Status = otlpStatus{Code: 2, Message: doc.Attributes.ErrMsg} // STATUS_CODE_ERROR
+	}
+	service := ptr.services.Get(ns)
+	ptr.spans[service] = append(ptr.spans[service], span)
+	ptr.pending++
+	if ptr.pending >= OTLP_BATCH_SIZE {
+		return ptr.Flush()
+	}
+	return nil
+}
+
+// Flush sends pending spans, spans of a failed batch are dropped and counted
+func (ptr *OTLPExporter) Flush() error {
+	if ptr.pending == 0 {
+		return nil
+	}
+	resourceSpans := []map[string]interface{}{}
+	for service, spans := range ptr.spans {
+		resourceSpans = append(resourceSpans, map[string]interface{}{
+			"resource": map[string]interface{}{"attributes": []otlpAttribute{getOTLPString("service.name", service)}},
+			"scopeSpans": []map[string]interface{}{{
+				"scope": map[string]interface{}{"name": "hatchet"}, "spans": spans}}})
+	}
+	count := ptr.pending
+	ptr.spans = map[string][]otlpSpan{}
+	ptr.pending = 0
+	data, err := json.Marshal(map[string]interface{}{"resourceSpans": resourceSpans})
+	if err != nil {
+		ptr.Failed += count
+		return err
+	}
+	resp, err := ptr.client.Post(ptr.endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		ptr.Failed += count
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		ptr.Failed += count
+		return fmt.Errorf("%v responded %v %v", ptr.endpoint, resp.Status, strings.TrimSpace(string(body)))
+	}
+	ptr.Exported += count
+	return nil
+}
+
+func getOTLPString(key string, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: value}}
+}
+
+// getOTLPInt returns an int attribute, OTLP/JSON encodes 64-bit integers as strings
+func getOTLPInt(key string, value int) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{IntValue: fmt.Sprint(value)}}
+}
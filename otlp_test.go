@@ -0,0 +1,104 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * otlp_test.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseServiceNames(t *testing.T) {
+	services, err := ParseServiceNames(`shop\..*=shop-svc, app.sessions=sessions`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ns, expected := range map[string]string{"shop.orders": "shop-svc", "app.sessions": "sessions", "app.users": "app.users"} {
+		if name := services.Get(ns); name != expected {
+			t.Fatal("expected", expected, "of", ns, "but got", name)
+		}
+	}
+	for _, str := range []string{"shop", "shop=", "(=svc"} {
+		if _, err = ParseServiceNames(str); err == nil {
+			t.Fatal("expected", "error of", str, "but got", nil)
+		}
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != OTLP_TRACES_PATH {
+			t.Fatal("expected", OTLP_TRACES_PATH, "but got", r.URL.Path)
+		}
+		var doc map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, doc)
+	}))
+	defer server.Close()
+
+	str := `{"t":{"$date":"2023-10-01T12:00:01.500+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","appName":"orders-svc","command":{"find":"orders","filter":{"status":"new"},"$db":"shop"},"planSummary":"COLLSCAN","keysExamined":0,"docsExamined":5000,"nreturned":3,"reslen":300,"durationMillis":1500}}`
+	var doc Logv2Info
+	if err := UnmarshalLogv2([]byte(str), time.UTC, &doc); err != nil {
+		t.Fatal(err)
+	}
+	stat, err := AnalyzeSlowOp(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	services, _ := ParseServiceNames(`shop\..*=shop-svc`)
+	exporter := NewOTLPExporter(server.URL+"/", services, "test")
+	if err = exporter.Add(1, &doc, stat); err != nil {
+		t.Fatal(err)
+	}
+	if err = exporter.Add(2, &doc, &OpStat{}); err != nil { // not an op
+		t.Fatal(err)
+	}
+	if err = exporter.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 || exporter.Exported != 1 {
+		t.Fatal("expected", 1, "but got", len(requests), exporter.Exported)
+	}
+	resourceSpans := requests[0]["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	resource := resourceSpans["resource"].(map[string]interface{})["attributes"].([]interface{})[0].(map[string]interface{})
+	if service := resource["value"].(map[string]interface{})["stringValue"]; service != "shop-svc" {
+		t.Fatal("expected", "shop-svc", "but got", service)
+	}
+	span := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})[0].(map[string]interface{})
+	end := time.Date(2023, 10, 1, 12, 0, 1, 500000000, time.UTC)
+	if span["startTimeUnixNano"] != "1696161600000000000" || span["endTimeUnixNano"] != fmt.Sprint(end.UnixNano()) {
+		t.Fatal("expected", "span of 1500 ms backfilled to", end, "but got", span["startTimeUnixNano"], span["endTimeUnixNano"])
+	}
+	attrs := map[string]interface{}{}
+	for _, attr := range span["attributes"].([]interface{}) {
+		m := attr.(map[string]interface{})
+		value := m["value"].(map[string]interface{})
+		if v, ok := value["stringValue"]; ok {
+			attrs[m["key"].(string)] = v
+		} else {
+			attrs[m["key"].(string)] = value["intValue"]
+		}
+	}
+	for key, expected := range map[string]interface{}{"db.mongodb.plan_summary": "COLLSCAN", "db.mongodb.app_name": "orders-svc",
+		"db.mongodb.docs_examined": "5000", "db.mongodb.duration_ms": "1500", "db.mongodb.collection": "orders"} {
+		if attrs[key] != expected {
+			t.Fatal("expected", expected, "of", key, "but got", attrs[key])
+		}
+	}
+
+	exporter = NewOTLPExporter(server.URL+"/bad", nil, "test")
+	server.Config.Handler = http.NotFoundHandler()
+	exporter.Add(1, &doc, stat)
+	if err = exporter.Flush(); err == nil || exporter.Failed != 1 {
+		t.Fatal("expected", "error", "but got", err, exporter.Failed)
+	}
+}
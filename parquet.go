@@ -0,0 +1,324 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * parquet.go
+ */
+
+package hatchet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// PARQUET_ROW_GROUP_SIZE is the number of rows of a row group
+const PARQUET_ROW_GROUP_SIZE = 100000
+
+// physical types of Parquet columns
+const (
+	PARQUET_INT64      = 2
+	PARQUET_DOUBLE     = 5
+	PARQUET_BYTE_ARRAY = 6
+)
+
+// types of the thrift compact protocol
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// ParquetColumn is a nullable column, strings are stored as UTF8 byte arrays
+type ParquetColumn struct {
+	Name string
+	Type int // PARQUET_INT64, PARQUET_DOUBLE, or PARQUET_BYTE_ARRAY
+}
+
+// ParquetWriter writes rows to a Parquet file of uncompressed, plain encoded
+// columns, a row group every PARQUET_ROW_GROUP_SIZE rows
+type ParquetWriter struct {
+	columns []ParquetColumn
+	groups  []parquetRowGroup
+	numRows int64
+	offset  int64
+	rows    [][]interface{}
+	w       io.Writer
+}
+
+type parquetRowGroup struct {
+	chunks  []parquetChunk
+	numRows int64
+}
+
+type parquetChunk struct {
+	numValues int64
+	offset    int64
+	size      int64
+}
+
+// NewParquetWriter writes the magic number and returns a writer
+func NewParquetWriter(w io.Writer, columns []ParquetColumn) (*ParquetWriter, error) {
+	ptr := &ParquetWriter{columns: columns, w: w}
+	if err := ptr.write([]byte("PAR1")); err != nil {
+		return nil, err
+	}
+	return ptr, nil
+}
+
+// Write buffers a row, values are int64, float64, string, []byte, or nil
+func (ptr *ParquetWriter) Write(row []interface{}) error {
+	if len(row) != len(ptr.columns) {
+		return fmt.Errorf("expected %v values but got %v", len(ptr.columns), len(row))
+	}
+	ptr.rows = append(ptr.rows, row)
+	if len(ptr.rows) >= PARQUET_ROW_GROUP_SIZE {
+		return ptr.flush()
+	}
+	return nil
+}
+
+// Close writes remaining rows and the file metadata
+func (ptr *ParquetWriter) Close() error {
+	if err := ptr.flush(); err != nil {
+		return err
+	}
+	meta := thriftWriter{}
+	meta.beginStruct(0)
+	meta.writeI32(1, 1) // version
+	meta.writeList(2, thriftStruct, len(ptr.columns)+1)
+	meta.beginStruct(0) // root of schema
+	meta.writeString(4, "schema")
+	meta.writeI32(5, int32(len(ptr.columns)))
+	meta.endStruct()
+	for _, column := range ptr.columns {
+		meta.beginStruct(0)
+		meta.writeI32(1, int32(column.Type))
+		meta.writeI32(3, 1) // OPTIONAL
+		meta.writeString(4, column.Name)
+		if column.Type == PARQUET_BYTE_ARRAY {
+			meta.writeI32(6, 0) // UTF8
+		}
+		meta.endStruct()
+	}
+	meta.writeI64(3, ptr.numRows)
+	meta.writeList(4, thriftStruct, len(ptr.groups))
+	for _, group := range ptr.groups {
+		var size int64
+		meta.beginStruct(0)
+		meta.writeList(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			meta.beginStruct(0)
+			meta.writeI64(2, chunk.offset)
+			meta.beginStruct(3)
+			meta.writeI32(1, int32(ptr.columns[i].Type))
+			meta.writeList(2, thriftI32, 2)
+			meta.zigzag(0) // PLAIN
+			meta.zigzag(3) // RLE
+			meta.writeList(3, thriftBinary, 1)
+			meta.binary(ptr.columns[i].Name)
+			meta.writeI32(4, 0) // UNCOMPRESSED
+			meta.writeI64(5, chunk.numValues)
+			meta.writeI64(6, chunk.size)
+			meta.writeI64(7, chunk.size)
+			meta.writeI64(9, chunk.offset)
+			meta.endStruct()
+			meta.endStruct()
+			size += chunk.size
+		}
+		meta.writeI64(2, size)
+		meta.writeI64(3, group.numRows)
+		meta.endStruct()
+	}
+	meta.writeString(6, "hatchet")
+	meta.endStruct()
+	footer := make([]byte, 4)
+	binary.LittleEndian.PutUint32(footer, uint32(meta.buf.Len()))
+	if err := ptr.write(meta.buf.Bytes()); err != nil {
+		return err
+	}
+	if err := ptr.write(footer); err != nil {
+		return err
+	}
+	return ptr.write([]byte("PAR1"))
+}
+
+// flush writes buffered rows as a row group of a data page per column
+func (ptr *ParquetWriter) flush() error {
+	if len(ptr.rows) == 0 {
+		return nil
+	}
+	n := len(ptr.rows)
+	group := parquetRowGroup{numRows: int64(n)}
+	for i, column := range ptr.columns {
+		levels := make([]byte, (n+7)/8) // definition levels bit-packed, 0 for null
+		var values bytes.Buffer
+		for j, row := range ptr.rows {
+			if row[i] == nil {
+				continue
+			}
+			levels[j/8] |= 1 << (j % 8)
+			if err := writeParquetValue(&values, column.Type, row[i]); err != nil {
+				return fmt.Errorf("column %v: %v", column.Name, err)
+			}
+		}
+		hybrid := thriftWriter{}
+		hybrid.varint(uint64(len(levels))<<1 | 1)
+		hybrid.buf.Write(levels)
+		page := make([]byte, 4, 4+hybrid.buf.Len()+values.Len())
+		binary.LittleEndian.PutUint32(page, uint32(hybrid.buf.Len()))
+		page = append(page, hybrid.buf.Bytes()...)
+		page = append(page, values.Bytes()...)
+
+		header := thriftWriter{}
+		header.beginStruct(0)
+		header.writeI32(1, 0) // DATA_PAGE
+		header.writeI32(2, int32(len(page)))
+		header.writeI32(3, int32(len(page)))
+		header.beginStruct(5)
+		header.writeI32(1, int32(n))
+		header.writeI32(2, 0) // PLAIN
+		header.writeI32(3, 3) // RLE
+		header.writeI32(4, 3) // RLE
+		header.endStruct()
+		header.endStruct()
+		chunk := parquetChunk{numValues: int64(n), offset: ptr.offset, size: int64(header.buf.Len() + len(page))}
+		if err := ptr.write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := ptr.write(page); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+	}
+	ptr.groups = append(ptr.groups, group)
+	ptr.numRows += int64(n)
+	ptr.rows = ptr.rows[:0]
+	return nil
+}
+
+func (ptr *ParquetWriter) write(b []byte) error {
+	n, err := ptr.w.Write(b)
+	ptr.offset += int64(n)
+	return err
+}
+
+// writeParquetValue writes a value plain encoded
+func writeParquetValue(buf *bytes.Buffer, ptype int, value interface{}) error {
+	b := make([]byte, 8)
+	switch ptype {
+	case PARQUET_INT64:
+		switch v := value.(type) {
+		case int64:
+			binary.LittleEndian.PutUint64(b, uint64(v))
+		case float64:
+			binary.LittleEndian.PutUint64(b, uint64(int64(v)))
+		default:
+			return fmt.Errorf("invalid integer %v", value)
+		}
+		buf.Write(b)
+	case PARQUET_DOUBLE:
+		switch v := value.(type) {
+		case int64:
+			binary.LittleEndian.PutUint64(b, math.Float64bits(float64(v)))
+		case float64:
+			binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+		default:
+			return fmt.Errorf("invalid number %v", value)
+		}
+		buf.Write(b)
+	default:
+		var str []byte
+		switch v := value.(type) {
+		case []byte:
+			str = v
+		case string:
+			str = []byte(v)
+		default:
+			str = []byte(fmt.Sprintf("%v", v))
+		}
+		binary.LittleEndian.PutUint32(b, uint32(len(str)))
+		buf.Write(b[:4])
+		buf.Write(str)
+	}
+	return nil
+}
+
+// thriftWriter encodes structs in the thrift compact protocol
+type thriftWriter struct {
+	buf    bytes.Buffer
+	fields []int16 // last field ids of enclosing structs
+	last   int16
+}
+
+func (ptr *thriftWriter) varint(v uint64) {
+	for v >= 0x80 {
+		ptr.buf.WriteByte(byte(v) | 0x80)
+		v >>= 7
+	}
+	ptr.buf.WriteByte(byte(v))
+}
+
+func (ptr *thriftWriter) zigzag(v int64) {
+	ptr.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (ptr *thriftWriter) binary(s string) {
+	ptr.varint(uint64(len(s)))
+	ptr.buf.WriteString(s)
+}
+
+func (ptr *thriftWriter) field(id int16, ctype byte) {
+	if delta := id - ptr.last; delta > 0 && delta <= 15 {
+		ptr.buf.WriteByte(byte(delta)<<4 | ctype)
+	} else {
+		ptr.buf.WriteByte(ctype)
+		ptr.zigzag(int64(id))
+	}
+	ptr.last = id
+}
+
+func (ptr *thriftWriter) writeI32(id int16, v int32) {
+	ptr.field(id, thriftI32)
+	ptr.zigzag(int64(v))
+}
+
+func (ptr *thriftWriter) writeI64(id int16, v int64) {
+	ptr.field(id, thriftI64)
+	ptr.zigzag(v)
+}
+
+func (ptr *thriftWriter) writeString(id int16, s string) {
+	ptr.field(id, thriftBinary)
+	ptr.binary(s)
+}
+
+// writeList writes the header of a list, elements follow without field headers
+func (ptr *thriftWriter) writeList(id int16, etype byte, size int) {
+	ptr.field(id, thriftList)
+	if size < 15 {
+		ptr.buf.WriteByte(byte(size)<<4 | etype)
+	} else {
+		ptr.buf.WriteByte(0xf0 | etype)
+		ptr.varint(uint64(size))
+	}
+}
+
+// beginStruct begins a struct field, or a list element or the top level
+// struct if id is 0
+func (ptr *thriftWriter) beginStruct(id int16) {
+	if id > 0 {
+		ptr.field(id, thriftStruct)
+	}
+	ptr.fields = append(ptr.fields, ptr.last)
+	ptr.last = 0
+}
+
+func (ptr *thriftWriter) endStruct() {
+	ptr.buf.WriteByte(0) // stop
+	ptr.last = ptr.fields[len(ptr.fields)-1]
+	ptr.fields = ptr.fields[:len(ptr.fields)-1]
+}
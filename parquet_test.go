@@ -0,0 +1,216 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * parquet_test.go
+ */
+
+package hatchet
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestThriftWriter(t *testing.T) {
+	writer := thriftWriter{}
+	writer.beginStruct(0)
+	writer.writeI32(1, 1)
+	writer.writeI32(20, -1) // long delta
+	writer.writeList(21, thriftI32, 2)
+	writer.endStruct()
+	expected := []byte{0x15, 0x02, 0x05, 0x28, 0x01, 0x19, 0x25, 0x00}
+	if !bytes.Equal(writer.buf.Bytes(), expected) {
+		t.Fatal("expected", fmt.Sprintf("% x", expected), "but got", fmt.Sprintf("% x", writer.buf.Bytes()))
+	}
+}
+
+func TestExportTable(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err = db.Exec(`CREATE TABLE t_ops (op text, count integer, avg_ms numeric);
+		INSERT INTO t_ops VALUES ('find', 3, 1.5), ('update', NULL, 2), (NULL, 1, NULL);`); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err = ExportTable(db, "t_ops", "tsv", &buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := "op\tcount\tavg_ms\nfind\t3\t1.5\nupdate\t\t2\n\t1\t\n"
+	if buf.String() != expected {
+		t.Fatal("expected", expected, "but got", buf.String())
+	}
+	buf.Reset()
+	n, err := ExportTable(db, "t_ops", "parquet", &buf)
+	if err != nil || n != 3 {
+		t.Fatal("expected", 3, "but got", n, err)
+	}
+	data := buf.Bytes()
+	size := len(data)
+	if string(data[:4]) != "PAR1" || string(data[size-4:]) != "PAR1" {
+		t.Fatal("expected", "PAR1", "but got", string(data[:4]), string(data[size-4:]))
+	}
+	footer := int(binary.LittleEndian.Uint32(data[size-8:]))
+	if footer <= 0 || footer > size-12 {
+		t.Fatal("expected", "a footer within the file", "but got", footer)
+	}
+
+	// FileMetaData of the schema, row count, and row groups
+	meta := (&thriftReader{data: data[size-8-footer : size-8]}).readStruct()
+	schema := []string{}
+	for _, elem := range meta[2].([]interface{}) {
+		field := elem.(map[int16]interface{})
+		schema = append(schema, fmt.Sprintf("%v:%v", field[4], field[1]))
+	}
+	if expected := "[schema:<nil> op:6 count:2 avg_ms:5]"; fmt.Sprint(schema) != expected || meta[3] != int64(3) {
+		t.Fatal("expected", expected, 3, "but got", schema, meta[3])
+	}
+	groups := meta[4].([]interface{})
+	chunks := groups[0].(map[int16]interface{})[1].([]interface{})
+	if len(groups) != 1 || len(chunks) != 3 {
+		t.Fatal("expected", 1, 3, "but got", len(groups), len(chunks))
+	}
+
+	// data pages of columns
+	for i, expected := range []string{"[find update <nil>]", "[3 <nil> 1]", "[1.5 2 <nil>]"} {
+		chunk := chunks[i].(map[int16]interface{})[3].(map[int16]interface{})
+		offset := int(chunk[9].(int64))
+		values := readParquetPage(t, data[offset:offset+int(chunk[7].(int64))], int(chunk[1].(int64)))
+		if fmt.Sprint(values) != expected || chunk[5] != int64(3) {
+			t.Fatal("expected", expected, "but got", values, chunk[5])
+		}
+	}
+	if _, err = ExportTable(db, "t_ops; DROP TABLE t_ops", "parquet", &buf); err == nil {
+		t.Fatal("expected", "table not found", "but got", nil)
+	}
+}
+
+// thriftReader decodes structs of the thrift compact protocol into fields by
+// id, lists into slices, and integers into int64
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (ptr *thriftReader) varint() uint64 {
+	var v uint64
+	for shift := 0; ; shift += 7 {
+		b := ptr.data[ptr.pos]
+		ptr.pos++
+		v |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return v
+		}
+	}
+}
+
+func (ptr *thriftReader) zigzag() int64 {
+	v := ptr.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (ptr *thriftReader) readStruct() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var id int16
+	for {
+		b := ptr.data[ptr.pos]
+		ptr.pos++
+		if b == 0 {
+			return fields
+		} else if delta := int16(b >> 4); delta == 0 {
+			id = int16(ptr.zigzag())
+		} else {
+			id += delta
+		}
+		fields[id] = ptr.readValue(b & 0x0f)
+	}
+}
+
+func (ptr *thriftReader) readValue(ctype byte) interface{} {
+	switch ctype {
+	case thriftI32, thriftI64:
+		return ptr.zigzag()
+	case thriftBinary:
+		n := int(ptr.varint())
+		ptr.pos += n
+		return string(ptr.data[ptr.pos-n : ptr.pos])
+	case thriftList:
+		b := ptr.data[ptr.pos]
+		ptr.pos++
+		size := int(b >> 4)
+		if size == 15 {
+			size = int(ptr.varint())
+		}
+		list := []interface{}{}
+		for i := 0; i < size; i++ {
+			list = append(list, ptr.readValue(b&0x0f))
+		}
+		return list
+	case thriftStruct:
+		return ptr.readStruct()
+	}
+	panic(fmt.Sprintf("unexpected thrift type %v", ctype))
+}
+
+// readParquetPage returns values of a plain encoded data page of a column of a
+// physical type, nil of definition levels of 0
+func readParquetPage(t *testing.T, chunk []byte, ptype int) []interface{} {
+	reader := &thriftReader{data: chunk}
+	header := reader.readStruct()
+	page := chunk[reader.pos:]
+	dataPage := header[5].(map[int16]interface{})
+	if header[1] != int64(0) || header[3] != int64(len(page)) || dataPage[2] != int64(0) {
+		t.Fatal("expected", "a plain data page of", len(page), "bytes but got", header)
+	}
+	n := int(dataPage[1].(int64))
+	levels := &thriftReader{data: page[4 : 4+binary.LittleEndian.Uint32(page)]}
+	if run := levels.varint(); run != uint64((n+7)/8)<<1|1 {
+		t.Fatal("expected", "a bit-packed run of definition levels", "but got", run)
+	}
+	bits := levels.data[levels.pos:]
+	values := bytes.NewReader(page[4+len(levels.data):])
+	row := []interface{}{}
+	for i := 0; i < n; i++ {
+		if bits[i/8]&(1<<(i%8)) == 0 {
+			row = append(row, nil)
+			continue
+		}
+		var v uint64
+		if ptype == PARQUET_BYTE_ARRAY {
+			var size uint32
+			binary.Read(values, binary.LittleEndian, &size)
+			str := make([]byte, size)
+			values.Read(str)
+			row = append(row, string(str))
+			continue
+		}
+		binary.Read(values, binary.LittleEndian, &v)
+		if ptype == PARQUET_DOUBLE {
+			row = append(row, math.Float64frombits(v))
+		} else {
+			row = append(row, int64(v))
+		}
+	}
+	return row
+}
+
+func TestWriteSlowOpsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	ops := []OpStat{{Op: "find", Namespace: "shop.orders", QueryPattern: "{ a:1, b:1 }", Count: 2, AvgMilli: 150.25,
+		MaxMilli: 200, TotalMilli: 300, Index: "COLLSCAN", Reslen: 10}}
+	if err := WriteSlowOpsCSV(&buf, ops); err != nil {
+		t.Fatal(err)
+	}
+	expected := "op,ns,filter,sort,count,avg_ms,max_ms,total_ms,index,reslen,first_seen,last_seen\n" +
+		"find,shop.orders,\"{ a:1, b:1 }\",,2,150.2,200,300,COLLSCAN,10,,\n"
+	if buf.String() != expected {
+		t.Fatal("expected", expected, "but got", buf.String())
+	}
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * pdf.go
+ */
+
+package hatchet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// page sizes in points of A4 landscape
+const (
+	PDF_HEIGHT = 595.0
+	PDF_WIDTH  = 842.0
+)
+
+// widths of ASCII 32 to 126 in 1/1000 of the font size of Helvetica
+var pdfHelveticaWidths = []int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584}
+
+// widths of ASCII 32 to 126 in 1/1000 of the font size of Helvetica-Bold
+var pdfHelveticaBoldWidths = []int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584}
+
+// PDF writes pages of text, lines, and rectangles in the standard Helvetica
+// fonts, coordinates are points from the top left corner of a page
+type PDF struct {
+	footer string
+	pages  []*bytes.Buffer
+}
+
+// NewPDF returns a PDF of a footer printed with page numbers
+func NewPDF(footer string) *PDF {
+	return &PDF{footer: footer}
+}
+
+// AddPage starts a new page
+func (ptr *PDF) AddPage() {
+	ptr.pages = append(ptr.pages, &bytes.Buffer{})
+}
+
+// PageCount returns number of pages
+func (ptr *PDF) PageCount() int {
+	return len(ptr.pages)
+}
+
+// Text prints a line of text with its baseline at y
+func (ptr *PDF) Text(x float64, y float64, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(ptr.page(), "BT /%v %.1f Tf %.2f %.2f Td (%v) Tj ET\n", font, size, x, PDF_HEIGHT-y, escapePDFText(s))
+}
+
+// Rect fills a rectangle of a color of red, green, and blue from 0 to 1
+func (ptr *PDF) Rect(x float64, y float64, w float64, h float64, rgb [3]float64) {
+	fmt.Fprintf(ptr.page(), "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f 0 g\n", rgb[0], rgb[1], rgb[2],
+		x, PDF_HEIGHT-y-h, w, h)
+}
+
+// Line strokes a line of a width in gray
+func (ptr *PDF) Line(x1 float64, y1 float64, x2 float64, y2 float64, width float64) {
+	fmt.Fprintf(ptr.page(), "0.6 G %.2f w %.2f %.2f m %.2f %.2f l S 0 G\n", width, x1, PDF_HEIGHT-y1, x2, PDF_HEIGHT-y2)
+}
+
+// WriteTo writes the document
+func (ptr *PDF) WriteTo(w io.Writer) (int64, error) {
+	if len(ptr.pages) == 0 {
+		ptr.AddPage()
+	}
+	var buf bytes.Buffer
+	offsets := []int{}
+	addObject := func(s string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%v\nendobj\n", len(offsets), s)
+	}
+	buf.WriteString("%PDF-1.4\n")
+	// objects 1 to 4 are the catalog, page tree, and fonts, followed by pairs
+	// of a page and its content
+	kids := []string{}
+	for i := range ptr.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*i))
+	}
+	addObject("<< /Type /Catalog /Pages 2 0 R >>")
+	addObject(fmt.Sprintf("<< /Type /Pages /Kids [%v] /Count %d >>", strings.Join(kids, " "), len(ptr.pages)))
+	addObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	addObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range ptr.pages {
+		footer := fmt.Sprintf("%v - page %d of %d", ptr.footer, i+1, len(ptr.pages))
+		content := page.String() + fmt.Sprintf("0.4 g BT /F1 8.0 Tf %.2f 20.00 Td (%v) Tj ET 0 g\n",
+			PDF_WIDTH-36-PDFTextWidth(footer, 8, false), escapePDFText(footer))
+		addObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PDF_WIDTH, PDF_HEIGHT, 6+2*i))
+		addObject(fmt.Sprintf("<< /Length %d >>\nstream\n%vendstream", len(content), content))
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.WriteTo(w)
+}
+
+// page returns the current page, added if none
+func (ptr *PDF) page() *bytes.Buffer {
+	if len(ptr.pages) == 0 {
+		ptr.AddPage()
+	}
+	return ptr.pages[len(ptr.pages)-1]
+}
+
+// PDFTextWidth returns width in points of a text printed in a font size
+func PDFTextWidth(s string, size float64, bold bool) float64 {
+	widths := pdfHelveticaWidths
+	if bold {
+		widths = pdfHelveticaBoldWidths
+	}
+	total := 0
+	for _, r := range toPDFText(s) {
+		total += widths[r-32]
+	}
+	return float64(total) * size / 1000
+}
+
+// FitPDFText breaks a text into lines of a width, the last of maxLines lines
+// is truncated with ...
+func FitPDFText(s string, width float64, size float64, bold bool, maxLines int) []string {
+	lines := []string{}
+	line := ""
+	for _, r := range toPDFText(s) {
+		if PDFTextWidth(line+string(r), size, bold) > width && line != "" {
+			if len(lines) == maxLines-1 {
+				for line != "" && PDFTextWidth(line+"...", size, bold) > width {
+					line = line[:len(line)-1]
+				}
+				return append(lines, line+"...")
+			}
+			lines = append(lines, line)
+			line = ""
+		}
+		line += string(r)
+	}
+	return append(lines, line)
+}
+
+// toPDFText replaces characters other than printable ASCII with ?
+func toPDFText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' {
+			return ' '
+		} else if r < 32 || r > 126 {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+// escapePDFText escapes a text of a string object
+func escapePDFText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(toPDFText(s))
+}
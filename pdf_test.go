@@ -0,0 +1,37 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * pdf_test.go
+ */
+
+package hatchet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPDF(t *testing.T) {
+	pdf := NewPDF("test")
+	pdf.Text(36, 36, 12, true, "a (b) \\ c")
+	pdf.AddPage()
+	pdf.Rect(36, 36, 100, 10, [3]float64{0, 0, 1})
+	var buf strings.Builder
+	if _, err := pdf.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"%PDF-1.4\n", "/Count 2", `(a \(b\) \\ c) Tj`, "(test - page 2 of 2) Tj", "%%EOF\n"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatal("expected", s, "but got", buf.String())
+		}
+	}
+}
+
+func TestFitPDFText(t *testing.T) {
+	if width := PDFTextWidth("ab", 10, false); width != 11.12 {
+		t.Fatal("expected", 11.12, "but got", width)
+	}
+	lines := FitPDFText(strings.Repeat("x", 100), 50, 10, false, 2)
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], "...") || PDFTextWidth(lines[1], 10, false) > 50 {
+		t.Fatal("expected", "2 lines of 50 points truncated", "but got", lines)
+	}
+}
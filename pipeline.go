@@ -0,0 +1,146 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * pipeline.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// LINE_BATCH_SIZE is the number of lines a worker parses at a time
+const LINE_BATCH_SIZE = 256
+
+// LogLine is a line read and parsed, Str is empty for a blank line
+type LogLine struct {
+	Doc       Logv2Info
+	Err       error // parsing error
+	Index     int   // line number
+	LegacyErr error // error converting to the legacy format
+	Legacy    bool  // text format before 4.4
+	Offset    int64 // bytes read through the line
+	Stat      *OpStat
+	Str       string
+}
+
+// LinePipeline reads lines of a log and parses them, concurrently by workers
+// if more than one, lines are returned in the order of the log
+type LinePipeline struct {
+	batches chan *lineBatch
+	index   int
+	lines   []*LogLine
+	offset  int64
+	parse   func(*LogLine)
+	quit    chan struct{}
+	reader  *bufio.Reader
+	workers int
+}
+
+// lineBatch is lines parsed by a worker, done is closed once parsed
+type lineBatch struct {
+	done  chan struct{}
+	lines []*LogLine
+}
+
+// NewLinePipeline returns a pipeline parsing lines by a number of workers,
+// lines are read and parsed in the goroutine calling Next if workers is 1 or
+// less
+func NewLinePipeline(reader *bufio.Reader, workers int, parse func(*LogLine)) *LinePipeline {
+	ptr := &LinePipeline{parse: parse, reader: reader, workers: workers}
+	if workers <= 1 {
+		return ptr
+	}
+	ptr.batches = make(chan *lineBatch, 2*workers)
+	quit := make(chan struct{})
+	ptr.quit = quit
+	jobs := make(chan *lineBatch)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for batch := range jobs {
+				for _, line := range batch.lines {
+					if line.Str != "" {
+						parse(line)
+					}
+				}
+				close(batch.done)
+			}
+		}()
+	}
+	go func() { // reads lines in batches, batches are queued in order before parsed
+		defer close(ptr.batches)
+		defer close(jobs)
+		for {
+			batch := &lineBatch{done: make(chan struct{})}
+			for len(batch.lines) < LINE_BATCH_SIZE {
+				line, ok := ptr.readLine()
+				if !ok {
+					break
+				}
+				batch.lines = append(batch.lines, line)
+			}
+			if len(batch.lines) == 0 {
+				return
+			}
+			select {
+			case ptr.batches <- batch:
+			case <-quit:
+				return
+			}
+			select {
+			case jobs <- batch:
+			case <-quit:
+				return
+			}
+			if len(batch.lines) < LINE_BATCH_SIZE {
+				return
+			}
+		}
+	}()
+	return ptr
+}
+
+// Next returns the next line parsed, false at the end of the log
+func (ptr *LinePipeline) Next() (*LogLine, bool) {
+	if ptr.workers <= 1 {
+		line, ok := ptr.readLine()
+		if ok && line.Str != "" {
+			ptr.parse(line)
+		}
+		return line, ok
+	}
+	for len(ptr.lines) == 0 {
+		batch, ok := <-ptr.batches
+		if !ok {
+			return nil, false
+		}
+		<-batch.done
+		ptr.lines = batch.lines
+	}
+	line := ptr.lines[0]
+	ptr.lines = ptr.lines[1:]
+	return line, true
+}
+
+// Close stops reading lines if not all lines were read
+func (ptr *LinePipeline) Close() {
+	if ptr.quit != nil {
+		close(ptr.quit)
+		ptr.quit = nil
+	}
+}
+
+// readLine reads a line without its \n or \r\n, the offset is of bytes read
+// including the line ending
+func (ptr *LinePipeline) readLine() (*LogLine, bool) {
+	raw, _ := ptr.reader.ReadBytes('\n') // 0x0A separator = newline
+	if len(raw) == 0 {
+		return nil, false
+	}
+	ptr.index++
+	ptr.offset += int64(len(raw))
+	buf := bytes.TrimSuffix(raw, []byte{'\n'})
+	buf = bytes.TrimSuffix(buf, []byte{'\r'})
+	return &LogLine{Index: ptr.index, Offset: ptr.offset, Str: string(buf)}, true
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * pipeline_test.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLinePipeline(t *testing.T) {
+	var buffer strings.Builder
+	for i := 1; i <= 3*LINE_BATCH_SIZE+5; i++ {
+		if i%100 == 0 {
+			buffer.WriteString("\n") // blank line
+			continue
+		}
+		buffer.WriteString(fmt.Sprintf("line %v %v\n", i, strings.Repeat("x", i%40)))
+	}
+	parse := func(line *LogLine) {
+		line.Doc.Msg = strings.ToUpper(line.Str)
+	}
+	for _, workers := range []int{1, 4} {
+		reader := bufio.NewReaderSize(strings.NewReader(buffer.String()), 16) // long lines are read in parts
+		lines := NewLinePipeline(reader, workers, parse)
+		var offset int64
+		n := 0
+		for {
+			line, ok := lines.Next()
+			if !ok {
+				break
+			}
+			n++
+			offset += int64(len(line.Str)) + 1
+			if line.Index != n || line.Offset != offset {
+				t.Fatal("expected", n, offset, "but got", line.Index, line.Offset)
+			}
+			if n%100 == 0 {
+				if line.Str != "" {
+					t.Fatal("expected", "blank line", "but got", line.Str)
+				}
+				continue
+			}
+			if expected := fmt.Sprintf("line %v %v", n, strings.Repeat("x", n%40)); line.Str != expected || line.Doc.Msg != strings.ToUpper(expected) {
+				t.Fatal("expected", expected, "but got", line.Str, line.Doc.Msg)
+			}
+		}
+		lines.Close()
+		if n != 3*LINE_BATCH_SIZE+5 {
+			t.Fatal("expected", 3*LINE_BATCH_SIZE+5, "lines of", workers, "workers but got", n)
+		}
+	}
+}
+
+func TestLinePipelineClose(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader(strings.Repeat("line\n", 10*LINE_BATCH_SIZE)))
+	lines := NewLinePipeline(reader, 2, func(line *LogLine) {})
+	if line, ok := lines.Next(); !ok || line.Index != 1 {
+		t.Fatal("expected", "line 1", "but got", line)
+	}
+	lines.Close() // stops reading remaining lines
+}
+
+func TestLinePipelineCRLF(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		reader := bufio.NewReaderSize(strings.NewReader("a\r\nbb\r\n\r\nccc"), 16)
+		lines := NewLinePipeline(reader, workers, func(line *LogLine) {})
+		expected := []LogLine{{Index: 1, Offset: 3, Str: "a"}, {Index: 2, Offset: 7, Str: "bb"},
+			{Index: 3, Offset: 9, Str: ""}, {Index: 4, Offset: 12, Str: "ccc"}}
+		n := 0
+		for {
+			line, ok := lines.Next()
+			if !ok {
+				break
+			}
+			if n >= len(expected) || line.Index != expected[n].Index || line.Offset != expected[n].Offset || line.Str != expected[n].Str {
+				t.Fatal("expected", expected, "but got", n, line.Index, line.Offset, line.Str)
+			}
+			n++
+		}
+		lines.Close()
+		if n != len(expected) {
+			t.Fatal("expected", len(expected), "lines but got", n)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * planning.go
+ */
+
+package hatchet
+
+import (
+	"math"
+	"sort"
+)
+
+// PLAN_THRASH_RATIO is the max-to-min planning time ratio of a shape to flag
+// plan cache thrash
+const PLAN_THRASH_RATIO = 10
+
+// PLAN_THRASH_MIN_MICROS ignores shapes that never plan slower than it
+const PLAN_THRASH_MIN_MICROS = 1000
+
+// PlanningStat stores planning times of an op shape
+type PlanningStat struct {
+	AvgMicros    float64 `json:"avg_micros" bson:"avg_micros"`
+	Count        int     `json:"count" bson:"count"`
+	MaxMicros    int     `json:"max_micros" bson:"max_micros"`
+	MinMicros    int     `json:"min_micros" bson:"min_micros"`
+	Namespace    string  `json:"ns" bson:"ns"`
+	Op           string  `json:"op" bson:"op"`
+	QueryPattern string  `json:"query_pattern" bson:"query_pattern"`
+	StdDevMicros float64 `json:"stddev_micros" bson:"stddev_micros"`
+}
+
+// GetPlanningTime returns planningTimeMicros of a slow op, false if the
+// server doesn't emit it
+func GetPlanningTime(doc *Logv2Info) (int, bool) {
+	micros, ok := doc.Attr.Map()["planningTimeMicros"]
+	if !ok {
+		return 0, false
+	}
+	return ToInt(micros), true
+}
+
+// GetRatio returns the max-to-min planning time ratio, a min of 0 counts as
+// 1 microsecond
+func (ptr *PlanningStat) GetRatio() float64 {
+	return float64(ptr.MaxMicros) / math.Max(float64(ptr.MinMicros), 1)
+}
+
+// IsThrashing returns true if some executions plan from cache and others plan
+// slowly, an indication of plan cache evictions or many candidate plans
+func (ptr *PlanningStat) IsThrashing() bool {
+	return ptr.Count > 1 && ptr.MaxMicros >= PLAN_THRASH_MIN_MICROS && ptr.GetRatio() >= PLAN_THRASH_RATIO
+}
+
+// SortPlanningStats sorts shapes of plan cache thrash first, then by the
+// max-to-min ratio
+func SortPlanningStats(ops []PlanningStat) {
+	sort.SliceStable(ops, func(i, j int) bool {
+		if ops[i].IsThrashing() != ops[j].IsThrashing() {
+			return ops[i].IsThrashing()
+		}
+		return ops[i].GetRatio() > ops[j].GetRatio()
+	})
+}
+
+// getStdDev returns the population standard deviation of the average and the
+// average of squares
+func getStdDev(avg float64, avgSquare float64) float64 {
+	return math.Sqrt(math.Max(avgSquare-avg*avg, 0))
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * planning_template.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetPlanningTemplate returns HTML
+func GetPlanningTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Planning}}
+	<table width='100%'>
+		<caption>Planning Time by Shape (&micro;s)</caption>
+		<tr><th>op</th><th>namespace</th><th>count</th><th>min</th><th>avg</th><th>max</th><th>stddev</th>
+			<th>max/min</th><th>query pattern</th></tr>
+	{{range $op := .Planning}}
+		<tr><td>{{$op.Op}}</td><td>{{$op.Namespace}}</td><td align='right'>{{numPrinter $op.Count}}</td>
+			<td align='right'>{{numPrinter $op.MinMicros}}</td>
+			<td align='right'>{{printf "%.0f" $op.AvgMicros}}</td>
+			<td align='right'>{{numPrinter $op.MaxMicros}}</td>
+			<td align='right'>{{printf "%.0f" $op.StdDevMicros}}</td>
+			{{if $op.IsThrashing}}
+			<td align='right' style='color: red;' title='plan cache thrash'>{{formatRatio $op.GetRatio}}</td>
+			{{else}}
+			<td align='right'>{{formatRatio $op.GetRatio}}</td>
+			{{end}}
+			<td class='break'>{{$op.QueryPattern}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<div>Shapes in red plan at least {{.Ratio}} times slower in some executions than in others, some from the plan
+		cache and others planning slowly, as the plan cache is evicted or the query has many candidate plans.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no planningTimeMicros found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"formatRatio": func(ratio float64) string {
+			return fmt.Sprintf("%.1f", ratio)
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
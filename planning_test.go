@@ -0,0 +1,52 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * planning_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetPlanningTime(t *testing.T) {
+	str := `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","planningTimeMicros":{"$numberLong":"52000"},"durationMillis":200}}`
+	var doc Logv2Info
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if micros, ok := GetPlanningTime(&doc); !ok || micros != 52000 {
+		t.Fatal("expected", 52000, "but got", micros, ok)
+	}
+	doc = Logv2Info{}
+	if micros, ok := GetPlanningTime(&doc); ok {
+		t.Fatal("expected", "not logged", "but got", micros)
+	}
+}
+
+func TestPlanningStat(t *testing.T) {
+	ops := []PlanningStat{
+		{Op: "find", Namespace: "shop.products", Count: 50, MinMicros: 100, MaxMicros: 300},
+		{Op: "find", Namespace: "shop.tiny", Count: 50, MinMicros: 0, MaxMicros: 900},
+		{Op: "find", Namespace: "shop.orders", Count: 50, MinMicros: 40, MaxMicros: 80000},
+	}
+	if ratio := ops[1].GetRatio(); ratio != 900 {
+		t.Fatal("expected", 900, "but got", ratio)
+	}
+	for i, expected := range []bool{false, false, true} {
+		if ops[i].IsThrashing() != expected {
+			t.Fatal("expected", expected, "of", ops[i].Namespace, "but got", ops[i].IsThrashing())
+		}
+	}
+	SortPlanningStats(ops)
+	for i, expected := range []string{"shop.orders", "shop.tiny", "shop.products"} {
+		if ops[i].Namespace != expected {
+			t.Fatal("expected", expected, "but got", ops[i].Namespace)
+		}
+	}
+	if stddev := getStdDev(20, 500); stddev != 10 {
+		t.Fatal("expected", 10, "but got", stddev)
+	}
+}
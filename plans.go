@@ -0,0 +1,119 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * plans.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+	"strings"
+)
+
+// ShapePlan stores the count of a plan of an op shape in a time bucket
+type ShapePlan struct {
+	Count        int    `json:"count" bson:"count"`
+	Date         string `json:"date" bson:"date"`             // time bucket
+	FirstSeen    string `json:"first_seen" bson:"first_seen"` // first log of the plan in the bucket
+	Index        string `json:"index" bson:"index"`
+	Namespace    string `json:"ns" bson:"ns"`
+	Op           string `json:"op" bson:"op"`
+	QueryPattern string `json:"query_pattern" bson:"query_pattern"`
+	SortPattern  string `json:"sort_pattern" bson:"sort_pattern"`
+}
+
+// PlanChange is when the dominant plan of an op shape changed
+type PlanChange struct {
+	Date string `json:"date"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PlanTimeline stores plan counts of an op shape by time bucket, Counts
+// follow the order of Plans
+type PlanTimeline struct {
+	Buckets      []string     `json:"buckets"`
+	Changes      []PlanChange `json:"changes"`
+	Counts       [][]int      `json:"counts"`
+	Dominants    []string     `json:"dominants"`
+	Namespace    string       `json:"ns"`
+	Op           string       `json:"op"`
+	Plans        []string     `json:"plans"`
+	QueryPattern string       `json:"query_pattern"`
+	SortPattern  string       `json:"sort_pattern"`
+}
+
+// GetPlanTimelines returns timelines of op shapes using more than one plan,
+// shapes with most plan changes first
+func GetPlanTimelines(docs []ShapePlan) []PlanTimeline {
+	sort.SliceStable(docs, func(i, j int) bool {
+		a, b := docs[i], docs[j]
+		if ka, kb := getShapeKey(a), getShapeKey(b); ka != kb {
+			return ka < kb
+		}
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		return a.FirstSeen < b.FirstSeen
+	})
+	timelines := []PlanTimeline{}
+	for i := 0; i < len(docs); {
+		j := i
+		for j < len(docs) && getShapeKey(docs[j]) == getShapeKey(docs[i]) {
+			j++
+		}
+		if timeline := getPlanTimeline(docs[i:j]); len(timeline.Plans) > 1 {
+			timelines = append(timelines, timeline)
+		}
+		i = j
+	}
+	sort.SliceStable(timelines, func(i, j int) bool {
+		return len(timelines[i].Changes) > len(timelines[j].Changes)
+	})
+	return timelines
+}
+
+// getPlanTimeline returns the timeline of plans of a shape sorted by date
+func getPlanTimeline(docs []ShapePlan) PlanTimeline {
+	timeline := PlanTimeline{Op: docs[0].Op, Namespace: docs[0].Namespace,
+		QueryPattern: docs[0].QueryPattern, SortPattern: docs[0].SortPattern}
+	plans := map[string]int{}
+	for _, doc := range docs {
+		if _, ok := plans[doc.Index]; !ok {
+			plans[doc.Index] = len(timeline.Plans)
+			timeline.Plans = append(timeline.Plans, doc.Index)
+		}
+	}
+	dominant := ""
+	for i := 0; i < len(docs); {
+		bucket := docs[i].Date
+		counts := make([]int, len(timeline.Plans))
+		firstSeen := map[string]string{}
+		top := ""
+		for ; i < len(docs) && docs[i].Date == bucket; i++ {
+			counts[plans[docs[i].Index]] += docs[i].Count
+			firstSeen[docs[i].Index] = docs[i].FirstSeen
+		}
+		for _, plan := range timeline.Plans { // ties keep the current plan
+			if _, ok := firstSeen[plan]; !ok {
+				continue
+			}
+			if top == "" || counts[plans[plan]] > counts[plans[top]] ||
+				(counts[plans[plan]] == counts[plans[top]] && plan == dominant) {
+				top = plan
+			}
+		}
+		if dominant != "" && top != dominant {
+			timeline.Changes = append(timeline.Changes, PlanChange{Date: firstSeen[top], From: dominant, To: top})
+		}
+		dominant = top
+		timeline.Buckets = append(timeline.Buckets, bucket)
+		timeline.Counts = append(timeline.Counts, counts)
+		timeline.Dominants = append(timeline.Dominants, top)
+	}
+	return timeline
+}
+
+func getShapeKey(doc ShapePlan) string {
+	return strings.Join([]string{doc.Op, doc.Namespace, doc.QueryPattern, doc.SortPattern}, "\t")
+}
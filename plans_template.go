@@ -0,0 +1,70 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * plans_template.go
+ */
+
+package hatchet
+
+import (
+	"html/template"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetPlansTemplate returns HTML
+func GetPlansTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Timelines}}
+	{{range $n, $t := .Timelines}}
+	<table width='100%'>
+		<caption>{{add $n 1}}. {{$t.Op}} {{$t.Namespace}} {{$t.QueryPattern}} {{$t.SortPattern}}</caption>
+		{{range $c := $t.Changes}}
+		<tr><td colspan='{{add (len $t.Plans) 1}}'>
+			<mark>plan changed from {{$c.From}} to <span style='color: {{getPlanColor $c.To}};'>{{$c.To}}</span>
+			at {{getDateTime $c.Date}}</mark></td></tr>
+		{{end}}
+		<tr><th>date</th>{{range $p := $t.Plans}}<th style='color: {{getPlanColor $p}};'>{{$p}}</th>{{end}}</tr>
+		{{range $i, $b := $t.Buckets}}
+		<tr><td>{{getDateTime $b}}</td>
+			{{range $j, $c := index $t.Counts $i}}
+				{{if eq (index $t.Plans $j) (index $t.Dominants $i)}}
+			<td align='right'><b>{{numPrinter $c}}</b></td>
+				{{else}}
+			<td align='right'>{{numPrinter $c}}</td>
+				{{end}}
+			{{end}}
+		</tr>
+		{{end}}
+	</table>
+	<p/>
+	{{end}}
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no op shapes using more than one plan found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"add": func(a int, b int) int {
+			return a + b
+		},
+		"getDateTime": func(str string) string {
+			if len(str) > 19 {
+				str = str[:19]
+			}
+			return strings.Replace(str, "T", " ", 1)
+		},
+		"getPlanColor": func(plan string) string {
+			if plan == "COLLSCAN" {
+				return "red"
+			}
+			return "inherit"
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
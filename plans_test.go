@@ -0,0 +1,38 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * plans_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestGetPlanTimelines(t *testing.T) {
+	docs := []ShapePlan{
+		{Op: "find", Namespace: "db.a", QueryPattern: "{ x:1 }", Index: "{ x:1 }", Date: "14:10", FirstSeen: "14:10:01", Count: 5},
+		{Op: "find", Namespace: "db.a", QueryPattern: "{ x:1 }", Index: "COLLSCAN", Date: "14:30", FirstSeen: "14:30:12", Count: 4},
+		{Op: "find", Namespace: "db.a", QueryPattern: "{ x:1 }", Index: "{ x:1 }", Date: "14:30", FirstSeen: "14:30:01", Count: 1},
+		{Op: "find", Namespace: "db.a", QueryPattern: "{ x:1 }", Index: "{ x:1 }", Date: "14:20", FirstSeen: "14:20:01", Count: 3},
+		{Op: "find", Namespace: "db.b", QueryPattern: "{ y:1 }", Index: "{ y:1 }", Date: "14:10", FirstSeen: "14:10:05", Count: 9},
+	}
+	timelines := GetPlanTimelines(docs)
+	if len(timelines) != 1 {
+		t.Fatal("expected", 1, "but got", len(timelines))
+	}
+	timeline := timelines[0]
+	if len(timeline.Buckets) != 3 || timeline.Buckets[2] != "14:30" {
+		t.Fatal("expected", 3, "buckets but got", timeline.Buckets)
+	}
+	if len(timeline.Changes) != 1 {
+		t.Fatal("expected", 1, "but got", len(timeline.Changes))
+	}
+	change := timeline.Changes[0]
+	if change.Date != "14:30:12" || change.From != "{ x:1 }" || change.To != "COLLSCAN" {
+		t.Fatal("expected", "COLLSCAN at 14:30:12", "but got", change)
+	}
+	if timeline.Counts[2][0] != 1 || timeline.Counts[2][1] != 4 {
+		t.Fatal("expected", []int{1, 4}, "but got", timeline.Counts[2])
+	}
+}
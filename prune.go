@@ -0,0 +1,163 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * prune.go
+ */
+
+package hatchet
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HatchetAge is a hatchet and when it was created
+type HatchetAge struct {
+	Created string `json:"created"`
+	Name    string `json:"name"`
+}
+
+// RunPrune drops hatchets older than an age
+func RunPrune(args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "list hatchets to prune without dropping them")
+	olderThan := fs.String("older-than", "", "age of hatchets to prune, e.g. 30d, 2w, or 12h")
+	url := fs.String("url", SQLITE3_FILE, "database file name or connection string")
+	fs.Parse(args)
+
+	if *olderThan == "" {
+		return errors.New("usage: hatchet prune -older-than 30d [-dry-run] [-url file]")
+	}
+	age, err := ParseAge(*olderThan)
+	if err != nil {
+		return err
+	}
+	instance = &Logv2{url: *url}
+	if GetLogv2().GetDBType() == SQLite3 {
+		RegisterSQLite3Extended()
+	}
+	docs, skipped, err := PruneHatchets(age, *dryRun)
+	for _, name := range skipped {
+		log.Printf("skipped %v, creation not recorded, i.e. created by an older version\n", name)
+	}
+	for _, doc := range docs {
+		if *dryRun {
+			fmt.Printf("%v created %v\n", doc.Name, doc.Created)
+		} else {
+			log.Printf("dropped %v created %v\n", doc.Name, doc.Created)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("%v hatchets older than %v\n", len(docs), *olderThan)
+	if len(docs) > 0 && !*dryRun && GetLogv2().GetDBType() == SQLite3 {
+		db, err := sql.Open("sqlite3", *url)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		log.Println("reclaiming space of", *url)
+		if _, err = db.Exec("VACUUM"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseAge parses an age in days, e.g. 30d, weeks, e.g. 2w, or a duration,
+// e.g. 12h
+func ParseAge(age string) (time.Duration, error) {
+	var unit time.Duration
+	if strings.HasSuffix(age, "d") {
+		unit = 24 * time.Hour
+	} else if strings.HasSuffix(age, "w") {
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(age[:len(age)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %v", age)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	duration, err := time.ParseDuration(age)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid age %v", age)
+	}
+	return duration, nil
+}
+
+// GetOldHatchets returns hatchets created before an age, and names of
+// hatchets skipped of no creation recorded, i.e. created by older versions
+func GetOldHatchets(age time.Duration) ([]HatchetAge, []string, error) {
+	docs := []HatchetAge{}
+	skipped := []string{}
+	dbase, err := GetDatabase("")
+	if err != nil {
+		return docs, skipped, err
+	}
+	names, err := dbase.GetHatchetNames()
+	dbase.Close()
+	if err != nil {
+		return docs, skipped, err
+	}
+	cutoff := time.Now().Add(-age)
+	for _, name := range names {
+		if dbase, err = GetDatabase(name); err != nil {
+			return docs, skipped, err
+		}
+		created, err := getHatchetCreated(dbase)
+		dbase.Close()
+		if err != nil {
+			return docs, skipped, err
+		} else if created.IsZero() {
+			skipped = append(skipped, name)
+		} else if created.Before(cutoff) {
+			docs = append(docs, HatchetAge{Created: created.UTC().Format(time.RFC3339), Name: name})
+		}
+	}
+	return docs, skipped, nil
+}
+
+// PruneHatchets drops hatchets created before an age, or lists them only if
+// dryRun, hatchets of no creation recorded are skipped
+func PruneHatchets(age time.Duration, dryRun bool) ([]HatchetAge, []string, error) {
+	docs, skipped, err := GetOldHatchets(age)
+	if err != nil || dryRun {
+		return docs, skipped, err
+	}
+	for i, doc := range docs {
+		dbase, err := GetDatabase(doc.Name)
+		if err != nil {
+			return docs[:i], skipped, err
+		}
+		err = dbase.Drop()
+		dbase.Close()
+		if err != nil {
+			return docs[:i], skipped, err
+		}
+	}
+	return docs, skipped, nil
+}
+
+// getHatchetCreated returns when a hatchet was created, i.e. its schema
+// version recorded when created, zero if not recorded.  Migrations applied
+// later are not of its creation, nor are timestamps of its logs.
+func getHatchetCreated(dbase Database) (time.Time, error) {
+	records, err := dbase.GetMigrations()
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, record := range records {
+		if record.Description == MIGRATION_CREATED {
+			return time.Parse(time.RFC3339, record.Applied)
+		}
+	}
+	return time.Time{}, nil
+}
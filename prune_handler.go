@@ -0,0 +1,39 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * prune_handler.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// PruneHandler lists or drops hatchets older than an age
+func PruneHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	/** APIs
+	 * GET /api/hatchet/v1.0/prune?olderThan={age} lists hatchets to prune
+	 * DELETE /api/hatchet/v1.0/prune?olderThan={age} drops them
+	 */
+	w.Header().Set("Content-Type", "application/json")
+	olderThan := r.URL.Query().Get("olderThan")
+	age, err := ParseAge(olderThan)
+	if err != nil || olderThan == "" {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": "invalid olderThan, e.g. 30d, 2w, or 12h"})
+		return
+	}
+	dryRun := r.Method != http.MethodDelete
+	docs, skipped, err := PruneHatchets(age, dryRun)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error(), "hatchets": docs, "skipped": skipped})
+		return
+	}
+	if !dryRun {
+		log.Printf("pruned %v hatchets older than %v\n", len(docs), olderThan)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": 1, "dry_run": dryRun, "hatchets": docs, "skipped": skipped})
+}
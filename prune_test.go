@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * prune_test.go
+ */
+
+package hatchet
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	ages := map[string]time.Duration{"30d": 30 * 24 * time.Hour, "2w": 14 * 24 * time.Hour, "12h": 12 * time.Hour,
+		"90m": 90 * time.Minute, "0d": 0}
+	for age, expected := range ages {
+		if duration, err := ParseAge(age); err != nil || duration != expected {
+			t.Fatal("expected", expected, "but got", duration, err)
+		}
+	}
+	for _, age := range []string{"", "d", "-1d", "30days", "1y"} {
+		if _, err := ParseAge(age); err == nil {
+			t.Fatal("expected", "invalid age", "but got", nil, "for", age)
+		}
+	}
+}
+
+func TestGetOldHatchets(t *testing.T) {
+	RegisterSQLite3Extended()
+	saved := instance
+	defer func() { instance = saved }()
+	dbfile := filepath.Join(t.TempDir(), "prune.db")
+	instance = &Logv2{url: dbfile}
+	db, err := sql.Open("sqlite3", dbfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err = db.Exec(`CREATE TABLE hatchet (name text not null primary key, end text);
+		INSERT INTO hatchet VALUES ('h_new', '2023-01-01T00:00:00.000-0000'), ('h_old', '2023-01-01T00:00:00.000-0000'),
+			('h_legacy', '2020-01-01T00:00:00.000-0000');`); err != nil {
+		t.Fatal(err)
+	}
+
+	// dry runs don't create the migrations table
+	if docs, skipped, err := GetOldHatchets(30 * 24 * time.Hour); err != nil || len(docs) != 0 || len(skipped) != 3 {
+		t.Fatal("expected", "3 skipped", "but got", docs, skipped, err)
+	}
+	var n int
+	if db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM sqlite_master WHERE name = '%v'`, MIGRATIONS_TABLE)).Scan(&n); n != 0 {
+		t.Fatal("expected", MIGRATIONS_TABLE, "not created but got", n)
+	}
+
+	// hatchets of no creation recorded are skipped, e.g. migrated later
+	now, old := time.Now().UTC(), time.Now().UTC().Add(-60*24*time.Hour)
+	if _, err = db.Exec(GetMigrationsInitStmt()+`INSERT INTO hatchet_migrations VALUES
+		('h_new', 27, ?, ?), ('h_old', 26, ?, ?), ('h_old', 27, 'add slow thresholds', ?), ('h_legacy', 27, 'add slow thresholds', ?);`,
+		MIGRATION_CREATED, now.Format(time.RFC3339), MIGRATION_CREATED, old.Format(time.RFC3339), now.Format(time.RFC3339),
+		old.Format(time.RFC3339)); err != nil {
+		t.Fatal(err)
+	}
+	docs, skipped, err := GetOldHatchets(30 * 24 * time.Hour)
+	if err != nil || len(docs) != 1 || docs[0].Name != "h_old" || docs[0].Created != old.Format(time.RFC3339) {
+		t.Fatal("expected", "h_old", "but got", docs, err)
+	}
+	if len(skipped) != 1 || skipped[0] != "h_legacy" {
+		t.Fatal("expected", []string{"h_legacy"}, "but got", skipped)
+	}
+}
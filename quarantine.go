@@ -0,0 +1,51 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * quarantine.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// Quarantine writes skipped raw lines with their decode errors to a file,
+// each line is preceded by a comment of the log name, line number, and error
+type Quarantine struct {
+	Count    int
+	filename string
+	file     *os.File
+	writer   *bufio.Writer
+}
+
+// NewQuarantine returns Quarantine writing to a file
+func NewQuarantine(filename string) (*Quarantine, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &Quarantine{filename: filename, file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+// Add writes a skipped line
+func (ptr *Quarantine) Add(logname string, index int, line string, err error) error {
+	ptr.Count++
+	_, werr := fmt.Fprintf(ptr.writer, "# %v:%d %v\n%v\n", logname, index, err, line)
+	return werr
+}
+
+// Flush writes buffered lines to the quarantine file
+func (ptr *Quarantine) Flush() error {
+	return ptr.writer.Flush()
+}
+
+// Close flushes and closes the quarantine file
+func (ptr *Quarantine) Close() error {
+	if err := ptr.writer.Flush(); err != nil {
+		ptr.file.Close()
+		return err
+	}
+	return ptr.file.Close()
+}
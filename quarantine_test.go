@@ -0,0 +1,35 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * quarantine_test.go
+ */
+
+package hatchet
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuarantine(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "quarantine.log")
+	quarantine, err := NewQuarantine(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = quarantine.Add("mongod.log", 3, `{"t":`, errors.New("unexpected EOF")); err != nil {
+		t.Fatal(err)
+	}
+	if err = quarantine.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# mongod.log:3 unexpected EOF\n{\"t\":\n"
+	if string(data) != expected || quarantine.Count != 1 {
+		t.Fatal("expected", expected, "but got", string(data))
+	}
+}
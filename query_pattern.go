@@ -0,0 +1,182 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * query_pattern.go
+ */
+
+package hatchet
+
+import (
+	"errors"
+	"strings"
+)
+
+// patternNode is a parsed query pattern, an object has keys, an array has
+// values, and a scalar has only text
+type patternNode struct {
+	keys   []string
+	values []*patternNode
+	text   string
+	isObj  bool
+	isArr  bool
+}
+
+// CollapseFieldPaths returns a query pattern with nested field documents
+// collapsed into dotted paths for display, e.g. { a.b.c:1 } for
+// { a:{ b:{ c:1 } } }, operator documents are not collapsed.  The original
+// pattern is returned if it cannot be parsed.
+func CollapseFieldPaths(pattern string) string {
+	parser := &patternParser{str: pattern}
+	node, err := parser.parseValue()
+	if err != nil || strings.TrimSpace(parser.str[parser.pos:]) != "" || !node.isObj {
+		return pattern
+	}
+	return collapseNode(node).String()
+}
+
+func collapseNode(node *patternNode) *patternNode {
+	if node.isArr {
+		for i, value := range node.values {
+			node.values[i] = collapseNode(value)
+		}
+		return node
+	} else if !node.isObj {
+		return node
+	}
+	collapsed := &patternNode{isObj: true}
+	for i, key := range node.keys {
+		value := collapseNode(node.values[i])
+		if !strings.HasPrefix(key, "$") && isFieldDoc(value) {
+			for j, subkey := range value.keys {
+				collapsed.keys = append(collapsed.keys, key+"."+subkey)
+				collapsed.values = append(collapsed.values, value.values[j])
+			}
+			continue
+		}
+		collapsed.keys = append(collapsed.keys, key)
+		collapsed.values = append(collapsed.values, value)
+	}
+	return collapsed
+}
+
+// isFieldDoc returns true if a node is a non-empty object of field names only
+func isFieldDoc(node *patternNode) bool {
+	if !node.isObj || len(node.keys) == 0 {
+		return false
+	}
+	for _, key := range node.keys {
+		if strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a node in the query pattern format
+func (ptr *patternNode) String() string {
+	if ptr.isObj {
+		if len(ptr.keys) == 0 {
+			return "{}"
+		}
+		fields := []string{}
+		for i, key := range ptr.keys {
+			fields = append(fields, " "+key+":"+ptr.values[i].String())
+		}
+		return "{" + strings.Join(fields, ",") + " }"
+	} else if ptr.isArr {
+		values := []string{}
+		for _, value := range ptr.values {
+			values = append(values, value.String())
+		}
+		return "[" + strings.Join(values, ",") + "]"
+	}
+	return ptr.text
+}
+
+type patternParser struct {
+	str string
+	pos int
+}
+
+func (ptr *patternParser) skipSpaces() {
+	for ptr.pos < len(ptr.str) && ptr.str[ptr.pos] == ' ' {
+		ptr.pos++
+	}
+}
+
+func (ptr *patternParser) parseValue() (*patternNode, error) {
+	ptr.skipSpaces()
+	if ptr.pos >= len(ptr.str) {
+		return nil, errors.New("unexpected end of pattern")
+	}
+	switch ptr.str[ptr.pos] {
+	case '{':
+		return ptr.parseObject()
+	case '[':
+		return ptr.parseArray()
+	}
+	start := ptr.pos
+	for ptr.pos < len(ptr.str) && !strings.ContainsRune(",}]", rune(ptr.str[ptr.pos])) {
+		ptr.pos++
+	}
+	text := strings.TrimSpace(ptr.str[start:ptr.pos])
+	if text == "" {
+		return nil, errors.New("empty value")
+	}
+	return &patternNode{text: text}, nil
+}
+
+func (ptr *patternParser) parseObject() (*patternNode, error) {
+	node := &patternNode{isObj: true}
+	ptr.pos++ // {
+	for {
+		ptr.skipSpaces()
+		if ptr.pos >= len(ptr.str) {
+			return nil, errors.New("unclosed object")
+		} else if ptr.str[ptr.pos] == '}' {
+			ptr.pos++
+			return node, nil
+		} else if len(node.keys) > 0 {
+			if ptr.str[ptr.pos] != ',' {
+				return nil, errors.New("expected ,")
+			}
+			ptr.pos++
+			ptr.skipSpaces()
+		}
+		n := strings.IndexByte(ptr.str[ptr.pos:], ':')
+		if n <= 0 || strings.ContainsAny(ptr.str[ptr.pos:ptr.pos+n], "{}[],") {
+			return nil, errors.New("expected key")
+		}
+		key := strings.TrimSpace(ptr.str[ptr.pos : ptr.pos+n])
+		ptr.pos += n + 1
+		value, err := ptr.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.keys = append(node.keys, key)
+		node.values = append(node.values, value)
+	}
+}
+
+func (ptr *patternParser) parseArray() (*patternNode, error) {
+	node := &patternNode{isArr: true}
+	ptr.pos++ // [
+	for {
+		ptr.skipSpaces()
+		if ptr.pos >= len(ptr.str) {
+			return nil, errors.New("unclosed array")
+		} else if ptr.str[ptr.pos] == ']' {
+			ptr.pos++
+			return node, nil
+		} else if len(node.values) > 0 {
+			if ptr.str[ptr.pos] != ',' {
+				return nil, errors.New("expected ,")
+			}
+			ptr.pos++
+		}
+		value, err := ptr.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.values = append(node.values, value)
+	}
+}
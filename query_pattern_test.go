@@ -0,0 +1,30 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * query_pattern_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestCollapseFieldPaths(t *testing.T) {
+	tests := map[string]string{
+		"{ a:{ b:{ c:1 } } }":                         "{ a.b.c:1 }",
+		"{ category:1, price:{ $gt:1 } }":             "{ category:1, price:{ $gt:1 } }",
+		"{ a:{ b:{ $gte:1, $lt:1 } }, c:1 }":          "{ a.b:{ $gte:1, $lt:1 }, c:1 }",
+		"{ $or:[{ a:{ b:1 } },{ c:{ $in:[...] } }] }": "{ $or:[{ a.b:1 },{ c:{ $in:[...] } }] }",
+		"{ a:{ $elemMatch:{ b:{ c:1 } } } }":          "{ a:{ $elemMatch:{ b.c:1 } } }",
+		"{ a:{} }":                                    "{ a:{} }",
+		"{ name:/^abc.../i }":                         "{ name:/^abc.../i }",
+		"{ $facet:... }":                              "{ $facet:... }",
+		"{ a:{ b:1 ":                                  "{ a:{ b:1 ",
+		"":                                            "",
+	}
+	for pattern, expected := range tests {
+		if collapsed := CollapseFieldPaths(pattern); collapsed != expected {
+			t.Fatal("expected", expected, "but got", collapsed)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * raw_logs.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// RAW_LOG_FILTERS are query string parameters of exporting logs, all but
+// duration and severity match columns of the same names
+var RAW_LOG_FILTERS = []string{"component", "context", "duration", "severity", "ns", "op", "filter", "_index", "source"}
+
+// RawLogFilter is a condition of exporting logs
+type RawLogFilter struct {
+	Column string
+	Values []string // two dates of duration, severities at or above of severity
+}
+
+// GetRawLogFilters returns filters of query string parameters in the order
+// of RAW_LOG_FILTERS, empty values are ignored
+func GetRawLogFilters(query url.Values) ([]RawLogFilter, error) {
+	filters := []RawLogFilter{}
+	for _, name := range RAW_LOG_FILTERS {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		if name == "duration" {
+			dates := strings.Split(value, ",")
+			if len(dates) != 2 {
+				return nil, fmt.Errorf("invalid duration %q, use {start},{end}", value)
+			}
+			filters = append(filters, RawLogFilter{Column: "date", Values: dates})
+		} else if name == "severity" {
+			severities := []string{}
+			for _, v := range SEVERITIES {
+				severities = append(severities, v)
+				if v == value {
+					break
+				}
+			}
+			filters = append(filters, RawLogFilter{Column: name, Values: severities})
+		} else {
+			filters = append(filters, RawLogFilter{Column: name, Values: []string{value}})
+		}
+	}
+	return filters, nil
+}
+
+// GetRawLogLine returns the original log line if stored, otherwise the line
+// reconstructed in the legacy format
+func GetRawLogLine(raw string, doc LegacyLog) string {
+	if raw != "" {
+		return raw
+	}
+	return fmt.Sprintf("%v %-2s %-8s [%v] %v", doc.Timestamp, doc.Severity, doc.Component, doc.Context, doc.Message)
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * raw_logs_test.go
+ */
+
+package hatchet
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestGetRawLogFilters(t *testing.T) {
+	query, _ := url.ParseQuery("ns=shop.orders&severity=W&duration=2023-01-01T00:00:00,2023-01-01T01:00:00&context=&limit=10")
+	filters, err := GetRawLogFilters(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 3 {
+		t.Fatal("expected", 3, "but got", len(filters))
+	}
+	if filters[0].Column != "date" || filters[0].Values[1] != "2023-01-01T01:00:00" {
+		t.Fatal("expected", "date", "but got", filters[0])
+	}
+	if filters[1].Column != "severity" || filters[1].Values[len(filters[1].Values)-1] != "W" {
+		t.Fatal("expected", "severities at or above W", "but got", filters[1])
+	}
+	if filters[2].Column != "ns" || filters[2].Values[0] != "shop.orders" {
+		t.Fatal("expected", "shop.orders", "but got", filters[2])
+	}
+	query, _ = url.ParseQuery("duration=2023-01-01T00:00:00")
+	if _, err = GetRawLogFilters(query); err == nil {
+		t.Fatal("expected", "error", "but got", nil)
+	}
+}
+
+func TestGetRawLogLine(t *testing.T) {
+	raw := `{"t":{"$date":"2023-01-01T00:00:00.000+00:00"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted"}`
+	doc := LegacyLog{Timestamp: "2023-01-01T00:00:00.000-0000", Severity: "I", Component: "NETWORK", Context: "listener",
+		Message: "connection accepted"}
+	if line := GetRawLogLine(raw, doc); line != raw {
+		t.Fatal("expected", raw, "but got", line)
+	}
+	expected := "2023-01-01T00:00:00.000-0000 I  NETWORK  [listener] connection accepted"
+	if line := GetRawLogLine("", doc); line != expected {
+		t.Fatal("expected", expected, "but got", line)
+	}
+}
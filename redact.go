@@ -0,0 +1,186 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * redact.go
+ */
+
+package hatchet
+
+import (
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// REDACTED replaces literal values, as redactClientLogData of MongoDB does
+const REDACTED = "###"
+
+// REDACT_ATTRS are attributes of commands of which literals are redacted
+var REDACT_ATTRS = []string{"command", "originatingCommand"}
+
+// redactKept are fields of commands kept as is, of no literals of documents
+// and needed by shapes, e.g. sort and index specifications
+var redactKept = map[string]bool{"$clusterTime": true, "$db": true, "$readPreference": true, "$sort": true,
+	"collation": true, "hint": true, "key": true, "keyPattern": true, "lsid": true, "readConcern": true,
+	"sort": true, "writeConcern": true}
+
+var (
+	redactFieldPath = regexp.MustCompile(`^\$\$?[A-Za-z_][\w.]*$`)
+	redactDupKey    = regexp.MustCompile(`dup key: \{.*\}`)
+	// values of documents of the legacy text format, strings, constructors,
+	// regexes, and numbers following a colon, bracket, or comma
+	redactLiteral = regexp.MustCompile(`([:\[,]\s*)("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|` +
+		`(?:ObjectId|BinData|ISODate|new Date|Timestamp|UUID|NumberLong|NumberDecimal|NumberInt)\([^)]*\)|` +
+		`/(?:[^/\\]|\\.)+/[a-z]*|-?\d+(?:\.\d+)?(?:[eE][-+]?\d+)?\b)`)
+)
+
+// RedactLiterals replaces literal values, strings, numbers, binaries, and
+// others, of filters and commands of a log with ###, returns true if any
+// attribute is redacted.  Options of commands, i.e. scalars of the top
+// level except comment, field paths, regex anchors, and sort, hint, and
+// index specifications are kept so that shapes are the same.
+func RedactLiterals(doc *Logv2Info) bool {
+	redacted := false
+	for i, attr := range doc.Attr {
+		if attr.Key == "errMsg" {
+			if msg, ok := attr.Value.(string); ok && redactDupKey.MatchString(msg) {
+				doc.Attr[i].Value = redactDupKey.ReplaceAllString(msg, "dup key: { "+REDACTED+" }")
+				redacted = true
+			}
+			continue
+		}
+		command, ok := attr.Value.(bson.D)
+		if ok && attr.Key == "query" { // filter of the legacy text format
+			doc.Attr[i].Value = redactValue(attr.Key, command)
+			redacted = true
+			continue
+		} else if !ok || !isRedactAttr(attr.Key) {
+			continue
+		}
+		for j, elem := range command {
+			if redactKept[elem.Key] {
+				continue
+			}
+			switch elem.Value.(type) {
+			case bson.D, bson.A:
+				command[j].Value = redactValue(elem.Key, elem.Value)
+			case string:
+				if elem.Key == "comment" {
+					command[j].Value = REDACTED
+				}
+			}
+		}
+		redacted = true
+	}
+	return redacted
+}
+
+// RedactMessage returns a message of the legacy text format, of a line of no
+// command attribute, with its dup key and values of its documents, i.e. in
+// braces, replaced by ###, keeping field paths and regex anchors
+func RedactMessage(message string) string {
+	message = redactDupKey.ReplaceAllString(message, "dup key: { "+REDACTED+" }")
+	var buf strings.Builder
+	depth, start := 0, 0
+	for i := 0; i < len(message); i++ {
+		switch message[i] {
+		case '{':
+			if depth == 0 {
+				buf.WriteString(message[start:i])
+				start = i
+			}
+			depth++
+		case '}':
+			if depth > 0 {
+				if depth--; depth == 0 {
+					buf.WriteString(redactDocString(message[start : i+1]))
+					start = i + 1
+				}
+			}
+		case '"', '\'':
+			for j := i + 1; j < len(message); j++ { // braces of strings are not of documents
+				if message[j] == '\\' {
+					j++
+				} else if message[j] == message[i] {
+					i = j
+					break
+				}
+			}
+		}
+	}
+	if depth > 0 { // truncated
+		buf.WriteString(redactDocString(message[start:]))
+	} else {
+		buf.WriteString(message[start:])
+	}
+	return buf.String()
+}
+
+// redactDocString returns a document of the legacy text format with its
+// values replaced by ###
+func redactDocString(doc string) string {
+	return redactLiteral.ReplaceAllStringFunc(doc, func(str string) string {
+		matches := redactLiteral.FindStringSubmatch(str)
+		value := matches[2]
+		if redactFieldPath.MatchString(strings.Trim(value, `"'`)) {
+			return str
+		} else if strings.HasPrefix(value, "/") {
+			return matches[1] + "/" + getRedactedRegex(value[1:strings.LastIndex(value, "/")]) + value[strings.LastIndex(value, "/"):]
+		}
+		return matches[1] + REDACTED
+	})
+}
+
+// isRedactAttr returns true if literals of an attribute are redacted
+func isRedactAttr(key string) bool {
+	for _, name := range REDACT_ATTRS {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue returns a value of a field with its literals redacted
+func redactValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.D:
+		d := bson.D{}
+		for _, elem := range v {
+			if redactKept[elem.Key] || elem.Key == "$options" {
+				d = append(d, elem)
+			} else {
+				d = append(d, bson.E{Key: elem.Key, Value: redactValue(elem.Key, elem.Value)})
+			}
+		}
+		return d
+	case bson.A:
+		a := bson.A{}
+		for _, elem := range v {
+			a = append(a, redactValue(key, elem))
+		}
+		return a
+	case nil, bool:
+		return v
+	case string:
+		if key == "$regex" {
+			return getRedactedRegex(v)
+		} else if redactFieldPath.MatchString(v) {
+			return v
+		}
+		return REDACTED
+	case primitive.Regex:
+		return primitive.Regex{Pattern: getRedactedRegex(v.Pattern), Options: v.Options}
+	default:
+		return REDACTED
+	}
+}
+
+// getRedactedRegex returns a redacted pattern keeping its ^ anchor
+func getRedactedRegex(pattern string) string {
+	if strings.HasPrefix(pattern, "^") {
+		return "^" + REDACTED
+	}
+	return REDACTED
+}
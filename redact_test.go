@@ -0,0 +1,98 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * redact_test.go
+ */
+
+package hatchet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactLiterals(t *testing.T) {
+	line := `{"t":{"$date":"2023-01-01T00:00:02.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query",` +
+		`"attr":{"type":"command","ns":"shop.users","command":{"find":"users","filter":{"email":"jane@example.com",` +
+		`"age":{"$gt":30},"name":{"$regex":"^Jan"},"id":{"$binary":{"base64":"AQI=","subType":"0"}},"tags":{"$in":["a","b"]},` +
+		`"$expr":{"$eq":["$owner","$$USER"]}},"sort":{"age":-1},"limit":10,"comment":"for jane","$db":"shop"},` +
+		`"planSummary":"IXSCAN { email: 1 }","errMsg":"E11000 duplicate key error dup key: { email: \"jane@example.com\" }",` +
+		`"durationMillis":150}}`
+	doc := Logv2Info{}
+	if err := UnmarshalLogv2([]byte(line), nil, &doc); err != nil {
+		t.Fatal(err)
+	}
+	original := Logv2Info{}
+	UnmarshalLogv2([]byte(line), nil, &original)
+	if !RedactLiterals(&doc) {
+		t.Fatal("expected", true, "but got", false)
+	}
+	if err := AddLegacyString(&doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, literal := range []string{"jane", "30", "Jan", "AQI=", `"a"`} {
+		if strings.Contains(doc.Message, literal) {
+			t.Fatal("expected", literal, "redacted but got", doc.Message)
+		}
+	}
+	for _, kept := range []string{`find: "users"`, "$owner", "$$USER", "^###", "age:-1", "limit:10", `$db: "shop"`} {
+		if !strings.Contains(doc.Message, kept) {
+			t.Fatal("expected", kept, "kept but got", doc.Message)
+		}
+	}
+
+	// shapes are of the same query and sort patterns
+	AddLegacyString(&original)
+	stat, _ := AnalyzeSlowOp(&doc)
+	expected, _ := AnalyzeSlowOp(&original)
+	if stat.QueryPattern != expected.QueryPattern || stat.SortPattern != expected.SortPattern {
+		t.Fatal("expected", expected.QueryPattern, expected.SortPattern, "but got", stat.QueryPattern, stat.SortPattern)
+	}
+
+	line = `{"t":{"$date":"2023-01-01T00:00:01.000+00:00"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted","attr":{"remote":"10.0.0.1:5000","connectionId":1,"connectionCount":1}}`
+	doc = Logv2Info{}
+	if err := UnmarshalLogv2([]byte(line), nil, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if RedactLiterals(&doc) {
+		t.Fatal("expected", false, "but got", true)
+	}
+}
+
+func TestRedactLegacyMessage(t *testing.T) {
+	logv2 := &Logv2{redact: true}
+	for _, test := range [][3]string{
+		// no command attribute, messages of lines not converted are redacted
+		{`2023-01-01T00:00:02.000+0000 I WRITE    [conn1] E11000 duplicate key error collection: shop.users index: email_1 dup key: { email: "jane@example.com" }`,
+			`E11000 duplicate key error collection: shop.users index: email_1 dup key: { ### }`,
+			`E11000 duplicate key error collection: shop.users index: email_1 dup key: { ### }`},
+		{`2023-01-01T00:00:03.000+0000 I STORAGE  [conn1] createCollection: shop.users with options: { validator: { email: { $regex: "@example\.com$" }, age: { $gte: 18 }, o: "$owner" } }`,
+			`createCollection: shop.users with options: { validator: { email: { $regex: ### }, age: { $gte: ### }, o: "$owner" } }`,
+			`createCollection: shop.users with options: { validator: { email: { $regex: ### }, age: { $gte: ### }, o: "$owner" } }`},
+		{`2023-01-01T00:00:04.000+0000 I NETWORK  [listener] connection accepted from 10.0.0.1:5000 #1 (1 connection now open)`,
+			`connection accepted from 10.0.0.1:5000 #1 (1 connection now open)`, "Connection accepted"},
+	} {
+		str, expected, msg := test[0], test[1], test[2]
+		line := &LogLine{Str: str}
+		if logv2.parseLine(line); line.Err != nil || line.LegacyErr != nil {
+			t.Fatal(line.Err, line.LegacyErr)
+		}
+		if line.Doc.Message != expected || line.Doc.Msg != msg {
+			t.Fatal("expected", expected, msg, "but got", line.Doc.Message, line.Doc.Msg)
+		}
+	}
+
+	// filters of the query attribute
+	line := &LogLine{Str: `2023-01-01T00:00:02.000+0000 I COMMAND  [conn1] query shop.users query: { email: "jane@example.com", age: { $gt: 30 } } planSummary: COLLSCAN ntoreturn:0 keysExamined:0 docsExamined:100 nreturned:1 reslen:200 locks:{} 150ms`}
+	logv2.parseLine(line)
+	for _, literal := range []string{"jane", "30"} {
+		if strings.Contains(line.Doc.Message, literal) {
+			t.Fatal("expected", literal, "redacted but got", line.Doc.Message)
+		}
+	}
+
+	// braces of strings are not of documents, and a truncated document
+	expected := `find "{ x" { a: ###, b: ### } and { c: ###`
+	if message := RedactMessage(`find "{ x" { a: "y }", b: 'x' } and { c: 2`); message != expected {
+		t.Fatal("expected", expected, "but got", message)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * repl_lag.go
+ */
+
+package hatchet
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// REPL_LAG_SERIES are series of GetReplicationLags
+var REPL_LAG_SERIES = []string{"avg lag (s)", "max lag (s)", "applied ops", "docs written"}
+
+var (
+	legacyAppliedTS   = regexp.MustCompile(`\bts: Timestamp\((\d+), ?\d+\)`)
+	legacyAppliedWall = regexp.MustCompile(`\bwall: new Date\((\d+)\)`)
+)
+
+// IsAppliedOp returns true if a log is an oplog entry applied slowly by a
+// secondary, i.e. Applied op or slow oplog entry
+func IsAppliedOp(doc *Logv2Info) bool {
+	msg := strings.ToLower(doc.Msg)
+	return doc.Component == "REPL" && (strings.HasPrefix(msg, "applied op") || strings.Contains(msg, "slow oplog entry"))
+}
+
+// GetReplicationLag returns milliseconds from the wall clock time, or the
+// timestamp, of an applied oplog entry to when it was logged applied, false if
+// not an applied op
+func GetReplicationLag(doc *Logv2Info) (int, bool) {
+	if !IsAppliedOp(doc) {
+		return 0, false
+	}
+	var written time.Time
+	if command, ok := doc.Attr.Map()["command"].(bson.D); ok {
+		entry := command.Map()
+		if wall, ok := entry["wall"].(primitive.DateTime); ok {
+			written = wall.Time()
+		} else if ts, ok := entry["ts"].(primitive.Timestamp); ok {
+			written = time.Unix(int64(ts.T), 0)
+		}
+	} else if m := legacyAppliedWall.FindStringSubmatch(doc.Msg); m != nil {
+		millis, _ := strconv.ParseInt(m[1], 10, 64)
+		written = time.UnixMilli(millis)
+	} else if m := legacyAppliedTS.FindStringSubmatch(doc.Msg); m != nil {
+		seconds, _ := strconv.ParseInt(m[1], 10, 64)
+		written = time.Unix(seconds, 0)
+	}
+	if written.IsZero() {
+		return 0, false
+	}
+	lag := int(doc.Timestamp.Sub(written).Milliseconds())
+	if lag < 0 { // clocks of members are not in sync
+		lag = 0
+	}
+	return lag, true
+}
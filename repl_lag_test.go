@@ -0,0 +1,45 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * repl_lag_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetReplicationLag(t *testing.T) {
+	tests := []struct {
+		str string
+		lag int
+		ok  bool
+	}{
+		{`{"t":{"$date":"2023-01-01T00:01:20.500+00:00"},"s":"I","c":"REPL","id":21071,"ctx":"ReplWriterWorker-1","msg":"Applied op","attr":{"command":{"op":"u","ns":"shop.orders","ts":{"$timestamp":{"t":1672531200,"i":1}},"wall":{"$date":"2023-01-01T00:01:18.000Z"}},"durationMillis":150}}`,
+			2500, true},
+		{`{"t":{"$date":"2023-01-01T00:00:03.000+00:00"},"s":"I","c":"REPL","id":21071,"ctx":"ReplWriterWorker-1","msg":"Applied op","attr":{"command":{"op":"i","ns":"shop.orders","ts":{"$timestamp":{"t":1672531200,"i":1}}},"durationMillis":150}}`,
+			3000, true},
+		{`{"t":{"$date":"2023-01-01T00:00:03.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","durationMillis":200}}`,
+			0, false},
+	}
+	for _, test := range tests {
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(test.str), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if lag, ok := GetReplicationLag(&doc); lag != test.lag || ok != test.ok {
+			t.Fatal("expected", test.lag, test.ok, "but got", lag, ok)
+		}
+	}
+
+	var doc Logv2Info
+	str := `2020-03-01T10:00:05.000+0000 I  REPL     [repl-writer-worker-1] applied op: CRUD { ts: Timestamp(1583056800, 1), t: 1, h: 0, v: 2, op: "u", ns: "shop.orders", o2: { _id: 1 }, o: { $set: { a: 1 } } }, took 120ms`
+	if err := ParseLegacyLog(str, nil, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if lag, ok := GetReplicationLag(&doc); lag != 5000 || !ok {
+		t.Fatal("expected", 5000, "but got", lag, ok)
+	}
+}
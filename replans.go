@@ -0,0 +1,116 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * replans.go
+ */
+
+package hatchet
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	replanMsgRegex = regexp.MustCompile(`(?i)replanning|evicting cache entry`)
+	replanNSRegex  = regexp.MustCompile(`ns: (\S+)`)
+)
+
+// ReplanStat stores replans of an op shape, a shape of an empty op is plan
+// cache evictions of a namespace not logged as slow ops
+type ReplanStat struct {
+	AvgMilli         float64 `json:"avg_ms" bson:"avg_ms"`                   // avg ms of executions not replanned
+	AvgReplanMilli   float64 `json:"avg_replan_ms" bson:"avg_replan_ms"`     // avg ms of replanned executions
+	Count            int     `json:"count" bson:"count"`                     // executions logged
+	Evictions        int     `json:"evictions" bson:"evictions"`             // plan cache evictions of the namespace
+	MaxReplanMilli   int     `json:"max_replan_ms" bson:"max_replan_ms"`     // max ms of replanned executions
+	Namespace        string  `json:"ns" bson:"ns"`                           // database.collection
+	Op               string  `json:"op" bson:"op"`                           // empty for evictions only
+	QueryPattern     string  `json:"query_pattern" bson:"query_pattern"`     // query pattern
+	Reason           string  `json:"reason" bson:"reason"`                   // a logged replan reason
+	Replans          int     `json:"replans" bson:"replans"`                 // replanned executions
+	TotalReplanMilli int     `json:"total_replan_ms" bson:"total_replan_ms"` // total ms of replanned executions
+}
+
+// GetReplanReason returns the replan reason of a slow op logged replanned,
+// or the message of a plan cache eviction, false otherwise
+func GetReplanReason(doc *Logv2Info) (string, bool) {
+	attr := doc.Attr.Map()
+	if replanned, ok := attr["replanned"].(bool); ok && replanned {
+		if reason, ok := attr["replanReason"].(string); ok && reason != "" {
+			return reason, true
+		}
+		return "replanned", true
+	} else if doc.Component == "QUERY" && replanMsgRegex.MatchString(doc.Msg) {
+		return doc.Msg, true
+	}
+	return "", false
+}
+
+// GetReplanNamespace returns the namespace of a plan cache eviction from the
+// ns attribute or the canonical query, e.g. ns: db.coll query: ...
+func GetReplanNamespace(doc *Logv2Info) string {
+	attr := doc.Attr.Map()
+	if ns, ok := attr["ns"].(string); ok {
+		return ns
+	}
+	if query, ok := attr["query"].(string); ok {
+		if matches := replanNSRegex.FindStringSubmatch(query); len(matches) > 1 {
+			return strings.TrimSuffix(matches[1], ",")
+		}
+	}
+	return ""
+}
+
+// GetImpact returns milliseconds replanned executions took more than the
+// average of executions not replanned
+func (ptr *ReplanStat) GetImpact() int {
+	if ptr.Replans == 0 || ptr.Replans == ptr.Count {
+		return ptr.TotalReplanMilli
+	}
+	impact := float64(ptr.TotalReplanMilli) - ptr.AvgMilli*float64(ptr.Replans)
+	if impact < 0 {
+		return 0
+	}
+	return int(impact)
+}
+
+// SortReplanStats sorts shapes by latency impact, then by replans
+func SortReplanStats(ops []ReplanStat) {
+	sort.SliceStable(ops, func(i, j int) bool {
+		if ops[i].GetImpact() != ops[j].GetImpact() {
+			return ops[i].GetImpact() > ops[j].GetImpact()
+		}
+		if ops[i].Replans != ops[j].Replans {
+			return ops[i].Replans > ops[j].Replans
+		}
+		return ops[i].Evictions > ops[j].Evictions
+	})
+}
+
+// MergeReplanEvictions sets plan cache evictions of namespaces to their
+// shapes, namespaces without replanned shapes are added as shapes of an empty
+// op
+func MergeReplanEvictions(ops []ReplanStat, evictions map[string]int) []ReplanStat {
+	for i := range ops {
+		if n, ok := evictions[ops[i].Namespace]; ok {
+			ops[i].Evictions = n
+		}
+	}
+	namespaces := []string{}
+	for ns := range evictions {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		found := false
+		for _, op := range ops {
+			found = found || op.Namespace == ns
+		}
+		if !found {
+			ops = append(ops, ReplanStat{Evictions: evictions[ns], Namespace: ns})
+		}
+	}
+	SortReplanStats(ops)
+	return ops
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * replans_template.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetReplansTemplate returns HTML
+func GetReplansTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Replans}}
+	<table width='100%'>
+		<caption>Replanning by Shape</caption>
+		<tr><th>op</th><th>namespace</th><th>count</th><th>replans</th><th>avg ms</th><th>replanned avg ms</th>
+			<th>replanned max ms</th><th>impact</th><th>cache evictions</th><th>query pattern</th><th>replan reason</th></tr>
+	{{range $op := .Replans}}
+		<tr><td>{{if $op.Op}}{{$op.Op}}{{else}}-{{end}}</td><td>{{$op.Namespace}}</td>
+			<td align='right'>{{numPrinter $op.Count}}</td>
+			<td align='right'>{{numPrinter $op.Replans}}</td>
+			<td align='right'>{{formatMilli $op.AvgMilli}}</td>
+			<td align='right'>{{formatMilli $op.AvgReplanMilli}}</td>
+			<td align='right'>{{numPrinter $op.MaxReplanMilli}}</td>
+			<td align='right'>{{getDurationFromMillis $op.GetImpact}}</td>
+			<td align='right'>{{numPrinter $op.Evictions}}</td>
+			<td class='break'>{{$op.QueryPattern}}</td>
+			<td class='break'>{{$op.Reason}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<div>Replanning happens when a cached plan performs worse than expected and the plan cache entry is evicted,
+		executions that replan are often intermittently slow.  Impact is the time replanned executions took more
+		than the average of the others of the shape.  Plan cache evictions are logged at a log verbosity of the
+		query component of 1 or higher and are counted by namespace.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no replanning found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"formatMilli": func(ms float64) string {
+			return fmt.Sprintf("%.0f", ms)
+		},
+		"getDurationFromMillis": func(ms int) string {
+			if ms < 1000 {
+				return fmt.Sprintf("%d ms", ms)
+			}
+			return fmt.Sprintf("%.1f s", float64(ms)/1000)
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * replans_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetReplanReason(t *testing.T) {
+	tests := []struct {
+		str    string
+		reason string
+		ns     string
+	}{
+		{`{"t":{"$date":"2023-03-01T10:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","replanned":true,"replanReason":"cached plan was less efficient than expected","durationMillis":900}}`,
+			"cached plan was less efficient than expected", "shop.orders"},
+		{`{"t":{"$date":"2023-03-01T10:00:00.000+00:00"},"s":"D1","c":"QUERY","id":20582,"ctx":"conn2","msg":"Evicting cache entry and replanning query","attr":{"query":"ns: shop.items query: { a: 1 } sort: {} projection: {}"}}`,
+			"Evicting cache entry and replanning query", "shop.items"},
+		{`{"t":{"$date":"2023-03-01T10:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","durationMillis":200}}`,
+			"", "shop.orders"},
+	}
+	for _, test := range tests {
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(test.str), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		reason, ok := GetReplanReason(&doc)
+		if reason != test.reason || ok != (test.reason != "") {
+			t.Fatal("expected", test.reason, "but got", reason, ok)
+		}
+		if ns := GetReplanNamespace(&doc); ns != test.ns {
+			t.Fatal("expected", test.ns, "but got", ns)
+		}
+	}
+}
+
+func TestMergeReplanEvictions(t *testing.T) {
+	ops := []ReplanStat{
+		{Op: "find", Namespace: "shop.orders", Count: 6, Replans: 2, AvgMilli: 150, TotalReplanMilli: 1800},
+		{Op: "find", Namespace: "shop.items", Count: 2, Replans: 2, TotalReplanMilli: 3000},
+	}
+	if impact := ops[0].GetImpact(); impact != 1500 {
+		t.Fatal("expected", 1500, "but got", impact)
+	}
+	ops = MergeReplanEvictions(ops, map[string]int{"shop.orders": 3, "app.users": 1})
+	if len(ops) != 3 || ops[0].Namespace != "shop.items" || ops[2].Namespace != "app.users" {
+		t.Fatal("expected", "shop.items, shop.orders, app.users", "but got", ops)
+	}
+	if ops[1].Evictions != 3 {
+		t.Fatal("expected", 3, "but got", ops[1].Evictions)
+	}
+}
@@ -0,0 +1,468 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * report.go
+ */
+
+package hatchet
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/simagix/gox"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// ReportCard is an audit card printed as a table, -1 of columns is the name
+// and others are indexes of values
+type ReportCard struct {
+	Columns []int
+	Headers []string
+	Key     string
+	Title   string
+}
+
+// REPORT_CARDS lists audit cards of reports in the order of the audit page
+var REPORT_CARDS = []ReportCard{
+	{[]int{-1, 0}, []string{"Name", "Value"}, "server", "Server Info"},
+	{[]int{-1, 0}, []string{"Severity", "Total"}, "exception", "Exceptions"},
+	{[]int{0, 1, -1}, []string{"Driver", "Version", "IP"}, "driver", "Drivers"},
+	{[]int{-1, 0}, []string{"Failed Operation", "Total"}, "failed", "Failed Operations"},
+	{[]int{-1, 0, 1}, []string{"IP", "Accepted Connections", "Response Length"}, "ip", "Stats by IPs"},
+	{[]int{-1, 0}, []string{"Operation", "Total"}, "op", "Operations Stats"},
+	{[]int{-1, 0, 1}, []string{"Namespace", "Accessed", "Response Length"}, "ns", "Stats by Namespaces"},
+	{[]int{-1, 0, 1, 2, 3, 4}, []string{"Namespace", "Query Pattern", "Ops", "Count", "Total ms", "Max ms"}, "collscans", "Collection Scans"},
+	{[]int{-1, 0, 1, 2, 3, 4}, []string{"Op Namespace", "Count", "p50 ms", "p95 ms", "p99 ms", "Max ms"}, "latency", "Latency Percentiles"},
+	{[]int{-1, 0, 1}, []string{"Namespace", "CursorNotFound", "Slow getMore"}, "cursor-not-found", "Cursors Not Found"},
+	{[]int{-1, 0, 1}, []string{"Document", "Writes", "Write Conflicts"}, "hotdoc", "Hot Documents"},
+	{[]int{-1, 0}, []string{"Restarted at", "PID"}, "restart", "Server Restarts"},
+	{[]int{-1, 0, 1, 2, 3}, []string{"Date", "Event", "State", "Term", "Reason"}, "election", "Replica Set Timeline"},
+	{[]int{-1, 0, 2, 3, 4, 1}, []string{"Namespace", "Op", "Docs/Returned", "Keys/Returned", "Count", "Query Pattern"}, "targeting", "Query Targeting"},
+	{[]int{-1, 0, 2, 3, 4, 5, 1}, []string{"Namespace", "Op", "Initial", "Initial Avg ms", "getMore", "getMore Avg ms", "Query Pattern"}, "getmore", "Initial Execution vs getMore Latency"},
+	{[]int{-1, 0, 3, 4, 5, 1, 2}, []string{"Namespace", "Op", "Sorts", "Spills", "Avg ms", "Query Pattern", "Sort"}, "sort", "Blocking Sorts and Spills to Disk"},
+	{[]int{-1, 0, 1, 2, 3}, []string{"Date", "Change", "Name", "Old Value", "New Value"}, "config", "Configuration Changes"},
+	{[]int{-1, 0, 1, 2, 3, 4}, []string{"Namespace", "Migrated", "Failed", "Splits", "Total ms", "Max ms"}, "chunk-migration", "Chunk Migrations"},
+	{[]int{-1, 0, 1, 2, 3}, []string{"Termination", "Count", "Avg ms", "Max ms", "Avg Ops"}, "transaction", "Transactions"},
+}
+
+// ReportChart is a bar chart of labels and values, bars are horizontal
+// unless columns
+type ReportChart struct {
+	Columns bool
+	Labels  []string
+	Title   string
+	Values  []float64
+}
+
+// ReportTable is a titled table of rows of text
+type ReportTable struct {
+	Headers []string
+	Key     string // key of the audit card, or slowops of slowest query shapes
+	Rows    [][]string
+	Title   string
+}
+
+// REPORT_SUMMARY_KEYS lists tables of Markdown summaries
+var REPORT_SUMMARY_KEYS = []string{"slowops", "collscans", "ip", "exception", "failed"}
+
+// Report is the summary, charts, and tables of a hatchet
+type Report struct {
+	Charts  []ReportChart
+	Hatchet string
+	Summary []string
+	Tables  []ReportTable
+}
+
+// RunReport writes a report of a hatchet to a file of a format
+func RunReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	format := fs.String("format", "pdf", "file format, pdf, html, or markdown")
+	output := fs.String("o", "", "output file, {hatchet}.{format} if not set")
+	topN := fs.Int("topN", TOP_N, "rows of each table")
+	url := fs.String("url", SQLITE3_FILE, "database file name or connection string")
+	fs.Parse(args)
+
+	if *format != "pdf" && *format != "html" && *format != "markdown" {
+		return fmt.Errorf("unsupported format %v, use pdf, html, or markdown", *format)
+	} else if fs.NArg() != 1 {
+		return errors.New("usage: hatchet report [-format pdf|html|markdown] [-o file] [-topN n] [-url file] hatchet")
+	}
+	hatchetName := fs.Arg(0)
+	instance = &Logv2{url: *url}
+	if GetLogv2().GetDBType() == SQLite3 {
+		RegisterSQLite3Extended()
+	}
+	dbase, err := GetDatabase(hatchetName)
+	if err != nil {
+		return err
+	}
+	defer dbase.Close()
+	report, err := GetReport(dbase, hatchetName, *topN)
+	if err != nil {
+		return err
+	}
+	filename := *output
+	if filename == "" && *format == "markdown" {
+		filename = hatchetName + ".md"
+	} else if filename == "" {
+		filename = hatchetName + "." + *format
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if *format == "markdown" {
+		_, err = report.WriteMarkdown(file)
+	} else if *format == "html" {
+		_, err = report.WriteHTML(file)
+	} else {
+		_, err = report.WritePDF(file)
+	}
+	if err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+	if err != nil {
+		os.Remove(filename)
+		return err
+	}
+	log.Printf("report of %v written to %v\n", hatchetName, filename)
+	return nil
+}
+
+// GetReport returns a report of audit cards, slowest query shapes, and
+// charts of ops of a hatchet, tables have up to topN rows
+func GetReport(dbase Database, hatchetName string, topN int) (*Report, error) {
+	report := &Report{Hatchet: hatchetName}
+	info := dbase.GetHatchetInfo()
+	report.Summary = append(report.Summary, fmt.Sprintf("Logs from %v to %v.", info.Start, info.End))
+	if info.Version != "" {
+		report.Summary = append(report.Summary, fmt.Sprintf("MongoDB %v %v on %v %v.", info.Module, info.Version, info.OS, info.Arch))
+	}
+	data, err := dbase.GetAuditData()
+	if err != nil {
+		return nil, err
+	}
+	ops, err := dbase.GetSlowOps("total_ms", "DESC", false)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := dbase.GetAverageOpTime("", "")
+	if err != nil {
+		return nil, err
+	}
+	report.Charts = GetReportCharts(data, counts)
+	report.Tables = GetReportTables(data, topN)
+	printer := message.NewPrinter(language.English)
+	table := ReportTable{Key: "slowops", Title: "Slowest Query Shapes by Total Time",
+		Headers: []string{"Op", "Namespace", "Count", "Avg ms", "Max ms", "Total", "Index", "Query Pattern"}}
+	for i, op := range ops {
+		if i == topN {
+			break
+		}
+		table.Rows = append(table.Rows, []string{op.Op, op.Namespace, printer.Sprintf("%d", op.Count),
+			printer.Sprintf("%.0f", op.AvgMilli), printer.Sprintf("%d", op.MaxMilli),
+			gox.GetDurationFromSeconds(float64(op.TotalMilli) / 1000), op.Index, op.QueryPattern})
+	}
+	if len(table.Rows) > 0 {
+		report.Tables = append([]ReportTable{table}, report.Tables...)
+	}
+	return report, nil
+}
+
+// GetReportCharts returns charts of totals by op of audit data and of slow
+// ops over time
+func GetReportCharts(data map[string][]NameValues, counts []OpCount) []ReportChart {
+	charts := []ReportChart{}
+	chart := ReportChart{Title: "Operations Stats"}
+	for _, doc := range data["op"] {
+		if len(doc.Values) > 0 {
+			chart.Labels = append(chart.Labels, doc.Name)
+			chart.Values = append(chart.Values, ToFloat64(doc.Values[0]))
+		}
+	}
+	if len(chart.Values) > 0 {
+		charts = append(charts, chart)
+	}
+	totals := map[string]float64{}
+	for _, doc := range counts {
+		totals[doc.Date] += float64(doc.Count)
+	}
+	dates := []string{}
+	for date := range totals {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	chart = ReportChart{Columns: true, Title: "Slow Ops over Time"}
+	size := int(math.Ceil(float64(len(dates)) / reportMaxColumns))
+	for i := 0; i < len(dates); i += size {
+		total := 0.0
+		for _, date := range dates[i:int(math.Min(float64(i+size), float64(len(dates))))] {
+			total += totals[date]
+		}
+		chart.Labels = append(chart.Labels, dates[i])
+		chart.Values = append(chart.Values, total)
+	}
+	if len(chart.Values) > 0 {
+		charts = append(charts, chart)
+	}
+	return charts
+}
+
+// GetReportTables returns tables of audit cards of data, up to topN rows each
+func GetReportTables(data map[string][]NameValues, topN int) []ReportTable {
+	tables := []ReportTable{}
+	for _, card := range REPORT_CARDS {
+		docs := data[card.Key]
+		if len(docs) == 0 {
+			continue
+		}
+		table := ReportTable{Headers: card.Headers, Key: card.Key, Title: card.Title}
+		for i, doc := range docs {
+			if i == topN {
+				break
+			}
+			row := []string{}
+			for _, column := range card.Columns {
+				if column < 0 {
+					row = append(row, doc.Name)
+				} else if column < len(doc.Values) {
+					row = append(row, formatReportValue(doc.Values[column]))
+				} else {
+					row = append(row, "")
+				}
+			}
+			table.Rows = append(table.Rows, row)
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// formatReportValue formats numbers with thousands separators
+func formatReportValue(value interface{}) string {
+	printer := message.NewPrinter(language.English)
+	switch v := value.(type) {
+	case int, int32, int64:
+		return printer.Sprintf("%d", v)
+	case float64:
+		if v == math.Trunc(v) {
+			return printer.Sprintf("%.0f", v)
+		}
+		return printer.Sprintf("%.2f", v)
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// WriteMarkdown writes a concise summary of slowest query shapes, collection
+// scans, connections by IP, and errors in Markdown to paste into tickets
+func (ptr *Report) WriteMarkdown(w io.Writer) (int64, error) {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "## Hatchet Summary - %v\n\n", ptr.Hatchet)
+	for _, line := range ptr.Summary {
+		fmt.Fprintf(&buf, "%v\n", line)
+	}
+	for _, key := range REPORT_SUMMARY_KEYS {
+		for _, table := range ptr.Tables {
+			if table.Key != key || len(table.Rows) == 0 {
+				continue
+			}
+			fmt.Fprintf(&buf, "\n### %v\n\n| # | %v |\n|--:|%v\n", table.Title, strings.Join(table.Headers, " | "),
+				strings.Repeat("---|", len(table.Headers)))
+			for i, row := range table.Rows {
+				cells := []string{}
+				for _, cell := range row {
+					cells = append(cells, escapeMarkdownCell(cell))
+				}
+				fmt.Fprintf(&buf, "| %d | %v |\n", i+1, strings.Join(cells, " | "))
+			}
+		}
+	}
+	n, err := io.WriteString(w, buf.String())
+	return int64(n), err
+}
+
+// WriteHTML writes the report as a single HTML file of inline styles, chart
+// data, and scripts, to be opened without the web server
+func (ptr *Report) WriteHTML(w io.Writer) (int64, error) {
+	templ, err := GetReportTemplate()
+	if err != nil {
+		return 0, err
+	}
+	var buf strings.Builder
+	if err = templ.Execute(&buf, ptr); err != nil {
+		return 0, err
+	}
+	n, err := io.WriteString(w, buf.String())
+	return int64(n), err
+}
+
+// escapeMarkdownCell escapes pipes of a table cell, query patterns are code
+func escapeMarkdownCell(cell string) string {
+	cell = strings.NewReplacer("|", "\\|", "\n", " ", "\r", " ").Replace(cell)
+	if strings.HasPrefix(cell, "{") && !strings.Contains(cell, "`") {
+		return "`" + cell + "`"
+	}
+	return cell
+}
+
+// layout of report pages in points
+const (
+	reportBottom     = PDF_HEIGHT - 40
+	reportFontSize   = 8.0
+	reportLeading    = 10.0
+	reportMargin     = 36.0
+	reportMaxColumns = 48 // columns of a chart over time, periods are merged beyond
+	reportMaxLines   = 4  // lines of a cell, truncated beyond
+)
+
+// WritePDF writes the report as a paginated PDF
+func (ptr *Report) WritePDF(w io.Writer) (int64, error) {
+	pdf := NewPDF("hatchet report of " + ptr.Hatchet)
+	pdf.AddPage()
+	y := reportMargin + 18
+	pdf.Text(reportMargin, y, 18, true, "Hatchet Report - "+ptr.Hatchet)
+	y += 20
+	for _, line := range ptr.Summary {
+		pdf.Text(reportMargin, y, 10, false, line)
+		y += 14
+	}
+	for _, chart := range ptr.Charts {
+		y = ptr.writeChart(pdf, chart, y+16)
+	}
+	for _, table := range ptr.Tables {
+		y = ptr.writeTable(pdf, table, y+16)
+	}
+	return pdf.WriteTo(w)
+}
+
+// writeChart draws a chart starting at y on a new page if not fit and
+// returns y below it
+func (ptr *Report) writeChart(pdf *PDF, chart ReportChart, y float64) float64 {
+	height := 180.0
+	if !chart.Columns {
+		height = math.Min(float64(len(chart.Values))*14+8, 320)
+	}
+	if y+24+height > reportBottom {
+		pdf.AddPage()
+		y = reportMargin
+	}
+	pdf.Text(reportMargin, y+12, 12, true, chart.Title)
+	y += 24
+	max := 0.0
+	for _, value := range chart.Values {
+		max = math.Max(max, value)
+	}
+	if max == 0 {
+		max = 1
+	}
+	printer := message.NewPrinter(language.English)
+	barColor := [3]float64{0.23, 0.51, 0.80}
+	if chart.Columns {
+		width := PDF_WIDTH - 2*reportMargin - 60
+		barWidth := width / float64(len(chart.Values))
+		left := reportMargin + 60
+		pdf.Text(reportMargin, y+reportFontSize, reportFontSize, false, printer.Sprintf("%.0f", max))
+		pdf.Line(left, y+height, left+width, y+height, 0.5)
+		for i, value := range chart.Values {
+			h := (height - 20) * value / max
+			pdf.Rect(left+float64(i)*barWidth+1, y+height-h, math.Max(barWidth-2, 1), h, barColor)
+		}
+		for _, i := range []int{0, len(chart.Labels) - 1} {
+			label := chart.Labels[i]
+			x := left + float64(i)*barWidth
+			if i > 0 {
+				x = left + width - PDFTextWidth(label, reportFontSize, false)
+			}
+			pdf.Text(x, y+height+reportLeading, reportFontSize, false, label)
+		}
+		return y + height + reportLeading
+	}
+	labelWidth := 160.0
+	width := PDF_WIDTH - 2*reportMargin - labelWidth - 80
+	for i, value := range chart.Values {
+		if float64(i+1)*14 > height {
+			break
+		}
+		top := y + float64(i)*14
+		label := FitPDFText(chart.Labels[i], labelWidth-8, reportFontSize, false, 1)[0]
+		pdf.Text(reportMargin, top+10, reportFontSize, false, label)
+		pdf.Rect(reportMargin+labelWidth, top+2, math.Max(width*value/max, 1), 10, barColor)
+		pdf.Text(reportMargin+labelWidth+width*value/max+4, top+10, reportFontSize, false, printer.Sprintf("%.0f", value))
+	}
+	return y + height
+}
+
+// writeTable prints a table starting at y, rows continue on new pages with
+// headers repeated, and returns y below it
+func (ptr *Report) writeTable(pdf *PDF, table ReportTable, y float64) float64 {
+	widths := getReportColumnWidths(table, PDF_WIDTH-2*reportMargin)
+	if y+24+3*reportLeading > reportBottom {
+		pdf.AddPage()
+		y = reportMargin
+	}
+	pdf.Text(reportMargin, y+12, 12, true, table.Title)
+	y += 18
+	writeRow := func(cells []string, bold bool, shade bool) {
+		lines := [][]string{}
+		height := 0
+		for i, cell := range cells {
+			lines = append(lines, FitPDFText(cell, widths[i]-6, reportFontSize, bold, reportMaxLines))
+			height = int(math.Max(float64(height), float64(len(lines[i]))))
+		}
+		if shade {
+			pdf.Rect(reportMargin, y, PDF_WIDTH-2*reportMargin, float64(height)*reportLeading+4, [3]float64{0.93, 0.95, 0.97})
+		}
+		x := reportMargin
+		for i := range cells {
+			for n, line := range lines[i] {
+				pdf.Text(x+3, y+float64(n+1)*reportLeading-1, reportFontSize, bold, line)
+			}
+			x += widths[i]
+		}
+		y += float64(height)*reportLeading + 4
+	}
+	writeRow(table.Headers, true, true)
+	pdf.Line(reportMargin, y, PDF_WIDTH-reportMargin, y, 0.5)
+	for i, row := range table.Rows {
+		if y+reportMaxLines*reportLeading+4 > reportBottom {
+			pdf.AddPage()
+			y = reportMargin
+			writeRow(table.Headers, true, true)
+			pdf.Line(reportMargin, y, PDF_WIDTH-reportMargin, y, 0.5)
+		}
+		writeRow(row, false, i%2 == 1)
+	}
+	return y
+}
+
+// getReportColumnWidths returns widths of columns in proportion to the widest
+// cells, capped so that long patterns wrap
+func getReportColumnWidths(table ReportTable, total float64) []float64 {
+	widths := make([]float64, len(table.Headers))
+	sum := 0.0
+	for i, header := range table.Headers {
+		widths[i] = PDFTextWidth(header, reportFontSize, true) + 8
+		for _, row := range table.Rows {
+			if i < len(row) {
+				widths[i] = math.Max(widths[i], math.Min(PDFTextWidth(strings.TrimSpace(row[i]), reportFontSize, false)+8, 320))
+			}
+		}
+		sum += widths[i]
+	}
+	for i := range widths {
+		widths[i] = widths[i] * total / sum
+	}
+	return widths
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * report_template.go
+ */
+
+package hatchet
+
+import (
+	"html/template"
+)
+
+// GetReportTemplate returns a template of a self-contained HTML report,
+// styles, chart data, and scripts are inline to open offline
+func GetReportTemplate() (*template.Template, error) {
+	html := `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>Hatchet Report - {{.Hatchet}}</title>
+  <style>
+    body { font-family: Helvetica, Arial, sans-serif; margin: 10px; background-color: #F3F7F4; color: #2C5234; }
+    table { border-collapse: collapse; min-width: 300px; margin: 10px 0px; }
+    caption { caption-side: top; font-size: 1.1em; font-weight: bold; text-align: left; padding: 5px 0px; }
+    table, th, td { border: 1px solid #DDD; vertical-align: middle; }
+    th { background-color: #C1D8C5; padding: 0.2rem; font-size: 0.9em; text-align: left; }
+    td { background-color: #E8F1E9; padding: 0.2rem; font-size: 0.9em; }
+    tr:nth-child(even) td { background-color: white; }
+    .break { font-size: .8em; word-break: break-all; }
+    .chart { margin: 10px 0px; font-size: 11px; }
+    .chart rect { fill: #5E8961; }
+    .chart rect:hover { fill: #DB4437; }
+  </style>
+</head>
+<body>
+  <h2>Hatchet Report - {{.Hatchet}}</h2>
+  {{range $line := .Summary}}<div>{{$line}}</div>{{end}}
+  <div id='charts'></div>
+{{range $table := .Tables}}
+  <table>
+    <caption>{{$table.Title}}</caption>
+    <tr><th></th>{{range $h := $table.Headers}}<th>{{$h}}</th>{{end}}</tr>
+  {{range $n, $row := $table.Rows}}
+    <tr><td align=right>{{add $n 1}}</td>{{range $cell := $row}}<td class='break'>{{$cell}}</td>{{end}}</tr>
+  {{end}}
+  </table>
+{{end}}
+  <script>
+    var charts = {{.Charts}};
+    var svgns = 'http://www.w3.org/2000/svg';
+    function svgElement(name, attrs, text) {
+      var e = document.createElementNS(svgns, name);
+      for (var k in attrs) { e.setAttribute(k, attrs[k]); }
+      if (text != null) { e.textContent = text; }
+      return e;
+    }
+    (charts || []).forEach(function(chart) {
+      var div = document.createElement('div');
+      div.className = 'chart';
+      var h = document.createElement('h3');
+      h.textContent = chart.Title;
+      div.appendChild(h);
+      var max = Math.max.apply(null, chart.Values.concat([1]));
+      var width = 900, label = 160, height;
+      var svg;
+      if (chart.Columns) {
+        height = 220;
+        svg = svgElement('svg', {width: width, height: height + 20});
+        var w = (width - 60) / chart.Values.length;
+        chart.Values.forEach(function(v, i) {
+          var bar = svgElement('rect', {x: 60 + i * w + 1, y: height - (height - 20) * v / max,
+            width: Math.max(w - 2, 1), height: (height - 20) * v / max});
+          bar.appendChild(svgElement('title', {}, chart.Labels[i] + ': ' + v.toLocaleString()));
+          svg.appendChild(bar);
+        });
+        svg.appendChild(svgElement('text', {x: 0, y: 12}, max.toLocaleString()));
+        svg.appendChild(svgElement('text', {x: 60, y: height + 15}, chart.Labels[0]));
+        svg.appendChild(svgElement('text', {x: width, y: height + 15, 'text-anchor': 'end'},
+          chart.Labels[chart.Labels.length - 1]));
+      } else {
+        height = chart.Values.length * 18;
+        svg = svgElement('svg', {width: width, height: height});
+        chart.Values.forEach(function(v, i) {
+          var len = Math.max((width - label - 100) * v / max, 1);
+          svg.appendChild(svgElement('text', {x: 0, y: i * 18 + 13}, chart.Labels[i]));
+          svg.appendChild(svgElement('rect', {x: label, y: i * 18 + 3, width: len, height: 12}));
+          svg.appendChild(svgElement('text', {x: label + len + 4, y: i * 18 + 13}, v.toLocaleString()));
+        });
+      }
+      div.appendChild(svg);
+      document.getElementById('charts').appendChild(div);
+    });
+  </script>
+</body>
+</html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"add": func(a int, b int) int {
+			return a + b
+		}}).Parse(html)
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * report_test.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGetReportTables(t *testing.T) {
+	data := map[string][]NameValues{
+		"sort": {{Name: "shop.orders", Values: []interface{}{"find", "{ status:1 }", "{ date:-1 }", 12, 3, 250.5}}},
+		"op":   {{Name: "find", Values: []interface{}{12000}}, {Name: "update", Values: []interface{}{100}}},
+	}
+	tables := GetReportTables(data, 1)
+	if len(tables) != 2 || tables[0].Title != "Operations Stats" || len(tables[0].Rows) != 1 {
+		t.Fatal("expected", "Operations Stats of 1 row first", "but got", tables)
+	}
+	expected := "[shop.orders find 12 3 250.50 { status:1 } { date:-1 }]"
+	if row := fmt.Sprint(tables[1].Rows[0]); row != expected {
+		t.Fatal("expected", expected, "but got", row)
+	}
+	if tables[0].Rows[0][1] != "12,000" {
+		t.Fatal("expected", "12,000", "but got", tables[0].Rows[0][1])
+	}
+}
+
+func TestReportWritePDF(t *testing.T) {
+	counts := []OpCount{{Date: "2023-01-01T00:01", Count: 3}, {Date: "2023-01-01T00:00", Count: 2}}
+	report := &Report{Hatchet: "mongod_1a2b3c", Summary: []string{"summary"}}
+	report.Charts = GetReportCharts(map[string][]NameValues{"op": {{Name: "find", Values: []interface{}{5}}}}, counts)
+	if len(report.Charts) != 2 || report.Charts[1].Labels[0] != "2023-01-01T00:00" || report.Charts[1].Values[1] != 3 {
+		t.Fatal("expected", "charts of ops and of slow ops over time", "but got", report.Charts)
+	}
+	table := ReportTable{Headers: []string{"Namespace", "Query Pattern"}, Title: "Shapes"}
+	for i := 0; i < 100; i++ {
+		table.Rows = append(table.Rows, []string{"shop.orders", strings.Repeat("{ status:1 } ", 50)})
+	}
+	report.Tables = append(report.Tables, table)
+	var buf strings.Builder
+	if _, err := report.WritePDF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "%PDF-") || strings.Contains(buf.String(), "/Count 1 ") {
+		t.Fatal("expected", "a PDF of pages", "but got", buf.String()[:200])
+	}
+	if !strings.Contains(buf.String(), "(hatchet report of mongod_1a2b3c - page 1 of") {
+		t.Fatal("expected", "footers of page numbers", "but got", buf.String()[:200])
+	}
+}
+
+func TestReportWriteMarkdown(t *testing.T) {
+	report := &Report{Hatchet: "mongod_1a2b3c", Summary: []string{"summary"}}
+	report.Tables = []ReportTable{
+		{Key: "op", Title: "Operations Stats", Headers: []string{"Operation"}, Rows: [][]string{{"find"}}},
+		{Key: "exception", Title: "Exceptions", Headers: []string{"Severity", "Total"}, Rows: [][]string{{"Warn", "10"}}},
+		{Key: "slowops", Title: "Slowest", Headers: []string{"Op", "Query Pattern"}, Rows: [][]string{{"find", "{ $or:[ a|b ] }"}}},
+	}
+	var buf strings.Builder
+	if _, err := report.WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	md := buf.String()
+	if strings.Contains(md, "Operations Stats") || strings.Index(md, "### Slowest") > strings.Index(md, "### Exceptions") {
+		t.Fatal("expected", "slowest shapes and exceptions only", "but got", md)
+	}
+	for _, line := range []string{"| # | Severity | Total |", "| 1 | find | `{ $or:[ a\\|b ] }` |"} {
+		if !strings.Contains(md, line+"\n") {
+			t.Fatal("expected", line, "but got", md)
+		}
+	}
+}
+
+func TestReportWriteHTML(t *testing.T) {
+	report := &Report{Hatchet: "mongod_1a2b3c", Summary: []string{"summary"}}
+	report.Charts = []ReportChart{{Labels: []string{"find"}, Title: "Operations Stats", Values: []float64{5}}}
+	report.Tables = []ReportTable{{Key: "slowops", Title: "Slowest", Headers: []string{"Query Pattern"},
+		Rows: [][]string{{"{ a:'<script>' }"}}}}
+	var buf strings.Builder
+	if _, err := report.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, s := range []string{`var charts = [{"Columns":false,"Labels":["find"],"Title":"Operations Stats","Values":[5]}];`,
+		"<td class='break'>{ a:&#39;&lt;script&gt;&#39; }</td>"} {
+		if !strings.Contains(html, s) {
+			t.Fatal("expected", s, "but got", html)
+		}
+	}
+	if strings.Contains(html, " src=") || strings.Contains(html, "<link") {
+		t.Fatal("expected", "no external resources", "but got", html)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * reslen.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+)
+
+// RESLEN_AVG_FLAG is the avg response bytes per op of a shape flagged
+const RESLEN_AVG_FLAG = 1024 * 1024
+
+// RESLEN_SERIES are series of GetReslenOverTime
+var RESLEN_SERIES = []string{"total (MB)", "avg per op (KB)", "max (KB)"}
+
+// ReslenStat stores response bytes of a namespace or an op shape
+type ReslenStat struct {
+	AvgBytes     int     `json:"avg_bytes" bson:"avg_bytes"` // per op
+	Count        int     `json:"count" bson:"count"`
+	Index        string  `json:"index,omitempty" bson:"index,omitempty"`
+	Namespace    string  `json:"ns" bson:"ns"`
+	Op           string  `json:"op,omitempty" bson:"op,omitempty"`
+	QueryPattern string  `json:"query_pattern,omitempty" bson:"query_pattern,omitempty"`
+	Ratio        float64 `json:"ratio" bson:"ratio"` // of total bytes of all ops
+	TotalBytes   int     `json:"total_bytes" bson:"total_bytes"`
+}
+
+// GetReslenStats returns the topN namespaces and op shapes of the most
+// response bytes of slow op shapes, 0 for all
+func GetReslenStats(ops []OpStat, topN int) ([]ReslenStat, []ReslenStat) {
+	namespaces := map[string]*ReslenStat{}
+	shapes := []ReslenStat{}
+	total := 0
+	for _, op := range ops {
+		if op.Reslen <= 0 {
+			continue
+		}
+		total += op.Reslen
+		shapes = append(shapes, ReslenStat{Count: op.Count, Index: op.Index, Namespace: op.Namespace, Op: op.Op,
+			QueryPattern: op.QueryPattern, TotalBytes: op.Reslen})
+		stat, ok := namespaces[op.Namespace]
+		if !ok {
+			stat = &ReslenStat{Namespace: op.Namespace}
+			namespaces[op.Namespace] = stat
+		}
+		stat.Count += op.Count
+		stat.TotalBytes += op.Reslen
+	}
+	nsStats := []ReslenStat{}
+	for _, stat := range namespaces {
+		nsStats = append(nsStats, *stat)
+	}
+	return rankReslenStats(nsStats, total, topN), rankReslenStats(shapes, total, topN)
+}
+
+// rankReslenStats sorts by total bytes, sets averages and ratios of the
+// total, and returns the topN
+func rankReslenStats(stats []ReslenStat, total int, topN int) []ReslenStat {
+	for i := range stats {
+		if stats[i].Count > 0 {
+			stats[i].AvgBytes = stats[i].TotalBytes / stats[i].Count
+		}
+		if total > 0 {
+			stats[i].Ratio = float64(stats[i].TotalBytes) / float64(total)
+		}
+	}
+	sort.SliceStable(stats, func(i int, j int) bool {
+		if stats[i].TotalBytes != stats[j].TotalBytes {
+			return stats[i].TotalBytes > stats[j].TotalBytes
+		}
+		return stats[i].Namespace < stats[j].Namespace
+	})
+	if topN > 0 && len(stats) > topN {
+		stats = stats[:topN]
+	}
+	return stats
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * reslen_template.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"html/template"
+
+	"github.com/simagix/gox"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetReslenTemplate returns HTML
+func GetReslenTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{$hatchet := .Hatchet}}
+{{if .Namespaces}}
+	<table width='100%'>
+		<caption>Namespaces by Response Bytes
+			(<a href='/hatchets/{{$hatchet}}/charts/reslen-time?type=bytes'>chart</a>)</caption>
+		<tr><th>#</th><th>namespace</th><th>ops</th><th>total bytes</th><th>avg per op</th><th>% of total</th></tr>
+	{{range $n, $s := .Namespaces}}
+		<tr><td align='right'>{{add $n 1}}</td>
+			<td><a href='/hatchets/{{$hatchet}}/stats/slowops?orderBy=reslen&ns=^{{$s.Namespace}}$'>{{$s.Namespace}}</a></td>
+			<td align='right'>{{numPrinter $s.Count}}</td>
+			<td align='right'>{{storageSize $s.TotalBytes}}</td>
+			<td align='right'>{{storageSize $s.AvgBytes}}</td>
+			<td align='right'>{{formatPercent $s.Ratio}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<table width='100%'>
+		<caption>Query Shapes by Response Bytes</caption>
+		<tr><th>#</th><th>op</th><th>namespace</th><th>ops</th><th>total bytes</th><th>avg per op</th><th>% of total</th>
+			<th>index</th><th>query pattern</th></tr>
+	{{range $n, $s := .Shapes}}
+		<tr><td align='right'>{{add $n 1}}</td>
+			<td>{{$s.Op}}</td>
+			<td>{{$s.Namespace}}</td>
+			<td align='right'>{{numPrinter $s.Count}}</td>
+			<td align='right'>{{storageSize $s.TotalBytes}}</td>
+			<td align='right'>{{if ge $s.AvgBytes $.AvgFlag}}<span style='color: red;'>{{storageSize $s.AvgBytes}}</span>{{else}}{{storageSize $s.AvgBytes}}{{end}}</td>
+			<td align='right'>{{formatPercent $s.Ratio}}</td>
+			<td>{{$s.Index}}</td>
+			<td class='break'>{{$s.QueryPattern}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<div>Response bytes are of reslen of slow ops logged; shapes returning {{storageSize .AvgFlag}} or more per op
+		on average are in red, often of unbounded or unprojected result sets.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no response lengths found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"add": func(a int, b int) int {
+			return a + b
+		},
+		"formatPercent": func(f float64) string {
+			return fmt.Sprintf("%.1f%%", 100*f)
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		},
+		"storageSize": func(n int) string {
+			return gox.GetStorageSize(n)
+		}}).Parse(html)
+}
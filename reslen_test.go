@@ -0,0 +1,32 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * reslen_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestGetReslenStats(t *testing.T) {
+	ops := []OpStat{
+		{Op: "find", Namespace: "shop.orders", QueryPattern: "{ status:1 }", Count: 10, Reslen: 5000},
+		{Op: "find", Namespace: "shop.products", QueryPattern: "{ category:1 }", Count: 2, Reslen: 8000},
+		{Op: "aggregate", Namespace: "shop.orders", QueryPattern: "{ $match:{ sku:1 } }", Count: 5, Reslen: 7000},
+		{Op: "update", Namespace: "shop.carts", QueryPattern: "{ _id:1 }", Count: 100},
+	}
+	namespaces, shapes := GetReslenStats(ops, 0)
+	if len(namespaces) != 2 || namespaces[0].Namespace != "shop.orders" || namespaces[0].TotalBytes != 12000 ||
+		namespaces[0].Count != 15 || namespaces[0].AvgBytes != 800 || namespaces[0].Ratio != 0.6 {
+		t.Fatal("expected", "shop.orders of 12000 bytes first", "but got", namespaces)
+	}
+	if len(shapes) != 3 || shapes[0].Namespace != "shop.products" || shapes[0].AvgBytes != 4000 || shapes[2].Op != "find" {
+		t.Fatal("expected", "shapes of shop.products first", "but got", shapes)
+	}
+
+	namespaces, shapes = GetReslenStats(ops, 1)
+	if len(namespaces) != 1 || len(shapes) != 1 || shapes[0].TotalBytes != 8000 {
+		t.Fatal("expected", "top 1", "but got", namespaces, shapes)
+	}
+}
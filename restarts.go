@@ -0,0 +1,56 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * restarts.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+	"strings"
+)
+
+const RESTART_WARN = 3
+
+// RestartStats detects server restarts within a log
+type RestartStats struct {
+	Restarts []NameValue // date and pid of startups after the first
+	running  bool
+}
+
+// NewRestartStats returns RestartStats
+func NewRestartStats() *RestartStats {
+	return &RestartStats{Restarts: []NameValue{}}
+}
+
+// IsStartupLog returns true if a log is the startup line with host, pid, and port
+func IsStartupLog(doc *Logv2Info) bool {
+	return doc.Component == "CONTROL" && doc.Attr.Map()["host"] != nil
+}
+
+// Add records a restart if a startup line follows logs of a running server,
+// logs before the startup line of a boot have the context main or -
+func (ptr *RestartStats) Add(doc *Logv2Info, date string) {
+	if IsStartupLog(doc) {
+		if ptr.running {
+			ptr.Restarts = append(ptr.Restarts, NameValue{date, ToInt(doc.Attr.Map()["pid"])})
+		}
+		ptr.running = true
+	} else if doc.Context != "main" && doc.Context != "-" {
+		ptr.running = true
+	}
+}
+
+// GetRestarts returns sorted restart dates of audit data, restarts between
+// start and end if both are given
+func GetRestarts(data map[string][]NameValues, start string, end string) []string {
+	dates := []string{}
+	for _, doc := range data["restart"] {
+		if start != "" && end != "" && (strings.Compare(doc.Name, start) < 0 || strings.Compare(doc.Name, end) > 0) {
+			continue
+		}
+		dates = append(dates, doc.Name)
+	}
+	sort.Strings(dates)
+	return dates
+}
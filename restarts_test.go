@@ -0,0 +1,40 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * restarts_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestRestartStats(t *testing.T) {
+	startup := Logv2Info{Component: "CONTROL", Context: "initandlisten",
+		Attr: bson.D{{Key: "pid", Value: int32(1234)}, {Key: "port", Value: int32(27017)}, {Key: "host", Value: "h1"}}}
+	stats := NewRestartStats()
+	stats.Add(&Logv2Info{Component: "CONTROL", Context: "main"}, "2023-10-01T00:00:00")
+	stats.Add(&startup, "2023-10-01T00:00:01")
+	if len(stats.Restarts) != 0 {
+		t.Fatal("expected", 0, "but got", len(stats.Restarts))
+	}
+	stats.Add(&Logv2Info{Component: "NETWORK", Context: "conn1"}, "2023-10-01T01:00:00")
+	stats.Add(&Logv2Info{Component: "CONTROL", Context: "main"}, "2023-10-01T02:00:00")
+	stats.Add(&startup, "2023-10-01T02:00:01")
+	if len(stats.Restarts) != 1 || stats.Restarts[0].Value != 1234 {
+		t.Fatal("expected", 1, 1234, "but got", stats.Restarts)
+	}
+}
+
+func TestGetRestarts(t *testing.T) {
+	data := map[string][]NameValues{"restart": {
+		{"2023-10-03T00:00:00", []interface{}{2}}, {"2023-10-01T00:00:00", []interface{}{1}}}}
+	if dates := GetRestarts(data, "", ""); len(dates) != 2 || dates[0] != "2023-10-01T00:00:00" {
+		t.Fatal("expected", "sorted dates", "but got", dates)
+	}
+	if dates := GetRestarts(data, "2023-10-02T00:00:00", "2023-10-04T00:00:00"); len(dates) != 1 {
+		t.Fatal("expected", 1, "but got", len(dates))
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
@@ -103,7 +104,7 @@ func (c *S3Client) GetObject(logname string) ([]byte, error) {
 	toks := strings.Split(logname, "/")
 	bucket := toks[0]
 	key := strings.Join(toks[1:], "/")
-	resp, err := c.service.GetObject(&s3.GetObjectInput{
+	resp, err := c.service.GetObjectWithContext(aws.BackgroundContext(), &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
@@ -113,3 +114,18 @@ func (c *S3Client) GetObject(logname string) ([]byte, error) {
 	defer resp.Body.Close()
 	return io.ReadAll(resp.Body)
 }
+
+// GetObjectReader returns a reader streaming an object, the client timeout
+// doesn't apply to reading the body
+func (c *S3Client) GetObjectReader(bucket, key string) (io.ReadCloser, error) {
+	resp, err := c.service.GetObjectWithContext(aws.BackgroundContext(), &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, func(r *request.Request) {
+		r.Config.HTTPClient = &http.Client{}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving S3 object: %v", err)
+	}
+	return resp.Body, nil
+}
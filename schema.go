@@ -0,0 +1,112 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * schema.go
+ */
+
+package hatchet
+
+// SCHEMA_VERSION is increased when fields are renamed or removed, added
+// fields don't change the version
+const SCHEMA_VERSION = 1
+
+// SchemaField describes a field exposed by hatchet, Filter is the query
+// string parameter to filter by the field and Sort is the orderBy value
+type SchemaField struct {
+	Name        string `json:"name"`
+	Column      string `json:"column"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Filter      string `json:"filter,omitempty"`
+	Groupable   bool   `json:"groupable"`
+	Sort        string `json:"sort,omitempty"`
+}
+
+// Schema describes fields of logs and op shapes
+type Schema struct {
+	Version int                      `json:"version"`
+	Tables  map[string][]SchemaField `json:"tables"`
+}
+
+// GetSchema returns fields of the logs table, {hatchet}, and the op shapes
+// table, {hatchet}_ops
+func GetSchema() Schema {
+	logs := []SchemaField{
+		{Name: "date", Column: "date", Type: "date", Description: "log timestamp", Filter: "duration", Groupable: true},
+		{Name: "severity", Column: "severity", Type: "string", Description: "F, E, W, I, or D1 to D5", Filter: "severity", Groupable: true},
+		{Name: "component", Column: "component", Type: "string", Description: "log component", Filter: "component", Groupable: true},
+		{Name: "context", Column: "context", Type: "string", Description: "thread or connection name", Filter: "context", Groupable: true},
+		{Name: "msg", Column: "msg", Type: "string", Description: "log message"},
+		{Name: "planSummary", Column: "plan", Type: "string", Description: "plan summary"},
+		{Name: "type", Column: "type", Type: "string", Description: "op type logged"},
+		{Name: "namespace", Column: "ns", Type: "string", Description: "database.collection", Filter: "ns", Groupable: true},
+		{Name: "message", Column: "message", Type: "string", Description: "log message in legacy format"},
+		{Name: "op", Column: "op", Type: "string", Description: "command of a slow op", Groupable: true},
+		{Name: "queryPattern", Column: "filter", Type: "string", Description: "query shape of a slow op", Groupable: true},
+		{Name: "sortPattern", Column: "sort", Type: "string", Description: "sort shape of a slow op", Groupable: true},
+		{Name: "index", Column: "_index", Type: "string", Description: "index used, COLLSCAN, or error", Groupable: true},
+		{Name: "durationMillis", Column: "milli", Type: "int", Description: "op duration in milliseconds"},
+		{Name: "reslen", Column: "reslen", Type: "int", Description: "response length in bytes"},
+		{Name: "ticketWait", Column: "ticket_wait", Type: "int", Description: "execution ticket wait in microseconds, null if not logged"},
+		{Name: "planningTimeMicros", Column: "planning_micros", Type: "int", Description: "query planning time in microseconds, null if not logged"},
+		{Name: "nShards", Column: "n_shards", Type: "int", Description: "shards an op was sent to by a mongos, null if not from a mongos"},
+		{Name: "shards", Column: "shards", Type: "string", Description: "comma separated shard names if logged by a mongos"},
+		{Name: "replanReason", Column: "replan_reason", Type: "string", Description: "replan reason of a slow op or a plan cache eviction, null if not replanned"},
+		{Name: "stages", Column: "stages", Type: "string", Description: "comma separated stages of a slow aggregate pipeline, null if not an aggregate", Groupable: true},
+		{Name: "replLagMillis", Column: "repl_lag_ms", Type: "int", Description: "replication lag in milliseconds of an oplog entry applied by a secondary, null if not logged"},
+		{Name: "wtEvent", Column: "wt_event", Type: "string", Description: "checkpoint, eviction, or cache of a WiredTiger message, null otherwise", Groupable: true},
+		{Name: "wtCheckpointMillis", Column: "wt_checkpoint_ms", Type: "int", Description: "milliseconds a WiredTiger checkpoint has been running, null if not logged"},
+		{Name: "lockWaitCount", Column: "lock_wait_count", Type: "int", Description: "waits acquiring locks of all levels and modes, null if not logged"},
+		{Name: "lockWaitMicros", Column: "lock_wait_micros", Type: "int", Description: "microseconds waited acquiring locks of all levels and modes, null if not logged"},
+		{Name: "ttlDeleted", Column: "ttl_deleted", Type: "int", Description: "documents deleted by a pass of a TTL index, null if not a TTL deletion"},
+		{Name: "ttlMillis", Column: "ttl_ms", Type: "int", Description: "milliseconds of a pass of a TTL index, null if not a TTL deletion"},
+		{Name: "chunkEvent", Column: "chunk_event", Type: "string", Description: "moveChunk.from, moveChunk.error, split, or balancer.round of a logged metadata event, null otherwise", Groupable: true},
+		{Name: "chunkMillis", Column: "chunk_ms", Type: "int", Description: "milliseconds of a chunk migration, the sum of its steps, or of a balancer round, null otherwise"},
+		{Name: "txnResult", Column: "txn_result", Type: "string", Description: "committed or aborted of a multi-document transaction, null otherwise", Groupable: true},
+		{Name: "txnCause", Column: "txn_cause", Type: "string", Description: "error of an aborted transaction, e.g. WriteConflict or LockTimeout, null otherwise", Groupable: true},
+		{Name: "txnMillis", Column: "txn_ms", Type: "int", Description: "milliseconds of a transaction, null if not a transaction"},
+		{Name: "txnOps", Column: "txn_ops", Type: "int", Description: "documents inserted, modified, deleted, and returned by a transaction, null if not a transaction"},
+		{Name: "configChange", Column: "config_change", Type: "string", Description: "setParameter, featureCompatibilityVersion, or commitQuorum of a runtime configuration change, null otherwise", Groupable: true},
+		{Name: "configName", Column: "config_name", Type: "string", Description: "parameter name or index build of a configuration change, null otherwise"},
+		{Name: "configOld", Column: "config_old", Type: "string", Description: "value before a configuration change, empty if not logged, null otherwise"},
+		{Name: "configNew", Column: "config_new", Type: "string", Description: "value after a configuration change, null otherwise"},
+		{Name: "keysExamined", Column: "keys_examined", Type: "int", Description: "index keys examined by a slow op, null if not logged"},
+		{Name: "docsExamined", Column: "docs_examined", Type: "int", Description: "documents examined by a slow op, null if not logged"},
+		{Name: "nreturned", Column: "nreturned", Type: "int", Description: "documents returned by a slow op, null if not logged"},
+		{Name: "hasSortStage", Column: "has_sort_stage", Type: "int", Description: "1 if a slow op had an in-memory blocking sort, null if neither a sort nor a spill is logged"},
+		{Name: "usedDisk", Column: "used_disk", Type: "int", Description: "1 if a slow op spilled to disk, null if neither a sort nor a spill is logged"},
+		{Name: "originatingOp", Column: "originating_op", Type: "string", Description: "op of the originating command of a getMore, or find or aggregate, null otherwise", Groupable: true},
+		{Name: "cursorId", Column: "cursor_id", Type: "int", Description: "cursor id of a getMore or of a find or aggregate returning a cursor, null otherwise"},
+		{Name: "bytesRead", Column: "bytes_read", Type: "int", Description: "bytes read from disk by a slow op of storage.data, null if not logged"},
+		{Name: "timeReadingMicros", Column: "read_micros", Type: "int", Description: "microseconds reading from disk by a slow op of storage.data, null if not logged"},
+		{Name: "logId", Column: "log_id", Type: "int", Description: "id of a message of logs in JSON format, stored as id by MongoDB, null if in legacy format", Groupable: true},
+		{Name: "appName", Column: "app_name", Type: "string", Description: "appName of the connection of an op from client metadata, null if unknown", Groupable: true},
+		{Name: "source", Column: "source", Type: "string", Description: "source of a line of logs merged with -merge", Filter: "source", Groupable: true},
+		{Name: "raw", Column: "raw", Type: "string", Description: "original log line, null unless processed with -raw"},
+	}
+	for _, column := range WRITE_COUNTERS {
+		logs = append(logs, SchemaField{Name: column, Column: column, Type: "int",
+			Description: "documents counter of writes, null if not logged"})
+	}
+	for _, column := range LOCK_COUNTERS {
+		logs = append(logs, SchemaField{Name: column, Column: column, Type: "int",
+			Description: "lock acquisitions of a level and mode, null if not logged"})
+	}
+	ops := []SchemaField{
+		{Name: "op", Column: "op", Type: "string", Description: "command", Groupable: true, Sort: "op"},
+		{Name: "namespace", Column: "ns", Type: "string", Description: "database.collection", Filter: "ns", Groupable: true, Sort: "ns"},
+		{Name: "queryPattern", Column: "filter", Type: "string", Description: "query shape", Groupable: true},
+		{Name: "sortPattern", Column: "sort", Type: "string", Description: "sort shape, fields in order with 1 or -1", Groupable: true},
+		{Name: "index", Column: "_index", Type: "string", Description: "index used, COLLSCAN, or error", Filter: COLLSCAN, Groupable: true, Sort: "index"},
+		{Name: "count", Column: "count", Type: "int", Description: "number of ops", Sort: "count"},
+		{Name: "avgMillis", Column: "avg_ms", Type: "float", Description: "average duration in milliseconds", Sort: "avg_ms"},
+		{Name: "maxMillis", Column: "max_ms", Type: "int", Description: "max duration in milliseconds", Sort: "max_ms"},
+		{Name: "p50Millis", Column: "p50_ms", Type: "int", Description: "median duration in milliseconds", Sort: "p50_ms"},
+		{Name: "p95Millis", Column: "p95_ms", Type: "int", Description: "95th percentile duration in milliseconds", Sort: "p95_ms"},
+		{Name: "p99Millis", Column: "p99_ms", Type: "int", Description: "99th percentile duration in milliseconds", Sort: "p99_ms"},
+		{Name: "totalMillis", Column: "total_ms", Type: "int", Description: "total duration in milliseconds", Sort: "total_ms"},
+		{Name: "reslen", Column: "reslen", Type: "int", Description: "total response length in bytes", Sort: "reslen"},
+		{Name: "firstSeen", Column: "first_seen", Type: "date", Description: "first op timestamp", Sort: "first_seen"},
+		{Name: "lastSeen", Column: "last_seen", Type: "date", Description: "last op timestamp", Sort: "last_seen"},
+	}
+	return Schema{Version: SCHEMA_VERSION, Tables: map[string][]SchemaField{"logs": logs, "ops": ops}}
+}
@@ -0,0 +1,23 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * schema_handler.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// SchemaHandler responds to API calls
+func SchemaHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	/** APIs
+	 * /api/hatchet/v1.0/schema
+	 */
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": 1, "schema": GetSchema()})
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * schema_test.go
+ */
+
+package hatchet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetSchema(t *testing.T) {
+	schema := GetSchema()
+	if schema.Version != SCHEMA_VERSION {
+		t.Fatal("expected", SCHEMA_VERSION, "but got", schema.Version)
+	}
+	columns := map[string]bool{}
+	for _, word := range strings.FieldsFunc(GetHatchetInitStmt("test"), func(r rune) bool {
+		return r != '_' && (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}) {
+		columns[word] = true
+	}
+	for table, fields := range schema.Tables {
+		for _, field := range fields {
+			if !columns[field.Column] {
+				t.Fatal("expected", field.Column, "in", table, "but got", "none")
+			}
+		}
+	}
+}
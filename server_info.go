@@ -0,0 +1,167 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * server_info.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// topologies of a server from its startup options
+const (
+	TOPOLOGY_CONFIG     = "config server"
+	TOPOLOGY_MONGOS     = "mongos"
+	TOPOLOGY_REPLICA    = "replica set"
+	TOPOLOGY_SHARD      = "shard"
+	TOPOLOGY_STANDALONE = "standalone"
+)
+
+// wiredTigerCacheRegex matches the cache size of a wiredtiger_open config
+var wiredTigerCacheRegex = regexp.MustCompile(`cache_size=(\w+)`)
+
+// ServerInfo keeps the startup banner of the last boot in a log, i.e. the
+// host, version, git hash, OS, and storage and command line options
+type ServerInfo struct {
+	keys   []string
+	values map[string]string
+}
+
+// NewServerInfo returns ServerInfo
+func NewServerInfo() *ServerInfo {
+	return &ServerInfo{keys: []string{}, values: map[string]string{}}
+}
+
+// Add records banner logs of the CONTROL and STORAGE components, a startup
+// line resets values of a previous boot
+func (ptr *ServerInfo) Add(doc *Logv2Info) {
+	if doc.Component != "CONTROL" && doc.Component != "STORAGE" {
+		return
+	}
+	attr := doc.Attr.Map()
+	switch doc.Msg {
+	case "MongoDB starting":
+		ptr.keys, ptr.values = []string{}, map[string]string{}
+		for _, key := range []string{"host", "pid", "port", "dbPath", "architecture"} {
+			ptr.set(key, attr[key])
+		}
+	case "Build Info":
+		buildInfo, ok := attr["buildInfo"].(bson.D)
+		if !ok {
+			return
+		}
+		info := buildInfo.Map()
+		for _, key := range []string{"version", "gitVersion", "openSSLVersion", "modules", "allocator"} {
+			ptr.set(key, info[key])
+		}
+		if env, ok := info["environment"].(bson.D); ok {
+			for _, key := range []string{"distmod", "distarch"} {
+				ptr.set(key, env.Map()[key])
+			}
+		}
+	case "Operating System":
+		if os, ok := attr["os"].(bson.D); ok {
+			for _, key := range []string{"name", "version"} {
+				ptr.set("os."+key, os.Map()[key])
+			}
+		}
+	case "Opening WiredTiger":
+		if config, ok := attr["config"].(string); ok {
+			if matches := wiredTigerCacheRegex.FindStringSubmatch(config); matches != nil {
+				ptr.set("wiredTiger.cacheSize", matches[1])
+			}
+		}
+	case "Options set by command line":
+		if options, ok := attr["options"].(bson.D); ok {
+			ptr.setOptions("options", options)
+		}
+	}
+}
+
+// GetTopology returns the topology of the server from startup options,
+// empty if options were not logged
+func (ptr *ServerInfo) GetTopology() string {
+	if ptr.values["options.sharding.configDB"] != "" {
+		return TOPOLOGY_MONGOS
+	} else if role := ptr.values["options.sharding.clusterRole"]; role == "configsvr" {
+		return TOPOLOGY_CONFIG
+	} else if role == "shardsvr" {
+		return TOPOLOGY_SHARD
+	} else if ptr.values["options.replication.replSetName"] != "" || ptr.values["options.replication.replSet"] != "" {
+		return TOPOLOGY_REPLICA
+	}
+	for _, key := range ptr.keys {
+		if strings.HasPrefix(key, "options.") {
+			return TOPOLOGY_STANDALONE
+		}
+	}
+	return ""
+}
+
+// GetAuditData returns banner values in the logged order as "name=value",
+// valued by the order, led by the topology
+func (ptr *ServerInfo) GetAuditData() []NameValue {
+	data := []NameValue{}
+	if topology := ptr.GetTopology(); topology != "" {
+		data = append(data, NameValue{"topology=" + topology, 0})
+	}
+	for _, key := range ptr.keys {
+		data = append(data, NameValue{key + "=" + ptr.values[key], len(data)})
+	}
+	return data
+}
+
+// GetServerInfoAuditData returns names and values of audit data of the
+// server info sorted by the order
+func GetServerInfoAuditData(rows []NameValue) []NameValues {
+	docs := []NameValues{}
+	for _, row := range rows {
+		toks := strings.SplitN(row.Name, "=", 2)
+		if len(toks) != 2 {
+			continue
+		}
+		docs = append(docs, NameValues{toks[0], []interface{}{toks[1]}})
+	}
+	return docs
+}
+
+// set records a logged value, the first of a key in a boot is kept
+func (ptr *ServerInfo) set(key string, value interface{}) {
+	if value == nil {
+		return
+	}
+	if _, ok := ptr.values[key]; ok {
+		return
+	}
+	var str string
+	if arr, ok := value.(bson.A); ok {
+		toks := []string{}
+		for _, v := range arr {
+			toks = append(toks, fmt.Sprintf("%v", v))
+		}
+		str = strings.Join(toks, ",")
+	} else {
+		str = getConfigValue(value)
+	}
+	if str == "" {
+		return
+	}
+	ptr.keys = append(ptr.keys, key)
+	ptr.values[key] = str
+}
+
+// setOptions records options flattened to dotted names
+func (ptr *ServerInfo) setOptions(prefix string, options bson.D) {
+	for _, elem := range options {
+		if doc, ok := elem.Value.(bson.D); ok {
+			ptr.setOptions(prefix+"."+elem.Key, doc)
+		} else {
+			ptr.set(prefix+"."+elem.Key, elem.Value)
+		}
+	}
+}
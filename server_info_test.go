@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * server_info_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestServerInfo(t *testing.T) {
+	lines := []string{
+		`{"t":{"$date":"2023-06-01T09:00:00.000+00:00"},"s":"I","c":"CONTROL","id":4615611,"ctx":"initandlisten","msg":"MongoDB starting","attr":{"pid":1000,"port":27017,"dbPath":"/data/old","host":"db0"}}`,
+		`{"t":{"$date":"2023-06-01T10:00:00.000+00:00"},"s":"I","c":"CONTROL","id":4615611,"ctx":"initandlisten","msg":"MongoDB starting","attr":{"pid":4242,"port":27018,"dbPath":"/data/rs0","host":"db1"}}`,
+		`{"t":{"$date":"2023-06-01T10:00:00.001+00:00"},"s":"I","c":"CONTROL","id":23403,"ctx":"initandlisten","msg":"Build Info","attr":{"buildInfo":{"version":"6.0.5","gitVersion":"c9a99c12","modules":["enterprise"],"environment":{"distmod":"rhel80"}}}}`,
+		`{"t":{"$date":"2023-06-01T10:00:00.002+00:00"},"s":"I","c":"CONTROL","id":51765,"ctx":"initandlisten","msg":"Operating System","attr":{"os":{"name":"Ubuntu","version":"20.04"}}}`,
+		`{"t":{"$date":"2023-06-01T10:00:00.003+00:00"},"s":"I","c":"CONTROL","id":21951,"ctx":"initandlisten","msg":"Options set by command line","attr":{"options":{"replication":{"replSetName":"rs0"},"sharding":{"clusterRole":"shardsvr"},"storage":{"dbPath":"/data/rs0"}}}}`,
+		`{"t":{"$date":"2023-06-01T10:00:00.004+00:00"},"s":"I","c":"STORAGE","id":22315,"ctx":"initandlisten","msg":"Opening WiredTiger","attr":{"config":"create,cache_size=2048M,session_max=33000"}}`,
+	}
+	server := NewServerInfo()
+	for _, line := range lines {
+		var doc Logv2Info
+		if err := UnmarshalLogv2([]byte(line), nil, &doc); err != nil {
+			t.Fatal(err)
+		}
+		server.Add(&doc)
+	}
+	if server.GetTopology() != TOPOLOGY_SHARD {
+		t.Fatal("expected", TOPOLOGY_SHARD, "but got", server.GetTopology())
+	}
+	docs := GetServerInfoAuditData(server.GetAuditData())
+	values := map[string]interface{}{}
+	for _, doc := range docs {
+		values[doc.Name] = doc.Values[0]
+	}
+	expected := map[string]string{"topology": TOPOLOGY_SHARD, "host": "db1", "pid": "4242", "version": "6.0.5",
+		"gitVersion": "c9a99c12", "modules": "enterprise", "os.name": "Ubuntu",
+		"options.storage.dbPath": "/data/rs0", "wiredTiger.cacheSize": "2048M"}
+	for key, value := range expected {
+		if values[key] != value {
+			t.Fatal("expected", value, "of", key, "but got", values[key])
+		}
+	}
+	if docs[0].Name != "topology" || docs[1].Name != "host" {
+		t.Fatal("expected", "topology and host first", "but got", docs)
+	}
+}
+
+func TestServerInfoLegacy(t *testing.T) {
+	server := NewServerInfo()
+	for _, line := range []string{
+		`2020-03-01T10:00:00.000+0000 I  CONTROL  [initandlisten] MongoDB starting : pid=1234 port=27017 dbpath=/data/db 64-bit host=db1`,
+		`2020-03-01T10:00:00.001+0000 I  CONTROL  [initandlisten] db version v4.2.3`,
+		`2020-03-01T10:00:00.002+0000 I  CONTROL  [initandlisten] options: { net: { port: 27017 }, storage: { dbPath: "/data/db" } }`,
+	} {
+		var doc Logv2Info
+		if err := ParseLegacyLog(line, nil, &doc); err != nil {
+			t.Fatal(err)
+		}
+		server.Add(&doc)
+	}
+	if server.GetTopology() != TOPOLOGY_STANDALONE {
+		t.Fatal("expected", TOPOLOGY_STANDALONE, "but got", server.GetTopology())
+	}
+	if server.values["version"] != "4.2.3" || server.values["options.storage.dbPath"] != "/data/db" {
+		t.Fatal("expected", "4.2.3 and /data/db", "but got", server.values)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * severity.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SEVERITY_LEVELS are severities of warnings, errors, and fatal messages
+var SEVERITY_LEVELS = []string{"W", "E", "F"}
+
+// SEVERITY_SERIES are series of GetSeverityCounts
+var SEVERITY_SERIES = []string{"warnings", "errors", "fatals"}
+
+// SeverityStat stores recurring messages of a severity, a component, and a log
+// id, or of a message if logged in legacy format
+type SeverityStat struct {
+	Component string `json:"component" bson:"component"`
+	Count     int    `json:"count" bson:"count"`
+	First     string `json:"first" bson:"first"`
+	Last      string `json:"last" bson:"last"`
+	LogID     int    `json:"id" bson:"id"`
+	Msg       string `json:"msg" bson:"msg"`
+	Severity  string `json:"severity" bson:"severity"`
+}
+
+// IsSeverityLevel returns true if a severity is W, E, or F
+func IsSeverityLevel(severity string) bool {
+	for _, level := range SEVERITY_LEVELS {
+		if severity == level {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterSeverityStats returns the top N recurring messages of a severity, or of
+// all W, E, and F if severity is empty, the most first
+func FilterSeverityStats(stats []SeverityStat, severity string, topN int) ([]SeverityStat, error) {
+	if severity != "" && !IsSeverityLevel(severity) {
+		return nil, fmt.Errorf("invalid severity %v, expected one of %v", severity, SEVERITY_LEVELS)
+	}
+	docs := []SeverityStat{}
+	for _, stat := range stats {
+		if severity == "" || stat.Severity == severity {
+			docs = append(docs, stat)
+		}
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Count > docs[j].Count
+	})
+	if topN > 0 && len(docs) > topN {
+		docs = docs[:topN]
+	}
+	return docs, nil
+}
+
+// GetSeverityTotals returns lines and distinct messages of W, E, and F
+func GetSeverityTotals(stats []SeverityStat) []NameValues {
+	docs := []NameValues{}
+	for _, level := range SEVERITY_LEVELS {
+		count, messages := 0, 0
+		for _, stat := range stats {
+			if stat.Severity == level {
+				count += stat.Count
+				messages++
+			}
+		}
+		docs = append(docs, NameValues{level, []interface{}{count, messages}})
+	}
+	return docs
+}
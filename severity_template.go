@@ -0,0 +1,59 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * severity_template.go
+ */
+
+package hatchet
+
+import (
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetSeverityTemplate returns HTML
+func GetSeverityTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+	{{$hatchet := .Hatchet}}
+	<table width='100%'>
+		<caption>Severity Breakdown</caption>
+		<tr><th>severity</th><th>lines</th><th>distinct messages</th></tr>
+	{{range $t := .Totals}}
+		<tr><td><a href='/hatchets/{{$hatchet}}/stats/severity?severity={{$t.Name}}'>{{$t.Name}}</a></td>
+			<td align='right'><a href='/hatchets/{{$hatchet}}/logs/all?severity={{$t.Name}}'>{{numPrinter (index $t.Values 0)}}</a></td>
+			<td align='right'>{{numPrinter (index $t.Values 1)}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+{{if .Stats}}
+	<table width='100%'>
+		<caption>Top Recurring {{if .Severity}}{{.Severity}} {{end}}Messages
+			(<a href='/hatchets/{{$hatchet}}/charts/severity?type=counts'>chart</a>)</caption>
+		<tr><th>severity</th><th>component</th><th>id</th><th>msg</th><th>count</th><th>first</th><th>last</th></tr>
+	{{range $s := .Stats}}
+		<tr><td>{{if eq $s.Severity "W"}}{{$s.Severity}}{{else}}<span style='color: red;'>{{$s.Severity}}</span>{{end}}</td>
+			<td><a href='/hatchets/{{$hatchet}}/logs/all?severity={{$s.Severity}}&component={{$s.Component}}'>{{$s.Component}}</a></td>
+			<td align='right'>{{if $s.LogID}}{{$s.LogID}}{{else}}-{{end}}</td>
+			<td>{{$s.Msg}}</td>
+			<td align='right'>{{numPrinter $s.Count}}</td>
+			<td>{{$s.First}}</td><td>{{$s.Last}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<div>Messages are grouped by severity, component, and log id, or by message if logged in legacy format.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no warnings or errors found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"numPrinter": func(n interface{}) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * severity_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestFilterSeverityStats(t *testing.T) {
+	stats := []SeverityStat{
+		{Severity: "W", Component: "NETWORK", LogID: 22944, Count: 3},
+		{Severity: "E", Component: "REPL", LogID: 21799, Count: 10},
+		{Severity: "W", Component: "QUERY", LogID: 20526, Count: 7},
+		{Severity: "F", Component: "STORAGE", LogID: 28595, Count: 1},
+	}
+	docs, err := FilterSeverityStats(stats, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].LogID != 21799 || docs[1].LogID != 20526 {
+		t.Fatal("expected", "21799 and 20526", "but got", docs)
+	}
+	if docs, err = FilterSeverityStats(stats, "W", 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].Count != 7 {
+		t.Fatal("expected", "2 warnings", "but got", docs)
+	}
+	if _, err = FilterSeverityStats(stats, "I", 0); err == nil {
+		t.Fatal("expected", "error", "but got", err)
+	}
+}
+
+func TestGetSeverityTotals(t *testing.T) {
+	stats := []SeverityStat{
+		{Severity: "W", LogID: 22944, Count: 3},
+		{Severity: "W", LogID: 20526, Count: 7},
+		{Severity: "E", LogID: 21799, Count: 10},
+	}
+	docs := GetSeverityTotals(stats)
+	if len(docs) != len(SEVERITY_LEVELS) || docs[0].Name != "W" || docs[2].Name != "F" {
+		t.Fatal("expected", SEVERITY_LEVELS, "but got", docs)
+	}
+	if docs[0].Values[0] != 10 || docs[0].Values[1] != 2 || docs[2].Values[0] != 0 {
+		t.Fatal("expected", "10 warnings of 2 messages", "but got", docs)
+	}
+}
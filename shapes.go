@@ -0,0 +1,43 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * shapes.go
+ */
+
+package hatchet
+
+const (
+	MAX_SHAPES  = 10000
+	SHAPE_OTHER = "(other)"
+)
+
+// ShapeGuard caps distinct shapes of op, namespace, and query pattern, ops
+// of new shapes beyond the cap are rolled into the other bucket
+type ShapeGuard struct {
+	Max      int
+	Overflow int // ops rolled into the other bucket
+	shapes   map[string]bool
+}
+
+// NewShapeGuard returns ShapeGuard, 0 for unlimited shapes
+func NewShapeGuard(max int) *ShapeGuard {
+	return &ShapeGuard{Max: max, shapes: map[string]bool{}}
+}
+
+// Add tracks the shape of an op and returns false if the op is rolled into
+// the other bucket
+func (ptr *ShapeGuard) Add(stat *OpStat) bool {
+	if stat == nil || stat.Op == "" || ptr.Max <= 0 {
+		return true
+	}
+	key := stat.Op + "\t" + stat.Namespace + "\t" + stat.QueryPattern + "\t" + stat.SortPattern
+	if ptr.shapes[key] {
+		return true
+	}
+	if len(ptr.shapes) < ptr.Max {
+		ptr.shapes[key] = true
+		return true
+	}
+	stat.QueryPattern = SHAPE_OTHER
+	ptr.Overflow++
+	return false
+}
@@ -0,0 +1,26 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * shapes_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestShapeGuard(t *testing.T) {
+	guard := NewShapeGuard(2)
+	for _, filter := range []string{"{ a:1 }", "{ b:1 }", "{ a:1 }"} {
+		if !guard.Add(&OpStat{Op: "find", Namespace: "db.c", QueryPattern: filter}) {
+			t.Fatal("expected", true, "but got", false)
+		}
+	}
+	stat := &OpStat{Op: "find", Namespace: "db.c", QueryPattern: "{ c:1 }"}
+	if guard.Add(stat) || stat.QueryPattern != SHAPE_OTHER || guard.Overflow != 1 {
+		t.Fatal("expected", SHAPE_OTHER, 1, "but got", stat.QueryPattern, guard.Overflow)
+	}
+	if !NewShapeGuard(0).Add(stat) {
+		t.Fatal("expected", true, "but got", false)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sharding.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	TARGETED       = "targeted"
+	MULTI_SHARD    = "multi-shard"
+	SCATTER_GATHER = "scatter-gather"
+)
+
+// ShardingStat stores shard targeting of an op shape routed by a mongos
+type ShardingStat struct {
+	AllShards    int      `json:"all_shards" bson:"all_shards"` // executions sent to all shards
+	AvgShards    float64  `json:"avg_shards" bson:"avg_shards"`
+	Count        int      `json:"count" bson:"count"`
+	MaxShards    int      `json:"max_shards" bson:"max_shards"`
+	MinShards    int      `json:"min_shards" bson:"min_shards"`
+	Namespace    string   `json:"ns" bson:"ns"`
+	Op           string   `json:"op" bson:"op"`
+	QueryPattern string   `json:"query_pattern" bson:"query_pattern"`
+	Shards       []string `json:"shards" bson:"shards"`             // shard names logged
+	TotalShards  int      `json:"total_shards" bson:"total_shards"` // max nShards of the log
+}
+
+// GetShardTargets returns nShards and the comma separated shard names of a
+// slow op logged by a mongos, names are from the shards or shard attributes,
+// false if not from a mongos
+func GetShardTargets(doc *Logv2Info) (int, string, bool) {
+	attr := doc.Attr.Map()
+	n, ok := attr["nShards"]
+	if !ok {
+		return 0, "", false
+	}
+	names := []string{}
+	switch shards := attr["shards"].(type) {
+	case bson.A:
+		for _, shard := range shards {
+			if name, ok := shard.(string); ok {
+				names = append(names, name)
+			}
+		}
+	case bson.D:
+		for _, shard := range shards {
+			names = append(names, shard.Key)
+		}
+	}
+	if name, ok := attr["shard"].(string); ok {
+		names = append(names, name)
+	}
+	return ToInt(n), strings.Join(names, ","), true
+}
+
+// GetTargeting returns scatter-gather if the shape was sent to all shards,
+// targeted if to one, and multi-shard otherwise
+func (ptr *ShardingStat) GetTargeting() string {
+	if ptr.MaxShards <= 1 {
+		return TARGETED
+	} else if ptr.MaxShards >= ptr.TotalShards {
+		return SCATTER_GATHER
+	}
+	return MULTI_SHARD
+}
+
+// GetAllShardsPercent returns percent of executions sent to all shards
+func (ptr *ShardingStat) GetAllShardsPercent() string {
+	if ptr.Count == 0 {
+		return "0.0"
+	}
+	return fmt.Sprintf("%.1f", float64(100*ptr.AllShards)/float64(ptr.Count))
+}
+
+// SetShards sets distinct sorted shard names of comma separated names
+func (ptr *ShardingStat) SetShards(values []string) {
+	names := map[string]bool{}
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			if name != "" {
+				names[name] = true
+			}
+		}
+	}
+	ptr.Shards = []string{}
+	for name := range names {
+		ptr.Shards = append(ptr.Shards, name)
+	}
+	sort.Strings(ptr.Shards)
+}
+
+// SortShardingStats sets the shards of the cluster, the max nShards seen, and
+// sorts shapes by executions sent to all shards
+func SortShardingStats(ops []ShardingStat) {
+	total := 0
+	for _, op := range ops {
+		if op.MaxShards > total {
+			total = op.MaxShards
+		}
+	}
+	for i := range ops {
+		ops[i].TotalShards = total
+	}
+	sort.SliceStable(ops, func(i, j int) bool {
+		if ops[i].AllShards != ops[j].AllShards {
+			return ops[i].AllShards > ops[j].AllShards
+		} else if ops[i].MaxShards != ops[j].MaxShards {
+			return ops[i].MaxShards > ops[j].MaxShards
+		}
+		return ops[i].Count > ops[j].Count
+	})
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sharding_template.go
+ */
+
+package hatchet
+
+import (
+	"html/template"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetShardingTemplate returns HTML
+func GetShardingTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Sharding}}
+	<table width='100%'>
+		<caption>Shard Targeting by Shape ({{.Total}} shards)</caption>
+		<tr><th>op</th><th>namespace</th><th>count</th><th>min shards</th><th>avg shards</th><th>max shards</th>
+			<th>all shards %</th><th>targeting</th><th>shards</th><th>query pattern</th></tr>
+	{{range $op := .Sharding}}
+		<tr><td>{{$op.Op}}</td><td>{{$op.Namespace}}</td><td align='right'>{{numPrinter $op.Count}}</td>
+			<td align='right'>{{$op.MinShards}}</td>
+			<td align='right'>{{printf "%.1f" $op.AvgShards}}</td>
+			<td align='right'>{{$op.MaxShards}}</td>
+			<td align='right'>{{$op.GetAllShardsPercent}}</td>
+			{{if eq $op.GetTargeting "scatter-gather"}}
+			<td style='color: red;'>{{$op.GetTargeting}}</td>
+			{{else}}
+			<td>{{$op.GetTargeting}}</td>
+			{{end}}
+			<td>{{join $op.Shards}}</td>
+			<td class='break'>{{$op.QueryPattern}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<div>Shapes in red are sent to all shards, scatter-gather queries whose filters don't include the shard key;
+		each shard runs them and the mongos merges the results, so they don't scale with more shards.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no nShards found, not a mongos log</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"join": func(names []string) string {
+			return strings.Join(names, ", ")
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sharding_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetShardTargets(t *testing.T) {
+	str := `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","nShards":2,"shards":["shard01","shard02"],"durationMillis":200}}`
+	var doc Logv2Info
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if n, names, ok := GetShardTargets(&doc); !ok || n != 2 || names != "shard01,shard02" {
+		t.Fatal("expected", "2 shard01,shard02", "but got", n, names, ok)
+	}
+	doc = Logv2Info{}
+	if _, _, ok := GetShardTargets(&doc); ok {
+		t.Fatal("expected", "not from a mongos", "but got", ok)
+	}
+}
+
+func TestShardingStat(t *testing.T) {
+	ops := []ShardingStat{
+		{Op: "find", Namespace: "shop.orders", QueryPattern: "{ customerId:1 }", Count: 20, MinShards: 1, MaxShards: 1},
+		{Op: "find", Namespace: "shop.orders", QueryPattern: "{ region:1 }", Count: 10, MinShards: 2, MaxShards: 2},
+		{Op: "find", Namespace: "shop.orders", QueryPattern: "{ status:1 }", Count: 10, MinShards: 2, MaxShards: 3, AllShards: 5},
+	}
+	SortShardingStats(ops)
+	for i, expected := range []string{SCATTER_GATHER, MULTI_SHARD, TARGETED} {
+		if ops[i].TotalShards != 3 {
+			t.Fatal("expected", 3, "but got", ops[i].TotalShards)
+		}
+		if ops[i].GetTargeting() != expected {
+			t.Fatal("expected", expected, "of", ops[i].QueryPattern, "but got", ops[i].GetTargeting())
+		}
+	}
+	if percent := ops[0].GetAllShardsPercent(); percent != "50.0" {
+		t.Fatal("expected", "50.0", "but got", percent)
+	}
+	ops[0].SetShards([]string{"shard02,shard01", "shard01", ""})
+	if len(ops[0].Shards) != 2 || ops[0].Shards[0] != "shard01" {
+		t.Fatal("expected", "[shard01 shard02]", "but got", ops[0].Shards)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * slow_thresholds.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const SLOW_MS = 100
+
+// SlowThresholds are slow op thresholds in milliseconds by namespace
+// patterns, the first matched pattern wins and Default applies otherwise,
+// Spec is the {namespace regex}={ms} list parsed
+type SlowThresholds struct {
+	Default  int
+	Spec     string
+	patterns []*regexp.Regexp
+	millis   []int
+}
+
+// ParseSlowThresholds parses comma separated {namespace regex}={ms}, e.g.
+// analytics\..*=5000,app.sessions=20, patterns match whole namespaces
+func ParseSlowThresholds(str string, defaultMilli int) (*SlowThresholds, error) {
+	thresholds := &SlowThresholds{Default: defaultMilli, Spec: strings.TrimSpace(str)}
+	if thresholds.Spec == "" {
+		return thresholds, nil
+	}
+	for _, tok := range strings.Split(str, ",") {
+		n := strings.LastIndex(tok, "=")
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid slow threshold %q, use {namespace regex}={ms}", tok)
+		}
+		re, err := regexp.Compile("^(?:" + strings.TrimSpace(tok[:n]) + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace regex %q: %v", tok[:n], err)
+		}
+		milli := ToInt(strings.TrimSpace(tok[n+1:]))
+		if milli <= 0 {
+			return nil, fmt.Errorf("invalid slow threshold %q, ms must be a positive number", tok)
+		}
+		thresholds.patterns = append(thresholds.patterns, re)
+		thresholds.millis = append(thresholds.millis, milli)
+	}
+	return thresholds, nil
+}
+
+// GetHatchetSlowThresholds returns slow thresholds a hatchet was ingested
+// with, or those of the command line if not saved, i.e. of hatchets ingested
+// before thresholds were saved
+func GetHatchetSlowThresholds(info HatchetInfo) *SlowThresholds {
+	if info.SlowMilli <= 0 {
+		return GetLogv2().GetSlowThresholds()
+	}
+	thresholds, err := ParseSlowThresholds(info.SlowNS, info.SlowMilli)
+	if err != nil {
+		return GetLogv2().GetSlowThresholds()
+	}
+	return thresholds
+}
+
+// Get returns the slow threshold of a namespace
+func (ptr *SlowThresholds) Get(ns string) int {
+	for i, re := range ptr.patterns {
+		if re.MatchString(ns) {
+			return ptr.millis[i]
+		}
+	}
+	return ptr.Default
+}
+
+// IsSlow returns true if the avg ms of an op exceeds its namespace threshold
+func (ptr *SlowThresholds) IsSlow(op OpStat) bool {
+	return op.AvgMilli > float64(ptr.Get(op.Namespace))
+}
+
+// FilterSlowOps returns ops slower than their namespace thresholds
+func (ptr *SlowThresholds) FilterSlowOps(ops []OpStat) []OpStat {
+	filtered := []OpStat{}
+	for _, op := range ops {
+		if ptr.IsSlow(op) {
+			filtered = append(filtered, op)
+		}
+	}
+	return filtered
+}
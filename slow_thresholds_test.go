@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * slow_thresholds_test.go
+ */
+
+package hatchet
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestSlowThresholds(t *testing.T) {
+	thresholds, err := ParseSlowThresholds(`analytics\..*=5000, app.sessions=20`, SLOW_MS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ms := thresholds.Get("analytics.events"); ms != 5000 {
+		t.Fatal("expected", 5000, "but got", ms)
+	}
+	if ms := thresholds.Get("app.sessions_archive"); ms != SLOW_MS {
+		t.Fatal("expected", SLOW_MS, "but got", ms)
+	}
+	ops := []OpStat{{Namespace: "analytics.events", AvgMilli: 1000}, {Namespace: "app.sessions", AvgMilli: 50},
+		{Namespace: "app.users", AvgMilli: 150}}
+	if slow := thresholds.FilterSlowOps(ops); len(slow) != 2 || slow[0].Namespace != "app.sessions" {
+		t.Fatal("expected", "app.sessions and app.users", "but got", slow)
+	}
+	for _, str := range []string{"app.sessions", "app.sessions=0", "app.[=20"} {
+		if _, err = ParseSlowThresholds(str, SLOW_MS); err == nil {
+			t.Fatal("expected", "error", "but got", nil)
+		}
+	}
+}
+
+func TestGetHatchetSlowThresholds(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "thresholds.db")
+	db, err := sql.Open("sqlite3", dbfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbase := &SQLite3DB{db: db, dbfile: dbfile, hatchetName: "mongod_1"}
+	defer dbase.Close()
+	if _, err = db.Exec(`CREATE TABLE hatchet (name text not null primary key, version text, module text, arch text,
+		os text, start text, end text);` + GetMigrationsInitStmt() + `
+		INSERT INTO hatchet_migrations (name, version, description, applied) VALUES ('mongod_1', 26, '', '');`); err != nil {
+		t.Fatal(err)
+	}
+	if migrations, err := dbase.Migrate(); err != nil || len(migrations) != 1 {
+		t.Fatal("expected", "slow thresholds migrated", "but got", migrations, err)
+	}
+	if err = dbase.UpdateHatchetInfo(HatchetInfo{Version: "6.0.1", SlowMilli: 200, SlowNS: `analytics\..*=5000`}); err != nil {
+		t.Fatal(err)
+	}
+	thresholds := GetHatchetSlowThresholds(dbase.GetHatchetInfo())
+	if thresholds.Default != 200 || thresholds.Get("analytics.events") != 5000 {
+		t.Fatal("expected", 200, 5000, "but got", thresholds.Default, thresholds.Get("analytics.events"))
+	}
+
+	// hatchets ingested before thresholds were saved
+	saved := instance
+	defer func() { instance = saved }()
+	instance = &Logv2{slowThresholds: &SlowThresholds{Default: 300}}
+	if _, err = db.Exec(`INSERT INTO hatchet (name, version) VALUES ('mongod_2', '5.0.1');`); err != nil {
+		t.Fatal(err)
+	}
+	dbase.hatchetName = "mongod_2"
+	if thresholds = GetHatchetSlowThresholds(dbase.GetHatchetInfo()); thresholds.Default != 300 {
+		t.Fatal("expected", 300, "but got", thresholds.Default)
+	}
+}
@@ -5,11 +5,13 @@ package hatchet
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
-	"github.com/simagix/gox"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 const (
@@ -32,7 +34,7 @@ const (
 // AnalyzeLog analyzes slow op log
 func AnalyzeLog(str string) (*OpStat, error) {
 	doc := Logv2Info{}
-	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+	if err := UnmarshalLogv2([]byte(str), GetLogv2().location, &doc); err != nil {
 		return nil, err
 	}
 	return AnalyzeSlowOp(&doc)
@@ -112,9 +114,7 @@ func AnalyzeSlowOp(doc *Logv2Info) (*OpStat, error) {
 		}
 
 		if query != nil {
-			walker := gox.NewMapWalker(cb)
-			doc := walker.Walk(query.(map[string]interface{}))
-			if buf, err := json.Marshal(doc); err == nil {
+			if buf, err := json.Marshal(NormalizeQuery(query)); err == nil {
 				stat.QueryPattern = string(buf)
 			} else {
 				stat.QueryPattern = "{}"
@@ -132,31 +132,21 @@ func AnalyzeSlowOp(doc *Logv2Info) (*OpStat, error) {
 			stage = v
 			break
 		}
-		fmap := stage.(map[string]interface{})
-		if !isRegex(fmap) {
-			walker := gox.NewMapWalker(cb)
-			doc := walker.Walk(fmap)
-			if buf, err := json.Marshal(doc); err == nil {
+		if buf, err := json.Marshal(NormalizeQuery(stage)); err == nil {
+			stat.QueryPattern = string(buf)
+		} else {
+			stat.QueryPattern = "{}"
+		}
+		if strings.Contains(stat.QueryPattern, "$changeStream") {
+			if len(pipeline) > 1 {
+				buf, _ := json.Marshal(NormalizeQuery(pipeline[1]))
 				stat.QueryPattern = string(buf)
 			} else {
 				stat.QueryPattern = "{}"
 			}
-			if strings.Contains(stat.QueryPattern, "$changeStream") {
-				if len(pipeline) > 1 {
-					buf, _ := json.Marshal(pipeline[1])
-					stat.QueryPattern = string(buf)
-				} else {
-					stat.QueryPattern = "{}"
-				}
-			} else if !strings.Contains(stat.QueryPattern, "$match") && !strings.Contains(stat.QueryPattern, "$sort") &&
-				!strings.Contains(stat.QueryPattern, "$facet") && !strings.Contains(stat.QueryPattern, "$indexStats") {
-				stat.QueryPattern = "{}"
-			}
-		} else {
-			buf, _ := json.Marshal(fmap)
-			str := string(buf)
-			re := regexp.MustCompile(`{(.*):{"\$regularExpression":{"options":"(\S+)?","pattern":"(\^)?(\S+)"}}}`)
-			stat.QueryPattern = re.ReplaceAllString(str, "{$1:/$3.../$2}")
+		} else if !strings.Contains(stat.QueryPattern, "$match") && !strings.Contains(stat.QueryPattern, "$sort") &&
+			!strings.Contains(stat.QueryPattern, "$facet") && !strings.Contains(stat.QueryPattern, "$indexStats") {
+			stat.QueryPattern = "{}"
 		}
 	} else {
 		var fmap map[string]interface{}
@@ -169,22 +159,13 @@ func AnalyzeSlowOp(doc *Logv2Info) (*OpStat, error) {
 		} else {
 			stat.QueryPattern = "{}"
 		}
-		if !isRegex(fmap) {
-			walker := gox.NewMapWalker(cb)
-			doc := walker.Walk(fmap)
-			var data []byte
-			if data, err = json.Marshal(doc); err != nil {
-				return stat, err
-			}
-			stat.QueryPattern = string(data)
-			if stat.QueryPattern == `{"":null}` {
-				stat.QueryPattern = "{}"
-			}
-		} else {
-			buf, _ := json.Marshal(fmap)
-			str := string(buf)
-			re := regexp.MustCompile(`{(.*):{"\$regularExpression":{"options":"(\S+)?","pattern":"(\^)?(\S+)"}}}`)
-			stat.QueryPattern = re.ReplaceAllString(str, "{$1:/$3.../$2}")
+		var data []byte
+		if data, err = json.Marshal(NormalizeQuery(fmap)); err != nil {
+			return stat, err
+		}
+		stat.QueryPattern = string(data)
+		if stat.QueryPattern == `{"":null}` {
+			stat.QueryPattern = "{}"
 		}
 	}
 	if stat.Op == "" {
@@ -196,24 +177,187 @@ func AnalyzeSlowOp(doc *Logv2Info) (*OpStat, error) {
 	stat.QueryPattern = re.ReplaceAllString(stat.QueryPattern, `{$1:...}`)
 	re = regexp.MustCompile(`{"\$oid":1}`)
 	stat.QueryPattern = re.ReplaceAllString(stat.QueryPattern, `1`)
-	re = regexp.MustCompile(`("\$n?in"):\[\S+(,\s?\S+)*\]`)
+	re = regexp.MustCompile(`("\$n?in"):\[1\]`)
 	stat.QueryPattern = re.ReplaceAllString(stat.QueryPattern, `$1:[...]`)
+	re = regexp.MustCompile(`"(/\^?\.\.\./[a-z]*)"`)
+	stat.QueryPattern = re.ReplaceAllString(stat.QueryPattern, `$1`)
 	re = regexp.MustCompile(`"(\$?\w+)":`)
 	stat.QueryPattern = re.ReplaceAllString(stat.QueryPattern, ` $1:`)
 	stat.QueryPattern = strings.ReplaceAll(stat.QueryPattern, "}", " }")
 	if isGetMore {
 		stat.Op = cmdGetMore
+		stat.SortPattern = GetSortPattern(doc.Attr.Map()["originatingCommand"])
+	} else {
+		stat.SortPattern = GetSortPattern(doc.Attr.Map()["command"])
 	}
 	return stat, nil
 }
 
-func isRegex(doc map[string]interface{}) bool {
-	if buf, err := json.Marshal(doc); err != nil {
-		return false
-	} else if strings.Contains(string(buf), "$regularExpression") {
-		return true
+// GetSortPattern returns a normalized sort specification of a command, from
+// the sort field or the first $sort stage of a pipeline.  Fields keep their
+// order, directions are 1 or -1, and $meta sorts are { $meta:1 }.
+func GetSortPattern(command interface{}) string {
+	cmd, ok := command.(bson.D)
+	if !ok {
+		return ""
+	}
+	spec, ok := cmd.Map()["sort"].(bson.D)
+	if !ok {
+		pipeline, _ := cmd.Map()["pipeline"].(bson.A)
+		for _, stage := range pipeline {
+			if d, isDoc := stage.(bson.D); isDoc {
+				if spec, ok = d.Map()["$sort"].(bson.D); ok {
+					break
+				}
+			}
+		}
+	}
+	if len(spec) == 0 {
+		return ""
+	}
+	fields := []string{}
+	for _, elem := range spec {
+		direction := "1"
+		if _, isDoc := elem.Value.(bson.D); isDoc {
+			direction = "{ $meta:1 }"
+		} else if ToFloat64(elem.Value) < 0 {
+			direction = "-1"
+		}
+		fields = append(fields, fmt.Sprintf(" %v:%v", elem.Key, direction))
+	}
+	return "{" + strings.Join(fields, ",") + " }"
+}
+
+// FilterOpsByNamespace returns ops whose namespace matches a regex, all ops if
+// the pattern is empty
+func FilterOpsByNamespace(ops []OpStat, pattern string) ([]OpStat, error) {
+	if pattern == "" {
+		return ops, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace regex %q: %v", pattern, err)
+	}
+	filtered := []OpStat{}
+	for _, op := range ops {
+		if re.MatchString(op.Namespace) {
+			filtered = append(filtered, op)
+		}
+	}
+	return filtered, nil
+}
+
+// FilterOpsByOp returns ops of comma separated op names, all ops if empty
+func FilterOpsByOp(ops []OpStat, names string) []OpStat {
+	if names == "" {
+		return ops
 	}
-	return false
+	filtered := []OpStat{}
+	for _, op := range ops {
+		for _, name := range strings.Split(names, ",") {
+			if op.Op == strings.TrimSpace(name) {
+				filtered = append(filtered, op)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// FilterOpsByMinMilli returns ops of average milliseconds at or above a
+// threshold
+func FilterOpsByMinMilli(ops []OpStat, milli float64) []OpStat {
+	if milli <= 0 {
+		return ops
+	}
+	filtered := []OpStat{}
+	for _, op := range ops {
+		if op.AvgMilli >= milli {
+			filtered = append(filtered, op)
+		}
+	}
+	return filtered
+}
+
+// SortSlowOps sorts ops by a field of its JSON name, descending if prefixed
+// with -, e.g. -avg_ms
+func SortSlowOps(ops []OpStat, field string) error {
+	desc := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+	numbers := map[string]func(op *OpStat) float64{
+		"avg_ms":       func(op *OpStat) float64 { return op.AvgMilli },
+		"count":        func(op *OpStat) float64 { return float64(op.Count) },
+		"max_ms":       func(op *OpStat) float64 { return float64(op.MaxMilli) },
+		"p50_ms":       func(op *OpStat) float64 { return float64(op.P50Milli) },
+		"p95_ms":       func(op *OpStat) float64 { return float64(op.P95Milli) },
+		"p99_ms":       func(op *OpStat) float64 { return float64(op.P99Milli) },
+		"total_ms":     func(op *OpStat) float64 { return float64(op.TotalMilli) },
+		"total_reslen": func(op *OpStat) float64 { return float64(op.Reslen) },
+	}
+	texts := map[string]func(op *OpStat) string{
+		"first_seen":    func(op *OpStat) string { return op.FirstSeen },
+		"index":         func(op *OpStat) string { return op.Index },
+		"last_seen":     func(op *OpStat) string { return op.LastSeen },
+		"ns":            func(op *OpStat) string { return op.Namespace },
+		"op":            func(op *OpStat) string { return op.Op },
+		"query_pattern": func(op *OpStat) string { return op.QueryPattern },
+		"sort_pattern":  func(op *OpStat) string { return op.SortPattern },
+	}
+	var less func(i, j int) bool
+	if number, ok := numbers[field]; ok {
+		less = func(i, j int) bool { return number(&ops[i]) < number(&ops[j]) }
+	} else if text, ok := texts[field]; ok {
+		less = func(i, j int) bool { return text(&ops[i]) < text(&ops[j]) }
+	} else {
+		return fmt.Errorf("invalid sort field %q", field)
+	}
+	sort.SliceStable(ops, func(i, j int) bool {
+		if desc {
+			return less(j, i)
+		}
+		return less(i, j)
+	})
+	return nil
+}
+
+// PageSlowOps returns ops of a page of up to limit ops from offset, all ops
+// from offset if limit is 0, and whether more ops follow
+func PageSlowOps(ops []OpStat, offset int, limit int) ([]OpStat, bool) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(ops) {
+		return []OpStat{}, false
+	}
+	if limit <= 0 || offset+limit >= len(ops) {
+		return ops[offset:], false
+	}
+	return ops[offset : offset+limit], true
+}
+
+// GetLoadPercents returns percents of total ms of each op and cumulative
+// percents of ops ranked by total ms, the most first, in the order of ops so
+// that cumulative percents are the same of any sort order
+func GetLoadPercents(ops []OpStat) [][2]float64 {
+	total := 0
+	ranks := make([]int, len(ops))
+	for i, op := range ops {
+		total += op.TotalMilli
+		ranks[i] = i
+	}
+	percents := make([][2]float64, len(ops))
+	if total == 0 {
+		return percents
+	}
+	sort.SliceStable(ranks, func(i, j int) bool {
+		return ops[ranks[i]].TotalMilli > ops[ranks[j]].TotalMilli
+	})
+	cumulative := 0
+	for _, i := range ranks {
+		cumulative += ops[i].TotalMilli
+		percents[i] = [2]float64{100 * float64(ops[i].TotalMilli) / float64(total), 100 * float64(cumulative) / float64(total)}
+	}
+	return percents
 }
 
 func getOp(command map[string]interface{}) string {
@@ -227,6 +371,82 @@ func getOp(command map[string]interface{}) string {
 	return ""
 }
 
-func cb(value interface{}) interface{} {
-	return 1
+// NormalizeQuery returns the shape of a query filter.  Values become 1, $in
+// and $nin arrays collapse to [...], regex literals keep only the anchor and
+// options, and $and, $or, and $nor branches are flattened, deduplicated, and
+// sorted so that equivalent queries have the same shape.
+func NormalizeQuery(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.D:
+		return NormalizeQuery(v.Map())
+	case bson.M:
+		return NormalizeQuery(map[string]interface{}(v))
+	case map[string]interface{}:
+		if pattern, ok := v["$regex"].(string); ok && (len(v) == 1 || (len(v) == 2 && v["$options"] != nil)) {
+			options, _ := v["$options"].(string)
+			return NormalizeQuery(primitive.Regex{Pattern: pattern, Options: options})
+		}
+		doc := map[string]interface{}{}
+		for key, val := range v {
+			switch key {
+			case "$in", "$nin":
+				doc[key] = []interface{}{1}
+			case "$and", "$or", "$nor":
+				doc[key] = normalizeBranches(key, val)
+			default:
+				doc[key] = NormalizeQuery(val)
+			}
+		}
+		return doc
+	case bson.A:
+		return NormalizeQuery([]interface{}(v))
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, elem := range v {
+			arr[i] = NormalizeQuery(elem)
+		}
+		return arr
+	case primitive.Regex:
+		anchor := ""
+		if strings.HasPrefix(v.Pattern, "^") {
+			anchor = "^"
+		}
+		return "/" + anchor + ".../" + v.Options
+	default:
+		return 1
+	}
+}
+
+// normalizeBranches returns the normalized branches of a logical operator,
+// nested branches of the same $and or $or are merged
+func normalizeBranches(op string, value interface{}) interface{} {
+	arr, ok := NormalizeQuery(value).([]interface{})
+	if !ok {
+		return NormalizeQuery(value)
+	}
+	branches := []interface{}{}
+	for _, elem := range arr {
+		if doc, ok := elem.(map[string]interface{}); ok && len(doc) == 1 && op != "$nor" {
+			if nested, ok := doc[op].([]interface{}); ok {
+				branches = append(branches, nested...)
+				continue
+			}
+		}
+		branches = append(branches, elem)
+	}
+	shapes := map[string]interface{}{}
+	keys := []string{}
+	for _, elem := range branches {
+		buf, _ := json.Marshal(elem)
+		if _, ok := shapes[string(buf)]; !ok {
+			shapes[string(buf)] = elem
+			keys = append(keys, string(buf))
+		}
+	}
+	sort.Strings(keys)
+	unique := make([]interface{}, len(keys))
+	for i, key := range keys {
+		unique[i] = shapes[key]
+	}
+	return unique
 }
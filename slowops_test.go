@@ -130,3 +130,117 @@ func TestAnalyzeSlowOpErrMsg(t *testing.T) {
 	}
 	t.Log(gox.Stringify(stat, "", "  "))
 }
+
+func TestFilterOpsByNamespace(t *testing.T) {
+	ops := []OpStat{{Namespace: "shop.orders"}, {Namespace: "shop.orders_archive"}, {Namespace: "shop.products"}}
+	filtered, err := FilterOpsByNamespace(ops, `^shop\.orders$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 {
+		t.Fatal("expected", 1, "but got", len(filtered))
+	}
+	if filtered, _ = FilterOpsByNamespace(ops, ""); len(filtered) != 3 {
+		t.Fatal("expected", 3, "but got", len(filtered))
+	}
+	if _, err = FilterOpsByNamespace(ops, "orders(["); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
+
+func TestGetLoadPercents(t *testing.T) {
+	ops := []OpStat{{TotalMilli: 600}, {TotalMilli: 300}, {TotalMilli: 100}}
+	percents := GetLoadPercents(ops)
+	if percents[0][0] != 60 || percents[1][1] != 90 || percents[2][1] != 100 {
+		t.Fatal("expected", 60, 90, 100, "but got", percents[0][0], percents[1][1], percents[2][1])
+	}
+	// the same cumulative percents of ops sorted by other than total ms
+	ops = []OpStat{{TotalMilli: 100}, {TotalMilli: 600}, {TotalMilli: 300}}
+	percents = GetLoadPercents(ops)
+	if percents[0][1] != 100 || percents[1][1] != 60 || percents[2][1] != 90 {
+		t.Fatal("expected", 100, 60, 90, "but got", percents[0][1], percents[1][1], percents[2][1])
+	}
+	if percents = GetLoadPercents([]OpStat{{}}); percents[0][1] != 0 {
+		t.Fatal("expected", 0, "but got", percents[0][1])
+	}
+}
+
+func TestGetSortPattern(t *testing.T) {
+	str := `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders","filter":{"status":"new"},"sort":{"created":-1,"_id":1,"score":{"$meta":"textScore"}},"$db":"shop"},"planSummary":"COLLSCAN","durationMillis":1200}}`
+	stat, err := AnalyzeLog(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "{ created:-1, _id:1, score:{ $meta:1 } }"
+	if stat.SortPattern != expected {
+		t.Fatal("expected", expected, "but got", stat.SortPattern)
+	}
+
+	str = `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"aggregate":"orders","pipeline":[{"$match":{"status":"new"}},{"$sort":{"total":-1.0}}],"$db":"shop"},"planSummary":"COLLSCAN","durationMillis":1200}}`
+	if stat, err = AnalyzeLog(str); err != nil {
+		t.Fatal(err)
+	}
+	if stat.SortPattern != "{ total:-1 }" {
+		t.Fatal("expected", "{ total:-1 }", "but got", stat.SortPattern)
+	}
+}
+
+func TestAnalyzeLogQueryShapes(t *testing.T) {
+	prefix := `{"t":{"$date":"2021-07-25T09:38:57.078+00:00"},"s":"I", "c":"COMMAND", "id":51803, "ctx":"conn541","msg":"Slow query","attr":{"type":"command","ns":"demo.orders","command":{"find":"orders","filter":`
+	suffix := `},"planSummary":"COLLSCAN","durationMillis":530}}`
+	tests := []struct {
+		filters  []string
+		expected string
+	}{
+		{[]string{`{"status":{"$in":["a","b","c","d","e","f","g"]},"qty":{"$nin":[1]}}`,
+			`{"qty":{"$nin":[3,4]},"status":{"$in":["a"]}}`},
+			`{ qty:{ $nin:[...] }, status:{ $in:[...] } }`},
+		{[]string{`{"name":{"$regex":"^abc","$options":"i"},"qty":5}`,
+			`{"name":{"$regularExpression":{"pattern":"^abc","options":"i"}},"qty":5}`,
+			`{"qty":9,"name":{"$regularExpression":{"pattern":"^xyz","options":"i"}}}`},
+			`{ name:/^.../i, qty:1 }`},
+		{[]string{`{"$or":[{"b":1},{"a":1},{"$or":[{"b":2},{"c":{"$gt":1}}]}]}`,
+			`{"$or":[{"c":{"$gt":9}},{"a":1},{"b":5}]}`},
+			`{ $or:[{ a:1 },{ b:1 },{ c:{ $gt:1 } }] }`},
+		{[]string{`{"$and":[{"$and":[{"a":1},{"b":1}]},{"a":2}]}`, `{"$and":[{"b":1},{"a":1}]}`},
+			`{ $and:[{ a:1 },{ b:1 }] }`},
+	}
+	for _, test := range tests {
+		for _, filter := range test.filters {
+			stat, err := AnalyzeLog(prefix + filter + suffix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stat.QueryPattern != test.expected {
+				t.Fatal("expected", test.expected, "but got", stat.QueryPattern)
+			}
+		}
+	}
+}
+
+func TestSortAndPageSlowOps(t *testing.T) {
+	ops := []OpStat{{Op: "find", Namespace: "b", AvgMilli: 50}, {Op: "update", Namespace: "a", AvgMilli: 300},
+		{Op: "find", Namespace: "c", AvgMilli: 120}}
+	if err := SortSlowOps(ops, "-avg_ms"); err != nil || ops[0].AvgMilli != 300 || ops[2].AvgMilli != 50 {
+		t.Fatal("expected", "300 first and 50 last", "but got", ops, err)
+	}
+	if err := SortSlowOps(ops, "ns"); err != nil || ops[0].Namespace != "a" {
+		t.Fatal("expected", "a first", "but got", ops, err)
+	}
+	if err := SortSlowOps(ops, "filter"); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	if filtered := FilterOpsByMinMilli(FilterOpsByOp(ops, "find, getMore"), 100); len(filtered) != 1 || filtered[0].Namespace != "c" {
+		t.Fatal("expected", "find of c", "but got", filtered)
+	}
+	page, hasMore := PageSlowOps(ops, 1, 1)
+	if len(page) != 1 || page[0].Namespace != "b" || !hasMore {
+		t.Fatal("expected", "b and more", "but got", page, hasMore)
+	}
+	if page, hasMore = PageSlowOps(ops, 1, 5); len(page) != 2 || hasMore {
+		t.Fatal("expected", 2, "but got", len(page), hasMore)
+	}
+	if page, _ = PageSlowOps(ops, 5, 0); len(page) != 0 {
+		t.Fatal("expected", 0, "but got", len(page))
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sort_stages.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+)
+
+// BlockingSortStat stores in-memory blocking sorts and spills to disk of an
+// op shape
+type BlockingSortStat struct {
+	AvgMilli     float64 `json:"avg_ms" bson:"avg_ms"`
+	Count        int     `json:"count" bson:"count"`
+	Namespace    string  `json:"ns" bson:"ns"`
+	Op           string  `json:"op" bson:"op"`
+	QueryPattern string  `json:"query_pattern" bson:"query_pattern"`
+	SortPattern  string  `json:"sort" bson:"sort"`
+	Sorts        int     `json:"sorts" bson:"sorts"`
+	Spills       int     `json:"spills" bson:"spills"`
+}
+
+// GetSortStage returns whether a slow op had a blocking sort stage and
+// whether it used disk, true if either is logged as true or 1
+func GetSortStage(doc *Logv2Info) (bool, bool, bool) {
+	var hasSortStage, usedDisk bool
+	for _, elem := range doc.Attr {
+		switch elem.Key {
+		case "hasSortStage":
+			hasSortStage = isLoggedTrue(elem.Value)
+		case "usedDisk":
+			usedDisk = isLoggedTrue(elem.Value)
+		}
+	}
+	return hasSortStage, usedDisk, hasSortStage || usedDisk
+}
+
+// SortBlockingSortStats sorts shapes by spills, blocking sorts, and then
+// average milliseconds
+func SortBlockingSortStats(stats []BlockingSortStat) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Spills != stats[j].Spills {
+			return stats[i].Spills > stats[j].Spills
+		} else if stats[i].Sorts != stats[j].Sorts {
+			return stats[i].Sorts > stats[j].Sorts
+		}
+		return stats[i].AvgMilli > stats[j].AvgMilli
+	})
+}
+
+// GetBlockingSortAuditData returns shapes of blocking sorts or spills to disk
+// by namespace with the op, query pattern, sort pattern, sorts, spills, and
+// average milliseconds
+func GetBlockingSortAuditData(stats []BlockingSortStat) []NameValues {
+	docs := []NameValues{}
+	for _, stat := range stats {
+		docs = append(docs, NameValues{stat.Namespace, []interface{}{stat.Op, stat.QueryPattern, stat.SortPattern,
+			stat.Sorts, stat.Spills, int(stat.AvgMilli + 0.5)}})
+	}
+	return docs
+}
+
+// getSortStageFlag returns 1 if true, 0 otherwise, as stored
+func getSortStageFlag(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// isLoggedTrue returns true of a logged boolean, true in JSON or 1 in the
+// legacy format
+func isLoggedTrue(value interface{}) bool {
+	if b, ok := value.(bool); ok {
+		return b
+	}
+	return ToInt(value) == 1
+}
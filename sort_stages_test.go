@@ -0,0 +1,48 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sort_stages_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetSortStage(t *testing.T) {
+	doc := Logv2Info{Attr: bson.D{{Key: "hasSortStage", Value: true}, {Key: "nreturned", Value: int32(2)}}}
+	if sorted, spilled, ok := GetSortStage(&doc); !ok || !sorted || spilled {
+		t.Fatal("expected", "sorted", "but got", sorted, spilled, ok)
+	}
+	str := `2020-03-01T10:00:02.000 I  COMMAND  [conn12] command shop.orders command: find { find: "orders", filter: { status: "A" }, sort: { ts: -1 }, $db: "shop" } planSummary: COLLSCAN keysExamined:0 docsExamined:5000 hasSortStage:1 usedDisk:1 numYields:0 nreturned:5 reslen:4512 protocol:op_msg 1532ms`
+	doc = Logv2Info{}
+	if err := ParseLegacyLog(str, time.UTC, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if sorted, spilled, ok := GetSortStage(&doc); !ok || !sorted || !spilled {
+		t.Fatal("expected", "sorted and spilled", "but got", sorted, spilled, ok)
+	}
+	doc = Logv2Info{Attr: bson.D{{Key: "hasSortStage", Value: false}}}
+	if _, _, ok := GetSortStage(&doc); ok {
+		t.Fatal("expected", false, "but got", ok)
+	}
+}
+
+func TestSortBlockingSortStats(t *testing.T) {
+	stats := []BlockingSortStat{
+		{Op: "find", Namespace: "shop.orders", Count: 20, Sorts: 20, AvgMilli: 300},
+		{Op: "aggregate", Namespace: "shop.items", Count: 5, Spills: 5, AvgMilli: 900},
+		{Op: "find", Namespace: "shop.users", Count: 20, Sorts: 20, AvgMilli: 500},
+	}
+	SortBlockingSortStats(stats)
+	if stats[0].Namespace != "shop.items" || stats[1].Namespace != "shop.users" {
+		t.Fatal("expected", "shop.items and shop.users first", "but got", stats)
+	}
+	docs := GetBlockingSortAuditData(stats)
+	if len(docs) != 3 || docs[0].Values[4] != 5 || docs[0].Values[5] != 900 {
+		t.Fatal("expected", "5 spills of 900 ms", "but got", docs[0])
+	}
+}
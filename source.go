@@ -0,0 +1,116 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * source.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// SourceStat stores counts of lines of a source merged into a hatchet
+type SourceStat struct {
+	End      string `json:"end" bson:"end"`
+	Errors   int    `json:"errors" bson:"errors"` // fatal and error lines
+	Lines    int    `json:"lines" bson:"lines"`
+	SlowOps  int    `json:"slow_ops" bson:"slow_ops"`
+	Source   string `json:"source" bson:"source"`
+	Start    string `json:"start" bson:"start"`
+	Warnings int    `json:"warnings" bson:"warnings"`
+}
+
+// mergeState is the state of logs merged into one hatchet, ids of lines
+// continue from lines of logs merged
+type mergeState struct {
+	end         string
+	hatchetName string
+	lines       int
+	start       string
+}
+
+// GetSourceNames returns names tagging lines of logs, the base name without
+// extensions, e.g. host1 of /logs/host1.log.gz, or the path if base names
+// are the same
+func GetSourceNames(lognames []string) []string {
+	names := make([]string, len(lognames))
+	counts := map[string]int{}
+	for i, logname := range lognames {
+		names[i] = getSourceName(logname, true)
+		counts[names[i]]++
+	}
+	for i, logname := range lognames {
+		if counts[names[i]] > 1 {
+			names[i] = getSourceName(logname, false)
+		}
+	}
+	return names
+}
+
+// getSourceName returns the base name or the path of a log without
+// extensions, the host is prefixed if from a remote host
+func getSourceName(logname string, base bool) string {
+	name := logname
+	host := ""
+	if u, err := url.Parse(logname); err == nil && u.Scheme != "" && u.Host != "" {
+		name = strings.TrimPrefix(u.Path, "/")
+		if u.Scheme == "ssh" || u.Scheme == "http" || u.Scheme == "https" {
+			host = u.Hostname()
+		}
+	}
+	name = filepath.ToSlash(filepath.Clean(name))
+	if base {
+		name = filepath.Base(name)
+	}
+	for _, ext := range LOG_COMPRESSED_EXTS {
+		name = strings.TrimSuffix(name, ext)
+	}
+	name = strings.TrimSuffix(name, ".log")
+	if host != "" {
+		name = host + ":" + name
+	}
+	return name
+}
+
+// Merge analyzes logs of nodes into one hatchet named after the first log,
+// each line is tagged with its source
+func (ptr *Logv2) Merge(lognames []string) error {
+	if len(lognames) < 2 {
+		return fmt.Errorf("merging requires 2 or more logs")
+	}
+	ptr.merge = &mergeState{}
+	defer func() {
+		ptr.merge = nil
+		ptr.source = ""
+	}()
+	for i, source := range GetSourceNames(lognames) {
+		ptr.source = source
+		if err := ptr.Analyze(lognames[i]); err != nil {
+			return err
+		}
+	}
+	dbase, err := GetDatabase(ptr.hatchetName)
+	if err != nil {
+		return err
+	}
+	defer dbase.Close()
+	if err = ptr.saveStats(dbase, ptr.merge.start, ptr.merge.end); err != nil {
+		return err
+	}
+	return ptr.PrintSummary()
+}
+
+// add adds lines of a log merged and extends the time range
+func (ptr *mergeState) add(hatchetName string, lines int, start string, end string) {
+	ptr.hatchetName = hatchetName
+	ptr.lines += lines
+	if start != "" && (ptr.start == "" || start < ptr.start) {
+		ptr.start = start
+	}
+	if end > ptr.end {
+		ptr.end = end
+	}
+}
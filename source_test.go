@@ -0,0 +1,37 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * source_test.go
+ */
+
+package hatchet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetSourceNames(t *testing.T) {
+	lognames := []string{"/logs/host1.log.gz", "ssh://user@host2/var/log/mongod.log", "https://host3/logs/node.log"}
+	expected := "host1,host2:mongod,host3:node"
+	if names := strings.Join(GetSourceNames(lognames), ","); names != expected {
+		t.Fatal("expected", expected, "but got", names)
+	}
+	lognames = []string{"node1/mongod.log", "node2/mongod.log.zst", "mongos.log"}
+	expected = "node1/mongod,node2/mongod,mongos"
+	if names := strings.Join(GetSourceNames(lognames), ","); names != expected {
+		t.Fatal("expected", expected, "but got", names)
+	}
+}
+
+func TestMergeState(t *testing.T) {
+	merge := mergeState{}
+	merge.add("node1_abcdef", 100, "2023-01-01T00:00:00.000-0000", "2023-01-01T01:00:00.000-0000")
+	merge.add("node1_abcdef", 50, "2022-12-31T23:00:00.000-0000", "2023-01-01T00:30:00.000-0000")
+	merge.add("node1_abcdef", 0, "", "")
+	if merge.lines != 150 || merge.start != "2022-12-31T23:00:00.000-0000" || merge.end != "2023-01-01T01:00:00.000-0000" {
+		t.Fatal("expected", "150 lines from 2022-12-31T23:00 to 2023-01-01T01:00", "but got", merge.lines, merge.start, merge.end)
+	}
+	if err := (&Logv2{}).Merge([]string{"mongod.log"}); err == nil {
+		t.Fatal("expected", "2 or more logs", "but got", nil)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sources_template.go
+ */
+
+package hatchet
+
+import (
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetSourcesTemplate returns HTML
+func GetSourcesTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Sources}}
+	{{$hatchet := .Hatchet}}
+	<table width='100%'>
+		<caption>Lines by Source ({{len .Sources}} sources)</caption>
+		<tr><th>source</th><th>lines</th><th>slow ops</th><th>warnings</th><th>errors</th><th>start</th><th>end</th></tr>
+	{{range $doc := .Sources}}
+		<tr><td><a href='/hatchets/{{$hatchet}}/logs/all?source={{$doc.Source}}'>{{$doc.Source}}</a></td>
+			<td align='right'>{{numPrinter $doc.Lines}}</td>
+			<td align='right'>{{numPrinter $doc.SlowOps}}</td>
+			<td align='right'><a href='/hatchets/{{$hatchet}}/logs/all?source={{$doc.Source}}&severity=W'>{{numPrinter $doc.Warnings}}</a></td>
+			{{if gt $doc.Errors 0}}
+			<td align='right'><a style='color: red;' href='/hatchets/{{$hatchet}}/logs/all?source={{$doc.Source}}&severity=E'>{{numPrinter $doc.Errors}}</a></td>
+			{{else}}
+			<td align='right'>0</td>
+			{{end}}
+			<td>{{$doc.Start}}</td><td>{{$doc.End}}</td>
+		</tr>
+	{{end}}
+	</table>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no sources found, logs were not merged with -merge</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
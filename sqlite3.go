@@ -11,14 +11,41 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/mattn/go-sqlite3"
 )
 
+// SQLITE_BATCH_SIZE is the default number of lines inserted per transaction
+const SQLITE_BATCH_SIZE = 50000
+
+var registerOnce sync.Once
+
+// RegisterSQLite3Extended registers the sqlite3_extended driver, sqlite3 with
+// the regexp function
+func RegisterSQLite3Extended() {
+	registerOnce.Do(func() {
+		regex := func(re, s string) (bool, error) {
+			return regexp.MatchString(re, s)
+		}
+		sql.Register("sqlite3_extended",
+			&sqlite3.SQLiteDriver{
+				ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+					return conn.RegisterFunc("regexp", regex, true)
+				},
+			})
+	})
+}
+
 type SQLite3DB struct {
+	batchSize   int       // lines inserted per transaction
 	clientStmt  *sql.Stmt // {hatchet}_clients
 	driverStmt  *sql.Stmt // {hatchet}_drivers
 	db          *sql.DB
 	dbfile      string
 	hatchetName string
+	pending     int // lines inserted in the transaction
 	tx          *sql.Tx
 	pstmt       *sql.Stmt // {hatchet}
 	verbose     bool
@@ -26,7 +53,7 @@ type SQLite3DB struct {
 
 func NewSQLite3DB(dbfile string, hatchetName string) (*SQLite3DB, error) {
 	var err error
-	sqlite := &SQLite3DB{dbfile: dbfile, hatchetName: hatchetName}
+	sqlite := &SQLite3DB{dbfile: dbfile, hatchetName: hatchetName, batchSize: SQLITE_BATCH_SIZE}
 	dirname := filepath.Dir(dbfile)
 	os.Mkdir(dirname, 0755)
 	if sqlite.db, err = sql.Open("sqlite3_extended", dbfile); err != nil {
@@ -43,6 +70,15 @@ func (ptr *SQLite3DB) SetVerbose(b bool) {
 	ptr.verbose = b
 }
 
+// SetBatchSize sets lines inserted per transaction, SQLITE_BATCH_SIZE if not
+// positive
+func (ptr *SQLite3DB) SetBatchSize(size int) {
+	if size <= 0 {
+		size = SQLITE_BATCH_SIZE
+	}
+	ptr.batchSize = size
+}
+
 func (ptr *SQLite3DB) Begin() error {
 	var err error
 	log.Println("creating hatchet", ptr.hatchetName)
@@ -50,6 +86,15 @@ func (ptr *SQLite3DB) Begin() error {
 	if _, err = ptr.db.Exec(stmts); err != nil {
 		return err
 	}
+	if err = ptr.recordSchemaVersion(); err != nil {
+		return err
+	}
+	return ptr.Resume()
+}
+
+// Resume begins a transaction of inserting logs of a hatchet already created
+func (ptr *SQLite3DB) Resume() error {
+	var err error
 	if ptr.tx, err = ptr.db.Begin(); err != nil {
 		return err
 	}
@@ -66,6 +111,7 @@ func (ptr *SQLite3DB) Begin() error {
 }
 
 func (ptr *SQLite3DB) Commit() error {
+	ptr.pending = 0
 	return ptr.tx.Commit()
 }
 
@@ -99,8 +145,11 @@ func (ptr *SQLite3DB) Drop() error {
 			DROP INDEX IF EXISTS %v_idx_op;
 			DROP TABLE IF EXISTS %v_drivers;
 			DROP TABLE IF EXISTS %v_clients;
-			DROP INDEX IF EXISTS %v_clients_idx_context`,
-		hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName)
+			DROP INDEX IF EXISTS %v_clients_idx_context;
+			DROP TABLE IF EXISTS %v_bookmarks;
+			DROP TABLE IF EXISTS %v_fts`,
+		hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName,
+		hatchetName, hatchetName)
 	if _, err = ptr.db.Exec(stmts); err != nil {
 		return err
 	}
@@ -109,15 +158,112 @@ func (ptr *SQLite3DB) Drop() error {
 	if _, err := ptr.db.Exec(stmt); err != nil {
 		return err
 	}
+	stmt = fmt.Sprintf(`DELETE FROM %v WHERE name = '%v'`, MIGRATIONS_TABLE, ptr.hatchetName)
+	if _, err := ptr.db.Exec(stmt); err != nil {
+		return err
+	}
+	if err = ptr.createIngestsTable(); err != nil {
+		return err
+	}
+	stmt = fmt.Sprintf(`DELETE FROM %v WHERE name = '%v'`, INGESTS_TABLE, ptr.hatchetName)
+	if _, err := ptr.db.Exec(stmt); err != nil {
+		return err
+	}
 	return err
 }
 
 func (ptr *SQLite3DB) InsertLog(index int, end string, doc *Logv2Info, stat *OpStat) error {
 	var err error
-	_, err = ptr.pstmt.Exec(index, end, doc.Severity, doc.Component, doc.Context,
+	var ticketWait, planning, raw, nShards, shards, source, replan, stages, app, lag, wtEvent, checkpoint interface{} // NULL if not logged
+	var lockWaits, lockMicros, ttlDeleted, ttlMilli, chunkEvent, chunkMilli, txnResult, txnCause, txnMilli, txnOps, logID interface{}
+	var configChange, configName, configOld, configNew, keysExamined, docsExamined, nReturned, hasSortStage, usedDisk interface{}
+	var originatingOp, cursorID, bytesRead, readMicros interface{}
+	if micros, ok := GetTicketWait(doc); ok {
+		ticketWait = micros
+	}
+	if micros, ok := GetPlanningTime(doc); ok {
+		planning = micros
+	}
+	if n, names, ok := GetShardTargets(doc); ok {
+		nShards, shards = n, names
+	}
+	if reason, ok := GetReplanReason(doc); ok {
+		replan = reason
+	}
+	if names, ok := GetPipelineStages(doc); ok {
+		stages = names
+	}
+	if doc.AppName != "" {
+		app = doc.AppName
+	}
+	if milli, ok := GetReplicationLag(doc); ok {
+		lag = milli
+	}
+	if event, milli, ok := GetWiredTigerEvent(doc); ok {
+		wtEvent = event
+		if event == WT_CHECKPOINT {
+			checkpoint = milli
+		}
+	}
+	if count, micros, ok := GetLockWait(doc); ok {
+		lockWaits, lockMicros = count, micros
+	}
+	if _, deleted, milli, ok := GetTTLDelete(doc); ok {
+		ttlDeleted, ttlMilli = deleted, milli
+	}
+	if _, event, milli, ok := GetChunkEvent(doc); ok {
+		chunkEvent, chunkMilli = event, milli
+	}
+	if doc.ID != 0 {
+		logID = doc.ID
+	}
+	if change, ok := GetConfigChange(doc); ok {
+		configChange, configName, configOld, configNew = change.Type, change.Name, change.OldValue, change.NewValue
+	}
+	if stat.Op != "" {
+		keysExamined, docsExamined, nReturned, _ = GetExamined(doc)
+		if sorted, spilled, ok := GetSortStage(doc); ok {
+			hasSortStage, usedDisk = getSortStageFlag(sorted), getSortStageFlag(spilled)
+		}
+	}
+	if op, id, ok := GetCursorOp(doc, stat); ok {
+		originatingOp, cursorID = op, id
+	}
+	if bytes, micros, ok := GetStorageRead(doc); ok {
+		bytesRead, readMicros = bytes, micros
+	}
+	if txn, ok := GetTransaction(doc); ok {
+		txnResult, txnMilli, txnOps = txn.Result, txn.Milli, txn.Ops
+		if txn.Cause != "" {
+			txnCause = txn.Cause
+		}
+	}
+	values := []interface{}{index, end, doc.Severity, doc.Component, doc.Context,
 		doc.Msg, doc.Attributes.PlanSummary, doc.Attr.Map()["type"], doc.Attributes.NS, doc.Message,
-		stat.Op, stat.QueryPattern, stat.Index, doc.Attributes.Milli, doc.Attributes.Reslen)
-	return err
+		stat.Op, stat.QueryPattern, stat.SortPattern, stat.Index, doc.Attributes.Milli, doc.Attributes.Reslen, ticketWait}
+	values = append(values, GetWriteCounters(doc)...)
+	values = append(values, GetLockCounts(doc)...)
+	if doc.Raw != "" {
+		raw = doc.Raw
+	}
+	if doc.Source != "" {
+		source = doc.Source
+	}
+	if _, err = ptr.pstmt.Exec(append(values, planning, raw, nShards, shards, source, replan, stages, app, lag, wtEvent, checkpoint, lockWaits, lockMicros, ttlDeleted, ttlMilli, chunkEvent, chunkMilli,
+		txnResult, txnCause, txnMilli, txnOps, logID, configChange, configName, configOld, configNew,
+		keysExamined, docsExamined, nReturned, hasSortStage, usedDisk, originatingOp, cursorID, bytesRead, readMicros)...); err != nil {
+		return err
+	}
+	if ptr.pending++; ptr.pending >= ptr.batchSize { // commits a batch and begins another transaction
+		if ptr.verbose {
+			log.Println("committing", ptr.pending, "lines of", ptr.hatchetName)
+		}
+		if err = ptr.Commit(); err != nil {
+			return err
+		}
+		return ptr.Resume()
+	}
+	return nil
 }
 
 func (ptr *SQLite3DB) InsertClientConn(index int, doc *Logv2Info) error {
@@ -134,23 +280,64 @@ func (ptr *SQLite3DB) InsertDriver(index int, doc *Logv2Info) error {
 	return err
 }
 
+// InsertAuditData inserts rows of a category into the audit table
+func (ptr *SQLite3DB) InsertAuditData(category string, data []NameValue) error {
+	tx, err := ptr.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(fmt.Sprintf(`INSERT INTO %v_audit (type, name, value) VALUES(?,?,?)`, ptr.hatchetName))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, doc := range data {
+		if _, err = stmt.Exec(category, doc.Name, doc.Value); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func (ptr *SQLite3DB) UpdateHatchetInfo(info HatchetInfo) error {
-	istmt := fmt.Sprintf(`INSERT OR REPLACE INTO hatchet (name, version, module, arch, os, start, end)
-		VALUES ('%v', '%v', '%v', '%v', '%v', '%v', '%v');`, ptr.hatchetName, info.Version, info.Module, info.Arch, info.OS, info.Start, info.End)
-	_, err := ptr.db.Exec(istmt)
+	istmt := fmt.Sprintf(`INSERT OR REPLACE INTO hatchet (name, version, module, arch, os, start, end, slow_ms, slow_ns)
+		VALUES ('%v', '%v', '%v', '%v', '%v', '%v', '%v', %v, ?);`, ptr.hatchetName, info.Version, info.Module, info.Arch, info.OS,
+		info.Start, info.End, info.SlowMilli)
+	_, err := ptr.db.Exec(istmt, info.SlowNS)
+	return err
+}
+
+// ResetMetaData removes op shapes and audit data before they are recreated
+func (ptr *SQLite3DB) ResetMetaData() error {
+	stmt := fmt.Sprintf(`DELETE FROM %v_ops; DELETE FROM %v_audit;`, ptr.hatchetName, ptr.hatchetName)
+	_, err := ptr.db.Exec(stmt)
 	return err
 }
 
 func (ptr *SQLite3DB) CreateMetaData() error {
 	var err error
+	log.Printf("index messages into %v_fts\n", ptr.hatchetName)
+	if _, err = ptr.IndexMessages(); err != nil {
+		return err
+	}
+
 	log.Printf("insert ops into %v_ops\n", ptr.hatchetName)
 	istmt := fmt.Sprintf(`INSERT INTO %v_ops
-			SELECT op, COUNT(*), ROUND(AVG(milli),1), MAX(milli), SUM(milli), ns, _index, SUM(reslen), filter
-				FROM %v WHERE op != "" GROUP BY op, ns, filter, _index`, ptr.hatchetName, ptr.hatchetName)
+			SELECT op, COUNT(*), ROUND(AVG(milli),1), MAX(milli), SUM(milli), ns, _index, SUM(reslen), filter,
+				MIN(date), MAX(date), sort, %v
+				FROM %v GROUP BY op, ns, filter, sort, _index`, ptr.hatchetName, GetSQLPercentiles(),
+		GetSQLRankedOps(ptr.hatchetName, "op, ns, filter, sort, _index"))
 	if _, err = ptr.db.Exec(istmt); err != nil {
 		return err
 	}
 
+	log.Printf("insert [latency] into %v_audit\n", ptr.hatchetName)
+	if _, err = ptr.db.Exec(GetSQLLatencyAudit(ptr.hatchetName)); err != nil {
+		return err
+	}
+
 	log.Printf("insert [exception] into %v_audit\n", ptr.hatchetName)
 	istmt = fmt.Sprintf(`INSERT INTO %v_audit
 		SELECT 'exception', severity, COUNT(*) count FROM %v WHERE severity IN ('W', 'E', 'F') 
@@ -220,17 +407,29 @@ func (ptr *SQLite3DB) CreateMetaData() error {
 func GetHatchetInitStmt(hatchetName string) string {
 	return fmt.Sprintf(`
 			CREATE TABLE IF NOT EXISTS hatchet ( name text not null primary key,
-				version text, module text, arch text, os text, start text, end text);
+				version text, module text, arch text, os text, start text, end text, slow_ms integer, slow_ns text);
+			%v
 
 			DROP TABLE IF EXISTS %v;
 			CREATE TABLE %v (
 				id integer not null primary key, date text, severity text, component text, context text,
 				msg text, plan text, type text, ns text, message text,
-				op text, filter text, _index text, milli integer, reslen integer);
+				op text, filter text, sort text, _index text, milli integer, reslen integer, ticket_wait integer,
+				n_matched integer, n_modified integer, n_inserted integer, n_upserted integer, n_deleted integer,
+				lock_global_r integer, lock_global_w integer, lock_database_r integer, lock_database_w integer,
+				lock_collection_r integer, lock_collection_w integer, planning_micros integer, raw text,
+				n_shards integer, shards text, source text, replan_reason text,
+				stages text, app_name text, repl_lag_ms integer, wt_event text, wt_checkpoint_ms integer,
+				lock_wait_count integer, lock_wait_micros integer, ttl_deleted integer, ttl_ms integer,
+				chunk_event text, chunk_ms integer, txn_result text, txn_cause text, txn_ms integer, txn_ops integer,
+				log_id integer, config_change text, config_name text, config_old text, config_new text,
+				keys_examined integer, docs_examined integer, nreturned integer, has_sort_stage integer, used_disk integer,
+				originating_op text, cursor_id integer, bytes_read integer, read_micros integer);
 
 			DROP TABLE IF EXISTS %v_ops;
 			CREATE TABLE %v_ops (op text, count integer, avg_ms numeric, max_ms integer, total_ms integer,
-				ns text, _index text, reslen integer, filter text);
+				ns text, _index text, reslen integer, filter text, first_seen text, last_seen text, sort text,
+				p50_ms integer, p95_ms integer, p99_ms integer);
 
 			DROP TABLE IF EXISTS %v_audit;
 			CREATE TABLE %v_audit (type text, name text, value integer);
@@ -249,6 +448,7 @@ func GetHatchetInitStmt(hatchetName string) string {
 			CREATE TABLE %v_clients(
 				id integer not null primary key, ip text, port text, conns integer, accepted integer, ended integer, context text);
 			CREATE INDEX IF NOT EXISTS %v_clients_idx_context ON %v_clients (context,ip);`,
+		GetMigrationsInitStmt(),
 		hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName,
 		hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName, hatchetName,
 		hatchetName, hatchetName)
@@ -257,8 +457,16 @@ func GetHatchetInitStmt(hatchetName string) string {
 // GetHatchetPreparedStmt returns prepared statement of the hatchet table
 func GetHatchetPreparedStmt(hatchetName string) string {
 	return fmt.Sprintf(`INSERT INTO %v (id, date, severity, component, context,
-		msg, plan, type, ns, message, op, filter, _index, milli, reslen)
-		VALUES(?,?,?,?,?, ?,?,?,?,?, ?,?,?,?,?)`, hatchetName)
+		msg, plan, type, ns, message, op, filter, sort, _index, milli, reslen, ticket_wait,
+		n_matched, n_modified, n_inserted, n_upserted, n_deleted,
+		lock_global_r, lock_global_w, lock_database_r, lock_database_w, lock_collection_r, lock_collection_w,
+		planning_micros, raw, n_shards, shards, source, replan_reason, stages, app_name, repl_lag_ms,
+		wt_event, wt_checkpoint_ms, lock_wait_count, lock_wait_micros,
+		ttl_deleted, ttl_ms, chunk_event, chunk_ms, txn_result, txn_cause, txn_ms, txn_ops, log_id,
+		config_change, config_name, config_old, config_new, keys_examined, docs_examined, nreturned,
+		has_sort_stage, used_disk, originating_op, cursor_id, bytes_read, read_micros)
+		VALUES(?,?,?,?,?, ?,?,?,?,?, ?,?,?,?,?, ?,?,?,?,?, ?,?,?,?,?, ?,?,?,?,?, ?,?,?,?,?, ?,?,?,?,?, ?,?,?,?,?,
+		?,?,?,?,?, ?,?,?,?,?, ?,?,?,?,?, ?,?,?)`, hatchetName)
 }
 
 // GetClientPreparedStmt returns prepared statement of clients table
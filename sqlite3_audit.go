@@ -8,6 +8,7 @@ package hatchet
 import (
 	"fmt"
 	"log"
+	"strings"
 )
 
 func (ptr *SQLite3DB) GetAuditData() (map[string][]NameValues, error) {
@@ -72,8 +73,73 @@ func (ptr *SQLite3DB) GetAuditData() (map[string][]NameValues, error) {
 		rows.Close()
 	}
 
+	// get the worst COLLSCAN shapes
+	category = "collscans"
+	if ops, err := ptr.GetSlowOps("total_ms", "DESC", true); err == nil {
+		if docs := GetCollscanAuditData(ops, TOP_N); len(docs) > 0 {
+			data[category] = docs
+		}
+	}
+
+	// get elections and state changes of the replica set
+	category = "election"
+	if events, err := ptr.GetElectionEvents(); err == nil {
+		if docs := GetElectionAuditData(events); len(docs) > 0 {
+			data[category] = docs
+		}
+	}
+
+	// get chunk migrations by collection and balancer rounds of a sharded cluster
+	if events, err := ptr.GetChunkEvents(); err == nil {
+		docs, balancer := GetChunkAuditData(events)
+		if len(docs) > 0 {
+			data["chunk-migration"] = docs
+		}
+		if len(balancer) > 0 {
+			data["balancer"] = balancer
+		}
+	}
+
+	// get multi-document transactions by termination and cause of aborts
+	if stats, err := ptr.GetTransactionStats(); err == nil {
+		if docs := GetTransactionAuditData(stats); len(docs) > 0 {
+			data["transaction"] = docs
+		}
+	}
+
+	// get runtime configuration changes
+	if changes, err := ptr.GetConfigChanges(); err == nil {
+		if docs := GetConfigAuditData(changes); len(docs) > 0 {
+			data["config"] = docs
+		}
+	}
+
+	// get shapes of poor query targeting
+	if stats, err := ptr.GetTargetingStats(); err == nil {
+		if docs := GetTargetingAuditData(stats, GetLogv2().GetTargetingRatio()); len(docs) > 0 {
+			data["targeting"] = docs
+		}
+	}
+
+	// get shapes of blocking sorts and spills to disk
+	if stats, err := ptr.GetSortStageStats(); err == nil && len(stats) > 0 {
+		data["sort"] = GetBlockingSortAuditData(stats)
+	}
+
+	// get initial and getMore latencies of shapes iterated by getMores
+	if stats, err := ptr.GetGetMoreStats(); err == nil && len(stats) > 0 {
+		data["getmore"] = GetGetMoreAuditData(stats)
+	}
+
+	// get the startup banner of the last boot
+	if banner, err := ptr.GetServerInfo(); err == nil {
+		if docs := GetServerInfoAuditData(banner); len(docs) > 0 {
+			data["server"] = docs
+		}
+	}
+
 	// get audit data
-	query = fmt.Sprintf(`SELECT type, name, value FROM %v_audit WHERE type IN ('exception', 'failed', 'op', 'duration') ORDER BY type, value DESC;`, ptr.hatchetName)
+	query = fmt.Sprintf(`SELECT type, name, value FROM %v_audit WHERE type IN ('exception', 'failed', 'op', 'duration', 'oplog', 'restart', 'shapes') ORDER BY type, value DESC;`, ptr.hatchetName)
 	if ptr.verbose {
 		log.Println(query)
 	}
@@ -149,6 +215,31 @@ func (ptr *SQLite3DB) GetAuditData() (map[string][]NameValues, error) {
 		rows.Close()
 	}
 
+	for category, types := range AUDIT_SERIES {
+		query = fmt.Sprintf(`SELECT type, name, value FROM %v_audit WHERE type IN ('%v') ORDER BY value DESC;`,
+			ptr.hatchetName, strings.Join(types, "','"))
+		if ptr.verbose {
+			log.Println(query)
+		}
+		if rows, err = db.Query(query); err != nil {
+			return data, err
+		}
+		series := map[string][]NameValue{}
+		for rows.Next() {
+			var name string
+			var doc NameValue
+			if err = rows.Scan(&name, &doc.Name, &doc.Value); err != nil {
+				rows.Close()
+				return data, err
+			}
+			series[name] = append(series[name], doc)
+		}
+		rows.Close()
+		if docs := MergeAuditSeries(types, series); len(docs) > 0 {
+			data[category] = docs
+		}
+	}
+
 	category = "driver"
 	query = fmt.Sprintf(`SELECT DISTINCT ip, driver, version FROM %v_drivers ORDER BY driver, version DESC;`,
 		ptr.hatchetName)
@@ -175,3 +266,51 @@ func (ptr *SQLite3DB) GetAuditData() (map[string][]NameValues, error) {
 
 	return data, err
 }
+
+// GetLogTemplates returns counts, and first and last seen of log templates
+// merged by TEMPLATE_SERIES
+func (ptr *SQLite3DB) GetLogTemplates() ([]NameValues, error) {
+	query := fmt.Sprintf(`SELECT type, name, value FROM %v_audit WHERE type IN ('%v');`,
+		ptr.hatchetName, strings.Join(TEMPLATE_SERIES, "','"))
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	series := map[string][]NameValue{}
+	for rows.Next() {
+		var name string
+		var doc NameValue
+		if err = rows.Scan(&name, &doc.Name, &doc.Value); err != nil {
+			return nil, err
+		}
+		series[name] = append(series[name], doc)
+	}
+	return MergeAuditSeries(TEMPLATE_SERIES, series), err
+}
+
+// GetServerInfo returns the startup banner of the last boot as "name=value"
+// in the logged order
+func (ptr *SQLite3DB) GetServerInfo() ([]NameValue, error) {
+	query := fmt.Sprintf(`SELECT name, value FROM %v_audit WHERE type = 'server' ORDER BY value;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	docs := []NameValue{}
+	for rows.Next() {
+		var doc NameValue
+		if err = rows.Scan(&doc.Name, &doc.Value); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
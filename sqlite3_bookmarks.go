@@ -0,0 +1,67 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sqlite3_bookmarks.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"log"
+)
+
+// createBookmarksTable creates the bookmarks table if not exists, it is not
+// dropped when logs are reprocessed
+func (ptr *SQLite3DB) createBookmarksTable() error {
+	_, err := ptr.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v_bookmarks (
+		hash text not null primary key, op text, ns text, filter text, _index text, note text, date text);`,
+		ptr.hatchetName))
+	return err
+}
+
+// GetBookmarks returns bookmarked ops
+func (ptr *SQLite3DB) GetBookmarks() ([]Bookmark, error) {
+	docs := []Bookmark{}
+	if err := ptr.createBookmarksTable(); err != nil {
+		return docs, err
+	}
+	query := fmt.Sprintf(`SELECT hash, op, ns, filter, _index, note, date FROM %v_bookmarks ORDER BY date;`,
+		ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc Bookmark
+		if err = rows.Scan(&doc.Hash, &doc.Op, &doc.Namespace, &doc.QueryPattern, &doc.Index,
+			&doc.Note, &doc.Date); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// SaveBookmark inserts or updates a bookmark
+func (ptr *SQLite3DB) SaveBookmark(doc Bookmark) error {
+	if err := ptr.createBookmarksTable(); err != nil {
+		return err
+	}
+	_, err := ptr.db.Exec(fmt.Sprintf(`INSERT INTO %v_bookmarks (hash, op, ns, filter, _index, note, date)
+		VALUES(?,?,?,?,?,?,?) ON CONFLICT(hash) DO UPDATE SET note = excluded.note;`, ptr.hatchetName),
+		doc.Hash, doc.Op, doc.Namespace, doc.QueryPattern, doc.Index, doc.Note, doc.Date)
+	return err
+}
+
+// DeleteBookmark removes a bookmark
+func (ptr *SQLite3DB) DeleteBookmark(hash string) error {
+	if err := ptr.createBookmarksTable(); err != nil {
+		return err
+	}
+	_, err := ptr.db.Exec(fmt.Sprintf(`DELETE FROM %v_bookmarks WHERE hash = ?;`, ptr.hatchetName), hash)
+	return err
+}
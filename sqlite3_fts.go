@@ -0,0 +1,103 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sqlite3_fts.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// createFTSTable creates {hatchet}_fts, an external content full text index
+// of messages, of FTS5 if compiled with the sqlite_fts5 tag or else of FTS4
+func (ptr *SQLite3DB) createFTSTable() error {
+	stmt := fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS %v_fts USING fts5(message, content='%v', content_rowid='id')`,
+		ptr.hatchetName, ptr.hatchetName)
+	if _, err := ptr.db.Exec(stmt); err == nil {
+		return nil
+	} else if !strings.Contains(err.Error(), "no such module") {
+		return err
+	}
+	stmt = fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS %v_fts USING fts4(message, content="%v")`,
+		ptr.hatchetName, ptr.hatchetName)
+	_, err := ptr.db.Exec(stmt)
+	return err
+}
+
+// IndexMessages adds messages not yet indexed to {hatchet}_fts and returns
+// the number of messages added
+func (ptr *SQLite3DB) IndexMessages() (int, error) {
+	if err := ptr.createFTSTable(); err != nil {
+		return 0, err
+	}
+	// full scans of external content tables read the content table, the
+	// last id indexed is of the docsize shadow table, docid of FTS4
+	var ddl string
+	if err := ptr.db.QueryRow(`SELECT sql FROM sqlite_master WHERE name = ?`, ptr.hatchetName+"_fts").Scan(&ddl); err != nil {
+		return 0, err
+	}
+	idcol := "docid"
+	if strings.Contains(strings.ToLower(ddl), "fts5") {
+		idcol = "id"
+	}
+	var last int
+	query := fmt.Sprintf(`SELECT IFNULL(MAX(%v), 0) FROM %v_fts_docsize`, idcol, ptr.hatchetName)
+	if err := ptr.db.QueryRow(query).Scan(&last); err != nil {
+		return 0, err
+	}
+	stmt := fmt.Sprintf(`INSERT INTO %v_fts (rowid, message) SELECT id, message FROM %v WHERE id > ? ORDER BY id`,
+		ptr.hatchetName, ptr.hatchetName)
+	result, err := ptr.db.Exec(stmt, last)
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+// SearchMessages returns logs of messages matching a full text query of
+// words, "phrases", prefix*, AND, OR, NOT, and parentheses in order of dates,
+// limit is of {offset,}{limit} and one more log is returned if there are more
+func (ptr *SQLite3DB) SearchMessages(query string, limit string) ([]LegacyLog, error) {
+	if _, err := ptr.IndexMessages(); err != nil {
+		return []LegacyLog{}, err
+	}
+	offset, nlimit := GetOffsetLimit(limit)
+	if nlimit <= 0 {
+		nlimit = LIMIT
+	}
+	stmt := fmt.Sprintf(`SELECT id, date, severity, component, context, message, IFNULL(source, '') FROM %v
+		WHERE id IN (SELECT rowid FROM %v_fts WHERE %v_fts MATCH ?) ORDER BY id LIMIT ?,?`,
+		ptr.hatchetName, ptr.hatchetName, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(stmt, query)
+	}
+	docs, err := ptr.queryMessages(stmt, query, offset, nlimit+1)
+	if err != nil && (strings.Contains(err.Error(), "syntax error") || strings.Contains(err.Error(), "malformed MATCH")) {
+		// search an invalid query as a phrase, e.g. 10.0.0.1:27017
+		docs, err = ptr.queryMessages(stmt, `"`+strings.ReplaceAll(query, `"`, `""`)+`"`, offset, nlimit+1)
+	}
+	return docs, err
+}
+
+// queryMessages returns logs of a statement of a MATCH, an offset, and a limit
+func (ptr *SQLite3DB) queryMessages(stmt string, match string, offset int, limit int) ([]LegacyLog, error) {
+	docs := []LegacyLog{}
+	rows, err := ptr.db.Query(stmt, match, offset, limit)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc LegacyLog
+		if err = rows.Scan(&doc.ID, &doc.Timestamp, &doc.Severity, &doc.Component, &doc.Context, &doc.Message,
+			&doc.Source); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sqlite3_fts_test.go
+ */
+
+package hatchet
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSQLite3SearchMessages(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "fts.db")
+	db, err := sql.Open("sqlite3", dbfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbase := &SQLite3DB{db: db, dbfile: dbfile, hatchetName: "mongod_1"}
+	defer dbase.Close()
+	if _, err = db.Exec(`CREATE TABLE mongod_1 (id integer primary key, date text, severity text, component text,
+		context text, message text, source text)`); err != nil {
+		t.Fatal(err)
+	}
+	messages := []string{
+		"Connection accepted remote: 10.0.0.1:27017",
+		"Write conflict E11000 duplicate key error collection: shop.orders",
+		"Slow query find shop.orders planSummary: COLLSCAN",
+		"E11000 duplicate key error collection: shop.carts",
+		"Connection ended remote: 10.0.0.2:27017",
+	}
+	insert := func(id int, message string) {
+		if _, err := db.Exec(`INSERT INTO mongod_1 VALUES (?, ?, 'I', 'NETWORK', 'conn1', ?, NULL)`,
+			id, fmt.Sprintf("2023-01-01T00:00:0%v", id), message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, message := range messages {
+		insert(i+1, message)
+	}
+
+	for query, expected := range map[string][]int{
+		`E11000`:                             {2, 4},
+		`"duplicate key" AND orders`:         {2},
+		`duplicate NOT carts`:                {2},
+		`COLLSCAN OR conflict`:               {2, 3},
+		`connect*`:                           {1, 5},
+		`(accepted OR ended) AND "10.0.0.2"`: {5},
+		`10.0.0.1:27017`:                     {1}, // invalid syntax searched as a phrase
+	} {
+		logs, err := dbase.SearchMessages(query, "")
+		if err != nil {
+			t.Fatal(query, err)
+		}
+		ids := []int{}
+		for _, doc := range logs {
+			ids = append(ids, doc.ID)
+		}
+		if !reflect.DeepEqual(ids, expected) {
+			t.Fatal("expected", expected, "of", query, "but got", ids)
+		}
+	}
+
+	// messages inserted after are indexed incrementally
+	insert(6, "E11000 duplicate key error collection: shop.users")
+	logs, err := dbase.SearchMessages("E11000", "1,1")
+	if err != nil || len(logs) != 2 || logs[0].ID != 4 {
+		t.Fatal("expected", "ids 4 and 6", "but got", logs, err)
+	}
+	if count, err := dbase.IndexMessages(); err != nil || count != 0 {
+		t.Fatal("expected", 0, "but got", count, err)
+	}
+}
+
+func TestGetSearchTerms(t *testing.T) {
+	expected := []string{"duplicate key", "shop\\.orders", "conn"}
+	terms := GetSearchTerms(`("duplicate key" AND shop.orders) OR conn* NOT carts -users`)
+	if !reflect.DeepEqual(terms, expected) {
+		t.Fatal("expected", expected, "but got", terms)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sqlite3_ingests.go
+ */
+
+package hatchet
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// createIngestsTable creates the ingests table if not exists
+func (ptr *SQLite3DB) createIngestsTable() error {
+	_, err := ptr.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		name text not null primary key, logname text, offset integer, lines integer, head text,
+		start text, end text, updated text);`, INGESTS_TABLE))
+	return err
+}
+
+// GetIngest returns the last ingest of a log, Name is empty if not found
+func (ptr *SQLite3DB) GetIngest(logname string) (Ingest, error) {
+	doc := Ingest{}
+	if err := ptr.createIngestsTable(); err != nil {
+		return doc, err
+	}
+	query := fmt.Sprintf(`SELECT name, logname, offset, lines, head, start, end, updated FROM %v
+		WHERE logname = ? ORDER BY updated DESC LIMIT 1;`, INGESTS_TABLE)
+	err := ptr.db.QueryRow(query, logname).Scan(&doc.Name, &doc.Logname, &doc.Offset, &doc.Lines, &doc.Head,
+		&doc.Start, &doc.End, &doc.Updated)
+	if err == sql.ErrNoRows {
+		return doc, nil
+	}
+	return doc, err
+}
+
+// SaveIngest inserts or replaces the ingest of a hatchet
+func (ptr *SQLite3DB) SaveIngest(doc Ingest) error {
+	if err := ptr.createIngestsTable(); err != nil {
+		return err
+	}
+	_, err := ptr.db.Exec(fmt.Sprintf(`INSERT OR REPLACE INTO %v (name, logname, offset, lines, head, start, end, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?);`, INGESTS_TABLE), ptr.hatchetName, doc.Logname, doc.Offset, doc.Lines,
+		doc.Head, doc.Start, doc.End, doc.Updated)
+	return err
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sqlite3_migrations.go
+ */
+
+package hatchet
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// GetMigrationsInitStmt returns statement to create the migrations table
+func GetMigrationsInitStmt() string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (name text, version integer, description text, applied text);`,
+		MIGRATIONS_TABLE)
+}
+
+// recordSchemaVersion records the schema version of a hatchet just created
+func (ptr *SQLite3DB) recordSchemaVersion() error {
+	stmt := fmt.Sprintf(`DELETE FROM %v WHERE name = '%v';
+		INSERT INTO %v (name, version, description, applied) VALUES ('%v', %v, '%v', '%v');`,
+		MIGRATIONS_TABLE, ptr.hatchetName, MIGRATIONS_TABLE, ptr.hatchetName, DB_SCHEMA_VERSION, MIGRATION_CREATED,
+		time.Now().UTC().Format(time.RFC3339))
+	_, err := ptr.db.Exec(stmt)
+	return err
+}
+
+// GetMigrations returns migrations recorded of a hatchet, none if the
+// migrations table is not created yet
+func (ptr *SQLite3DB) GetMigrations() ([]MigrationRecord, error) {
+	docs := []MigrationRecord{}
+	query := fmt.Sprintf(`SELECT version, description, applied FROM %v WHERE name = '%v' ORDER BY version;`,
+		MIGRATIONS_TABLE, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil && strings.Contains(err.Error(), "no such table") {
+		return docs, nil
+	} else if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc MigrationRecord
+		if err = rows.Scan(&doc.Version, &doc.Description, &doc.Applied); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// Migrate adds columns missing from tables of a hatchet created by an older
+// version, backfills them, and records the migrations applied
+func (ptr *SQLite3DB) Migrate() ([]Migration, error) {
+	if _, err := ptr.db.Exec(GetMigrationsInitStmt()); err != nil {
+		return nil, err
+	}
+	records, err := ptr.GetMigrations()
+	if err != nil {
+		return nil, err
+	}
+	version := 1
+	for _, record := range records {
+		if record.Version > version {
+			version = record.Version
+		}
+	}
+	migrations := GetPendingMigrations(version)
+	if len(migrations) == 0 {
+		return migrations, nil
+	}
+	tx, err := ptr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	for _, migration := range migrations {
+		for _, column := range migration.Columns {
+			table := ptr.hatchetName + column.Table
+			if column.Table == HATCHET_TABLE {
+				table = column.Table
+			}
+			columns, err := getColumns(tx, table)
+			if err != nil {
+				return nil, err
+			}
+			if len(columns) == 0 || columns[column.Name] { // table not found or column exists
+				continue
+			}
+			stmt := fmt.Sprintf(`ALTER TABLE %v ADD COLUMN %v %v;`, table, column.Name, column.Type)
+			if ptr.verbose {
+				log.Println(stmt)
+			}
+			if _, err = tx.Exec(stmt); err != nil {
+				return nil, err
+			}
+		}
+		if migration.Backfill != "" {
+			if _, err = tx.Exec(fmt.Sprintf(migration.Backfill, ptr.hatchetName)); err != nil {
+				return nil, err
+			}
+		}
+		stmt := fmt.Sprintf(`INSERT INTO %v (name, version, description, applied) VALUES (?, ?, ?, ?);`, MIGRATIONS_TABLE)
+		if _, err = tx.Exec(stmt, ptr.hatchetName, migration.Version, migration.Description,
+			time.Now().UTC().Format(time.RFC3339)); err != nil {
+			return nil, err
+		}
+	}
+	return migrations, tx.Commit()
+}
+
+// getColumns returns column names of a table, empty if the table doesn't exist
+func getColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	columns := map[string]bool{}
+	rows, err := tx.Query(fmt.Sprintf(`SELECT name FROM pragma_table_info('%v');`, table))
+	if err != nil {
+		return columns, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return columns, err
+		}
+		columns[name] = true
+	}
+	return columns, err
+}
@@ -7,6 +7,7 @@ package hatchet
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"regexp"
 	"strings"
@@ -25,11 +26,13 @@ func (ptr *SQLite3DB) GetSlowOps(orderBy string, order string, collscan bool) ([
 	ops := []OpStat{}
 	db := ptr.db
 	query := fmt.Sprintf(`SELECT op, count, avg_ms, max_ms,
-			total_ms, ns, _index "index", reslen, filter "query_pattern"
+			total_ms, ns, _index "index", reslen, filter "query_pattern", first_seen, last_seen, IFNULL(sort, ''),
+			IFNULL(p50_ms, 0), IFNULL(p95_ms, 0), IFNULL(p99_ms, 0)
 			FROM %v_ops ORDER BY %v %v`, ptr.hatchetName, orderBy, order)
 	if collscan {
 		query = fmt.Sprintf(`SELECT op, count, avg_ms, max_ms,
-				total_ms, ns, _index "index", reslen, filter "query_pattern"
+				total_ms, ns, _index "index", reslen, filter "query_pattern", first_seen, last_seen, IFNULL(sort, ''),
+				IFNULL(p50_ms, 0), IFNULL(p95_ms, 0), IFNULL(p99_ms, 0)
 				FROM %v_ops WHERE _index = "COLLSCAN" ORDER BY %v %v`, ptr.hatchetName, orderBy, order)
 	}
 	if ptr.verbose {
@@ -43,7 +46,8 @@ func (ptr *SQLite3DB) GetSlowOps(orderBy string, order string, collscan bool) ([
 	for rows.Next() {
 		var op OpStat
 		if err = rows.Scan(&op.Op, &op.Count, &op.AvgMilli, &op.MaxMilli, &op.TotalMilli,
-			&op.Namespace, &op.Index, &op.Reslen, &op.QueryPattern); err != nil {
+			&op.Namespace, &op.Index, &op.Reslen, &op.QueryPattern, &op.FirstSeen, &op.LastSeen, &op.SortPattern,
+			&op.P50Milli, &op.P95Milli, &op.P99Milli); err != nil {
 			return ops, err
 		}
 		ops = append(ops, op)
@@ -53,7 +57,7 @@ func (ptr *SQLite3DB) GetSlowOps(orderBy string, order string, collscan bool) ([
 
 func (ptr *SQLite3DB) GetLogs(opts ...string) ([]LegacyLog, error) {
 	docs := []LegacyLog{}
-	qheader := fmt.Sprintf(`SELECT date, severity, component, context, message FROM %v`, ptr.hatchetName)
+	qheader := fmt.Sprintf(`SELECT date, severity, component, context, message, IFNULL(source, '') FROM %v`, ptr.hatchetName)
 	wheres := []string{}
 	search := ""
 	qlimit := LIMIT + 1
@@ -104,7 +108,7 @@ func (ptr *SQLite3DB) GetLogs(opts ...string) ([]LegacyLog, error) {
 	defer rows.Close()
 	for rows.Next() {
 		var doc LegacyLog
-		if err = rows.Scan(&doc.Timestamp, &doc.Severity, &doc.Component, &doc.Context, &doc.Message); err != nil {
+		if err = rows.Scan(&doc.Timestamp, &doc.Severity, &doc.Component, &doc.Context, &doc.Message, &doc.Source); err != nil {
 			return docs, err
 		}
 		docs = append(docs, doc)
@@ -116,7 +120,7 @@ func (ptr *SQLite3DB) GetLogs(opts ...string) ([]LegacyLog, error) {
 }
 
 func (ptr *SQLite3DB) SearchLogs(opts ...string) ([]LegacyLog, error) {
-	qheader := fmt.Sprintf(`SELECT date, severity, component, context, message FROM %v`, ptr.hatchetName)
+	qheader := fmt.Sprintf(`SELECT date, severity, component, context, message, IFNULL(source, '') FROM %v`, ptr.hatchetName)
 	docs := []LegacyLog{}
 	wheres := []string{}
 	qlimit := LIMIT + 1
@@ -163,7 +167,7 @@ func (ptr *SQLite3DB) SearchLogs(opts ...string) ([]LegacyLog, error) {
 	defer rows.Close()
 	for rows.Next() {
 		var doc LegacyLog
-		if err = rows.Scan(&doc.Timestamp, &doc.Severity, &doc.Component, &doc.Context, &doc.Message); err != nil {
+		if err = rows.Scan(&doc.Timestamp, &doc.Severity, &doc.Component, &doc.Context, &doc.Message, &doc.Source); err != nil {
 			return docs, err
 		}
 		docs = append(docs, doc)
@@ -171,9 +175,53 @@ func (ptr *SQLite3DB) SearchLogs(opts ...string) ([]LegacyLog, error) {
 	return docs, err
 }
 
+// ExportLogs writes original lines, or lines in the legacy format if not
+// stored, of logs matching filters in order and returns the number of lines
+func (ptr *SQLite3DB) ExportLogs(w io.Writer, filters []RawLogFilter) (int, error) {
+	wheres := []string{}
+	args := []interface{}{}
+	for _, filter := range filters {
+		if filter.Column == "date" {
+			wheres = append(wheres, "date BETWEEN ? AND ?")
+		} else {
+			wheres = append(wheres, fmt.Sprintf("%v IN (?%v)", filter.Column, strings.Repeat(",?", len(filter.Values)-1)))
+		}
+		for _, value := range filter.Values {
+			args = append(args, value)
+		}
+	}
+	wclause := ""
+	if len(wheres) > 0 {
+		wclause = " WHERE " + strings.Join(wheres, " AND ")
+	}
+	query := fmt.Sprintf(`SELECT date, severity, component, context, message, IFNULL(raw, '') FROM %v%v ORDER BY id`,
+		ptr.hatchetName, wclause)
+	if ptr.verbose {
+		log.Println(query, args)
+	}
+	rows, err := ptr.db.Query(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	count := 0
+	for rows.Next() {
+		var doc LegacyLog
+		var raw string
+		if err = rows.Scan(&doc.Timestamp, &doc.Severity, &doc.Component, &doc.Context, &doc.Message, &raw); err != nil {
+			return count, err
+		}
+		if _, err = fmt.Fprintln(w, GetRawLogLine(raw, doc)); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
 func (ptr *SQLite3DB) GetSlowestLogs(topN int) ([]LegacyLog, error) {
 	docs := []LegacyLog{}
-	query := fmt.Sprintf(`SELECT date, severity, component, context, message
+	query := fmt.Sprintf(`SELECT id, date, severity, component, context, message
 			FROM %v WHERE op != "" ORDER BY milli DESC LIMIT %v`, ptr.hatchetName, topN)
 	db := ptr.db
 	if ptr.verbose {
@@ -185,8 +233,643 @@ func (ptr *SQLite3DB) GetSlowestLogs(topN int) ([]LegacyLog, error) {
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var doc LegacyLog
-		if err = rows.Scan(&doc.Timestamp, &doc.Severity, &doc.Component, &doc.Context, &doc.Message); err != nil {
+		var doc LegacyLog
+		if err = rows.Scan(&doc.ID, &doc.Timestamp, &doc.Severity, &doc.Component, &doc.Context, &doc.Message); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+func (ptr *SQLite3DB) GetAverageOpTime(op string, duration string) ([]OpCount, error) {
+	docs := []OpCount{}
+	db := ptr.db
+	durcond := ""
+	var substr string
+	opcond := "op != ''"
+	if op != "" {
+		opcond = fmt.Sprintf("op = '%v'", op)
+	}
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	query := fmt.Sprintf(`SELECT %v, AVG(milli), COUNT(*), op, ns, filter FROM %v 
+		WHERE %v %v GROUP by %v, op, ns, filter;`, substr, ptr.hatchetName, opcond, durcond, substr)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc OpCount
+		if err = rows.Scan(&doc.Date, &doc.Milli, &doc.Count, &doc.Op, &doc.Namespace, &doc.Filter); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetShapePlans returns counts of plans by op shape and time bucket of shapes
+// using more than one plan
+func (ptr *SQLite3DB) GetShapePlans(duration string) ([]ShapePlan, error) {
+	docs := []ShapePlan{}
+	db := ptr.db
+	durcond := ""
+	var substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	cond := "op != '' AND _index != '' AND _index NOT LIKE 'ErrMsg:%'"
+	query := fmt.Sprintf(`SELECT op, ns, filter, IFNULL(sort, ''), _index, %v, COUNT(*), MIN(date) FROM %v
+		WHERE %v %v AND (op, ns, filter, IFNULL(sort, '')) IN (
+			SELECT op, ns, filter, IFNULL(sort, '') FROM %v WHERE %v %v
+			GROUP BY 1, 2, 3, 4 HAVING COUNT(DISTINCT _index) > 1)
+		GROUP BY 1, 2, 3, 4, 5, 6 ORDER BY 1, 2, 3, 4, 6, 8;`,
+		substr, ptr.hatchetName, cond, durcond, ptr.hatchetName, cond, durcond)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc ShapePlan
+		if err = rows.Scan(&doc.Op, &doc.Namespace, &doc.QueryPattern, &doc.SortPattern, &doc.Index,
+			&doc.Date, &doc.Count, &doc.FirstSeen); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetTicketWaits returns avg and max ticket wait in ms and counts of queued ops
+func (ptr *SQLite3DB) GetTicketWaits(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	db := ptr.db
+	durcond := ""
+	var substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	query := fmt.Sprintf(`SELECT %v, AVG(ticket_wait)/1000.0, MAX(ticket_wait)/1000.0,
+		SUM(CASE WHEN ticket_wait > 0 THEN 1 ELSE 0 END) FROM %v
+		WHERE ticket_wait IS NOT NULL %v GROUP by %v ORDER BY 1;`, substr, ptr.hatchetName, durcond, substr)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TimeSeries
+		var avg, max, queued float64
+		if err = rows.Scan(&doc.Date, &avg, &max, &queued); err != nil {
+			return docs, err
+		}
+		doc.Values = []float64{avg, max, queued}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetReplicationLags returns avg and max replication lag in seconds, counts of
+// applied ops, and documents written over time
+func (ptr *SQLite3DB) GetReplicationLags(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	db := ptr.db
+	durcond := ""
+	var substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	query := fmt.Sprintf(`SELECT %v, IFNULL(AVG(repl_lag_ms)/1000.0, 0), IFNULL(MAX(repl_lag_ms)/1000.0, 0), COUNT(repl_lag_ms),
+		SUM(IFNULL(n_inserted, 0) + IFNULL(n_upserted, 0) + IFNULL(n_modified, 0) + IFNULL(n_deleted, 0)) FROM %v
+		WHERE (repl_lag_ms IS NOT NULL OR n_inserted IS NOT NULL OR n_upserted IS NOT NULL OR n_modified IS NOT NULL
+			OR n_deleted IS NOT NULL) %v GROUP by %v ORDER BY 1;`, substr, ptr.hatchetName, durcond, substr)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TimeSeries
+		var avg, max, applied, written float64
+		if err = rows.Scan(&doc.Date, &avg, &max, &applied, &written); err != nil {
+			return docs, err
+		}
+		doc.Values = []float64{avg, max, applied, written}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetWiredTigerStats returns max checkpoint seconds, counts of eviction
+// warnings and cache pressure messages, and counts of slow ops over time
+func (ptr *SQLite3DB) GetWiredTigerStats(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	db := ptr.db
+	durcond := ""
+	var substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	query := fmt.Sprintf(`SELECT %v, IFNULL(MAX(wt_checkpoint_ms)/1000.0, 0),
+		SUM(CASE WHEN wt_event = '%v' THEN 1 ELSE 0 END), SUM(CASE WHEN wt_event = '%v' THEN 1 ELSE 0 END),
+		SUM(CASE WHEN op != '' THEN 1 ELSE 0 END) FROM %v
+		WHERE (wt_event IS NOT NULL OR op != '') %v GROUP by %v ORDER BY 1;`,
+		substr, WT_EVICTION, WT_CACHE, ptr.hatchetName, durcond, substr)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TimeSeries
+		var checkpoint, evictions, cache, slowops float64
+		if err = rows.Scan(&doc.Date, &checkpoint, &evictions, &cache, &slowops); err != nil {
+			return docs, err
+		}
+		doc.Values = []float64{checkpoint, evictions, cache, slowops}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetLockWaits returns seconds waited acquiring locks, counts of slow ops
+// waited, and counts of slow ops over time
+func (ptr *SQLite3DB) GetLockWaits(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	db := ptr.db
+	durcond := ""
+	var substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	query := fmt.Sprintf(`SELECT %v, IFNULL(SUM(lock_wait_micros), 0)/1000000.0,
+		SUM(CASE WHEN lock_wait_micros > 0 OR lock_wait_count > 0 THEN 1 ELSE 0 END), COUNT(*) FROM %v
+		WHERE op != '' %v GROUP by %v ORDER BY 1;`, substr, ptr.hatchetName, durcond, substr)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TimeSeries
+		var wait, waited, slowops float64
+		if err = rows.Scan(&doc.Date, &wait, &waited, &slowops); err != nil {
+			return docs, err
+		}
+		doc.Values = []float64{wait, waited, slowops}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetChunkMigrationStats returns counts of chunk migrations and failed
+// migrations, max seconds of migrations, and counts of balancer rounds over time
+func (ptr *SQLite3DB) GetChunkMigrationStats(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	db := ptr.db
+	durcond := ""
+	var substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	migrated := fmt.Sprintf("chunk_event IN ('%v', '%v')", CHUNK_MIGRATION_FROM, CHUNK_MIGRATION_TO)
+	query := fmt.Sprintf(`SELECT %v, SUM(CASE WHEN %v THEN 1 ELSE 0 END),
+		SUM(CASE WHEN chunk_event = '%v' THEN 1 ELSE 0 END), IFNULL(MAX(CASE WHEN %v THEN chunk_ms END)/1000.0, 0),
+		SUM(CASE WHEN chunk_event = '%v' THEN 1 ELSE 0 END) FROM %v
+		WHERE chunk_event IS NOT NULL %v GROUP by %v ORDER BY 1;`, substr, migrated, CHUNK_MIGRATION_FAIL, migrated,
+		CHUNK_BALANCER_ROUND, ptr.hatchetName, durcond, substr)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TimeSeries
+		var migrations, failed, seconds, rounds float64
+		if err = rows.Scan(&doc.Date, &migrations, &failed, &seconds, &rounds); err != nil {
+			return docs, err
+		}
+		doc.Values = []float64{migrations, failed, seconds, rounds}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetSeverityCounts returns counts of warnings, errors, and fatal messages over
+// time
+func (ptr *SQLite3DB) GetSeverityCounts(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	db := ptr.db
+	durcond := ""
+	var substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	query := fmt.Sprintf(`SELECT %v, SUM(CASE WHEN severity = 'W' THEN 1 ELSE 0 END),
+		SUM(CASE WHEN severity = 'E' THEN 1 ELSE 0 END), SUM(CASE WHEN severity = 'F' THEN 1 ELSE 0 END) FROM %v
+		WHERE severity IN ('W', 'E', 'F') %v GROUP by %v ORDER BY 1;`, substr, ptr.hatchetName, durcond, substr)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TimeSeries
+		var warnings, errors, fatals float64
+		if err = rows.Scan(&doc.Date, &warnings, &errors, &fatals); err != nil {
+			return docs, err
+		}
+		doc.Values = []float64{warnings, errors, fatals}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetReslenOverTime returns total response MB, avg KB per op, and max KB of
+// ops over time
+func (ptr *SQLite3DB) GetReslenOverTime(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	durcond := ""
+	var substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	query := fmt.Sprintf(`SELECT %v, ROUND(SUM(reslen)/1048576.0, 2), ROUND(AVG(reslen)/1024.0, 1),
+		ROUND(MAX(reslen)/1024.0, 1) FROM %v WHERE reslen > 0 %v GROUP by %v ORDER BY 1;`,
+		substr, ptr.hatchetName, durcond, substr)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TimeSeries
+		var total, avg, max float64
+		if err = rows.Scan(&doc.Date, &total, &avg, &max); err != nil {
+			return docs, err
+		}
+		doc.Values = []float64{total, avg, max}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetStorageReads returns MB read from disk, avg milliseconds reading of slow
+// ops read, and time reading in percent of the duration of slow ops, at most
+// 100 of durations logged in milliseconds, over time
+func (ptr *SQLite3DB) GetStorageReads(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	durcond := ""
+	var substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	query := fmt.Sprintf(`SELECT %v, ROUND(IFNULL(SUM(bytes_read), 0)/1048576.0, 2),
+		ROUND(IFNULL(AVG(CASE WHEN bytes_read > 0 THEN read_micros END), 0)/1000.0, 1),
+		ROUND(CASE WHEN SUM(milli) > 0 THEN MIN(IFNULL(SUM(read_micros), 0)/10.0/SUM(milli), 100) ELSE 0 END, 1) FROM %v
+		WHERE op != '' %v GROUP by %v ORDER BY 1;`, substr, ptr.hatchetName, durcond, substr)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TimeSeries
+		var mb, avg, ratio float64
+		if err = rows.Scan(&doc.Date, &mb, &avg, &ratio); err != nil {
+			return docs, err
+		}
+		doc.Values = []float64{mb, avg, ratio}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetTransactionRates returns counts of committed and aborted transactions and
+// aborted in percent of transactions over time
+func (ptr *SQLite3DB) GetTransactionRates(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	db := ptr.db
+	durcond := ""
+	var substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	query := fmt.Sprintf(`SELECT %v, SUM(CASE WHEN txn_result = '%v' THEN 1 ELSE 0 END),
+		SUM(CASE WHEN txn_result = '%v' THEN 1 ELSE 0 END) FROM %v
+		WHERE txn_result IS NOT NULL %v GROUP by %v ORDER BY 1;`, substr, TXN_COMMITTED, TXN_ABORTED,
+		ptr.hatchetName, durcond, substr)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TimeSeries
+		var committed, aborted float64
+		if err = rows.Scan(&doc.Date, &committed, &aborted); err != nil {
+			return docs, err
+		}
+		doc.Values = []float64{committed, aborted, 100 * aborted / (committed + aborted)}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetTTLDeletes returns documents deleted and milliseconds of passes of TTL
+// indexes and avg milliseconds of slow ops over time
+func (ptr *SQLite3DB) GetTTLDeletes(duration string) ([]TimeSeries, error) {
+	docs := []TimeSeries{}
+	db := ptr.db
+	durcond := ""
+	var substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	query := fmt.Sprintf(`SELECT %v, IFNULL(SUM(ttl_deleted), 0), IFNULL(SUM(ttl_ms), 0),
+		IFNULL(AVG(CASE WHEN op != '' THEN milli END), 0) FROM %v
+		WHERE (ttl_deleted IS NOT NULL OR op != '') %v GROUP by %v ORDER BY 1;`, substr, ptr.hatchetName, durcond, substr)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TimeSeries
+		var deleted, milli, avg float64
+		if err = rows.Scan(&doc.Date, &deleted, &milli, &avg); err != nil {
+			return docs, err
+		}
+		doc.Values = []float64{deleted, milli, avg}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetOpsHeatmap returns counts and total milliseconds of slow ops by
+// namespace and hour, or by day and hour of day
+func (ptr *SQLite3DB) GetOpsHeatmap(layout string, duration string) ([]HeatmapCell, error) {
+	docs := []HeatmapCell{}
+	db := ptr.db
+	durcond := ""
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+	}
+	row, column := "ns", "SUBSTR(date, 1, 13)"
+	if layout == HEATMAP_BY_DAY {
+		row, column = "SUBSTR(date, 1, 10)", "SUBSTR(date, 12, 2)"
+	}
+	query := fmt.Sprintf(`SELECT %v, %v, COUNT(*), SUM(milli) FROM %v
+		WHERE op != '' %v GROUP BY 1, 2 ORDER BY 1, 2;`, row, column, ptr.hatchetName, durcond)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc HeatmapCell
+		if err = rows.Scan(&doc.Row, &doc.Column, &doc.Count, &doc.TotalMilli); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetLockStats returns lock acquisitions of op shapes
+func (ptr *SQLite3DB) GetLockStats() ([]LockStat, error) {
+	docs := []LockStat{}
+	db := ptr.db
+	query := fmt.Sprintf(`SELECT op, ns, filter, COUNT(*), SUM(milli), IFNULL(SUM(lock_global_r), 0),
+		IFNULL(SUM(lock_global_w), 0), IFNULL(SUM(lock_database_r), 0), IFNULL(SUM(lock_database_w), 0),
+		IFNULL(SUM(lock_collection_r), 0), IFNULL(SUM(lock_collection_w), 0), IFNULL(SUM(lock_wait_count), 0),
+		IFNULL(SUM(lock_wait_micros), 0) FROM %v
+		WHERE op != '' AND (lock_global_r IS NOT NULL OR lock_database_r IS NOT NULL OR lock_collection_r IS NOT NULL
+			OR lock_wait_micros IS NOT NULL)
+		GROUP BY op, ns, filter;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc LockStat
+		if err = rows.Scan(&doc.Op, &doc.Namespace, &doc.QueryPattern, &doc.Count, &doc.TotalMilli, &doc.GlobalR,
+			&doc.GlobalW, &doc.DatabaseR, &doc.DatabaseW, &doc.CollectionR, &doc.CollectionW, &doc.WaitCount, &doc.WaitMicros); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetAppStats returns slow ops by application, op, and namespace, ops of
+// connections without an appName are of APP_UNKNOWN
+func (ptr *SQLite3DB) GetAppStats() ([]AppStat, error) {
+	docs := []AppStat{}
+	db := ptr.db
+	query := fmt.Sprintf(`SELECT IFNULL(app_name, '%v'), op, ns, COUNT(*), IFNULL(SUM(milli), 0), IFNULL(MAX(milli), 0)
+		FROM %v WHERE op != ''
+		GROUP BY app_name, op, ns ORDER BY SUM(milli) DESC;`, APP_UNKNOWN, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc AppStat
+		if err = rows.Scan(&doc.AppName, &doc.Op, &doc.Namespace, &doc.Count, &doc.TotalMilli, &doc.MaxMilli); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetElectionEvents returns elections and state changes of a replica set in
+// time order
+func (ptr *SQLite3DB) GetElectionEvents() ([]ElectionEvent, error) {
+	docs := []ElectionEvent{}
+	db := ptr.db
+	likes := []string{}
+	for _, word := range strings.Split(ELECTION_MSG_FILTER, "|") {
+		likes = append(likes, fmt.Sprintf("msg LIKE '%%%v%%'", word))
+	}
+	query := fmt.Sprintf(`SELECT date, component, msg, message FROM %v
+		WHERE component IN ('REPL', 'ELECTION') AND (%v) ORDER BY id;`, ptr.hatchetName, strings.Join(likes, " OR "))
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var date, component, msg, message string
+		if err = rows.Scan(&date, &component, &msg, &message); err != nil {
+			return docs, err
+		}
+		if event, ok := GetElectionEvent(date, component, msg, message); ok {
+			docs = append(docs, event)
+		}
+	}
+	return docs, err
+}
+
+// GetConfigChanges returns setParameter, featureCompatibilityVersion, and
+// commit quorum changes in time order
+func (ptr *SQLite3DB) GetConfigChanges() ([]ConfigChange, error) {
+	docs := []ConfigChange{}
+	db := ptr.db
+	query := fmt.Sprintf(`SELECT date, config_change, IFNULL(config_name, ''), IFNULL(config_old, ''),
+		IFNULL(config_new, '') FROM %v WHERE config_change IS NOT NULL ORDER BY id;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc ConfigChange
+		if err = rows.Scan(&doc.Date, &doc.Type, &doc.Name, &doc.OldValue, &doc.NewValue); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetChunkEvents returns chunk migrations, splits, and balancer rounds in
+// time order
+func (ptr *SQLite3DB) GetChunkEvents() ([]ChunkEvent, error) {
+	docs := []ChunkEvent{}
+	db := ptr.db
+	query := fmt.Sprintf(`SELECT date, IFNULL(ns, ''), chunk_event, IFNULL(chunk_ms, 0) FROM %v
+		WHERE chunk_event IS NOT NULL ORDER BY id;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc ChunkEvent
+		if err = rows.Scan(&doc.Date, &doc.Namespace, &doc.Type, &doc.Milli); err != nil {
 			return docs, err
 		}
 		docs = append(docs, doc)
@@ -194,25 +877,45 @@ func (ptr *SQLite3DB) GetSlowestLogs(topN int) ([]LegacyLog, error) {
 	return docs, err
 }
 
-func (ptr *SQLite3DB) GetAverageOpTime(op string, duration string) ([]OpCount, error) {
-	docs := []OpCount{}
+// GetTransactionStats returns multi-document transactions by termination and
+// cause of aborts
+func (ptr *SQLite3DB) GetTransactionStats() ([]TxnStat, error) {
+	docs := []TxnStat{}
 	db := ptr.db
-	durcond := ""
-	var substr string
-	opcond := "op != ''"
-	if op != "" {
-		opcond = fmt.Sprintf("op = '%v'", op)
+	query := fmt.Sprintf(`SELECT txn_result, IFNULL(txn_cause, ''), COUNT(*), IFNULL(SUM(txn_ms), 0), IFNULL(MAX(txn_ms), 0),
+		IFNULL(SUM(txn_ops), 0) FROM %v WHERE txn_result IS NOT NULL
+		GROUP BY txn_result, txn_cause ORDER BY COUNT(*) DESC;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TxnStat
+		if err = rows.Scan(&doc.Result, &doc.Cause, &doc.Count, &doc.TotalMilli, &doc.MaxMilli, &doc.Ops); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
 	}
+	return docs, err
+}
+
+// GetSlowOpsByShape returns slow ops by op, namespace, query shape, and sort
+// shape in a duration, of all logs if empty, the most total ms first
+func (ptr *SQLite3DB) GetSlowOpsByShape(duration string) ([]OpStat, error) {
+	docs := []OpStat{}
+	db := ptr.db
+	durcond := ""
 	if duration != "" {
 		toks := strings.Split(duration, ",")
 		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
-		substr = GetSQLDateSubString(toks[0], toks[1])
-	} else {
-		info := ptr.GetHatchetInfo()
-		substr = GetSQLDateSubString(info.Start, info.End)
 	}
-	query := fmt.Sprintf(`SELECT %v, AVG(milli), COUNT(*), op, ns, filter FROM %v 
-		WHERE %v %v GROUP by %v, op, ns, filter;`, substr, ptr.hatchetName, opcond, durcond, substr)
+	query := fmt.Sprintf(`SELECT op, ns, filter, IFNULL(sort, ''), COUNT(*), AVG(milli), MAX(milli), SUM(milli),
+		IFNULL(SUM(reslen), 0), MIN(date), MAX(date) FROM %v WHERE op != '' %v
+		GROUP BY 1, 2, 3, 4 ORDER BY SUM(milli) DESC;`, ptr.hatchetName, durcond)
 	if ptr.verbose {
 		log.Println(query)
 	}
@@ -222,8 +925,313 @@ func (ptr *SQLite3DB) GetAverageOpTime(op string, duration string) ([]OpCount, e
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var doc OpCount
-		if err = rows.Scan(&doc.Date, &doc.Milli, &doc.Count, &doc.Op, &doc.Namespace, &doc.Filter); err != nil {
+		var doc OpStat
+		if err = rows.Scan(&doc.Op, &doc.Namespace, &doc.QueryPattern, &doc.SortPattern, &doc.Count, &doc.AvgMilli,
+			&doc.MaxMilli, &doc.TotalMilli, &doc.Reslen, &doc.FirstSeen, &doc.LastSeen); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetSeverityStats returns warnings, errors, and fatal messages by severity,
+// component, and log id, or by message if logged in legacy format, with the
+// first and last occurrences
+func (ptr *SQLite3DB) GetSeverityStats() ([]SeverityStat, error) {
+	docs := []SeverityStat{}
+	db := ptr.db
+	query := fmt.Sprintf(`SELECT severity, component, IFNULL(log_id, 0), MAX(msg), COUNT(*), MIN(date), MAX(date)
+		FROM %v WHERE severity IN ('W', 'E', 'F')
+		GROUP BY severity, component, IFNULL(log_id, msg) ORDER BY COUNT(*) DESC;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc SeverityStat
+		if err = rows.Scan(&doc.Severity, &doc.Component, &doc.LogID, &doc.Msg, &doc.Count, &doc.First, &doc.Last); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetPipelineStats returns slow aggregate pipelines by namespace and stages
+func (ptr *SQLite3DB) GetPipelineStats() ([]PipelineStat, error) {
+	docs := []PipelineStat{}
+	db := ptr.db
+	query := fmt.Sprintf(`SELECT ns, stages, COUNT(*), IFNULL(SUM(milli), 0), IFNULL(MAX(milli), 0) FROM %v
+		WHERE stages IS NOT NULL AND op != ''
+		GROUP BY ns, stages ORDER BY SUM(milli) DESC;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc PipelineStat
+		if err = rows.Scan(&doc.Namespace, &doc.Stages, &doc.Count, &doc.TotalMilli, &doc.MaxMilli); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetPlanningStats returns planning times of op shapes of more than one
+// execution logging planningTimeMicros
+func (ptr *SQLite3DB) GetPlanningStats() ([]PlanningStat, error) {
+	docs := []PlanningStat{}
+	db := ptr.db
+	query := fmt.Sprintf(`SELECT op, ns, filter, COUNT(*), MIN(planning_micros), MAX(planning_micros),
+		AVG(planning_micros), AVG(planning_micros * planning_micros) FROM %v
+		WHERE op != '' AND planning_micros IS NOT NULL
+		GROUP BY op, ns, filter HAVING COUNT(*) > 1;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc PlanningStat
+		var avgSquare float64
+		if err = rows.Scan(&doc.Op, &doc.Namespace, &doc.QueryPattern, &doc.Count, &doc.MinMicros, &doc.MaxMicros,
+			&doc.AvgMicros, &avgSquare); err != nil {
+			return docs, err
+		}
+		doc.StdDevMicros = getStdDev(doc.AvgMicros, avgSquare)
+		docs = append(docs, doc)
+	}
+	SortPlanningStats(docs)
+	return docs, err
+}
+
+// GetGetMoreStats returns latencies of initial executions and getMores of
+// shapes iterated by getMores, the most getMore milliseconds first
+func (ptr *SQLite3DB) GetGetMoreStats() ([]GetMoreStat, error) {
+	docs := []GetMoreStat{}
+	query := fmt.Sprintf(`SELECT ns, filter, origin, SUM(op != 'getMore'),
+			IFNULL(AVG(CASE WHEN op != 'getMore' THEN milli END), 0), IFNULL(MAX(CASE WHEN op != 'getMore' THEN milli END), 0),
+			SUM(op = 'getMore'), IFNULL(AVG(CASE WHEN op = 'getMore' THEN milli END), 0),
+			IFNULL(MAX(CASE WHEN op = 'getMore' THEN milli END), 0), IFNULL(SUM(CASE WHEN op = 'getMore' THEN milli END), 0),
+			COUNT(DISTINCT CASE WHEN op = 'getMore' THEN cursor_id END)
+		FROM (SELECT ns, filter, op, milli, cursor_id, CASE WHEN op = 'getMore' THEN IFNULL(originating_op, '') ELSE op END origin
+			FROM %v WHERE op IN ('find', 'aggregate', 'getMore'))
+		GROUP BY ns, filter, origin HAVING SUM(op = 'getMore') > 0;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc GetMoreStat
+		if err = rows.Scan(&doc.Namespace, &doc.QueryPattern, &doc.OriginatingOp, &doc.InitialCount, &doc.InitialAvg,
+			&doc.InitialMax, &doc.GetMoreCount, &doc.GetMoreAvg, &doc.GetMoreMax, &doc.GetMoreTotalMs, &doc.Cursors); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	SortGetMoreStats(docs)
+	return docs, err
+}
+
+// GetSortStageStats returns op shapes of blocking sorts or spills to disk,
+// the most spills first
+func (ptr *SQLite3DB) GetSortStageStats() ([]BlockingSortStat, error) {
+	docs := []BlockingSortStat{}
+	query := fmt.Sprintf(`SELECT op, ns, filter, IFNULL(sort, ''), COUNT(*), SUM(has_sort_stage), SUM(used_disk), AVG(milli)
+		FROM %v WHERE op != '' AND (has_sort_stage = 1 OR used_disk = 1)
+		GROUP BY op, ns, filter, sort;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc BlockingSortStat
+		if err = rows.Scan(&doc.Op, &doc.Namespace, &doc.QueryPattern, &doc.SortPattern, &doc.Count, &doc.Sorts,
+			&doc.Spills, &doc.AvgMilli); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	SortBlockingSortStats(docs)
+	return docs, err
+}
+
+// GetTargetingStats returns keys and documents examined and documents
+// returned by op shapes, the worst targeting first
+func (ptr *SQLite3DB) GetTargetingStats() ([]TargetingStat, error) {
+	docs := []TargetingStat{}
+	query := fmt.Sprintf(`SELECT op, ns, filter, COUNT(*), SUM(IFNULL(docs_examined, 0)), SUM(IFNULL(keys_examined, 0)),
+		SUM(nreturned) FROM %v WHERE op != '' AND nreturned IS NOT NULL
+		GROUP BY op, ns, filter;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc TargetingStat
+		if err = rows.Scan(&doc.Op, &doc.Namespace, &doc.QueryPattern, &doc.Count, &doc.DocsExamined, &doc.KeysExamined,
+			&doc.Returned); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	SortTargetingStats(docs)
+	return docs, err
+}
+
+// GetReplanStats returns op shapes of replanned executions and plan cache
+// evictions of namespaces
+func (ptr *SQLite3DB) GetReplanStats() ([]ReplanStat, error) {
+	docs := []ReplanStat{}
+	db := ptr.db
+	query := fmt.Sprintf(`SELECT op, ns, filter, COUNT(*), COUNT(replan_reason),
+		IFNULL(AVG(CASE WHEN replan_reason IS NULL THEN milli END), 0),
+		IFNULL(AVG(CASE WHEN replan_reason IS NOT NULL THEN milli END), 0),
+		IFNULL(MAX(CASE WHEN replan_reason IS NOT NULL THEN milli END), 0),
+		IFNULL(SUM(CASE WHEN replan_reason IS NOT NULL THEN milli END), 0), IFNULL(MAX(replan_reason), '')
+		FROM %v WHERE op != ''
+		GROUP BY op, ns, filter HAVING COUNT(replan_reason) > 0;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	for rows.Next() {
+		var doc ReplanStat
+		if err = rows.Scan(&doc.Op, &doc.Namespace, &doc.QueryPattern, &doc.Count, &doc.Replans, &doc.AvgMilli,
+			&doc.AvgReplanMilli, &doc.MaxReplanMilli, &doc.TotalReplanMilli, &doc.Reason); err != nil {
+			rows.Close()
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	rows.Close()
+	query = fmt.Sprintf(`SELECT IFNULL(ns, ''), COUNT(*) FROM %v WHERE IFNULL(op, '') = '' AND replan_reason IS NOT NULL
+		GROUP BY ns;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	if rows, err = db.Query(query); err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	evictions := map[string]int{}
+	for rows.Next() {
+		var ns string
+		var count int
+		if err = rows.Scan(&ns, &count); err != nil {
+			return docs, err
+		}
+		evictions[ns] = count
+	}
+	return MergeReplanEvictions(docs, evictions), err
+}
+
+// GetShardingStats returns shard targeting of op shapes routed by a mongos
+func (ptr *SQLite3DB) GetShardingStats() ([]ShardingStat, error) {
+	docs := []ShardingStat{}
+	db := ptr.db
+	query := fmt.Sprintf(`SELECT op, ns, filter, COUNT(*), MIN(n_shards), MAX(n_shards), AVG(n_shards),
+		SUM(CASE WHEN n_shards > 1 AND n_shards >= (SELECT MAX(n_shards) FROM %[1]v) THEN 1 ELSE 0 END),
+		IFNULL(GROUP_CONCAT(DISTINCT NULLIF(shards, '')), '') FROM %[1]v
+		WHERE op != '' AND n_shards IS NOT NULL
+		GROUP BY op, ns, filter;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc ShardingStat
+		var shards string
+		if err = rows.Scan(&doc.Op, &doc.Namespace, &doc.QueryPattern, &doc.Count, &doc.MinShards, &doc.MaxShards,
+			&doc.AvgShards, &doc.AllShards, &shards); err != nil {
+			return docs, err
+		}
+		doc.SetShards([]string{shards})
+		docs = append(docs, doc)
+	}
+	SortShardingStats(docs)
+	return docs, err
+}
+
+// GetSourceStats returns counts of lines by sources of merged logs
+func (ptr *SQLite3DB) GetSourceStats() ([]SourceStat, error) {
+	docs := []SourceStat{}
+	db := ptr.db
+	query := fmt.Sprintf(`SELECT source, COUNT(*), SUM(CASE WHEN op != '' THEN 1 ELSE 0 END),
+		SUM(CASE WHEN severity = 'W' THEN 1 ELSE 0 END), SUM(CASE WHEN severity IN ('F', 'E') THEN 1 ELSE 0 END),
+		MIN(date), MAX(date) FROM %v WHERE source IS NOT NULL GROUP BY source ORDER BY source;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc SourceStat
+		if err = rows.Scan(&doc.Source, &doc.Lines, &doc.SlowOps, &doc.Warnings, &doc.Errors,
+			&doc.Start, &doc.End); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// GetWriteStats returns documents written by op shapes, ordered by documents modified
+func (ptr *SQLite3DB) GetWriteStats() ([]WriteStat, error) {
+	docs := []WriteStat{}
+	db := ptr.db
+	query := fmt.Sprintf(`SELECT op, ns, filter, COUNT(*), IFNULL(SUM(n_matched), 0), IFNULL(SUM(n_modified), 0),
+		IFNULL(SUM(n_inserted), 0), IFNULL(SUM(n_upserted), 0), IFNULL(SUM(n_deleted), 0) FROM %v
+		WHERE op != '' AND (n_matched IS NOT NULL OR n_modified IS NOT NULL OR n_inserted IS NOT NULL
+			OR n_upserted IS NOT NULL OR n_deleted IS NOT NULL)
+		GROUP BY op, ns, filter ORDER BY 6 DESC, 4 DESC;`, ptr.hatchetName)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc WriteStat
+		if err = rows.Scan(&doc.Op, &doc.Namespace, &doc.QueryPattern, &doc.Count, &doc.Matched, &doc.Modified,
+			&doc.Inserted, &doc.Upserted, &doc.Deleted); err != nil {
 			return docs, err
 		}
 		docs = append(docs, doc)
@@ -233,8 +1241,8 @@ func (ptr *SQLite3DB) GetAverageOpTime(op string, duration string) ([]OpCount, e
 
 func (ptr *SQLite3DB) GetHatchetInfo() HatchetInfo {
 	var info HatchetInfo
-	query := fmt.Sprintf("SELECT name, version, module, os, arch, start, end FROM hatchet WHERE name = '%v'",
-		ptr.hatchetName)
+	query := fmt.Sprintf(`SELECT name, version, module, os, arch, start, end, IFNULL(slow_ms, 0), IFNULL(slow_ns, '')
+		FROM hatchet WHERE name = '%v'`, ptr.hatchetName)
 	db := ptr.db
 	rows, err := db.Query(query)
 	if err != nil {
@@ -242,7 +1250,7 @@ func (ptr *SQLite3DB) GetHatchetInfo() HatchetInfo {
 	}
 	if rows.Next() {
 		if err = rows.Scan(&info.Name, &info.Version, &info.Module, &info.OS, &info.Arch,
-			&info.Start, &info.End); err != nil {
+			&info.Start, &info.End, &info.SlowMilli, &info.SlowNS); err != nil {
 			return info
 		}
 	}
@@ -341,6 +1349,33 @@ func (ptr *SQLite3DB) GetAcceptedConnsCounts(duration string) ([]NameValue, erro
 	return docs, err
 }
 
+// GetConnectionEvents returns connections accepted and ended in time order
+func (ptr *SQLite3DB) GetConnectionEvents() ([]ConnEvent, error) {
+	hatchetName := ptr.hatchetName
+	docs := []ConnEvent{}
+	query := fmt.Sprintf(`SELECT a.date, b.ip, b.port, b.accepted
+		FROM %v a, %v_clients b WHERE a.id = b.id ORDER BY a.id;`, hatchetName, hatchetName)
+	db := ptr.db
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc ConnEvent
+		var accepted int
+		if err = rows.Scan(&doc.Date, &doc.IP, &doc.Port, &accepted); err != nil {
+			return docs, err
+		}
+		doc.Accepted = accepted > 0
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
 // GetConnectionStats returns stats data of accepted and ended
 func (ptr *SQLite3DB) GetConnectionStats(chartType string, duration string) ([]RemoteClient, error) {
 	hatchetName := ptr.hatchetName
@@ -387,6 +1422,41 @@ func (ptr *SQLite3DB) GetConnectionStats(chartType string, duration string) ([]R
 	return docs, err
 }
 
+// GetConnectionsByIP returns connections accepted and ended of IPs by
+// periods in order of dates
+func (ptr *SQLite3DB) GetConnectionsByIP(duration string) ([]IPConns, error) {
+	hatchetName := ptr.hatchetName
+	docs := []IPConns{}
+	var durcond, substr string
+	if duration != "" {
+		toks := strings.Split(duration, ",")
+		durcond = fmt.Sprintf("AND date BETWEEN '%v' AND '%v'", toks[0], toks[1])
+		substr = GetSQLDateSubString(toks[0], toks[1])
+	} else {
+		info := ptr.GetHatchetInfo()
+		substr = GetSQLDateSubString(info.Start, info.End)
+	}
+	query := fmt.Sprintf(`SELECT %v dt, b.ip, SUM(b.accepted), SUM(b.ended)
+		FROM %v a, %v_clients b WHERE a.id = b.id %v GROUP BY dt, b.ip ORDER BY dt, b.ip`,
+		substr, hatchetName, hatchetName, durcond)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc IPConns
+		if err = rows.Scan(&doc.Date, &doc.IP, &doc.Accepted, &doc.Ended); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
 // GetOpsCounts returns opened connection counts
 func (ptr *SQLite3DB) GetOpsCounts(duration string) ([]NameValue, error) {
 	docs := []NameValue{}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sqlite3_test.go
+ */
+
+package hatchet
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSQLite3BatchSize(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "batch.db")
+	db, err := sql.Open("sqlite3", dbfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbase := &SQLite3DB{db: db, dbfile: dbfile, hatchetName: "batch_test"}
+	defer dbase.Close()
+	dbase.SetBatchSize(0)
+	if dbase.batchSize != SQLITE_BATCH_SIZE {
+		t.Fatal("expected", SQLITE_BATCH_SIZE, "but got", dbase.batchSize)
+	}
+	dbase.SetBatchSize(2)
+	if err = dbase.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 5; i++ {
+		doc := Logv2Info{Severity: "I", Component: "NETWORK", Msg: "Connection accepted"}
+		if err = dbase.InsertLog(i, "2023-01-01T00:00:00.000-0000", &doc, &OpStat{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reader, err := sql.Open("sqlite3", dbfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	count := func() int {
+		var n int
+		if err := reader.QueryRow("SELECT COUNT(*) FROM batch_test").Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := count(); n != 4 {
+		t.Fatal("expected", 4, "lines committed in batches of 2", "but got", n)
+	}
+	if err = dbase.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 5 {
+		t.Fatal("expected", 5, "but got", n)
+	}
+}
+
+func TestSQLite3Ingests(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "ingests.db")
+	db, err := sql.Open("sqlite3", dbfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbase := &SQLite3DB{db: db, dbfile: dbfile, hatchetName: "mongod_1"}
+	defer dbase.Close()
+	if _, err = db.Exec("CREATE TABLE hatchet (name text not null primary key);" + GetMigrationsInitStmt()); err != nil {
+		t.Fatal(err)
+	}
+	if doc, err := dbase.GetIngest("/logs/mongod.log"); err != nil || doc.Name != "" {
+		t.Fatal("expected", "no ingest", "but got", doc, err)
+	}
+	if err = dbase.SaveIngest(Ingest{Logname: "/logs/mongod.log", Offset: 100, Lines: 2, Updated: "2023-01-01T00:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+	dbase.hatchetName = "mongod_2"
+	if err = dbase.SaveIngest(Ingest{Logname: "/logs/mongod.log", Offset: 300, Lines: 5, Updated: "2023-01-02T00:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := dbase.GetIngest("/logs/mongod.log")
+	if err != nil || doc.Name != "mongod_2" || doc.Offset != 300 || doc.Lines != 5 {
+		t.Fatal("expected", "mongod_2 at offset 300", "but got", doc, err)
+	}
+	if err = dbase.Drop(); err != nil {
+		t.Fatal(err)
+	}
+	if doc, err = dbase.GetIngest("/logs/mongod.log"); err != nil || doc.Name != "mongod_1" {
+		t.Fatal("expected", "mongod_1", "but got", doc, err)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sqlite3_trace.go
+ */
+
+package hatchet
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// GetConnectionLogs returns logs of the connection of a log, from the
+// connection accepted to the connection ended around the log
+func (ptr *SQLite3DB) GetConnectionLogs(id int) ([]TraceLog, error) {
+	var conn string
+	query := fmt.Sprintf(`SELECT context FROM %v WHERE id = %v`, ptr.hatchetName, id)
+	if err := ptr.db.QueryRow(query).Scan(&conn); err != nil {
+		return nil, err
+	}
+	accepted, start, end := -1, id, id
+	if connID := GetConnectionID(conn); connID > 0 {
+		var acceptedID, endedID, nextEndedID sql.NullInt64
+		query = fmt.Sprintf(`SELECT
+			(SELECT MAX(id) FROM %[1]v WHERE id < %[2]v AND msg = 'Connection accepted' AND message LIKE '%%#%[3]v (%%'),
+			(SELECT MAX(id) FROM %[1]v WHERE id < %[2]v AND context = '%[4]v' AND msg = 'Connection ended'),
+			(SELECT MIN(id) FROM %[1]v WHERE id > %[2]v AND context = '%[4]v' AND msg = 'Connection ended')`,
+			ptr.hatchetName, id, connID, conn)
+		if ptr.verbose {
+			log.Println(query)
+		}
+		if err := ptr.db.QueryRow(query).Scan(&acceptedID, &endedID, &nextEndedID); err != nil {
+			return nil, err
+		}
+		start = 0
+		if endedID.Valid { // connection ids are reused after restarts
+			start = int(endedID.Int64) + 1
+		}
+		if acceptedID.Valid && int(acceptedID.Int64) >= start {
+			accepted, start = int(acceptedID.Int64), int(acceptedID.Int64)
+		}
+		end = -1
+		if nextEndedID.Valid {
+			end = int(nextEndedID.Int64)
+		}
+	}
+	cond := fmt.Sprintf("id = %v OR (context = '%v' AND id >= %v", accepted, conn, start)
+	if end >= 0 {
+		cond += fmt.Sprintf(" AND id <= %v", end)
+	}
+	cond += ")"
+	query = fmt.Sprintf(`SELECT id, date, severity, component, context, msg, message, op, ns,
+			COALESCE(filter, ''), COALESCE(sort, ''), COALESCE(_index, ''), COALESCE(plan, ''), %v
+		FROM %v WHERE %v ORDER BY id`, strings.Join(TRACE_METRICS, ", "), ptr.hatchetName, cond)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	docs := []TraceLog{}
+	for rows.Next() {
+		var doc TraceLog
+		metrics := make([]sql.NullInt64, len(TRACE_METRICS))
+		fields := []interface{}{&doc.ID, &doc.Date, &doc.Severity, &doc.Component, &doc.Context, &doc.Msg,
+			&doc.Message, &doc.Op, &doc.Namespace, &doc.QueryPattern, &doc.SortPattern, &doc.Index, &doc.Plan}
+		for i := range metrics {
+			fields = append(fields, &metrics[i])
+		}
+		if err = rows.Scan(fields...); err != nil {
+			return docs, err
+		}
+		doc.Metrics = map[string]int{}
+		for i, metric := range metrics {
+			if metric.Valid && (doc.Op != "" || metric.Int64 != 0) {
+				doc.Metrics[TRACE_METRICS[i]] = int(metric.Int64)
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
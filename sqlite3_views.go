@@ -0,0 +1,63 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * sqlite3_views.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"log"
+)
+
+// createViewsTable creates the views table if not exists, views are shared
+// by all hatchets
+func (ptr *SQLite3DB) createViewsTable() error {
+	_, err := ptr.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		name text not null primary key, path text, query text, date text);`, VIEWS_TABLE))
+	return err
+}
+
+// GetViews returns saved views sorted by names
+func (ptr *SQLite3DB) GetViews() ([]SavedView, error) {
+	docs := []SavedView{}
+	if err := ptr.createViewsTable(); err != nil {
+		return docs, err
+	}
+	query := fmt.Sprintf(`SELECT name, path, query, date FROM %v ORDER BY name;`, VIEWS_TABLE)
+	if ptr.verbose {
+		log.Println(query)
+	}
+	rows, err := ptr.db.Query(query)
+	if err != nil {
+		return docs, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc SavedView
+		if err = rows.Scan(&doc.Name, &doc.Path, &doc.Query, &doc.Date); err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, err
+}
+
+// SaveView inserts or replaces a view
+func (ptr *SQLite3DB) SaveView(doc SavedView) error {
+	if err := ptr.createViewsTable(); err != nil {
+		return err
+	}
+	_, err := ptr.db.Exec(fmt.Sprintf(`INSERT OR REPLACE INTO %v (name, path, query, date) VALUES(?,?,?,?);`,
+		VIEWS_TABLE), doc.Name, doc.Path, doc.Query, doc.Date)
+	return err
+}
+
+// DeleteView removes a view
+func (ptr *SQLite3DB) DeleteView(name string) error {
+	if err := ptr.createViewsTable(); err != nil {
+		return err
+	}
+	_, err := ptr.db.Exec(fmt.Sprintf(`DELETE FROM %v WHERE name = ?;`, VIEWS_TABLE), name)
+	return err
+}
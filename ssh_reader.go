@@ -0,0 +1,110 @@
+//go:build ssh
+
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * ssh_reader.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sshReader reads the output of a remote command of a session
+type sshReader struct {
+	client  *ssh.Client
+	eof     bool
+	session *ssh.Session
+	stdout  io.Reader
+}
+
+// Read reads the output of the remote command
+func (ptr *sshReader) Read(p []byte) (int, error) {
+	n, err := ptr.stdout.Read(p)
+	if err == io.EOF {
+		ptr.eof = true
+	}
+	return n, err
+}
+
+// Close waits for the remote command if its output was read to the end, then
+// closes the session and the client
+func (ptr *sshReader) Close() error {
+	var err error
+	if ptr.eof {
+		err = ptr.session.Wait()
+	}
+	ptr.session.Close()
+	if cerr := ptr.client.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// GetSSHContent streams a remote log file over SSH using the user's SSH agent
+// or keys, the caller is responsible for closing it
+func GetSSHContent(source string) (io.ReadCloser, error) {
+	username, host, path, err := ParseSSHSource(source)
+	if err != nil {
+		return nil, err
+	}
+	home, _ := os.UserHomeDir()
+	hostKeyCallback, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %v", err)
+	}
+	config := &ssh.ClientConfig{User: username, Auth: getSSHAuthMethods(home), HostKeyCallback: hostKeyCallback}
+	client, err := ssh.Dial("tcp", host, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %v: %v", host, err)
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		client.Close()
+		return nil, err
+	}
+	if err = session.Start("cat '" + strings.ReplaceAll(path, "'", `'\''`) + "'"); err != nil {
+		session.Close()
+		client.Close()
+		return nil, err
+	}
+	return &sshReader{client: client, session: session, stdout: stdout}, nil
+}
+
+// getSSHAuthMethods returns SSH agent and default private keys
+func getSSHAuthMethods(home string) []ssh.AuthMethod {
+	methods := []ssh.AuthMethod{}
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+	signers := []ssh.Signer{}
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		if key, err := os.ReadFile(filepath.Join(home, ".ssh", name)); err == nil {
+			if signer, err := ssh.ParsePrivateKey(key); err == nil {
+				signers = append(signers, signer)
+			}
+		}
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	return methods
+}
@@ -0,0 +1,18 @@
+//go:build !ssh
+
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * ssh_reader_stub.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"io"
+)
+
+// GetSSHContent is not available without the ssh build tag
+func GetSSHContent(source string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("reading %v requires hatchet built with -tags ssh", source)
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * stages.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// STAGE_DOMINANT_PERCENT is the percent of slow pipeline time of a namespace
+// spent in pipelines of a stage to flag the stage dominant
+const STAGE_DOMINANT_PERCENT = 50
+
+// HEAVY_STAGES are stages that may dominate a pipeline
+var HEAVY_STAGES = []string{"$bucket", "$bucketAuto", "$facet", "$graphLookup", "$group", "$lookup",
+	"$setWindowFields", "$sort", "$unionWith", "$unwind"}
+
+// PipelineStat stores slow aggregate pipelines of a namespace and sequence of
+// stages
+type PipelineStat struct {
+	Count      int    `json:"count" bson:"count"`
+	MaxMilli   int    `json:"max_ms" bson:"max_ms"`
+	Namespace  string `json:"ns" bson:"ns"`
+	Stages     string `json:"stages" bson:"stages"` // comma separated stages in order
+	TotalMilli int    `json:"total_ms" bson:"total_ms"`
+}
+
+// StageStat stores slow aggregate pipelines of a namespace with a stage
+type StageStat struct {
+	Count        int     `json:"count" bson:"count"` // pipelines with the stage
+	MaxMilli     int     `json:"max_ms" bson:"max_ms"`
+	NSTotalMilli int     `json:"ns_total_ms" bson:"ns_total_ms"` // slow pipeline time of the namespace
+	Namespace    string  `json:"ns" bson:"ns"`
+	Occurrences  int     `json:"occurrences" bson:"occurrences"` // stages of the pipelines
+	Percent      float64 `json:"percent" bson:"percent"`         // percent of slow pipeline time of the namespace
+	Pipelines    int     `json:"pipelines" bson:"pipelines"`     // slow pipelines of the namespace
+	Stage        string  `json:"stage" bson:"stage"`
+	TotalMilli   int     `json:"total_ms" bson:"total_ms"`
+}
+
+// GetPipelineStages returns comma separated stages of the pipeline of a slow
+// aggregate, or of the originating aggregate of a getMore, false otherwise
+func GetPipelineStages(doc *Logv2Info) (string, bool) {
+	attr := doc.Attr.Map()
+	command, ok := attr["command"].(bson.D)
+	if !ok {
+		return "", false
+	}
+	if _, isGetMore := command.Map()[cmdGetMore]; isGetMore {
+		if command, ok = attr["originatingCommand"].(bson.D); !ok {
+			return "", false
+		}
+	}
+	if _, ok = command.Map()[cmdAggregate]; !ok {
+		return "", false
+	}
+	pipeline, ok := command.Map()["pipeline"].(bson.A)
+	if !ok || len(pipeline) == 0 {
+		return "", false
+	}
+	stages := []string{}
+	for _, stage := range pipeline {
+		if d, isDoc := stage.(bson.D); isDoc && len(d) > 0 {
+			stages = append(stages, d[0].Key)
+		}
+	}
+	return strings.Join(stages, ","), len(stages) > 0
+}
+
+// GetStageStats returns stages of slow pipelines by namespace, namespaces of
+// the most slow pipeline time first and then stages of the most time
+func GetStageStats(pipelines []PipelineStat) []StageStat {
+	stats := map[string]*StageStat{}
+	totals := map[string]int{}
+	counts := map[string]int{}
+	for _, pipeline := range pipelines {
+		totals[pipeline.Namespace] += pipeline.TotalMilli
+		counts[pipeline.Namespace] += pipeline.Count
+		seen := map[string]bool{}
+		for _, stage := range strings.Split(pipeline.Stages, ",") {
+			key := pipeline.Namespace + "\t" + stage
+			stat, ok := stats[key]
+			if !ok {
+				stat = &StageStat{Namespace: pipeline.Namespace, Stage: stage}
+				stats[key] = stat
+			}
+			stat.Occurrences += pipeline.Count
+			if seen[stage] {
+				continue
+			}
+			seen[stage] = true
+			stat.Count += pipeline.Count
+			stat.TotalMilli += pipeline.TotalMilli
+			if pipeline.MaxMilli > stat.MaxMilli {
+				stat.MaxMilli = pipeline.MaxMilli
+			}
+		}
+	}
+	docs := []StageStat{}
+	for _, stat := range stats {
+		stat.NSTotalMilli = totals[stat.Namespace]
+		stat.Pipelines = counts[stat.Namespace]
+		if stat.NSTotalMilli > 0 {
+			stat.Percent = 100 * float64(stat.TotalMilli) / float64(stat.NSTotalMilli)
+		}
+		docs = append(docs, *stat)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].Namespace != docs[j].Namespace {
+			if docs[i].NSTotalMilli != docs[j].NSTotalMilli {
+				return docs[i].NSTotalMilli > docs[j].NSTotalMilli
+			}
+			return docs[i].Namespace < docs[j].Namespace
+		}
+		if docs[i].TotalMilli != docs[j].TotalMilli {
+			return docs[i].TotalMilli > docs[j].TotalMilli
+		}
+		return docs[i].Stage < docs[j].Stage
+	})
+	return docs
+}
+
+// GetLookupHeavyNamespaces returns namespaces of which pipelines with $lookup
+// or $graphLookup take most of the slow pipeline time
+func GetLookupHeavyNamespaces(stats []StageStat) []string {
+	namespaces := []string{}
+	for _, stat := range stats {
+		if (stat.Stage == "$lookup" || stat.Stage == "$graphLookup") && stat.IsDominant() {
+			found := false
+			for _, ns := range namespaces {
+				found = found || ns == stat.Namespace
+			}
+			if !found {
+				namespaces = append(namespaces, stat.Namespace)
+			}
+		}
+	}
+	return namespaces
+}
+
+// GetAvgMilli returns the average milliseconds of pipelines with the stage
+func (ptr *StageStat) GetAvgMilli() float64 {
+	if ptr.Count == 0 {
+		return 0
+	}
+	return float64(ptr.TotalMilli) / float64(ptr.Count)
+}
+
+// GetPerPipeline returns the average occurrences of the stage in a pipeline
+func (ptr *StageStat) GetPerPipeline() float64 {
+	if ptr.Count == 0 {
+		return 0
+	}
+	return float64(ptr.Occurrences) / float64(ptr.Count)
+}
+
+// IsDominant returns true if a heavy stage is in pipelines taking at least
+// STAGE_DOMINANT_PERCENT of slow pipeline time of the namespace
+func (ptr *StageStat) IsDominant() bool {
+	for _, stage := range HEAVY_STAGES {
+		if stage == ptr.Stage {
+			return ptr.Percent >= STAGE_DOMINANT_PERCENT
+		}
+	}
+	return false
+}
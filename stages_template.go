@@ -0,0 +1,77 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * stages_template.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetStagesTemplate returns HTML
+func GetStagesTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Stages}}
+	{{if .LookupHeavy}}
+	<div class='btn'><span style='color: red'>$lookup heavy namespaces: {{join .LookupHeavy ", "}}</span></div>
+	{{end}}
+	<table width='100%'>
+		<caption>Aggregation Stages by Namespace</caption>
+		<tr><th>namespace</th><th>stage</th><th>pipelines</th><th>per pipeline</th><th>avg ms</th><th>max ms</th>
+			<th>total ms</th><th>% of time</th></tr>
+	{{range $s := .Stages}}
+		<tr><td>{{$s.Namespace}}</td><td>{{$s.Stage}}</td>
+			<td align='right'>{{numPrinter $s.Count}} / {{numPrinter $s.Pipelines}}</td>
+			<td align='right'>{{formatFloat $s.GetPerPipeline}}</td>
+			<td align='right'>{{printf "%.0f" $s.GetAvgMilli}}</td>
+			<td align='right'>{{numPrinter $s.MaxMilli}}</td>
+			<td align='right'>{{numPrinter $s.TotalMilli}}</td>
+			{{if $s.IsDominant}}
+			<td align='right' style='color: red;' title='dominant stage'>{{formatFloat $s.Percent}}</td>
+			{{else}}
+			<td align='right'>{{formatFloat $s.Percent}}</td>
+			{{end}}
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<table width='100%'>
+		<caption>Slow Pipelines by Stages</caption>
+		<tr><th>namespace</th><th>stages</th><th>count</th><th>max ms</th><th>total ms</th></tr>
+	{{range $p := .Pipelines}}
+		<tr><td>{{$p.Namespace}}</td><td class='break'>{{replace $p.Stages "," " &rarr; "}}</td>
+			<td align='right'>{{numPrinter $p.Count}}</td>
+			<td align='right'>{{numPrinter $p.MaxMilli}}</td>
+			<td align='right'>{{numPrinter $p.TotalMilli}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<div>Percents in red are stages of pipelines taking at least {{.Percent}}% of the slow aggregate time of the
+		namespace.  A namespace is $lookup heavy if pipelines with $lookup or $graphLookup dominate it.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no slow aggregate pipelines found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"formatFloat": func(f float64) string {
+			return fmt.Sprintf("%.1f", f)
+		},
+		"join": strings.Join,
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		},
+		"replace": func(s string, old string, new string) template.HTML {
+			return template.HTML(strings.ReplaceAll(template.HTMLEscapeString(s), old, new))
+		}}).Parse(html)
+}
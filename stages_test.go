@@ -0,0 +1,61 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * stages_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetPipelineStages(t *testing.T) {
+	tests := []struct {
+		str    string
+		stages string
+	}{
+		{`{"t":{"$date":"2023-03-01T10:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"aggregate":"orders","pipeline":[{"$match":{"status":"A"}},{"$lookup":{"from":"items","localField":"sku","foreignField":"sku","as":"items"}},{"$unwind":"$items"},{"$group":{"_id":"$cust_id"}}]},"durationMillis":900}}`,
+			"$match,$lookup,$unwind,$group"},
+		{`{"t":{"$date":"2023-03-01T10:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"getMore":{"$numberLong":"1"},"collection":"orders"},"originatingCommand":{"aggregate":"orders","pipeline":[{"$sort":{"a":1}}]},"durationMillis":300}}`,
+			"$sort"},
+		{`{"t":{"$date":"2023-03-01T10:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders","filter":{"a":1}},"durationMillis":200}}`,
+			""},
+	}
+	for _, test := range tests {
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(test.str), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		stages, ok := GetPipelineStages(&doc)
+		if stages != test.stages || ok != (test.stages != "") {
+			t.Fatal("expected", test.stages, "but got", stages, ok)
+		}
+	}
+}
+
+func TestGetStageStats(t *testing.T) {
+	pipelines := []PipelineStat{
+		{Count: 4, MaxMilli: 2000, Namespace: "shop.orders", Stages: "$match,$lookup,$unwind,$lookup", TotalMilli: 6000},
+		{Count: 10, MaxMilli: 300, Namespace: "shop.orders", Stages: "$match,$group", TotalMilli: 2000},
+		{Count: 2, MaxMilli: 200, Namespace: "shop.items", Stages: "$sort", TotalMilli: 300},
+	}
+	stats := GetStageStats(pipelines)
+	if len(stats) != 5 || stats[0].Namespace != "shop.orders" || stats[0].Stage != "$match" {
+		t.Fatal("expected", "$match of shop.orders first", "but got", stats)
+	}
+	for _, stat := range stats {
+		if stat.Stage == "$lookup" {
+			if stat.Percent != 75 || stat.Occurrences != 8 || stat.GetPerPipeline() != 2 || !stat.IsDominant() {
+				t.Fatal("expected", "75% dominant $lookup twice per pipeline", "but got", stat)
+			}
+		} else if stat.Stage == "$group" && stat.IsDominant() {
+			t.Fatal("expected", "$group not dominant", "but got", stat)
+		}
+	}
+	namespaces := GetLookupHeavyNamespaces(stats)
+	if len(namespaces) != 1 || namespaces[0] != "shop.orders" {
+		t.Fatal("expected", "shop.orders", "but got", namespaces)
+	}
+}
@@ -7,6 +7,7 @@ package hatchet
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 
@@ -17,7 +18,23 @@ import (
 func StatsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	/** APIs
 	 * /hatchets/{hatchet}/stats/audit
-	 * /hatchets/{hatchet}/stats/slowops
+	 * /hatchets/{hatchet}/stats/slowops[?ns={regex}&collapse=true&slow=true]
+	 * /hatchets/{hatchet}/stats/writes
+	 * /hatchets/{hatchet}/stats/locks[?topN={n}]
+	 * /hatchets/{hatchet}/stats/plans[?duration={start},{end}]
+	 * /hatchets/{hatchet}/stats/planning
+	 * /hatchets/{hatchet}/stats/replans
+	 * /hatchets/{hatchet}/stats/stages
+	 * /hatchets/{hatchet}/stats/churn
+	 * /hatchets/{hatchet}/stats/reslen[?topN={n}]
+	 * /hatchets/{hatchet}/stats/apps[?ns={regex}]
+	 * /hatchets/{hatchet}/stats/sharding
+	 * /hatchets/{hatchet}/stats/targeting[?ratio={n}]
+	 * /hatchets/{hatchet}/stats/sources
+	 * /hatchets/{hatchet}/stats/severity[?severity={W|E|F}&topN={n}]
+	 * /hatchets/{hatchet}/stats/templates[?component={component}&rare=true]
+	 * /hatchets/{hatchet}/stats/explain[?topN={n}&ns={regex}]
+	 * /hatchets/{hatchet}/stats/indexes[?ns={regex}]
 	 */
 	hatchetName := params.ByName("hatchet")
 	attr := params.ByName("attr")
@@ -40,7 +57,7 @@ func StatsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Para
 			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
 			return
 		}
-		templ, err := GetAuditTablesTemplate()
+		templ, err := GetAuditTablesTemplate(GetHatchetSlowThresholds(info))
 		if err != nil {
 			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
 			return
@@ -76,12 +93,349 @@ func StatsHandler(w http.ResponseWriter, r *http.Request, params httprouter.Para
 			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
 			return
 		}
-		templ, err := GetStatsTableTemplate(collscan, orderBy, download)
+		ns := r.URL.Query().Get("ns")
+		if ops, err = FilterOpsByNamespace(ops, ns); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		slow := r.URL.Query().Get("slow") == "true"
+		if slow {
+			ops = GetHatchetSlowThresholds(info).FilterSlowOps(ops)
+		}
+		templ, err := GetStatsTableTemplate(collscan, orderBy, download, GetHatchetSlowThresholds(info))
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		bookmarks := map[string]string{}
+		if docs, err := dbase.GetBookmarks(); err == nil {
+			for _, doc := range docs {
+				bookmarks[doc.Hash] = doc.Note
+			}
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Ops": ops, "Summary": summary, "NS": ns,
+			"Bookmarks": bookmarks, "Percents": GetLoadPercents(ops), "Collapse": r.URL.Query().Get("collapse") == "true",
+			"Slow": slow}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "explain" {
+		topN := ToInt(r.URL.Query().Get("topN"))
+		if topN == 0 {
+			topN = TOP_N
+		}
+		ops, err := dbase.GetSlowOps("avg_ms", "DESC", false)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		if ops, err = FilterOpsByNamespace(ops, r.URL.Query().Get("ns")); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", "text/javascript")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v_explain.js", hatchetName))
+		w.Write([]byte(GetExplainScript(hatchetName, ops, topN)))
+		return
+	} else if attr == "indexes" {
+		ops, err := dbase.GetSlowOps("total_ms", "DESC", false)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		if ops, err = FilterOpsByNamespace(ops, r.URL.Query().Get("ns")); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetIndexesTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Suggestions": GetIndexSuggestions(ops), "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "writes" {
+		writes, err := dbase.GetWriteStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetWritesTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Writes": writes, "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "locks" {
+		topN := ToInt(r.URL.Query().Get("topN"))
+		if topN == 0 {
+			topN = TOP_N
+		}
+		ops, err := dbase.GetLockStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetLocksTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Locks": GetLockStatsByNamespace(ops, topN),
+			"Waits": GetLockWaitsByOp(ops, topN), "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "planning" {
+		ops, err := dbase.GetPlanningStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetPlanningTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Planning": ops, "Ratio": PLAN_THRASH_RATIO, "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "replans" {
+		ops, err := dbase.GetReplanStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetReplansTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Replans": ops, "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "targeting" {
+		ops, err := dbase.GetTargetingStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetTargetingTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		ratio := ToInt(r.URL.Query().Get("ratio"))
+		if ratio <= 0 {
+			ratio = GetLogv2().GetTargetingRatio()
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Shapes": ops, "Namespaces": GetNamespaceTargeting(ops),
+			"Ratio": ratio, "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "apps" {
+		apps, err := dbase.GetAppStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		ns := r.URL.Query().Get("ns")
+		if apps, err = FilterAppsByNamespace(apps, ns); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetAppsTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Apps": apps, "Summaries": GetAppSummaries(apps),
+			"NS": ns, "Summary": summary, "Unknown": APP_UNKNOWN}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "churn" {
+		events, err := dbase.GetConnectionEvents()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetChurnTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Clients": GetClientChurns(events), "Labels": LIFETIME_LABELS,
+			"MinConns": CHURN_MIN_CONNS, "PerMinute": CHURN_PER_MINUTE, "ShortLivedMillis": CHURN_SHORT_LIVED_MILLIS,
+			"ShortLivedRatio": CHURN_SHORT_LIVED_RATIO, "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "reslen" {
+		ops, err := dbase.GetSlowOps("total_ms", "DESC", false)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetReslenTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		topN := ToInt(r.URL.Query().Get("topN"))
+		if topN == 0 {
+			topN = TOP_N
+		}
+		namespaces, shapes := GetReslenStats(ops, topN)
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Namespaces": namespaces, "Shapes": shapes,
+			"AvgFlag": RESLEN_AVG_FLAG, "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "stages" {
+		pipelines, err := dbase.GetPipelineStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetStagesTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		stages := GetStageStats(pipelines)
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Stages": stages, "Pipelines": pipelines,
+			"LookupHeavy": GetLookupHeavyNamespaces(stages), "Percent": STAGE_DOMINANT_PERCENT, "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "sharding" {
+		ops, err := dbase.GetShardingStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetShardingTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Sharding": ops, "Summary": summary}
+		if len(ops) > 0 {
+			doc["Total"] = ops[0].TotalShards
+		}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "sources" {
+		docs, err := dbase.GetSourceStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetSourcesTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Sources": docs, "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "severity" {
+		stats, err := dbase.GetSeverityStats()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		severity := r.URL.Query().Get("severity")
+		topN := ToInt(r.URL.Query().Get("topN"))
+		if topN == 0 {
+			topN = TOP_N
+		}
+		docs, err := FilterSeverityStats(stats, severity, topN)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetSeverityTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Stats": docs, "Totals": GetSeverityTotals(stats),
+			"Severity": severity, "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "templates" {
+		docs, err := dbase.GetLogTemplates()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		component := r.URL.Query().Get("component")
+		rare := r.URL.Query().Get("rare") == "true"
+		templ, err := GetTemplatesTemplate()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Templates": GetLogTemplates(docs, component, rare),
+			"Component": component, "Rare": rare, "Summary": summary}
+		if err = templ.Execute(w, doc); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		return
+	} else if attr == "plans" {
+		plans, err := dbase.GetShapePlans(r.URL.Query().Get("duration"))
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		templ, err := GetPlansTemplate()
 		if err != nil {
 			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
 			return
 		}
-		doc := map[string]interface{}{"Hatchet": hatchetName, "Ops": ops, "Summary": summary}
+		doc := map[string]interface{}{"Hatchet": hatchetName, "Timelines": GetPlanTimelines(plans), "Summary": summary}
 		if err = templ.Execute(w, doc); err != nil {
 			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
 			return
@@ -17,7 +17,7 @@ import (
 const MIN_MONGO_VER = "5.0"
 
 // GetStatsTableTemplate returns HTML
-func GetStatsTableTemplate(collscan bool, orderBy string, download string) (*template.Template, error) {
+func GetStatsTableTemplate(collscan bool, orderBy string, download string, thresholds *SlowThresholds) (*template.Template, error) {
 	html := headers
 	if download == "" {
 		html = getContentHTML()
@@ -27,6 +27,31 @@ func GetStatsTableTemplate(collscan bool, orderBy string, download string) (*tem
 		"add": func(a int, b int) int {
 			return a + b
 		},
+		"getDateTime": func(str string) string {
+			if len(str) > 19 {
+				return strings.Replace(str[:19], "T", " ", 1)
+			}
+			return str
+		},
+		"isSlow": func(op OpStat) bool {
+			return thresholds.IsSlow(op)
+		},
+		"getSlowMilli": func(op OpStat) int {
+			return thresholds.Get(op.Namespace)
+		},
+		"getQueryPattern": func(pattern string, collapse bool) string {
+			if collapse {
+				return CollapseFieldPaths(pattern)
+			}
+			return pattern
+		},
+		"getOpHash": func(op OpStat) string {
+			return GetOpHash(op.Op, op.Namespace, op.QueryPattern, op.Index)
+		},
+		"hasKey": func(m map[string]string, key string) bool {
+			_, ok := m[key]
+			return ok
+		},
 		"hasPrefix": func(str string, pre string) bool {
 			return strings.HasPrefix(str, pre)
 		},
@@ -45,52 +70,142 @@ func getStatsTable(collscan bool, orderBy string, download string) string {
 <script>
 	function getSlowopsStats() {
 		var b = document.getElementById('collscan').checked;
-		loadData('/hatchets/{{.Hatchet}}/stats/slowops?orderBy=%v&COLLSCAN='+b);
+		var ns = encodeURIComponent(document.getElementById('ns').value);
+		var c = document.getElementById('collapse').checked;
+		var s = document.getElementById('slow').checked;
+		loadData('/hatchets/{{.Hatchet}}/stats/slowops?orderBy=%v&COLLSCAN='+b+'&ns='+ns+'&collapse='+c+'&slow='+s);
 	}
 	function downloadStats() {
         anchor = document.createElement('a');
         anchor.download = '{{.Hatchet}}_stats.html';
-        anchor.href = '/hatchets/{{.Hatchet}}/stats/slowops?type=stats&download=true';
+        anchor.href = '/hatchets/{{.Hatchet}}/stats/slowops?type=stats&download=true&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}';
         anchor.dataset.downloadurl = ['text/html', anchor.download, anchor.href].join(':');
         anchor.click();
     }
 </script>`, orderBy)
+	if download == "" {
+		html += getBookmarkScript()
+	}
 	asc := "<i class='fa fa-sort-asc'/>"
 	desc := "<i class='fa fa-sort-desc'/>"
 	html += `<div align='left'>`
 	if download == "" {
 		html += `<button id="download" onClick="downloadStats(); return false;"
-			class="btn" style="float: right;"><i class="fa fa-download"></i></button>`
+			class="btn" style="float: right;"><i class="fa fa-download"></i></button>
+		<button id="csv" onClick="javascript:location.href='/api/hatchet/v1.0/hatchets/{{.Hatchet}}/stats/slowops?format=csv&ns={{.NS}}&slow={{.Slow}}'; return false;"
+			class="btn" style="float: right;" title="export as CSV"><i class="fa fa-file-excel-o"></i></button>
+		<button id="plans" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/plans'; return false;"
+			class="btn" style="float: right;" title="plan changes"><i class="fa fa-random"></i></button>
+		<button id="planning" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/planning'; return false;"
+			class="btn" style="float: right;" title="planning time"><i class="fa fa-hourglass-half"></i></button>
+		<button id="apps" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/apps'; return false;"
+			class="btn" style="float: right;" title="workload by application"><i class="fa fa-cubes"></i></button>
+		<button id="churn" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/churn'; return false;"
+			class="btn" style="float: right;" title="connection churn"><i class="fa fa-plug"></i></button>
+		<button id="reslen" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/reslen'; return false;"
+			class="btn" style="float: right;" title="response bytes"><i class="fa fa-database"></i></button>
+		<button id="stages" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/stages'; return false;"
+			class="btn" style="float: right;" title="aggregation stages"><i class="fa fa-filter"></i></button>
+		<button id="replans" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/replans'; return false;"
+			class="btn" style="float: right;" title="replanning"><i class="fa fa-repeat"></i></button>
+		<button id="targeting" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/targeting'; return false;"
+			class="btn" style="float: right;" title="query targeting"><i class="fa fa-crosshairs"></i></button>
+		<button id="sharding" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/sharding'; return false;"
+			class="btn" style="float: right;" title="shard targeting"><i class="fa fa-sitemap"></i></button>
+		<button id="severity" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/severity'; return false;"
+			class="btn" style="float: right;" title="warnings and errors"><i class="fa fa-exclamation-triangle"></i></button>
+		<button id="templates" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/templates?rare=true'; return false;"
+			class="btn" style="float: right;" title="log templates"><i class="fa fa-th-list"></i></button>
+		<button id="sources" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/sources'; return false;"
+			class="btn" style="float: right;" title="lines by source of merged logs"><i class="fa fa-server"></i></button>
+		<button id="indexes" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/indexes?ns={{.NS}}'; return false;"
+			class="btn" style="float: right;" title="index suggestions"><i class="fa fa-key"></i></button>
+		<button id="explain" onClick="javascript:location.href='/hatchets/{{.Hatchet}}/stats/explain?ns={{.NS}}'; return false;"
+			class="btn" style="float: right;" title="explain() script"><i class="fa fa-terminal"></i></button>
+		<div style="float: right; margin-right: 10px;">namespace regex
+			<input type='text' id='ns' value='{{.NS}}' placeholder='e.g. orders\..*'
+				onkeydown="if(event.key == 'Enter') { getSlowopsStats(); }"></input>
+			<button onClick="getSlowopsStats(); return false;" class="button">Filter</button></div>
+		<div style="float: right; margin-right: 10px;">slow only
+			<input type='checkbox' id='slow' title='avg ms above the slow threshold of the namespace'
+				onchange='getSlowopsStats(); return false;' {{if .Slow}}checked{{end}}></input></div>`
 	} else {
 		html += "<div align='center'>{{.Summary}}</div>"
 		asc = ""
 		desc = ""
 	}
 	html += `<table width='100%'><tr><th>#</th>`
-	html += fmt.Sprintf(`<th>op <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=op&COLLSCAN=%v'>%v</th>`, collscan, asc)
-	html += fmt.Sprintf(`<th>namespace <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=ns&order=ASC&COLLSCAN=%v'>%v</th>`, collscan, asc)
-	html += fmt.Sprintf(`<th>count <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=count&COLLSCAN=%v'>%v</th>`, collscan, desc)
-	html += fmt.Sprintf(`<th>avg ms <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=avg_ms&COLLSCAN=%v'>%v</th>`, collscan, desc)
-	html += fmt.Sprintf(`<th>max ms <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=max_ms&COLLSCAN=%v'>%v</th>`, collscan, desc)
-	html += fmt.Sprintf(`<th>total ms <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=total_ms&COLLSCAN=%v'>%v</th>`, collscan, desc)
-	html += fmt.Sprintf(`<th>reslen <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=reslen&COLLSCAN=%v'>%v</th>`, collscan, desc)
+	if download == "" {
+		html += `<th><i class='fa fa-bookmark-o'></i></th>`
+	}
+	html += fmt.Sprintf(`<th>op <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=op&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, asc)
+	html += fmt.Sprintf(`<th>namespace <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=ns&order=ASC&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, asc)
+	html += fmt.Sprintf(`<th>count <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=count&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, desc)
+	html += fmt.Sprintf(`<th>avg ms <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=avg_ms&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, desc)
+	html += fmt.Sprintf(`<th>p50 ms <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=p50_ms&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, desc)
+	html += fmt.Sprintf(`<th>p95 ms <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=p95_ms&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, desc)
+	html += fmt.Sprintf(`<th>p99 ms <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=p99_ms&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, desc)
+	html += fmt.Sprintf(`<th>max ms <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=max_ms&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, desc)
+	html += fmt.Sprintf(`<th>total ms <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=total_ms&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, desc)
+	html += `<th>% total ms</th><th title='cumulative % of shapes ranked by total ms'>cum %</th>`
+	html += fmt.Sprintf(`<th>reslen <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=reslen&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, desc)
+	html += fmt.Sprintf(`<th>first seen <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=first_seen&order=ASC&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, asc)
+	html += fmt.Sprintf(`<th>last seen <a class='sort' href='/hatchets/{{.Hatchet}}/stats/slowops?orderBy=last_seen&COLLSCAN=%v&ns={{.NS}}&collapse={{.Collapse}}&slow={{.Slow}}'>%v</th>`, collscan, desc)
 	if download == "" {
 		html += fmt.Sprintf(`<th valign='middle'>index <input type='checkbox' id='collscan' onchange='getSlowopsStats(); return false;' %v></th>`, checked)
 	} else {
 		html += "<th valign='middle'>index</th>"
 	}
-	html += `<th>query pattern</th>
-		</tr>
+	if download == "" {
+		html += `<th valign='middle'>query pattern <input type='checkbox' id='collapse' title='collapse nested fields into dotted paths'
+			onchange='getSlowopsStats(); return false;' {{if .Collapse}}checked{{end}}></th>`
+	} else {
+		html += `<th>query pattern</th>`
+	}
+	html += `<th>sort</th>
+		</tr>`
+	html += `
 {{range $n, $value := .Ops}}
 		<tr>
 			<td align='right'>{{ add $n 1 }}</td>
+`
+	if download == "" {
+		html += `
+		{{ $hash := getOpHash $value }}
+			<td><button class='btn' onClick='bookmark(this); return false;' data-hash='{{$hash}}' data-op='{{$value.Op}}'
+				data-ns='{{$value.Namespace}}' data-filter='{{$value.QueryPattern}}' data-index='{{$value.Index}}'
+				data-note='{{index $.Bookmarks $hash}}'>
+			{{ if hasKey $.Bookmarks $hash }}
+				<i class='fa fa-bookmark'></i>
+			{{ else }}
+				<i class='fa fa-bookmark-o'></i>
+			{{ end }}</button>
+			{{ if not $.Collapse }}
+			<a href='/api/hatchet/v1.0/hatchets/{{$.Hatchet}}/logs/raw?op={{$value.Op}}&ns={{$value.Namespace}}&filter={{$value.QueryPattern}}&_index={{$value.Index}}'
+				title='export log lines of the shape'><i class='fa fa-download'></i></a>
+			{{ end }}</td>`
+	}
+	html += `
 			<td class='break'>{{ $value.Op }}</td>
-			<td class='break'>{{ $value.Namespace }}</td>
+			<td class='break'><a href='/hatchets/{{$.Hatchet}}/ns/{{$value.Namespace}}'>{{ $value.Namespace }}</a></td>
 			<td align='right'>{{ numPrinter $value.Count }}</td>
+		{{ if isSlow $value }}
+			<td align='right'><span style='color:red;' title='slow threshold {{getSlowMilli $value}} ms'>{{ numPrinter $value.AvgMilli }}</span></td>
+		{{ else }}
 			<td align='right'>{{ numPrinter $value.AvgMilli }}</td>
+		{{ end }}
+			<td align='right'>{{ numPrinter $value.P50Milli }}</td>
+			<td align='right'>{{ numPrinter $value.P95Milli }}</td>
+			<td align='right'>{{ numPrinter $value.P99Milli }}</td>
 			<td align='right'>{{ numPrinter $value.MaxMilli }}</td>
 			<td align='right'>{{ numPrinter $value.TotalMilli }}</td>
+		{{ with index $.Percents $n }}
+			<td align='right'>{{ printf "%.1f" (index . 0) }}</td>
+			<td align='right'>{{ printf "%.1f" (index . 1) }}</td>
+		{{ end }}
 			<td align='right'>{{ numPrinter $value.Reslen }}</td>
+			<td>{{ getDateTime $value.FirstSeen }}</td>
+			<td>{{ getDateTime $value.LastSeen }}</td>
 		{{ if or (eq $value.Index "COLLSCAN") }}
 			<td><span style='color:red;'>{{ $value.Index }}</span></td>
 		{{ else if (hasPrefix $value.Index "ErrMsg:") }}
@@ -101,7 +216,8 @@ func getStatsTable(collscan bool, orderBy string, download string) string {
 		{{else}}
 			<td>{{ $value.Index }}</td>
 		{{end}}
-			<td class='break'>{{ $value.QueryPattern }}</td>
+			<td class='break'>{{ getQueryPattern $value.QueryPattern $.Collapse }}</td>
+			<td class='break'>{{ $value.SortPattern }}</td>
 		</tr>
 {{end}}
 	</table>
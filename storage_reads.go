@@ -0,0 +1,37 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * storage_reads.go
+ */
+
+package hatchet
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// STORAGE_READ_SERIES are series of GetStorageReads, read time is in percent
+// of the duration of slow ops, high of slow ops reading from disk, i.e. cache
+// misses, and low of slow ops of inefficient plans
+var STORAGE_READ_SERIES = []string{"read (MB)", "avg read (ms)", "read time (%)"}
+
+// GetStorageRead returns bytes read from disk and microseconds reading of
+// storage.data of a slow op, false if not logged
+func GetStorageRead(doc *Logv2Info) (int, int, bool) {
+	storage, ok := doc.Attr.Map()["storage"].(bson.D)
+	if !ok {
+		return 0, 0, false
+	}
+	data, ok := storage.Map()["data"].(bson.D)
+	if !ok {
+		return 0, 0, false
+	}
+	bytesRead, micros, logged := 0, 0, false
+	for _, elem := range data {
+		if elem.Key == "bytesRead" {
+			bytesRead, logged = ToInt(elem.Value), true
+		} else if elem.Key == "timeReadingMicros" {
+			micros, logged = ToInt(elem.Value), true
+		}
+	}
+	return bytesRead, micros, logged
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * storage_reads_test.go
+ */
+
+package hatchet
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetStorageRead(t *testing.T) {
+	str := `{"t":{"$date":"2021-07-25T09:38:57.078+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn541","msg":"Slow query","attr":{"type":"command","ns":"_mongopush.tasks","planSummary":"IXSCAN { status: 1 }","reslen":6117,"storage":{"data":{"bytesRead":{"$numberLong":"4248700"},"timeReadingMicros":527302}},"protocol":"op_msg","durationMillis":530}}`
+	var doc Logv2Info
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	bytesRead, micros, ok := GetStorageRead(&doc)
+	if !ok || bytesRead != 4248700 || micros != 527302 {
+		t.Fatal("expected", 4248700, 527302, "but got", bytesRead, micros, ok)
+	}
+
+	str = `{"t":{"$date":"2020-08-21T20:39:17.211-04:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn49","msg":"Slow query","attr":{"type":"command","ns":"keyhole.numbers","storage":{},"protocol":"op_msg","durationMillis":385}}`
+	doc = Logv2Info{}
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok = GetStorageRead(&doc); ok {
+		t.Fatal("expected", false, "but got", ok)
+	}
+}
+
+func TestSQLite3GetStorageReads(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "reads.db")
+	db, err := sql.Open("sqlite3", dbfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbase := &SQLite3DB{db: db, dbfile: dbfile, hatchetName: "mongod_1"}
+	defer dbase.Close()
+	if _, err = db.Exec(`CREATE TABLE mongod_1 (id integer primary key, date text, op text, milli integer,
+		bytes_read integer, read_micros integer);
+		INSERT INTO mongod_1 VALUES (1, '2023-01-01T00:00:01', 'find', 400, 2097152, 300000),
+			(2, '2023-01-01T00:00:02', 'find', 100, 1048576, 100000),
+			(3, '2023-01-01T00:00:03', 'update', 500, NULL, NULL),
+			(4, '2023-01-01T00:00:04', '', 0, NULL, NULL);`); err != nil {
+		t.Fatal(err)
+	}
+	docs, err := dbase.GetStorageReads("2023-01-01T00:00:00,2023-01-01T00:20:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 MB read, 200 ms avg of 2 ops read, and 400 of 1000 ms reading
+	if len(docs) != 1 || docs[0].Values[0] != 3 || docs[0].Values[1] != 200 || docs[0].Values[2] != 40 {
+		t.Fatal("expected", []float64{3, 200, 40}, "but got", docs)
+	}
+}
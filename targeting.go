@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * targeting.go
+ */
+
+package hatchet
+
+import (
+	"math"
+	"sort"
+)
+
+// TARGETING_RATIO is the default docsExamined or keysExamined to nreturned
+// ratio of a shape to flag poor query targeting
+const TARGETING_RATIO = 100
+
+// TargetingStat stores keys and documents examined and documents returned by
+// an op shape, or by a namespace if op and query pattern are empty
+type TargetingStat struct {
+	Count        int    `json:"count" bson:"count"`
+	DocsExamined int    `json:"docs_examined" bson:"docs_examined"`
+	KeysExamined int    `json:"keys_examined" bson:"keys_examined"`
+	Namespace    string `json:"ns" bson:"ns"`
+	Op           string `json:"op" bson:"op"`
+	QueryPattern string `json:"query_pattern" bson:"query_pattern"`
+	Returned     int    `json:"nreturned" bson:"nreturned"`
+}
+
+// GetExamined returns keysExamined, docsExamined, and nreturned of a slow
+// op, false if none of keys and documents examined is logged
+func GetExamined(doc *Logv2Info) (interface{}, interface{}, interface{}, bool) {
+	var keys, docs, returned interface{}
+	for _, elem := range doc.Attr {
+		switch elem.Key {
+		case "keysExamined":
+			keys = ToInt(elem.Value)
+		case "docsExamined":
+			docs = ToInt(elem.Value)
+		case "nreturned":
+			returned = ToInt(elem.Value)
+		}
+	}
+	return keys, docs, returned, keys != nil || docs != nil
+}
+
+// GetDocsRatio returns documents examined per document returned, none
+// returned counts as 1
+func (ptr *TargetingStat) GetDocsRatio() float64 {
+	return math.Round(10*float64(ptr.DocsExamined)/math.Max(float64(ptr.Returned), 1)) / 10
+}
+
+// GetKeysRatio returns keys examined per document returned, none returned
+// counts as 1
+func (ptr *TargetingStat) GetKeysRatio() float64 {
+	return math.Round(10*float64(ptr.KeysExamined)/math.Max(float64(ptr.Returned), 1)) / 10
+}
+
+// GetRatio returns the worse of the documents and keys examined ratios
+func (ptr *TargetingStat) GetRatio() float64 {
+	return math.Max(ptr.GetDocsRatio(), ptr.GetKeysRatio())
+}
+
+// IsPoorlyTargeted returns true if a shape examines more than ratio keys or
+// documents per document returned
+func (ptr *TargetingStat) IsPoorlyTargeted(ratio int) bool {
+	return ptr.GetRatio() > float64(ratio)
+}
+
+// SortTargetingStats sorts shapes by the worst targeting first
+func SortTargetingStats(stats []TargetingStat) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].GetRatio() > stats[j].GetRatio()
+	})
+}
+
+// GetNamespaceTargeting returns targeting of namespaces of shapes, the worst
+// targeting first
+func GetNamespaceTargeting(stats []TargetingStat) []TargetingStat {
+	namespaces := map[string]*TargetingStat{}
+	names := []string{}
+	for _, stat := range stats {
+		ns := namespaces[stat.Namespace]
+		if ns == nil {
+			ns = &TargetingStat{Namespace: stat.Namespace}
+			namespaces[stat.Namespace] = ns
+			names = append(names, stat.Namespace)
+		}
+		ns.Count += stat.Count
+		ns.DocsExamined += stat.DocsExamined
+		ns.KeysExamined += stat.KeysExamined
+		ns.Returned += stat.Returned
+	}
+	docs := []TargetingStat{}
+	for _, name := range names {
+		docs = append(docs, *namespaces[name])
+	}
+	SortTargetingStats(docs)
+	return docs
+}
+
+// GetTargetingAuditData returns poorly targeted shapes by namespace with the
+// op, query pattern, documents and keys examined ratios, and count, the
+// worst targeting first
+func GetTargetingAuditData(stats []TargetingStat, ratio int) []NameValues {
+	docs := []NameValues{}
+	for _, stat := range stats {
+		if !stat.IsPoorlyTargeted(ratio) {
+			continue
+		}
+		docs = append(docs, NameValues{stat.Namespace, []interface{}{stat.Op, stat.QueryPattern,
+			stat.GetDocsRatio(), stat.GetKeysRatio(), stat.Count}})
+	}
+	return docs
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * targeting_template.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetTargetingTemplate returns HTML
+func GetTargetingTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Shapes}}
+	<table width='100%'>
+		<caption>Query Targeting by Namespace</caption>
+		<tr><th>namespace</th><th>count</th><th>keys examined</th><th>docs examined</th><th>returned</th>
+			<th>keys/returned</th><th>docs/returned</th></tr>
+	{{range $ns := .Namespaces}}
+		<tr><td>{{$ns.Namespace}}</td><td align='right'>{{numPrinter $ns.Count}}</td>
+			<td align='right'>{{numPrinter $ns.KeysExamined}}</td>
+			<td align='right'>{{numPrinter $ns.DocsExamined}}</td>
+			<td align='right'>{{numPrinter $ns.Returned}}</td>
+			<td align='right'>{{formatRatio $ns.GetKeysRatio}}</td>
+			<td align='right'>{{formatRatio $ns.GetDocsRatio}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<table width='100%'>
+		<caption>Query Targeting by Shape</caption>
+		<tr><th>op</th><th>namespace</th><th>count</th><th>keys examined</th><th>docs examined</th><th>returned</th>
+			<th>keys/returned</th><th>docs/returned</th><th>query pattern</th></tr>
+	{{range $op := .Shapes}}
+		<tr><td>{{$op.Op}}</td><td>{{$op.Namespace}}</td><td align='right'>{{numPrinter $op.Count}}</td>
+			<td align='right'>{{numPrinter $op.KeysExamined}}</td>
+			<td align='right'>{{numPrinter $op.DocsExamined}}</td>
+			<td align='right'>{{numPrinter $op.Returned}}</td>
+			{{if $op.IsPoorlyTargeted $.Ratio}}
+			<td align='right' style='color: red;' title='poor targeting'>{{formatRatio $op.GetKeysRatio}}</td>
+			<td align='right' style='color: red;' title='poor targeting'>{{formatRatio $op.GetDocsRatio}}</td>
+			{{else}}
+			<td align='right'>{{formatRatio $op.GetKeysRatio}}</td>
+			<td align='right'>{{formatRatio $op.GetDocsRatio}}</td>
+			{{end}}
+			<td class='break'>{{$op.QueryPattern}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<div>Shapes in red examine more than {{.Ratio}} keys or documents per document returned, an index matching
+		the filter and sort of the query pattern reduces the work.  A shape returning no documents counts as
+		returning 1.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no keysExamined or docsExamined found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"formatRatio": func(ratio float64) string {
+			return fmt.Sprintf("%.1f", ratio)
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
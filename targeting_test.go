@@ -0,0 +1,53 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * targeting_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetExamined(t *testing.T) {
+	doc := Logv2Info{Attr: bson.D{{Key: "keysExamined", Value: int32(0)}, {Key: "docsExamined", Value: int64(5000)},
+		{Key: "nreturned", Value: int32(2)}}}
+	keys, docs, returned, ok := GetExamined(&doc)
+	if !ok || keys != 0 || docs != 5000 || returned != 2 {
+		t.Fatal("expected", "0 5000 2", "but got", keys, docs, returned, ok)
+	}
+	doc = Logv2Info{Attr: bson.D{{Key: "nreturned", Value: int32(2)}}}
+	if _, _, _, ok = GetExamined(&doc); ok {
+		t.Fatal("expected", false, "but got", ok)
+	}
+}
+
+func TestTargetingStats(t *testing.T) {
+	stats := []TargetingStat{
+		{Op: "find", Namespace: "shop.orders", QueryPattern: `{"_id":1}`, Count: 10, KeysExamined: 10, DocsExamined: 10, Returned: 10},
+		{Op: "find", Namespace: "shop.items", QueryPattern: `{"sku":1}`, Count: 4, KeysExamined: 0, DocsExamined: 40000, Returned: 8},
+		{Op: "count", Namespace: "shop.orders", QueryPattern: `{"status":1}`, Count: 2, KeysExamined: 500, DocsExamined: 0, Returned: 0},
+	}
+	SortTargetingStats(stats)
+	if stats[0].Namespace != "shop.items" || stats[0].GetDocsRatio() != 5000 {
+		t.Fatal("expected", "shop.items of 5000", "but got", stats[0])
+	}
+	if stats[1].GetKeysRatio() != 500 {
+		t.Fatal("expected", 500, "but got", stats[1].GetKeysRatio())
+	}
+	if docs := GetTargetingAuditData(stats, TARGETING_RATIO); len(docs) != 2 {
+		t.Fatal("expected", 2, "but got", docs)
+	}
+	if docs := GetTargetingAuditData(stats, 1000); len(docs) != 1 {
+		t.Fatal("expected", 1, "but got", docs)
+	}
+	namespaces := GetNamespaceTargeting(stats)
+	if len(namespaces) != 2 || namespaces[0].Namespace != "shop.items" {
+		t.Fatal("expected", "shop.items first", "but got", namespaces)
+	}
+	if namespaces[1].KeysExamined != 510 || namespaces[1].Returned != 10 || namespaces[1].GetKeysRatio() != 51 {
+		t.Fatal("expected", "510 keys of 10 returned", "but got", namespaces[1])
+	}
+}
@@ -272,6 +272,7 @@ const headers = `<!DOCTYPE html>
 				document.open();
 				document.write(data);
 				document.close();
+				history.replaceState(null, '', url);
         	})
         	.catch(error => {
       			loading.style.display = 'none';
@@ -312,9 +313,24 @@ func getContentHTML() string {
   <div style="float: left; margin-right: 10px;">
   	<button id="logs" onClick="javascript:loadData('/hatchets/{{.Hatchet}}/logs/slowops'); return false;"
 		class="btn"><i class="fa fa-list"></i></button>Top N</div>
+  <div style="float: left; margin-right: 10px;">
+  	<button id="writes" onClick="javascript:loadData('/hatchets/{{.Hatchet}}/stats/writes'); return false;"
+		class="btn"><i class="fa fa-pencil"></i></button>Writes</div>
+  <div style="float: left; margin-right: 10px;">
+  	<button id="locks" onClick="javascript:loadData('/hatchets/{{.Hatchet}}/stats/locks'); return false;"
+		class="btn"><i class="fa fa-lock"></i></button>Locks</div>
+  <div style="float: left; margin-right: 10px;">
+  	<button id="bookmarks" onClick="javascript:loadData('/hatchets/{{.Hatchet}}/bookmarks/all'); return false;"
+		class="btn"><i class="fa fa-bookmark"></i></button>Bookmarks</div>
+  <div style="float: left; margin-right: 10px;">
+  	<button id="live" onClick="javascript:loadData('/hatchets/{{.Hatchet}}/live/dashboard'); return false;"
+		class="btn"><i class="fa fa-bolt"></i></button>Live</div>
   <div style="float: left; margin-right: 10px;">
   	<button id="search" onClick="javascript:loadData('/hatchets/{{.Hatchet}}/logs/all?component=NONE'); return false;"
     	class="btn"><i class="fa fa-search"></i></button>Search</div>
+  <div style="float: left; margin-right: 10px;">
+  	<button id="fulltext" onClick="javascript:loadData('/hatchets/{{.Hatchet}}/logs/search'); return false;"
+    	class="btn"><i class="fa fa-binoculars"></i></button>Find</div>
 
 	<select id='nextChart' style="float: right;" onchange='gotoChart()'>`
 	items := []Chart{}
@@ -336,7 +352,71 @@ func getContentHTML() string {
 	html += `</select>
 	<button id="chart" onClick="javascript:loadData('/hatchets/{{.Hatchet}}/charts/ops?type=stats'); return false;" 
     	class="btn" style="float: right;"><i class="fa fa-bar-chart"></i></button>
+	<select id='savedView' style="float: right; margin-right: 10px;" onchange='gotoView()'>
+		<option value=''>select a saved view</option>
+	</select>
+	<button id="saveView" onClick="javascript:saveView(); return false;" title="save filters of this page as a view"
+		class="btn" style="float: right;"><i class="fa fa-floppy-o"></i></button>
 </div>
+<script>
+	function loadViews() {
+		fetch('/api/hatchet/v1.0/views')
+			.then(response => response.json())
+			.then(doc => {
+				var sel = document.getElementById('savedView');
+				sel.length = 1;
+				(doc.views || []).forEach(function(view) {
+					var opt = document.createElement('option');
+					opt.value = view.path + (view.query ? '?' + view.query : '');
+					opt.textContent = view.name;
+					opt.title = opt.value;
+					sel.appendChild(opt);
+				});
+				if (sel.length > 1) {
+					var opt = document.createElement('option');
+					opt.value = '-';
+					opt.textContent = 'remove a view...';
+					sel.appendChild(opt);
+				}
+			});
+	}
+
+	function gotoView() {
+		var sel = document.getElementById('savedView');
+		var value = sel.options[sel.selectedIndex].value;
+		sel.selectedIndex = 0;
+		if (value == '') {
+			return;
+		} else if (value == '-') {
+			var name = prompt('name of the view to remove');
+			if (name) {
+				fetch('/api/hatchet/v1.0/views/' + encodeURIComponent(name), {method: 'DELETE'})
+					.then(response => response.json())
+					.then(doc => { doc.ok == 1 ? loadViews() : alert(doc.error); });
+			}
+			return;
+		}
+		loadData('/hatchets/{{.Hatchet}}/' + value);
+	}
+
+	function saveView() {
+		var m = location.pathname.match(/^\/hatchets\/[^\/]+\/(.+)$/);
+		if (!m) {
+			alert('open a page of a hatchet to save its filters');
+			return;
+		}
+		var name = prompt('name of the view of ' + m[1] + location.search + ', a saved name is replaced');
+		if (!name) {
+			return;
+		}
+		var body = new URLSearchParams({path: m[1], query: location.search.substring(1)});
+		fetch('/api/hatchet/v1.0/views/' + encodeURIComponent(name), {method: 'POST', body: body})
+			.then(response => response.json())
+			.then(doc => { doc.ok == 1 ? loadViews() : alert(doc.error); });
+	}
+
+	loadViews();
+</script>
 <script>
 	function setChartType() {
 		var sel = document.getElementById('nextChart')
@@ -383,6 +463,7 @@ func getMainPage() string {
       <tr><td align=center><i class="fa fa-shield"></i></td><td>Audit</td><td>Display information on security audits and performance metrics</td></tr>
       <tr><td align=center><i class="fa fa-bar-chart"></i></td><td>Charts</td><td>A number of charts are available for security audits and performance metrics</td></tr>
       <tr><td align=center><i class="fa fa-search"></i></td><td>Search</td><td>Powerful log searching function with key metrics highlighted</td></tr>
+      <tr><td align=center><i class="fa fa-binoculars"></i></td><td>Find</td><td>Full text search of messages of words, phrases, and boolean queries</td></tr>
       <tr><td align=center><i class="fa fa-info"></i></td><td>Stats</td><td>Summary of slow operational query patterns and duration</td></tr>
       <tr><td align=center><i class="fa fa-list"></i></td><td>TopN</td><td>Display the slowest 23 operation logs</td></tr>
     </table>
@@ -407,6 +488,7 @@ func getMainPage() string {
 	<li>/</li>
 	<li>/hatchets/{hatchet}/charts/{chart}[?type={str}]</li>
 	<li>/hatchets/{hatchet}/logs/all[?component={str}&context={str}&duration={date},{date}&severity={str}&limit=[{offset},]{int}]</li>
+	<li>/hatchets/{hatchet}/logs/search[?q={str}&limit=[{offset},]{int}]</li>
 	<li>/hatchets/{hatchet}/logs/slowops[?topN={int}]</li>
 	<li>/hatchets/{hatchet}/stats/slowops[?COLLSCAN={bool}&orderBy={str}]</li>
 </ul>
@@ -414,9 +496,11 @@ func getMainPage() string {
 <h3>API</h3>
 <ul class="api">
 	<li>/api/hatchet/v1.0/hatchets/{hatchet}/logs/all[?component={str}&context={str}&duration={date},{date}&severity={str}&limit=[{offset},]{int}]</li>
+	<li>/api/hatchet/v1.0/hatchets/{hatchet}/logs/search?q={str}[&limit=[{offset},]{int}]</li>
 	<li>/api/hatchet/v1.0/hatchets/{hatchet}/logs/slowops[?topN={int}]</li>
 	<li>/api/hatchet/v1.0/hatchets/{hatchet}/stats/audit</li>
 	<li>/api/hatchet/v1.0/hatchets/{hatchet}/stats/slowops[?COLLSCAN={bool}&orderBy={str}]</li>
+	<li>/api/hatchet/v1.0/hatchets/{hatchet}/indexes/suggestions[?ns={regex}]</li>
 	<li>/api/hatchet/v1.0/mongodb/{version}/drivers/{driver}?compatibleWith={driver version}</li>
 </ul>
 <h4 align='center'><hr/>{{.Version}}</h4>
@@ -0,0 +1,215 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * templates.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// parameters of mining log templates with a fixed depth parse tree of Drain
+const (
+	TEMPLATE_DEPTH        = 2    // leading tokens of a message to route by
+	TEMPLATE_MAX_CHILDREN = 100  // children of a node before routing by the wildcard
+	TEMPLATE_MAX_CLUSTERS = 1000 // templates before messages are counted as overflow
+	TEMPLATE_MAX_TOKENS   = 64   // tokens of a message mined, the rest are ignored
+	TEMPLATE_SIMILARITY   = 0.5  // ratio of tokens equal to join a template
+	TEMPLATE_WILDCARD     = "<*>"
+)
+
+// TEMPLATE_SERIES are audit types of counts, and first and last seen in epoch
+// seconds, of log templates
+var TEMPLATE_SERIES = []string{"template", "template-first", "template-last"}
+
+// LogTemplate is a template of messages with variable tokens replaced by
+// wildcards, and its count and first and last occurrences
+type LogTemplate struct {
+	Component string `json:"component"`
+	Count     int    `json:"count"`
+	First     string `json:"first"`
+	Last      string `json:"last"`
+	Template  string `json:"template"`
+
+	first  int64
+	last   int64
+	tokens []string
+}
+
+// templateNode is a node of the parse tree routing by a token of messages
+type templateNode struct {
+	children  map[string]*templateNode
+	templates []*LogTemplate
+}
+
+// TemplateMiner collapses messages into templates, a Drain parse tree routes
+// messages by component, token count, and leading tokens to templates of
+// which the most similar one is joined
+type TemplateMiner struct {
+	Overflow  int // messages beyond the templates limit
+	root      map[string]*templateNode
+	templates []*LogTemplate
+}
+
+// NewTemplateMiner returns *TemplateMiner
+func NewTemplateMiner() *TemplateMiner {
+	return &TemplateMiner{root: map[string]*templateNode{}}
+}
+
+// Add mines the message of a line other than a slow op
+func (ptr *TemplateMiner) Add(doc *Logv2Info, stat *OpStat) {
+	if doc.Msg == "Slow query" || (stat != nil && stat.Op != "") {
+		return
+	}
+	message := doc.Message
+	if message == "" {
+		message = doc.Msg
+	}
+	tokens := GetTemplateTokens(message)
+	if len(tokens) == 0 {
+		return
+	}
+	route := fmt.Sprintf("%v %v", doc.Component, len(tokens))
+	node := ptr.root[route]
+	if node == nil {
+		node = &templateNode{children: map[string]*templateNode{}}
+		ptr.root[route] = node
+	}
+	for i := 0; i < TEMPLATE_DEPTH && i < len(tokens); i++ {
+		key := tokens[i]
+		if node.children[key] == nil {
+			if len(node.children) >= TEMPLATE_MAX_CHILDREN {
+				key = TEMPLATE_WILDCARD
+			}
+			if node.children[key] == nil {
+				node.children[key] = &templateNode{children: map[string]*templateNode{}}
+			}
+		}
+		node = node.children[key]
+	}
+	var best *LogTemplate
+	similarity := 0.0
+	for _, template := range node.templates {
+		if sim := getTemplateSimilarity(template.tokens, tokens); sim > similarity {
+			best, similarity = template, sim
+		}
+	}
+	seconds := doc.Timestamp.Unix()
+	if best == nil || similarity < TEMPLATE_SIMILARITY {
+		if len(ptr.templates) >= TEMPLATE_MAX_CLUSTERS {
+			ptr.Overflow++
+			return
+		}
+		best = &LogTemplate{Component: doc.Component, first: seconds, tokens: tokens}
+		node.templates = append(node.templates, best)
+		ptr.templates = append(ptr.templates, best)
+	}
+	for i, token := range tokens {
+		if best.tokens[i] != token {
+			best.tokens[i] = TEMPLATE_WILDCARD
+		}
+	}
+	best.Count++
+	if seconds < best.first {
+		best.first = seconds
+	}
+	if seconds > best.last {
+		best.last = seconds
+	}
+}
+
+// GetAuditData returns counts, and first and last seen of templates by audit
+// types of TEMPLATE_SERIES, templates of different routes converged to the same
+// tokens are combined
+func (ptr *TemplateMiner) GetAuditData() map[string][]NameValue {
+	data := map[string][]NameValue{}
+	names := []string{}
+	templates := map[string]*LogTemplate{}
+	for _, template := range ptr.templates {
+		name := template.Component + " " + strings.Join(template.tokens, " ")
+		doc := templates[name]
+		if doc == nil {
+			doc = &LogTemplate{first: template.first, last: template.last}
+			templates[name] = doc
+			names = append(names, name)
+		}
+		doc.Count += template.Count
+		if template.first < doc.first {
+			doc.first = template.first
+		}
+		if template.last > doc.last {
+			doc.last = template.last
+		}
+	}
+	for _, name := range names {
+		doc := templates[name]
+		data["template"] = append(data["template"], NameValue{name, doc.Count})
+		data["template-first"] = append(data["template-first"], NameValue{name, int(doc.first)})
+		data["template-last"] = append(data["template-last"], NameValue{name, int(doc.last)})
+	}
+	return data
+}
+
+// GetTemplateTokens splits a message into tokens, tokens of digits, e.g. ids,
+// counts, addresses, and durations, are wildcards
+func GetTemplateTokens(message string) []string {
+	tokens := strings.Fields(message)
+	if len(tokens) > TEMPLATE_MAX_TOKENS {
+		tokens = tokens[:TEMPLATE_MAX_TOKENS]
+	}
+	for i, token := range tokens {
+		if strings.IndexFunc(token, unicode.IsDigit) >= 0 {
+			tokens[i] = TEMPLATE_WILDCARD
+		}
+	}
+	return tokens
+}
+
+// getTemplateSimilarity returns the ratio of tokens of a message equal to the
+// tokens of a template, wildcards are not counted as equal
+func getTemplateSimilarity(template []string, tokens []string) float64 {
+	if len(template) != len(tokens) || len(tokens) == 0 {
+		return 0
+	}
+	equal := 0
+	for i, token := range tokens {
+		if template[i] == token && token != TEMPLATE_WILDCARD {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(tokens))
+}
+
+// GetLogTemplates returns templates of a component, or of all if empty, from
+// audit rows merged by TEMPLATE_SERIES, the rarest first if rare, otherwise the
+// most first
+func GetLogTemplates(docs []NameValues, component string, rare bool) []LogTemplate {
+	templates := []LogTemplate{}
+	for _, doc := range docs {
+		if len(doc.Values) < len(TEMPLATE_SERIES) {
+			continue
+		}
+		template := LogTemplate{Template: doc.Name, Count: ToInt(doc.Values[0])}
+		if i := strings.Index(doc.Name, " "); i > 0 {
+			template.Component, template.Template = doc.Name[:i], doc.Name[i+1:]
+		}
+		if component != "" && template.Component != component {
+			continue
+		}
+		template.First = getDateTimeStr(time.Unix(int64(ToInt(doc.Values[1])), 0).UTC())
+		template.Last = getDateTimeStr(time.Unix(int64(ToInt(doc.Values[2])), 0).UTC())
+		templates = append(templates, template)
+	}
+	sort.SliceStable(templates, func(i, j int) bool {
+		if rare {
+			return templates[i].Count < templates[j].Count
+		}
+		return templates[i].Count > templates[j].Count
+	})
+	return templates
+}
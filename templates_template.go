@@ -0,0 +1,49 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * templates_template.go
+ */
+
+package hatchet
+
+import (
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetTemplatesTemplate returns HTML
+func GetTemplatesTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Templates}}
+	{{$hatchet := .Hatchet}}
+	{{$rare := .Rare}}
+	<table width='100%'>
+		<caption>Log Templates{{if .Component}} of {{.Component}}{{end}} ({{len .Templates}} templates,
+			{{if .Rare}}<a href='/hatchets/{{$hatchet}}/stats/templates?component={{.Component}}'>most first</a>{{else}}<a
+				href='/hatchets/{{$hatchet}}/stats/templates?component={{.Component}}&rare=true'>rarest first</a>{{end}})</caption>
+		<tr><th>component</th><th>template</th><th>count</th><th>first</th><th>last</th></tr>
+	{{range $t := .Templates}}
+		<tr><td><a href='/hatchets/{{$hatchet}}/stats/templates?component={{$t.Component}}&rare={{$rare}}'>{{$t.Component}}</a></td>
+			<td>{{$t.Template}}</td>
+			<td align='right'>{{numPrinter $t.Count}}</td>
+			<td>{{$t.First}}</td><td>{{$t.Last}}</td>
+		</tr>
+	{{end}}
+	</table>
+	<p/>
+	<div>Messages other than slow ops are collapsed into templates of the same component and tokens, tokens
+		varying among messages of a template and tokens of digits are shown as &lt;*&gt;.</div>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no templates found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
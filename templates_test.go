@@ -0,0 +1,66 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * templates_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetTemplateTokens(t *testing.T) {
+	tokens := GetTemplateTokens("connection accepted from 10.0.0.1:51234 #12 (3 connections now open)")
+	expected := []string{"connection", "accepted", "from", TEMPLATE_WILDCARD, TEMPLATE_WILDCARD, TEMPLATE_WILDCARD,
+		"connections", "now", "open)"}
+	if len(tokens) != len(expected) {
+		t.Fatal("expected", expected, "but got", tokens)
+	}
+	for i, token := range expected {
+		if tokens[i] != token {
+			t.Fatal("expected", expected, "but got", tokens)
+		}
+	}
+}
+
+func TestTemplateMiner(t *testing.T) {
+	tm := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	docs := []Logv2Info{
+		{Component: "NETWORK", Message: "connection accepted from 10.0.0.1:51234 #12 (3 connections now open)", Timestamp: tm},
+		{Component: "NETWORK", Message: "connection accepted from 10.0.0.2:51235 #13 (4 connections now open)", Timestamp: tm.Add(time.Minute)},
+		{Component: "ACCESS", Message: "Successfully authenticated as principal alice on admin", Timestamp: tm.Add(2 * time.Minute)},
+		{Component: "ACCESS", Message: "Successfully authenticated as principal bob on admin", Timestamp: tm.Add(3 * time.Minute)},
+		{Component: "STORAGE", Message: "WiredTiger error message", Timestamp: tm.Add(4 * time.Minute)},
+		{Component: "COMMAND", Msg: "Slow query", Message: "command shop.orders find", Timestamp: tm.Add(5 * time.Minute)},
+	}
+	miner := NewTemplateMiner()
+	for i := range docs {
+		miner.Add(&docs[i], nil)
+	}
+	miner.Add(&Logv2Info{Component: "COMMAND", Message: "command shop.orders find 200ms"}, &OpStat{Op: "find"})
+	data := miner.GetAuditData()
+	templates := GetLogTemplates(MergeAuditSeries(TEMPLATE_SERIES, data), "", false)
+	if len(templates) != 3 {
+		t.Fatal("expected", 3, "but got", templates)
+	}
+	if templates[0].Count != 2 || templates[2].Component != "STORAGE" || templates[2].Count != 1 {
+		t.Fatal("expected", "2 of NETWORK or ACCESS and 1 of STORAGE", "but got", templates)
+	}
+	expected := map[string]string{
+		"NETWORK": "connection accepted from <*> <*> <*> connections now open)",
+		"ACCESS":  "Successfully authenticated as principal <*> on admin",
+	}
+	for _, template := range templates[:2] {
+		if template.Template != expected[template.Component] {
+			t.Fatal("expected", expected[template.Component], "but got", template.Template)
+		}
+		if template.Component == "NETWORK" && (template.First != "2023-01-01T00:00:00.000-0000" || template.Last != "2023-01-01T00:01:00.000-0000") {
+			t.Fatal("expected", "2023-01-01T00:00:00.000-0000", "2023-01-01T00:01:00.000-0000", "but got", template.First, template.Last)
+		}
+	}
+	rare := GetLogTemplates(MergeAuditSeries(TEMPLATE_SERIES, data), "STORAGE", true)
+	if len(rare) != 1 || rare[0].Template != "WiredTiger error message" {
+		t.Fatal("expected", "WiredTiger error message", "but got", rare)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * tickets.go
+ */
+
+package hatchet
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TimeSeries is values of series at a point in time
+type TimeSeries struct {
+	Date   string    `bson:"date"`
+	Values []float64 `bson:"values"`
+}
+
+// TICKET_WAIT_SERIES are series of GetTicketWaits
+var TICKET_WAIT_SERIES = []string{"avg wait (ms)", "max wait (ms)", "queued ops"}
+
+// GetTicketWait returns total microseconds queued for read/write tickets
+// under the queues attribute (7.0+), false if the server doesn't emit it
+func GetTicketWait(doc *Logv2Info) (int, bool) {
+	queues, ok := doc.Attr.Map()["queues"].(bson.D)
+	if !ok {
+		return 0, false
+	}
+	return sumQueuedMicros(queues), true
+}
+
+func sumQueuedMicros(doc bson.D) int {
+	micros := 0
+	for _, elem := range doc {
+		if elem.Key == "totalTimeQueuedMicros" {
+			micros += ToInt(elem.Value)
+		} else if d, ok := elem.Value.(bson.D); ok {
+			micros += sumQueuedMicros(d)
+		}
+	}
+	return micros
+}
@@ -0,0 +1,33 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * tickets_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetTicketWait(t *testing.T) {
+	str := `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"db.coll","queues":{"execution":{"admissions":2,"totalTimeQueuedMicros":1500},"ingress":{"admissions":1,"totalTimeQueuedMicros":500}},"durationMillis":120}}`
+	var doc Logv2Info
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	micros, ok := GetTicketWait(&doc)
+	if !ok || micros != 2000 {
+		t.Fatal("expected", 2000, "but got", micros, ok)
+	}
+
+	str = `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"db.coll","durationMillis":120}}`
+	doc = Logv2Info{}
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok = GetTicketWait(&doc); ok {
+		t.Fatal("expected", false, "but got", ok)
+	}
+}
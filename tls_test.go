@@ -0,0 +1,67 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * tls_test.go
+ */
+
+package hatchet
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetTLSConfig(t *testing.T) {
+	config, err := GetTLSConfig("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := config.Certificates[0].Leaf
+	if err = leaf.VerifyHostname("localhost"); err != nil {
+		t.Fatal(err)
+	}
+	if err = leaf.VerifyHostname("127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if config.MinVersion != tls.VersionTLS12 {
+		t.Fatal("expected", tls.VersionTLS12, "but got", config.MinVersion)
+	}
+	if fingerprint := GetCertFingerprint(config.Certificates[0]); len(fingerprint) != 95 {
+		t.Fatal("expected", "32 colon separated bytes", "but got", fingerprint)
+	}
+	if _, err = GetTLSConfig("server.crt", ""); err == nil {
+		t.Fatal("expected", "error of no key", "but got", err)
+	}
+	if _, err = GetTLSConfig("testdata/none.crt", "testdata/none.key"); err == nil {
+		t.Fatal("expected", "error of missing files", "but got", err)
+	}
+}
+
+func TestGetSelfSignedCert(t *testing.T) {
+	cert, err := GetSelfSignedCert([]string{"127.0.0.1"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert.Leaf)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "ok" {
+		t.Fatal("expected", "ok", "but got", string(body))
+	}
+}
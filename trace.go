@@ -0,0 +1,154 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * trace.go
+ */
+
+package hatchet
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TRACE_METRICS are numeric columns of a log added to span attributes
+var TRACE_METRICS = append(append([]string{"milli", "reslen", "ticket_wait", "planning_micros", "n_shards"}, WRITE_COUNTERS...), LOCK_COUNTERS...)
+
+var acceptedRemote = regexp.MustCompile(`from (\S+)`)
+
+// TraceLog is a log of a connection
+type TraceLog struct {
+	ID           int            `bson:"_id"`
+	Component    string         `bson:"component"`
+	Context      string         `bson:"context"`
+	Date         string         `bson:"date"`
+	Index        string         `bson:"_index"`
+	Message      string         `bson:"message"`
+	Metrics      map[string]int `bson:"-"`
+	Msg          string         `bson:"msg"`
+	Namespace    string         `bson:"ns"`
+	Op           string         `bson:"op"`
+	Plan         string         `bson:"plan"`
+	QueryPattern string         `bson:"filter"`
+	Severity     string         `bson:"severity"`
+	SortPattern  string         `bson:"sort"`
+}
+
+// TraceSpan is a span of a trace, logs of a connection are children of the
+// connection span
+type TraceSpan struct {
+	Attributes map[string]interface{} `json:"attributes"`
+	Children   []TraceSpan            `json:"children,omitempty"`
+	EndTime    string                 `json:"end_time"`
+	Name       string                 `json:"name"`
+	ParentID   string                 `json:"parent_span_id,omitempty"`
+	SpanID     string                 `json:"span_id"`
+	StartTime  string                 `json:"start_time"`
+	Status     string                 `json:"status"`
+}
+
+// Trace is the lifecycle of the connection of a slow op
+type Trace struct {
+	Hatchet string    `json:"hatchet"`
+	Root    TraceSpan `json:"root"`
+	TraceID string    `json:"trace_id"`
+}
+
+// GetConnectionID returns the connection id of a context, e.g. 12 of conn12
+func GetConnectionID(context string) int {
+	if !strings.HasPrefix(context, "conn") {
+		return 0
+	}
+	return ToInt(context[len("conn"):])
+}
+
+// GetTrace returns the trace of logs of a connection, id is of the slow op
+func GetTrace(hatchetName string, id int, logs []TraceLog) (Trace, error) {
+	var target *TraceLog
+	for i := range logs {
+		if logs[i].ID == id {
+			target = &logs[i]
+		}
+	}
+	if target == nil {
+		return Trace{}, fmt.Errorf("log %v not found", id)
+	}
+	trace := Trace{Hatchet: hatchetName, TraceID: getTraceHash(hatchetName, logs[0].ID)[:32]}
+	root := TraceSpan{Name: "connection " + target.Context, SpanID: getTraceHash(hatchetName, -logs[0].ID)[:16],
+		StartTime: logs[0].Date, EndTime: logs[len(logs)-1].Date, Status: "UNSET",
+		Attributes: map[string]interface{}{"context": target.Context, "connection_id": GetConnectionID(target.Context)}}
+	for _, doc := range logs {
+		if doc.Msg == "Connection accepted" {
+			if matches := acceptedRemote.FindStringSubmatch(doc.Message); matches != nil {
+				root.Attributes["remote"] = matches[1]
+			}
+		} else if doc.Msg == "client metadata" {
+			if metadata := GetClientMetadata(doc.Message); metadata != nil {
+				root.Attributes["client_metadata"] = metadata
+			}
+		}
+		root.Children = append(root.Children, getTraceSpan(hatchetName, doc, root.SpanID, doc.ID == id))
+	}
+	if root.Children[0].Name != "Connection accepted" {
+		root.Attributes["accepted"] = "not found in logs"
+	}
+	if root.Children[len(root.Children)-1].Name != "Connection ended" {
+		root.Attributes["ended"] = "not found in logs"
+	}
+	trace.Root = root
+	return trace, nil
+}
+
+// GetClientMetadata returns the client metadata document of a legacy
+// message of a client metadata log
+func GetClientMetadata(message string) map[string]interface{} {
+	i := strings.Index(message, `"doc":"`)
+	if i < 0 || !strings.HasSuffix(message, `"`) {
+		return nil
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(message[i+len(`"doc":"`):len(message)-1]), &doc); err != nil {
+		return nil
+	}
+	return doc
+}
+
+// getTraceSpan returns the span of a log, a slow op starts its duration
+// before the time logged
+func getTraceSpan(hatchetName string, doc TraceLog, parentID string, isTarget bool) TraceSpan {
+	span := TraceSpan{Name: doc.Msg, SpanID: getTraceHash(hatchetName, doc.ID)[:16], ParentID: parentID,
+		StartTime: doc.Date, EndTime: doc.Date, Status: "OK",
+		Attributes: map[string]interface{}{"log_id": doc.ID, "severity": doc.Severity, "component": doc.Component,
+			"message": doc.Message}}
+	if doc.Severity == "E" || doc.Severity == "F" || strings.HasPrefix(doc.Index, "ErrMsg:") {
+		span.Status = "ERROR"
+	}
+	if isTarget {
+		span.Attributes["target"] = true
+	}
+	if doc.Op != "" {
+		span.Name = doc.Op + " " + doc.Namespace
+		for key, value := range map[string]string{"op": doc.Op, "ns": doc.Namespace, "query_pattern": doc.QueryPattern,
+			"sort_pattern": doc.SortPattern, "index": doc.Index, "plan_summary": doc.Plan} {
+			if value != "" {
+				span.Attributes[key] = value
+			}
+		}
+	}
+	for key, value := range doc.Metrics {
+		span.Attributes[key] = value
+	}
+	if milli, ok := doc.Metrics["milli"]; ok && milli > 0 {
+		if end, err := time.Parse("2006-01-02T15:04:05.000-0700", doc.Date); err == nil {
+			span.StartTime = getDateTimeStr(end.Add(-time.Duration(milli) * time.Millisecond))
+		}
+	}
+	return span
+}
+
+func getTraceHash(hatchetName string, id int) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("%v/%v", hatchetName, id))))
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * trace_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+)
+
+func TestGetConnectionID(t *testing.T) {
+	for context, expected := range map[string]int{"conn12": 12, "listener": 0, "conn": 0} {
+		if id := GetConnectionID(context); id != expected {
+			t.Fatal("expected", expected, "of", context, "but got", id)
+		}
+	}
+}
+
+func TestGetClientMetadata(t *testing.T) {
+	message := `client metadata "remote":"10.0.0.1:50000" "client":"conn5" "doc":"{"driver":{"name":"nodejs","version":"4.13.0"}}"`
+	doc := GetClientMetadata(message)
+	driver, ok := doc["driver"].(map[string]interface{})
+	if !ok || driver["name"] != "nodejs" {
+		t.Fatal("expected", "nodejs", "but got", doc)
+	}
+	if doc = GetClientMetadata("client metadata"); doc != nil {
+		t.Fatal("expected", nil, "but got", doc)
+	}
+}
+
+func TestGetTrace(t *testing.T) {
+	logs := []TraceLog{
+		{ID: 3, Context: "listener", Date: "2023-01-01T00:00:00.000-0000", Msg: "Connection accepted",
+			Message: `connection accepted from 10.0.0.1:50000 uuid:"abc" #5 (2 connections now open)`},
+		{ID: 4, Context: "conn5", Date: "2023-01-01T00:00:00.001-0000", Msg: "client metadata",
+			Message: `client metadata "remote":"10.0.0.1:50000" "client":"conn5" "doc":"{"application":{"name":"orders"}}"`},
+		{ID: 7, Context: "conn5", Date: "2023-01-01T00:00:02.000-0000", Msg: "Slow query", Op: "find",
+			Namespace: "shop.orders", Plan: "COLLSCAN", Index: "COLLSCAN", Metrics: map[string]int{"milli": 1500, "reslen": 100}},
+		{ID: 9, Context: "conn5", Date: "2023-01-01T00:00:03.000-0000", Msg: "Connection ended"},
+	}
+	trace, err := GetTrace("test", 7, logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trace.Root.Attributes["remote"] != "10.0.0.1:50000" {
+		t.Fatal("expected", "10.0.0.1:50000", "but got", trace.Root.Attributes["remote"])
+	}
+	if _, ok := trace.Root.Attributes["accepted"]; ok {
+		t.Fatal("expected", "connection accepted found", "but got", trace.Root.Attributes["accepted"])
+	}
+	if len(trace.Root.Children) != len(logs) {
+		t.Fatal("expected", len(logs), "but got", len(trace.Root.Children))
+	}
+	span := trace.Root.Children[2]
+	if span.Name != "find shop.orders" || span.Attributes["target"] != true || span.Attributes["milli"] != 1500 {
+		t.Fatal("expected", "target find shop.orders of 1500 ms", "but got", span)
+	}
+	if span.StartTime != "2023-01-01T00:00:00.500-0000" {
+		t.Fatal("expected", "2023-01-01T00:00:00.500-0000", "but got", span.StartTime)
+	}
+	if span.ParentID != trace.Root.SpanID {
+		t.Fatal("expected", trace.Root.SpanID, "but got", span.ParentID)
+	}
+	again, _ := GetTrace("test", 7, logs)
+	if again.TraceID != trace.TraceID || again.Root.Children[2].SpanID != span.SpanID {
+		t.Fatal("expected", "same ids", "but got", again.TraceID, trace.TraceID)
+	}
+	if _, err = GetTrace("test", 8, logs); err == nil {
+		t.Fatal("expected", "error", "but got", nil)
+	}
+}
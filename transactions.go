@@ -0,0 +1,117 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * transactions.go
+ */
+
+package hatchet
+
+import (
+	"sort"
+	"strings"
+)
+
+// termination of multi-document transactions
+const (
+	TXN_ABORTED   = "aborted"
+	TXN_COMMITTED = "committed"
+
+	// TXN_LIFETIME_LIMIT is the cause of transactions aborted for running
+	// longer than transactionLifetimeLimitSeconds
+	TXN_LIFETIME_LIMIT = "TransactionExceededLifetimeLimitSeconds"
+)
+
+// TXN_SERIES are series of GetTransactionRates
+var TXN_SERIES = []string{"committed", "aborted", "abort rate (%)"}
+
+// TXN_COUNTERS are counters of documents of a transaction added as its ops
+var TXN_COUNTERS = []string{"ninserted", "nModified", "ndeleted", "nreturned"}
+
+// Transaction is the termination, cause of an abort, milliseconds, and ops of
+// a multi-document transaction
+type Transaction struct {
+	Cause  string
+	Milli  int
+	Ops    int
+	Result string
+}
+
+// TxnStat stores transactions of a termination and a cause
+type TxnStat struct {
+	Cause      string `json:"cause" bson:"cause"`
+	Count      int    `json:"count" bson:"count"`
+	MaxMilli   int    `json:"max_ms" bson:"max_ms"`
+	Ops        int    `json:"ops" bson:"ops"`
+	Result     string `json:"result" bson:"result"`
+	TotalMilli int    `json:"total_ms" bson:"total_ms"`
+}
+
+// GetTransaction returns a committed or aborted transaction of a TXN log, or a
+// transaction aborted for exceeding its lifetime limit, false otherwise
+func GetTransaction(doc *Logv2Info) (Transaction, bool) {
+	txn := Transaction{}
+	if doc.Component != "TXN" {
+		return txn, false
+	}
+	attr := doc.Attr.Map()
+	if strings.Contains(doc.Msg, "transactionLifetimeLimitSeconds") {
+		txn.Result, txn.Cause = TXN_ABORTED, TXN_LIFETIME_LIMIT
+		return txn, true
+	}
+	result, _ := attr["terminationCause"].(string)
+	if result != TXN_COMMITTED && result != TXN_ABORTED {
+		return txn, false
+	}
+	txn.Result = result
+	txn.Milli = ToInt(attr["durationMillis"])
+	for _, counter := range TXN_COUNTERS {
+		txn.Ops += ToInt(attr[counter])
+	}
+	if result == TXN_ABORTED {
+		for _, key := range []string{"errName", "errCodeName", "errMsg"} {
+			if cause, ok := attr[key].(string); ok && cause != "" {
+				txn.Cause = cause
+				break
+			}
+		}
+		if txn.Cause == "" {
+			txn.Cause = "unknown"
+		}
+	}
+	return txn, true
+}
+
+// GetAbortRate returns aborted transactions in percent of transactions
+func GetAbortRate(stats []TxnStat) float64 {
+	total, aborted := 0, 0
+	for _, stat := range stats {
+		total += stat.Count
+		if stat.Result == TXN_ABORTED {
+			aborted += stat.Count
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(aborted) / float64(total)
+}
+
+// GetTransactionAuditData returns transactions by termination and cause of
+// aborts, the most first, with avg and max ms and avg ops
+func GetTransactionAuditData(stats []TxnStat) []NameValues {
+	docs := []NameValues{}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Count > stats[j].Count
+	})
+	for _, stat := range stats {
+		if stat.Count == 0 {
+			continue
+		}
+		name := stat.Result
+		if stat.Cause != "" {
+			name += ": " + stat.Cause
+		}
+		docs = append(docs, NameValues{name, []interface{}{stat.Count, stat.TotalMilli / stat.Count, stat.MaxMilli,
+			stat.Ops / stat.Count}})
+	}
+	return docs
+}
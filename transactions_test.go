@@ -0,0 +1,61 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * transactions_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetTransaction(t *testing.T) {
+	tests := []struct {
+		str string
+		txn Transaction
+		ok  bool
+	}{
+		{`{"t":{"$date":"2023-01-01T00:01:00.000+00:00"},"s":"I","c":"TXN","id":51802,"ctx":"conn1","msg":"transaction","attr":{"parameters":{"lsid":{"id":{"$uuid":"3b0cc4cd-bb1f-4a8e-8f6e-3f8a6d1c2b11"}},"txnNumber":2,"autocommit":false},"ninserted":2,"nModified":1,"terminationCause":"committed","timeActiveMicros":1200,"timeInactiveMicros":300,"durationMillis":150}}`,
+			Transaction{Milli: 150, Ops: 3, Result: TXN_COMMITTED}, true},
+		{`{"t":{"$date":"2023-01-01T00:02:00.000+00:00"},"s":"I","c":"TXN","id":51802,"ctx":"conn2","msg":"transaction","attr":{"parameters":{"txnNumber":3,"autocommit":false},"terminationCause":"aborted","errName":"WriteConflict","errCode":112,"durationMillis":80}}`,
+			Transaction{Cause: "WriteConflict", Milli: 80, Result: TXN_ABORTED}, true},
+		{`{"t":{"$date":"2023-01-01T00:03:00.000+00:00"},"s":"I","c":"TXN","id":51802,"ctx":"conn3","msg":"transaction","attr":{"parameters":{"txnNumber":4,"autocommit":false},"terminationCause":"aborted","durationMillis":10}}`,
+			Transaction{Cause: "unknown", Milli: 10, Result: TXN_ABORTED}, true},
+		{`{"t":{"$date":"2023-01-01T00:04:00.000+00:00"},"s":"I","c":"TXN","id":20707,"ctx":"LogicalSessionCacheReap","msg":"Aborting transaction because it has been running for longer than 'transactionLifetimeLimitSeconds'","attr":{"txnNumber":5}}`,
+			Transaction{Cause: TXN_LIFETIME_LIMIT, Result: TXN_ABORTED}, true},
+		{`{"t":{"$date":"2023-01-01T00:05:00.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","durationMillis":200}}`,
+			Transaction{}, false},
+	}
+	for _, test := range tests {
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(test.str), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		txn, ok := GetTransaction(&doc)
+		if txn != test.txn || ok != test.ok {
+			t.Fatal("expected", test.txn, test.ok, "but got", txn, ok)
+		}
+	}
+}
+
+func TestGetTransactionAuditData(t *testing.T) {
+	stats := []TxnStat{
+		{Result: TXN_ABORTED, Cause: "LockTimeout", Count: 2, TotalMilli: 10000, MaxMilli: 5000},
+		{Result: TXN_COMMITTED, Count: 8, TotalMilli: 800, MaxMilli: 300, Ops: 24},
+	}
+	if rate := GetAbortRate(stats); rate != 20 {
+		t.Fatal("expected", 20, "but got", rate)
+	}
+	docs := GetTransactionAuditData(stats)
+	if len(docs) != 2 || docs[0].Name != TXN_COMMITTED || docs[1].Name != "aborted: LockTimeout" {
+		t.Fatal("expected", "committed and aborted: LockTimeout", "but got", docs)
+	}
+	expected := []interface{}{8, 100, 300, 3}
+	for i, value := range expected {
+		if docs[0].Values[i] != value {
+			t.Fatal("expected", expected, "but got", docs[0].Values)
+		}
+	}
+}
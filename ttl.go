@@ -0,0 +1,117 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * ttl.go
+ */
+
+package hatchet
+
+import (
+	"strings"
+)
+
+// TTL_SERIES are series of GetTTLDeletes
+var TTL_SERIES = []string{"docs deleted", "TTL ms", "avg slow op ms"}
+
+// TTLNamespace counts TTL deletes of a namespace and the minutes deleted
+type TTLNamespace struct {
+	Deleted    int             // documents deleted
+	Minutes    map[string]bool // minutes of passes
+	Passes     int             // passes of TTL indexes deleted documents
+	TotalMilli int             // total ms of passes
+}
+
+// TTLWindow counts slow ops of a minute
+type TTLWindow struct {
+	Ops        int
+	TotalMilli int
+}
+
+// TTLStats counts TTL deletes by namespace and slow ops by minute to
+// correlate TTL deletes with latency of slow ops
+type TTLStats struct {
+	Namespaces map[string]*TTLNamespace
+	Ops        int // slow ops of all minutes
+	TotalMilli int // total ms of slow ops of all minutes
+	Windows    map[string]*TTLWindow
+}
+
+// NewTTLStats returns TTLStats
+func NewTTLStats() *TTLStats {
+	return &TTLStats{Namespaces: map[string]*TTLNamespace{}, Windows: map[string]*TTLWindow{}}
+}
+
+// GetTTLDelete returns the namespace, documents deleted, and milliseconds of
+// a pass of a TTL index, false if not a TTL monitor deletion
+func GetTTLDelete(doc *Logv2Info) (string, int, int, bool) {
+	if doc.Context != "TTLMonitor" && !strings.Contains(doc.Msg, "Deleted expired documents") {
+		return "", 0, 0, false
+	}
+	attr := doc.Attr.Map()
+	deleted, ok := attr["numDeleted"]
+	if !ok {
+		return "", 0, 0, false
+	}
+	ns, _ := attr["namespace"].(string)
+	if ns == "" {
+		ns, _ = attr["ns"].(string)
+	}
+	return ns, ToInt(deleted), ToInt(attr["durationMillis"]), true
+}
+
+// Add counts a TTL deletion or a slow op of the minute of date
+func (ptr *TTLStats) Add(doc *Logv2Info, stat *OpStat, date string) {
+	if len(date) < 16 {
+		return
+	}
+	minute := date[:16]
+	if ns, deleted, milli, ok := GetTTLDelete(doc); ok {
+		namespace := ptr.Namespaces[ns]
+		if namespace == nil {
+			namespace = &TTLNamespace{Minutes: map[string]bool{}}
+			ptr.Namespaces[ns] = namespace
+		}
+		namespace.Deleted += deleted
+		namespace.Minutes[minute] = true
+		namespace.Passes++
+		namespace.TotalMilli += milli
+	} else if stat != nil {
+		window := ptr.Windows[minute]
+		if window == nil {
+			window = &TTLWindow{}
+			ptr.Windows[minute] = window
+		}
+		window.Ops++
+		window.TotalMilli += doc.Attributes.Milli
+		ptr.Ops++
+		ptr.TotalMilli += doc.Attributes.Milli
+	}
+}
+
+// GetAuditData returns audit data by type of namespaces of TTL deletes,
+// documents deleted, passes, ms of passes, and the avg latency of slow ops in
+// minutes of passes in percent of the avg of all, 0 if no slow ops
+func (ptr *TTLStats) GetAuditData() map[string][]NameValue {
+	data := map[string][]NameValue{}
+	avgMilli := 0.0
+	if ptr.Ops > 0 {
+		avgMilli = float64(ptr.TotalMilli) / float64(ptr.Ops)
+	}
+	for ns, namespace := range ptr.Namespaces {
+		ops, milli := 0, 0
+		for minute := range namespace.Minutes {
+			if window := ptr.Windows[minute]; window != nil {
+				ops += window.Ops
+				milli += window.TotalMilli
+			}
+		}
+		percent := 0
+		if ops > 0 && avgMilli > 0 {
+			percent = int(100 * float64(milli) / float64(ops) / avgMilli)
+		}
+		data["ttl"] = append(data["ttl"], NameValue{ns, namespace.Deleted})
+		data["ttl-passes"] = append(data["ttl-passes"], NameValue{ns, namespace.Passes})
+		data["ttl-ms"] = append(data["ttl-ms"], NameValue{ns, namespace.TotalMilli})
+		data["ttl-latency"] = append(data["ttl-latency"], NameValue{ns, percent})
+	}
+	return data
+}
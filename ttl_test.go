@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * ttl_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetTTLDelete(t *testing.T) {
+	str := `{"t":{"$date":"2023-01-01T00:01:00.000+00:00"},"s":"I","c":"INDEX","id":5479200,"ctx":"TTLMonitor","msg":"Deleted expired documents using index","attr":{"namespace":"shop.sessions","index":"lastSeen_1","numDeleted":1200,"durationMillis":850}}`
+	var doc Logv2Info
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	ns, deleted, milli, ok := GetTTLDelete(&doc)
+	if !ok || ns != "shop.sessions" || deleted != 1200 || milli != 850 {
+		t.Fatal("expected", "shop.sessions", 1200, 850, "but got", ns, deleted, milli, ok)
+	}
+
+	str = `{"t":{"$date":"2023-01-01T00:01:00.000+00:00"},"s":"I","c":"INDEX","id":22533,"ctx":"TTLMonitor","msg":"TTL monitor pass started"}`
+	doc = Logv2Info{}
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, ok = GetTTLDelete(&doc); ok {
+		t.Fatal("expected", false, "but got", ok)
+	}
+}
+
+func TestTTLStats(t *testing.T) {
+	logs := []string{
+		`{"t":{"$date":"2023-01-01T00:00:10.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders"},"durationMillis":100}}`,
+		`{"t":{"$date":"2023-01-01T00:00:20.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders"},"durationMillis":100}}`,
+		`{"t":{"$date":"2023-01-01T00:01:00.000+00:00"},"s":"I","c":"INDEX","id":5479200,"ctx":"TTLMonitor","msg":"Deleted expired documents using index","attr":{"namespace":"shop.sessions","index":"lastSeen_1","numDeleted":5000,"durationMillis":2000}}`,
+		`{"t":{"$date":"2023-01-01T00:01:10.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn2","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","command":{"find":"orders"},"durationMillis":700}}`,
+		`{"t":{"$date":"2023-01-01T00:02:00.000+00:00"},"s":"I","c":"INDEX","id":5479200,"ctx":"TTLMonitor","msg":"Deleted expired documents using index","attr":{"namespace":"shop.sessions","index":"lastSeen_1","numDeleted":100,"durationMillis":20}}`,
+		`{"t":{"$date":"2023-01-01T00:03:00.000+00:00"},"s":"I","c":"INDEX","id":5479200,"ctx":"TTLMonitor","msg":"Deleted expired documents using index","attr":{"namespace":"shop.carts","index":"updated_1","numDeleted":10,"durationMillis":5}}`,
+	}
+	ttl := NewTTLStats()
+	for _, str := range logs {
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		stat, err := AnalyzeSlowOp(&doc)
+		if err != nil {
+			stat = nil
+		}
+		ttl.Add(&doc, stat, getDateTimeStr(doc.Timestamp))
+	}
+	docs := MergeAuditSeries(AUDIT_SERIES["ttl"], ttl.GetAuditData())
+	if len(docs) != 2 || docs[0].Name != "shop.sessions" {
+		t.Fatal("expected", "shop.sessions and shop.carts", "but got", docs)
+	}
+	expected := []interface{}{5100, 2, 2020, 233}
+	for i, value := range expected {
+		if docs[0].Values[i] != value {
+			t.Fatal("expected", expected, "but got", docs[0].Values)
+		}
+	}
+	if docs[1].Values[3] != 0 {
+		t.Fatal("expected", 0, "but got", docs[1].Values[3])
+	}
+}
@@ -0,0 +1,323 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * tui.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	KEY_NONE = iota
+	KEY_UP
+	KEY_DOWN
+	KEY_PAGE_UP
+	KEY_PAGE_DOWN
+	KEY_HOME
+	KEY_END
+	KEY_ENTER
+	KEY_QUIT
+)
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// TUI is a terminal frontend to browse slow op shapes of a hatchet
+type TUI struct {
+	dbase       Database
+	hatchetName string
+	ops         []OpStat
+	selected    int
+	offset      int
+	detail      bool
+	timeline    []OpCount
+	width       int
+	height      int
+}
+
+// RunTUI browses shapes of a hatchet in the terminal until q is pressed
+func RunTUI(hatchetName string) error {
+	dbase, err := GetDatabase(hatchetName)
+	if err != nil {
+		return err
+	}
+	defer dbase.Close()
+	tui := &TUI{dbase: dbase, hatchetName: hatchetName, width: 120, height: 40}
+	if tui.ops, err = dbase.GetSlowOps("avg_ms", "DESC", false); err != nil {
+		return err
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("-tui requires a terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+	fmt.Print("\x1b[?25l") // hide cursor
+	defer fmt.Print("\x1b[?25h\x1b[H\x1b[2J")
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		if w, h, err := term.GetSize(fd); err == nil {
+			tui.width, tui.height = w, h
+		}
+		var buffer bytes.Buffer
+		tui.Render(&buffer)
+		os.Stdout.Write(buffer.Bytes())
+		key, err := ReadKey(reader)
+		if err != nil {
+			return err
+		}
+		if key == KEY_QUIT {
+			return nil
+		}
+		tui.HandleKey(key)
+	}
+}
+
+// SelectHatchet prompts for a hatchet if there are more than one
+func SelectHatchet() (string, error) {
+	dbase, err := GetDatabase("")
+	if err != nil {
+		return "", err
+	}
+	names, err := dbase.GetHatchetNames()
+	dbase.Close()
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no hatchet found")
+	} else if len(names) == 1 {
+		return names[0], nil
+	}
+	for i, name := range names {
+		fmt.Printf("%3d. %v\n", i+1, name)
+	}
+	fmt.Print("select a hatchet: ")
+	var n int
+	if _, err = fmt.Scanln(&n); err != nil || n < 1 || n > len(names) {
+		return "", fmt.Errorf("invalid selection")
+	}
+	return names[n-1], nil
+}
+
+// ReadKey reads a key press, arrow and page keys are escape sequences
+func ReadKey(reader *bufio.Reader) (int, error) {
+	b, err := reader.ReadByte()
+	if err != nil {
+		return KEY_NONE, err
+	}
+	switch b {
+	case 'q', 'Q', 3: // ctrl-c
+		return KEY_QUIT, nil
+	case 'k':
+		return KEY_UP, nil
+	case 'j':
+		return KEY_DOWN, nil
+	case 'g':
+		return KEY_HOME, nil
+	case 'G':
+		return KEY_END, nil
+	case '\r', '\n', ' ':
+		return KEY_ENTER, nil
+	case 0x1b:
+		if reader.Buffered() == 0 {
+			return KEY_ENTER, nil // escape closes the detail pane
+		}
+		seq := make([]byte, 2)
+		if _, err = io.ReadFull(reader, seq); err != nil {
+			return KEY_NONE, err
+		}
+		if seq[0] != '[' {
+			return KEY_NONE, nil
+		}
+		switch seq[1] {
+		case 'A':
+			return KEY_UP, nil
+		case 'B':
+			return KEY_DOWN, nil
+		case 'H':
+			return KEY_HOME, nil
+		case 'F':
+			return KEY_END, nil
+		case '5', '6':
+			reader.ReadByte() // trailing ~
+			if seq[1] == '5' {
+				return KEY_PAGE_UP, nil
+			}
+			return KEY_PAGE_DOWN, nil
+		}
+	}
+	return KEY_NONE, nil
+}
+
+// HandleKey moves the selection or toggles the detail pane
+func (ptr *TUI) HandleKey(key int) {
+	rows := ptr.listRows()
+	switch key {
+	case KEY_UP:
+		ptr.selected--
+	case KEY_DOWN:
+		ptr.selected++
+	case KEY_PAGE_UP:
+		ptr.selected -= rows
+	case KEY_PAGE_DOWN:
+		ptr.selected += rows
+	case KEY_HOME:
+		ptr.selected = 0
+	case KEY_END:
+		ptr.selected = len(ptr.ops) - 1
+	case KEY_ENTER:
+		ptr.detail = !ptr.detail
+	}
+	if ptr.selected >= len(ptr.ops) {
+		ptr.selected = len(ptr.ops) - 1
+	}
+	if ptr.selected < 0 {
+		ptr.selected = 0
+	}
+	rows = ptr.listRows()
+	if ptr.selected < ptr.offset {
+		ptr.offset = ptr.selected
+	} else if ptr.selected >= ptr.offset+rows {
+		ptr.offset = ptr.selected - rows + 1
+	}
+	ptr.timeline = nil
+	if ptr.detail && ptr.dbase != nil && len(ptr.ops) > 0 {
+		ptr.timeline = ptr.getTimeline(ptr.ops[ptr.selected])
+	}
+}
+
+// getTimeline returns counts of a shape over time
+func (ptr *TUI) getTimeline(op OpStat) []OpCount {
+	docs, err := ptr.dbase.GetAverageOpTime(op.Op, "")
+	timeline := []OpCount{}
+	if err != nil {
+		return timeline
+	}
+	for _, doc := range docs {
+		if doc.Namespace == op.Namespace && doc.Filter == op.QueryPattern {
+			timeline = append(timeline, doc)
+		}
+	}
+	return timeline
+}
+
+// listRows returns number of shapes displayed, half the screen if detail is on
+func (ptr *TUI) listRows() int {
+	rows := ptr.height - 4 // title, header, and footer
+	if ptr.detail {
+		rows = rows / 2
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// Render writes a screen of the shape list and detail pane
+func (ptr *TUI) Render(w io.Writer) {
+	lines := []string{}
+	title := fmt.Sprintf(" %v: %d shapes", ptr.hatchetName, len(ptr.ops))
+	lines = append(lines, "\x1b[1m"+fitWidth(title, ptr.width)+"\x1b[0m")
+	lines = append(lines, fitWidth(fmt.Sprintf(" %-10s %8s %8s %8s %-30s %s",
+		"Command", "COLLSCAN", "avg ms", "Count", "Namespace", "Query Pattern"), ptr.width))
+	rows := ptr.listRows()
+	for i := ptr.offset; i < len(ptr.ops) && i < ptr.offset+rows; i++ {
+		op := ptr.ops[i]
+		collscan := ""
+		if op.Index == COLLSCAN {
+			collscan = COLLSCAN
+		}
+		line := fitWidth(fmt.Sprintf(" %-10s %8s %8d %8d %-30s %s", op.Op, collscan,
+			int(op.AvgMilli), op.Count, op.Namespace, op.QueryPattern), ptr.width)
+		if i == ptr.selected {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) < rows+2 {
+		lines = append(lines, "")
+	}
+	if ptr.detail && len(ptr.ops) > 0 {
+		lines = append(lines, ptr.getDetail(ptr.ops[ptr.selected])...)
+	}
+	for len(lines) < ptr.height-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, fitWidth(" ↑/↓ j/k move, PgUp/PgDn page, g/G first/last, Enter detail, q quit", ptr.width))
+	fmt.Fprint(w, "\x1b[H\x1b[2J"+strings.Join(lines, "\x1b[K\r\n"))
+}
+
+// getDetail returns lines of the detail pane of a shape
+func (ptr *TUI) getDetail(op OpStat) []string {
+	lines := []string{strings.Repeat("─", ptr.width)}
+	lines = append(lines, fmt.Sprintf(" %v %v", op.Op, op.Namespace))
+	for _, str := range wrapText(op.QueryPattern, ptr.width-2) {
+		lines = append(lines, " "+str)
+	}
+	if op.SortPattern != "" {
+		lines = append(lines, fmt.Sprintf(" sort: %v", op.SortPattern))
+	}
+	lines = append(lines, fmt.Sprintf(" index: %v", op.Index))
+	lines = append(lines, fmt.Sprintf(" count: %d, avg ms: %d, max ms: %d, total ms: %d, reslen: %d",
+		op.Count, int(op.AvgMilli), op.MaxMilli, op.TotalMilli, op.Reslen))
+	lines = append(lines, fmt.Sprintf(" first seen: %v, last seen: %v", op.FirstSeen, op.LastSeen))
+	if len(ptr.timeline) > 0 {
+		counts := []int{}
+		for _, doc := range ptr.timeline {
+			counts = append(counts, doc.Count)
+		}
+		lines = append(lines, fmt.Sprintf(" timeline: %v - %v", ptr.timeline[0].Date, ptr.timeline[len(ptr.timeline)-1].Date))
+		lines = append(lines, " "+fitWidth(Sparkline(counts), ptr.width-2))
+	}
+	return lines
+}
+
+// Sparkline returns a bar of block characters scaled to the max value
+func Sparkline(values []int) string {
+	max := 0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	runes := []rune{}
+	for _, v := range values {
+		i := 0
+		if max > 0 {
+			i = v * (len(sparks) - 1) / max
+		}
+		runes = append(runes, sparks[i])
+	}
+	return string(runes)
+}
+
+func fitWidth(str string, width int) string {
+	runes := []rune(str)
+	if width > 0 && len(runes) > width {
+		return string(runes[:width])
+	}
+	return str
+}
+
+func wrapText(str string, width int) []string {
+	lines := []string{}
+	runes := []rune(str)
+	for width > 0 && len(runes) > width {
+		lines = append(lines, string(runes[:width]))
+		runes = runes[width:]
+	}
+	return append(lines, string(runes))
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * tui_test.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	str := Sparkline([]int{0, 4, 8})
+	if str != "▁▄█" {
+		t.Fatal("expected", "▁▄█", "but got", str)
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("\x1b[A\x1b[Bj\x1b[6~\rq"))
+	expected := []int{KEY_UP, KEY_DOWN, KEY_DOWN, KEY_PAGE_DOWN, KEY_ENTER, KEY_QUIT}
+	for _, value := range expected {
+		key, err := ReadKey(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != value {
+			t.Fatal("expected", value, "but got", key)
+		}
+	}
+}
+
+func TestTUIHandleKey(t *testing.T) {
+	tui := &TUI{hatchetName: "mongod_1b3d5f7", width: 80, height: 10}
+	for i := 0; i < 20; i++ {
+		tui.ops = append(tui.ops, OpStat{Op: "find", Namespace: "db.coll", Count: i})
+	}
+	tui.HandleKey(KEY_UP)
+	if tui.selected != 0 {
+		t.Fatal("expected", 0, "but got", tui.selected)
+	}
+	tui.HandleKey(KEY_END)
+	if tui.selected != 19 || tui.offset != 14 {
+		t.Fatal("expected", 19, 14, "but got", tui.selected, tui.offset)
+	}
+	tui.HandleKey(KEY_ENTER)
+	if !tui.detail || tui.offset != 17 {
+		t.Fatal("expected", 17, "but got", tui.offset)
+	}
+	var buffer bytes.Buffer
+	tui.Render(&buffer)
+	if !strings.Contains(buffer.String(), "first seen") {
+		t.Fatal("expected detail pane")
+	}
+}
@@ -8,9 +8,10 @@ package hatchet
 import (
 	"bufio"
 	"bytes"
-	"compress/gzip"
 	"fmt"
 	"math/rand"
+	"net/url"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -57,14 +58,17 @@ func getHatchetName(logname string) string {
 	temp := filepath.Base(logname)
 	hatchetName := replaceSpecialChars(temp)
 	i := strings.LastIndex(hatchetName, "_log")
-	if i >= 0 && i >= len(temp)-len(".log.gz") {
+	if i >= 0 && i >= len(temp)-len(".log"+ZSTD_EXT) {
 		hatchetName = replaceSpecialChars(hatchetName[0:i])
 	}
 	if len(hatchetName) > MAX_SIZE {
 		hatchetName = hatchetName[:MAX_SIZE-TAIL_SIZE]
 	}
-	if i = strings.LastIndex(hatchetName, "_gz"); i > 0 {
-		hatchetName = hatchetName[:i]
+	for _, ext := range LOG_COMPRESSED_EXTS {
+		if i = strings.LastIndex(hatchetName, replaceSpecialChars(ext)); i > 0 {
+			hatchetName = hatchetName[:i]
+			break
+		}
 	}
 	rand.Seed(time.Now().UnixNano())
 	b := make([]byte, TAIL_SIZE)
@@ -166,33 +170,68 @@ func GetOffsetLimit(str string) (int, int) {
 	return 0, 0
 }
 
-func getDateTimeStr(tm time.Time) string {
-	dt := tm.Format("2006-01-02T15:04:05.000-0000")
-	return dt
-}
-
-func GetBufioReader(data []byte) (*bufio.Reader, error) {
-	isGzipped := false
-	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
-		isGzipped = true
-	}
-
-	if isGzipped {
-		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
-		if err != nil {
-			return nil, err
+// ParseSize returns number of bytes from a size string, e.g. 512MB or 2GB
+func ParseSize(str string) (int64, error) {
+	units := []struct {
+		suffix string
+		size   float64
+	}{{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+	s := strings.ToUpper(strings.TrimSpace(str))
+	multiplier := float64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.size
+			break
 		}
-		defer gzipReader.Close()
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid size %v", str)
+	}
+	return int64(f * multiplier), nil
+}
 
-		var buf bytes.Buffer
-		if _, err = buf.ReadFrom(gzipReader); err != nil {
-			return nil, err
+// GetDBFileSize returns size of a database file including its journal files
+func GetDBFileSize(dbfile string) int64 {
+	var size int64
+	for _, suffix := range []string{"", "-journal", "-wal"} {
+		if info, err := os.Stat(dbfile + suffix); err == nil {
+			size += info.Size()
 		}
+	}
+	return size
+}
 
-		return bufio.NewReader(&buf), nil
+// ParseSSHSource returns user, host:port, and file path of ssh://user@host[:port]/path
+func ParseSSHSource(source string) (string, string, string, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", "", "", err
+	}
+	if u.Scheme != "ssh" || u.Host == "" || u.Path == "" || u.Path == "/" {
+		return "", "", "", fmt.Errorf("invalid ssh source %v, use ssh://user@host/path/to/mongod.log", source)
 	}
+	username := u.User.Username()
+	if username == "" {
+		username = os.Getenv("USER")
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host += ":22"
+	}
+	return username, host, u.Path, nil
+}
 
-	return bufio.NewReader(bytes.NewReader(data)), nil
+func getDateTimeStr(tm time.Time) string {
+	dt := tm.UTC().Format("2006-01-02T15:04:05.000-0000")
+	return dt
+}
+
+// GetBufioReader returns a reader of log content, decompressed if it is
+// gzip, zstd, or bzip2
+func GetBufioReader(data []byte) (*bufio.Reader, error) {
+	return NewLogReader(bytes.NewReader(data))
 }
 
 func ContainsCreditCardNo(card string) bool {
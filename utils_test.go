@@ -440,3 +440,34 @@ func TestCheckLuhn(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSize(t *testing.T) {
+	for str, expected := range map[string]int64{"1024": 1024, "512KB": 512 * 1024, "1.5 GB": 3 << 29, "10mb": 10 << 20} {
+		size, err := ParseSize(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != expected {
+			t.Fatal("expected", expected, "but got", size)
+		}
+	}
+	if _, err := ParseSize("lots"); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
+
+func TestParseSSHSource(t *testing.T) {
+	user, host, path, err := ParseSSHSource("ssh://ken@db1.example.com/var/log/mongodb/mongod.log.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != "ken" || host != "db1.example.com:22" || path != "/var/log/mongodb/mongod.log.gz" {
+		t.Fatal("expected", "ken db1.example.com:22 /var/log/mongodb/mongod.log.gz", "but got", user, host, path)
+	}
+	if _, host, _, _ = ParseSSHSource("ssh://ken@db1:2222/mongod.log"); host != "db1:2222" {
+		t.Fatal("expected", "db1:2222", "but got", host)
+	}
+	if _, _, _, err = ParseSSHSource("ssh://db1"); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
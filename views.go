@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * views.go
+ */
+
+package hatchet
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	MAX_VIEW_NAME = 64
+	VIEWS_TABLE   = "hatchet_views"
+)
+
+// VIEW_CATEGORIES are pages of a hatchet a view can recall
+var VIEW_CATEGORIES = []string{"bookmarks", "charts", "compare", "diff", "logs", "ns", "stats"}
+
+var viewAttrRegex = regexp.MustCompile(`^[\w.\-$]+$`)
+
+// SavedView is a named page and filters, e.g. the time range, namespace, and
+// op, of any hatchet to recall in recurring investigations
+type SavedView struct {
+	Date  string `json:"date" bson:"date"`
+	Name  string `json:"name" bson:"_id"`
+	Path  string `json:"path" bson:"path"`   // {category}/{attr}, e.g. stats/slowops
+	Query string `json:"query" bson:"query"` // query string of filters, e.g. ns=shop.orders&op=find
+}
+
+// NewSavedView returns a view after validating its name, page, and filters
+func NewSavedView(name string, path string, query string) (SavedView, error) {
+	view := SavedView{Name: strings.TrimSpace(name), Path: strings.Trim(path, "/"), Query: strings.TrimPrefix(query, "?")}
+	if view.Name == "" || len(view.Name) > MAX_VIEW_NAME {
+		return view, fmt.Errorf("view name is required, up to %v characters", MAX_VIEW_NAME)
+	}
+	toks := strings.Split(view.Path, "/")
+	if len(toks) != 2 || !viewAttrRegex.MatchString(toks[1]) {
+		return view, errors.New("invalid view path " + path + ", expected {category}/{attr}")
+	}
+	found := false
+	for _, category := range VIEW_CATEGORIES {
+		if toks[0] == category {
+			found = true
+		}
+	}
+	if !found {
+		return view, fmt.Errorf("invalid view category %v, expected one of %v", toks[0], strings.Join(VIEW_CATEGORIES, ", "))
+	}
+	values, err := url.ParseQuery(view.Query)
+	if err != nil {
+		return view, err
+	}
+	view.Query = values.Encode()
+	return view, nil
+}
+
+// GetURL returns the URL of the view of a hatchet
+func (ptr SavedView) GetURL(hatchetName string) string {
+	uri := fmt.Sprintf("/hatchets/%v/%v", url.PathEscape(hatchetName), ptr.Path)
+	if ptr.Query != "" {
+		uri += "?" + ptr.Query
+	}
+	return uri
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * views_handler.go
+ */
+
+package hatchet
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// ViewsAPIHandler lists, saves, or removes saved views
+func ViewsAPIHandler(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	/** APIs
+	 * GET /api/hatchet/v1.0/views
+	 * POST /api/hatchet/v1.0/views/{name} with path and query
+	 * DELETE /api/hatchet/v1.0/views/{name}
+	 */
+	w.Header().Set("Content-Type", "application/json")
+	name := params.ByName("name")
+	dbase, err := GetDatabase("")
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	defer dbase.Close()
+	if dbase.GetVerbose() {
+		log.Println("ViewsAPIHandler", r.Method, r.URL.Path, name)
+	}
+	if r.Method == http.MethodGet {
+		views, err := dbase.GetViews()
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 1, "views": views})
+		return
+	} else if r.Method == http.MethodDelete {
+		if err = dbase.DeleteView(name); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 1, "name": name})
+		return
+	}
+	if err = r.ParseForm(); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	view, err := NewSavedView(name, r.FormValue("path"), r.FormValue("query"))
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	view.Date = getDateTimeStr(time.Now())
+	if err = dbase.SaveView(view); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": 0, "error": err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": 1, "name": view.Name, "view": view})
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * views_test.go
+ */
+
+package hatchet
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewSavedView(t *testing.T) {
+	view, err := NewSavedView(" slow finds ", "/stats/slowops", "?op=find&ns=shop.orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "/hatchets/mongod_1/stats/slowops?ns=shop.orders&op=find"
+	if view.Name != "slow finds" || view.GetURL("mongod_1") != expected {
+		t.Fatal("expected", expected, "but got", view.GetURL("mongod_1"))
+	}
+	for _, path := range []string{"stats", "admin/prune", "stats/../../etc", "logs/all/more"} {
+		if _, err = NewSavedView("v", path, ""); err == nil {
+			t.Fatal("expected", "error of path", path, "but got", err)
+		}
+	}
+	if _, err = NewSavedView("", "stats/slowops", ""); err == nil {
+		t.Fatal("expected", "error of no name", "but got", err)
+	}
+	if _, err = NewSavedView("v", "logs/all", "duration=%zz"); err == nil {
+		t.Fatal("expected", "error of query", "but got", err)
+	}
+}
+
+func TestSQLite3Views(t *testing.T) {
+	dbfile := filepath.Join(t.TempDir(), "views.db")
+	db, err := sql.Open("sqlite3", dbfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbase := &SQLite3DB{db: db, dbfile: dbfile}
+	defer dbase.Close()
+	for _, query := range []string{"op=find", "op=update"} {
+		view, err := NewSavedView("writes", "stats/slowops", query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = dbase.SaveView(view); err != nil {
+			t.Fatal(err)
+		}
+	}
+	view, _ := NewSavedView("errors", "logs/all", "severity=E")
+	if err = dbase.SaveView(view); err != nil {
+		t.Fatal(err)
+	}
+	views, err := dbase.GetViews()
+	if err != nil || len(views) != 2 || views[0].Name != "errors" || views[1].Query != "op=update" {
+		t.Fatal("expected", "errors and writes of op=update", "but got", views, err)
+	}
+	if err = dbase.DeleteView("errors"); err != nil {
+		t.Fatal(err)
+	}
+	if views, err = dbase.GetViews(); err != nil || len(views) != 1 {
+		t.Fatal("expected", 1, "but got", views, err)
+	}
+}
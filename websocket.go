@@ -0,0 +1,202 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * websocket.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes of RFC 6455
+const (
+	WS_TEXT  = 0x1
+	WS_CLOSE = 0x8
+	WS_PING  = 0x9
+	WS_PONG  = 0xA
+)
+
+const (
+	wsGUID          = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsMaxPayload    = 64 * 1024 // of frames from clients
+	wsWriteDeadline = 10 * time.Second
+)
+
+// WebSocket is a server side WebSocket connection pushing text messages,
+// messages from the client are discarded other than pings and closes
+type WebSocket struct {
+	conn   net.Conn
+	done   chan struct{}
+	mutex  sync.Mutex // serializes writes
+	once   sync.Once
+	reader *bufio.Reader
+}
+
+// UpgradeWebSocket upgrades a request to a WebSocket connection, requests of
+// other origins are rejected and errors are responded before returning
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request) (*WebSocket, error) {
+	var err error
+	if r.Method != http.MethodGet || !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!hasHeaderToken(r.Header.Get("Connection"), "upgrade") {
+		err = errors.New("not a WebSocket handshake")
+	} else if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		err = errors.New("unsupported WebSocket version")
+	} else if r.Header.Get("Sec-WebSocket-Key") == "" {
+		err = errors.New("missing Sec-WebSocket-Key")
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, err
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || !strings.EqualFold(u.Host, r.Host) {
+			http.Error(w, "cross origin WebSocket rejected", http.StatusForbidden)
+			return nil, fmt.Errorf("cross origin WebSocket of %v rejected", origin)
+		}
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket not supported", http.StatusInternalServerError)
+		return nil, errors.New("response does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + GetWebSocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n"
+	conn.SetWriteDeadline(time.Now().Add(wsWriteDeadline))
+	if _, err = conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	ws := &WebSocket{conn: conn, done: make(chan struct{}), reader: rw.Reader}
+	go ws.readLoop()
+	return ws, nil
+}
+
+// GetWebSocketAccept returns Sec-WebSocket-Accept of Sec-WebSocket-Key
+func GetWebSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WriteText sends a text message
+func (ptr *WebSocket) WriteText(data []byte) error {
+	return ptr.writeFrame(WS_TEXT, data)
+}
+
+// Done returns a channel closed when the connection is closed
+func (ptr *WebSocket) Done() <-chan struct{} {
+	return ptr.done
+}
+
+// Close sends a close frame and closes the connection
+func (ptr *WebSocket) Close() error {
+	ptr.writeFrame(WS_CLOSE, []byte{0x03, 0xE8}) // 1000, normal closure
+	return ptr.close()
+}
+
+func (ptr *WebSocket) close() error {
+	var err error
+	ptr.once.Do(func() {
+		err = ptr.conn.Close()
+		close(ptr.done)
+	})
+	return err
+}
+
+// writeFrame writes an unmasked and unfragmented frame
+func (ptr *WebSocket) writeFrame(opcode byte, data []byte) error {
+	ptr.mutex.Lock()
+	defer ptr.mutex.Unlock()
+	header := []byte{0x80 | opcode, 0}
+	if size := len(data); size < 126 {
+		header[1] = byte(size)
+	} else if size <= 0xFFFF {
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(size))
+	} else {
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(size))
+	}
+	ptr.conn.SetWriteDeadline(time.Now().Add(wsWriteDeadline))
+	if _, err := ptr.conn.Write(append(header, data...)); err != nil {
+		ptr.close()
+		return err
+	}
+	return nil
+}
+
+// readLoop reads frames of the client until closed, pings are answered
+func (ptr *WebSocket) readLoop() {
+	defer ptr.close()
+	header := make([]byte, 2)
+	for {
+		if _, err := io.ReadFull(ptr.reader, header); err != nil {
+			return
+		}
+		opcode := header[0] & 0x0F
+		if header[1]&0x80 == 0 { // frames of clients must be masked
+			return
+		}
+		size := uint64(header[1] & 0x7F)
+		if size == 126 {
+			b := make([]byte, 2)
+			if _, err := io.ReadFull(ptr.reader, b); err != nil {
+				return
+			}
+			size = uint64(binary.BigEndian.Uint16(b))
+		} else if size == 127 {
+			b := make([]byte, 8)
+			if _, err := io.ReadFull(ptr.reader, b); err != nil {
+				return
+			}
+			size = binary.BigEndian.Uint64(b)
+		}
+		if size > wsMaxPayload {
+			return
+		}
+		mask := make([]byte, 4)
+		if _, err := io.ReadFull(ptr.reader, mask); err != nil {
+			return
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(ptr.reader, payload); err != nil {
+			return
+		}
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+		if opcode == WS_CLOSE {
+			ptr.writeFrame(WS_CLOSE, payload)
+			return
+		} else if opcode == WS_PING {
+			ptr.writeFrame(WS_PONG, payload)
+		}
+	}
+}
+
+// hasHeaderToken returns true if a comma separated header has a token
+func hasHeaderToken(header string, token string) bool {
+	for _, tok := range strings.Split(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(tok), token) {
+			return true
+		}
+	}
+	return false
+}
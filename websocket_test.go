@@ -0,0 +1,108 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * websocket_test.go
+ */
+
+package hatchet
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetWebSocketAccept(t *testing.T) {
+	expected := "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" // of RFC 6455
+	if accept := GetWebSocketAccept("dGhlIHNhbXBsZSBub25jZQ=="); accept != expected {
+		t.Fatal("expected", expected, "but got", accept)
+	}
+}
+
+// readTestFrame returns the opcode and payload of an unmasked frame
+func readTestFrame(t *testing.T, reader *bufio.Reader) (byte, []byte) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		t.Fatal(err)
+	}
+	size := int(header[1] & 0x7F)
+	if size == 126 {
+		b := make([]byte, 2)
+		io.ReadFull(reader, b)
+		size = int(b[0])<<8 | int(b[1])
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatal(err)
+	}
+	return header[0] & 0x0F, payload
+}
+
+func TestLiveHub(t *testing.T) {
+	metrics := NewMetrics()
+	hub := NewLiveHub(metrics)
+	stop := make(chan struct{})
+	defer close(stop)
+	go hub.Run(50*time.Millisecond, stop)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := UpgradeWebSocket(w, r)
+		if err != nil {
+			return
+		}
+		hub.Serve(ws, "mongod_1")
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatal("expected", http.StatusBadRequest, "but got", resp.StatusCode)
+	}
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: " + strings.TrimPrefix(server.URL, "http://") +
+		"\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\nSec-WebSocket-Version: 13\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"))
+	reader := bufio.NewReader(conn)
+	status, _ := reader.ReadString('\n')
+	if !strings.Contains(status, "101") {
+		t.Fatal("expected", "101 Switching Protocols", "but got", status)
+	}
+	for line, _ := reader.ReadString('\n'); line != "\r\n"; line, _ = reader.ReadString('\n') {
+	}
+
+	var update LiveUpdate
+	opcode, payload := readTestFrame(t, reader)
+	if err = json.Unmarshal(payload, &update); err != nil || opcode != WS_TEXT || update.Type != LIVE_METRICS ||
+		update.Metrics.Lines != 0 {
+		t.Fatal("expected", "metrics of 0 lines", "but got", opcode, string(payload), err)
+	}
+	metrics.Add("mongod_1", &Logv2Info{Timestamp: time.Now()}, nil)
+	opcode, payload = readTestFrame(t, reader)
+	if err = json.Unmarshal(payload, &update); err != nil || update.Metrics.Lines != 1 {
+		t.Fatal("expected", "metrics of 1 line", "but got", opcode, string(payload), err)
+	}
+	hub.Refreshed("mongod_1")
+	if _, payload = readTestFrame(t, reader); !strings.Contains(string(payload), `"type":"refresh"`) {
+		t.Fatal("expected", "refresh", "but got", string(payload))
+	}
+
+	// a masked close frame of the client is echoed
+	conn.Write([]byte{0x80 | WS_CLOSE, 0x80 | 2, 1, 2, 3, 4, 0x03 ^ 1, 0xE8 ^ 2})
+	if opcode, payload = readTestFrame(t, reader); opcode != WS_CLOSE || len(payload) != 2 || payload[0] != 0x03 {
+		t.Fatal("expected", "close of 1000", "but got", opcode, payload)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * wiredtiger.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// WiredTiger event types
+const (
+	WT_CACHE      = "cache"
+	WT_CHECKPOINT = "checkpoint"
+	WT_EVICTION   = "eviction"
+)
+
+// WIREDTIGER_SERIES are series of GetWiredTigerStats
+var WIREDTIGER_SERIES = []string{"max checkpoint (s)", "eviction warnings", "cache pressure", "slow ops"}
+
+var (
+	wtCacheRegex      = regexp.MustCompile(`(?i)cache (?:stuck|full)|WT_CACHE_FULL|cache.*(?:pressure|overflow)`)
+	wtCheckpointRegex = regexp.MustCompile(`(?i)checkpoint (?:has been running|ran) for (\d+) seconds`)
+	wtEvictionRegex   = regexp.MustCompile(`(?i)evict`)
+)
+
+// GetWiredTigerMessage returns the message of a WiredTiger log, the message
+// attribute is a string before 6.0 and a document of msg and category since,
+// false if not a WiredTiger log
+func GetWiredTigerMessage(doc *Logv2Info) (string, bool) {
+	if !strings.HasPrefix(doc.Component, "WT") && !strings.Contains(doc.Msg, "WiredTiger") {
+		return "", false
+	}
+	message := doc.Msg
+	switch value := doc.Attr.Map()["message"].(type) {
+	case string:
+		message = value
+	case bson.D:
+		attr := value.Map()
+		message = fmt.Sprintf("%v %v", attr["category"], attr["msg"])
+	}
+	return message, true
+}
+
+// GetWiredTigerEvent returns the type of a WiredTiger checkpoint, eviction, or
+// cache pressure log and the milliseconds a checkpoint has been running,
+// false otherwise
+func GetWiredTigerEvent(doc *Logv2Info) (string, int, bool) {
+	message, ok := GetWiredTigerMessage(doc)
+	if !ok {
+		return "", 0, false
+	}
+	if m := wtCheckpointRegex.FindStringSubmatch(message); m != nil {
+		seconds, _ := strconv.Atoi(m[1])
+		return WT_CHECKPOINT, seconds * 1000, true
+	} else if wtCacheRegex.MatchString(message) {
+		return WT_CACHE, 0, true
+	} else if wtEvictionRegex.MatchString(message) {
+		return WT_EVICTION, 0, true
+	}
+	return "", 0, false
+}
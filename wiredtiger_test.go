@@ -0,0 +1,42 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * wiredtiger_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetWiredTigerEvent(t *testing.T) {
+	tests := []struct {
+		str    string
+		event  string
+		millis int
+		ok     bool
+	}{
+		{`{"t":{"$date":"2023-01-01T00:01:00.000+00:00"},"s":"I","c":"STORAGE","id":22430,"ctx":"Checkpointer","msg":"WiredTiger message","attr":{"message":"[1672531260:0][1:0x7f], WT_SESSION.checkpoint: [WT_VERB_CHECKPOINT_PROGRESS] Checkpoint has been running for 40 seconds and wrote: 5000 pages (100 MB)"}}`,
+			WT_CHECKPOINT, 40000, true},
+		{`{"t":{"$date":"2023-01-01T00:02:00.000+00:00"},"s":"I","c":"WTCHKPT","id":22430,"ctx":"Checkpointer","msg":"WiredTiger message","attr":{"message":{"category":"WT_VERB_CHECKPOINT_PROGRESS","msg":"Checkpoint has been running for 80 seconds and wrote: 9000 pages (200 MB)"}}}`,
+			WT_CHECKPOINT, 80000, true},
+		{`{"t":{"$date":"2023-01-01T00:02:20.000+00:00"},"s":"W","c":"WTEVICT","id":22430,"ctx":"conn1","msg":"WiredTiger message","attr":{"message":{"category":"WT_VERB_EVICTSERVER","msg":"application thread eviction took too long"}}}`,
+			WT_EVICTION, 0, true},
+		{`{"t":{"$date":"2023-01-01T00:02:21.000+00:00"},"s":"E","c":"STORAGE","id":22435,"ctx":"conn1","msg":"WiredTiger error","attr":{"error":-31807,"message":"[1672531341:0], eviction-server: Cache stuck for too long, giving up: WT_CACHE_FULL"}}`,
+			WT_CACHE, 0, true},
+		{`{"t":{"$date":"2023-01-01T00:02:30.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"command","ns":"shop.orders","durationMillis":200}}`,
+			"", 0, false},
+	}
+	for _, test := range tests {
+		var doc Logv2Info
+		if err := bson.UnmarshalExtJSON([]byte(test.str), false, &doc); err != nil {
+			t.Fatal(err)
+		}
+		event, millis, ok := GetWiredTigerEvent(&doc)
+		if event != test.event || millis != test.millis || ok != test.ok {
+			t.Fatal("expected", test.event, test.millis, test.ok, "but got", event, millis, ok)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * writes.go
+ */
+
+package hatchet
+
+// WRITE_COUNTERS are columns of write counters, values of GetWriteCounters
+// follow the order
+var WRITE_COUNTERS = []string{"n_matched", "n_modified", "n_inserted", "n_upserted", "n_deleted"}
+
+// writeCounterNames maps counter names logged by different op types to columns
+var writeCounterNames = map[string]string{
+	"nMatched": "n_matched", "nModified": "n_modified", "nInserted": "n_inserted", "ninserted": "n_inserted",
+	"nUpserted": "n_upserted", "nDeleted": "n_deleted", "ndeleted": "n_deleted",
+}
+
+// WriteStat stores documents written by an op shape
+type WriteStat struct {
+	Count        int    `json:"count" bson:"count"`
+	Deleted      int    `json:"n_deleted" bson:"n_deleted"`
+	Inserted     int    `json:"n_inserted" bson:"n_inserted"`
+	Matched      int    `json:"n_matched" bson:"n_matched"`
+	Modified     int    `json:"n_modified" bson:"n_modified"`
+	Namespace    string `json:"ns" bson:"ns"`
+	Op           string `json:"op" bson:"op"`
+	QueryPattern string `json:"query_pattern" bson:"query_pattern"`
+	Upserted     int    `json:"n_upserted" bson:"n_upserted"`
+}
+
+// GetWriteCounters returns write counters of a log in the order of
+// WRITE_COUNTERS, nil if a counter is not logged
+func GetWriteCounters(doc *Logv2Info) []interface{} {
+	counters := make([]interface{}, len(WRITE_COUNTERS))
+	for _, elem := range doc.Attr {
+		column, ok := writeCounterNames[elem.Key]
+		if !ok {
+			continue
+		}
+		for i, name := range WRITE_COUNTERS {
+			if name == column {
+				counters[i] = ToInt(elem.Value)
+			}
+		}
+	}
+	return counters
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * writes_template.go
+ */
+
+package hatchet
+
+import (
+	"fmt"
+	"html/template"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// GetWritesTemplate returns HTML
+func GetWritesTemplate() (*template.Template, error) {
+	html := getContentHTML()
+	html += `
+<div style='margin: 10px 10px; clear: left;'>
+{{if .Writes}}
+	<table width='100%'>
+		<caption>Write Heavy Ops, by Documents Modified</caption>
+		<tr><th>#</th><th>op</th><th>namespace</th><th>count</th><th>matched</th><th>modified</th>
+			<th>matched/modified</th><th>inserted</th><th>upserted</th><th>deleted</th><th>query pattern</th></tr>
+	{{range $n, $w := .Writes}}
+		<tr><td align='right'>{{add $n 1}}</td><td>{{$w.Op}}</td><td class='break'>{{$w.Namespace}}</td>
+			<td align='right'>{{numPrinter $w.Count}}</td><td align='right'>{{numPrinter $w.Matched}}</td>
+			<td align='right'>{{numPrinter $w.Modified}}</td><td align='right'>{{getRatio $w.Matched $w.Modified}}</td>
+			<td align='right'>{{numPrinter $w.Inserted}}</td><td align='right'>{{numPrinter $w.Upserted}}</td>
+			<td align='right'>{{numPrinter $w.Deleted}}</td><td class='break'>{{$w.QueryPattern}}</td>
+		</tr>
+	{{end}}
+	</table>
+{{else}}
+	<div align='center' class='btn'><span style='color: red'>no write counters found</span></div>
+{{end}}
+</div>
+</body></html>`
+	return template.New("hatchet").Funcs(template.FuncMap{
+		"add": func(a int, b int) int {
+			return a + b
+		},
+		"getRatio": func(matched int, modified int) string {
+			if modified == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f", float64(matched)/float64(modified))
+		},
+		"numPrinter": func(n int) string {
+			printer := message.NewPrinter(language.English)
+			return printer.Sprintf("%v", n)
+		}}).Parse(html)
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright 2022-present Kuei-chun Chen. All rights reserved.
+ * writes_test.go
+ */
+
+package hatchet
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGetWriteCounters(t *testing.T) {
+	str := `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"WRITE","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"update","ns":"shop.orders","command":{"q":{"status":"new"},"u":{"$set":{"status":"done"}},"multi":true},"planSummary":"COLLSCAN","nMatched":5000,"nModified":4990,"nUpserted":0,"durationMillis":1200}}`
+	var doc Logv2Info
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	counters := GetWriteCounters(&doc)
+	if counters[0] != 5000 || counters[1] != 4990 || counters[3] != 0 {
+		t.Fatal("expected", 5000, 4990, 0, "but got", counters[0], counters[1], counters[3])
+	}
+	if counters[2] != nil || counters[4] != nil {
+		t.Fatal("expected", nil, nil, "but got", counters[2], counters[4])
+	}
+
+	str = `{"t":{"$date":"2023-10-01T12:00:00.000+00:00"},"s":"I","c":"WRITE","id":51803,"ctx":"conn1","msg":"Slow query","attr":{"type":"remove","ns":"shop.orders","command":{"q":{"status":"done"},"limit":0},"ndeleted":300,"durationMillis":200}}`
+	doc = Logv2Info{}
+	if err := bson.UnmarshalExtJSON([]byte(str), false, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if counters = GetWriteCounters(&doc); counters[4] != 300 {
+		t.Fatal("expected", 300, "but got", counters[4])
+	}
+}